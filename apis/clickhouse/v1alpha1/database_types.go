@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A DatabaseSpec defines the desired state of a Database.
+type DatabaseSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseParameters `json:"forProvider"`
+}
+
+// A DatabaseStatus represents the observed state of a Database.
+type DatabaseStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseObservation represents the observed state of a ClickHouse database.
+type DatabaseObservation struct {
+	// Engine is the database engine.
+	Engine string `json:"engine,omitempty"`
+
+	// SizeBytes is the disk space used by the active parts of the tables in
+	// the database.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+}
+
+// DatabaseParameters define the desired state of a ClickHouse database instance.
+type DatabaseParameters struct {
+	// Engine of the database, e.g. Atomic or Replicated('/path', '{shard}', '{replica}').
+	// Defaults to the default engine of the server.
+	// See https://clickhouse.com/docs/en/engines/database-engines
+	// +immutable
+	// +optional
+	Engine *string `json:"engine,omitempty"`
+
+	// Cluster to create the database on with ON CLUSTER.
+	// +immutable
+	// +optional
+	Cluster *string `json:"cluster,omitempty"`
+
+	// Comment on the database.
+	// +immutable
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Database represents the declarative state of a ClickHouse database.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="ENGINE",type="string",JSONPath=".status.atProvider.engine"
+// +kubebuilder:printcolumn:name="SIZE",type="integer",JSONPath=".status.atProvider.sizeBytes",priority=1
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Database struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseSpec   `json:"spec"`
+	Status DatabaseStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseList contains a list of Database
+type DatabaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Database `json:"items"`
+}
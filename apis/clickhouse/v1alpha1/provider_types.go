@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A ProviderConfigSpec defines the desired state of a ProviderConfig.
+type ProviderConfigSpec struct {
+	// Credentials required to authenticate to this provider.
+	Credentials ProviderCredentials `json:"credentials"`
+
+	// Secure enables TLS encrypted connections to the server. The port of
+	// the connection secret must then be the secure native protocol port,
+	// usually 9440.
+	// +optional
+	Secure *bool `json:"secure,omitempty"`
+
+	// ConnectionOptions are additional driver parameters that are appended
+	// to the connection string, for example dial_timeout or compress.
+	// Supported options are compress, connection_open_strategy,
+	// dial_timeout, max_execution_time, read_timeout and skip_verify.
+	// +optional
+	ConnectionOptions map[string]string `json:"connectionOptions,omitempty"`
+
+	// MaxOpenConnections bounds the number of connections the provider has
+	// open to the server at once, across all of the managed resources that
+	// use this ProviderConfig. Statements wait for a connection to be closed
+	// once it is reached. Each statement needs only one connection, and
+	// releases it before the next one starts, so 1 runs all statements one
+	// after another. Unset does not bound them.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOpenConnections *int32 `json:"maxOpenConnections,omitempty"`
+
+	// MaxIdleConnections bounds the number of idle connections kept open
+	// between the statements of a reconcile. Unset keeps two, or none when
+	// maxOpenConnections is set, so that idle connections do not hold up
+	// other reconciles waiting for a connection.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIdleConnections *int32 `json:"maxIdleConnections,omitempty"`
+
+	// MaintenanceWindow restricts updates and deletions of the external
+	// resources of managed resources that use this ProviderConfig to
+	// recurring periods, e.g. "Sat,Sun 02:00-06:00 Europe/Berlin". Periods
+	// are separated by semicolons, and start on the listed days of the week,
+	// which may be ranges such as Mon-Fri or * for every day. Times are in
+	// UTC unless a time zone is given. Outside of it, managed resources are
+	// observed but not changed, and report a Deferred condition. A managed
+	// resource may override it with the sql.crossplane.io/maintenance-window
+	// annotation. Changes are allowed at any time if it is unset.
+	// +optional
+	MaintenanceWindow *string `json:"maintenanceWindow,omitempty"`
+}
+
+const (
+	// CredentialsSourceClickHouseConnectionSecret indicates that a provider
+	// should acquire credentials from a connection secret written by a managed
+	// resource that represents a ClickHouse server.
+	CredentialsSourceClickHouseConnectionSecret xpv1.CredentialsSource = "ClickHouseConnectionSecret"
+)
+
+// ProviderCredentials required to authenticate.
+type ProviderCredentials struct {
+	// Source of the provider credentials.
+	// +kubebuilder:validation:Enum=ClickHouseConnectionSecret
+	Source xpv1.CredentialsSource `json:"source"`
+
+	// A CredentialsSecretRef is a reference to a ClickHouse connection secret
+	// that contains the credentials that must be used to connect to the
+	// provider.
+	// +optional
+	ConnectionSecretRef *xpv1.SecretReference `json:"connectionSecretRef,omitempty"`
+}
+
+// A ProviderConfigStatus reflects the observed state of a ProviderConfig.
+type ProviderConfigStatus struct {
+	xpv1.ProviderConfigStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProviderConfig configures a ClickHouse provider.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentialsSecretRef.name",priority=1
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,sql}
+type ProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderConfigSpec   `json:"spec"`
+	Status ProviderConfigStatus `json:"status,omitempty"`
+}
+
+// GetMaintenanceWindow returns the maintenance window of the ProviderConfig,
+// or an empty string if it has none.
+func (pc *ProviderConfig) GetMaintenanceWindow() string {
+	if pc.Spec.MaintenanceWindow == nil {
+		return ""
+	}
+	return *pc.Spec.MaintenanceWindow
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderConfigList contains a list of ProviderConfig.
+type ProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderConfig `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProviderConfigUsage indicates that a resource is using a ProviderConfig.
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="CONFIG-NAME",type="string",JSONPath=".providerConfigRef.name"
+// +kubebuilder:printcolumn:name="RESOURCE-KIND",type="string",JSONPath=".resourceRef.kind"
+// +kubebuilder:printcolumn:name="RESOURCE-NAME",type="string",JSONPath=".resourceRef.name"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,sql}
+type ProviderConfigUsage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	xpv1.ProviderConfigUsage `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderConfigUsageList contains a list of ProviderConfigUsage
+type ProviderConfigUsageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderConfigUsage `json:"items"`
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A UserSpec defines the desired state of a User.
+type UserSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UserParameters `json:"forProvider"`
+}
+
+// A UserStatus represents the observed state of a User.
+type UserStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          UserObservation `json:"atProvider,omitempty"`
+}
+
+// UserParameters define the desired state of a ClickHouse user instance.
+type UserParameters struct {
+	// PasswordSecretRef references the secret that contains the password used
+	// for this user. If no reference is given, a password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// DefaultDatabase of the user.
+	// +optional
+	DefaultDatabase *string `json:"defaultDatabase,omitempty"`
+
+	// SettingsProfile is the name of an existing settings profile applied
+	// to the user.
+	// See https://clickhouse.com/docs/en/operations/settings/settings-profiles
+	// +optional
+	SettingsProfile *string `json:"settingsProfile,omitempty"`
+
+	// Quota sets resource limits for the user. The limits are applied
+	// through a quota named after the user.
+	// See https://clickhouse.com/docs/en/operations/quotas
+	// +optional
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// Quota define the resource limits of a user over an interval.
+type Quota struct {
+	// Interval the limits apply to, e.g. 1 hour or 1 day.
+	// +kubebuilder:default="1 hour"
+	// +kubebuilder:validation:Pattern:=^[0-9]+ (second|minute|hour|day|week|month|quarter|year)$
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// MaxQueries is the number of queries the user can issue per interval.
+	// +optional
+	MaxQueries *int64 `json:"maxQueries,omitempty"`
+
+	// MaxErrors is the number of queries that may fail per interval.
+	// +optional
+	MaxErrors *int64 `json:"maxErrors,omitempty"`
+
+	// MaxResultRows is the number of rows that may be returned per interval.
+	// +optional
+	MaxResultRows *int64 `json:"maxResultRows,omitempty"`
+
+	// MaxReadRows is the number of rows that may be read from tables per
+	// interval.
+	// +optional
+	MaxReadRows *int64 `json:"maxReadRows,omitempty"`
+
+	// MaxExecutionTime is the total query execution time, in seconds, per
+	// interval.
+	// +optional
+	MaxExecutionTime *int64 `json:"maxExecutionTime,omitempty"`
+}
+
+// A UserObservation represents the observed state of a ClickHouse user.
+type UserObservation struct {
+	// QuotaAsClauses represents the applied quota limits.
+	QuotaAsClauses []string `json:"quotaAsClauses,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A User represents the declarative state of a ClickHouse user.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// An SQLError is an error returned while reconciling a managed resource.
+type SQLError struct {
+	// Operation during which the error occurred; one of Connect, Observe,
+	// Create, Update or Delete.
+	Operation string `json:"operation"`
+
+	// Message of the error, with string literals, which may hold secrets,
+	// redacted.
+	Message string `json:"message"`
+
+	// Time at which the error first occurred. It is not updated while the
+	// same error recurs.
+	Time metav1.Time `json:"time"`
+}
+
+// A SyncStatus records the outcome of the most recent reconciles of a
+// managed resource, so that it can be monitored, e.g. by kube-state-metrics,
+// without scraping logs.
+type SyncStatus struct {
+	// LastSQLError is the most recent error returned while reconciling the
+	// managed resource. It is kept once the resource is in sync again; an
+	// error older than LastSuccessfulSync has been resolved.
+	// +optional
+	LastSQLError *SQLError `json:"lastSQLError,omitempty"`
+
+	// LastSuccessfulSync is the time at which the external resource was
+	// last observed, or made, to match the managed resource. While it stays
+	// in sync the time is refreshed at most once an hour.
+	// +optional
+	LastSuccessfulSync *metav1.Time `json:"lastSuccessfulSync,omitempty"`
+}
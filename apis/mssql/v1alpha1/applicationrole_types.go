@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// An ApplicationRoleSpec defines the desired state of an ApplicationRole.
+type ApplicationRoleSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ApplicationRoleParameters `json:"forProvider"`
+}
+
+// An ApplicationRoleStatus represents the observed state of an
+// ApplicationRole.
+type ApplicationRoleStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          ApplicationRoleObservation `json:"atProvider,omitempty"`
+}
+
+// ApplicationRoleParameters define the desired state of a MSSQL application
+// role.
+type ApplicationRoleParameters struct {
+	// Database the application role is created in.
+	// +crossplane:generate:reference:type=Database
+	// +kubebuilder:validation:MaxLength=128
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the application role is
+	// created in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the application
+	// role is created in.
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+
+	// PasswordSecretRef references the secret that contains the password
+	// applications activate the role with. If no reference is given, a
+	// password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// DefaultSchema of the application role. Defaults to dbo.
+	// +kubebuilder:default=dbo
+	// +kubebuilder:validation:MaxLength=128
+	// +optional
+	DefaultSchema *string `json:"defaultSchema,omitempty"`
+}
+
+// An ApplicationRoleObservation represents the observed state of a MSSQL
+// application role.
+type ApplicationRoleObservation struct {
+	// DefaultSchema is the default schema of the application role.
+	DefaultSchema string `json:"defaultSchema,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An ApplicationRole represents the declarative state of a MSSQL application
+// role, which applications activate with sp_setapprole to assume its
+// permissions. Its connection secret holds the name and password of the
+// role, and the endpoint, port and database of the server.
+// See https://learn.microsoft.com/en-us/sql/relational-databases/security/authentication-access/application-roles
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type ApplicationRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationRoleSpec   `json:"spec"`
+	Status ApplicationRoleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationRoleList contains a list of ApplicationRole
+type ApplicationRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationRole `json:"items"`
+}
@@ -20,16 +20,137 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A DatabaseSpec defines the desired state of a Database.
 type DatabaseSpec struct {
 	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseParameters `json:"forProvider,omitempty"`
+}
+
+// DatabaseParameters define the desired state of a MSSQL database.
+// +kubebuilder:validation:XValidation:rule="!(has(self.tdeCertificate) && has(self.tdeAsymmetricKey))",message="tdeCertificate and tdeAsymmetricKey are mutually exclusive"
+type DatabaseParameters struct {
+	// RestoreFromSnapshot is the name of a snapshot of this database to
+	// revert it to. The database is reverted each time this changes, which
+	// discards all changes made since the snapshot was taken, and requires
+	// it to be the only snapshot of the database. Users connected to the
+	// database are disconnected.
+	// See https://learn.microsoft.com/en-us/sql/relational-databases/databases/revert-a-database-to-a-database-snapshot
+	// +optional
+	RestoreFromSnapshot *string `json:"restoreFromSnapshot,omitempty"`
+
+	// DatabaseScopedConfigurations to set on the database, keyed by name,
+	// e.g. MAXDOP: "4" or LEGACY_CARDINALITY_ESTIMATION: "ON". Values are
+	// numbers or keywords. Configurations that are not listed are left
+	// alone.
+	// See https://learn.microsoft.com/en-us/sql/t-sql/statements/alter-database-scoped-configuration-transact-sql
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[A-Za-z_]+$') && self[k].matches('^[A-Za-z0-9_]+$'))",message="names must be letters and underscores, and values numbers or keywords"
+	DatabaseScopedConfigurations map[string]string `json:"databaseScopedConfigurations,omitempty"`
+
+	// ExtendedProperties to set on the database, keyed by name, e.g. for
+	// tagging it with its cost center. They are set using
+	// sp_addextendedproperty and sp_updateextendedproperty. Properties that
+	// are removed from the list are dropped, and those that were never
+	// listed are left alone.
+	// See https://learn.microsoft.com/en-us/sql/relational-databases/system-stored-procedures/sp-addextendedproperty-transact-sql
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(k, size(k) <= 128)",message="names must be at most 128 characters"
+	ExtendedProperties map[string]string `json:"extendedProperties,omitempty"`
+
+	// SchemaExtendedProperties to set on schemas of the database, keyed by
+	// schema and then by property name. They are managed like
+	// extendedProperties. The schemas must exist.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(s, self[s].all(k, size(k) <= 128))",message="names must be at most 128 characters"
+	SchemaExtendedProperties map[string]map[string]string `json:"schemaExtendedProperties,omitempty"`
+
+	// TDEEnabled turns Transparent Data Encryption of the database on or
+	// off. Turning it on creates a database encryption key protected by
+	// tdeCertificate or tdeAsymmetricKey, unless the database has one.
+	// Turning it off leaves the key in place. Encryption is left as is when
+	// unset.
+	// See https://learn.microsoft.com/en-us/sql/relational-databases/security/encryption/transparent-data-encryption
+	// +optional
+	TDEEnabled *bool `json:"tdeEnabled,omitempty"`
+
+	// TDECertificate is the name of the certificate in master that protects
+	// the database encryption key. Changing it re-encrypts the key with the
+	// new certificate.
+	// +optional
+	TDECertificate *string `json:"tdeCertificate,omitempty"`
+
+	// TDEAsymmetricKey is the name of the asymmetric key in master, e.g. one
+	// of an EKM provider such as Azure Key Vault, that protects the database
+	// encryption key. Changing it re-encrypts the key with the new
+	// asymmetric key.
+	// +optional
+	TDEAsymmetricKey *string `json:"tdeAsymmetricKey,omitempty"`
+
+	// TDEAlgorithm of the database encryption key. It is only used when the
+	// key is created. Defaults to AES_256.
+	// +optional
+	// +kubebuilder:validation:Enum=AES_128;AES_192;AES_256
+	TDEAlgorithm *string `json:"tdeAlgorithm,omitempty"`
 }
 
 // A DatabaseStatus represents the observed state of a Database.
 type DatabaseStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseObservation represents the observed state of a MSSQL database.
+type DatabaseObservation struct {
+	// Owner is the login that owns the database.
+	Owner string `json:"owner,omitempty"`
+
+	// Collation is the default collation of the database.
+	Collation string `json:"collation,omitempty"`
+
+	// State of the database, e.g. ONLINE or RESTORING.
+	State string `json:"state,omitempty"`
+
+	// RecoveryModel of the database, i.e. FULL, BULK_LOGGED or SIMPLE.
+	RecoveryModel string `json:"recoveryModel,omitempty"`
+
+	// CompatibilityLevel of the database, e.g. 160 for SQL Server 2022.
+	CompatibilityLevel int `json:"compatibilityLevel,omitempty"`
+
+	// ReadOnly is true if the database is read only.
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// SizeBytes is the disk space used by the data and log files of the
+	// database.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// RestoredFromSnapshot is the snapshot the database was last reverted
+	// to by the provider.
+	RestoredFromSnapshot string `json:"restoredFromSnapshot,omitempty"`
+
+	// DatabaseScopedConfigurations are the values of the database scoped
+	// configurations listed in databaseScopedConfigurations.
+	DatabaseScopedConfigurations map[string]string `json:"databaseScopedConfigurations,omitempty"`
+
+	// ExtendedProperties are the values of the extended properties listed
+	// in extendedProperties that are set on the database.
+	ExtendedProperties map[string]string `json:"extendedProperties,omitempty"`
+
+	// SchemaExtendedProperties are the values of the extended properties
+	// listed in schemaExtendedProperties that are set on the schemas.
+	SchemaExtendedProperties map[string]map[string]string `json:"schemaExtendedProperties,omitempty"`
+
+	// EncryptionState of the database encryption key, e.g. ENCRYPTED or
+	// ENCRYPTION_IN_PROGRESS. It is empty if the database has no key.
+	EncryptionState string `json:"encryptionState,omitempty"`
+
+	// TDEProtector is the name of the certificate or asymmetric key in
+	// master that protects the database encryption key.
+	TDEProtector string `json:"tdeProtector,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -39,6 +160,11 @@ type DatabaseStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="OWNER",type="string",JSONPath=".status.atProvider.owner"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="RECOVERY",type="string",JSONPath=".status.atProvider.recoveryModel",priority=1
+// +kubebuilder:printcolumn:name="SIZE",type="integer",JSONPath=".status.atProvider.sizeBytes",priority=1
+// +kubebuilder:printcolumn:name="ENCRYPTION",type="string",JSONPath=".status.atProvider.encryptionState",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
 type Database struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A DatabaseAuditSpecificationSpec defines the desired state of a
+// DatabaseAuditSpecification.
+type DatabaseAuditSpecificationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseAuditSpecificationParameters `json:"forProvider"`
+}
+
+// A DatabaseAuditSpecificationStatus represents the observed state of a
+// DatabaseAuditSpecification.
+type DatabaseAuditSpecificationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseAuditSpecificationObservation `json:"atProvider,omitempty"`
+}
+
+// DatabaseAuditSpecificationParameters define the desired state of a MSSQL
+// database audit specification. The specification is disabled while it is
+// altered.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-database-audit-specification-transact-sql
+type DatabaseAuditSpecificationParameters struct {
+	// AuditActionGroups are the database-level audit action groups that
+	// are audited, e.g. SCHEMA_OBJECT_ACCESS_GROUP.
+	// See https://learn.microsoft.com/en-us/sql/relational-databases/security/auditing/sql-server-audit-action-groups-and-actions
+	// +kubebuilder:validation:MinItems:=1
+	AuditActionGroups []AuditActionGroup `json:"auditActionGroups"`
+
+	// Enabled starts the specification. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ServerAudit the audited actions are recorded by.
+	// +optional
+	// +crossplane:generate:reference:type=ServerAudit
+	ServerAudit *string `json:"serverAudit,omitempty"`
+
+	// ServerAuditRef references the ServerAudit the audited actions are
+	// recorded by.
+	// +optional
+	ServerAuditRef *xpv1.Reference `json:"serverAuditRef,omitempty"`
+
+	// ServerAuditSelector selects a reference to a ServerAudit the audited
+	// actions are recorded by.
+	// +optional
+	ServerAuditSelector *xpv1.Selector `json:"serverAuditSelector,omitempty"`
+
+	// Database the specification is created in.
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the specification is
+	// created in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the specification
+	// is created in.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// AuditActionGroup is a SQL Server audit action group.
+// +kubebuilder:validation:Pattern:=^[A-Z_]+_GROUP$
+type AuditActionGroup string
+
+// A DatabaseAuditSpecificationObservation represents the observed state of a
+// MSSQL database audit specification.
+type DatabaseAuditSpecificationObservation struct {
+	// ServerAudit the audited actions are recorded by.
+	ServerAudit string `json:"serverAudit,omitempty"`
+
+	// AuditActionGroups are the audit action groups that are audited.
+	AuditActionGroups []string `json:"auditActionGroups,omitempty"`
+
+	// Enabled is true if the specification is started.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DatabaseAuditSpecification represents the declarative state of a MSSQL
+// database audit specification.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="SERVER AUDIT",type="string",JSONPath=".spec.forProvider.serverAudit"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type DatabaseAuditSpecification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseAuditSpecificationSpec   `json:"spec"`
+	Status DatabaseAuditSpecificationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseAuditSpecificationList contains a list of DatabaseAuditSpecification
+type DatabaseAuditSpecificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseAuditSpecification `json:"items"`
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A DatabaseLoginSpec defines the desired state of a DatabaseLogin.
+type DatabaseLoginSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseLoginParameters `json:"forProvider"`
+}
+
+// A DatabaseLoginStatus represents the observed state of a DatabaseLogin.
+type DatabaseLoginStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseLoginObservation `json:"atProvider,omitempty"`
+}
+
+// DatabaseLoginParameters define the desired state of a MSSQL SQL login
+// together with its user in a database and the database roles of that user.
+type DatabaseLoginParameters struct {
+	// Database the user is created in. It is also the default database of
+	// the login.
+	// +crossplane:generate:reference:type=Database
+	// +kubebuilder:validation:MaxLength=128
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the user is created in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the user is
+	// created in.
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+
+	// PasswordSecretRef references the secret that contains the password of
+	// the login. If no reference is given, a password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// DefaultSchema of the user. Defaults to dbo.
+	// +kubebuilder:default=dbo
+	// +kubebuilder:validation:MaxLength=128
+	// +optional
+	DefaultSchema *string `json:"defaultSchema,omitempty"`
+
+	// Roles are the database roles the user is a member of. Defaults to
+	// db_datareader and db_datawriter. The user is removed from any other
+	// role.
+	// +kubebuilder:default={db_datareader,db_datawriter}
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// A DatabaseLoginObservation represents the observed state of a MSSQL
+// DatabaseLogin.
+type DatabaseLoginObservation struct {
+	// DefaultSchema is the default schema of the user.
+	DefaultSchema string `json:"defaultSchema,omitempty"`
+
+	// Roles are the database roles the user is a member of.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DatabaseLogin represents the declarative state of a MSSQL SQL login, its
+// user in a database and the database roles of that user. Its connection
+// secret holds the username, password, endpoint, port and database of the
+// login.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type DatabaseLogin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseLoginSpec   `json:"spec"`
+	Status DatabaseLoginStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseLoginList contains a list of DatabaseLogin
+type DatabaseLoginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseLogin `json:"items"`
+}
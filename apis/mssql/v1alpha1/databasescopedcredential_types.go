@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A DatabaseScopedCredentialSpec defines the desired state of a
+// DatabaseScopedCredential.
+type DatabaseScopedCredentialSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseScopedCredentialParameters `json:"forProvider"`
+}
+
+// A DatabaseScopedCredentialStatus represents the observed state of a
+// DatabaseScopedCredential.
+type DatabaseScopedCredentialStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseScopedCredentialObservation `json:"atProvider,omitempty"`
+}
+
+// DatabaseScopedCredentialParameters define the desired state of a MSSQL
+// database scoped credential. The database must have a master key.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-database-scoped-credential-transact-sql
+type DatabaseScopedCredentialParameters struct {
+	// Identity is the name of the account to be used when connecting outside
+	// the server, e.g. the remote login or SHARED ACCESS SIGNATURE.
+	Identity string `json:"identity"`
+
+	// SecretRef references the secret that contains the secret used for
+	// authentication, e.g. the password of the remote login.
+	// +optional
+	SecretRef *xpv1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// Database the credential is created in.
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the credential is created in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the credential is
+	// created in.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A DatabaseScopedCredentialObservation represents the observed state of a
+// MSSQL database scoped credential.
+type DatabaseScopedCredentialObservation struct {
+	// SecretHash is a hash of the secret last applied to the credential. The
+	// secret itself cannot be read back from the database.
+	SecretHash string `json:"secretHash,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DatabaseScopedCredential represents the declarative state of a MSSQL
+// database scoped credential.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="IDENTITY",type="string",JSONPath=".spec.forProvider.identity"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type DatabaseScopedCredential struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseScopedCredentialSpec   `json:"spec"`
+	Status DatabaseScopedCredentialStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseScopedCredentialList contains a list of DatabaseScopedCredential
+type DatabaseScopedCredentialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseScopedCredential `json:"items"`
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A DatabaseSnapshotSpec defines the desired state of a DatabaseSnapshot.
+type DatabaseSnapshotSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseSnapshotParameters `json:"forProvider"`
+}
+
+// A DatabaseSnapshotStatus represents the observed state of a
+// DatabaseSnapshot.
+type DatabaseSnapshotStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseSnapshotObservation `json:"atProvider,omitempty"`
+}
+
+// DatabaseSnapshotParameters define the desired state of a MSSQL database
+// snapshot. The sparse files of the snapshot are created next to the data
+// files of the source database.
+// See https://learn.microsoft.com/en-us/sql/relational-databases/databases/create-a-database-snapshot-transact-sql
+type DatabaseSnapshotParameters struct {
+	// Database the snapshot is taken of.
+	// +crossplane:generate:reference:type=Database
+	// +immutable
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the snapshot is taken of.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the snapshot is
+	// taken of.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A DatabaseSnapshotObservation represents the observed state of a MSSQL
+// database snapshot.
+type DatabaseSnapshotObservation struct {
+	// SourceDatabase is the database the snapshot was taken of.
+	SourceDatabase string `json:"sourceDatabase,omitempty"`
+
+	// CreationTime is when the snapshot was taken.
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DatabaseSnapshot represents the declarative state of a MSSQL database
+// snapshot, a read-only, point-in-time view of a database.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SOURCE",type="string",JSONPath=".status.atProvider.sourceDatabase"
+// +kubebuilder:printcolumn:name="CREATED",type="date",JSONPath=".status.atProvider.creationTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type DatabaseSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseSnapshotSpec   `json:"spec"`
+	Status DatabaseSnapshotStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseSnapshotList contains a list of DatabaseSnapshot
+type DatabaseSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseSnapshot `json:"items"`
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// An ExternalDataSourceSpec defines the desired state of an
+// ExternalDataSource.
+type ExternalDataSourceSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ExternalDataSourceParameters `json:"forProvider"`
+}
+
+// An ExternalDataSourceStatus represents the observed state of an
+// ExternalDataSource.
+type ExternalDataSourceStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          ExternalDataSourceObservation `json:"atProvider,omitempty"`
+}
+
+// ExternalDataSourceParameters define the desired state of a MSSQL external
+// data source, as used by PolyBase and elastic query.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-external-data-source-transact-sql
+type ExternalDataSourceParameters struct {
+	// Type of the external data source. May be omitted for PolyBase data
+	// sources on SQL Server 2022 and later.
+	// +kubebuilder:validation:Enum=HADOOP;BLOB_STORAGE;RDBMS;SHARD_MAP_MANAGER
+	// +immutable
+	// +optional
+	Type *string `json:"type,omitempty"`
+
+	// Location of the external data source, e.g. the server name of a
+	// remote Azure SQL database or an object storage URL.
+	Location string `json:"location"`
+
+	// DatabaseName is the name of the remote database for RDBMS and
+	// SHARD_MAP_MANAGER data sources.
+	// +immutable
+	// +optional
+	DatabaseName *string `json:"databaseName,omitempty"`
+
+	// ShardMapName is the name of the shard map for SHARD_MAP_MANAGER data
+	// sources.
+	// +immutable
+	// +optional
+	ShardMapName *string `json:"shardMapName,omitempty"`
+
+	// Credential is the name of the database scoped credential used to
+	// authenticate to the external data source.
+	// +optional
+	// +crossplane:generate:reference:type=DatabaseScopedCredential
+	Credential *string `json:"credential,omitempty"`
+
+	// CredentialRef references the DatabaseScopedCredential used to
+	// authenticate to the external data source.
+	// +optional
+	CredentialRef *xpv1.Reference `json:"credentialRef,omitempty"`
+
+	// CredentialSelector selects a reference to a DatabaseScopedCredential
+	// used to authenticate to the external data source.
+	// +optional
+	CredentialSelector *xpv1.Selector `json:"credentialSelector,omitempty"`
+
+	// Database the external data source is created in.
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the external data source
+	// is created in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the external data
+	// source is created in.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// An ExternalDataSourceObservation represents the observed state of a MSSQL
+// external data source.
+type ExternalDataSourceObservation struct {
+	// Type of the external data source.
+	Type string `json:"type,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An ExternalDataSource represents the declarative state of a MSSQL external
+// data source.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="LOCATION",type="string",JSONPath=".spec.forProvider.location"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type ExternalDataSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExternalDataSourceSpec   `json:"spec"`
+	Status ExternalDataSourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExternalDataSourceList contains a list of ExternalDataSource
+type ExternalDataSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExternalDataSource `json:"items"`
+}
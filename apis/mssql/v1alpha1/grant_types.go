@@ -19,6 +19,8 @@ package v1alpha1
 import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A GrantSpec defines the desired state of a Grant.
@@ -49,6 +51,7 @@ func (gp *GrantPermissions) ToStringSlice() []string {
 }
 
 // GrantParameters define the desired state of a MSSQL grant instance.
+// +kubebuilder:validation:XValidation:rule="!(has(self.schema) && (has(self.onUser) || has(self.onUserRef) || has(self.onUserSelector)))",message="schema and onUser are mutually exclusive"
 type GrantParameters struct {
 	// Permissions to be granted.
 	// See https://docs.microsoft.com/en-us/sql/t-sql/statements/grant-database-permissions-transact-sql?view=sql-server-ver15#remarks
@@ -60,6 +63,24 @@ type GrantParameters struct {
 	// +optional
 	Schema *string `json:"schema,omitempty"`
 
+	// OnUser is the database user the permissions are granted on, for
+	// example IMPERSONATE to let the grantee execute as that user.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=User
+	OnUser *string `json:"onUser,omitempty"`
+
+	// OnUserRef references the user object the permissions are granted on.
+	// +immutable
+	// +optional
+	OnUserRef *xpv1.Reference `json:"onUserRef,omitempty"`
+
+	// OnUserSelector selects a reference to a User the permissions are
+	// granted on.
+	// +immutable
+	// +optional
+	OnUserSelector *xpv1.Selector `json:"onUserSelector,omitempty"`
+
 	// User this grant is for.
 	// +optional
 	// +crossplane:generate:reference:type=User
@@ -94,6 +115,7 @@ type GrantParameters struct {
 // A GrantStatus represents the observed state of a Grant.
 type GrantStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A LinkedServerSpec defines the desired state of a LinkedServer.
+type LinkedServerSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       LinkedServerParameters `json:"forProvider"`
+}
+
+// A LinkedServerStatus represents the observed state of a LinkedServer.
+type LinkedServerStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          LinkedServerObservation `json:"atProvider,omitempty"`
+}
+
+// LinkedServerParameters define the desired state of a MSSQL linked server.
+// Changing any of the server parameters drops and re-adds the linked server.
+// See https://learn.microsoft.com/en-us/sql/relational-databases/system-stored-procedures/sp-addlinkedserver-transact-sql
+type LinkedServerParameters struct {
+	// Product is the product name of the OLE DB data source. If it is
+	// "SQL Server" the name of the linked server is the network name of
+	// the remote instance and no other server parameters may be set.
+	// +optional
+	Product *string `json:"product,omitempty"`
+
+	// Provider is the unique programmatic identifier of the OLE DB provider,
+	// e.g. MSOLEDBSQL.
+	// +optional
+	Provider *string `json:"provider,omitempty"`
+
+	// DataSource is the name of the data source as interpreted by the OLE DB
+	// provider, e.g. the network name of a remote SQL Server instance.
+	// +optional
+	DataSource *string `json:"dataSource,omitempty"`
+
+	// Catalog is the catalog used when connecting to the OLE DB provider.
+	// +optional
+	Catalog *string `json:"catalog,omitempty"`
+
+	// ProviderString is the OLE DB provider-specific connection string.
+	// +optional
+	ProviderString *string `json:"providerString,omitempty"`
+
+	// RemoteUser is the remote login used by all local logins when
+	// connecting to the linked server. If unset, local logins connect with
+	// their own credentials.
+	// +optional
+	RemoteUser *string `json:"remoteUser,omitempty"`
+
+	// RemotePasswordSecretRef references the secret that contains the
+	// password of the remote user.
+	// +optional
+	RemotePasswordSecretRef *xpv1.SecretKeySelector `json:"remotePasswordSecretRef,omitempty"`
+}
+
+// A LinkedServerObservation represents the observed state of a MSSQL linked
+// server.
+type LinkedServerObservation struct {
+	// RemotePasswordHash is a hash of the remote password last applied to
+	// the linked server login. The password cannot be read back from the
+	// server.
+	RemotePasswordHash string `json:"remotePasswordHash,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A LinkedServer represents the declarative state of a MSSQL linked server.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.forProvider.provider"
+// +kubebuilder:printcolumn:name="DATA SOURCE",type="string",JSONPath=".spec.forProvider.dataSource"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type LinkedServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LinkedServerSpec   `json:"spec"`
+	Status LinkedServerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LinkedServerList contains a list of LinkedServer
+type LinkedServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LinkedServer `json:"items"`
+}
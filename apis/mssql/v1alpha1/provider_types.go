@@ -26,6 +26,92 @@ import (
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// ApplicationIntent declares the application workload type when
+	// connecting to an availability group listener. Set it to ReadOnly to
+	// be routed to a readable secondary replica. Every managed resource
+	// writes to the server when it is created, updated or deleted, which
+	// fails on a secondary replica, so use ReadOnly only for resources that
+	// are observed, i.e. whose managementPolicies are ["Observe"].
+	// +kubebuilder:validation:Enum=ReadWrite;ReadOnly
+	// +optional
+	ApplicationIntent *string `json:"applicationIntent,omitempty"`
+
+	// MultiSubnetFailover enables faster detection of and connection to the
+	// active server when connecting to an availability group listener that
+	// spans multiple subnets.
+	// +optional
+	MultiSubnetFailover *bool `json:"multiSubnetFailover,omitempty"`
+
+	// FailoverPartner is the host of the database mirroring failover partner
+	// that is used when the primary server cannot be reached.
+	// +optional
+	FailoverPartner *string `json:"failoverPartner,omitempty"`
+
+	// Encrypt controls whether the connection to the server is encrypted.
+	// true encrypts the whole connection, false only the login packet, and
+	// disable none of it. strict encrypts the whole connection using TDS 8.0,
+	// which SQL Server 2022 and Azure SQL support. Defaults to the encrypt
+	// connection option, or the driver default of false if that is not set.
+	// +kubebuilder:validation:Enum=disable;false;true;strict
+	// +optional
+	Encrypt *string `json:"encrypt,omitempty"`
+
+	// DialTimeout bounds how long the provider waits to open a network
+	// connection to the server, e.g. 15s. It is rounded up to whole seconds.
+	// Unset uses the dial timeout connection option, or the driver default
+	// of 15 seconds.
+	// +optional
+	DialTimeout *metav1.Duration `json:"dialTimeout,omitempty"`
+
+	// ConnectionTimeout bounds how long the provider waits to connect and
+	// log in to the server, e.g. 30s, separately from how long its queries
+	// may run. It is rounded up to whole seconds. Unset uses the connection
+	// timeout connection option, or no timeout if that is not set.
+	// +optional
+	ConnectionTimeout *metav1.Duration `json:"connectionTimeout,omitempty"`
+
+	// ConnectionOptions are additional driver parameters that are appended
+	// to the connection string, for example encrypt or connection timeout.
+	// Supported options are app name, certificate, connection timeout,
+	// dial timeout, encrypt, fedauth, hostnameincertificate, keepalive,
+	// packet size, serverspn, trustservercertificate and workstation id.
+	// fedauth selects Azure AD authentication using the environment of the
+	// provider rather than the username and password of its credentials,
+	// either ActiveDirectoryWorkloadIdentity, which requires an AKS workload
+	// identity that sets AZURE_FEDERATED_TOKEN_FILE, or ActiveDirectoryDefault.
+	// +optional
+	ConnectionOptions map[string]string `json:"connectionOptions,omitempty"`
+
+	// MaxOpenConnections bounds the number of connections the provider has
+	// open to the server at once, across all of the managed resources that
+	// use this ProviderConfig. Statements wait for a connection to be closed
+	// once it is reached. Each statement needs only one connection, and
+	// releases it before the next one starts, so 1 runs all statements one
+	// after another. Unset does not bound them.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOpenConnections *int32 `json:"maxOpenConnections,omitempty"`
+
+	// MaxIdleConnections bounds the number of idle connections kept open
+	// between the statements of a reconcile. Unset keeps two, or none when
+	// maxOpenConnections is set, so that idle connections do not hold up
+	// other reconciles waiting for a connection.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIdleConnections *int32 `json:"maxIdleConnections,omitempty"`
+
+	// MaintenanceWindow restricts updates and deletions of the external
+	// resources of managed resources that use this ProviderConfig to
+	// recurring periods, e.g. "Sat,Sun 02:00-06:00 Europe/Berlin". Periods
+	// are separated by semicolons, and start on the listed days of the week,
+	// which may be ranges such as Mon-Fri or * for every day. Times are in
+	// UTC unless a time zone is given. Outside of it, managed resources are
+	// observed but not changed, and report a Deferred condition. A managed
+	// resource may override it with the sql.crossplane.io/maintenance-window
+	// annotation. Changes are allowed at any time if it is unset.
+	// +optional
+	MaintenanceWindow *string `json:"maintenanceWindow,omitempty"`
 }
 
 const (
@@ -37,8 +123,11 @@ const (
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
-	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=MSSQLConnectionSecret
+	// Source of the provider credentials. Environment and Filesystem read
+	// them from the environment or filesystem of the provider, e.g. secrets
+	// injected by Vault Agent or the Secrets Store CSI driver, instead of a
+	// connection secret.
+	// +kubebuilder:validation:Enum=MSSQLConnectionSecret;Environment;Filesystem
 	Source xpv1.CredentialsSource `json:"source"`
 
 	// A CredentialsSecretRef is a reference to a MSSQL connection secret
@@ -46,6 +135,19 @@ type ProviderCredentials struct {
 	// provider.
 	// +optional
 	ConnectionSecretRef *xpv1.SecretReference `json:"connectionSecretRef,omitempty"`
+
+	// Env is the environment variable of the provider that holds the
+	// credentials when the source is Environment. It holds a JSON object of
+	// connection secret keys, e.g. {"endpoint": "...", "username": "..."}.
+	// +optional
+	Env *xpv1.EnvSelector `json:"env,omitempty"`
+
+	// Fs is the path of the file or directory that holds the credentials
+	// when the source is Filesystem. A file holds a JSON object of connection
+	// secret keys. A directory, such as one mounted by the Secrets Store CSI
+	// driver or written by Vault Agent, holds a file named after each key.
+	// +optional
+	Fs *xpv1.FsSelector `json:"fs,omitempty"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
@@ -68,6 +170,15 @@ type ProviderConfig struct {
 	Status ProviderConfigStatus `json:"status,omitempty"`
 }
 
+// GetMaintenanceWindow returns the maintenance window of the ProviderConfig,
+// or an empty string if it has none.
+func (pc *ProviderConfig) GetMaintenanceWindow() string {
+	if pc.Spec.MaintenanceWindow == nil {
+		return ""
+	}
+	return *pc.Spec.MaintenanceWindow
+}
+
 // +kubebuilder:object:root=true
 
 // ProviderConfigList contains a list of ProviderConfig.
@@ -58,6 +58,14 @@ var (
 	ProviderConfigUsageListGroupVersionKind = SchemeGroupVersion.WithKind(ProviderConfigUsageListKind)
 )
 
+// StoreConfig type metadata.
+var (
+	StoreConfigKind             = reflect.TypeOf(StoreConfig{}).Name()
+	StoreConfigGroupKind        = schema.GroupKind{Group: Group, Kind: StoreConfigKind}.String()
+	StoreConfigKindAPIVersion   = StoreConfigKind + "." + SchemeGroupVersion.String()
+	StoreConfigGroupVersionKind = SchemeGroupVersion.WithKind(StoreConfigKind)
+)
+
 // Database type metadata.
 var (
 	DatabaseKind             = reflect.TypeOf(Database{}).Name()
@@ -82,10 +90,101 @@ var (
 	GrantGroupVersionKind = SchemeGroupVersion.WithKind(GrantKind)
 )
 
+// DatabaseScopedCredential type metadata.
+var (
+	DatabaseScopedCredentialKind             = reflect.TypeOf(DatabaseScopedCredential{}).Name()
+	DatabaseScopedCredentialGroupKind        = schema.GroupKind{Group: Group, Kind: DatabaseScopedCredentialKind}.String()
+	DatabaseScopedCredentialKindAPIVersion   = DatabaseScopedCredentialKind + "." + SchemeGroupVersion.String()
+	DatabaseScopedCredentialGroupVersionKind = SchemeGroupVersion.WithKind(DatabaseScopedCredentialKind)
+)
+
+// ExternalDataSource type metadata.
+var (
+	ExternalDataSourceKind             = reflect.TypeOf(ExternalDataSource{}).Name()
+	ExternalDataSourceGroupKind        = schema.GroupKind{Group: Group, Kind: ExternalDataSourceKind}.String()
+	ExternalDataSourceKindAPIVersion   = ExternalDataSourceKind + "." + SchemeGroupVersion.String()
+	ExternalDataSourceGroupVersionKind = SchemeGroupVersion.WithKind(ExternalDataSourceKind)
+)
+
+// LinkedServer type metadata.
+var (
+	LinkedServerKind             = reflect.TypeOf(LinkedServer{}).Name()
+	LinkedServerGroupKind        = schema.GroupKind{Group: Group, Kind: LinkedServerKind}.String()
+	LinkedServerKindAPIVersion   = LinkedServerKind + "." + SchemeGroupVersion.String()
+	LinkedServerGroupVersionKind = SchemeGroupVersion.WithKind(LinkedServerKind)
+)
+
+// DatabaseSnapshot type metadata.
+var (
+	DatabaseSnapshotKind             = reflect.TypeOf(DatabaseSnapshot{}).Name()
+	DatabaseSnapshotGroupKind        = schema.GroupKind{Group: Group, Kind: DatabaseSnapshotKind}.String()
+	DatabaseSnapshotKindAPIVersion   = DatabaseSnapshotKind + "." + SchemeGroupVersion.String()
+	DatabaseSnapshotGroupVersionKind = SchemeGroupVersion.WithKind(DatabaseSnapshotKind)
+)
+
+// ServerAudit type metadata.
+var (
+	ServerAuditKind             = reflect.TypeOf(ServerAudit{}).Name()
+	ServerAuditGroupKind        = schema.GroupKind{Group: Group, Kind: ServerAuditKind}.String()
+	ServerAuditKindAPIVersion   = ServerAuditKind + "." + SchemeGroupVersion.String()
+	ServerAuditGroupVersionKind = SchemeGroupVersion.WithKind(ServerAuditKind)
+)
+
+// DatabaseAuditSpecification type metadata.
+var (
+	DatabaseAuditSpecificationKind             = reflect.TypeOf(DatabaseAuditSpecification{}).Name()
+	DatabaseAuditSpecificationGroupKind        = schema.GroupKind{Group: Group, Kind: DatabaseAuditSpecificationKind}.String()
+	DatabaseAuditSpecificationKindAPIVersion   = DatabaseAuditSpecificationKind + "." + SchemeGroupVersion.String()
+	DatabaseAuditSpecificationGroupVersionKind = SchemeGroupVersion.WithKind(DatabaseAuditSpecificationKind)
+)
+
+// DatabaseLogin type metadata.
+var (
+	DatabaseLoginKind             = reflect.TypeOf(DatabaseLogin{}).Name()
+	DatabaseLoginGroupKind        = schema.GroupKind{Group: Group, Kind: DatabaseLoginKind}.String()
+	DatabaseLoginKindAPIVersion   = DatabaseLoginKind + "." + SchemeGroupVersion.String()
+	DatabaseLoginGroupVersionKind = SchemeGroupVersion.WithKind(DatabaseLoginKind)
+)
+
+// ApplicationRole type metadata.
+var (
+	ApplicationRoleKind             = reflect.TypeOf(ApplicationRole{}).Name()
+	ApplicationRoleGroupKind        = schema.GroupKind{Group: Group, Kind: ApplicationRoleKind}.String()
+	ApplicationRoleKindAPIVersion   = ApplicationRoleKind + "." + SchemeGroupVersion.String()
+	ApplicationRoleGroupVersionKind = SchemeGroupVersion.WithKind(ApplicationRoleKind)
+)
+
+// Sequence type metadata.
+var (
+	SequenceKind             = reflect.TypeOf(Sequence{}).Name()
+	SequenceGroupKind        = schema.GroupKind{Group: Group, Kind: SequenceKind}.String()
+	SequenceKindAPIVersion   = SequenceKind + "." + SchemeGroupVersion.String()
+	SequenceGroupVersionKind = SchemeGroupVersion.WithKind(SequenceKind)
+)
+
+// Synonym type metadata.
+var (
+	SynonymKind             = reflect.TypeOf(Synonym{}).Name()
+	SynonymGroupKind        = schema.GroupKind{Group: Group, Kind: SynonymKind}.String()
+	SynonymKindAPIVersion   = SynonymKind + "." + SchemeGroupVersion.String()
+	SynonymGroupVersionKind = SchemeGroupVersion.WithKind(SynonymKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
+	SchemeBuilder.Register(&StoreConfig{}, &StoreConfigList{})
 	SchemeBuilder.Register(&Database{}, &DatabaseList{})
 	SchemeBuilder.Register(&User{}, &UserList{})
 	SchemeBuilder.Register(&Grant{}, &GrantList{})
+	SchemeBuilder.Register(&DatabaseScopedCredential{}, &DatabaseScopedCredentialList{})
+	SchemeBuilder.Register(&ExternalDataSource{}, &ExternalDataSourceList{})
+	SchemeBuilder.Register(&LinkedServer{}, &LinkedServerList{})
+	SchemeBuilder.Register(&DatabaseSnapshot{}, &DatabaseSnapshotList{})
+	SchemeBuilder.Register(&ServerAudit{}, &ServerAuditList{})
+	SchemeBuilder.Register(&DatabaseAuditSpecification{}, &DatabaseAuditSpecificationList{})
+	SchemeBuilder.Register(&DatabaseLogin{}, &DatabaseLoginList{})
+	SchemeBuilder.Register(&ApplicationRole{}, &ApplicationRoleList{})
+	SchemeBuilder.Register(&Sequence{}, &SequenceList{})
+	SchemeBuilder.Register(&Synonym{}, &SynonymList{})
 }
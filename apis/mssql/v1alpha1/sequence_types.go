@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A SequenceSpec defines the desired state of a Sequence.
+type SequenceSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       SequenceParameters `json:"forProvider"`
+}
+
+// A SequenceStatus represents the observed state of a Sequence.
+type SequenceStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          SequenceObservation `json:"atProvider,omitempty"`
+}
+
+// SequenceParameters define the desired state of a MSSQL sequence. The
+// increment, bounds, cycling and cache of an existing sequence are altered
+// to match those that are set.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-sequence-transact-sql
+type SequenceParameters struct {
+	// Schema the sequence is created in. Defaults to dbo.
+	// +immutable
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// DataType of the sequence. Defaults to bigint.
+	// +kubebuilder:validation:Enum=tinyint;smallint;int;bigint
+	// +immutable
+	// +optional
+	DataType *string `json:"dataType,omitempty"`
+
+	// StartWith is the first value returned by the sequence. Defaults to
+	// the minValue of an ascending sequence, and the maxValue of a
+	// descending one.
+	// +immutable
+	// +optional
+	StartWith *int64 `json:"startWith,omitempty"`
+
+	// IncrementBy is the value added to the sequence for each value it
+	// returns. A negative increment makes a descending sequence. Defaults
+	// to 1.
+	// +optional
+	IncrementBy *int64 `json:"incrementBy,omitempty"`
+
+	// MinValue is the lowest value of the sequence. Defaults to the lowest
+	// value of its dataType.
+	// +optional
+	MinValue *int64 `json:"minValue,omitempty"`
+
+	// MaxValue is the highest value of the sequence. Defaults to the
+	// highest value of its dataType.
+	// +optional
+	MaxValue *int64 `json:"maxValue,omitempty"`
+
+	// Cycle restarts the sequence from its minValue, or the maxValue of a
+	// descending sequence, once it is exhausted. Defaults to false.
+	// +optional
+	Cycle *bool `json:"cycle,omitempty"`
+
+	// Cache is the number of values the server keeps in memory. 0 disables
+	// the cache. Unset leaves its size to the server.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Cache *int64 `json:"cache,omitempty"`
+
+	// Database the sequence is created in.
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the sequence is created
+	// in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the sequence is
+	// created in.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A SequenceObservation represents the observed state of a MSSQL sequence.
+type SequenceObservation struct {
+	// DataType of the sequence.
+	DataType string `json:"dataType,omitempty"`
+
+	// IncrementBy is the increment of the sequence.
+	IncrementBy int64 `json:"incrementBy,omitempty"`
+
+	// MinValue is the lowest value of the sequence.
+	MinValue int64 `json:"minValue,omitempty"`
+
+	// MaxValue is the highest value of the sequence.
+	MaxValue int64 `json:"maxValue,omitempty"`
+
+	// Cycle is true if the sequence restarts once it is exhausted.
+	Cycle bool `json:"cycle,omitempty"`
+
+	// Cache is the number of values the server keeps in memory, or 0 if
+	// the sequence is not cached. It is not reported if the server chooses
+	// the size of the cache.
+	Cache *int64 `json:"cache,omitempty"`
+
+	// CurrentValue is the last value returned by the sequence.
+	CurrentValue int64 `json:"currentValue,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Sequence represents the declarative state of a MSSQL sequence.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="CURRENT",type="integer",JSONPath=".status.atProvider.currentValue",priority=1
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Sequence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SequenceSpec   `json:"spec"`
+	Status SequenceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SequenceList contains a list of Sequence
+type SequenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Sequence `json:"items"`
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A ServerAuditSpec defines the desired state of a ServerAudit.
+type ServerAuditSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ServerAuditParameters `json:"forProvider"`
+}
+
+// A ServerAuditStatus represents the observed state of a ServerAudit.
+type ServerAuditStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          ServerAuditObservation `json:"atProvider,omitempty"`
+}
+
+// ServerAuditParameters define the desired state of a MSSQL server audit.
+// The audit is disabled while it is altered.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-server-audit-transact-sql
+// +kubebuilder:validation:XValidation:rule="self.destination != 'FILE' || has(self.filePath)",message="filePath is required for a FILE destination"
+type ServerAuditParameters struct {
+	// Destination of the audit records.
+	// +kubebuilder:validation:Enum=FILE;APPLICATION_LOG;SECURITY_LOG
+	Destination string `json:"destination"`
+
+	// FilePath is the directory the audit log files of a FILE destination
+	// are written to.
+	// +optional
+	FilePath *string `json:"filePath,omitempty"`
+
+	// MaxSizeMB is the maximum size of each audit log file of a FILE
+	// destination in megabytes. Log files are unlimited in size if it is
+	// unset or 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSizeMB *int64 `json:"maxSizeMB,omitempty"`
+
+	// MaxRolloverFiles is the maximum number of audit log files of a FILE
+	// destination that are retained. The number of files is unlimited if
+	// it is unset or 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRolloverFiles *int32 `json:"maxRolloverFiles,omitempty"`
+
+	// QueueDelay is the time in milliseconds that can elapse before audit
+	// actions are forced to be processed. 0 processes them synchronously.
+	// Defaults to 1000.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	QueueDelay *int32 `json:"queueDelay,omitempty"`
+
+	// OnFailure is what the server does when it can't write to the audit
+	// destination. Defaults to CONTINUE.
+	// +kubebuilder:validation:Enum=CONTINUE;SHUTDOWN;FAIL_OPERATION
+	// +optional
+	OnFailure *string `json:"onFailure,omitempty"`
+
+	// Enabled starts the audit. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// A ServerAuditObservation represents the observed state of a MSSQL server
+// audit.
+type ServerAuditObservation struct {
+	// AuditGUID is the GUID of the audit. Audits of the same GUID on the
+	// replicas of an availability group are needed for the database audit
+	// specifications of its databases to be applied after a failover.
+	AuditGUID string `json:"auditGUID,omitempty"`
+
+	// Enabled is true if the audit is started.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ServerAudit represents the declarative state of a MSSQL server audit.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DESTINATION",type="string",JSONPath=".spec.forProvider.destination"
+// +kubebuilder:printcolumn:name="ENABLED",type="boolean",JSONPath=".status.atProvider.enabled"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type ServerAudit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServerAuditSpec   `json:"spec"`
+	Status ServerAuditStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServerAuditList contains a list of ServerAudit
+type ServerAuditList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServerAudit `json:"items"`
+}
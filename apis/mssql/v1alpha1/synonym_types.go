@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A SynonymSpec defines the desired state of a Synonym.
+type SynonymSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       SynonymParameters `json:"forProvider"`
+}
+
+// A SynonymStatus represents the observed state of a Synonym.
+type SynonymStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          SynonymObservation `json:"atProvider,omitempty"`
+}
+
+// SynonymParameters define the desired state of a MSSQL synonym. A synonym
+// cannot be altered, so it is recreated when its target changes.
+// See https://learn.microsoft.com/en-us/sql/t-sql/statements/create-synonym-transact-sql
+// +kubebuilder:validation:XValidation:rule="!has(self.targetServer) || has(self.targetDatabase)",message="targetDatabase is required when targetServer is set"
+type SynonymParameters struct {
+	// Schema the synonym is created in. Defaults to dbo.
+	// +immutable
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// TargetServer is the linked server of the object the synonym refers
+	// to, if it is on another server.
+	// +optional
+	TargetServer *string `json:"targetServer,omitempty"`
+
+	// TargetDatabase is the database of the object the synonym refers to.
+	// Defaults to the database of the synonym.
+	// +optional
+	TargetDatabase *string `json:"targetDatabase,omitempty"`
+
+	// TargetSchema is the schema of the object the synonym refers to.
+	// Defaults to dbo.
+	// +optional
+	TargetSchema *string `json:"targetSchema,omitempty"`
+
+	// TargetObject is the name of the table, view, procedure or function the
+	// synonym refers to. It does not have to exist when the synonym is
+	// created.
+	TargetObject string `json:"targetObject"`
+
+	// Database the synonym is created in.
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object the synonym is created
+	// in.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database the synonym is
+	// created in.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A SynonymObservation represents the observed state of a MSSQL synonym.
+type SynonymObservation struct {
+	// BaseObjectName is the quoted name of the object the synonym refers
+	// to.
+	BaseObjectName string `json:"baseObjectName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Synonym represents the declarative state of a MSSQL synonym.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="TARGET",type="string",JSONPath=".status.atProvider.baseObjectName"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Synonym struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SynonymSpec   `json:"spec"`
+	Status SynonymStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SynonymList contains a list of Synonym
+type SynonymList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Synonym `json:"items"`
+}
@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A UserSpec defines the desired state of a Database.
@@ -31,6 +33,7 @@ type UserSpec struct {
 // A UserStatus represents the observed state of a User.
 type UserStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 	AtProvider          UserObservation `json:"atProvider,omitempty"`
 }
 
@@ -38,28 +41,94 @@ type UserStatus struct {
 type UserParameters struct {
 	// Database allows you to specify the name of the Database the USER is created for.
 	// +crossplane:generate:reference:type=Database
+	// +kubebuilder:validation:MaxLength=128
 	Database *string `json:"database,omitempty"`
 	// DatabaseRef allows you to specify custom resource name of the Database the USER is created for.
 	// to fill Database field.
 	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
 	// DatabaseSelector allows you to use selector constraints to select a Database the USER is created for.
 	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+	// Databases are additional databases the login is mapped to a user of
+	// the same name in, besides Database. Whether the user exists in each
+	// is reported in the status.
+	// +optional
+	Databases []string `json:"databases,omitempty"`
 	// PasswordSecretRef references the secret that contains the password used
 	// for this user. If no reference is given, a password will be auto-generated.
 	// +optional
 	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
 	// LoginDatabase allows you to specify the name of the Database to be used to create the user LOGIN in (normally master).
 	// +crossplane:generate:reference:type=Database
+	// +kubebuilder:validation:MaxLength=128
 	LoginDatabase *string `json:"loginDatabase,omitempty"`
 	// DatabaseRef allows you to specify custom resource name of the Database to be used to create the user LOGIN in (normally master).
 	// to fill Database field.
 	LoginDatabaseRef *xpv1.Reference `json:"loginDatabaseRef,omitempty"`
 	// DatabaseSelector allows you to use selector constraints to select a Database to be used to create the user LOGIN in (normally master).
 	LoginDatabaseSelector *xpv1.Selector `json:"loginDatabaseSelector,omitempty"`
+	// AdoptExisting records this resource as the owner of an existing user
+	// of the same name that records no owner, so that users created outside
+	// of the provider can be brought under management. The password of an
+	// adopted user is left untouched until a PasswordSecretRef is given.
+	// +optional
+	AdoptExisting *bool `json:"adoptExisting,omitempty"`
+	// ReleaseOwnershipOnDelete transfers the schemas owned by the user to
+	// SchemaOwnerOnDelete and removes the user from all database roles
+	// before it is dropped. Without it, deleting a user that owns schemas or
+	// is a member of roles fails.
+	// +optional
+	ReleaseOwnershipOnDelete *bool `json:"releaseOwnershipOnDelete,omitempty"`
+	// SchemaOwnerOnDelete is the principal that schemas owned by the user
+	// are transferred to when ReleaseOwnershipOnDelete is set.
+	// +optional
+	// +kubebuilder:default=dbo
+	SchemaOwnerOnDelete *string `json:"schemaOwnerOnDelete,omitempty"`
+	// LoginType is the kind of login the user is created for. SQL logins
+	// authenticate with a password. Windows logins map an Active Directory
+	// user or group, named DOMAIN\name, and are authenticated by Windows,
+	// so no password is generated and PasswordSecretRef is ignored.
+	// +kubebuilder:validation:Enum=SQL;Windows
+	// +optional
+	LoginType *string `json:"loginType,omitempty"`
+	// CheckPolicy enforces the Windows password policy of the server on
+	// the SQL login of the user. It is left as the server defaults it if
+	// unset, and ignored for Windows logins.
+	// +optional
+	CheckPolicy *bool `json:"checkPolicy,omitempty"`
+	// CheckExpiration enforces the password expiration policy of the server
+	// on the SQL login of the user. It requires CheckPolicy. It is left as
+	// the server defaults it if unset, and ignored for Windows logins.
+	// +optional
+	CheckExpiration *bool `json:"checkExpiration,omitempty"`
 }
 
 // A UserObservation represents the observed state of a MSSQL user.
 type UserObservation struct {
+	// LoginType is the type of the login of a Windows user as reported by
+	// sys.server_principals, i.e. WINDOWS_LOGIN or WINDOWS_GROUP.
+	LoginType string `json:"loginType,omitempty"`
+
+	// CheckPolicy is whether the password policy is enforced on the SQL
+	// login of the user, as reported by sys.sql_logins.
+	CheckPolicy *bool `json:"checkPolicy,omitempty"`
+
+	// CheckExpiration is whether the password expiration policy is enforced
+	// on the SQL login of the user, as reported by sys.sql_logins.
+	CheckExpiration *bool `json:"checkExpiration,omitempty"`
+
+	// Databases are the additional databases of the user, and whether the
+	// user exists in each of them.
+	Databases []UserDatabaseObservation `json:"databases,omitempty"`
+}
+
+// A UserDatabaseObservation represents the observed state of a user in one of
+// its additional databases.
+type UserDatabaseObservation struct {
+	// Name of the database.
+	Name string `json:"name"`
+
+	// Exists is whether the user exists in the database.
+	Exists bool `json:"exists"`
 }
 
 // +kubebuilder:object:root=true
@@ -22,9 +22,159 @@ package v1alpha1
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRole) DeepCopyInto(out *ApplicationRole) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRole.
+func (in *ApplicationRole) DeepCopy() *ApplicationRole {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRole) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRoleList) DeepCopyInto(out *ApplicationRoleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRoleList.
+func (in *ApplicationRoleList) DeepCopy() *ApplicationRoleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRoleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRoleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRoleObservation) DeepCopyInto(out *ApplicationRoleObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRoleObservation.
+func (in *ApplicationRoleObservation) DeepCopy() *ApplicationRoleObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRoleObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRoleParameters) DeepCopyInto(out *ApplicationRoleParameters) {
+	*out = *in
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.DefaultSchema != nil {
+		in, out := &in.DefaultSchema, &out.DefaultSchema
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRoleParameters.
+func (in *ApplicationRoleParameters) DeepCopy() *ApplicationRoleParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRoleParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRoleSpec) DeepCopyInto(out *ApplicationRoleSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRoleSpec.
+func (in *ApplicationRoleSpec) DeepCopy() *ApplicationRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRoleStatus) DeepCopyInto(out *ApplicationRoleStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRoleStatus.
+func (in *ApplicationRoleStatus) DeepCopy() *ApplicationRoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Database) DeepCopyInto(out *Database) {
 	*out = *in
@@ -52,6 +202,175 @@ func (in *Database) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecification) DeepCopyInto(out *DatabaseAuditSpecification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecification.
+func (in *DatabaseAuditSpecification) DeepCopy() *DatabaseAuditSpecification {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseAuditSpecification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecificationList) DeepCopyInto(out *DatabaseAuditSpecificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatabaseAuditSpecification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecificationList.
+func (in *DatabaseAuditSpecificationList) DeepCopy() *DatabaseAuditSpecificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseAuditSpecificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecificationObservation) DeepCopyInto(out *DatabaseAuditSpecificationObservation) {
+	*out = *in
+	if in.AuditActionGroups != nil {
+		in, out := &in.AuditActionGroups, &out.AuditActionGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecificationObservation.
+func (in *DatabaseAuditSpecificationObservation) DeepCopy() *DatabaseAuditSpecificationObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecificationObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecificationParameters) DeepCopyInto(out *DatabaseAuditSpecificationParameters) {
+	*out = *in
+	if in.AuditActionGroups != nil {
+		in, out := &in.AuditActionGroups, &out.AuditActionGroups
+		*out = make([]AuditActionGroup, len(*in))
+		copy(*out, *in)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ServerAudit != nil {
+		in, out := &in.ServerAudit, &out.ServerAudit
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerAuditRef != nil {
+		in, out := &in.ServerAuditRef, &out.ServerAuditRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerAuditSelector != nil {
+		in, out := &in.ServerAuditSelector, &out.ServerAuditSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecificationParameters.
+func (in *DatabaseAuditSpecificationParameters) DeepCopy() *DatabaseAuditSpecificationParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecificationParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecificationSpec) DeepCopyInto(out *DatabaseAuditSpecificationSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecificationSpec.
+func (in *DatabaseAuditSpecificationSpec) DeepCopy() *DatabaseAuditSpecificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuditSpecificationStatus) DeepCopyInto(out *DatabaseAuditSpecificationStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuditSpecificationStatus.
+func (in *DatabaseAuditSpecificationStatus) DeepCopy() *DatabaseAuditSpecificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuditSpecificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
 	*out = *in
@@ -66,58 +385,1551 @@ func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseList.
-func (in *DatabaseList) DeepCopy() *DatabaseList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseList.
+func (in *DatabaseList) DeepCopy() *DatabaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLogin) DeepCopyInto(out *DatabaseLogin) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLogin.
+func (in *DatabaseLogin) DeepCopy() *DatabaseLogin {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLogin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseLogin) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLoginList) DeepCopyInto(out *DatabaseLoginList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatabaseLogin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLoginList.
+func (in *DatabaseLoginList) DeepCopy() *DatabaseLoginList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLoginList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseLoginList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLoginObservation) DeepCopyInto(out *DatabaseLoginObservation) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLoginObservation.
+func (in *DatabaseLoginObservation) DeepCopy() *DatabaseLoginObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLoginObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLoginParameters) DeepCopyInto(out *DatabaseLoginParameters) {
+	*out = *in
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.DefaultSchema != nil {
+		in, out := &in.DefaultSchema, &out.DefaultSchema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLoginParameters.
+func (in *DatabaseLoginParameters) DeepCopy() *DatabaseLoginParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLoginParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLoginSpec) DeepCopyInto(out *DatabaseLoginSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLoginSpec.
+func (in *DatabaseLoginSpec) DeepCopy() *DatabaseLoginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLoginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseLoginStatus) DeepCopyInto(out *DatabaseLoginStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseLoginStatus.
+func (in *DatabaseLoginStatus) DeepCopy() *DatabaseLoginStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseLoginStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseObservation) DeepCopyInto(out *DatabaseObservation) {
+	*out = *in
+	if in.DatabaseScopedConfigurations != nil {
+		in, out := &in.DatabaseScopedConfigurations, &out.DatabaseScopedConfigurations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtendedProperties != nil {
+		in, out := &in.ExtendedProperties, &out.ExtendedProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SchemaExtendedProperties != nil {
+		in, out := &in.SchemaExtendedProperties, &out.SchemaExtendedProperties
+		*out = make(map[string]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseObservation.
+func (in *DatabaseObservation) DeepCopy() *DatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseParameters) DeepCopyInto(out *DatabaseParameters) {
+	*out = *in
+	if in.RestoreFromSnapshot != nil {
+		in, out := &in.RestoreFromSnapshot, &out.RestoreFromSnapshot
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseScopedConfigurations != nil {
+		in, out := &in.DatabaseScopedConfigurations, &out.DatabaseScopedConfigurations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtendedProperties != nil {
+		in, out := &in.ExtendedProperties, &out.ExtendedProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SchemaExtendedProperties != nil {
+		in, out := &in.SchemaExtendedProperties, &out.SchemaExtendedProperties
+		*out = make(map[string]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.TDEEnabled != nil {
+		in, out := &in.TDEEnabled, &out.TDEEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TDECertificate != nil {
+		in, out := &in.TDECertificate, &out.TDECertificate
+		*out = new(string)
+		**out = **in
+	}
+	if in.TDEAsymmetricKey != nil {
+		in, out := &in.TDEAsymmetricKey, &out.TDEAsymmetricKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.TDEAlgorithm != nil {
+		in, out := &in.TDEAlgorithm, &out.TDEAlgorithm
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseParameters.
+func (in *DatabaseParameters) DeepCopy() *DatabaseParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredential) DeepCopyInto(out *DatabaseScopedCredential) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredential.
+func (in *DatabaseScopedCredential) DeepCopy() *DatabaseScopedCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseScopedCredential) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredentialList) DeepCopyInto(out *DatabaseScopedCredentialList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatabaseScopedCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredentialList.
+func (in *DatabaseScopedCredentialList) DeepCopy() *DatabaseScopedCredentialList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredentialList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseScopedCredentialList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredentialObservation) DeepCopyInto(out *DatabaseScopedCredentialObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredentialObservation.
+func (in *DatabaseScopedCredentialObservation) DeepCopy() *DatabaseScopedCredentialObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredentialObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredentialParameters) DeepCopyInto(out *DatabaseScopedCredentialParameters) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredentialParameters.
+func (in *DatabaseScopedCredentialParameters) DeepCopy() *DatabaseScopedCredentialParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredentialParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredentialSpec) DeepCopyInto(out *DatabaseScopedCredentialSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredentialSpec.
+func (in *DatabaseScopedCredentialSpec) DeepCopy() *DatabaseScopedCredentialSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredentialSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseScopedCredentialStatus) DeepCopyInto(out *DatabaseScopedCredentialStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseScopedCredentialStatus.
+func (in *DatabaseScopedCredentialStatus) DeepCopy() *DatabaseScopedCredentialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseScopedCredentialStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshot) DeepCopyInto(out *DatabaseSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshot.
+func (in *DatabaseSnapshot) DeepCopy() *DatabaseSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshotList) DeepCopyInto(out *DatabaseSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatabaseSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshotList.
+func (in *DatabaseSnapshotList) DeepCopy() *DatabaseSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshotObservation) DeepCopyInto(out *DatabaseSnapshotObservation) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshotObservation.
+func (in *DatabaseSnapshotObservation) DeepCopy() *DatabaseSnapshotObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshotObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshotParameters) DeepCopyInto(out *DatabaseSnapshotParameters) {
+	*out = *in
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshotParameters.
+func (in *DatabaseSnapshotParameters) DeepCopy() *DatabaseSnapshotParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshotParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshotSpec) DeepCopyInto(out *DatabaseSnapshotSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshotSpec.
+func (in *DatabaseSnapshotSpec) DeepCopy() *DatabaseSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSnapshotStatus) DeepCopyInto(out *DatabaseSnapshotStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSnapshotStatus.
+func (in *DatabaseSnapshotStatus) DeepCopy() *DatabaseSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
+func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
+func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSource) DeepCopyInto(out *ExternalDataSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSource.
+func (in *ExternalDataSource) DeepCopy() *ExternalDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExternalDataSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSourceList) DeepCopyInto(out *ExternalDataSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ExternalDataSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSourceList.
+func (in *ExternalDataSourceList) DeepCopy() *ExternalDataSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExternalDataSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSourceObservation) DeepCopyInto(out *ExternalDataSourceObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSourceObservation.
+func (in *ExternalDataSourceObservation) DeepCopy() *ExternalDataSourceObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSourceObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSourceParameters) DeepCopyInto(out *ExternalDataSourceParameters) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseName != nil {
+		in, out := &in.DatabaseName, &out.DatabaseName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ShardMapName != nil {
+		in, out := &in.ShardMapName, &out.ShardMapName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(string)
+		**out = **in
+	}
+	if in.CredentialRef != nil {
+		in, out := &in.CredentialRef, &out.CredentialRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialSelector != nil {
+		in, out := &in.CredentialSelector, &out.CredentialSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSourceParameters.
+func (in *ExternalDataSourceParameters) DeepCopy() *ExternalDataSourceParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSourceParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSourceSpec) DeepCopyInto(out *ExternalDataSourceSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSourceSpec.
+func (in *ExternalDataSourceSpec) DeepCopy() *ExternalDataSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDataSourceStatus) DeepCopyInto(out *ExternalDataSourceStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDataSourceStatus.
+func (in *ExternalDataSourceStatus) DeepCopy() *ExternalDataSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDataSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Grant) DeepCopyInto(out *Grant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Grant.
+func (in *Grant) DeepCopy() *Grant {
+	if in == nil {
+		return nil
+	}
+	out := new(Grant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Grant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantList) DeepCopyInto(out *GrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Grant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantList.
+func (in *GrantList) DeepCopy() *GrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantParameters) DeepCopyInto(out *GrantParameters) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make(GrantPermissions, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.OnUser != nil {
+		in, out := &in.OnUser, &out.OnUser
+		*out = new(string)
+		**out = **in
+	}
+	if in.OnUserRef != nil {
+		in, out := &in.OnUserRef, &out.OnUserRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnUserSelector != nil {
+		in, out := &in.OnUserSelector, &out.OnUserSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+	if in.UserRef != nil {
+		in, out := &in.UserRef, &out.UserRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserSelector != nil {
+		in, out := &in.UserSelector, &out.UserSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantParameters.
+func (in *GrantParameters) DeepCopy() *GrantParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in GrantPermissions) DeepCopyInto(out *GrantPermissions) {
+	{
+		in := &in
+		*out = make(GrantPermissions, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantPermissions.
+func (in GrantPermissions) DeepCopy() GrantPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantPermissions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantSpec) DeepCopyInto(out *GrantSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantSpec.
+func (in *GrantSpec) DeepCopy() *GrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantStatus) DeepCopyInto(out *GrantStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantStatus.
+func (in *GrantStatus) DeepCopy() *GrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServer) DeepCopyInto(out *LinkedServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServer.
+func (in *LinkedServer) DeepCopy() *LinkedServer {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LinkedServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServerList) DeepCopyInto(out *LinkedServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LinkedServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServerList.
+func (in *LinkedServerList) DeepCopy() *LinkedServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LinkedServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServerObservation) DeepCopyInto(out *LinkedServerObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServerObservation.
+func (in *LinkedServerObservation) DeepCopy() *LinkedServerObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServerObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServerParameters) DeepCopyInto(out *LinkedServerParameters) {
+	*out = *in
+	if in.Product != nil {
+		in, out := &in.Product, &out.Product
+		*out = new(string)
+		**out = **in
+	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(string)
+		**out = **in
+	}
+	if in.DataSource != nil {
+		in, out := &in.DataSource, &out.DataSource
+		*out = new(string)
+		**out = **in
+	}
+	if in.Catalog != nil {
+		in, out := &in.Catalog, &out.Catalog
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProviderString != nil {
+		in, out := &in.ProviderString, &out.ProviderString
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemoteUser != nil {
+		in, out := &in.RemoteUser, &out.RemoteUser
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemotePasswordSecretRef != nil {
+		in, out := &in.RemotePasswordSecretRef, &out.RemotePasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServerParameters.
+func (in *LinkedServerParameters) DeepCopy() *LinkedServerParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServerParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServerSpec) DeepCopyInto(out *LinkedServerSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServerSpec.
+func (in *LinkedServerSpec) DeepCopy() *LinkedServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedServerStatus) DeepCopyInto(out *LinkedServerStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedServerStatus.
+func (in *LinkedServerStatus) DeepCopy() *LinkedServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfig.
+func (in *ProviderConfig) DeepCopy() *ProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigList) DeepCopyInto(out *ProviderConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProviderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigList.
+func (in *ProviderConfigList) DeepCopy() *ProviderConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
+	*out = *in
+	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.ApplicationIntent != nil {
+		in, out := &in.ApplicationIntent, &out.ApplicationIntent
+		*out = new(string)
+		**out = **in
+	}
+	if in.MultiSubnetFailover != nil {
+		in, out := &in.MultiSubnetFailover, &out.MultiSubnetFailover
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailoverPartner != nil {
+		in, out := &in.FailoverPartner, &out.FailoverPartner
+		*out = new(string)
+		**out = **in
+	}
+	if in.Encrypt != nil {
+		in, out := &in.Encrypt, &out.Encrypt
+		*out = new(string)
+		**out = **in
+	}
+	if in.DialTimeout != nil {
+		in, out := &in.DialTimeout, &out.DialTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ConnectionTimeout != nil {
+		in, out := &in.ConnectionTimeout, &out.ConnectionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ConnectionOptions != nil {
+		in, out := &in.ConnectionOptions, &out.ConnectionOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxOpenConnections != nil {
+		in, out := &in.MaxOpenConnections, &out.MaxOpenConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxIdleConnections != nil {
+		in, out := &in.MaxIdleConnections, &out.MaxIdleConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
+func (in *ProviderConfigSpec) DeepCopy() *ProviderConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigStatus) DeepCopyInto(out *ProviderConfigStatus) {
+	*out = *in
+	in.ProviderConfigStatus.DeepCopyInto(&out.ProviderConfigStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigStatus.
+func (in *ProviderConfigStatus) DeepCopy() *ProviderConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigUsage) DeepCopyInto(out *ProviderConfigUsage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.ProviderConfigUsage.DeepCopyInto(&out.ProviderConfigUsage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigUsage.
+func (in *ProviderConfigUsage) DeepCopy() *ProviderConfigUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderConfigUsage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigUsageList) DeepCopyInto(out *ProviderConfigUsageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProviderConfigUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigUsageList.
+func (in *ProviderConfigUsageList) DeepCopy() *ProviderConfigUsageList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigUsageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderConfigUsageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderCredentials) DeepCopyInto(out *ProviderCredentials) {
+	*out = *in
+	if in.ConnectionSecretRef != nil {
+		in, out := &in.ConnectionSecretRef, &out.ConnectionSecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = new(v1.EnvSelector)
+		**out = **in
+	}
+	if in.Fs != nil {
+		in, out := &in.Fs, &out.Fs
+		*out = new(v1.FsSelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderCredentials.
+func (in *ProviderCredentials) DeepCopy() *ProviderCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sequence) DeepCopyInto(out *Sequence) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sequence.
+func (in *Sequence) DeepCopy() *Sequence {
+	if in == nil {
+		return nil
+	}
+	out := new(Sequence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Sequence) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SequenceList) DeepCopyInto(out *SequenceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Sequence, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SequenceList.
+func (in *SequenceList) DeepCopy() *SequenceList {
+	if in == nil {
+		return nil
+	}
+	out := new(SequenceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SequenceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SequenceObservation) DeepCopyInto(out *SequenceObservation) {
+	*out = *in
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SequenceObservation.
+func (in *SequenceObservation) DeepCopy() *SequenceObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SequenceObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SequenceParameters) DeepCopyInto(out *SequenceParameters) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.DataType != nil {
+		in, out := &in.DataType, &out.DataType
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartWith != nil {
+		in, out := &in.StartWith, &out.StartWith
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IncrementBy != nil {
+		in, out := &in.IncrementBy, &out.IncrementBy
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinValue != nil {
+		in, out := &in.MinValue, &out.MinValue
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxValue != nil {
+		in, out := &in.MaxValue, &out.MaxValue
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Cycle != nil {
+		in, out := &in.Cycle, &out.Cycle
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SequenceParameters.
+func (in *SequenceParameters) DeepCopy() *SequenceParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseList)
+	out := new(SequenceParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DatabaseList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
+func (in *SequenceSpec) DeepCopyInto(out *SequenceSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
-func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SequenceSpec.
+func (in *SequenceSpec) DeepCopy() *SequenceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseSpec)
+	out := new(SequenceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
+func (in *SequenceStatus) DeepCopyInto(out *SequenceStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
-func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SequenceStatus.
+func (in *SequenceStatus) DeepCopy() *SequenceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseStatus)
+	out := new(SequenceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Grant) DeepCopyInto(out *Grant) {
+func (in *ServerAudit) DeepCopyInto(out *ServerAudit) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -125,18 +1937,18 @@ func (in *Grant) DeepCopyInto(out *Grant) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Grant.
-func (in *Grant) DeepCopy() *Grant {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAudit.
+func (in *ServerAudit) DeepCopy() *ServerAudit {
 	if in == nil {
 		return nil
 	}
-	out := new(Grant)
+	out := new(ServerAudit)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Grant) DeepCopyObject() runtime.Object {
+func (in *ServerAudit) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -144,31 +1956,31 @@ func (in *Grant) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantList) DeepCopyInto(out *GrantList) {
+func (in *ServerAuditList) DeepCopyInto(out *ServerAuditList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Grant, len(*in))
+		*out = make([]ServerAudit, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantList.
-func (in *GrantList) DeepCopy() *GrantList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuditList.
+func (in *ServerAuditList) DeepCopy() *ServerAuditList {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantList)
+	out := new(ServerAuditList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrantList) DeepCopyObject() runtime.Object {
+func (in *ServerAuditList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -176,114 +1988,102 @@ func (in *GrantList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantParameters) DeepCopyInto(out *GrantParameters) {
+func (in *ServerAuditObservation) DeepCopyInto(out *ServerAuditObservation) {
 	*out = *in
-	if in.Permissions != nil {
-		in, out := &in.Permissions, &out.Permissions
-		*out = make(GrantPermissions, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuditObservation.
+func (in *ServerAuditObservation) DeepCopy() *ServerAuditObservation {
+	if in == nil {
+		return nil
 	}
-	if in.Schema != nil {
-		in, out := &in.Schema, &out.Schema
+	out := new(ServerAuditObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerAuditParameters) DeepCopyInto(out *ServerAuditParameters) {
+	*out = *in
+	if in.FilePath != nil {
+		in, out := &in.FilePath, &out.FilePath
 		*out = new(string)
 		**out = **in
 	}
-	if in.User != nil {
-		in, out := &in.User, &out.User
-		*out = new(string)
+	if in.MaxSizeMB != nil {
+		in, out := &in.MaxSizeMB, &out.MaxSizeMB
+		*out = new(int64)
 		**out = **in
 	}
-	if in.UserRef != nil {
-		in, out := &in.UserRef, &out.UserRef
-		*out = new(v1.Reference)
-		(*in).DeepCopyInto(*out)
+	if in.MaxRolloverFiles != nil {
+		in, out := &in.MaxRolloverFiles, &out.MaxRolloverFiles
+		*out = new(int32)
+		**out = **in
 	}
-	if in.UserSelector != nil {
-		in, out := &in.UserSelector, &out.UserSelector
-		*out = new(v1.Selector)
-		(*in).DeepCopyInto(*out)
+	if in.QueueDelay != nil {
+		in, out := &in.QueueDelay, &out.QueueDelay
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Database != nil {
-		in, out := &in.Database, &out.Database
+	if in.OnFailure != nil {
+		in, out := &in.OnFailure, &out.OnFailure
 		*out = new(string)
 		**out = **in
 	}
-	if in.DatabaseRef != nil {
-		in, out := &in.DatabaseRef, &out.DatabaseRef
-		*out = new(v1.Reference)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.DatabaseSelector != nil {
-		in, out := &in.DatabaseSelector, &out.DatabaseSelector
-		*out = new(v1.Selector)
-		(*in).DeepCopyInto(*out)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantParameters.
-func (in *GrantParameters) DeepCopy() *GrantParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuditParameters.
+func (in *ServerAuditParameters) DeepCopy() *ServerAuditParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantParameters)
+	out := new(ServerAuditParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in GrantPermissions) DeepCopyInto(out *GrantPermissions) {
-	{
-		in := &in
-		*out = make(GrantPermissions, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantPermissions.
-func (in GrantPermissions) DeepCopy() GrantPermissions {
-	if in == nil {
-		return nil
-	}
-	out := new(GrantPermissions)
-	in.DeepCopyInto(out)
-	return *out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantSpec) DeepCopyInto(out *GrantSpec) {
+func (in *ServerAuditSpec) DeepCopyInto(out *ServerAuditSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantSpec.
-func (in *GrantSpec) DeepCopy() *GrantSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuditSpec.
+func (in *ServerAuditSpec) DeepCopy() *ServerAuditSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantSpec)
+	out := new(ServerAuditSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantStatus) DeepCopyInto(out *GrantStatus) {
+func (in *ServerAuditStatus) DeepCopyInto(out *ServerAuditStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantStatus.
-func (in *GrantStatus) DeepCopy() *GrantStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuditStatus.
+func (in *ServerAuditStatus) DeepCopy() *ServerAuditStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantStatus)
+	out := new(ServerAuditStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
+func (in *StoreConfig) DeepCopyInto(out *StoreConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -291,18 +2091,18 @@ func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfig.
-func (in *ProviderConfig) DeepCopy() *ProviderConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfig.
+func (in *StoreConfig) DeepCopy() *StoreConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfig)
+	out := new(StoreConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProviderConfig) DeepCopyObject() runtime.Object {
+func (in *StoreConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -310,31 +2110,31 @@ func (in *ProviderConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfigList) DeepCopyInto(out *ProviderConfigList) {
+func (in *StoreConfigList) DeepCopyInto(out *StoreConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ProviderConfig, len(*in))
+		*out = make([]StoreConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigList.
-func (in *ProviderConfigList) DeepCopy() *ProviderConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigList.
+func (in *StoreConfigList) DeepCopy() *StoreConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfigList)
+	out := new(StoreConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
+func (in *StoreConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -342,57 +2142,58 @@ func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
+func (in *StoreConfigSpec) DeepCopyInto(out *StoreConfigSpec) {
 	*out = *in
-	in.Credentials.DeepCopyInto(&out.Credentials)
+	in.SecretStoreConfig.DeepCopyInto(&out.SecretStoreConfig)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
-func (in *ProviderConfigSpec) DeepCopy() *ProviderConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigSpec.
+func (in *StoreConfigSpec) DeepCopy() *StoreConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfigSpec)
+	out := new(StoreConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfigStatus) DeepCopyInto(out *ProviderConfigStatus) {
+func (in *StoreConfigStatus) DeepCopyInto(out *StoreConfigStatus) {
 	*out = *in
-	in.ProviderConfigStatus.DeepCopyInto(&out.ProviderConfigStatus)
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigStatus.
-func (in *ProviderConfigStatus) DeepCopy() *ProviderConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigStatus.
+func (in *StoreConfigStatus) DeepCopy() *StoreConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfigStatus)
+	out := new(StoreConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfigUsage) DeepCopyInto(out *ProviderConfigUsage) {
+func (in *Synonym) DeepCopyInto(out *Synonym) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.ProviderConfigUsage.DeepCopyInto(&out.ProviderConfigUsage)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigUsage.
-func (in *ProviderConfigUsage) DeepCopy() *ProviderConfigUsage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Synonym.
+func (in *Synonym) DeepCopy() *Synonym {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfigUsage)
+	out := new(Synonym)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProviderConfigUsage) DeepCopyObject() runtime.Object {
+func (in *Synonym) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -400,31 +2201,31 @@ func (in *ProviderConfigUsage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderConfigUsageList) DeepCopyInto(out *ProviderConfigUsageList) {
+func (in *SynonymList) DeepCopyInto(out *SynonymList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ProviderConfigUsage, len(*in))
+		*out = make([]Synonym, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigUsageList.
-func (in *ProviderConfigUsageList) DeepCopy() *ProviderConfigUsageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymList.
+func (in *SynonymList) DeepCopy() *SynonymList {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderConfigUsageList)
+	out := new(SynonymList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProviderConfigUsageList) DeepCopyObject() runtime.Object {
+func (in *SynonymList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -432,21 +2233,101 @@ func (in *ProviderConfigUsageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProviderCredentials) DeepCopyInto(out *ProviderCredentials) {
+func (in *SynonymObservation) DeepCopyInto(out *SynonymObservation) {
 	*out = *in
-	if in.ConnectionSecretRef != nil {
-		in, out := &in.ConnectionSecretRef, &out.ConnectionSecretRef
-		*out = new(v1.SecretReference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymObservation.
+func (in *SynonymObservation) DeepCopy() *SynonymObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SynonymObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynonymParameters) DeepCopyInto(out *SynonymParameters) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetServer != nil {
+		in, out := &in.TargetServer, &out.TargetServer
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetDatabase != nil {
+		in, out := &in.TargetDatabase, &out.TargetDatabase
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetSchema != nil {
+		in, out := &in.TargetSchema, &out.TargetSchema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
 		**out = **in
 	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderCredentials.
-func (in *ProviderCredentials) DeepCopy() *ProviderCredentials {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymParameters.
+func (in *SynonymParameters) DeepCopy() *SynonymParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(ProviderCredentials)
+	out := new(SynonymParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynonymSpec) DeepCopyInto(out *SynonymSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymSpec.
+func (in *SynonymSpec) DeepCopy() *SynonymSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SynonymSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynonymStatus) DeepCopyInto(out *SynonymStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymStatus.
+func (in *SynonymStatus) DeepCopy() *SynonymStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SynonymStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -478,6 +2359,21 @@ func (in *User) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDatabaseObservation) DeepCopyInto(out *UserDatabaseObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDatabaseObservation.
+func (in *UserDatabaseObservation) DeepCopy() *UserDatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserList) DeepCopyInto(out *UserList) {
 	*out = *in
@@ -513,6 +2409,21 @@ func (in *UserList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserObservation) DeepCopyInto(out *UserObservation) {
 	*out = *in
+	if in.CheckPolicy != nil {
+		in, out := &in.CheckPolicy, &out.CheckPolicy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CheckExpiration != nil {
+		in, out := &in.CheckExpiration, &out.CheckExpiration
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]UserDatabaseObservation, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserObservation.
@@ -543,6 +2454,11 @@ func (in *UserParameters) DeepCopyInto(out *UserParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.PasswordSecretRef != nil {
 		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
 		*out = new(v1.SecretKeySelector)
@@ -563,6 +2479,36 @@ func (in *UserParameters) DeepCopyInto(out *UserParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdoptExisting != nil {
+		in, out := &in.AdoptExisting, &out.AdoptExisting
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReleaseOwnershipOnDelete != nil {
+		in, out := &in.ReleaseOwnershipOnDelete, &out.ReleaseOwnershipOnDelete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SchemaOwnerOnDelete != nil {
+		in, out := &in.SchemaOwnerOnDelete, &out.SchemaOwnerOnDelete
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoginType != nil {
+		in, out := &in.LoginType, &out.LoginType
+		*out = new(string)
+		**out = **in
+	}
+	if in.CheckPolicy != nil {
+		in, out := &in.CheckPolicy, &out.CheckPolicy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CheckExpiration != nil {
+		in, out := &in.CheckExpiration, &out.CheckExpiration
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserParameters.
@@ -596,7 +2542,8 @@ func (in *UserSpec) DeepCopy() *UserSpec {
 func (in *UserStatus) DeepCopyInto(out *UserStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	out.AtProvider = in.AtProvider
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserStatus.
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// Privilege profiles of an ApplicationAccount.
+const (
+	// ProfileReadOnly allows reading the tables and views of the database.
+	ProfileReadOnly = "readonly"
+
+	// ProfileReadWrite allows reading and writing the data of the database,
+	// but not changing its schema.
+	ProfileReadWrite = "readwrite"
+
+	// ProfileAdmin allows all privileges on the database, except granting
+	// them to other accounts.
+	ProfileAdmin = "admin"
+)
+
+// ApplicationAccountParameters define the desired state of a MySQL database
+// together with the user an application connects to it as.
+type ApplicationAccountParameters struct {
+	// User is the account that is created for the application, in user@host
+	// form. The host defaults to %. Defaults to the external name of the
+	// ApplicationAccount.
+	// +immutable
+	// +optional
+	User *string `json:"user,omitempty"`
+
+	// PasswordSecretRef references the secret that contains the password of
+	// the user. If no reference is given, a password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// Profile is the set of privileges the user is granted on the database:
+	// readonly, readwrite, which also allows modifying data but not the
+	// schema, or admin, which allows all privileges. Defaults to readwrite.
+	// +kubebuilder:validation:Enum=readonly;readwrite;admin
+	// +kubebuilder:default=readwrite
+	// +optional
+	Profile *string `json:"profile,omitempty"`
+
+	// CharacterSet is the default character set of the database. Defaults
+	// to utf8mb4.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_]+$
+	// +kubebuilder:default=utf8mb4
+	// +optional
+	CharacterSet *string `json:"characterSet,omitempty"`
+
+	// Collation is the default collation of the database. Defaults to the
+	// default collation of the character set.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_]+$
+	// +optional
+	Collation *string `json:"collation,omitempty"`
+
+	// BinLog defines whether the create, delete, update operations of this
+	// account are propagated to replicas. Defaults to true.
+	// +optional
+	BinLog *bool `json:"binlog,omitempty"`
+}
+
+// An ApplicationAccountSpec defines the desired state of an
+// ApplicationAccount.
+type ApplicationAccountSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ApplicationAccountParameters `json:"forProvider"`
+}
+
+// An ApplicationAccountStatus represents the observed state of an
+// ApplicationAccount.
+type ApplicationAccountStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          ApplicationAccountObservation `json:"atProvider,omitempty"`
+}
+
+// An ApplicationAccountObservation represents the observed state of an
+// ApplicationAccount.
+type ApplicationAccountObservation struct {
+	// CharacterSet is the default character set of the database.
+	CharacterSet string `json:"characterSet,omitempty"`
+
+	// Collation is the default collation of the database.
+	Collation string `json:"collation,omitempty"`
+
+	// Privileges are the privileges the user currently holds on the
+	// database.
+	Privileges []string `json:"privileges,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An ApplicationAccount represents the declarative state of a MySQL database,
+// a user an application connects to it as and the privileges of that user on
+// the database. Its connection secret holds the username, password, endpoint,
+// port and database of the user.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="PROFILE",type="string",JSONPath=".spec.forProvider.profile"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type ApplicationAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationAccountSpec   `json:"spec"`
+	Status ApplicationAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationAccountList contains a list of ApplicationAccount
+type ApplicationAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationAccount `json:"items"`
+}
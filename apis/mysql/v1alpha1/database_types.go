@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A DatabaseSpec defines the desired state of a Database.
@@ -31,13 +33,63 @@ type DatabaseSpec struct {
 // A DatabaseStatus represents the observed state of a Database.
 type DatabaseStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseObservation represents the observed state of a MySQL database.
+type DatabaseObservation struct {
+	// CharacterSet is the default character set of the database.
+	CharacterSet string `json:"characterSet,omitempty"`
+
+	// Collation is the default collation of the database.
+	Collation string `json:"collation,omitempty"`
+
+	// SizeBytes is the disk space used by the data and indexes of the tables
+	// in the database.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// DefaultEncryption is whether tables in the database are encrypted by
+	// default. It is only observed when defaultEncryption is set.
+	DefaultEncryption *bool `json:"defaultEncryption,omitempty"`
+
+	// ReadOnly is whether the database is read only. It is only observed
+	// when readOnly is set.
+	ReadOnly *bool `json:"readOnly,omitempty"`
 }
 
 // DatabaseParameters define the desired state of a MySQL database instance.
 type DatabaseParameters struct {
-	// BinLog defines whether the create, delete, update operations of this database are propagated to replicas. Defaults to true
+	// BinLog defines whether the create, delete, update operations of this database are propagated to replicas. Defaults to true.
+	// Setting it to false runs the statements of this database with sql_log_bin=0,
+	// excluding them from replication without a separate ProviderConfig.
+	// This requires the SUPER or SYSTEM_VARIABLES_ADMIN privilege.
 	// +optional
 	BinLog *bool `json:"binlog,omitempty"`
+
+	// CharacterSet is the default character set of the database, e.g.
+	// utf8mb4. Defaults to the server character set.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_]+$
+	// +optional
+	CharacterSet *string `json:"characterSet,omitempty"`
+
+	// Collation is the default collation of the database, e.g.
+	// utf8mb4_0900_ai_ci. Defaults to the default collation of the
+	// character set.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_]+$
+	// +optional
+	Collation *string `json:"collation,omitempty"`
+
+	// DefaultEncryption controls whether tables created in the database are
+	// encrypted by default. Requires MySQL 8.0.16 or later.
+	// +optional
+	DefaultEncryption *bool `json:"defaultEncryption,omitempty"`
+
+	// ReadOnly controls whether the database and its objects can be
+	// modified. A database can only be made read only after it was created.
+	// Requires MySQL 8.0.22 or later.
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -47,6 +99,9 @@ type DatabaseParameters struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="CHARSET",type="string",JSONPath=".status.atProvider.characterSet"
+// +kubebuilder:printcolumn:name="COLLATION",type="string",JSONPath=".status.atProvider.collation"
+// +kubebuilder:printcolumn:name="SIZE",type="integer",JSONPath=".status.atProvider.sizeBytes",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
 type Database struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -25,6 +25,8 @@ import (
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reference"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A GrantSpec defines the desired state of a Grant.
@@ -56,10 +58,17 @@ func (gp *GrantPrivileges) ToStringSlice() []string {
 }
 
 // GrantParameters define the desired state of a MySQL grant instance.
+// +kubebuilder:validation:XValidation:rule="has(self.privileges) != has(self.privilegeSet)",message="exactly one of privileges or privilegeSet must be set"
 type GrantParameters struct {
 	// Privileges to be granted.
 	// See https://mariadb.com/kb/en/grant/#database-privileges for available privileges.
-	Privileges GrantPrivileges `json:"privileges"`
+	// +optional
+	Privileges GrantPrivileges `json:"privileges,omitempty"`
+
+	// PrivilegeSet is the name of a set of privileges, defined in the
+	// privilegeSets of the ProviderConfig, to grant instead of privileges.
+	// +optional
+	PrivilegeSet *string `json:"privilegeSet,omitempty"`
 
 	// User this grant is for.
 	// +optional
@@ -93,7 +102,27 @@ type GrantParameters struct {
 	// +optional
 	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
 
-	// BinLog defines whether the create, delete, update operations of this grant are propagated to replicas. Defaults to true
+	// DatabasePattern this grant is for instead of a single database, e.g.
+	// app\_% for all databases whose name starts with app_. The wildcards _
+	// and % match any single and any number of characters; escape them with
+	// a backslash to match them literally. It cannot be set together with
+	// database, and the grant must be for all tables.
+	// See https://dev.mysql.com/doc/refman/8.0/en/grant.html#grant-quoting
+	// +kubebuilder:validation:MinLength:=1
+	// +optional
+	DatabasePattern *string `json:"databasePattern,omitempty"`
+
+	// Restrictions lists the databases on which the privileges of a global
+	// grant are partially revoked. This requires the partial_revokes system
+	// variable to be enabled and can only be used when database is *.
+	// See https://dev.mysql.com/doc/refman/8.0/en/partial-revokes.html
+	// +optional
+	Restrictions []string `json:"restrictions,omitempty"`
+
+	// BinLog defines whether the create, delete, update operations of this grant are propagated to replicas. Defaults to true.
+	// Setting it to false runs the statements of this grant with sql_log_bin=0,
+	// excluding them from replication without a separate ProviderConfig.
+	// This requires the SUPER or SYSTEM_VARIABLES_ADMIN privilege.
 	// +optional
 	BinLog *bool `json:"binlog,omitempty"`
 }
@@ -101,6 +130,7 @@ type GrantParameters struct {
 // A GrantStatus represents the observed state of a Grant.
 type GrantStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 	AtProvider          GrantObservation `json:"atProvider,omitempty"`
 }
 
@@ -108,6 +138,10 @@ type GrantStatus struct {
 type GrantObservation struct {
 	// Privileges represents the applied privileges
 	Privileges []string `json:"privileges,omitempty"`
+
+	// Restrictions represents the databases on which the applied privileges
+	// are partially revoked
+	Restrictions []string `json:"restrictions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// HealthCheckParameters are the configurable fields of a HealthCheck.
+type HealthCheckParameters struct {
+	// Query is a read-only SELECT statement run on every check. Tables must
+	// be qualified with their database name. The query is run as a derived
+	// table, so it must be a single statement without a trailing semicolon.
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+
+	// MinRows is the minimum number of rows the query must return for the
+	// check to pass. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinRows *int64 `json:"minRows,omitempty"`
+
+	// Interval between checks. Defaults to the provider poll interval.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// A HealthCheckObservation represents the result of the last check.
+type HealthCheckObservation struct {
+	// LastCheckTime is when the query was last run.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// LatencyMilliseconds is how long the last check took to complete.
+	LatencyMilliseconds *int64 `json:"latencyMilliseconds,omitempty"`
+
+	// RowCount is the number of rows returned by the last successful check.
+	RowCount *int64 `json:"rowCount,omitempty"`
+}
+
+// A HealthCheckSpec defines the desired state of a HealthCheck.
+type HealthCheckSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       HealthCheckParameters `json:"forProvider"`
+}
+
+// A HealthCheckStatus represents the observed state of a HealthCheck.
+type HealthCheckStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          HealthCheckObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A HealthCheck periodically runs a read-only query and reports whether it
+// passed. It becomes Ready only while the query succeeds, so it can be used
+// as a readiness gate in compositions.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ROWS",type="integer",JSONPath=".status.atProvider.rowCount"
+// +kubebuilder:printcolumn:name="LATENCY-MS",type="integer",JSONPath=".status.atProvider.latencyMilliseconds"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type HealthCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HealthCheckSpec   `json:"spec"`
+	Status HealthCheckStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HealthCheckList contains a list of HealthCheck
+type HealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HealthCheck `json:"items"`
+}
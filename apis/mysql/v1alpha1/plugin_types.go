@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A PluginSpec defines the desired state of a Plugin.
+type PluginSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       PluginParameters `json:"forProvider"`
+}
+
+// PluginParameters define the desired state of a MySQL server plugin. The
+// name of the plugin is the external name of the resource.
+// See https://dev.mysql.com/doc/refman/8.0/en/install-plugin.html
+type PluginParameters struct {
+	// Soname is the shared library file of the plugin in the plugin
+	// directory of the server, e.g. audit_log.so. Changing it reinstalls the
+	// plugin from the new library.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_.-]+$
+	Soname string `json:"soname"`
+}
+
+// A PluginObservation represents the observed state of a MySQL server plugin.
+type PluginObservation struct {
+	// Status of the plugin, e.g. ACTIVE or DISABLED.
+	Status string `json:"status,omitempty"`
+
+	// Version of the plugin.
+	Version string `json:"version,omitempty"`
+
+	// Library the plugin was loaded from. It is empty for plugins that are
+	// built into the server.
+	Library string `json:"library,omitempty"`
+}
+
+// A PluginStatus represents the observed state of a Plugin.
+type PluginStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          PluginObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Plugin represents the declarative state of a plugin installed in a MySQL
+// server with INSTALL PLUGIN, such as audit_log or validate_password.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.atProvider.status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.atProvider.version",priority=1
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Plugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PluginSpec   `json:"spec"`
+	Status PluginStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PluginList contains a list of Plugin
+type PluginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Plugin `json:"items"`
+}
@@ -39,14 +39,102 @@ type ProviderConfigSpec struct {
 	// Optional TLS configuration for sql driver. Setting this field also requires the tls field to be set to custom.
 	// +optional
 	TLSConfig *TLSConfig `json:"tlsConfig"`
+
+	// ConnectionOptions are additional driver parameters that are appended
+	// to the connection string, for example readTimeout or charset.
+	// Supported options are allowCleartextPasswords, allowNativePasswords,
+	// charset, collation, interpolateParams, loc, maxAllowedPacket,
+	// parseTime, readTimeout, timeout and writeTimeout.
+	// +optional
+	ConnectionOptions map[string]string `json:"connectionOptions,omitempty"`
+
+	// Flavor is the kind of server the provider connects to. rds and aurora
+	// are Amazon RDS and Aurora, whose master user cannot grant privileges
+	// such as SUPER and FILE. Grants ignore such privileges on them, and a
+	// grant of ALL is up to date without them. tidb is TiDB, self-hosted or
+	// TiDB Cloud, which additionally does not support the resource options
+	// of Users other than maxUserConnections, nor their proxyOf. Users
+	// ignore those resource options on it. Unset is vanilla, which lets
+	// every privilege be granted.
+	// +kubebuilder:validation:Enum=vanilla;rds;aurora;tidb
+	// +optional
+	Flavor *string `json:"flavor,omitempty"`
+
+	// MaxOpenConnections bounds the number of connections the provider has
+	// open to the server at once, across all of the managed resources that
+	// use this ProviderConfig. Statements wait for a connection to be closed
+	// once it is reached. Each statement needs only one connection, and
+	// releases it before the next one starts, so 1 runs all statements one
+	// after another. Unset does not bound them.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOpenConnections *int32 `json:"maxOpenConnections,omitempty"`
+
+	// MaxIdleConnections bounds the number of idle connections kept open
+	// between the statements of a reconcile. Unset keeps two, or none when
+	// maxOpenConnections is set, so that idle connections do not hold up
+	// other reconciles waiting for a connection.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIdleConnections *int32 `json:"maxIdleConnections,omitempty"`
+
+	// PrivilegeSets are named sets of privileges, e.g. readonly: [SELECT],
+	// that Grants using this ProviderConfig may grant by setting
+	// privilegeSet. Changing a set changes the privileges of every Grant
+	// that uses it on its next reconcile.
+	// +optional
+	PrivilegeSets map[string]GrantPrivileges `json:"privilegeSets,omitempty"`
+
+	// MaintenanceWindow restricts updates and deletions of the external
+	// resources of managed resources that use this ProviderConfig to
+	// recurring periods, e.g. "Sat,Sun 02:00-06:00 Europe/Berlin". Periods
+	// are separated by semicolons, and start on the listed days of the week,
+	// which may be ranges such as Mon-Fri or * for every day. Times are in
+	// UTC unless a time zone is given. Outside of it, managed resources are
+	// observed but not changed, and report a Deferred condition. A managed
+	// resource may override it with the sql.crossplane.io/maintenance-window
+	// annotation. Changes are allowed at any time if it is unset.
+	// +optional
+	MaintenanceWindow *string `json:"maintenanceWindow,omitempty"`
 }
 
 // TLSConfig defines the TLS configuration for the provider when tls=custom.
+// The configuration is registered with the driver under a name unique to
+// the ProviderConfig, so ProviderConfigs with different TLS settings do not
+// affect each other.
 type TLSConfig struct {
-	CACert             TLSSecret `json:"caCert,omitempty"`
-	ClientCert         TLSSecret `json:"clientCert,omitempty"`
-	ClientKey          TLSSecret `json:"clientKey,omitempty"`
-	InsecureSkipVerify bool      `json:"insecureSkipVerify,omitempty"`
+	// CACert verifies the server certificate. The system roots are used if
+	// it is not set.
+	// +optional
+	CACert TLSSecret `json:"caCert,omitempty"`
+
+	// ClientCert and ClientKey authenticate the provider to the server. They
+	// must be set together.
+	// +optional
+	ClientCert TLSSecret `json:"clientCert,omitempty"`
+	// +optional
+	ClientKey TLSSecret `json:"clientKey,omitempty"`
+
+	// InsecureSkipVerify disables verification of the server certificate.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ServerName the server certificate is verified against, instead of the
+	// host of the endpoint. Useful when connecting through a proxy or an IP
+	// address.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// MinVersion is the minimum TLS version accepted. Defaults to 1.2.
+	// +kubebuilder:validation:Enum="1.0";"1.1";"1.2";"1.3"
+	// +optional
+	MinVersion *string `json:"minVersion,omitempty"`
+
+	// PublishCACert writes the CA certificate, as ca.crt, to the connection
+	// secrets of Users alongside tls=true, so that workloads consuming them
+	// can verify the server without mounting the CA separately.
+	// +optional
+	PublishCACert bool `json:"publishCACert,omitempty"`
 }
 
 // TLSSecret defines a reference to a K8s secret and its specific internal key that contains the TLS cert/keys in PEM format.
@@ -59,23 +147,66 @@ const (
 	// should acquire credentials from a connection secret written by a managed
 	// resource that represents a MySQL server.
 	CredentialsSourceMySQLConnectionSecret xpv1.CredentialsSource = "MySQLConnectionSecret"
+
+	// CredentialsSourceAzureAD indicates that a provider should connect
+	// using an Azure AD access token as its password, and acquire the other
+	// credentials from a connection secret.
+	CredentialsSourceAzureAD xpv1.CredentialsSource = "AzureAD"
 )
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
-	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=MySQLConnectionSecret
+	// Source of the provider credentials. Environment and Filesystem read
+	// them from the environment or filesystem of the provider, e.g. secrets
+	// injected by Vault Agent or the Secrets Store CSI driver, instead of a
+	// connection secret. AzureAD reads the endpoint, port and username from
+	// the connection secret, and uses an Azure AD access token, which is
+	// renewed before it expires, as the password.
+	// +kubebuilder:validation:Enum=MySQLConnectionSecret;Environment;Filesystem;AzureAD
 	Source xpv1.CredentialsSource `json:"source"`
 
+	// AzureADMethod is how Azure AD access tokens are acquired when the
+	// source is AzureAD. ActiveDirectoryWorkloadIdentity uses the AKS
+	// workload identity of the provider. ActiveDirectoryDefault uses the
+	// first credential available in the environment of the provider: a
+	// client secret or certificate, the workload identity, the managed
+	// identity of the host or the Azure CLI.
+	// +kubebuilder:validation:Enum=ActiveDirectoryDefault;ActiveDirectoryWorkloadIdentity
+	// +kubebuilder:default=ActiveDirectoryDefault
+	// +optional
+	AzureADMethod *string `json:"azureADMethod,omitempty"`
+
 	// A CredentialsSecretRef is a reference to a MySQL connection secret
 	// that contains the credentials that must be used to connect to the
-	// provider. +optional
+	// provider. The secret may set protocol to tcp or unix. When it is unix,
+	// or unset and the endpoint is an absolute path, the endpoint is the
+	// path of a Unix domain socket.
+	// +optional
 	ConnectionSecretRef *xpv1.SecretReference `json:"connectionSecretRef,omitempty"`
+
+	// Env is the environment variable of the provider that holds the
+	// credentials when the source is Environment. It holds a JSON object of
+	// connection secret keys, e.g. {"endpoint": "...", "username": "..."}.
+	// +optional
+	Env *xpv1.EnvSelector `json:"env,omitempty"`
+
+	// Fs is the path of the file or directory that holds the credentials
+	// when the source is Filesystem. A file holds a JSON object of connection
+	// secret keys. A directory, such as one mounted by the Secrets Store CSI
+	// driver or written by Vault Agent, holds a file named after each key.
+	// +optional
+	Fs *xpv1.FsSelector `json:"fs,omitempty"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// ServerVersion is the version of the MySQL or MariaDB server the
+	// provider last detected, as reported by VERSION(). It is detected when
+	// a Grant requests privileges that not every version supports.
+	// +optional
+	ServerVersion string `json:"serverVersion,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -84,6 +215,7 @@ type ProviderConfigStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentialsSecretRef.name",priority=1
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.serverVersion",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,sql}
 type ProviderConfig struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -93,6 +225,15 @@ type ProviderConfig struct {
 	Status ProviderConfigStatus `json:"status,omitempty"`
 }
 
+// GetMaintenanceWindow returns the maintenance window of the ProviderConfig,
+// or an empty string if it has none.
+func (pc *ProviderConfig) GetMaintenanceWindow() string {
+	if pc.Spec.MaintenanceWindow == nil {
+		return ""
+	}
+	return *pc.Spec.MaintenanceWindow
+}
+
 // +kubebuilder:object:root=true
 
 // ProviderConfigList contains a list of ProviderConfig.
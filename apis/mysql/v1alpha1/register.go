@@ -58,6 +58,14 @@ var (
 	ProviderConfigUsageListGroupVersionKind = SchemeGroupVersion.WithKind(ProviderConfigUsageListKind)
 )
 
+// StoreConfig type metadata.
+var (
+	StoreConfigKind             = reflect.TypeOf(StoreConfig{}).Name()
+	StoreConfigGroupKind        = schema.GroupKind{Group: Group, Kind: StoreConfigKind}.String()
+	StoreConfigKindAPIVersion   = StoreConfigKind + "." + SchemeGroupVersion.String()
+	StoreConfigGroupVersionKind = SchemeGroupVersion.WithKind(StoreConfigKind)
+)
+
 // Database type metadata.
 var (
 	DatabaseKind             = reflect.TypeOf(Database{}).Name()
@@ -82,10 +90,38 @@ var (
 	GrantGroupVersionKind = SchemeGroupVersion.WithKind(GrantKind)
 )
 
+// HealthCheck type metadata.
+var (
+	HealthCheckKind             = reflect.TypeOf(HealthCheck{}).Name()
+	HealthCheckGroupKind        = schema.GroupKind{Group: Group, Kind: HealthCheckKind}.String()
+	HealthCheckKindAPIVersion   = HealthCheckKind + "." + SchemeGroupVersion.String()
+	HealthCheckGroupVersionKind = SchemeGroupVersion.WithKind(HealthCheckKind)
+)
+
+// ApplicationAccount type metadata.
+var (
+	ApplicationAccountKind             = reflect.TypeOf(ApplicationAccount{}).Name()
+	ApplicationAccountGroupKind        = schema.GroupKind{Group: Group, Kind: ApplicationAccountKind}.String()
+	ApplicationAccountKindAPIVersion   = ApplicationAccountKind + "." + SchemeGroupVersion.String()
+	ApplicationAccountGroupVersionKind = SchemeGroupVersion.WithKind(ApplicationAccountKind)
+)
+
+// Plugin type metadata.
+var (
+	PluginKind             = reflect.TypeOf(Plugin{}).Name()
+	PluginGroupKind        = schema.GroupKind{Group: Group, Kind: PluginKind}.String()
+	PluginKindAPIVersion   = PluginKind + "." + SchemeGroupVersion.String()
+	PluginGroupVersionKind = SchemeGroupVersion.WithKind(PluginKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
+	SchemeBuilder.Register(&StoreConfig{}, &StoreConfigList{})
 	SchemeBuilder.Register(&Database{}, &DatabaseList{})
 	SchemeBuilder.Register(&User{}, &UserList{})
 	SchemeBuilder.Register(&Grant{}, &GrantList{})
+	SchemeBuilder.Register(&HealthCheck{}, &HealthCheckList{})
+	SchemeBuilder.Register(&ApplicationAccount{}, &ApplicationAccountList{})
+	SchemeBuilder.Register(&Plugin{}, &PluginList{})
 }
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// GetSyncStatus of this ApplicationAccount.
+func (mg *ApplicationAccount) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Database.
+func (mg *Database) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Grant.
+func (mg *Grant) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this HealthCheck.
+func (mg *HealthCheck) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Plugin.
+func (mg *Plugin) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this User.
+func (mg *User) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
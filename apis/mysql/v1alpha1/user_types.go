@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A UserSpec defines the desired state of a Database.
@@ -31,6 +33,7 @@ type UserSpec struct {
 // A UserStatus represents the observed state of a User.
 type UserStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 	AtProvider          UserObservation `json:"atProvider,omitempty"`
 }
 
@@ -41,14 +44,63 @@ type UserParameters struct {
 	// +optional
 	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
 
+	// HashedPassword states that PasswordSecretRef contains an authentication
+	// string already hashed by caching_sha2_password, sha256_password or
+	// mysql_native_password, as stored in the authentication_string column of
+	// mysql.user, rather than a password. This allows users to be imported
+	// from another server without knowing their passwords. The plugin is
+	// told by the format of the hash. The connection secret does not contain
+	// a password, and PasswordSecretRef is required.
+	// +optional
+	HashedPassword *bool `json:"hashedPassword,omitempty"`
+
 	// ResourceOptions sets account specific resource limits.
 	// See https://dev.mysql.com/doc/refman/8.0/en/user-resources.html
 	// +optional
 	ResourceOptions *ResourceOptions `json:"resourceOptions,omitempty"`
 
-	// BinLog defines whether the create, delete, update operations of this user are propagated to replicas. Defaults to true
+	// BinLog defines whether the create, delete, update operations of this user are propagated to replicas. Defaults to true.
+	// Setting it to false runs the statements of this user with sql_log_bin=0,
+	// excluding them from replication without a separate ProviderConfig.
+	// This requires the SUPER or SYSTEM_VARIABLES_ADMIN privilege.
 	// +optional
 	BinLog *bool `json:"binlog,omitempty"`
+
+	// AdoptExisting states that a user of the same name may already exist and
+	// is to be brought under management. MySQL records no owner of a user, so
+	// existing users are adopted whether or not it is set; in either case
+	// their password is left untouched until a PasswordSecretRef is given.
+	// +optional
+	AdoptExisting *bool `json:"adoptExisting,omitempty"`
+
+	// ProxyOf is an account, in user@host form, that this user is granted
+	// PROXY on. This allows proxy users of authentication plugins such as
+	// PAM or LDAP to be mapped onto the privileges of the named account.
+	// Removing it revokes the PROXY grant.
+	// +optional
+	ProxyOf *string `json:"proxyOf,omitempty"`
+
+	// AuthPlugin is the authentication plugin the user is identified with,
+	// e.g. authentication_ldap_simple or authentication_pam. Users of a
+	// plugin are authenticated externally, so no password is generated,
+	// PasswordSecretRef is ignored and the connection secret does not
+	// contain a password.
+	// +kubebuilder:validation:Pattern:=^[A-Za-z0-9_]+$
+	// +optional
+	AuthPlugin *string `json:"authPlugin,omitempty"`
+
+	// AuthString is passed to the AuthPlugin, e.g. the distinguished name
+	// of the user in LDAP or the PAM service name and group mappings.
+	// +optional
+	AuthString *string `json:"authString,omitempty"`
+
+	// DefaultRoles are the roles, in role or role@host form, that are
+	// activated when the user connects. Roles the user has not been granted
+	// are granted to it. Roles removed from the list are no longer activated
+	// but stay granted. The default roles are left untouched if it is not
+	// set. This requires MySQL 8.0 or later.
+	// +optional
+	DefaultRoles []string `json:"defaultRoles,omitempty"`
 }
 
 // ResourceOptions define the account specific resource limits.
@@ -74,6 +126,27 @@ type ResourceOptions struct {
 type UserObservation struct {
 	// ResourceOptionsAsClauses represents the applied resource options
 	ResourceOptionsAsClauses []string `json:"resourceOptionsAsClauses,omitempty"`
+
+	// ResourceOptions are the resource limits currently applied to the
+	// account. A value of zero means no limit.
+	ResourceOptions *ResourceOptions `json:"resourceOptions,omitempty"`
+
+	// ProxyOf is the account, in user@host form, this user is currently
+	// granted PROXY on.
+	ProxyOf *string `json:"proxyOf,omitempty"`
+
+	// AuthPlugin is the authentication plugin the user is currently
+	// identified with.
+	AuthPlugin *string `json:"authPlugin,omitempty"`
+
+	// DefaultRoles are the roles, in role@host form, that are activated when
+	// the user connects and that the user has been granted. They are only
+	// observed if DefaultRoles is set.
+	DefaultRoles []string `json:"defaultRoles,omitempty"`
+
+	// Name is the account, in user@host form, the user was last observed
+	// as. The user is renamed to its external name if that changes.
+	Name string `json:"name,omitempty"`
 }
 
 // +kubebuilder:object:root=true
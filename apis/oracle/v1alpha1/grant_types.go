@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A GrantSpec defines the desired state of a Grant.
+type GrantSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       GrantParameters `json:"forProvider"`
+}
+
+// GrantPrivilege represents a privilege to be granted
+// +kubebuilder:validation:Pattern:=^[A-Z ]+$
+type GrantPrivilege string
+
+// GrantPrivileges is a list of the privileges to be granted
+type GrantPrivileges []GrantPrivilege
+
+// ToStringSlice converts the slice of privileges to strings
+func (gp *GrantPrivileges) ToStringSlice() []string {
+	if gp == nil {
+		return []string{}
+	}
+	out := make([]string, len(*gp))
+	for i, v := range *gp {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// GrantParameters define the desired state of an Oracle grant instance. The
+// grantee is either a user or a role. Privileges are system privileges
+// unless on is set, in which case they are object privileges. If memberOf
+// is set the role is instead granted to the grantee.
+type GrantParameters struct {
+	// Privileges to be granted, e.g. CREATE SESSION or SELECT.
+	// +optional
+	Privileges GrantPrivileges `json:"privileges,omitempty"`
+
+	// On is the object the privileges are granted on, in the form
+	// SCHEMA.OBJECT, e.g. HR.EMPLOYEES.
+	// +kubebuilder:validation:Pattern:=^[^.]+\.[^.]+$
+	// +immutable
+	// +optional
+	On *string `json:"on,omitempty"`
+
+	// WithAdminOption allows the grantee to grant the system privileges or
+	// the role to others.
+	// +immutable
+	// +optional
+	WithAdminOption *bool `json:"withAdminOption,omitempty"`
+
+	// WithGrantOption allows the grantee to grant the object privileges to
+	// others.
+	// +immutable
+	// +optional
+	WithGrantOption *bool `json:"withGrantOption,omitempty"`
+
+	// User this grant is for.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=User
+	User *string `json:"user,omitempty"`
+
+	// UserRef references the user object this grant is for.
+	// +immutable
+	// +optional
+	UserRef *xpv1.Reference `json:"userRef,omitempty"`
+
+	// UserSelector selects a reference to a User this grant is for.
+	// +immutable
+	// +optional
+	UserSelector *xpv1.Selector `json:"userSelector,omitempty"`
+
+	// Role this grant is for.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Role
+	Role *string `json:"role,omitempty"`
+
+	// RoleRef references the role object this grant is for.
+	// +immutable
+	// +optional
+	RoleRef *xpv1.Reference `json:"roleRef,omitempty"`
+
+	// RoleSelector selects a reference to a Role this grant is for.
+	// +immutable
+	// +optional
+	RoleSelector *xpv1.Selector `json:"roleSelector,omitempty"`
+
+	// MemberOf is the role that the grantee will be granted.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Role
+	MemberOf *string `json:"memberOf,omitempty"`
+
+	// MemberOfRef references the role that the grantee will be granted.
+	// +immutable
+	// +optional
+	MemberOfRef *xpv1.Reference `json:"memberOfRef,omitempty"`
+
+	// MemberOfSelector selects a reference to a Role that the grantee will
+	// be granted.
+	// +immutable
+	// +optional
+	MemberOfSelector *xpv1.Selector `json:"memberOfSelector,omitempty"`
+}
+
+// A GrantStatus represents the observed state of a Grant.
+type GrantStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          GrantObservation `json:"atProvider,omitempty"`
+}
+
+// A GrantObservation represents the observed state of an Oracle grant.
+type GrantObservation struct {
+	// Privileges represents the applied privileges
+	Privileges []string `json:"privileges,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Grant represents the declarative state of an Oracle grant.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="USER",type="string",JSONPath=".spec.forProvider.user"
+// +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".spec.forProvider.role"
+// +kubebuilder:printcolumn:name="MEMBER OF",type="string",JSONPath=".spec.forProvider.memberOf"
+// +kubebuilder:printcolumn:name="ON",type="string",JSONPath=".spec.forProvider.on"
+// +kubebuilder:printcolumn:name="PRIVILEGES",type="string",JSONPath=".spec.forProvider.privileges"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Grant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrantSpec   `json:"spec"`
+	Status GrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GrantList contains a list of Grant
+type GrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Grant `json:"items"`
+}
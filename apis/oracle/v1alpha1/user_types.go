@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A UserSpec defines the desired state of a User.
+type UserSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UserParameters `json:"forProvider"`
+}
+
+// A UserStatus represents the observed state of a User.
+type UserStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          UserObservation `json:"atProvider,omitempty"`
+}
+
+// UserParameters define the desired state of an Oracle user.
+type UserParameters struct {
+	// PasswordSecretRef references the secret that contains the password used
+	// for this user. If no reference is given, a password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// DefaultTablespace of the user. Defaults to the default tablespace of
+	// the database.
+	// +optional
+	DefaultTablespace *string `json:"defaultTablespace,omitempty"`
+
+	// TemporaryTablespace of the user. Defaults to the default temporary
+	// tablespace of the database.
+	// +optional
+	TemporaryTablespace *string `json:"temporaryTablespace,omitempty"`
+
+	// Profile limiting the resources and password policy of the user.
+	// Defaults to the DEFAULT profile.
+	// +optional
+	Profile *string `json:"profile,omitempty"`
+
+	// AccountLocked prevents the user from logging in.
+	// +optional
+	AccountLocked *bool `json:"accountLocked,omitempty"`
+
+	// CascadeOnDelete drops the objects in the schema of the user when the
+	// user is deleted. Without it, deleting a user that owns objects fails.
+	// +optional
+	CascadeOnDelete *bool `json:"cascadeOnDelete,omitempty"`
+}
+
+// A UserObservation represents the observed state of an Oracle user.
+type UserObservation struct {
+	// AccountStatus of the user, e.g. OPEN, LOCKED or EXPIRED.
+	AccountStatus string `json:"accountStatus,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A User represents the declarative state of an Oracle user. User names are
+// case-sensitive; use upper case to match unquoted names in SQL.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.atProvider.accountStatus"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// An AuditLogClass is a class of statements that pgaudit logs. NONE logs
+// no statements, e.g. to override a server-wide default.
+// +kubebuilder:validation:Enum=READ;WRITE;FUNCTION;ROLE;DDL;MISC;MISC_SET;ALL;NONE
+type AuditLogClass string
+
+// AuditSettings are the pgaudit settings of a role or database. They set the
+// pgaudit.log, pgaudit.log_relation and pgaudit.log_parameter configuration
+// parameters, each of which is reset to the default of the server when its
+// setting is omitted. The server must load the pgaudit library for them to
+// take effect.
+// See https://github.com/pgaudit/pgaudit#settings for details.
+type AuditSettings struct {
+	// Log lists the classes of statements that are logged by session audit
+	// logging, e.g. DDL and WRITE.
+	// +listType=set
+	// +optional
+	Log []AuditLogClass `json:"log,omitempty"`
+
+	// LogRelation logs a separate entry for each relation, e.g. table or
+	// view, that a logged statement references.
+	// +optional
+	LogRelation *bool `json:"logRelation,omitempty"`
+
+	// LogParameter includes the parameters that were passed with a logged
+	// statement.
+	// +optional
+	LogParameter *bool `json:"logParameter,omitempty"`
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A CastSpec defines the desired state of a Cast.
+type CastSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CastParameters `json:"forProvider"`
+}
+
+// CastParameters define the desired state of a PostgreSQL cast.
+type CastParameters struct {
+	// SourceType is the source data type of the cast, for example text or
+	// public.my_type.
+	// +immutable
+	SourceType string `json:"sourceType"`
+
+	// TargetType is the target data type of the cast.
+	// +immutable
+	TargetType string `json:"targetType"`
+
+	// Function performing the cast, including its argument types, for
+	// example public.text_to_my_type(text). If neither function nor inout
+	// is set the cast is created WITHOUT FUNCTION, meaning both types are
+	// binary coercible.
+	// +optional
+	Function *string `json:"function,omitempty"`
+
+	// InOut creates the cast WITH INOUT, using the output function of the
+	// source type and the input function of the target type.
+	// +optional
+	InOut *bool `json:"inout,omitempty"`
+
+	// Context in which the cast may be invoked implicitly. Defaults to
+	// Explicit.
+	// +kubebuilder:validation:Enum=Explicit;Assignment;Implicit
+	// +optional
+	Context *string `json:"context,omitempty"`
+
+	// Database this cast is for.
+	// +optional
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object this cast is for.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database this cast is for.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A CastStatus represents the observed state of a Cast.
+type CastStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Cast represents the declarative state of a PostgreSQL cast.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="SOURCE",type="string",JSONPath=".spec.forProvider.sourceType"
+// +kubebuilder:printcolumn:name="TARGET",type="string",JSONPath=".spec.forProvider.targetType"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Cast struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CastSpec   `json:"spec"`
+	Status CastStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CastList contains a list of Cast
+type CastList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cast `json:"items"`
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A CollationSpec defines the desired state of a Collation.
+type CollationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CollationParameters `json:"forProvider"`
+}
+
+// CollationParameters define the desired state of a PostgreSQL collation.
+// Collations cannot be altered once created, so all parameters are
+// immutable.
+type CollationParameters struct {
+	// Provider of the collation. Defaults to libc.
+	// +kubebuilder:validation:Enum=libc;icu;builtin
+	// +immutable
+	// +optional
+	Provider *string `json:"provider,omitempty"`
+
+	// Locale sets both LC_COLLATE and LC_CTYPE of the collation, for example
+	// en-US-x-icu or de_DE.utf8.
+	// +immutable
+	// +optional
+	Locale *string `json:"locale,omitempty"`
+
+	// LCCollate is the LC_COLLATE locale of the collation. Ignored if
+	// locale is set.
+	// +immutable
+	// +optional
+	LCCollate *string `json:"lcCollate,omitempty"`
+
+	// LCCtype is the LC_CTYPE locale of the collation. Ignored if locale is
+	// set.
+	// +immutable
+	// +optional
+	LCCtype *string `json:"lcCtype,omitempty"`
+
+	// Deterministic specifies whether the collation uses deterministic
+	// comparisons. Non-deterministic collations require the icu provider.
+	// Defaults to true.
+	// +immutable
+	// +optional
+	Deterministic *bool `json:"deterministic,omitempty"`
+
+	// From copies the properties of an existing collation, for example
+	// "und-x-icu". All other parameters are ignored if it is set.
+	// +immutable
+	// +optional
+	From *string `json:"from,omitempty"`
+
+	// Schema the collation is created in. Defaults to the first schema in
+	// the search path of the provider's role.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Schema
+	Schema *string `json:"schema,omitempty"`
+
+	// SchemaRef references the schema object this collation is created in.
+	// +immutable
+	// +optional
+	SchemaRef *xpv1.Reference `json:"schemaRef,omitempty"`
+
+	// SchemaSelector selects a reference to a Schema this collation is
+	// created in.
+	// +immutable
+	// +optional
+	SchemaSelector *xpv1.Selector `json:"schemaSelector,omitempty"`
+
+	// Database this collation is for.
+	// +optional
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object this collation is for.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database this collation is
+	// for.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A CollationStatus represents the observed state of a Collation.
+type CollationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Collation represents the declarative state of a PostgreSQL collation.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.forProvider.provider"
+// +kubebuilder:printcolumn:name="LOCALE",type="string",JSONPath=".spec.forProvider.locale"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Collation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CollationSpec   `json:"spec"`
+	Status CollationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CollationList contains a list of Collation
+type CollationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Collation `json:"items"`
+}
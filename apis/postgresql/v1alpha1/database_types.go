@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // DatabaseParameters are the configurable fields of a Database.
@@ -28,12 +30,45 @@ type DatabaseParameters struct {
 	// use the default (namely, the user executing the command). To create a
 	// database owned by another role, you must be a direct or indirect member
 	// of that role, or be a superuser.
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
 	Owner *string `json:"owner,omitempty"`
 
+	// ReassignOwnedObjects also transfers the objects inside the database to
+	// the new owner when the owner changes, by running REASSIGN OWNED BY
+	// in the database. Note that REASSIGN OWNED also transfers shared
+	// objects such as other databases owned by the previous owner, and fails
+	// if the previous owner is the bootstrap superuser.
+	// +optional
+	ReassignOwnedObjects *bool `json:"reassignOwnedObjects,omitempty"`
+
 	// The name of the template from which to create the new database, or
 	// DEFAULT to use the default template (template1).
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
 	Template *string `json:"template,omitempty"`
 
+	// TerminateTemplateConnections terminates the connections to the
+	// template before creating the database, since PostgreSQL refuses to
+	// copy a template that other sessions are connected to. Creation is
+	// retried a few times in case clients reconnect in the meantime.
+	// +optional
+	TerminateTemplateConnections *bool `json:"terminateTemplateConnections,omitempty"`
+
+	// Strategy is how the new database is created from its template, either
+	// WAL_LOG, which copies it block by block through the write-ahead log,
+	// or FILE_COPY, which copies its files and then checkpoints. It is
+	// ignored by servers older than PostgreSQL 15, which always copy files.
+	// +kubebuilder:validation:Enum=WAL_LOG;FILE_COPY
+	// +optional
+	Strategy *string `json:"strategy,omitempty"`
+
+	// OID is the object identifier of the new database, e.g. to match that
+	// of the database on another server. It requires PostgreSQL 15 or later
+	// and cannot be changed once the database exists.
+	// +kubebuilder:validation:Minimum=16384
+	// +kubebuilder:validation:Maximum=4294967295
+	// +optional
+	OID *int64 `json:"oid,omitempty"`
+
 	// Character set encoding to use in the new database. Specify a string
 	// constant (e.g., 'SQL_ASCII'), or an integer encoding number, or DEFAULT
 	// to use the default encoding (namely, the encoding of the template
@@ -54,10 +89,32 @@ type DatabaseParameters struct {
 	// database. See below for additional restrictions.
 	LCCType *string `json:"lcCType,omitempty"`
 
+	// LocaleProvider is the provider of the collation of the new database,
+	// either libc or icu. It requires PostgreSQL 15 or later and cannot be
+	// changed once the database exists.
+	// +kubebuilder:validation:Enum=libc;icu
+	// +optional
+	LocaleProvider *string `json:"localeProvider,omitempty"`
+
+	// ICULocale is the ICU locale of the new database, e.g. en-US, if its
+	// LocaleProvider is icu. It requires PostgreSQL 15 or later and cannot
+	// be changed once the database exists.
+	// +optional
+	ICULocale *string `json:"icuLocale,omitempty"`
+
+	// CollationVersionRefresh records the collation version of the
+	// operating system or ICU library in the database whenever it differs
+	// from the one the database was created with, e.g. after an upgrade.
+	// Indexes that depend on the collation should be rebuilt first. It
+	// requires PostgreSQL 15 or later.
+	// +optional
+	CollationVersionRefresh *bool `json:"collationVersionRefresh,omitempty"`
+
 	// The name of the tablespace that will be associated with the new database,
 	// or DEFAULT to use the template database's tablespace. This tablespace
 	// will be the default tablespace used for objects created in this database.
 	// See CREATE TABLESPACE for more information.
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
 	Tablespace *string `json:"tablespace,omitempty"`
 
 	// If false then no one can connect to this database. The default is true,
@@ -73,6 +130,25 @@ type DatabaseParameters struct {
 	// privileges; if false (the default), then only superusers or the owner of
 	// the database can clone it.
 	IsTemplate *bool `json:"isTemplate,omitempty"`
+
+	// Comment on the database, as set by COMMENT ON DATABASE. An empty
+	// comment removes it. The comment is left untouched if omitted.
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+
+	// Audit are the pgaudit settings of the database, which apply to the
+	// sessions connected to it. Other pgaudit settings of the database are
+	// left untouched.
+	// +optional
+	Audit *AuditSettings `json:"audit,omitempty"`
+
+	// ForceDrop terminates the connections to the database when it is
+	// deleted, since PostgreSQL refuses to drop a database that other
+	// sessions are connected to. It uses DROP DATABASE WITH (FORCE) on
+	// PostgreSQL 13 or later, and terminates the connections before dropping
+	// the database on older servers.
+	// +optional
+	ForceDrop *bool `json:"forceDrop,omitempty"`
 }
 
 // A DatabaseSpec defines the desired state of a Database.
@@ -84,6 +160,58 @@ type DatabaseSpec struct {
 // A DatabaseStatus represents the observed state of a Database.
 type DatabaseStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseObservation represents the observed state of a PostgreSQL
+// database.
+type DatabaseObservation struct {
+	// Owner is the role that owns the database.
+	Owner string `json:"owner,omitempty"`
+
+	// Encoding is the character set encoding of the database.
+	Encoding string `json:"encoding,omitempty"`
+
+	// LCCollate is the collation order of the database.
+	LCCollate string `json:"lcCollate,omitempty"`
+
+	// LCCType is the character classification of the database.
+	LCCType string `json:"lcCType,omitempty"`
+
+	// LocaleProvider is the provider of the collation of the database.
+	LocaleProvider string `json:"localeProvider,omitempty"`
+
+	// ICULocale is the ICU locale of the database.
+	ICULocale string `json:"icuLocale,omitempty"`
+
+	// Tablespace is the default tablespace of the database.
+	Tablespace string `json:"tablespace,omitempty"`
+
+	// AllowConnections is false if no one can connect to the database.
+	AllowConnections bool `json:"allowConnections,omitempty"`
+
+	// ConnectionLimit is the number of concurrent connections that can be
+	// made to the database. -1 means no limit.
+	ConnectionLimit int `json:"connectionLimit,omitempty"`
+
+	// IsTemplate is true if the database can be cloned by any user with
+	// CREATEDB privileges.
+	IsTemplate bool `json:"isTemplate,omitempty"`
+
+	// Comment is the comment on the database.
+	Comment string `json:"comment,omitempty"`
+
+	// Audit are the pgaudit settings of the database. They are only
+	// observed if audit settings are specified.
+	Audit *AuditSettings `json:"audit,omitempty"`
+
+	// OID is the object identifier of the database.
+	OID int64 `json:"oid,omitempty"`
+
+	// SizeBytes is the disk space used by the database. It is zero if the
+	// provider may not connect to the database.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -93,6 +221,9 @@ type DatabaseStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="OWNER",type="string",JSONPath=".status.atProvider.owner"
+// +kubebuilder:printcolumn:name="ENCODING",type="string",JSONPath=".status.atProvider.encoding"
+// +kubebuilder:printcolumn:name="SIZE",type="integer",JSONPath=".status.atProvider.sizeBytes",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
 type Database struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// DatabaseInstanceParameters are the configurable fields of a
+// DatabaseInstance: a database, the login role that owns it and its schemas,
+// and the revocation of the default privileges of PUBLIC.
+type DatabaseInstanceParameters struct {
+	// Owner is the name of the login role that is created to own the
+	// database and its schemas. Defaults to the external name of the
+	// DatabaseInstance.
+	// +immutable
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
+	Owner *string `json:"owner,omitempty"`
+
+	// PasswordSecretRef references the secret that contains the password of
+	// the owner. If no reference is given, a password will be auto-generated.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// ConnectionLimit of the owner.
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// Encoding of the database.
+	// +immutable
+	// +optional
+	Encoding *string `json:"encoding,omitempty"`
+
+	// Schemas that are created in the database and owned by the owner.
+	// +optional
+	Schemas []string `json:"schemas,omitempty"`
+
+	// RevokePublic revokes the privileges PUBLIC has on the database, and
+	// to create objects in its public schema, so that only the owner and
+	// roles it grants privileges to may use the database. Defaults to true.
+	// +optional
+	RevokePublic *bool `json:"revokePublic,omitempty"`
+}
+
+// A DatabaseInstanceSpec defines the desired state of a DatabaseInstance.
+type DatabaseInstanceSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DatabaseInstanceParameters `json:"forProvider"`
+}
+
+// A DatabaseInstanceStatus represents the observed state of a
+// DatabaseInstance.
+type DatabaseInstanceStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          DatabaseInstanceObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseInstanceObservation represents the observed state of a
+// DatabaseInstance.
+type DatabaseInstanceObservation struct {
+	// Owner is the role that owns the database.
+	Owner string `json:"owner,omitempty"`
+
+	// Schemas are the desired schemas that exist and are owned by the
+	// owner.
+	Schemas []string `json:"schemas,omitempty"`
+
+	// PublicRevoked is true if PUBLIC may neither connect to the database
+	// nor create objects in its public schema.
+	PublicRevoked bool `json:"publicRevoked,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DatabaseInstance represents the declarative state of a PostgreSQL
+// database bootstrapped together with its owner. Its connection secret holds
+// the username, password, endpoint, port and database of the owner.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="OWNER",type="string",JSONPath=".status.atProvider.owner"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type DatabaseInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseInstanceSpec   `json:"spec"`
+	Status DatabaseInstanceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DatabaseInstanceList contains a list of DatabaseInstance
+type DatabaseInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseInstance `json:"items"`
+}
@@ -25,6 +25,8 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reference"
 	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // ExtensionParameters are the configurable fields of a Extension.
@@ -65,6 +67,7 @@ type ExtensionSpec struct {
 // A ExtensionStatus represents the observed state of a Extension.
 type ExtensionStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
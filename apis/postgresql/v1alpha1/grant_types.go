@@ -20,11 +20,14 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reference"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A GrantSpec defines the desired state of a Grant.
@@ -34,7 +37,7 @@ type GrantSpec struct {
 }
 
 // GrantPrivilege represents a privilege to be granted
-// +kubebuilder:validation:Pattern:=^[A-Z]+$
+// +kubebuilder:validation:Pattern:=^[A-Z]+( [A-Z]+)?$
 type GrantPrivilege string
 
 // If Privileges are specified, we should have at least one
@@ -53,14 +56,76 @@ var grantReplacements = map[GrantPrivilege]GrantPrivileges{
 	"TEMP":           {"TEMPORARY"},
 }
 
+// Configuration parameter privileges have their own shorthands.
+// https://www.postgresql.org/docs/15/ddl-priv.html
+var parameterGrantReplacements = map[GrantPrivilege]GrantPrivileges{
+	"ALL":            {"SET", "ALTER SYSTEM"},
+	"ALL PRIVILEGES": {"SET", "ALTER SYSTEM"},
+}
+
+// Table and sequence privileges have their own shorthands.
+// https://www.postgresql.org/docs/15/ddl-priv.html
+var (
+	tableGrantReplacements = map[GrantPrivilege]GrantPrivileges{
+		"ALL":            {"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+		"ALL PRIVILEGES": {"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	}
+	sequenceGrantReplacements = map[GrantPrivilege]GrantPrivileges{
+		"ALL":            {"USAGE", "SELECT", "UPDATE"},
+		"ALL PRIVILEGES": {"USAGE", "SELECT", "UPDATE"},
+	}
+	schemaGrantReplacements = map[GrantPrivilege]GrantPrivileges{
+		"ALL":            {"USAGE", "CREATE"},
+		"ALL PRIVILEGES": {"USAGE", "CREATE"},
+	}
+	routineGrantReplacements = map[GrantPrivilege]GrantPrivileges{
+		"ALL":            {"EXECUTE"},
+		"ALL PRIVILEGES": {"EXECUTE"},
+	}
+)
+
 // ExpandPrivileges expands any shorthand privileges to their full equivalents.
 func (gp *GrantPrivileges) ExpandPrivileges() GrantPrivileges {
+	return gp.expand(grantReplacements)
+}
+
+// ExpandTablePrivileges expands any shorthand table privileges to their full
+// equivalents.
+func (gp *GrantPrivileges) ExpandTablePrivileges() GrantPrivileges {
+	return gp.expand(tableGrantReplacements)
+}
+
+// ExpandSequencePrivileges expands any shorthand sequence privileges to their
+// full equivalents.
+func (gp *GrantPrivileges) ExpandSequencePrivileges() GrantPrivileges {
+	return gp.expand(sequenceGrantReplacements)
+}
+
+// ExpandSchemaPrivileges expands any shorthand schema privileges to their
+// full equivalents.
+func (gp *GrantPrivileges) ExpandSchemaPrivileges() GrantPrivileges {
+	return gp.expand(schemaGrantReplacements)
+}
+
+// ExpandRoutinePrivileges expands any shorthand function or procedure
+// privileges to their full equivalents.
+func (gp *GrantPrivileges) ExpandRoutinePrivileges() GrantPrivileges {
+	return gp.expand(routineGrantReplacements)
+}
+
+// ExpandParameterPrivileges expands any shorthand configuration parameter
+// privileges to their full equivalents.
+func (gp *GrantPrivileges) ExpandParameterPrivileges() GrantPrivileges {
+	return gp.expand(parameterGrantReplacements)
+}
+
+func (gp *GrantPrivileges) expand(replacements map[GrantPrivilege]GrantPrivileges) GrantPrivileges {
 	privilegeSet := make(map[GrantPrivilege]struct{})
 
 	// Replace any shorthand privileges with their full equivalents
 	for _, p := range *gp {
-		if _, ok := grantReplacements[p]; ok {
-			for _, rp := range grantReplacements[p] {
+		if _, ok := replacements[p]; ok {
+			for _, rp := range replacements[p] {
 				privilegeSet[rp] = struct{}{}
 			}
 		} else {
@@ -99,6 +164,39 @@ const (
 	GrantOptionGrant GrantOption = "GRANT"
 )
 
+// A RoutineType is the kind of the routines a grant is for.
+type RoutineType string
+
+// The possible routine types.
+const (
+	// RoutineTypeFunction is the type of functions, including aggregate
+	// and window functions.
+	RoutineTypeFunction RoutineType = "FUNCTION"
+
+	// RoutineTypeProcedure is the type of procedures.
+	RoutineTypeProcedure RoutineType = "PROCEDURE"
+)
+
+// A PrivilegeProfile is a named combination of the privileges commonly
+// granted on a schema, or on the tables or sequences in it.
+type PrivilegeProfile string
+
+// The possible privilege profiles.
+const (
+	// PrivilegeProfileOwner grants USAGE and CREATE on a schema, and all
+	// privileges on tables and sequences.
+	PrivilegeProfileOwner PrivilegeProfile = "owner"
+
+	// PrivilegeProfileWriter grants USAGE on a schema, SELECT, INSERT,
+	// UPDATE and DELETE on tables, and USAGE, SELECT and UPDATE on
+	// sequences.
+	PrivilegeProfileWriter PrivilegeProfile = "writer"
+
+	// PrivilegeProfileReader grants USAGE on a schema, and SELECT on tables
+	// and sequences.
+	PrivilegeProfileReader PrivilegeProfile = "reader"
+)
+
 // GrantParameters define the desired state of a PostgreSQL grant instance.
 type GrantParameters struct {
 	// Privileges to be granted.
@@ -106,6 +204,20 @@ type GrantParameters struct {
 	// +optional
 	Privileges GrantPrivileges `json:"privileges,omitempty"`
 
+	// PrivilegeSet is the name of a set of privileges, defined in the
+	// privilegeSets of the ProviderConfig, to grant instead of privileges.
+	// +optional
+	PrivilegeSet *string `json:"privilegeSet,omitempty"`
+
+	// PrivilegeProfile grants the privileges of a common role on a schema,
+	// or on the tables or sequences in it, instead of privileges. A reader
+	// may use the schema and read its tables and sequences, a writer may
+	// also modify their data, and an owner may also create objects in the
+	// schema. Only grants that set schema may use a profile.
+	// +kubebuilder:validation:Enum=owner;writer;reader
+	// +optional
+	PrivilegeProfile *PrivilegeProfile `json:"privilegeProfile,omitempty"`
+
 	// WithOption allows an option to be set on the grant.
 	// See https://www.postgresql.org/docs/current/sql-grant.html for available
 	// options for each grant type, and the effects of applying the option.
@@ -141,7 +253,34 @@ type GrantParameters struct {
 	// +optional
 	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
 
-	// MemberOf is the Role that this grant makes Role a member of.
+	// DatabasesSelector selects every Database with matching labels that
+	// uses the same ProviderConfig as this grant. The privileges are granted
+	// on each of them, including those selected later, instead of on
+	// database. Databases that stop matching keep their privileges until the
+	// grant is deleted. Only database grants may select databases.
+	// +optional
+	DatabasesSelector *xpv1.Selector `json:"databasesSelector,omitempty"`
+
+	// RevokeUnmanaged controls whether privileges the role holds on the
+	// database, tables or sequences but that are not listed in privileges,
+	// for example ones granted outside of this Grant, are revoked. Defaults
+	// to false. Do not enable it when more than one Grant manages privileges
+	// of the same role on the same objects, as they would revoke each
+	// other's privileges. Does not apply to membership and parameter grants.
+	// +optional
+	RevokeUnmanaged *bool `json:"revokeUnmanaged,omitempty"`
+
+	// Parameters are the configuration parameters this grant is for. Only
+	// the SET and ALTER SYSTEM privileges (or ALL) may be granted on
+	// parameters, which requires PostgreSQL 15 or later.
+	// See https://www.postgresql.org/docs/current/sql-grant.html
+	// +optional
+	Parameters []string `json:"parameters,omitempty"`
+
+	// MemberOf is the Role that this grant makes Role a member of. This may
+	// also be one of the predefined roles, e.g. pg_monitor, pg_read_all_data
+	// or pg_signal_backend.
+	// See https://www.postgresql.org/docs/current/predefined-roles.html
 	// +optional
 	MemberOf *string `json:"memberOf,omitempty"`
 
@@ -154,11 +293,121 @@ type GrantParameters struct {
 	// +immutable
 	// +optional
 	MemberOfSelector *xpv1.Selector `json:"memberOfSelector,omitempty"`
+
+	// Grantor is the role the grant is issued and revoked as. The provider
+	// switches to this role with SET ROLE before granting, so that the grant
+	// is recorded as made by it rather than by the role the provider connects
+	// as. The connecting role must be a member of the grantor.
+	// +optional
+	Grantor *string `json:"grantor,omitempty"`
+
+	// Schema the tables, sequences or routines of this grant are in. They
+	// are looked up in database, or in the default database of the
+	// ProviderConfig if database is not set. If neither tables, sequences nor
+	// routines are set, the privileges are granted on the schema itself, and
+	// may only be USAGE, CREATE or ALL. CREATE requires USAGE, without which
+	// the role cannot use the objects it creates.
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// Schemas this grant is for, as an alternative to schema when the same
+	// privileges are to be granted on several schemas. They are granted on
+	// all of them in a single transaction. Cannot be set with schema, tables,
+	// sequences or routines.
+	// +optional
+	Schemas []string `json:"schemas,omitempty"`
+
+	// Tables this grant is for. Use ["*"] to grant on all tables, views and
+	// materialized views that currently exist in schema. Requires schema.
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// Sequences this grant is for. Use ["*"] to grant on all sequences that
+	// currently exist in schema. Requires schema.
+	// +optional
+	Sequences []string `json:"sequences,omitempty"`
+
+	// Routines this grant is for, by name, which must be unique in schema.
+	// Use ["*"] to grant on all routines of routineType that currently
+	// exist in schema. Only EXECUTE (or ALL) may be granted on routines.
+	// Requires schema.
+	// +optional
+	Routines []string `json:"routines,omitempty"`
+
+	// RoutineType is whether routines are functions, including aggregate
+	// and window functions, or procedures, which require PostgreSQL 11 or
+	// later. Defaults to FUNCTION.
+	// +kubebuilder:validation:Enum=FUNCTION;PROCEDURE
+	// +optional
+	RoutineType *RoutineType `json:"routineType,omitempty"`
+
+	// DefaultPrivilegesFor is the role whose tables or sequences, created in
+	// schema after this grant, should receive the same privileges. If set,
+	// the provider manages the corresponding ALTER DEFAULT PRIVILEGES FOR
+	// ROLE. If not set, the FutureObjectsCovered condition reports whether
+	// any default privileges already cover them.
+	// See https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	// +optional
+	DefaultPrivilegesFor *string `json:"defaultPrivilegesFor,omitempty"`
+}
+
+// TypeFutureObjectsCovered indicates whether tables or sequences created
+// later in the schema of a grant receive its privileges through default
+// privileges.
+const TypeFutureObjectsCovered xpv1.ConditionType = "FutureObjectsCovered"
+
+// Reasons a grant does or does not cover future objects.
+const (
+	ReasonDefaultPrivilegesPresent xpv1.ConditionReason = "DefaultPrivilegesPresent"
+	ReasonDefaultPrivilegesMissing xpv1.ConditionReason = "DefaultPrivilegesMissing"
+)
+
+// FutureObjectsCovered returns a condition that indicates tables or sequences
+// created later in the schema of a grant receive its privileges.
+func FutureObjectsCovered() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeFutureObjectsCovered,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDefaultPrivilegesPresent,
+	}
+}
+
+// FutureObjectsNotCovered returns a condition that indicates tables or
+// sequences created later in the schema of a grant will not receive its
+// privileges, because no default privileges grant them.
+func FutureObjectsNotCovered() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeFutureObjectsCovered,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDefaultPrivilegesMissing,
+	}
 }
 
 // A GrantStatus represents the observed state of a Grant.
 type GrantStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          GrantObservation `json:"atProvider,omitempty"`
+}
+
+// A GrantObservation represents the observed state of a PostgreSQL grant.
+type GrantObservation struct {
+	// Databases are those selected by databasesSelector, and whether the
+	// grant is up to date on each of them.
+	Databases []GrantDatabaseObservation `json:"databases,omitempty"`
+}
+
+// A GrantDatabaseObservation is the observed state of a grant on one of the
+// databases selected by databasesSelector.
+type GrantDatabaseObservation struct {
+	// Name of the database.
+	Name string `json:"name"`
+
+	// UpToDate is true if the role holds exactly the privileges of the
+	// grant on the database.
+	UpToDate bool `json:"upToDate"`
 }
 
 // +kubebuilder:object:root=true
@@ -171,6 +420,8 @@ type GrantStatus struct {
 // +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".spec.forProvider.role"
 // +kubebuilder:printcolumn:name="MEMBER OF",type="string",JSONPath=".spec.forProvider.memberOf"
 // +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="PARAMETERS",type="string",JSONPath=".spec.forProvider.parameters",priority=1
+// +kubebuilder:printcolumn:name="SCHEMA",type="string",JSONPath=".spec.forProvider.schema",priority=1
 // +kubebuilder:printcolumn:name="PRIVILEGES",type="string",JSONPath=".spec.forProvider.privileges"
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
 type Grant struct {
@@ -36,25 +36,133 @@ type ProviderConfigSpec struct {
 	// +kubebuilder:default=verify-full
 	// +kubebuilder:validation:Optional
 	SSLMode *string `json:"sslMode,omitempty"`
+
+	// CACertSecretRef selects the PEM encoded CA bundle of the server. When
+	// sslMode requires TLS it is written, as ca.crt, to the connection
+	// secrets of Roles alongside the sslmode, so that workloads consuming
+	// them can verify the server without mounting the CA separately.
+	// +optional
+	CACertSecretRef *xpv1.SecretKeySelector `json:"caCertSecretRef,omitempty"`
+
+	// ConnectionOptions are additional driver parameters that are appended
+	// to the connection string, for example connect_timeout or
+	// application_name. Supported options are application_name,
+	// connect_timeout, fallback_application_name, sslcert, sslkey,
+	// sslrootcert and sslsni.
+	// +optional
+	ConnectionOptions map[string]string `json:"connectionOptions,omitempty"`
+
+	// MaxOpenConnections bounds the number of connections the provider has
+	// open to the server at once, across all of the managed resources that
+	// use this ProviderConfig. Statements wait for a connection to be closed
+	// once it is reached. Each statement needs only one connection, and
+	// releases it before the next one starts, so 1 runs all statements one
+	// after another. Unset does not bound them.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOpenConnections *int32 `json:"maxOpenConnections,omitempty"`
+
+	// MaxIdleConnections bounds the number of idle connections kept open
+	// between the statements of a reconcile. Unset keeps two, or none when
+	// maxOpenConnections is set, so that idle connections do not hold up
+	// other reconciles waiting for a connection.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIdleConnections *int32 `json:"maxIdleConnections,omitempty"`
+
+	// LockTimeout bounds how long each statement of a Grant transaction
+	// waits to acquire a lock, e.g. 5s. Unset uses the lock_timeout of the
+	// server.
+	// +optional
+	LockTimeout *metav1.Duration `json:"lockTimeout,omitempty"`
+
+	// Pooler is the connection pooler between the provider and the server,
+	// if any. pgbouncer-transaction makes the provider avoid session state,
+	// which a pooler in transaction mode does not keep between statements,
+	// and emit a warning event for resources that require it, e.g. Roles
+	// with configurationParameters.
+	// +kubebuilder:validation:Enum=pgbouncer-transaction
+	// +optional
+	Pooler *string `json:"pooler,omitempty"`
+
+	// PrivilegeSets are named sets of privileges, e.g. readonly: [SELECT],
+	// that Grants using this ProviderConfig may grant by setting
+	// privilegeSet. Changing a set changes the privileges of every Grant
+	// that uses it on its next reconcile.
+	// +optional
+	PrivilegeSets map[string]GrantPrivileges `json:"privilegeSets,omitempty"`
+
+	// MaintenanceWindow restricts updates and deletions of the external
+	// resources of managed resources that use this ProviderConfig to
+	// recurring periods, e.g. "Sat,Sun 02:00-06:00 Europe/Berlin". Periods
+	// are separated by semicolons, and start on the listed days of the week,
+	// which may be ranges such as Mon-Fri or * for every day. Times are in
+	// UTC unless a time zone is given. Outside of it, managed resources are
+	// observed but not changed, and report a Deferred condition. A managed
+	// resource may override it with the sql.crossplane.io/maintenance-window
+	// annotation. Changes are allowed at any time if it is unset.
+	// +optional
+	MaintenanceWindow *string `json:"maintenanceWindow,omitempty"`
 }
 
+// PoolerPgBouncerTransaction is a PgBouncer pooler in transaction pooling
+// mode, which may run each transaction on a different server connection.
+const PoolerPgBouncerTransaction = "pgbouncer-transaction"
+
 const (
 	// CredentialsSourcePostgreSQLConnectionSecret indicates that a provider
 	// should acquire credentials from a connection secret written by a managed
 	// resource that represents a PostgreSQL server.
 	CredentialsSourcePostgreSQLConnectionSecret xpv1.CredentialsSource = "PostgreSQLConnectionSecret"
+
+	// CredentialsSourceAzureAD indicates that a provider should connect
+	// using an Azure AD access token as its password, and acquire the other
+	// credentials from a connection secret.
+	CredentialsSourceAzureAD xpv1.CredentialsSource = "AzureAD"
 )
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
-	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=PostgreSQLConnectionSecret
+	// Source of the provider credentials. Environment and Filesystem read
+	// them from the environment or filesystem of the provider, e.g. secrets
+	// injected by Vault Agent or the Secrets Store CSI driver, instead of a
+	// connection secret. AzureAD reads the endpoint, port and username from
+	// the connection secret, and uses an Azure AD access token, which is
+	// renewed before it expires, as the password.
+	// +kubebuilder:validation:Enum=PostgreSQLConnectionSecret;Environment;Filesystem;AzureAD
 	Source xpv1.CredentialsSource `json:"source"`
 
+	// AzureADMethod is how Azure AD access tokens are acquired when the
+	// source is AzureAD. ActiveDirectoryWorkloadIdentity uses the AKS
+	// workload identity of the provider. ActiveDirectoryDefault uses the
+	// first credential available in the environment of the provider: a
+	// client secret or certificate, the workload identity, the managed
+	// identity of the host or the Azure CLI.
+	// +kubebuilder:validation:Enum=ActiveDirectoryDefault;ActiveDirectoryWorkloadIdentity
+	// +kubebuilder:default=ActiveDirectoryDefault
+	// +optional
+	AzureADMethod *string `json:"azureADMethod,omitempty"`
+
 	// A CredentialsSecretRef is a reference to a PostgreSQL connection secret
 	// that contains the credentials that must be used to connect to the
-	// provider. +optional
+	// provider. The secret may set protocol to tcp or unix. When it is unix,
+	// or unset and the endpoint is an absolute path, the endpoint is the
+	// directory containing the Unix domain socket of the server.
+	// +optional
 	ConnectionSecretRef *xpv1.SecretReference `json:"connectionSecretRef,omitempty"`
+
+	// Env is the environment variable of the provider that holds the
+	// credentials when the source is Environment. It holds a JSON object of
+	// connection secret keys, e.g. {"endpoint": "...", "username": "..."}.
+	// +optional
+	Env *xpv1.EnvSelector `json:"env,omitempty"`
+
+	// Fs is the path of the file or directory that holds the credentials
+	// when the source is Filesystem. A file holds a JSON object of connection
+	// secret keys. A directory, such as one mounted by the Secrets Store CSI
+	// driver or written by Vault Agent, holds a file named after each key.
+	// +optional
+	Fs *xpv1.FsSelector `json:"fs,omitempty"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
@@ -77,6 +185,15 @@ type ProviderConfig struct {
 	Status ProviderConfigStatus `json:"status,omitempty"`
 }
 
+// GetMaintenanceWindow returns the maintenance window of the ProviderConfig,
+// or an empty string if it has none.
+func (pc *ProviderConfig) GetMaintenanceWindow() string {
+	if pc.Spec.MaintenanceWindow == nil {
+		return ""
+	}
+	return *pc.Spec.MaintenanceWindow
+}
+
 // +kubebuilder:object:root=true
 
 // ProviderConfigList contains a list of ProviderConfig.
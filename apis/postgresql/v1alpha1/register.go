@@ -58,6 +58,14 @@ var (
 	ProviderConfigUsageListGroupVersionKind = SchemeGroupVersion.WithKind(ProviderConfigUsageListKind)
 )
 
+// StoreConfig type metadata.
+var (
+	StoreConfigKind             = reflect.TypeOf(StoreConfig{}).Name()
+	StoreConfigGroupKind        = schema.GroupKind{Group: Group, Kind: StoreConfigKind}.String()
+	StoreConfigKindAPIVersion   = StoreConfigKind + "." + SchemeGroupVersion.String()
+	StoreConfigGroupVersionKind = SchemeGroupVersion.WithKind(StoreConfigKind)
+)
+
 // Extension type metadata.
 var (
 	ExtensionKind             = reflect.TypeOf(Extension{}).Name()
@@ -98,12 +106,49 @@ var (
 	SchemaGroupVersionKind = SchemeGroupVersion.WithKind(SchemaKind)
 )
 
+// Collation type metadata.
+var (
+	CollationKind             = reflect.TypeOf(Collation{}).Name()
+	CollationGroupKind        = schema.GroupKind{Group: Group, Kind: CollationKind}.String()
+	CollationKindAPIVersion   = CollationKind + "." + SchemeGroupVersion.String()
+	CollationGroupVersionKind = SchemeGroupVersion.WithKind(CollationKind)
+)
+
+// Cast type metadata.
+var (
+	CastKind             = reflect.TypeOf(Cast{}).Name()
+	CastGroupKind        = schema.GroupKind{Group: Group, Kind: CastKind}.String()
+	CastKindAPIVersion   = CastKind + "." + SchemeGroupVersion.String()
+	CastGroupVersionKind = SchemeGroupVersion.WithKind(CastKind)
+)
+
+// HealthCheck type metadata.
+var (
+	HealthCheckKind             = reflect.TypeOf(HealthCheck{}).Name()
+	HealthCheckGroupKind        = schema.GroupKind{Group: Group, Kind: HealthCheckKind}.String()
+	HealthCheckKindAPIVersion   = HealthCheckKind + "." + SchemeGroupVersion.String()
+	HealthCheckGroupVersionKind = SchemeGroupVersion.WithKind(HealthCheckKind)
+)
+
+// DatabaseInstance type metadata.
+var (
+	DatabaseInstanceKind             = reflect.TypeOf(DatabaseInstance{}).Name()
+	DatabaseInstanceGroupKind        = schema.GroupKind{Group: Group, Kind: DatabaseInstanceKind}.String()
+	DatabaseInstanceKindAPIVersion   = DatabaseInstanceKind + "." + SchemeGroupVersion.String()
+	DatabaseInstanceGroupVersionKind = SchemeGroupVersion.WithKind(DatabaseInstanceKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
+	SchemeBuilder.Register(&StoreConfig{}, &StoreConfigList{})
 	SchemeBuilder.Register(&Database{}, &DatabaseList{})
+	SchemeBuilder.Register(&DatabaseInstance{}, &DatabaseInstanceList{})
 	SchemeBuilder.Register(&Role{}, &RoleList{})
 	SchemeBuilder.Register(&Grant{}, &GrantList{})
 	SchemeBuilder.Register(&Extension{}, &ExtensionList{})
 	SchemeBuilder.Register(&Schema{}, &SchemaList{})
+	SchemeBuilder.Register(&Collation{}, &CollationList{})
+	SchemeBuilder.Register(&Cast{}, &CastList{})
+	SchemeBuilder.Register(&HealthCheck{}, &HealthCheckList{})
 }
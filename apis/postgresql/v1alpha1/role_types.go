@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A RoleSpec defines the desired state of a Role.
@@ -31,6 +33,7 @@ type RoleSpec struct {
 // A RoleStatus represents the observed state of a Role.
 type RoleStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
 	AtProvider          RoleObservation `json:"atProvider,omitempty"`
 }
 
@@ -84,12 +87,54 @@ type RoleParameters struct {
 	// +optional
 	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
 
+	// HashedPassword states that PasswordSecretRef contains a SCRAM-SHA-256
+	// verifier, as stored in pg_authid, rather than a password. PostgreSQL
+	// stores the verifier as is, which allows roles to be migrated without
+	// knowing their passwords. The connection secret does not contain a
+	// password, and PasswordSecretRef is required.
+	// +optional
+	HashedPassword *bool `json:"hashedPassword,omitempty"`
+
 	// ConfigurationParameters to be applied to the role. If specified, any other configuration parameters set on the
 	// role in the database will be reset.
 	//
 	// See https://www.postgresql.org/docs/current/runtime-config-client.html for some available configuration parameters.
+	// Parameters unknown to the server are reported in the Ready condition and
+	// not applied. Customized options, i.e. those containing a dot, are not
+	// validated.
 	// +optional
 	ConfigurationParameters *[]RoleConfigurationParameter `json:"configurationParameters,omitempty"`
+
+	// Audit are the pgaudit settings of the role, which apply to the
+	// sessions it starts. They cannot be set by ConfigurationParameters
+	// too. Other pgaudit settings of the role are left untouched.
+	// +optional
+	Audit *AuditSettings `json:"audit,omitempty"`
+
+	// AdoptExisting records this resource as the owner of an existing role
+	// of the same name that records no owner, so that roles created outside
+	// of the provider can be brought under management. The password of an
+	// adopted role is left untouched until a PasswordSecretRef is given.
+	// +optional
+	AdoptExisting *bool `json:"adoptExisting,omitempty"`
+
+	// Members are the roles that are granted membership of this role, so
+	// that the memberships of a group role, typically one without LOGIN,
+	// are managed in one place. Members that aren't listed are revoked,
+	// unless KeepUnmanagedMembers is true. Memberships are left untouched
+	// if no members are listed.
+	// +optional
+	Members []string `json:"members,omitempty"`
+
+	// KeepUnmanagedMembers leaves memberships of roles that aren't listed
+	// in Members in place, so that only the listed memberships are managed.
+	// +optional
+	KeepUnmanagedMembers *bool `json:"keepUnmanagedMembers,omitempty"`
+
+	// Comment on the role, as set by COMMENT ON ROLE. An empty comment
+	// removes it. The comment is left untouched if omitted.
+	// +optional
+	Comment *string `json:"comment,omitempty"`
 }
 
 // RoleConfigurationParameter is a role configuration parameter.
@@ -105,6 +150,23 @@ type RoleObservation struct {
 	PrivilegesAsClauses []string `json:"privilegesAsClauses,omitempty"`
 	// ConfigurationParameters represents the applied configuration parameters for the PostgreSQL role.
 	ConfigurationParameters *[]RoleConfigurationParameter `json:"configurationParameters,omitempty"`
+	// Audit are the applied pgaudit settings of the role. They are only
+	// observed if audit settings are specified, and are not included in
+	// ConfigurationParameters then.
+	Audit *AuditSettings `json:"audit,omitempty"`
+	// Members are the roles that are members of this role. They are only
+	// observed if members are listed in the spec.
+	Members []string `json:"members,omitempty"`
+	// Comment is the comment on the role, without the line that records
+	// the resource that manages it.
+	Comment string `json:"comment,omitempty"`
+	// Name is the name the role was last observed with. The role is renamed
+	// to its external name if that changes.
+	Name string `json:"name,omitempty"`
+	// PasswordVerifierDigest is the SHA-256 digest of the SCRAM verifier last
+	// set as the password of a role with a hashed password. It tells when
+	// the verifier changes.
+	PasswordVerifierDigest string `json:"passwordVerifierDigest,omitempty"`
 }
 
 // +kubebuilder:object:root=true
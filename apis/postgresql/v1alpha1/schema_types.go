@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
 )
 
 // A SchemaSpec defines the desired state of a Schema.
@@ -33,6 +35,7 @@ type SchemaParameters struct {
 	// Role for ownership of this schema.
 	// +optional
 	// +crossplane:generate:reference:type=Role
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
 	Role *string `json:"role,omitempty"`
 
 	// RoleRef references the role object this schema is for.
@@ -48,6 +51,7 @@ type SchemaParameters struct {
 	// Database this schema is for.
 	// +optional
 	// +crossplane:generate:reference:type=Database
+	// +kubebuilder:validation:XValidation:rule="size(bytes(self)) <= 63",message="must be at most 63 bytes, the limit of PostgreSQL identifiers"
 	Database *string `json:"database,omitempty"`
 
 	// DatabaseRef references the database object this schema is for.
@@ -59,11 +63,62 @@ type SchemaParameters struct {
 	// +immutable
 	// +optional
 	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+
+	// DefaultPrivileges granted on objects the schema owner creates in this
+	// schema, as set by ALTER DEFAULT PRIVILEGES ... IN SCHEMA. Default
+	// privileges that are not listed are revoked. Existing default
+	// privileges are left alone when this field is omitted.
+	// +optional
+	DefaultPrivileges []SchemaDefaultPrivilege `json:"defaultPrivileges,omitempty"`
+
+	// DefaultOwner controls whether tables, views, sequences, routines and
+	// types in this schema that are not owned by role are reassigned to it.
+	// Enforce reassigns them whenever the schema is reconciled, which
+	// repairs objects left owned by another role, such as the one database
+	// migrations run as. The provider must be a member of both the current
+	// and the new owner. Defaults to Ignore.
+	// +kubebuilder:validation:Enum=Ignore;Enforce
+	// +optional
+	DefaultOwner *string `json:"defaultOwner,omitempty"`
+
+	// Comment on the schema, as set by COMMENT ON SCHEMA. An empty comment
+	// removes it. The comment is left untouched if omitted.
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+}
+
+// The modes of DefaultOwner.
+const (
+	DefaultOwnerIgnore  = "Ignore"
+	DefaultOwnerEnforce = "Enforce"
+)
+
+// A SchemaDefaultPrivilege grants privileges on a type of object to a role.
+type SchemaDefaultPrivilege struct {
+	// Role the privileges are granted to. Use PUBLIC to grant them to all
+	// roles.
+	Role string `json:"role"`
+
+	// ObjectType the privileges apply to.
+	// +kubebuilder:validation:Enum=TABLES;SEQUENCES;FUNCTIONS;TYPES
+	ObjectType string `json:"objectType"`
+
+	// Privileges to grant, for example SELECT or USAGE. Shorthands such as
+	// ALL are not supported.
+	Privileges GrantPrivileges `json:"privileges"`
 }
 
 // A SchemaStatus represents the observed state of a Schema.
 type SchemaStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          SchemaObservation `json:"atProvider,omitempty"`
+}
+
+// A SchemaObservation represents the observed state of a PostgreSQL schema.
+type SchemaObservation struct {
+	// Comment is the comment on the schema.
+	Comment string `json:"comment,omitempty"`
 }
 
 // +kubebuilder:object:root=true
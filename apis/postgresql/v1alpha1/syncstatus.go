@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// GetSyncStatus of this Cast.
+func (mg *Cast) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Collation.
+func (mg *Collation) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Database.
+func (mg *Database) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this DatabaseInstance.
+func (mg *DatabaseInstance) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Extension.
+func (mg *Extension) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Grant.
+func (mg *Grant) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this HealthCheck.
+func (mg *HealthCheck) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Role.
+func (mg *Role) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
+
+// GetSyncStatus of this Schema.
+func (mg *Schema) GetSyncStatus() *common.SyncStatus {
+	return &mg.Status.SyncStatus
+}
@@ -22,11 +22,42 @@ package v1alpha1
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Database) DeepCopyInto(out *Database) {
+func (in *AuditSettings) DeepCopyInto(out *AuditSettings) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = make([]AuditLogClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogRelation != nil {
+		in, out := &in.LogRelation, &out.LogRelation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogParameter != nil {
+		in, out := &in.LogParameter, &out.LogParameter
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSettings.
+func (in *AuditSettings) DeepCopy() *AuditSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cast) DeepCopyInto(out *Cast) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -34,18 +65,18 @@ func (in *Database) DeepCopyInto(out *Database) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Database.
-func (in *Database) DeepCopy() *Database {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cast.
+func (in *Cast) DeepCopy() *Cast {
 	if in == nil {
 		return nil
 	}
-	out := new(Database)
+	out := new(Cast)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Database) DeepCopyObject() runtime.Object {
+func (in *Cast) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -53,31 +84,31 @@ func (in *Database) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
+func (in *CastList) DeepCopyInto(out *CastList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Database, len(*in))
+		*out = make([]Cast, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseList.
-func (in *DatabaseList) DeepCopy() *DatabaseList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CastList.
+func (in *CastList) DeepCopy() *CastList {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseList)
+	out := new(CastList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DatabaseList) DeepCopyObject() runtime.Object {
+func (in *CastList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -85,100 +116,86 @@ func (in *DatabaseList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseParameters) DeepCopyInto(out *DatabaseParameters) {
+func (in *CastParameters) DeepCopyInto(out *CastParameters) {
 	*out = *in
-	if in.Owner != nil {
-		in, out := &in.Owner, &out.Owner
-		*out = new(string)
-		**out = **in
-	}
-	if in.Template != nil {
-		in, out := &in.Template, &out.Template
-		*out = new(string)
-		**out = **in
-	}
-	if in.Encoding != nil {
-		in, out := &in.Encoding, &out.Encoding
+	if in.Function != nil {
+		in, out := &in.Function, &out.Function
 		*out = new(string)
 		**out = **in
 	}
-	if in.LCCollate != nil {
-		in, out := &in.LCCollate, &out.LCCollate
-		*out = new(string)
+	if in.InOut != nil {
+		in, out := &in.InOut, &out.InOut
+		*out = new(bool)
 		**out = **in
 	}
-	if in.LCCType != nil {
-		in, out := &in.LCCType, &out.LCCType
+	if in.Context != nil {
+		in, out := &in.Context, &out.Context
 		*out = new(string)
 		**out = **in
 	}
-	if in.Tablespace != nil {
-		in, out := &in.Tablespace, &out.Tablespace
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
 		*out = new(string)
 		**out = **in
 	}
-	if in.AllowConnections != nil {
-		in, out := &in.AllowConnections, &out.AllowConnections
-		*out = new(bool)
-		**out = **in
-	}
-	if in.ConnectionLimit != nil {
-		in, out := &in.ConnectionLimit, &out.ConnectionLimit
-		*out = new(int)
-		**out = **in
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.IsTemplate != nil {
-		in, out := &in.IsTemplate, &out.IsTemplate
-		*out = new(bool)
-		**out = **in
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseParameters.
-func (in *DatabaseParameters) DeepCopy() *DatabaseParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CastParameters.
+func (in *CastParameters) DeepCopy() *CastParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseParameters)
+	out := new(CastParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
+func (in *CastSpec) DeepCopyInto(out *CastSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
-func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CastSpec.
+func (in *CastSpec) DeepCopy() *CastSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseSpec)
+	out := new(CastSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
+func (in *CastStatus) DeepCopyInto(out *CastStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
-func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CastStatus.
+func (in *CastStatus) DeepCopy() *CastStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseStatus)
+	out := new(CastStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Extension) DeepCopyInto(out *Extension) {
+func (in *Collation) DeepCopyInto(out *Collation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -186,18 +203,18 @@ func (in *Extension) DeepCopyInto(out *Extension) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extension.
-func (in *Extension) DeepCopy() *Extension {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Collation.
+func (in *Collation) DeepCopy() *Collation {
 	if in == nil {
 		return nil
 	}
-	out := new(Extension)
+	out := new(Collation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Extension) DeepCopyObject() runtime.Object {
+func (in *Collation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -205,31 +222,31 @@ func (in *Extension) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExtensionList) DeepCopyInto(out *ExtensionList) {
+func (in *CollationList) DeepCopyInto(out *CollationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Extension, len(*in))
+		*out = make([]Collation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionList.
-func (in *ExtensionList) DeepCopy() *ExtensionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollationList.
+func (in *CollationList) DeepCopy() *CollationList {
 	if in == nil {
 		return nil
 	}
-	out := new(ExtensionList)
+	out := new(CollationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ExtensionList) DeepCopyObject() runtime.Object {
+func (in *CollationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -237,10 +254,35 @@ func (in *ExtensionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
+func (in *CollationParameters) DeepCopyInto(out *CollationParameters) {
 	*out = *in
-	if in.Version != nil {
-		in, out := &in.Version, &out.Version
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(string)
+		**out = **in
+	}
+	if in.Locale != nil {
+		in, out := &in.Locale, &out.Locale
+		*out = new(string)
+		**out = **in
+	}
+	if in.LCCollate != nil {
+		in, out := &in.LCCollate, &out.LCCollate
+		*out = new(string)
+		**out = **in
+	}
+	if in.LCCtype != nil {
+		in, out := &in.LCCtype, &out.LCCtype
+		*out = new(string)
+		**out = **in
+	}
+	if in.Deterministic != nil {
+		in, out := &in.Deterministic, &out.Deterministic
+		*out = new(bool)
+		**out = **in
+	}
+	if in.From != nil {
+		in, out := &in.From, &out.From
 		*out = new(string)
 		**out = **in
 	}
@@ -249,6 +291,16 @@ func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SchemaRef != nil {
+		in, out := &in.SchemaRef, &out.SchemaRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SchemaSelector != nil {
+		in, out := &in.SchemaSelector, &out.SchemaSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Database != nil {
 		in, out := &in.Database, &out.Database
 		*out = new(string)
@@ -266,51 +318,851 @@ func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionParameters.
-func (in *ExtensionParameters) DeepCopy() *ExtensionParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollationParameters.
+func (in *CollationParameters) DeepCopy() *CollationParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(ExtensionParameters)
+	out := new(CollationParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExtensionSpec) DeepCopyInto(out *ExtensionSpec) {
+func (in *CollationSpec) DeepCopyInto(out *CollationSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionSpec.
-func (in *ExtensionSpec) DeepCopy() *ExtensionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollationSpec.
+func (in *CollationSpec) DeepCopy() *CollationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ExtensionSpec)
+	out := new(CollationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollationStatus) DeepCopyInto(out *CollationStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollationStatus.
+func (in *CollationStatus) DeepCopy() *CollationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Database) DeepCopyInto(out *Database) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Database.
+func (in *Database) DeepCopy() *Database {
+	if in == nil {
+		return nil
+	}
+	out := new(Database)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Database) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstance) DeepCopyInto(out *DatabaseInstance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstance.
+func (in *DatabaseInstance) DeepCopy() *DatabaseInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseInstance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceList) DeepCopyInto(out *DatabaseInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatabaseInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceList.
+func (in *DatabaseInstanceList) DeepCopy() *DatabaseInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseInstanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceObservation) DeepCopyInto(out *DatabaseInstanceObservation) {
+	*out = *in
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceObservation.
+func (in *DatabaseInstanceObservation) DeepCopy() *DatabaseInstanceObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceParameters) DeepCopyInto(out *DatabaseInstanceParameters) {
+	*out = *in
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(string)
+		**out = **in
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.ConnectionLimit != nil {
+		in, out := &in.ConnectionLimit, &out.ConnectionLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RevokePublic != nil {
+		in, out := &in.RevokePublic, &out.RevokePublic
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceParameters.
+func (in *DatabaseInstanceParameters) DeepCopy() *DatabaseInstanceParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceSpec) DeepCopyInto(out *DatabaseInstanceSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceSpec.
+func (in *DatabaseInstanceSpec) DeepCopy() *DatabaseInstanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceStatus) DeepCopyInto(out *DatabaseInstanceStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceStatus.
+func (in *DatabaseInstanceStatus) DeepCopy() *DatabaseInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Database, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseList.
+func (in *DatabaseList) DeepCopy() *DatabaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatabaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseObservation) DeepCopyInto(out *DatabaseObservation) {
+	*out = *in
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseObservation.
+func (in *DatabaseObservation) DeepCopy() *DatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseParameters) DeepCopyInto(out *DatabaseParameters) {
+	*out = *in
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReassignOwnedObjects != nil {
+		in, out := &in.ReassignOwnedObjects, &out.ReassignOwnedObjects
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(string)
+		**out = **in
+	}
+	if in.TerminateTemplateConnections != nil {
+		in, out := &in.TerminateTemplateConnections, &out.TerminateTemplateConnections
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(string)
+		**out = **in
+	}
+	if in.OID != nil {
+		in, out := &in.OID, &out.OID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(string)
+		**out = **in
+	}
+	if in.LCCollate != nil {
+		in, out := &in.LCCollate, &out.LCCollate
+		*out = new(string)
+		**out = **in
+	}
+	if in.LCCType != nil {
+		in, out := &in.LCCType, &out.LCCType
+		*out = new(string)
+		**out = **in
+	}
+	if in.LocaleProvider != nil {
+		in, out := &in.LocaleProvider, &out.LocaleProvider
+		*out = new(string)
+		**out = **in
+	}
+	if in.ICULocale != nil {
+		in, out := &in.ICULocale, &out.ICULocale
+		*out = new(string)
+		**out = **in
+	}
+	if in.CollationVersionRefresh != nil {
+		in, out := &in.CollationVersionRefresh, &out.CollationVersionRefresh
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Tablespace != nil {
+		in, out := &in.Tablespace, &out.Tablespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.AllowConnections != nil {
+		in, out := &in.AllowConnections, &out.AllowConnections
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ConnectionLimit != nil {
+		in, out := &in.ConnectionLimit, &out.ConnectionLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.IsTemplate != nil {
+		in, out := &in.IsTemplate, &out.IsTemplate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForceDrop != nil {
+		in, out := &in.ForceDrop, &out.ForceDrop
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseParameters.
+func (in *DatabaseParameters) DeepCopy() *DatabaseParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
+func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
+func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Extension) DeepCopyInto(out *Extension) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extension.
+func (in *Extension) DeepCopy() *Extension {
+	if in == nil {
+		return nil
+	}
+	out := new(Extension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Extension) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionList) DeepCopyInto(out *ExtensionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Extension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionList.
+func (in *ExtensionList) DeepCopy() *ExtensionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExtensionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionParameters.
+func (in *ExtensionParameters) DeepCopy() *ExtensionParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionSpec) DeepCopyInto(out *ExtensionSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionSpec.
+func (in *ExtensionSpec) DeepCopy() *ExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionStatus) DeepCopyInto(out *ExtensionStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionStatus.
+func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Grant) DeepCopyInto(out *Grant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Grant.
+func (in *Grant) DeepCopy() *Grant {
+	if in == nil {
+		return nil
+	}
+	out := new(Grant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Grant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantDatabaseObservation) DeepCopyInto(out *GrantDatabaseObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantDatabaseObservation.
+func (in *GrantDatabaseObservation) DeepCopy() *GrantDatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantDatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantList) DeepCopyInto(out *GrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Grant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantList.
+func (in *GrantList) DeepCopy() *GrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantObservation) DeepCopyInto(out *GrantObservation) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]GrantDatabaseObservation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantObservation.
+func (in *GrantObservation) DeepCopy() *GrantObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantParameters) DeepCopyInto(out *GrantParameters) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make(GrantPrivileges, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrivilegeSet != nil {
+		in, out := &in.PrivilegeSet, &out.PrivilegeSet
+		*out = new(string)
+		**out = **in
+	}
+	if in.PrivilegeProfile != nil {
+		in, out := &in.PrivilegeProfile, &out.PrivilegeProfile
+		*out = new(PrivilegeProfile)
+		**out = **in
+	}
+	if in.WithOption != nil {
+		in, out := &in.WithOption, &out.WithOption
+		*out = new(GrantOption)
+		**out = **in
+	}
+	if in.Role != nil {
+		in, out := &in.Role, &out.Role
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleRef != nil {
+		in, out := &in.RoleRef, &out.RoleRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleSelector != nil {
+		in, out := &in.RoleSelector, &out.RoleSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabasesSelector != nil {
+		in, out := &in.DatabasesSelector, &out.DatabasesSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevokeUnmanaged != nil {
+		in, out := &in.RevokeUnmanaged, &out.RevokeUnmanaged
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MemberOf != nil {
+		in, out := &in.MemberOf, &out.MemberOf
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemberOfRef != nil {
+		in, out := &in.MemberOfRef, &out.MemberOfRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberOfSelector != nil {
+		in, out := &in.MemberOfSelector, &out.MemberOfSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grantor != nil {
+		in, out := &in.Grantor, &out.Grantor
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sequences != nil {
+		in, out := &in.Sequences, &out.Sequences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routines != nil {
+		in, out := &in.Routines, &out.Routines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoutineType != nil {
+		in, out := &in.RoutineType, &out.RoutineType
+		*out = new(RoutineType)
+		**out = **in
+	}
+	if in.DefaultPrivilegesFor != nil {
+		in, out := &in.DefaultPrivilegesFor, &out.DefaultPrivilegesFor
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantParameters.
+func (in *GrantParameters) DeepCopy() *GrantParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in GrantPrivileges) DeepCopyInto(out *GrantPrivileges) {
+	{
+		in := &in
+		*out = make(GrantPrivileges, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantPrivileges.
+func (in GrantPrivileges) DeepCopy() GrantPrivileges {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantPrivileges)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantSpec) DeepCopyInto(out *GrantSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantSpec.
+func (in *GrantSpec) DeepCopy() *GrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExtensionStatus) DeepCopyInto(out *ExtensionStatus) {
+func (in *GrantStatus) DeepCopyInto(out *GrantStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionStatus.
-func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantStatus.
+func (in *GrantStatus) DeepCopy() *GrantStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ExtensionStatus)
+	out := new(GrantStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Grant) DeepCopyInto(out *Grant) {
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -318,18 +1170,18 @@ func (in *Grant) DeepCopyInto(out *Grant) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Grant.
-func (in *Grant) DeepCopy() *Grant {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(Grant)
+	out := new(HealthCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Grant) DeepCopyObject() runtime.Object {
+func (in *HealthCheck) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -337,31 +1189,31 @@ func (in *Grant) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantList) DeepCopyInto(out *GrantList) {
+func (in *HealthCheckList) DeepCopyInto(out *HealthCheckList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Grant, len(*in))
+		*out = make([]HealthCheck, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantList.
-func (in *GrantList) DeepCopy() *GrantList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckList.
+func (in *HealthCheckList) DeepCopy() *HealthCheckList {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantList)
+	out := new(HealthCheckList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrantList) DeepCopyObject() runtime.Object {
+func (in *HealthCheckList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -369,32 +1221,46 @@ func (in *GrantList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantParameters) DeepCopyInto(out *GrantParameters) {
+func (in *HealthCheckObservation) DeepCopyInto(out *HealthCheckObservation) {
 	*out = *in
-	if in.Privileges != nil {
-		in, out := &in.Privileges, &out.Privileges
-		*out = make(GrantPrivileges, len(*in))
-		copy(*out, *in)
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
 	}
-	if in.WithOption != nil {
-		in, out := &in.WithOption, &out.WithOption
-		*out = new(GrantOption)
+	if in.LatencyMilliseconds != nil {
+		in, out := &in.LatencyMilliseconds, &out.LatencyMilliseconds
+		*out = new(int64)
 		**out = **in
 	}
-	if in.Role != nil {
-		in, out := &in.Role, &out.Role
-		*out = new(string)
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		*out = new(int64)
 		**out = **in
 	}
-	if in.RoleRef != nil {
-		in, out := &in.RoleRef, &out.RoleRef
-		*out = new(v1.Reference)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckObservation.
+func (in *HealthCheckObservation) DeepCopy() *HealthCheckObservation {
+	if in == nil {
+		return nil
 	}
-	if in.RoleSelector != nil {
-		in, out := &in.RoleSelector, &out.RoleSelector
-		*out = new(v1.Selector)
-		(*in).DeepCopyInto(*out)
+	out := new(HealthCheckObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckParameters) DeepCopyInto(out *HealthCheckParameters) {
+	*out = *in
+	if in.MinRows != nil {
+		in, out := &in.MinRows, &out.MinRows
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 	if in.Database != nil {
 		in, out := &in.Database, &out.Database
@@ -411,81 +1277,49 @@ func (in *GrantParameters) DeepCopyInto(out *GrantParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.MemberOf != nil {
-		in, out := &in.MemberOf, &out.MemberOf
-		*out = new(string)
-		**out = **in
-	}
-	if in.MemberOfRef != nil {
-		in, out := &in.MemberOfRef, &out.MemberOfRef
-		*out = new(v1.Reference)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.MemberOfSelector != nil {
-		in, out := &in.MemberOfSelector, &out.MemberOfSelector
-		*out = new(v1.Selector)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantParameters.
-func (in *GrantParameters) DeepCopy() *GrantParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckParameters.
+func (in *HealthCheckParameters) DeepCopy() *HealthCheckParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantParameters)
+	out := new(HealthCheckParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in GrantPrivileges) DeepCopyInto(out *GrantPrivileges) {
-	{
-		in := &in
-		*out = make(GrantPrivileges, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantPrivileges.
-func (in GrantPrivileges) DeepCopy() GrantPrivileges {
-	if in == nil {
-		return nil
-	}
-	out := new(GrantPrivileges)
-	in.DeepCopyInto(out)
-	return *out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantSpec) DeepCopyInto(out *GrantSpec) {
+func (in *HealthCheckSpec) DeepCopyInto(out *HealthCheckSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantSpec.
-func (in *GrantSpec) DeepCopy() *GrantSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckSpec.
+func (in *HealthCheckSpec) DeepCopy() *HealthCheckSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantSpec)
+	out := new(HealthCheckSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrantStatus) DeepCopyInto(out *GrantStatus) {
+func (in *HealthCheckStatus) DeepCopyInto(out *HealthCheckStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantStatus.
-func (in *GrantStatus) DeepCopy() *GrantStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckStatus.
+func (in *HealthCheckStatus) DeepCopy() *HealthCheckStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GrantStatus)
+	out := new(HealthCheckStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -558,6 +1392,59 @@ func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.CACertSecretRef != nil {
+		in, out := &in.CACertSecretRef, &out.CACertSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.ConnectionOptions != nil {
+		in, out := &in.ConnectionOptions, &out.ConnectionOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxOpenConnections != nil {
+		in, out := &in.MaxOpenConnections, &out.MaxOpenConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxIdleConnections != nil {
+		in, out := &in.MaxIdleConnections, &out.MaxIdleConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LockTimeout != nil {
+		in, out := &in.LockTimeout, &out.LockTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Pooler != nil {
+		in, out := &in.Pooler, &out.Pooler
+		*out = new(string)
+		**out = **in
+	}
+	if in.PrivilegeSets != nil {
+		in, out := &in.PrivilegeSets, &out.PrivilegeSets
+		*out = make(map[string]GrantPrivileges, len(*in))
+		for key, val := range *in {
+			var outVal []GrantPrivilege
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(GrantPrivileges, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
@@ -647,11 +1534,26 @@ func (in *ProviderConfigUsageList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderCredentials) DeepCopyInto(out *ProviderCredentials) {
 	*out = *in
+	if in.AzureADMethod != nil {
+		in, out := &in.AzureADMethod, &out.AzureADMethod
+		*out = new(string)
+		**out = **in
+	}
 	if in.ConnectionSecretRef != nil {
 		in, out := &in.ConnectionSecretRef, &out.ConnectionSecretRef
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = new(v1.EnvSelector)
+		**out = **in
+	}
+	if in.Fs != nil {
+		in, out := &in.Fs, &out.Fs
+		*out = new(v1.FsSelector)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderCredentials.
@@ -755,6 +1657,16 @@ func (in *RoleObservation) DeepCopyInto(out *RoleObservation) {
 			copy(*out, *in)
 		}
 	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleObservation.
@@ -781,6 +1693,11 @@ func (in *RoleParameters) DeepCopyInto(out *RoleParameters) {
 		*out = new(v1.SecretKeySelector)
 		**out = **in
 	}
+	if in.HashedPassword != nil {
+		in, out := &in.HashedPassword, &out.HashedPassword
+		*out = new(bool)
+		**out = **in
+	}
 	if in.ConfigurationParameters != nil {
 		in, out := &in.ConfigurationParameters, &out.ConfigurationParameters
 		*out = new([]RoleConfigurationParameter)
@@ -790,6 +1707,31 @@ func (in *RoleParameters) DeepCopyInto(out *RoleParameters) {
 			copy(*out, *in)
 		}
 	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdoptExisting != nil {
+		in, out := &in.AdoptExisting, &out.AdoptExisting
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeepUnmanagedMembers != nil {
+		in, out := &in.KeepUnmanagedMembers, &out.KeepUnmanagedMembers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleParameters.
@@ -873,6 +1815,7 @@ func (in *RoleSpec) DeepCopy() *RoleSpec {
 func (in *RoleStatus) DeepCopyInto(out *RoleStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
 	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
@@ -913,6 +1856,26 @@ func (in *Schema) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaDefaultPrivilege) DeepCopyInto(out *SchemaDefaultPrivilege) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make(GrantPrivileges, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaDefaultPrivilege.
+func (in *SchemaDefaultPrivilege) DeepCopy() *SchemaDefaultPrivilege {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaDefaultPrivilege)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SchemaList) DeepCopyInto(out *SchemaList) {
 	*out = *in
@@ -945,6 +1908,21 @@ func (in *SchemaList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaObservation) DeepCopyInto(out *SchemaObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaObservation.
+func (in *SchemaObservation) DeepCopy() *SchemaObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SchemaParameters) DeepCopyInto(out *SchemaParameters) {
 	*out = *in
@@ -978,6 +1956,23 @@ func (in *SchemaParameters) DeepCopyInto(out *SchemaParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultPrivileges != nil {
+		in, out := &in.DefaultPrivileges, &out.DefaultPrivileges
+		*out = make([]SchemaDefaultPrivilege, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultOwner != nil {
+		in, out := &in.DefaultOwner, &out.DefaultOwner
+		*out = new(string)
+		**out = **in
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaParameters.
@@ -1011,6 +2006,8 @@ func (in *SchemaSpec) DeepCopy() *SchemaSpec {
 func (in *SchemaStatus) DeepCopyInto(out *SchemaStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	out.AtProvider = in.AtProvider
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaStatus.
@@ -1022,3 +2019,94 @@ func (in *SchemaStatus) DeepCopy() *SchemaStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfig) DeepCopyInto(out *StoreConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfig.
+func (in *StoreConfig) DeepCopy() *StoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoreConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigList) DeepCopyInto(out *StoreConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StoreConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigList.
+func (in *StoreConfigList) DeepCopy() *StoreConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoreConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigSpec) DeepCopyInto(out *StoreConfigSpec) {
+	*out = *in
+	in.SecretStoreConfig.DeepCopyInto(&out.SecretStoreConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigSpec.
+func (in *StoreConfigSpec) DeepCopy() *StoreConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreConfigStatus) DeepCopyInto(out *StoreConfigStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreConfigStatus.
+func (in *StoreConfigStatus) DeepCopy() *StoreConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A GrantSpec defines the desired state of a Grant.
+type GrantSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       GrantParameters `json:"forProvider"`
+}
+
+// GrantPrivilege represents a privilege to be granted
+// +kubebuilder:validation:Pattern:=^[A-Z]+$
+type GrantPrivilege string
+
+// GrantPrivileges is a list of the privileges to be granted
+type GrantPrivileges []GrantPrivilege
+
+// ToStringSlice converts the slice of privileges to strings
+func (gp *GrantPrivileges) ToStringSlice() []string {
+	if gp == nil {
+		return []string{}
+	}
+	out := make([]string, len(*gp))
+	for i, v := range *gp {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// GrantParameters define the desired state of a Redshift grant instance.
+// Privileges are granted on the table if a table is set, otherwise on the
+// schema if a schema is set, otherwise on the database. They are granted to
+// either a user or a group.
+type GrantParameters struct {
+	// Privileges to be granted, e.g. CREATE and USAGE on a schema or SELECT
+	// on a table. See
+	// https://docs.aws.amazon.com/redshift/latest/dg/r_GRANT.html for the
+	// available privileges. ALL is not supported.
+	// +kubebuilder:validation:MinItems:=1
+	Privileges GrantPrivileges `json:"privileges"`
+
+	// User this grant is for.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=User
+	User *string `json:"user,omitempty"`
+
+	// UserRef references the user object this grant is for.
+	// +immutable
+	// +optional
+	UserRef *xpv1.Reference `json:"userRef,omitempty"`
+
+	// UserSelector selects a reference to a User this grant is for.
+	// +immutable
+	// +optional
+	UserSelector *xpv1.Selector `json:"userSelector,omitempty"`
+
+	// Group this grant is for.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Group
+	Group *string `json:"group,omitempty"`
+
+	// GroupRef references the group object this grant is for.
+	// +immutable
+	// +optional
+	GroupRef *xpv1.Reference `json:"groupRef,omitempty"`
+
+	// GroupSelector selects a reference to a Group this grant is for.
+	// +immutable
+	// +optional
+	GroupSelector *xpv1.Selector `json:"groupSelector,omitempty"`
+
+	// Database this grant is for. Defaults to the defaultDatabase of the
+	// ProviderConfig, on which privileges are granted only if it is set
+	// explicitly and no schema is set.
+	// +immutable
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// Schema this grant is for, in the database of this grant.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Schema
+	Schema *string `json:"schema,omitempty"`
+
+	// SchemaRef references the schema object this grant is for.
+	// +immutable
+	// +optional
+	SchemaRef *xpv1.Reference `json:"schemaRef,omitempty"`
+
+	// SchemaSelector selects a reference to a Schema this grant is for.
+	// +immutable
+	// +optional
+	SchemaSelector *xpv1.Selector `json:"schemaSelector,omitempty"`
+
+	// Table this grant is for, in the schema of this grant.
+	// +immutable
+	// +optional
+	Table *string `json:"table,omitempty"`
+}
+
+// A GrantStatus represents the observed state of a Grant.
+type GrantStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          GrantObservation `json:"atProvider,omitempty"`
+}
+
+// A GrantObservation represents the observed state of a Redshift grant.
+type GrantObservation struct {
+	// Privileges represents the applied privileges
+	Privileges []string `json:"privileges,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Grant represents the declarative state of a Redshift grant.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="USER",type="string",JSONPath=".spec.forProvider.user"
+// +kubebuilder:printcolumn:name="GROUP",type="string",JSONPath=".spec.forProvider.group"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="SCHEMA",type="string",JSONPath=".spec.forProvider.schema"
+// +kubebuilder:printcolumn:name="TABLE",type="string",JSONPath=".spec.forProvider.table"
+// +kubebuilder:printcolumn:name="PRIVILEGES",type="string",JSONPath=".spec.forProvider.privileges"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Grant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrantSpec   `json:"spec"`
+	Status GrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GrantList contains a list of Grant
+type GrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Grant `json:"items"`
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A UserSpec defines the desired state of a User.
+type UserSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UserParameters `json:"forProvider"`
+}
+
+// UserParameters define the desired state of a Redshift user.
+type UserParameters struct {
+	// PasswordSecretRef references the secret that contains the password used
+	// for this user. If no reference is given, a password will be
+	// auto-generated. Redshift requires passwords of 8 to 64 characters that
+	// contain an uppercase letter, a lowercase letter and a digit.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// CreateDB allows the user to create databases.
+	// +optional
+	CreateDB *bool `json:"createDB,omitempty"`
+
+	// CreateUser makes the user a superuser, which may create other users.
+	// +optional
+	CreateUser *bool `json:"createUser,omitempty"`
+
+	// ConnectionLimit is the maximum number of connections the user may
+	// have open at once. Unset does not limit them.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// Groups the user is a member of. When set, the user is added to and
+	// removed from groups so that it is a member of exactly these.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// A UserStatus represents the observed state of a User.
+type UserStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          UserObservation `json:"atProvider,omitempty"`
+}
+
+// A UserObservation represents the observed state of a Redshift user.
+type UserObservation struct {
+	// CreateDB is true if the user may create databases.
+	CreateDB *bool `json:"createDB,omitempty"`
+
+	// CreateUser is true if the user is a superuser.
+	CreateUser *bool `json:"createUser,omitempty"`
+
+	// ConnectionLimit is the maximum number of connections the user may
+	// have open at once, if it is limited.
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// Groups the user is a member of.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A User represents the declarative state of a Redshift user.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+)
+
+// A GrantSpec defines the desired state of a Grant.
+type GrantSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       GrantParameters `json:"forProvider"`
+}
+
+// GrantPrivilege represents a privilege to be granted
+// +kubebuilder:validation:Pattern:=^[A-Z ]+$
+type GrantPrivilege string
+
+// GrantPrivileges is a list of the privileges to be granted
+type GrantPrivileges []GrantPrivilege
+
+// ToStringSlice converts the slice of privileges to strings
+func (gp *GrantPrivileges) ToStringSlice() []string {
+	if gp == nil {
+		return []string{}
+	}
+	out := make([]string, len(*gp))
+	for i, v := range *gp {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// GrantParameters define the desired state of a Snowflake grant instance.
+// Privileges are granted on the schema if a schema is set, otherwise on the
+// database if a database is set, otherwise on the account. If memberOf is
+// set the role is instead granted to the grantee role.
+type GrantParameters struct {
+	// Privileges to be granted.
+	// See https://docs.snowflake.com/en/user-guide/security-access-control-privileges
+	// for available privileges. OWNERSHIP is not supported.
+	// +optional
+	Privileges GrantPrivileges `json:"privileges,omitempty"`
+
+	// OnFuture grants the privileges on the objects of this type that will
+	// be created in the schema, or in the database if no schema is set.
+	// +kubebuilder:validation:Enum=TABLES;VIEWS;SEQUENCES;STAGES;FUNCTIONS;PROCEDURES
+	// +immutable
+	// +optional
+	OnFuture *string `json:"onFuture,omitempty"`
+
+	// WithGrantOption allows the grantee role to grant the privileges to
+	// other roles.
+	// +immutable
+	// +optional
+	WithGrantOption *bool `json:"withGrantOption,omitempty"`
+
+	// Role this grant is for.
+	// +optional
+	// +crossplane:generate:reference:type=Role
+	Role *string `json:"role,omitempty"`
+
+	// RoleRef references the role object this grant is for.
+	// +immutable
+	// +optional
+	RoleRef *xpv1.Reference `json:"roleRef,omitempty"`
+
+	// RoleSelector selects a reference to a Role this grant is for.
+	// +immutable
+	// +optional
+	RoleSelector *xpv1.Selector `json:"roleSelector,omitempty"`
+
+	// Database this grant is for.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Database
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object this grant is for.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database this grant is for.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+
+	// Schema this grant is for, in the database of this grant.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Schema
+	Schema *string `json:"schema,omitempty"`
+
+	// SchemaRef references the schema object this grant is for.
+	// +immutable
+	// +optional
+	SchemaRef *xpv1.Reference `json:"schemaRef,omitempty"`
+
+	// SchemaSelector selects a reference to a Schema this grant is for.
+	// +immutable
+	// +optional
+	SchemaSelector *xpv1.Selector `json:"schemaSelector,omitempty"`
+
+	// MemberOf is the role that the grantee role will be granted.
+	// +immutable
+	// +optional
+	// +crossplane:generate:reference:type=Role
+	MemberOf *string `json:"memberOf,omitempty"`
+
+	// MemberOfRef references the role that the grantee role will be granted.
+	// +immutable
+	// +optional
+	MemberOfRef *xpv1.Reference `json:"memberOfRef,omitempty"`
+
+	// MemberOfSelector selects a reference to a Role that the grantee role
+	// will be granted.
+	// +immutable
+	// +optional
+	MemberOfSelector *xpv1.Selector `json:"memberOfSelector,omitempty"`
+}
+
+// A GrantStatus represents the observed state of a Grant.
+type GrantStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	common.SyncStatus   `json:",inline"`
+	AtProvider          GrantObservation `json:"atProvider,omitempty"`
+}
+
+// A GrantObservation represents the observed state of a Snowflake grant.
+type GrantObservation struct {
+	// Privileges represents the applied privileges
+	Privileges []string `json:"privileges,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Grant represents the declarative state of a Snowflake grant.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".spec.forProvider.role"
+// +kubebuilder:printcolumn:name="MEMBER OF",type="string",JSONPath=".spec.forProvider.memberOf"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:printcolumn:name="SCHEMA",type="string",JSONPath=".spec.forProvider.schema"
+// +kubebuilder:printcolumn:name="PRIVILEGES",type="string",JSONPath=".spec.forProvider.privileges"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Grant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrantSpec   `json:"spec"`
+	Status GrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GrantList contains a list of Grant
+type GrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Grant `json:"items"`
+}
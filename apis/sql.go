@@ -18,19 +18,31 @@ limitations under the License.
 package apis
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	clickhouse "github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
 	mssql "github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
 	mysql "github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	oracle "github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
 	postgresql "github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	redshift "github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	snowflake "github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
 )
 
 func init() {
 	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
 	AddToSchemes = append(AddToSchemes,
+		clickhouse.SchemeBuilder.AddToScheme,
 		mssql.SchemeBuilder.AddToScheme,
 		mysql.SchemeBuilder.AddToScheme,
+		oracle.SchemeBuilder.AddToScheme,
 		postgresql.SchemeBuilder.AddToScheme,
+		redshift.SchemeBuilder.AddToScheme,
+		snowflake.SchemeBuilder.AddToScheme,
 	)
 }
 
@@ -41,3 +53,20 @@ var AddToSchemes runtime.SchemeBuilder
 func AddToScheme(s *runtime.Scheme) error {
 	return AddToSchemes.AddToScheme(s)
 }
+
+// DefaultStoreConfigs returns the StoreConfig named default of every API
+// group, which managed resources publish connection details to unless they
+// name another one. They store them as Secrets in the supplied namespace.
+func DefaultStoreConfigs(namespace string) []client.Object {
+	meta := metav1.ObjectMeta{Name: "default"}
+	cfg := xpv1.SecretStoreConfig{DefaultScope: namespace}
+	return []client.Object{
+		&clickhouse.StoreConfig{ObjectMeta: meta, Spec: clickhouse.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&mssql.StoreConfig{ObjectMeta: meta, Spec: mssql.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&mysql.StoreConfig{ObjectMeta: meta, Spec: mysql.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&oracle.StoreConfig{ObjectMeta: meta, Spec: oracle.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&postgresql.StoreConfig{ObjectMeta: meta, Spec: postgresql.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&redshift.StoreConfig{ObjectMeta: meta, Spec: redshift.StoreConfigSpec{SecretStoreConfig: cfg}},
+		&snowflake.StoreConfig{ObjectMeta: meta, Spec: snowflake.StoreConfigSpec{SecretStoreConfig: cfg}},
+	}
+}
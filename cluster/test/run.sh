@@ -0,0 +1,146 @@
+#!/usr/bin/env bash
+# Runs the declarative acceptance test cases against a kind cluster with
+# Crossplane, this provider and throwaway databases installed.
+#
+# Environment:
+#   CASES_DIR             directory of test cases, defaults to cluster/test/cases
+#   DATABASES             databases to deploy, defaults to "mysql postgresql"
+#   PROVIDER_PACKAGE      provider package to install; when unset the provider
+#                         is built from this tree and run out of cluster
+#   USE_EXISTING_CLUSTER  set to true to use the current kubeconfig context
+#   TIMEOUT               how long to wait for each assertion, defaults to 3m
+#   skipcleanup           set to true to keep the cluster afterwards
+set -e
+
+BLU='\033[0;34m'
+GRN='\033[0;32m'
+RED='\033[0;31m'
+NOC='\033[0m' # No Color
+echo_step() {
+    printf "\n${BLU}>>>>>>> %s${NOC}\n" "$1"
+}
+echo_success() {
+    printf "\n${GRN}%s${NOC}\n" "$1"
+}
+echo_fail() {
+    printf "\n${RED}%s${NOC}\n" "$1"
+}
+
+# ------------------------------
+projectdir="$( cd "$( dirname "${BASH_SOURCE[0]}")"/../.. && pwd )"
+testdir="${projectdir}/cluster/test"
+
+KIND="${KIND:-kind}"
+KUBECTL="${KUBECTL:-kubectl}"
+HELM="${HELM:-helm}"
+K8S_CLUSTER="${K8S_CLUSTER:-provider-sql-acceptance}"
+CASES_DIR="${CASES_DIR:-${testdir}/cases}"
+DATABASES="${DATABASES:-mysql postgresql}"
+TIMEOUT="${TIMEOUT:-3m}"
+
+provider_pid=""
+
+cleanup() {
+  echo_step "cleaning up"
+  if [ -n "${provider_pid}" ]; then
+    kill "${provider_pid}" 2>/dev/null || true
+  fi
+  if [ "${USE_EXISTING_CLUSTER}" != true ]; then
+    "${KIND}" delete cluster --name="${K8S_CLUSTER}"
+  fi
+}
+
+if [ "$skipcleanup" != true ]; then
+  trap cleanup EXIT
+fi
+
+setup_cluster() {
+  if [ "${USE_EXISTING_CLUSTER}" == true ]; then
+    echo_step "using the current kubeconfig context"
+    return
+  fi
+  echo_step "creating kind cluster ${K8S_CLUSTER}"
+  "${KIND}" create cluster --name="${K8S_CLUSTER}" --wait=5m
+}
+
+setup_crossplane() {
+  echo_step "installing crossplane from stable channel"
+  "${HELM}" repo add crossplane-stable https://charts.crossplane.io/stable/ --force-update
+  "${HELM}" upgrade --install crossplane --namespace crossplane-system --create-namespace crossplane-stable/crossplane --wait
+}
+
+setup_provider() {
+  if [ -n "${PROVIDER_PACKAGE}" ]; then
+    echo_step "installing provider package ${PROVIDER_PACKAGE}"
+    local yaml="$( cat <<EOF
+apiVersion: pkg.crossplane.io/v1
+kind: Provider
+metadata:
+  name: provider-sql
+spec:
+  package: "${PROVIDER_PACKAGE}"
+EOF
+    )"
+    echo "${yaml}" | "${KUBECTL}" apply -f -
+    "${KUBECTL}" wait provider.pkg.crossplane.io/provider-sql --for=condition=healthy --timeout=5m
+    return
+  fi
+
+  echo_step "installing CRDs and running the provider from this tree"
+  "${KUBECTL}" apply --server-side -R -f "${projectdir}/package/crds"
+  (cd "${projectdir}" && go run ./cmd/provider --debug) > "${testdir}/provider.log" 2>&1 &
+  provider_pid=$!
+}
+
+setup_databases() {
+  for db in ${DATABASES}; do
+    echo_step "deploying ${db}"
+    "${KUBECTL}" apply -f "${testdir}/setup/${db}.yaml"
+    "${KUBECTL}" rollout status "deployment/${db}" --timeout=5m
+  done
+}
+
+# run_case applies the manifests of a test case, waits for every assertion in
+# its assert file and then deletes the manifests again.
+run_case() {
+  local dir="$1"
+  local name="$(basename "${dir}")"
+  local ok=true
+
+  echo_step "case ${name}"
+  "${KUBECTL}" apply -f "${dir}" || return 1
+
+  if [ -f "${dir}/assert" ]; then
+    while read -r resource condition; do
+      case "${resource}" in
+        ''|'#'*) continue ;;
+      esac
+      if ! "${KUBECTL}" wait --timeout="${TIMEOUT}" --for="${condition}" "${resource}"; then
+        "${KUBECTL}" describe "${resource}" || true
+        ok=false
+      fi
+    done < "${dir}/assert"
+  fi
+
+  "${KUBECTL}" delete -f "${dir}" --wait --timeout="${TIMEOUT}" || ok=false
+  [ "${ok}" == true ]
+}
+
+setup_cluster
+setup_crossplane
+setup_provider
+setup_databases
+
+failed=()
+for dir in "${CASES_DIR}"/*/; do
+  if ! run_case "${dir%/}"; then
+    failed+=("$(basename "${dir}")")
+  fi
+done
+
+if [ ${#failed[@]} -ne 0 ]; then
+  echo_fail "failed cases: ${failed[*]}"
+  exit 1
+fi
+
+echo_success "Acceptance tests succeeded!"
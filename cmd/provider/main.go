@@ -17,23 +17,37 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/ClickHouse/clickhouse-go/v2"
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
+	_ "github.com/sijms/go-ora/v2"
+	_ "github.com/snowflakedb/gosnowflake"
 
 	"gopkg.in/alecthomas/kingpin.v2"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/crossplane/crossplane-runtime/pkg/certificates"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/role"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 func main() {
@@ -43,6 +57,23 @@ func main() {
 		pollInterval   = app.Flag("poll", "Poll interval controls how often an individual resource should be checked for drift.").Default("10m").Duration()
 		syncPeriod     = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
 		leaderElection = app.Flag("leader-election", "Use leader election for the conroller manager.").Short('l').Default("false").Envar("LEADER_ELECTION").Bool()
+		logSQL         = app.Flag("log-sql", "Log executed SQL statements, with literals redacted. Requires debug logging.").Default("false").Envar("LOG_SQL").Bool()
+		offlineDelete  = app.Flag("allow-offline-deletion", "Remove the finalizer of deleted managed resources whose ProviderConfig or credentials Secret no longer exists, without deleting the external resource.").Default("false").Envar("ALLOW_OFFLINE_DELETION").Bool()
+		offlineAfter   = app.Flag("offline-delete-after", "Also remove the finalizer of deleted managed resources that may be deleted offline once connecting to delete their external resource has failed this many times in a row. Zero disables it.").Default("0").Envar("OFFLINE_DELETE_AFTER").Int()
+		deleteBackoff  = app.Flag("max-delete-backoff", "Longest delay between attempts to delete an external resource whose deletion keeps failing.").Default("30m").Envar("MAX_DELETE_BACKOFF").Duration()
+		otlpEndpoint   = app.Flag("otlp-endpoint", "Export OpenTelemetry traces of reconciles and SQL statements to this OTLP/HTTP collector, such as otel-collector:4318. Tracing is disabled when unset.").Envar("OTLP_ENDPOINT").String()
+		otlpInsecure   = app.Flag("otlp-insecure", "Connect to the OTLP collector without TLS.").Default("false").Envar("OTLP_INSECURE").Bool()
+		traceRatio     = app.Flag("trace-sample-ratio", "Fraction of reconciles to trace, between 0 and 1.").Default("1").Envar("TRACE_SAMPLE_RATIO").Float64()
+		leaseDuration  = app.Flag("leader-election-lease-duration", "Duration that non-leader candidates wait before attempting to acquire leadership.").Default("15s").Envar("LEADER_ELECTION_LEASE_DURATION").Duration()
+		renewDeadline  = app.Flag("leader-election-renew-deadline", "Duration that the leader retries refreshing leadership before giving it up.").Default("10s").Envar("LEADER_ELECTION_RENEW_DEADLINE").Duration()
+		retryPeriod    = app.Flag("leader-election-retry-period", "Duration that candidates wait between attempts to acquire or renew leadership.").Default("2s").Envar("LEADER_ELECTION_RETRY_PERIOD").Duration()
+		shutdownPeriod = app.Flag("graceful-shutdown-timeout", "Duration to wait on shutdown for running reconciles to finish, and then for open database connections to be closed.").Default("30s").Envar("GRACEFUL_SHUTDOWN_TIMEOUT").Duration()
+		mgmtPolicies   = app.Flag("enable-management-policies", "Honor spec.managementPolicies on managed resources that support them.").Default("true").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+		noLateInit     = app.Flag("disable-late-initialization", "Do not copy the observed privileges and connection limit of existing PostgreSQL roles into the spec of Roles that omit them.").Default("false").Envar("DISABLE_LATE_INITIALIZATION").Bool()
+		validateSQL    = app.Flag("validate-sql", "Check the syntax of PostgreSQL and MSSQL statements before executing them, so that a syntax error leaves no statement of an operation executed.").Default("false").Envar("VALIDATE_SQL").Bool()
+		essEnabled     = app.Flag("enable-external-secret-stores", "Also publish connection details to the external secret stores configured by StoreConfigs, such as Vault through an ESS plugin.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
+		essTLSCertsDir = app.Flag("ess-tls-cert-dir", "Directory of the ca.crt, tls.crt and tls.key used to connect to ESS plugins.").Envar("ESS_TLS_CERTS_DIR").String()
+		namespace      = app.Flag("namespace", "Namespace in which the default StoreConfigs store the connection secrets of managed resources.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -55,6 +86,22 @@ func main() {
 		ctrl.SetLogger(zl)
 	}
 
+	xsql.LogStatements = *logSQL
+	xsql.ValidateStatements = *validateSQL
+	offline.AllowDeletion = *offlineDelete
+	offline.DeleteAfter = *offlineAfter
+	deletion.MaxDelay = *deleteBackoff
+
+	if *otlpEndpoint != "" {
+		shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+			Endpoint:    *otlpEndpoint,
+			Insecure:    *otlpInsecure,
+			SampleRatio: *traceRatio,
+		})
+		kingpin.FatalIfError(err, "Cannot set up tracing")
+		defer shutdown(context.Background()) //nolint:errcheck // Spans that cannot be flushed on exit are dropped.
+	}
+
 	log.Debug("Starting", "sync-period", syncPeriod.String())
 
 	cfg, err := ctrl.GetConfig()
@@ -63,6 +110,13 @@ func main() {
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		LeaderElection:   *leaderElection,
 		LeaderElectionID: "crossplane-leader-election-provider-sql",
+		// The provider exits as soon as the manager stops, so the lease can
+		// be released for the next leader rather than left to expire.
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 leaseDuration,
+		RenewDeadline:                 renewDeadline,
+		RetryPeriod:                   retryPeriod,
+		GracefulShutdownTimeout:       shutdownPeriod,
 		Cache: cache.Options{
 			SyncPeriod: syncPeriod,
 		},
@@ -73,8 +127,49 @@ func main() {
 	o := xpcontroller.Options{
 		Logger:       log,
 		PollInterval: *pollInterval,
+		Features:     &feature.Flags{},
+	}
+
+	if *mgmtPolicies {
+		o.Features.Enable(feature.EnableBetaManagementPolicies)
+		log.Info("Beta feature enabled", "flag", feature.EnableBetaManagementPolicies)
+	}
+
+	if *noLateInit {
+		o.Features.Enable(role.DisableLateInitialization)
+		log.Info("Feature enabled", "flag", role.DisableLateInitialization)
+	}
+
+	if *essEnabled {
+		o.Features.Enable(publisher.EnableAlphaExternalSecretStores)
+		log.Info("Alpha feature enabled", "flag", publisher.EnableAlphaExternalSecretStores)
+
+		if *essTLSCertsDir != "" {
+			tcfg, err := certificates.LoadMTLSConfig(
+				filepath.Join(*essTLSCertsDir, "ca.crt"),
+				filepath.Join(*essTLSCertsDir, "tls.crt"),
+				filepath.Join(*essTLSCertsDir, "tls.key"),
+				false)
+			kingpin.FatalIfError(err, "Cannot load ESS TLS certificates")
+			o.ESSOptions = &xpcontroller.ESSOptions{TLSConfig: tcfg}
+		}
+
+		for _, sc := range apis.DefaultStoreConfigs(*namespace) {
+			err := mgr.GetClient().Create(context.Background(), sc)
+			kingpin.FatalIfError(resource.Ignore(kerrors.IsAlreadyExists, err), "Cannot create default StoreConfig")
+		}
 	}
 
 	kingpin.FatalIfError(controller.Setup(mgr, o), "Cannot setup SQL controllers")
-	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// Close the database connections of reconciles that did not finish in
+	// time, so that they do not count against the connection limit of the
+	// server until it notices they are gone.
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownPeriod)
+	defer cancel()
+	if derr := xsql.Drain(ctx); derr != nil {
+		log.Info("Cannot close all database connections", "open", xsql.OpenHandles(), "error", derr)
+	}
+	kingpin.FatalIfError(err, "Cannot start controller manager")
 }
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command sqlctl runs the observation of a managed resource exactly as the
+// provider does and prints what the provider sees, without changing the
+// resource or its database.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
+	_ "github.com/sijms/go-ora/v2"
+	_ "github.com/snowflakedb/gosnowflake"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller"
+)
+
+func main() {
+	var (
+		app = kingpin.New(filepath.Base(os.Args[0]), "Debug the SQL provider by observing managed resources the way it does.").DefaultEnvars()
+
+		observe     = app.Command("observe", "Observe a managed resource and print whether it exists and is up to date, and its observed status. Executed statements are logged to stderr.")
+		kind        = observe.Arg("kind", "Kind of the managed resource, optionally qualified by its group, e.g. grant.postgresql or Grant.postgresql.sql.crossplane.io.").Required().String()
+		name        = observe.Arg("name", "Name of the managed resource.").Required().String()
+		files       = observe.Flag("file", "Read the managed resource, its ProviderConfig and credentials Secret from the supplied YAML file instead of the cluster. May be repeated.").Short('f').ExistingFiles()
+		kubeContext = observe.Flag("context", "Kubeconfig context to read the managed resource, its ProviderConfig and credentials Secret from.").String()
+	)
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	s := runtime.NewScheme()
+	kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add SQL APIs to scheme")
+	kingpin.FatalIfError(corev1.AddToScheme(s), "Cannot add core APIs to scheme")
+
+	switch cmd {
+	case observe.FullCommand():
+		kube, err := newClient(s, *files, *kubeContext)
+		kingpin.FatalIfError(err, "Cannot create client")
+		kingpin.FatalIfError(runObserve(context.Background(), s, kube, *kind, *name, os.Stdout), "Cannot observe %s %s", *kind, *name)
+	}
+}
+
+// newClient returns a client of the supplied files if there are any, or of
+// the cluster of the supplied kubeconfig context otherwise. The client of
+// the cluster is only used to read.
+func newClient(s *runtime.Scheme, files []string, kubeContext string) (client.Client, error) {
+	if len(files) == 0 {
+		cfg, err := config.GetConfigWithContext(kubeContext)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get kubeconfig")
+		}
+		return client.New(cfg, client.Options{Scheme: s})
+	}
+
+	objs := []client.Object{}
+	for _, f := range files {
+		o, err := readObjects(s, f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read %s", f)
+		}
+		objs = append(objs, o...)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(), nil
+}
+
+// readObjects returns the objects of all YAML documents in the supplied
+// file.
+func readObjects(s *runtime.Scheme, file string) ([]client.Object, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	objs := []client.Object{}
+	d := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := d.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		o, err := s.New(u.GroupVersionKind())
+		if err != nil {
+			return nil, err
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, o); err != nil {
+			return nil, err
+		}
+		co, ok := o.(client.Object)
+		if !ok {
+			return nil, errors.Errorf("%s is not an object", u.GroupVersionKind())
+		}
+		objs = append(objs, co)
+	}
+}
+
+// A result is what the provider sees when it observes a managed resource.
+type result struct {
+	ResourceExists          bool     `json:"resourceExists"`
+	ResourceUpToDate        bool     `json:"resourceUpToDate"`
+	ResourceLateInitialized bool     `json:"resourceLateInitialized"`
+	ConnectionDetailKeys    []string `json:"connectionDetailKeys,omitempty"`
+	Diff                    string   `json:"diff,omitempty"`
+
+	// Resource is the managed resource after the observation, i.e. with
+	// its status, and any late initialized spec fields, as observed.
+	Resource resource.Managed `json:"resource"`
+}
+
+func runObserve(ctx context.Context, s *runtime.Scheme, kube client.Client, kind, name string, out io.Writer) error {
+	gk, err := findKind(s, kind)
+	if err != nil {
+		return err
+	}
+	newConnecter, ok := controller.Connecters()[gk.String()]
+	if !ok {
+		return errors.Errorf("%s is not observed by the provider", gk)
+	}
+
+	o, err := s.New(s.PrioritizedVersionsForGroup(gk.Group)[0].WithKind(gk.Kind))
+	if err != nil {
+		return err
+	}
+	mg, ok := o.(resource.Managed)
+	if !ok {
+		return errors.Errorf("%s is not a managed resource", gk)
+	}
+	if err := kube.Get(ctx, types.NamespacedName{Name: name}, mg); err != nil {
+		return errors.Wrap(err, "cannot get managed resource")
+	}
+
+	// Log every statement with its literals redacted, as the provider does
+	// when started with --debug --log-sql.
+	xsql.LogStatements = true
+	log := logging.NewLogrLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stderr)).WithName("sqlctl"))
+
+	// Don't record the usage of the ProviderConfig; we only read.
+	noUsage := resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil })
+
+	ext, err := newConnecter(kube, noUsage, log).Connect(ctx, mg)
+	if err != nil {
+		return errors.Wrap(err, "cannot connect")
+	}
+	obs, err := ext.Observe(ctx, mg)
+	if err != nil {
+		return errors.Wrap(err, "cannot observe")
+	}
+
+	// Only the keys of the connection details are printed; their values
+	// may be secret.
+	keys := make([]string, 0, len(obs.ConnectionDetails))
+	for k := range obs.ConnectionDetails {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b, err := json.MarshalIndent(result{
+		ResourceExists:          obs.ResourceExists,
+		ResourceUpToDate:        obs.ResourceUpToDate,
+		ResourceLateInitialized: obs.ResourceLateInitialized,
+		ConnectionDetailKeys:    keys,
+		Diff:                    obs.Diff,
+		Resource:                mg,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(b))
+	return err
+}
+
+// findKind returns the group kind of the managed resources matching the
+// supplied kind, which is matched case-insensitively and may be qualified
+// by the whole or the first segments of its group.
+func findKind(s *runtime.Scheme, kind string) (schema.GroupKind, error) {
+	k, group, _ := strings.Cut(kind, ".")
+	found := map[schema.GroupKind]bool{}
+	for gvk := range s.AllKnownTypes() {
+		if !strings.EqualFold(gvk.Kind, k) {
+			continue
+		}
+		if group != "" && !strings.EqualFold(gvk.Group, group) && !strings.HasPrefix(strings.ToLower(gvk.Group), strings.ToLower(group)+".") {
+			continue
+		}
+		found[gvk.GroupKind()] = true
+	}
+
+	switch len(found) {
+	case 0:
+		return schema.GroupKind{}, errors.Errorf("unknown kind %q", kind)
+	case 1:
+		for gk := range found {
+			return gk, nil
+		}
+	}
+	matches := make([]string, 0, len(found))
+	for gk := range found {
+		matches = append(matches, gk.String())
+	}
+	sort.Strings(matches)
+	return schema.GroupKind{}, errors.Errorf("kind %q is ambiguous, qualify it with one of the groups of %s", kind, strings.Join(matches, ", "))
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package azuread acquires Azure AD (Microsoft Entra ID) access tokens for
+// database servers that authenticate using them, so that the provider can
+// connect without a password in its connection secret.
+package azuread
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/pkg/errors"
+)
+
+const (
+	// WorkloadIdentity exchanges the federated token of an AKS workload
+	// identity, read from AZURE_FEDERATED_TOKEN_FILE, for an access token
+	// of the client AZURE_CLIENT_ID in the tenant AZURE_TENANT_ID.
+	WorkloadIdentity = "ActiveDirectoryWorkloadIdentity"
+
+	// Default acquires an access token using the first credential of the
+	// environment that is available: a client secret or certificate set by
+	// AZURE_CLIENT_SECRET or AZURE_CLIENT_CERTIFICATE_PATH, the workload
+	// identity, the managed identity of the host or the Azure CLI.
+	Default = "ActiveDirectoryDefault"
+)
+
+const (
+	// ScopeSQL is the scope of access tokens for Azure SQL.
+	ScopeSQL = "https://database.windows.net/.default"
+
+	// ScopeOSSRDBMS is the scope of access tokens for Azure Database for
+	// PostgreSQL and MySQL.
+	ScopeOSSRDBMS = "https://ossrdbms-aad.database.windows.net/.default"
+)
+
+// expiryDelta is how long before it expires a cached token is renewed, so
+// that it does not expire while a connection is being established.
+const expiryDelta = 5 * time.Minute
+
+const (
+	errUnknownMethod = "unknown Azure AD authentication method %q, must be one of %s"
+	errCredential    = "cannot create Azure AD credential"
+	errRequestToken  = "cannot request Azure AD access token"
+)
+
+// Methods are the supported authentication methods.
+var Methods = []string{Default, WorkloadIdentity}
+
+// ValidateMethod returns an error if the supplied authentication method is
+// not supported.
+func ValidateMethod(method string) error {
+	for _, m := range Methods {
+		if m == method {
+			return nil
+		}
+	}
+	return errors.Errorf(errUnknownMethod, method, strings.Join(Methods, ", "))
+}
+
+// newCredential returns the azidentity credential of the supplied
+// authentication method. Credentials read their configuration, such as
+// AZURE_AUTHORITY_HOST for sovereign clouds, from the environment.
+func newCredential(method string) (azcore.TokenCredential, error) {
+	if method == WorkloadIdentity {
+		return azidentity.NewWorkloadIdentityCredential(nil)
+	}
+	return azidentity.NewDefaultAzureCredential(nil)
+}
+
+// A TokenSource returns an access token for a scope, such as
+// https://database.windows.net/.default for Azure SQL Database.
+type TokenSource struct {
+	scope string
+	cred  azcore.TokenCredential
+	now   func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// sources are shared by every client, so that a token is reused until it
+// is about to expire rather than requested for each connection.
+var sources = struct {
+	sync.Mutex
+	m map[string]*TokenSource
+}{m: map[string]*TokenSource{}}
+
+// NewTokenSource returns the TokenSource of the supplied authentication
+// method and scope. Tokens are cached until shortly before they expire.
+func NewTokenSource(method, scope string) (*TokenSource, error) {
+	if err := ValidateMethod(method); err != nil {
+		return nil, err
+	}
+	sources.Lock()
+	defer sources.Unlock()
+	key := method + " " + scope
+	if ts, ok := sources.m[key]; ok {
+		return ts, nil
+	}
+	cred, err := newCredential(method)
+	if err != nil {
+		return nil, errors.Wrap(err, errCredential)
+	}
+	ts := &TokenSource{scope: scope, cred: cred, now: time.Now}
+	sources.m[key] = ts
+	return ts, nil
+}
+
+// Token returns an access token, requesting a new one if the cached token is
+// about to expire.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token != "" && ts.now().Add(expiryDelta).Before(ts.expires) {
+		return ts.token, nil
+	}
+	t, err := ts.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{ts.scope}})
+	if err != nil {
+		return "", errors.Wrap(err, errRequestToken)
+	}
+	ts.token, ts.expires = t.Token, t.ExpiresOn
+	return t.Token, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azuread
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type credentialFn func(ctx context.Context, o policy.TokenRequestOptions) (azcore.AccessToken, error)
+
+func (fn credentialFn) GetToken(ctx context.Context, o policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return fn(ctx, o)
+}
+
+func TestToken(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		token    string
+		err      error
+		requests int
+	}
+
+	cases := map[string]struct {
+		reason  string
+		cred    credentialFn
+		cached  string
+		expires time.Time
+		want    want
+	}{
+		"Requested": {
+			reason: "A token for the scope should be requested if none is cached",
+			cred: func(_ context.Context, o policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				if diff := cmp.Diff([]string{"scope/.default"}, o.Scopes); diff != "" {
+					return azcore.AccessToken{}, errors.New("unexpected scopes")
+				}
+				return azcore.AccessToken{Token: "token", ExpiresOn: now.Add(time.Hour)}, nil
+			},
+			want: want{token: "token", requests: 1},
+		},
+		"Cached": {
+			reason:  "A cached token that does not expire soon should be reused",
+			cached:  "cached",
+			expires: now.Add(time.Hour),
+			want:    want{token: "cached"},
+		},
+		"AboutToExpire": {
+			reason:  "A cached token that expires soon should be renewed",
+			cached:  "cached",
+			expires: now.Add(time.Minute),
+			cred: func(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				return azcore.AccessToken{Token: "renewed", ExpiresOn: now.Add(time.Hour)}, nil
+			},
+			want: want{token: "renewed", requests: 1},
+		},
+		"Failed": {
+			reason: "A failed token request should return an error",
+			cred: func(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				return azcore.AccessToken{}, errBoom
+			},
+			want: want{err: errors.Wrap(errBoom, errRequestToken), requests: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			ts := &TokenSource{
+				scope: "scope/.default",
+				cred: credentialFn(func(ctx context.Context, o policy.TokenRequestOptions) (azcore.AccessToken, error) {
+					requests++
+					return tc.cred(ctx, o)
+				}),
+				now:     func() time.Time { return now },
+				token:   tc.cached,
+				expires: tc.expires,
+			}
+
+			got, err := ts.Token(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nToken(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.token, got); diff != "" {
+				t.Errorf("\n%s\nToken(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requests, requests); diff != "" {
+				t.Errorf("\n%s\nToken(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewTokenSource(t *testing.T) {
+	for _, k := range []string{"AZURE_CLIENT_ID", "AZURE_TENANT_ID", "AZURE_FEDERATED_TOKEN_FILE"} {
+		// Setenv restores the variable once the test is done.
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+
+	if _, err := NewTokenSource(WorkloadIdentity, "scope/.default"); err == nil {
+		t.Errorf("NewTokenSource(%q, ...): want an error without a workload identity, got nil", WorkloadIdentity)
+	}
+	if _, err := NewTokenSource("ActiveDirectoryPassword", "scope/.default"); err == nil {
+		t.Errorf("NewTokenSource(%q, ...): want an error for an unknown method, got nil", "ActiveDirectoryPassword")
+	}
+}
+
+func TestValidateMethod(t *testing.T) {
+	if err := ValidateMethod(WorkloadIdentity); err != nil {
+		t.Errorf("ValidateMethod(%q): %v", WorkloadIdentity, err)
+	}
+	if err := ValidateMethod("ActiveDirectoryPassword"); err == nil {
+		t.Errorf("ValidateMethod(%q): want error, got nil", "ActiveDirectoryPassword")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clickhouse implements the xsql.DB client for ClickHouse.
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errNotSupported = "%s not supported by clickhouse client"
+)
+
+type clickHouseDB struct {
+	dsn      string
+	endpoint string
+	port     string
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"compress",
+	"connection_open_strategy",
+	"dial_timeout",
+	"max_execution_time",
+	"read_timeout",
+	"skip_verify",
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the ClickHouse client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
+}
+
+// New returns a new ClickHouse database client.
+func New(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB {
+	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
+	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
+	username := string(creds[xpv1.ResourceCredentialsSecretUserKey])
+	password := string(creds[xpv1.ResourceCredentialsSecretPasswordKey])
+
+	return clickHouseDB{
+		dsn:      DSN(username, password, endpoint, port, secure, opts),
+		endpoint: endpoint,
+		port:     port,
+	}
+}
+
+// DSN returns the DSN URL
+func DSN(username, password, endpoint, port string, secure *bool, opts map[string]string) string {
+	query := url.Values{}
+	for k, v := range opts {
+		query.Set(k, v)
+	}
+	if secure != nil {
+		query.Set("secure", strconv.FormatBool(*secure))
+	}
+	u := url.URL{
+		Scheme:   "clickhouse",
+		User:     url.UserPassword(username, password),
+		Host:     endpoint + ":" + port,
+		Path:     "/",
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// ExecTx is unsupported in ClickHouse.
+func (c clickHouseDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return errors.Errorf(errNotSupported, "transactions")
+}
+
+// Exec the supplied query.
+func (c clickHouseDB) Exec(ctx context.Context, q xsql.Query) error {
+	d, err := xsql.Open(ctx, "clickhouse", c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
+	return err
+}
+
+// Query the supplied query.
+func (c clickHouseDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	d, err := xsql.Open(ctx, "clickhouse", c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	rows, err := d.QueryContext(ctx, q.String, q.Parameters...)
+	return rows, err
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (c clickHouseDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	db, err := xsql.Open(ctx, "clickhouse", c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(db) //nolint:errcheck
+
+	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
+}
+
+// Dialect returns the SQL dialect of ClickHouse, whose string literals
+// use backslash escapes.
+func (c clickHouseDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{BackslashEscapes: true}
+}
+
+// GetConnectionDetails returns the connection details for a user of this DB
+func (c clickHouseDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte(c.endpoint),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte(c.port),
+	}
+}
+
+// ReservedUsers are the users that are created by ClickHouse itself and must
+// not be managed.
+var ReservedUsers = []string{
+	"default",
+}
+
+// IsReservedUser returns true if the supplied user name is one of the
+// ReservedUsers, or the user the provider connects as.
+func IsReservedUser(username, connectionUser string) bool {
+	return (connectionUser != "" && username == connectionUser) || slices.Contains(ReservedUsers, username)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "'", `\'`)
+
+// QuoteIdentifier for ClickHouse queries
+func QuoteIdentifier(id string) string {
+	return "`" + escaper.Replace(id) + "`"
+}
+
+// QuoteValue for ClickHouse queries
+func QuoteValue(v string) string {
+	return "'" + escaper.Replace(v) + "'"
+}
+
+// ExecQuery declares the query to execute and its error value if it fails
+type ExecQuery struct {
+	// Query defines the sql statement to execute
+	Query string
+	// ErrorValue defines what error will be returned if the provided sql statement failed when executing
+	ErrorValue string
+}
+
+// ExecWrapper executes the supplied query, wrapping any error in its
+// ErrorValue.
+func ExecWrapper(ctx context.Context, db xsql.DB, query ExecQuery) error {
+	if err := db.Exec(ctx, xsql.Query{String: query.Query}); err != nil {
+		return errors.Wrap(err, query.ErrorValue)
+	}
+	return nil
+}
+
+// OnCluster returns an ON CLUSTER clause for the supplied cluster, if any.
+func OnCluster(cluster *string) string {
+	if cluster == nil {
+		return ""
+	}
+	return fmt.Sprintf(" ON CLUSTER %s", QuoteIdentifier(*cluster))
+}
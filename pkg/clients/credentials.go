@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+const (
+	errNoEnv    = "ProviderConfig does not reference a credentials environment variable"
+	errEnvUnset = "credentials environment variable %s is not set"
+	errNoFs     = "ProviderConfig does not reference a credentials path"
+	errReadFs   = "cannot read credentials from filesystem"
+	errParse    = "cannot parse credentials as a JSON object"
+)
+
+// ExtractCredentials returns the credentials of a ProviderConfig whose source
+// is Environment or Filesystem, keyed like those of a connection secret. It
+// returns nil credentials for any other source, which are read from the
+// connection secret the ProviderConfig references.
+//
+// An environment variable or file holds a JSON object of string values, e.g.
+// {"endpoint": "db.example.org", "port": "5432", "username": "admin",
+// "password": "..."}. A directory, such as one mounted by the Secrets Store
+// CSI driver or written by Vault Agent, holds a file per key instead.
+func ExtractCredentials(src xpv1.CredentialsSource, env *xpv1.EnvSelector, fs *xpv1.FsSelector) (map[string][]byte, error) {
+	switch src {
+	case xpv1.CredentialsSourceEnvironment:
+		if env == nil || env.Name == "" {
+			return nil, errors.New(errNoEnv)
+		}
+		v, ok := os.LookupEnv(env.Name)
+		if !ok {
+			return nil, errors.Errorf(errEnvUnset, env.Name)
+		}
+		return parseCredentials([]byte(v))
+	case xpv1.CredentialsSourceFilesystem:
+		if fs == nil || fs.Path == "" {
+			return nil, errors.New(errNoFs)
+		}
+		creds, err := readCredentials(fs.Path)
+		return creds, errors.Wrap(err, errReadFs)
+	default:
+		return nil, nil
+	}
+}
+
+// readCredentials reads the credentials held by the supplied file, or by
+// the regular files in the supplied directory. Hidden files, such as the
+// ..data symlink of a projected volume, are ignored.
+func readCredentials(path string) (map[string][]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		return parseCredentials(b)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	creds := make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(path, e.Name())
+		// Stat follows the symlinks projected volumes are made of.
+		if fi, err := os.Stat(p); err != nil || fi.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return nil, err
+		}
+		creds[e.Name()] = b
+	}
+	return creds, nil
+}
+
+func parseCredentials(b []byte) (map[string][]byte, error) {
+	m := map[string]string{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, errors.Wrap(err, errParse)
+	}
+	creds := make(map[string][]byte, len(m))
+	for k, v := range m {
+		creds[k] = []byte(v)
+	}
+	return creds, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestExtractCredentials(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "credentials.json")
+	if err := os.WriteFile(file, []byte(`{"username": "admin", "password": "secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mounted := filepath.Join(dir, "mounted")
+	if err := os.MkdirAll(filepath.Join(mounted, "..data"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{"username": "admin", "password": "secret", ".hidden": "ignored"} {
+		if err := os.WriteFile(filepath.Join(mounted, k), []byte(v), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("SQL_CREDENTIALS", `{"username": "admin", "password": "secret"}`)
+	t.Setenv("SQL_CREDENTIALS_INVALID", "admin:secret")
+
+	want := map[string][]byte{
+		"username": []byte("admin"),
+		"password": []byte("secret"),
+	}
+
+	cases := map[string]struct {
+		reason string
+		src    xpv1.CredentialsSource
+		env    *xpv1.EnvSelector
+		fs     *xpv1.FsSelector
+		want   map[string][]byte
+		err    error
+	}{
+		"ConnectionSecret": {
+			reason: "No credentials should be extracted for a source read from a connection secret",
+			src:    "PostgreSQLConnectionSecret",
+		},
+		"Environment": {
+			reason: "Credentials should be parsed from a JSON object in the environment",
+			src:    xpv1.CredentialsSourceEnvironment,
+			env:    &xpv1.EnvSelector{Name: "SQL_CREDENTIALS"},
+			want:   want,
+		},
+		"ErrNoEnv": {
+			reason: "An error should be returned if no environment variable is referenced",
+			src:    xpv1.CredentialsSourceEnvironment,
+			err:    errors.New(errNoEnv),
+		},
+		"ErrEnvUnset": {
+			reason: "An error should be returned if the environment variable is not set",
+			src:    xpv1.CredentialsSourceEnvironment,
+			env:    &xpv1.EnvSelector{Name: "SQL_CREDENTIALS_UNSET"},
+			err:    errors.Errorf(errEnvUnset, "SQL_CREDENTIALS_UNSET"),
+		},
+		"ErrParse": {
+			reason: "An error should be returned if the environment variable is not a JSON object",
+			src:    xpv1.CredentialsSourceEnvironment,
+			env:    &xpv1.EnvSelector{Name: "SQL_CREDENTIALS_INVALID"},
+			err:    errors.Wrap(errors.New("invalid character 'a' looking for beginning of value"), errParse),
+		},
+		"File": {
+			reason: "Credentials should be parsed from a JSON object in a file",
+			src:    xpv1.CredentialsSourceFilesystem,
+			fs:     &xpv1.FsSelector{Path: file},
+			want:   want,
+		},
+		"Directory": {
+			reason: "Each visible file in a directory should be read as a credentials key",
+			src:    xpv1.CredentialsSourceFilesystem,
+			fs:     &xpv1.FsSelector{Path: mounted},
+			want:   want,
+		},
+		"ErrNoFs": {
+			reason: "An error should be returned if no path is referenced",
+			src:    xpv1.CredentialsSourceFilesystem,
+			fs:     &xpv1.FsSelector{},
+			err:    errors.New(errNoFs),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ExtractCredentials(tc.src, tc.env, tc.fs)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nExtractCredentials(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nExtractCredentials(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
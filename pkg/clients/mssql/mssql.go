@@ -19,55 +19,224 @@ package mssql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"math"
 	"net/url"
-	"strings"
+	"strconv"
+	"time"
 
+	mssqldb "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/azuread"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mssql"
 )
 
 const (
 	driverName = "sqlserver"
 
 	errNotSupported = "%s not supported by MSSQL client"
+
+	errNumDatabaseNotExist   = 911
+	errNumCannotOpenDatabase = 4060
 )
 
+// IdentifierLimit is the limit of SQL Server identifiers, which are of
+// type sysname.
+var IdentifierLimit = xsql.IdentifierLimit{Engine: "SQL Server", Length: 128}
+
+// FedAuthOption is the connection option that selects the Azure AD
+// authentication method, one of azuread.Methods. The provider then connects
+// using an access token rather than the username and password of its
+// connection secret.
+const FedAuthOption = "fedauth"
+
 type mssqlDB struct {
 	dsn      string
 	endpoint string
 	port     string
+	tokens   *azuread.TokenSource
+	err      error
+}
+
+// Options configures how a mssql database client connects to the server.
+type Options struct {
+	// ApplicationIntent declares the application workload type when
+	// connecting to an availability group listener, either ReadWrite or
+	// ReadOnly.
+	ApplicationIntent string
+
+	// MultiSubnetFailover enables faster detection of and connection to the
+	// active server when connecting to a multi-subnet availability group
+	// listener.
+	MultiSubnetFailover bool
+
+	// FailoverPartner is the host of the database mirroring failover
+	// partner.
+	FailoverPartner string
+
+	// Encrypt is the encrypt driver parameter, i.e. disable, false, true or
+	// strict.
+	Encrypt string
+
+	// DialTimeout bounds how long to wait to open a network connection.
+	DialTimeout time.Duration
+
+	// ConnectionTimeout bounds how long to wait to connect and log in.
+	ConnectionTimeout time.Duration
+
+	// ConnectionOptions are additional driver parameters. Keys must be
+	// one of ConnectionOptions. Encrypt, DialTimeout and ConnectionTimeout
+	// take precedence over the options they correspond to.
+	ConnectionOptions map[string]string
+}
+
+// OptionsFromProviderConfig returns the Options configured by the supplied
+// ProviderConfig.
+func OptionsFromProviderConfig(pc *v1alpha1.ProviderConfig) Options {
+	return Options{
+		ApplicationIntent:   ptr.Deref(pc.Spec.ApplicationIntent, ""),
+		MultiSubnetFailover: ptr.Deref(pc.Spec.MultiSubnetFailover, false),
+		FailoverPartner:     ptr.Deref(pc.Spec.FailoverPartner, ""),
+		Encrypt:             ptr.Deref(pc.Spec.Encrypt, ""),
+		DialTimeout:         ptr.Deref(pc.Spec.DialTimeout, metav1.Duration{}).Duration,
+		ConnectionTimeout:   ptr.Deref(pc.Spec.ConnectionTimeout, metav1.Duration{}).Duration,
+		ConnectionOptions:   pc.Spec.ConnectionOptions,
+	}
 }
 
-// New returns a new mssql database client.
-func New(creds map[string][]byte, database string) xsql.DB {
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"app name",
+	"certificate",
+	"connection timeout",
+	"dial timeout",
+	"encrypt",
+	FedAuthOption,
+	"hostnameincertificate",
+	"keepalive",
+	"packet size",
+	"serverspn",
+	"trustservercertificate",
+	"workstation id",
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the mssql client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	if err := xsql.ValidateOptions(opts, ConnectionOptions); err != nil {
+		return err
+	}
+	if m, ok := opts[FedAuthOption]; ok {
+		return azuread.ValidateMethod(m)
+	}
+	return nil
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
+}
+
+// New returns a new mssql database client. If the FedAuthOption connection
+// option is set, the client authenticates using Azure AD access tokens and
+// the username and password of the credentials are ignored.
+func New(creds map[string][]byte, database string, opts Options) xsql.DB {
 	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
 	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
+	username := string(creds[xpv1.ResourceCredentialsSecretUserKey])
+	password := string(creds[xpv1.ResourceCredentialsSecretPasswordKey])
 
+	c := mssqlDB{
+		endpoint: endpoint,
+		port:     port,
+	}
+	if m := opts.ConnectionOptions[FedAuthOption]; m != "" {
+		username, password = "", ""
+		c.tokens, c.err = azuread.NewTokenSource(m, azuread.ScopeSQL)
+	}
+	c.dsn = DSN(username, password, endpoint, port, database, opts)
+	return c
+}
+
+// DSN returns the DSN URL
+func DSN(username, password, endpoint, port, database string, opts Options) string {
 	host := endpoint
 	if port != "" {
 		host = fmt.Sprintf("%s:%s", endpoint, port)
 	}
 
 	query := url.Values{}
+	for k, v := range opts.ConnectionOptions {
+		if k == FedAuthOption {
+			continue
+		}
+		query.Set(k, v)
+	}
 	if database != "" {
 		query.Add("database", database)
 	}
+	if opts.ApplicationIntent != "" {
+		query.Add("applicationintent", opts.ApplicationIntent)
+	}
+	if opts.MultiSubnetFailover {
+		query.Add("multisubnetfailover", "true")
+	}
+	if opts.FailoverPartner != "" {
+		query.Add("failoverpartner", opts.FailoverPartner)
+	}
+	if opts.Encrypt != "" {
+		query.Set("encrypt", opts.Encrypt)
+	}
+	if opts.DialTimeout > 0 {
+		query.Set("dial timeout", seconds(opts.DialTimeout))
+	}
+	if opts.ConnectionTimeout > 0 {
+		query.Set("connection timeout", seconds(opts.ConnectionTimeout))
+	}
 	u := &url.URL{
 		Scheme:   driverName,
-		User:     url.UserPassword(string(creds[xpv1.ResourceCredentialsSecretUserKey]), string(creds[xpv1.ResourceCredentialsSecretPasswordKey])),
 		Host:     host,
 		RawQuery: query.Encode(),
 	}
-	return mssqlDB{
-		dsn:      u.String(),
-		endpoint: endpoint,
-		port:     port,
+	if username != "" || password != "" {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String()
+}
+
+// seconds returns the supplied timeout in whole seconds, rounded up so that
+// it does not become 0, which the driver treats as no timeout.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// open opens a database handle, which authenticates using an access token
+// if the client uses Azure AD authentication.
+func (c mssqlDB) open(ctx context.Context) (*sql.DB, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.tokens == nil {
+		return xsql.Open(ctx, driverName, c.dsn)
 	}
+	conn, err := mssqldb.NewAccessTokenConnector(c.dsn, func() (string, error) {
+		return c.tokens.Token(context.Background())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return xsql.OpenDB(ctx, conn)
 }
 
 // ExecTx is unsupported in mssql.
@@ -77,11 +246,11 @@ func (c mssqlDB) ExecTx(_ context.Context, _ []xsql.Query) error {
 
 // Exec the supplied query.
 func (c mssqlDB) Exec(ctx context.Context, q xsql.Query) error {
-	d, err := sql.Open(driverName, c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
 	return err
@@ -89,26 +258,76 @@ func (c mssqlDB) Exec(ctx context.Context, q xsql.Query) error {
 
 // Query the supplied query.
 func (c mssqlDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-	d, err := sql.Open(driverName, c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	return d.QueryContext(ctx, q.String, q.Parameters...)
 }
 
 // Scan the results of the supplied query into the supplied destination.
 func (c mssqlDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-	db, err := sql.Open(driverName, c.dsn)
+	db, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer db.Close() //nolint:errcheck
+	defer xsql.Close(db) //nolint:errcheck
 
 	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
 }
 
+// Validate checks the syntax of the supplied statements without executing
+// them, by parsing each as its own batch with PARSEONLY turned on for the
+// session. Statements with parameters are not validated.
+func (c mssqlDB) Validate(ctx context.Context, ql []xsql.Query) error {
+	d, err := c.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	// PARSEONLY is a session setting, so every batch must use the same
+	// connection.
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if err = parseOnly(ctx, conn, ql); err != nil {
+		// The connection may still only parse statements, so it must not be
+		// reused by the statements that share its handle.
+		_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	return err
+}
+
+// parseOnly parses the supplied statements on the supplied connection with
+// PARSEONLY turned on.
+func parseOnly(ctx context.Context, conn *sql.Conn, ql []xsql.Query) error {
+	if _, err := conn.ExecContext(ctx, "SET PARSEONLY ON"); err != nil {
+		return err
+	}
+	for _, q := range ql {
+		if len(q.Parameters) > 0 {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, q.String); err != nil {
+			return err
+		}
+	}
+	_, err := conn.ExecContext(ctx, "SET PARSEONLY OFF")
+	return err
+}
+
+// Dialect returns the SQL dialect of SQL Server, which quotes identifiers
+// in brackets and does not escape with backslashes.
+func (c mssqlDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{BracketIdentifiers: true}
+}
+
 // GetConnectionDetails returns the connection details for a user of this DB
 func (c mssqlDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
 	return managed.ConnectionDetails{
@@ -121,10 +340,20 @@ func (c mssqlDB) GetConnectionDetails(username, password string) managed.Connect
 
 // QuoteIdentifier for mssql queries
 func QuoteIdentifier(id string) string {
-	return "[" + id + "]"
+	return sqlgen.QuoteIdentifier(id)
 }
 
 // QuoteValue for mssql queries
 func QuoteValue(id string) string {
-	return "'" + strings.ReplaceAll(id, "'", "''") + "'"
+	return sqlgen.QuoteValue(id)
+}
+
+// IsUnknownDatabase returns true if passed a mssql error indicating that the
+// database a statement or connection refers to does not exist.
+func IsUnknownDatabase(err error) bool {
+	var msErr mssqldb.Error
+	if errors.As(err, &msErr) {
+		return msErr.Number == errNumDatabaseNotExist || msErr.Number == errNumCannotOpenDatabase
+	}
+	return false
 }
@@ -4,29 +4,124 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/azuread"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mysql"
 )
 
 const (
 	errNotSupported = "%s not supported by mysql client"
+
+	// sqlMode is the sql_mode of the sessions of the provider: that of the
+	// server without NO_BACKSLASH_ESCAPES. Backslashes are thus escape
+	// characters in string literals, as QuoteValue expects, even on servers
+	// that set it.
+	sqlMode = "TRIM(BOTH ',' FROM REPLACE(CONCAT(',', @@SESSION.sql_mode, ','), ',NO_BACKSLASH_ESCAPES,', ','))"
+)
+
+var (
+	// DatabaseNameLimit is the limit of MySQL database names.
+	DatabaseNameLimit = xsql.IdentifierLimit{Engine: "MySQL database", Length: 64}
+
+	// UserNameLimit is the limit of the user name part of MySQL accounts.
+	UserNameLimit = xsql.IdentifierLimit{Engine: "MySQL user", Length: 32}
+
+	// PluginNameLimit is the limit of MySQL plugin names.
+	PluginNameLimit = xsql.IdentifierLimit{Engine: "MySQL plugin", Length: 64}
 )
 
+// azureADOption is the connection option that OptionsFromProviderConfig
+// sets to the Azure AD authentication method of a ProviderConfig whose
+// credentials source is AzureAD. It is not a driver parameter, and is not one
+// of ConnectionOptions, so it cannot be set by users.
+const azureADOption = "azureADMethod"
+
 type mySQLDB struct {
 	dsn      string
 	endpoint string
 	port     string
+	protocol string
 	tls      string
+
+	// tokens are the Azure AD access tokens used as the password, if any.
+	// The DSN is then built for each connection using dsnFor.
+	tokens *azuread.TokenSource
+	err    error
+	dsnFor func(password string) string
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"allowCleartextPasswords",
+	"allowNativePasswords",
+	"charset",
+	"collation",
+	"interpolateParams",
+	"loc",
+	"maxAllowedPacket",
+	"parseTime",
+	"readTimeout",
+	"timeout",
+	"writeTimeout",
+}
+
+// escapedOptions are the connection options whose values the driver
+// unescapes when it parses the DSN. All others are read as is.
+var escapedOptions = []string{
+	"charset",
+	"loc",
+}
+
+// OptionsFromProviderConfig returns the connection options of the supplied
+// ProviderConfig. They include the Azure AD authentication method if its
+// credentials source is AzureAD.
+func OptionsFromProviderConfig(pc *v1alpha1.ProviderConfig) map[string]string {
+	if pc.Spec.Credentials.Source != v1alpha1.CredentialsSourceAzureAD {
+		return pc.Spec.ConnectionOptions
+	}
+	opts := make(map[string]string, len(pc.Spec.ConnectionOptions)+1)
+	for k, v := range pc.Spec.ConnectionOptions {
+		opts[k] = v
+	}
+	opts[azureADOption] = ptr.Deref(pc.Spec.Credentials.AzureADMethod, azuread.Default)
+	return opts
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the MySQL client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
 }
 
-// New returns a new MySQL database client.
-func New(creds map[string][]byte, tls *string, binlog *bool) xsql.DB {
+// New returns a new MySQL database client. If the connection secret sets
+// protocol to unix, or its endpoint is an absolute path, the endpoint is
+// used as the path of a Unix domain socket. If the options returned by
+// OptionsFromProviderConfig select Azure AD authentication, an access token
+// is used as the password instead of that of the credentials. It is sent in
+// clear text, as Azure requires, so TLS should be enabled.
+func New(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB {
 	// TODO(negz): Support alternative connection secret formats?
 	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
 	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
@@ -36,36 +131,76 @@ func New(creds map[string][]byte, tls *string, binlog *bool) xsql.DB {
 		defaultTLS := "preferred"
 		tls = &defaultTLS
 	}
-	dsn := DSN(username, password, endpoint, port, *tls, binlog)
-
-	return mySQLDB{
-		dsn:      dsn,
+	protocol := xsql.ProtocolTCP
+	if _, ok := xsql.UnixSocket(creds); ok {
+		protocol = xsql.ProtocolUnix
+	}
+	c := mySQLDB{
 		endpoint: endpoint,
 		port:     port,
+		protocol: protocol,
 		tls:      *tls,
 	}
+	if m, ok := opts[azureADOption]; ok {
+		c.tokens, c.err = azuread.NewTokenSource(m, azuread.ScopeOSSRDBMS)
+		driverOpts := map[string]string{"allowCleartextPasswords": "true"}
+		for k, v := range opts {
+			if k != azureADOption {
+				driverOpts[k] = v
+			}
+		}
+		opts = driverOpts
+	}
+	c.dsnFor = func(password string) string {
+		return DSN(username, password, protocol, endpoint, port, *tls, binlog, opts)
+	}
+	c.dsn = c.dsnFor(password)
+	return c
 }
 
-// DSN returns the DSN URL
-func DSN(username, password, endpoint, port, tls string, binlog *bool) string {
+// DSN returns the DSN URL. If protocol is unix the endpoint is the path of
+// a Unix domain socket and the port is ignored.
+func DSN(username, password, protocol, endpoint, port, tls string, binlog *bool, opts map[string]string) string {
 	// Use net/url UserPassword to encode the username and password
 	// This will ensure that any special characters in the username or password
 	// are percent-encoded for use in the user info portion of the DSN URL
+	address := fmt.Sprintf("tcp(%s:%s)", endpoint, port)
+	if protocol == xsql.ProtocolUnix {
+		address = fmt.Sprintf("unix(%s)", endpoint)
+	}
+	dsn := fmt.Sprintf("%s:%s@%s/?tls=%s&sql_mode=%s", username, password, address, tls, url.QueryEscape(sqlMode))
 	if binlog != nil {
-		return fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s&sql_log_bin=%s",
-			username,
-			password,
-			endpoint,
-			port,
-			tls,
-			strconv.FormatBool(*binlog))
-	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s",
-		username,
-		password,
-		endpoint,
-		port,
-		tls)
+		dsn += "&sql_log_bin=" + strconv.FormatBool(*binlog)
+	}
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := opts[k]
+		if slices.Contains(escapedOptions, k) {
+			v = url.QueryEscape(v)
+		}
+		dsn += "&" + k + "=" + v
+	}
+	return dsn
+}
+
+// open opens a database handle. If the client authenticates using Azure AD,
+// the current access token is used as the password.
+func (c mySQLDB) open(ctx context.Context) (*sql.DB, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.tokens == nil {
+		return xsql.Open(ctx, "mysql", c.dsn)
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return xsql.Open(ctx, "mysql", c.dsnFor(token))
 }
 
 // ExecTx is unsupported in MySQL.
@@ -75,11 +210,11 @@ func (c mySQLDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
 
 // Exec the supplied query.
 func (c mySQLDB) Exec(ctx context.Context, q xsql.Query) error {
-	d, err := sql.Open("mysql", c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
 	return err
@@ -87,11 +222,11 @@ func (c mySQLDB) Exec(ctx context.Context, q xsql.Query) error {
 
 // Query the supplied query.
 func (c mySQLDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-	d, err := sql.Open("mysql", c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	rows, err := d.QueryContext(ctx, q.String, q.Parameters...)
 	return rows, err
@@ -99,33 +234,69 @@ func (c mySQLDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
 
 // Scan the results of the supplied query into the supplied destination.
 func (c mySQLDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-	db, err := sql.Open("mysql", c.dsn)
+	db, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer db.Close() //nolint:errcheck
+	defer xsql.Close(db) //nolint:errcheck
 
 	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
 }
 
-// GetConnectionDetails returns the connection details for a user of this DB
+// Dialect returns the SQL dialect of MySQL, whose string literals use
+// backslash escapes and may be double quoted.
+func (c mySQLDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{BackslashEscapes: true, DoubleQuotedStrings: true}
+}
+
+// GetConnectionDetails returns the connection details for a user of this DB.
+// They include the tls mode if the provider requires TLS, so that consumers
+// require it too. A custom TLS configuration is published as true, since
+// its name is only meaningful to the provider. The protocol is included
+// only if the endpoint is a Unix domain socket.
 func (c mySQLDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
-	return managed.ConnectionDetails{
+	cd := managed.ConnectionDetails{
 		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
 		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
 		xpv1.ResourceCredentialsSecretEndpointKey: []byte(c.endpoint),
 		xpv1.ResourceCredentialsSecretPortKey:     []byte(c.port),
 	}
+	if c.protocol != xsql.ProtocolTCP {
+		cd[xsql.ProtocolKey] = []byte(c.protocol)
+	}
+	switch {
+	case c.tls == "true" || c.tls == "skip-verify":
+		cd[xsql.TLSKey] = []byte(c.tls)
+	case strings.HasPrefix(c.tls, "custom"):
+		cd[xsql.TLSKey] = []byte("true")
+	}
+	return cd
+}
+
+// ReservedUsers are the accounts, regardless of host, that are created by
+// MySQL itself or by managed database services and must not be managed.
+var ReservedUsers = []string{
+	"mysql.infoschema",
+	"mysql.session",
+	"mysql.sys",
+	"rdsadmin",
+	"root",
+}
+
+// IsReservedUser returns true if the supplied user name is one of the
+// ReservedUsers, or the user the provider connects as.
+func IsReservedUser(username, connectionUser string) bool {
+	return (connectionUser != "" && username == connectionUser) || slices.Contains(ReservedUsers, username)
 }
 
 // QuoteIdentifier for MySQL queries
 func QuoteIdentifier(id string) string {
-	return "`" + strings.ReplaceAll(id, "`", "``") + "`"
+	return sqlgen.QuoteIdentifier(id)
 }
 
 // QuoteValue for MySQL queries
 func QuoteValue(id string) string {
-	return "'" + strings.ReplaceAll(id, "'", "''") + "'"
+	return sqlgen.QuoteValue(id)
 }
 
 // SplitUserHost splits a MySQL user by name and host
@@ -158,3 +329,53 @@ func ExecWrapper(ctx context.Context, db xsql.DB, query ExecQuery) error {
 
 	return nil
 }
+
+// ServerVersion returns the version of the server as reported by VERSION(),
+// e.g. 8.0.36 or 10.11.6-MariaDB.
+func ServerVersion(ctx context.Context, db xsql.DB) (string, error) {
+	var v string
+	err := db.Scan(ctx, xsql.Query{String: "SELECT VERSION()"}, &v)
+	return v, err
+}
+
+// versionTTL is how long CachedServerVersion caches the version of a server,
+// so that an upgrade is noticed without restarting the provider.
+const versionTTL = 10 * time.Minute
+
+// versions are the server versions cached by CachedServerVersion, keyed by
+// ProviderConfig.
+var versions = struct {
+	sync.Mutex
+	cached map[string]cachedVersion
+}{cached: map[string]cachedVersion{}}
+
+type cachedVersion struct {
+	version string
+	expires time.Time
+}
+
+// CachedServerVersion returns the version of the server of the supplied
+// ProviderConfig. It is selected using the supplied client unless it was
+// selected less than versionTTL ago. An empty ProviderConfig name disables
+// caching.
+func CachedServerVersion(ctx context.Context, providerConfig string, db xsql.DB) (string, error) {
+	if providerConfig == "" {
+		return ServerVersion(ctx, db)
+	}
+
+	versions.Lock()
+	cv, ok := versions.cached[providerConfig]
+	versions.Unlock()
+	if ok && time.Now().Before(cv.expires) {
+		return cv.version, nil
+	}
+
+	v, err := ServerVersion(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	versions.Lock()
+	versions.cached[providerConfig] = cachedVersion{version: v, expires: time.Now().Add(versionTTL)}
+	versions.Unlock()
+	return v, nil
+}
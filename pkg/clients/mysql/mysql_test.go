@@ -2,8 +2,15 @@ package mysql
 
 import (
 	"fmt"
+	"net/url"
 	"strconv"
 	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
 )
 
 func TestDSNURLEscaping(t *testing.T) {
@@ -13,13 +20,14 @@ func TestDSNURLEscaping(t *testing.T) {
 	rawPass := "password^"
 	tls := "true"
 	binlog := false
-	dsn := DSN(user, rawPass, endpoint, port, tls, &binlog)
-	if dsn != fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s&sql_log_bin=%s",
+	dsn := DSN(user, rawPass, "tcp", endpoint, port, tls, &binlog, nil)
+	if dsn != fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s&sql_mode=%s&sql_log_bin=%s",
 		user,
 		rawPass,
 		endpoint,
 		port,
 		tls,
+		url.QueryEscape(sqlMode),
 		strconv.FormatBool(binlog)) {
 		t.Errorf("DSN string did not match expected output with URL encoded and binlog")
 	}
@@ -31,13 +39,143 @@ func TestDSNURLEscapingWithoutBinLog(t *testing.T) {
 	user := "username"
 	rawPass := "password^"
 	tls := "true"
-	dsn := DSN(user, rawPass, endpoint, port, tls, nil)
-	if dsn != fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s",
+	dsn := DSN(user, rawPass, "tcp", endpoint, port, tls, nil, nil)
+	if dsn != fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=%s&sql_mode=%s",
 		user,
 		rawPass,
 		endpoint,
 		port,
-		tls) {
+		tls,
+		url.QueryEscape(sqlMode)) {
 		t.Errorf("DSN string did not match expected output with URL encoded")
 	}
 }
+
+func TestDSNWithConnectionOptions(t *testing.T) {
+	dsn := DSN("username", "password", "tcp", "endpoint", "3306", "true", nil, map[string]string{
+		"readTimeout": "30s",
+		"loc":         "Europe/Berlin",
+	})
+	if dsn != "username:password@tcp(endpoint:3306)/?tls=true&sql_mode="+url.QueryEscape(sqlMode)+"&loc=Europe%2FBerlin&readTimeout=30s" {
+		t.Errorf("DSN string did not match expected output with connection options: %s", dsn)
+	}
+}
+
+func TestDSNWithSocket(t *testing.T) {
+	dsn := DSN("username", "password", "unix", "/var/run/mysqld/mysqld.sock", "3306", "false", nil, nil)
+	if dsn != "username:password@unix(/var/run/mysqld/mysqld.sock)/?tls=false&sql_mode="+url.QueryEscape(sqlMode) {
+		t.Errorf("DSN string did not match expected output with socket: %s", dsn)
+	}
+}
+
+func TestDSNRoundTrip(t *testing.T) {
+	dsn := DSN("username", "password", "tcp", "endpoint", "3306", "false", nil, map[string]string{
+		"charset":          "utf8mb4,utf8",
+		"collation":        "utf8mb4_unicode_ci",
+		"loc":              "America/New_York",
+		"maxAllowedPacket": "16777216",
+		"parseTime":        "true",
+		"readTimeout":      "1m30s",
+	})
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("ParseDSN(%q): %v", dsn, err)
+	}
+	if got := cfg.Params["charset"]; got != "utf8mb4,utf8" {
+		t.Errorf("charset: want %q, got %q", "utf8mb4,utf8", got)
+	}
+	if cfg.Collation != "utf8mb4_unicode_ci" {
+		t.Errorf("collation: want %q, got %q", "utf8mb4_unicode_ci", cfg.Collation)
+	}
+	if cfg.Loc.String() != "America/New_York" {
+		t.Errorf("loc: want %q, got %q", "America/New_York", cfg.Loc)
+	}
+	if cfg.MaxAllowedPacket != 16777216 {
+		t.Errorf("maxAllowedPacket: want %d, got %d", 16777216, cfg.MaxAllowedPacket)
+	}
+	if got := cfg.Params["sql_mode"]; got != sqlMode {
+		t.Errorf("sql_mode: want %q, got %q", sqlMode, got)
+	}
+	if !cfg.ParseTime {
+		t.Errorf("parseTime: want true, got false")
+	}
+	if cfg.ReadTimeout != 90*time.Second {
+		t.Errorf("readTimeout: want %s, got %s", 90*time.Second, cfg.ReadTimeout)
+	}
+}
+
+func TestNewUnixSocket(t *testing.T) {
+	db := New(map[string][]byte{
+		"username": []byte("username"),
+		"password": []byte("password"),
+		"endpoint": []byte("/cloudsql/project:region:instance"),
+		"protocol": []byte("unix"),
+	}, nil, nil, nil).(mySQLDB)
+	if db.dsn != "username:password@unix(/cloudsql/project:region:instance)/?tls=preferred&sql_mode="+url.QueryEscape(sqlMode) {
+		t.Errorf("DSN string did not match expected output with Unix socket: %s", db.dsn)
+	}
+}
+
+func TestNewAzureAD(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "client")
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "/var/run/secrets/azure/tokens/azure-identity-token")
+	pc := &v1alpha1.ProviderConfig{Spec: v1alpha1.ProviderConfigSpec{
+		Credentials: v1alpha1.ProviderCredentials{
+			Source:        v1alpha1.CredentialsSourceAzureAD,
+			AzureADMethod: ptr.To("ActiveDirectoryWorkloadIdentity"),
+		},
+	}}
+	db := New(map[string][]byte{
+		"username": []byte("provider"),
+		"endpoint": []byte("example.mysql.database.azure.com"),
+		"port":     []byte("3306"),
+	}, ptr.To("true"), nil, OptionsFromProviderConfig(pc)).(mySQLDB)
+	if db.tokens == nil {
+		t.Errorf("New(...): want an Azure AD token source")
+	}
+	if got := db.dsnFor("token"); got != "provider:token@tcp(example.mysql.database.azure.com:3306)/?tls=true&sql_mode="+url.QueryEscape(sqlMode)+"&allowCleartextPasswords=true" {
+		t.Errorf("DSN string did not match expected output with an access token: %s", got)
+	}
+}
+
+func TestGetConnectionDetailsProtocol(t *testing.T) {
+	cases := map[string]struct {
+		endpoint string
+		want     string
+	}{
+		"TCP":  {endpoint: "db.example.org", want: ""},
+		"Unix": {endpoint: "/var/run/mysqld/mysqld.sock", want: "unix"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cd := New(map[string][]byte{"endpoint": []byte(tc.endpoint)}, nil, nil, nil).GetConnectionDetails("username", "password")
+			if got := string(cd["protocol"]); got != tc.want {
+				t.Errorf("protocol: want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetConnectionDetailsTLS(t *testing.T) {
+	cases := map[string]struct {
+		tls  *string
+		want string
+	}{
+		"Default":    {tls: nil, want: ""},
+		"Preferred":  {tls: ptr.To("preferred"), want: ""},
+		"True":       {tls: ptr.To("true"), want: "true"},
+		"SkipVerify": {tls: ptr.To("skip-verify"), want: "skip-verify"},
+		"Custom":     {tls: ptr.To("custom-default"), want: "true"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cd := New(map[string][]byte{}, tc.tls, nil, nil).GetConnectionDetails("username", "password")
+			if got := string(cd["tls"]); got != tc.want {
+				t.Errorf("tls: want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	driverName = "oracle"
+
+	errNotSupported = "%s not supported by oracle client"
+)
+
+type oracleDB struct {
+	dsn      string
+	endpoint string
+	port     string
+}
+
+// Options configures how an Oracle database client connects to the server.
+type Options struct {
+	// TLS connects over TCPS.
+	TLS bool
+
+	// Wallet is the directory of an auto-login wallet.
+	Wallet string
+
+	// ConnectionOptions are additional driver parameters. Keys must be
+	// one of ConnectionOptions.
+	ConnectionOptions map[string]string
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"CONNECTION TIMEOUT",
+	"LANGUAGE",
+	"PREFETCH_ROWS",
+	"PROGRAM",
+	"SSL VERIFY",
+	"TERRITORY",
+	"TIMEOUT",
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the Oracle client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
+}
+
+// New returns a new Oracle database client.
+func New(creds map[string][]byte, service string, opts Options) xsql.DB {
+	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
+	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
+	username := string(creds[xpv1.ResourceCredentialsSecretUserKey])
+	password := string(creds[xpv1.ResourceCredentialsSecretPasswordKey])
+
+	return oracleDB{
+		dsn:      DSN(username, password, endpoint, port, service, opts),
+		endpoint: endpoint,
+		port:     port,
+	}
+}
+
+// DSN returns the DSN URL
+func DSN(username, password, endpoint, port, service string, opts Options) string {
+	query := url.Values{}
+	for k, v := range opts.ConnectionOptions {
+		query.Set(k, v)
+	}
+	if opts.TLS {
+		query.Set("SSL", "true")
+	}
+	if opts.Wallet != "" {
+		query.Set("WALLET", opts.Wallet)
+	}
+	u := url.URL{
+		Scheme:   driverName,
+		User:     url.UserPassword(username, password),
+		Host:     fmt.Sprintf("%s:%s", endpoint, port),
+		Path:     "/" + service,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// WalletDir returns the directory the wallet of the supplied ProviderConfig
+// is written to.
+func WalletDir(providerConfigName string) string {
+	return filepath.Join(os.TempDir(), "oracle-wallets", providerConfigName)
+}
+
+// WriteWallet writes the supplied wallet files to the supplied directory,
+// replacing any files of the same name.
+func WriteWallet(dir string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)), data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecTx is unsupported in Oracle, where DDL statements commit implicitly.
+func (c oracleDB) ExecTx(_ context.Context, _ []xsql.Query) error {
+	return errors.Errorf(errNotSupported, "transactions")
+}
+
+// Exec the supplied query.
+func (c oracleDB) Exec(ctx context.Context, q xsql.Query) error {
+	d, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
+	return err
+}
+
+// Query the supplied query.
+func (c oracleDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	d, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	return d.QueryContext(ctx, q.String, q.Parameters...)
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (c oracleDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	db, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(db) //nolint:errcheck
+
+	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
+}
+
+// Dialect returns the SQL dialect of Oracle as used by this provider, which
+// quotes passwords in double quotes.
+func (c oracleDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{DoubleQuotedStrings: true}
+}
+
+// GetConnectionDetails returns the connection details for a user of this DB
+func (c oracleDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte(c.endpoint),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte(c.port),
+	}
+}
+
+// ReservedUsers are the administrative accounts created by Oracle itself,
+// which must not be managed.
+var ReservedUsers = []string{
+	"AUDSYS",
+	"DBSNMP",
+	"OUTLN",
+	"SYS",
+	"SYSBACKUP",
+	"SYSDG",
+	"SYSKM",
+	"SYSRAC",
+	"SYSTEM",
+	"XDB",
+}
+
+// IsReservedUser returns true if the supplied user name is one of the
+// ReservedUsers, or the user the provider connects as. Unquoted user names
+// are upper case in Oracle, so the connection user is compared case
+// insensitively.
+func IsReservedUser(username, connectionUser string) bool {
+	return (connectionUser != "" && strings.EqualFold(username, connectionUser)) || slices.Contains(ReservedUsers, username)
+}
+
+// QuoteIdentifier for Oracle queries
+func QuoteIdentifier(id string) string {
+	return `"` + strings.ReplaceAll(id, `"`, `""`) + `"`
+}
+
+// QuoteValue for Oracle queries
+func QuoteValue(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// ExecQuery declares the query to execute and its error value if it fails
+type ExecQuery struct {
+	// Query defines the sql statement to execute
+	Query string
+	// ErrorValue defines what error will be returned if the provided sql statement failed when executing
+	ErrorValue string
+}
+
+// ExecWrapper executes the supplied query, wrapping any error in its
+// ErrorValue.
+func ExecWrapper(ctx context.Context, db xsql.DB, query ExecQuery) error {
+	if err := db.Exec(ctx, xsql.Query{String: query.Query}); err != nil {
+		return errors.Wrap(err, query.ErrorValue)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// The pgaudit configuration parameters that typed audit settings manage.
+const (
+	auditLog          = "pgaudit.log"
+	auditLogParameter = "pgaudit.log_parameter"
+	auditLogRelation  = "pgaudit.log_relation"
+)
+
+// AuditParameters are the names of the configuration parameters that the
+// audit settings of roles and databases manage.
+var AuditParameters = []string{auditLog, auditLogParameter, auditLogRelation}
+
+// IsAuditParameter returns true if the supplied configuration parameter is
+// managed by audit settings. Parameter names are case insensitive.
+func IsAuditParameter(name string) bool {
+	return slices.Contains(AuditParameters, strings.ToLower(name))
+}
+
+// AuditConfiguration returns the values of the configuration parameters that
+// the supplied audit settings set, by name. Parameters whose settings are
+// omitted are not included, since they are to be reset.
+func AuditConfiguration(a *v1alpha1.AuditSettings) map[string]string {
+	cfg := map[string]string{}
+	if a == nil {
+		return cfg
+	}
+	if len(a.Log) > 0 {
+		classes := make([]string, len(a.Log))
+		for i, c := range a.Log {
+			classes[i] = string(c)
+		}
+		cfg[auditLog] = normalizeAuditValue(auditLog, strings.Join(classes, ","))
+	}
+	if a.LogParameter != nil {
+		cfg[auditLogParameter] = onOff(*a.LogParameter)
+	}
+	if a.LogRelation != nil {
+		cfg[auditLogRelation] = onOff(*a.LogRelation)
+	}
+	return cfg
+}
+
+// ObservedAuditConfiguration returns the values of the configuration
+// parameters managed by audit settings in the supplied configuration, as
+// stored in the rolconfig or setconfig columns, i.e. as name=value pairs.
+func ObservedAuditConfiguration(config []string) map[string]string {
+	cfg := map[string]string{}
+	for _, kv := range config {
+		name, value, _ := strings.Cut(kv, "=")
+		if IsAuditParameter(name) {
+			name = strings.ToLower(name)
+			cfg[name] = normalizeAuditValue(name, value)
+		}
+	}
+	return cfg
+}
+
+// AuditSettingsFromConfiguration returns the audit settings that the
+// supplied configuration, as returned by ObservedAuditConfiguration, holds.
+func AuditSettingsFromConfiguration(cfg map[string]string) *v1alpha1.AuditSettings {
+	a := &v1alpha1.AuditSettings{}
+	if v, ok := cfg[auditLog]; ok && v != "" {
+		for _, c := range strings.Split(v, ",") {
+			a.Log = append(a.Log, v1alpha1.AuditLogClass(strings.ToUpper(c)))
+		}
+	}
+	if v, ok := cfg[auditLogParameter]; ok {
+		a.LogParameter = ptr.To(v == "on")
+	}
+	if v, ok := cfg[auditLogRelation]; ok {
+		a.LogRelation = ptr.To(v == "on")
+	}
+	return a
+}
+
+// AuditQueries returns the statements that change the observed audit
+// configuration of an object, e.g. ROLE "example", to the desired one.
+// Parameters that are not desired are reset.
+func AuditQueries(object string, desired, observed map[string]string) []xsql.Query {
+	var ql []xsql.Query
+	for _, name := range AuditParameters {
+		d, set := desired[name]
+		o, present := observed[name]
+		switch {
+		case set && (!present || d != o):
+			ql = append(ql, xsql.Query{String: fmt.Sprintf("ALTER %s SET %s = %s", object, name, pq.QuoteLiteral(d))})
+		case !set && present:
+			ql = append(ql, xsql.Query{String: fmt.Sprintf("ALTER %s RESET %s", object, name)})
+		}
+	}
+	return ql
+}
+
+// normalizeAuditValue returns the supplied value of an audit configuration
+// parameter in the form AuditConfiguration produces, so that values that
+// were set outside of the provider compare equal when they mean the same.
+func normalizeAuditValue(name, value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if name != auditLog {
+		switch value {
+		case "on", "true", "yes", "1":
+			return "on"
+		case "off", "false", "no", "0":
+			return "off"
+		}
+		return value
+	}
+	var classes []string
+	for _, c := range strings.Split(value, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			classes = append(classes, c)
+		}
+	}
+	slices.Sort(classes)
+	return strings.Join(slices.Compact(classes), ",")
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestAuditConfiguration(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		audit  *v1alpha1.AuditSettings
+		want   map[string]string
+	}{
+		"Omitted": {
+			reason: "Omitted audit settings should set no parameters",
+			want:   map[string]string{},
+		},
+		"Normalized": {
+			reason: "Log classes should be lowered, sorted and deduplicated, and booleans spelled on or off",
+			audit: &v1alpha1.AuditSettings{
+				Log:          []v1alpha1.AuditLogClass{"WRITE", "DDL", "WRITE"},
+				LogParameter: ptr.To(false),
+			},
+			want: map[string]string{
+				"pgaudit.log":           "ddl,write",
+				"pgaudit.log_parameter": "off",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, AuditConfiguration(tc.audit)); diff != "" {
+				t.Errorf("\n%s\nAuditConfiguration(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservedAuditConfiguration(t *testing.T) {
+	config := []string{"search_path=app", "PGAudit.Log=Write, DDL", "pgaudit.log_relation=1", "pgaudit.log_level=notice"}
+	want := map[string]string{
+		"pgaudit.log":          "ddl,write",
+		"pgaudit.log_relation": "on",
+	}
+	if diff := cmp.Diff(want, ObservedAuditConfiguration(config)); diff != "" {
+		t.Errorf("ObservedAuditConfiguration(...): -want, +got:\n%s", diff)
+	}
+}
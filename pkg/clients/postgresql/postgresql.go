@@ -5,65 +5,217 @@ import (
 	"database/sql"
 	"errors"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 	"github.com/lib/pq"
+	"k8s.io/utils/ptr"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/azuread"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
 const (
 	// https://www.postgresql.org/docs/current/errcodes-appendix.html
 	// These are not available as part of the pq library.
-	pqInvalidCatalog = pq.ErrorCode("3D000")
+	pqInvalidCatalog  = pq.ErrorCode("3D000")
+	pqUndefinedObject = pq.ErrorCode("42704")
+	pqObjectInUse     = pq.ErrorCode("55006")
+	pqDeadlock        = pq.ErrorCode("40P01")
 )
 
+// validateTag quotes the body of the anonymous code block that statements
+// are validated in.
+const validateTag = "$xsql_validate$"
+
+// IdentifierLimit is the limit of PostgreSQL identifiers, which are
+// truncated to NAMEDATALEN-1 bytes.
+var IdentifierLimit = xsql.IdentifierLimit{Engine: "PostgreSQL", Length: 63, Bytes: true}
+
+// azureADOption is the connection option that OptionsFromProviderConfig
+// sets to the Azure AD authentication method of a ProviderConfig whose
+// credentials source is AzureAD. It is not a driver parameter, and is not one
+// of ConnectionOptions, so it cannot be set by users.
+const azureADOption = "azure_ad_method"
+
 type postgresDB struct {
 	dsn      string
 	endpoint string
 	port     string
+	protocol string
 	sslmode  string
+
+	// tokens are the Azure AD access tokens used as the password, if any.
+	// The DSN is then built for each connection from the fields below.
+	tokens      *azuread.TokenSource
+	err         error
+	username    string
+	dsnEndpoint string
+	database    string
+	opts        map[string]string
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"application_name",
+	"connect_timeout",
+	"fallback_application_name",
+	"sslcert",
+	"sslkey",
+	"sslrootcert",
+	"sslsni",
+}
+
+// binaryParametersOption is the driver parameter that makes pq send the
+// parameters of a statement in the same round trip as the statement, rather
+// than preparing it first. A pooler in transaction mode may otherwise run
+// the two on different server connections.
+const binaryParametersOption = "binary_parameters"
+
+// OptionsFromProviderConfig returns the connection options of the supplied
+// ProviderConfig. They include the Azure AD authentication method if its
+// credentials source is AzureAD, and binary_parameters if it connects
+// through a pooler in transaction mode.
+func OptionsFromProviderConfig(pc *v1alpha1.ProviderConfig) map[string]string {
+	azureAD := pc.Spec.Credentials.Source == v1alpha1.CredentialsSourceAzureAD
+	if !azureAD && !TransactionPooling(pc) {
+		return pc.Spec.ConnectionOptions
+	}
+	opts := make(map[string]string, len(pc.Spec.ConnectionOptions)+2)
+	for k, v := range pc.Spec.ConnectionOptions {
+		opts[k] = v
+	}
+	if azureAD {
+		opts[azureADOption] = ptr.Deref(pc.Spec.Credentials.AzureADMethod, azuread.Default)
+	}
+	if TransactionPooling(pc) {
+		opts[binaryParametersOption] = "yes"
+	}
+	return opts
+}
+
+// TransactionPooling returns true if the supplied ProviderConfig connects
+// through a pooler in transaction mode, which does not keep session state
+// between transactions.
+func TransactionPooling(pc *v1alpha1.ProviderConfig) bool {
+	return ptr.Deref(pc.Spec.Pooler, "") == v1alpha1.PoolerPgBouncerTransaction
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the PostgreSQL client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
 }
 
 // New returns a new PostgreSQL database client. The default database name is
 // an empty string. The underlying pq library will default to either using the
 // value of PGDATABASE, or if unset, the hardcoded string 'postgres'.
 // The sslmode defines the mode used to set up the connection for the provider.
-func New(creds map[string][]byte, database, sslmode string) xsql.DB {
+// If the connection secret sets protocol to unix, or its endpoint is an
+// absolute path, the endpoint is used as the directory containing the Unix
+// domain socket of the server. If the options returned by
+// OptionsFromProviderConfig select Azure AD authentication, an access token
+// is used as the password instead of that of the credentials.
+func New(creds map[string][]byte, database, sslmode string, opts map[string]string) xsql.DB {
 	// TODO(negz): Support alternative connection secret formats?
 	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
 	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
 	username := string(creds[xpv1.ResourceCredentialsSecretUserKey])
 	password := string(creds[xpv1.ResourceCredentialsSecretPasswordKey])
-	dsn := DSN(username, password, endpoint, port, database, sslmode)
+	var tokens *azuread.TokenSource
+	var err error
+	if m, ok := opts[azureADOption]; ok {
+		tokens, err = azuread.NewTokenSource(m, azuread.ScopeOSSRDBMS)
+		driverOpts := make(map[string]string, len(opts))
+		for k, v := range opts {
+			if k != azureADOption {
+				driverOpts[k] = v
+			}
+		}
+		opts = driverOpts
+	}
+	protocol := xsql.ProtocolTCP
+	dsnEndpoint := endpoint
+	if dir, ok := xsql.UnixSocket(creds); ok {
+		// pq connects through a Unix domain socket when host is a
+		// directory, which cannot be expressed in the host of the URL.
+		protocol = xsql.ProtocolUnix
+		dsnEndpoint = ""
+		withHost := make(map[string]string, len(opts)+1)
+		for k, v := range opts {
+			withHost[k] = v
+		}
+		withHost["host"] = dir
+		opts = withHost
+	}
+	dsn := DSN(username, password, dsnEndpoint, port, database, sslmode, opts)
 
 	return postgresDB{
-		dsn:      dsn,
-		endpoint: endpoint,
-		port:     port,
-		sslmode:  sslmode,
+		dsn:         dsn,
+		endpoint:    endpoint,
+		port:        port,
+		protocol:    protocol,
+		sslmode:     sslmode,
+		tokens:      tokens,
+		err:         err,
+		username:    username,
+		dsnEndpoint: dsnEndpoint,
+		database:    database,
+		opts:        opts,
 	}
 }
 
 // DSN returns the DSN URL
-func DSN(username, password, endpoint, port, database, sslmode string) string {
+func DSN(username, password, endpoint, port, database, sslmode string, opts map[string]string) string {
 	// Use net/url UserPassword to encode the username and password
 	// This will ensure that any special characters in the username or password
 	// are percent-encoded for use in the user info portion of the DSN URL
 	userInfo := url.UserPassword(username, password)
+	query := url.Values{}
+	for k, v := range opts {
+		query.Set(k, v)
+	}
+	query.Set("sslmode", sslmode)
 	return "postgres://" +
 		userInfo.String() + "@" +
 		endpoint + ":" +
 		port + "/" +
 		database +
-		"?sslmode=" + sslmode
+		"?" + query.Encode()
+}
+
+// open opens a database handle. If the client authenticates using Azure AD,
+// the current access token is used as the password.
+func (c postgresDB) open(ctx context.Context) (*sql.DB, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.tokens == nil {
+		return xsql.Open(ctx, "postgres", c.dsn)
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return xsql.Open(ctx, "postgres", DSN(c.username, token, c.dsnEndpoint, c.port, c.database, c.sslmode, c.opts))
 }
 
 // ExecTx executes an array of queries, committing if all are successful and
 // rolling back immediately on failure.
 func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
-	d, err := sql.Open("postgres", c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
@@ -76,7 +228,7 @@ func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
 	// Rollback or Commit based on error state. Defer close in defer to make
 	// sure the connection is always closed.
 	defer func() {
-		defer d.Close() //nolint:errcheck
+		defer xsql.Close(d) //nolint:errcheck
 		if err != nil {
 			tx.Rollback() //nolint:errcheck
 			return
@@ -94,11 +246,11 @@ func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
 
 // Exec the supplied query.
 func (c postgresDB) Exec(ctx context.Context, q xsql.Query) error {
-	d, err := sql.Open("postgres", c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
 	return err
@@ -106,11 +258,11 @@ func (c postgresDB) Exec(ctx context.Context, q xsql.Query) error {
 
 // Query the supplied query.
 func (c postgresDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-	d, err := sql.Open("postgres", c.dsn)
+	d, err := c.open(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer d.Close() //nolint:errcheck
+	defer xsql.Close(d) //nolint:errcheck
 
 	rows, err := d.QueryContext(ctx, q.String, q.Parameters...)
 	return rows, err
@@ -118,23 +270,69 @@ func (c postgresDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error)
 
 // Scan the results of the supplied query into the supplied destination.
 func (c postgresDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-	db, err := sql.Open("postgres", c.dsn)
+	db, err := c.open(ctx)
 	if err != nil {
 		return err
 	}
-	defer db.Close() //nolint:errcheck
+	defer xsql.Close(db) //nolint:errcheck
 
 	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
 }
 
-// GetConnectionDetails returns the connection details for a user of this DB
+// Validate checks the syntax of the supplied statements without executing
+// them. They are compiled as the body of an anonymous code block that returns
+// before running them, which PL/pgSQL parses unless check_function_bodies is
+// turned off. Statements with parameters are not validated.
+func (c postgresDB) Validate(ctx context.Context, ql []xsql.Query) error {
+	var body strings.Builder
+	for _, q := range ql {
+		if len(q.Parameters) > 0 || strings.Contains(q.String, validateTag) {
+			continue
+		}
+		body.WriteString(strings.TrimRight(strings.TrimSpace(q.String), ";"))
+		body.WriteString(";\n")
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+	return c.Exec(ctx, xsql.Query{String: "DO " + validateTag + " BEGIN RETURN;\n" + body.String() + "END " + validateTag})
+}
+
+// Dialect returns the SQL dialect of PostgreSQL with standard conforming
+// strings, where only literals prefixed with E use backslash escapes.
+func (c postgresDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{EscapeStringPrefix: true, DollarQuotedStrings: true}
+}
+
+// GetConnectionDetails returns the connection details for a user of this DB.
+// They include the sslmode if the provider connects using TLS, so that
+// consumers connect using at least the same mode. The protocol is included
+// only if the endpoint is a Unix domain socket.
 func (c postgresDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
-	return managed.ConnectionDetails{
+	cd := managed.ConnectionDetails{
 		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
 		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
 		xpv1.ResourceCredentialsSecretEndpointKey: []byte(c.endpoint),
 		xpv1.ResourceCredentialsSecretPortKey:     []byte(c.port),
 	}
+	if c.protocol != xsql.ProtocolTCP {
+		cd[xsql.ProtocolKey] = []byte(c.protocol)
+	}
+	if RequiresTLS(c.sslmode) {
+		cd[xsql.SSLModeKey] = []byte(c.sslmode)
+	}
+	return cd
+}
+
+// RequiresTLS returns true if the supplied sslmode only allows connections
+// using TLS.
+func RequiresTLS(sslmode string) bool {
+	switch sslmode {
+	case "require", "verify-ca", "verify-full":
+		return true
+	default:
+		return false
+	}
 }
 
 // IsInvalidCatalog returns true if passed a pq error indicating
@@ -146,3 +344,84 @@ func IsInvalidCatalog(err error) bool {
 	}
 	return false
 }
+
+// IsUndefinedObject returns true if passed a pq error indicating that a
+// referenced object, such as a type, does not exist.
+func IsUndefinedObject(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqUndefinedObject
+	}
+	return false
+}
+
+// IsObjectInUse returns true if passed a pq error indicating that an
+// object, such as the template of a new database, is being accessed by
+// other users.
+func IsObjectInUse(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqObjectInUse
+	}
+	return false
+}
+
+// IsDeadlock returns true if passed a pq error indicating that the
+// transaction was aborted to resolve a deadlock, and may be retried.
+func IsDeadlock(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqDeadlock
+	}
+	return false
+}
+
+// ServerVersion returns the version of the server as a number, e.g. 150004
+// for PostgreSQL 15.4.
+func ServerVersion(ctx context.Context, db xsql.DB) (int, error) {
+	var v int
+	err := db.Scan(ctx, xsql.Query{String: "SELECT current_setting('server_version_num')::int"}, &v)
+	return v, err
+}
+
+// versionTTL is how long CachedServerVersion caches the version of a server,
+// so that an upgrade is noticed without restarting the provider.
+const versionTTL = 10 * time.Minute
+
+// versions are the server versions cached by CachedServerVersion, keyed by
+// ProviderConfig.
+var versions = struct {
+	sync.Mutex
+	cached map[string]cachedVersion
+}{cached: map[string]cachedVersion{}}
+
+type cachedVersion struct {
+	version int
+	expires time.Time
+}
+
+// CachedServerVersion returns the version of the server of the supplied
+// ProviderConfig. It is selected using the supplied client unless it was
+// selected less than versionTTL ago. An empty ProviderConfig name disables
+// caching.
+func CachedServerVersion(ctx context.Context, providerConfig string, db xsql.DB) (int, error) {
+	if providerConfig == "" {
+		return ServerVersion(ctx, db)
+	}
+
+	versions.Lock()
+	cv, ok := versions.cached[providerConfig]
+	versions.Unlock()
+	if ok && time.Now().Before(cv.expires) {
+		return cv.version, nil
+	}
+
+	v, err := ServerVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	versions.Lock()
+	versions.cached[providerConfig] = cachedVersion{version: v, expires: time.Now().Add(versionTTL)}
+	versions.Unlock()
+	return v, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redshift
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Redshift speaks the PostgreSQL wire protocol, so its clients use the
+// PostgreSQL driver. Its dialect is that of PostgreSQL 8.0, in which a
+// backslash escapes the next character of any string literal.
+type redshiftDB struct {
+	xsql.DB
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"application_name",
+	"connect_timeout",
+	"sslrootcert",
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the Redshift client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
+}
+
+// New returns a new Redshift database client connected to the supplied
+// database.
+func New(creds map[string][]byte, database, sslmode string, opts map[string]string) xsql.DB {
+	return redshiftDB{DB: postgresql.New(creds, database, sslmode, opts)}
+}
+
+// Dialect returns the SQL dialect of Redshift.
+func (c redshiftDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{BackslashEscapes: true}
+}
+
+// ReservedUsers are the users created by Amazon Redshift, which cannot be
+// managed.
+var ReservedUsers = []string{
+	"rdsdb",
+}
+
+// IsReservedUser returns true if the supplied user name is one of the
+// ReservedUsers, or the user the provider connects as.
+func IsReservedUser(username, connectionUser string) bool {
+	return (connectionUser != "" && username == connectionUser) || slices.Contains(ReservedUsers, username)
+}
+
+// QuoteIdentifier for Redshift queries
+func QuoteIdentifier(id string) string {
+	return pq.QuoteIdentifier(id)
+}
+
+// QuoteValue for Redshift queries
+func QuoteValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(v) + "'"
+}
+
+// QualifiedName returns the quoted, dot separated name of an object in a
+// schema, e.g. "schema"."table".
+func QualifiedName(names ...string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = QuoteIdentifier(n)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// GeneratePassword returns a random password that satisfies the password
+// requirements of Redshift, which are that it contains an uppercase letter,
+// a lowercase letter and a digit.
+func GeneratePassword() (string, error) {
+	for {
+		pw, err := password.Generate()
+		if err != nil {
+			return "", err
+		}
+		if strings.IndexFunc(pw, unicode.IsUpper) >= 0 && strings.IndexFunc(pw, unicode.IsLower) >= 0 && strings.IndexFunc(pw, unicode.IsDigit) >= 0 {
+			return pw, nil
+		}
+	}
+}
+
+// ExecQuery declares the query to execute and its error value if it fails
+type ExecQuery struct {
+	// Query defines the sql statement to execute
+	Query string
+	// ErrorValue defines what error will be returned if the provided sql statement failed when executing
+	ErrorValue string
+}
+
+// ExecWrapper executes the supplied query, wrapping any error in its
+// ErrorValue.
+func ExecWrapper(ctx context.Context, db xsql.DB, query ExecQuery) error {
+	if err := db.Exec(ctx, xsql.Query{String: query.Query}); err != nil {
+		return errors.Wrap(err, query.ErrorValue)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redshift
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestQuoteValue(t *testing.T) {
+	if got := QuoteValue(`it's a \ test`); got != `'it''s a \\ test'` {
+		t.Errorf("QuoteValue(...): got %s", got)
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	if got := QualifiedName(`my"schema`, "table"); got != `"my""schema"."table"` {
+		t.Errorf("QualifiedName(...): got %s", got)
+	}
+}
+
+func TestIsReservedUser(t *testing.T) {
+	cases := map[string]struct {
+		username       string
+		connectionUser string
+		want           bool
+	}{
+		"ConnectionUser": {username: "admin", connectionUser: "admin", want: true},
+		"RDSDB":          {username: "rdsdb", connectionUser: "admin", want: true},
+		"OtherUser":      {username: "example", connectionUser: "admin", want: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsReservedUser(tc.username, tc.connectionUser); got != tc.want {
+				t.Errorf("IsReservedUser(%q, %q): want %t, got %t", tc.username, tc.connectionUser, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		pw, err := GeneratePassword()
+		if err != nil {
+			t.Fatalf("GeneratePassword(): %v", err)
+		}
+		for _, f := range []func(rune) bool{unicode.IsUpper, unicode.IsLower, unicode.IsDigit} {
+			if strings.IndexFunc(pw, f) < 0 {
+				t.Fatalf("GeneratePassword(): %q does not satisfy the password requirements", pw)
+			}
+		}
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	driverName = "snowflake"
+
+	// PrivateKeyKey is the key of the connection secret that holds the
+	// unencrypted PKCS#8 PEM private key used for key-pair authentication.
+	PrivateKeyKey = "privateKey"
+
+	errNotSupported   = "%s not supported by snowflake client"
+	errNoPEM          = "cannot decode PEM private key"
+	errParsePKCS1     = "cannot parse PKCS#1 private key"
+	errMarshalPrivKey = "cannot convert private key to PKCS#8"
+)
+
+type snowflakeDB struct {
+	dsn     string
+	err     error
+	account string
+}
+
+// ConnectionOptions are the driver parameters that may be supplied through
+// the connectionOptions of a ProviderConfig.
+var ConnectionOptions = []string{
+	"application",
+	"clientTimeout",
+	"jwtTimeout",
+	"loginTimeout",
+	"requestTimeout",
+}
+
+// ValidateConnectionOptions returns an error if any of the supplied
+// connection options is not supported by the Snowflake client.
+func ValidateConnectionOptions(opts map[string]string) error {
+	return xsql.ValidateOptions(opts, ConnectionOptions)
+}
+
+// ConnectionLimits returns the limits of the connections opened for the
+// managed resources of the supplied ProviderConfig.
+func ConnectionLimits(pc *v1alpha1.ProviderConfig) xsql.ConnectionLimits {
+	return xsql.NewConnectionLimits(v1alpha1.ProviderConfigGroupKind+"/"+pc.GetName(), pc.Spec.MaxOpenConnections, pc.Spec.MaxIdleConnections)
+}
+
+// New returns a new Snowflake database client. It authenticates with the
+// private key of the connection secret if there is one, and with the
+// password otherwise.
+func New(creds map[string][]byte, account string, role, warehouse *string, opts map[string]string) xsql.DB {
+	username := string(creds[xpv1.ResourceCredentialsSecretUserKey])
+	password := string(creds[xpv1.ResourceCredentialsSecretPasswordKey])
+
+	dsn, err := DSN(username, password, creds[PrivateKeyKey], account, role, warehouse, opts)
+	return snowflakeDB{
+		dsn:     dsn,
+		err:     err,
+		account: account,
+	}
+}
+
+// DSN returns the DSN of the supplied account.
+func DSN(username, password string, privateKey []byte, account string, role, warehouse *string, opts map[string]string) (string, error) {
+	query := url.Values{}
+	for k, v := range opts {
+		query.Set(k, v)
+	}
+	if role != nil {
+		query.Set("role", *role)
+	}
+	if warehouse != nil {
+		query.Set("warehouse", *warehouse)
+	}
+
+	user := url.UserPassword(username, password)
+	if len(privateKey) > 0 {
+		der, err := pkcs8(privateKey)
+		if err != nil {
+			return "", err
+		}
+		query.Set("authenticator", "SNOWFLAKE_JWT")
+		query.Set("privateKey", base64.URLEncoding.EncodeToString(der))
+		user = url.User(username)
+	}
+
+	return user.String() + "@" + account + "/?" + query.Encode(), nil
+}
+
+// pkcs8 returns the DER encoded PKCS#8 form of the supplied PEM private key,
+// which is what the driver expects.
+func pkcs8(key []byte) ([]byte, error) {
+	b, _ := pem.Decode(key)
+	if b == nil {
+		return nil, errors.New(errNoPEM)
+	}
+	if b.Type != "RSA PRIVATE KEY" {
+		return b.Bytes, nil
+	}
+	k, err := x509.ParsePKCS1PrivateKey(b.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePKCS1)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	return der, errors.Wrap(err, errMarshalPrivKey)
+}
+
+// ExecTx is unsupported in Snowflake, where DDL statements commit
+// implicitly.
+func (c snowflakeDB) ExecTx(_ context.Context, _ []xsql.Query) error {
+	return errors.Errorf(errNotSupported, "transactions")
+}
+
+// Exec the supplied query.
+func (c snowflakeDB) Exec(ctx context.Context, q xsql.Query) error {
+	if c.err != nil {
+		return c.err
+	}
+	d, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
+	return err
+}
+
+// Query the supplied query.
+func (c snowflakeDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	d, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer xsql.Close(d) //nolint:errcheck
+
+	return d.QueryContext(ctx, q.String, q.Parameters...)
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (c snowflakeDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	db, err := xsql.Open(ctx, driverName, c.dsn)
+	if err != nil {
+		return err
+	}
+	defer xsql.Close(db) //nolint:errcheck
+
+	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
+}
+
+// Dialect returns the SQL dialect of Snowflake, whose string literals use
+// backslash escapes and may be dollar quoted.
+func (c snowflakeDB) Dialect() xsql.Dialect {
+	return xsql.Dialect{BackslashEscapes: true, DollarQuotedStrings: true}
+}
+
+// GetConnectionDetails returns the connection details for a user of this DB
+func (c snowflakeDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte(c.account),
+	}
+}
+
+// Show runs the supplied SHOW command and returns its rows keyed by column
+// name. SHOW commands cannot be filtered by a WHERE clause, and the columns
+// they return differ between Snowflake releases.
+func Show(ctx context.Context, db xsql.DB, query string) ([]map[string]string, error) {
+	rows, err := db.Query(ctx, xsql.Query{String: query})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		dest := make([]interface{}, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(cols))
+		for i, c := range cols {
+			row[c] = values[i].String
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// ReservedRoles are the system-defined roles of a Snowflake account, which
+// cannot be dropped or managed.
+var ReservedRoles = []string{
+	"ACCOUNTADMIN",
+	"ORGADMIN",
+	"PUBLIC",
+	"SECURITYADMIN",
+	"SYSADMIN",
+	"USERADMIN",
+}
+
+// IsReservedRole returns true if the supplied role is one of the
+// ReservedRoles, or the role the provider connects as.
+func IsReservedRole(role string, connectionRole *string) bool {
+	return (connectionRole != nil && role == *connectionRole) || slices.Contains(ReservedRoles, role)
+}
+
+// QuoteIdentifier for Snowflake queries
+func QuoteIdentifier(id string) string {
+	return `"` + strings.ReplaceAll(id, `"`, `""`) + `"`
+}
+
+// QuoteValue for Snowflake queries
+func QuoteValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(v) + "'"
+}
+
+// QualifiedName returns the quoted, dot separated name of an object in a
+// database or schema, e.g. "db"."schema".
+func QualifiedName(names ...string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = QuoteIdentifier(n)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// ExecQuery declares the query to execute and its error value if it fails
+type ExecQuery struct {
+	// Query defines the sql statement to execute
+	Query string
+	// ErrorValue defines what error will be returned if the provided sql statement failed when executing
+	ErrorValue string
+}
+
+// ExecWrapper executes the supplied query, wrapping any error in its
+// ErrorValue.
+func ExecWrapper(ctx context.Context, db xsql.DB, query ExecQuery) error {
+	if err := db.Exec(ctx, xsql.Query{String: query.Query}); err != nil {
+		return errors.Wrap(err, query.ErrorValue)
+	}
+	return nil
+}
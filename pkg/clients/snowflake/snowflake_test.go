@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestDSN(t *testing.T) {
+	dsn, err := DSN("username", "pass^word", nil, "myorg-account", ptr.To("SYSADMIN"), ptr.To("COMPUTE_WH"), map[string]string{
+		"loginTimeout": "30",
+	})
+	if err != nil {
+		t.Fatalf("DSN(...): %v", err)
+	}
+	if dsn != "username:pass%5Eword@myorg-account/?loginTimeout=30&role=SYSADMIN&warehouse=COMPUTE_WH" {
+		t.Errorf("DSN string did not match expected output: %s", dsn)
+	}
+}
+
+func TestDSNPrivateKey(t *testing.T) {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "username@myorg-account/?authenticator=SNOWFLAKE_JWT&privateKey=" + url.QueryEscape(base64.URLEncoding.EncodeToString(der))
+
+	for name, key := range map[string][]byte{
+		"PKCS8": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		"PKCS1": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dsn, err := DSN("username", "", key, "myorg-account", nil, nil, nil)
+			if err != nil {
+				t.Fatalf("DSN(...): %v", err)
+			}
+			if dsn != want {
+				t.Errorf("DSN string did not match expected output: %s", dsn)
+			}
+		})
+	}
+}
+
+func TestDSNInvalidPrivateKey(t *testing.T) {
+	if _, err := DSN("username", "", []byte("not a key"), "myorg-account", nil, nil, nil); err == nil || !strings.Contains(err.Error(), errNoPEM) {
+		t.Errorf("DSN(...): want error %q, got %v", errNoPEM, err)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if got := QualifiedName(`my"db`, "schema"); got != `"my""db"."schema"` {
+		t.Errorf("QualifiedName(...): got %s", got)
+	}
+}
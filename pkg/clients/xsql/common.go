@@ -3,12 +3,45 @@ package xsql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	"database/sql"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 )
 
+const (
+	// ProtocolKey is the connection secret key that selects how the endpoint
+	// is reached.
+	ProtocolKey = "protocol"
+
+	// ProtocolTCP reaches the endpoint and port over TCP. It is the default.
+	ProtocolTCP = "tcp"
+
+	// ProtocolUnix treats the endpoint as the path of a Unix domain socket.
+	ProtocolUnix = "unix"
+
+	// SSLModeKey is the connection secret key of the PostgreSQL sslmode
+	// that connections must use.
+	SSLModeKey = "sslmode"
+
+	// TLSKey is the connection secret key of the MySQL tls mode that
+	// connections must use.
+	TLSKey = "tls"
+
+	// CACertKey is the connection secret key of the PEM encoded CA bundle
+	// that verifies the certificate of the server.
+	CACertKey = "ca.crt"
+
+	// DatabaseKey is the connection secret key of the database that kinds
+	// which create a database together with its user connect to.
+	DatabaseKey = "database"
+)
+
 // A Query that may be run against a DB.
 type Query struct {
 	String     string
@@ -28,3 +61,84 @@ type DB interface {
 func IsNoRows(err error) bool {
 	return errors.Is(err, sql.ErrNoRows)
 }
+
+// ValidateOptions returns an error naming the first of the supplied
+// connection options whose key is not in the allowed list.
+func ValidateOptions(opts map[string]string, allowed []string) error {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !slices.Contains(allowed, k) {
+			return fmt.Errorf("connection option %q is not supported; supported options are %s", k, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// UnixSocket returns the endpoint of the supplied connection secret and
+// whether it is a Unix domain socket path rather than a TCP host. This is the
+// case when the secret sets protocol to unix, or when it sets no protocol and
+// the endpoint is an absolute path.
+func UnixSocket(creds map[string][]byte) (string, bool) {
+	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
+	switch string(creds[ProtocolKey]) {
+	case ProtocolUnix:
+		return endpoint, true
+	case "":
+		return endpoint, strings.HasPrefix(endpoint, "/")
+	default:
+		return endpoint, false
+	}
+}
+
+// WithConnectionDetails returns a DB whose connection details include the
+// supplied ones, in addition to those of the supplied DB. The DB is returned
+// as is if there are no additional details.
+func WithConnectionDetails(db DB, cd managed.ConnectionDetails) DB {
+	if len(cd) == 0 {
+		return db
+	}
+	return &detailsDB{DB: db, cd: cd}
+}
+
+type detailsDB struct {
+	DB
+	cd managed.ConnectionDetails
+}
+
+func (d *detailsDB) Dialect() Dialect {
+	return DialectOf(d.DB)
+}
+
+func (d *detailsDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	out := d.DB.GetConnectionDetails(username, password)
+	if out == nil {
+		out = managed.ConnectionDetails{}
+	}
+	for k, v := range d.cd {
+		out[k] = v
+	}
+	return out
+}
+
+// CountRows runs the supplied read-only query as a subquery and returns the
+// number of rows it produced. Wrapping the query means only a single SELECT
+// is accepted; statements that modify data are rejected by the server.
+func CountRows(ctx context.Context, db DB, q Query) (int64, error) {
+	s := strings.TrimSpace(q.String)
+	if s == "" {
+		return 0, errors.New("query must not be empty")
+	}
+	if strings.Contains(s, ";") {
+		return 0, errors.New("query must be a single statement without semicolons")
+	}
+	var n int64
+	err := db.Scan(ctx, Query{
+		String:     "SELECT COUNT(*) FROM (" + s + ") AS healthcheck",
+		Parameters: q.Parameters,
+	}, &n)
+	return n, err
+}
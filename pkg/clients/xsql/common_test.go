@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+)
+
+func TestValidateOptions(t *testing.T) {
+	allowed := []string{"connect_timeout", "application_name"}
+	cases := map[string]struct {
+		opts    map[string]string
+		wantErr bool
+	}{
+		"Nil": {
+			opts: nil,
+		},
+		"Allowed": {
+			opts: map[string]string{"connect_timeout": "10", "application_name": "crossplane"},
+		},
+		"NotAllowed": {
+			opts:    map[string]string{"connect_timeout": "10", "sslmode": "disable"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateOptions(tc.opts, allowed)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateOptions(...): want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	cases := map[string]struct {
+		creds map[string][]byte
+		want  bool
+	}{
+		"Host": {
+			creds: map[string][]byte{"endpoint": []byte("db.example.org")},
+			want:  false,
+		},
+		"AbsolutePath": {
+			creds: map[string][]byte{"endpoint": []byte("/var/run/postgresql")},
+			want:  true,
+		},
+		"ExplicitUnix": {
+			creds: map[string][]byte{"endpoint": []byte("mysqld.sock"), "protocol": []byte("unix")},
+			want:  true,
+		},
+		"ExplicitTCP": {
+			creds: map[string][]byte{"endpoint": []byte("/not/a/socket"), "protocol": []byte("tcp")},
+			want:  false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, got := UnixSocket(tc.creds); got != tc.want {
+				t.Errorf("UnixSocket(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+type detailsOnlyDB struct{ DB }
+
+func (detailsOnlyDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{"username": []byte(username), "password": []byte(password)}
+}
+
+func TestWithConnectionDetails(t *testing.T) {
+	cases := map[string]struct {
+		cd   managed.ConnectionDetails
+		want managed.ConnectionDetails
+	}{
+		"None": {
+			want: managed.ConnectionDetails{"username": []byte("u"), "password": []byte("p")},
+		},
+		"Additional": {
+			cd: managed.ConnectionDetails{CACertKey: []byte("ca")},
+			want: managed.ConnectionDetails{
+				"username": []byte("u"),
+				"password": []byte("p"),
+				CACertKey:  []byte("ca"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := WithConnectionDetails(detailsOnlyDB{}, tc.cd).GetConnectionDetails("u", "p")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("WithConnectionDetails(...).GetConnectionDetails(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
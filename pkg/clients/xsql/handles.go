@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const errDraining = "cannot open a database handle while the provider is shutting down"
+
+// handles are the database handles opened by the clients that have not been
+// closed yet. Handles opened using Open are shared, keyed by their driver,
+// DSN and connection limits.
+var handles = struct {
+	sync.Mutex
+	open     map[*sql.DB]*handle
+	shared   map[string]*handle
+	draining bool
+}{open: map[*sql.DB]*handle{}, shared: map[string]*handle{}}
+
+// A handle is reference counted, so that it is closed once the last of the
+// clients that opened it closes it.
+type handle struct {
+	db   *sql.DB
+	key  string
+	refs int
+}
+
+// Open opens a database handle of the supplied driver. Clients that open a
+// handle with the same DSN and connection limits, i.e. those of the managed
+// resources of a ProviderConfig that connect to the same database, share one
+// handle, and so its connections. The handle is closed once each of them has
+// closed it using Close, so that no handle outlives the statements that use
+// it. If the supplied context has connection limits, see
+// WithConnectionLimits, the handle opens connections within them.
+func Open(ctx context.Context, driverName, dsn string) (*sql.DB, error) {
+	key := driverName + "\x00" + dsn
+	if l, ok := limitsFrom(ctx); ok {
+		key += fmt.Sprintf("\x00%s\x00%d\x00%d", l.Pool, l.MaxOpen, l.maxIdle())
+	}
+
+	handles.Lock()
+	defer handles.Unlock()
+	if handles.draining {
+		return nil, errors.New(errDraining)
+	}
+	if h, ok := handles.shared[key]; ok {
+		h.refs++
+		return h.db, nil
+	}
+	db, err := open(ctx, driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	h := &handle{db: db, key: key, refs: 1}
+	handles.open[db] = h
+	handles.shared[key] = h
+	return db, nil
+}
+
+// OpenDB opens a database handle using the supplied connector, e.g. one that
+// authenticates using access tokens. The handle is tracked and limited like
+// those opened using Open, but is never shared.
+func OpenDB(ctx context.Context, c driver.Connector) (*sql.DB, error) {
+	handles.Lock()
+	defer handles.Unlock()
+	if handles.draining {
+		return nil, errors.New(errDraining)
+	}
+	db := limit(ctx, c)
+	handles.open[db] = &handle{db: db, refs: 1}
+	return db, nil
+}
+
+// open opens a database handle of the supplied driver, within the connection
+// limits of the supplied context if it has any.
+func open(ctx context.Context, driverName, dsn string) (*sql.DB, error) {
+	if _, ok := limitsFrom(ctx); !ok {
+		return sql.Open(driverName, dsn)
+	}
+	c, err := connectorOf(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return limit(ctx, c), nil
+}
+
+// limit opens a database handle using the supplied connector, within the
+// connection limits of the supplied context if it has any.
+func limit(ctx context.Context, c driver.Connector) *sql.DB {
+	l, ok := limitsFrom(ctx)
+	if !ok {
+		return sql.OpenDB(c)
+	}
+	if l.MaxOpen > 0 {
+		c = &limitedConnector{Connector: c, slots: slotsOf(l)}
+	}
+	db := sql.OpenDB(c)
+	db.SetMaxIdleConns(l.maxIdle())
+	return db
+}
+
+// Close closes a database handle opened using Open or OpenDB once every
+// client that opened it has closed it.
+func Close(db *sql.DB) error {
+	handles.Lock()
+	h, ok := handles.open[db]
+	if ok {
+		h.refs--
+		if h.refs > 0 {
+			handles.Unlock()
+			return nil
+		}
+		forget(h)
+	}
+	handles.Unlock()
+	return db.Close()
+}
+
+// forget stops tracking the supplied handle. The handles lock must be held.
+func forget(h *handle) {
+	delete(handles.open, h.db)
+	if h.key != "" {
+		delete(handles.shared, h.key)
+	}
+}
+
+// OpenHandles returns the number of database handles that are open.
+func OpenHandles() int {
+	handles.Lock()
+	defer handles.Unlock()
+	return len(handles.open)
+}
+
+// Drain closes all open database handles, and prevents new ones from being
+// opened. Closing a handle waits for the statements that have started on the
+// server to finish, and closes its connections cleanly rather than leaving
+// the server to time them out, which matters to servers with a low
+// max_connections. Drain returns the error of the context if it is done
+// before all handles are closed.
+func Drain(ctx context.Context) error {
+	handles.Lock()
+	handles.draining = true
+	open := make([]*sql.DB, 0, len(handles.open))
+	for db, h := range handles.open {
+		open = append(open, db)
+		forget(h)
+	}
+	handles.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for _, db := range open {
+			wg.Add(1)
+			go func(db *sql.DB) {
+				defer wg.Done()
+				_ = db.Close()
+			}(db)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
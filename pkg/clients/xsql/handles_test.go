@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type nopDriver struct{}
+
+func (nopDriver) Open(string) (driver.Conn, error) { return nil, errors.New("nop") }
+
+func init() {
+	sql.Register("xsql-nop", nopDriver{})
+}
+
+// TestShared runs before TestHandles, which drains all handles.
+func TestShared(t *testing.T) {
+	ctx := context.Background()
+
+	a, err := Open(ctx, "xsql-nop", "a")
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+
+	// Clients that open a handle of the same DSN while it is open share it.
+	b, err := Open(ctx, "xsql-nop", "a")
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	if a != b {
+		t.Errorf("Open(...): want the handle that is already open for the DSN")
+	}
+
+	// Handles of other DSNs, or other connection limits, are not shared.
+	c, err := Open(ctx, "xsql-nop", "b")
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	db := WithConnectionLimits(nil, ConnectionLimits{Pool: "pc", MaxOpen: 2}).(*limitedDB)
+	d, err := Open(db.with(ctx), "xsql-nop", "a")
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	if d == a {
+		t.Errorf("Open(...): want a handle of its own for other connection limits")
+	}
+	if diff := cmp.Diff(3, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles(): -want, +got:\n%s", diff)
+	}
+
+	// A shared handle stays open until every client that opened it closes it.
+	if err := Close(a); err != nil {
+		t.Fatalf("Close(...): %v", err)
+	}
+	if diff := cmp.Diff(3, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles() after closing a shared handle once: -want, +got:\n%s", diff)
+	}
+	for _, h := range []*sql.DB{b, c, d} {
+		if err := Close(h); err != nil {
+			t.Fatalf("Close(...): %v", err)
+		}
+	}
+	if diff := cmp.Diff(0, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles() after closing every handle: -want, +got:\n%s", diff)
+	}
+}
+
+func TestHandles(t *testing.T) {
+	a, err := Open(context.Background(), "xsql-nop", "a")
+	if err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	if _, err := Open(context.Background(), "xsql-nop", "b"); err != nil {
+		t.Fatalf("Open(...): %v", err)
+	}
+	if diff := cmp.Diff(2, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles(): -want, +got:\n%s", diff)
+	}
+
+	if err := Close(a); err != nil {
+		t.Fatalf("Close(...): %v", err)
+	}
+	if diff := cmp.Diff(1, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles() after Close: -want, +got:\n%s", diff)
+	}
+
+	if err := Drain(context.Background()); err != nil {
+		t.Fatalf("Drain(...): %v", err)
+	}
+	if diff := cmp.Diff(0, OpenHandles()); diff != "" {
+		t.Errorf("OpenHandles() after Drain: -want, +got:\n%s", diff)
+	}
+
+	if _, err := Open(context.Background(), "xsql-nop", "c"); err == nil || err.Error() != errDraining {
+		t.Errorf("Open(...) after Drain: want %q, got %v", errDraining, err)
+	}
+}
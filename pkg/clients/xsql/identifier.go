@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// An IdentifierLimit is the longest identifier a database accepts.
+type IdentifierLimit struct {
+	// Engine is the name of the database, used in error messages.
+	Engine string
+
+	// Length is the maximum length of an identifier.
+	Length int
+
+	// Bytes is true if Length is measured in bytes rather than characters.
+	Bytes bool
+}
+
+// ValidateIdentifier returns an error if the supplied name can't be safely
+// quoted as an identifier or is longer than the supplied limit. Catching
+// these names before they are used gives a clearer error than the one the
+// database returns for the statement they end up in, if any; PostgreSQL for
+// example silently truncates long identifiers.
+func ValidateIdentifier(name string, l IdentifierLimit) error {
+	switch {
+	case !utf8.ValidString(name):
+		return fmt.Errorf("name %q is not valid UTF-8", name)
+	case strings.ContainsRune(name, 0):
+		return fmt.Errorf("name %q must not contain NUL characters", name)
+	}
+
+	n, unit := utf8.RuneCountInString(name), "characters"
+	if l.Bytes {
+		n, unit = len(name), "bytes"
+	}
+	if n > l.Length {
+		return fmt.Errorf("name %q is %d %s long, but %s identifiers are limited to %d %s", name, n, unit, l.Engine, l.Length, unit)
+	}
+	return nil
+}
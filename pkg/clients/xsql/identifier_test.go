@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	bytes := IdentifierLimit{Engine: "PostgreSQL", Length: 63, Bytes: true}
+	chars := IdentifierLimit{Engine: "MySQL", Length: 64}
+
+	cases := map[string]struct {
+		name    string
+		limit   IdentifierLimit
+		wantErr bool
+	}{
+		"Valid": {
+			name:  "example",
+			limit: bytes,
+		},
+		"Quotable": {
+			name:  `it's a "name"`,
+			limit: bytes,
+		},
+		"NUL": {
+			name:    "exam\x00ple",
+			limit:   bytes,
+			wantErr: true,
+		},
+		"InvalidUTF8": {
+			name:    "exam\xffple",
+			limit:   bytes,
+			wantErr: true,
+		},
+		"AtLimit": {
+			name:  strings.Repeat("a", 63),
+			limit: bytes,
+		},
+		"TooManyBytes": {
+			name:    strings.Repeat("a", 64),
+			limit:   bytes,
+			wantErr: true,
+		},
+		"MultibyteTooManyBytes": {
+			name:    strings.Repeat("é", 32),
+			limit:   bytes,
+			wantErr: true,
+		},
+		"MultibyteWithinCharacters": {
+			name:  strings.Repeat("é", 64),
+			limit: chars,
+		},
+		"TooManyCharacters": {
+			name:    strings.Repeat("é", 65),
+			limit:   chars,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateIdentifier(tc.name, tc.limit)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateIdentifier(%q): want error %t, got %v", tc.name, tc.wantErr, err)
+			}
+		})
+	}
+}
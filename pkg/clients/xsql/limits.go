@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
+
+// ConnectionLimits bound the connections opened by the DBs of a pool, e.g.
+// those of the managed resources of a ProviderConfig.
+type ConnectionLimits struct {
+	// Pool identifies the DBs that share MaxOpen.
+	Pool string
+
+	// MaxOpen is the number of connections the DBs of the pool may have open
+	// at once. Opening another waits until one of them is closed. Zero does
+	// not bound them.
+	MaxOpen int
+
+	// MaxIdle is the number of idle connections each database handle keeps
+	// open. Nil keeps the database/sql default, or none if MaxOpen is set,
+	// so that idle connections held by one reconcile do not hold up others.
+	MaxIdle *int
+}
+
+// NewConnectionLimits returns the ConnectionLimits of the pool of the supplied
+// name, as configured by the maxOpenConnections and maxIdleConnections of a
+// ProviderConfig.
+func NewConnectionLimits(pool string, maxOpen, maxIdle *int32) ConnectionLimits {
+	l := ConnectionLimits{Pool: pool}
+	if maxOpen != nil {
+		l.MaxOpen = int(*maxOpen)
+	}
+	if maxIdle != nil {
+		n := int(*maxIdle)
+		l.MaxIdle = &n
+	}
+	return l
+}
+
+// maxIdle returns the number of idle connections each database handle keeps
+// open.
+func (l ConnectionLimits) maxIdle() int {
+	switch {
+	case l.MaxIdle != nil:
+		return *l.MaxIdle
+	case l.MaxOpen > 0:
+		return 0
+	default:
+		// The default of database/sql.
+		return 2
+	}
+}
+
+type limitsKey struct{}
+
+// WithConnectionLimits returns a DB whose statements open connections within
+// the supplied limits. The DB is returned as is if they set no limit.
+func WithConnectionLimits(db DB, l ConnectionLimits) DB {
+	if l.MaxOpen <= 0 && l.MaxIdle == nil {
+		return db
+	}
+	return &limitedDB{DB: db, limits: l, dialect: DialectOf(db)}
+}
+
+type limitedDB struct {
+	DB
+	limits  ConnectionLimits
+	dialect Dialect
+}
+
+func (l *limitedDB) Dialect() Dialect {
+	return l.dialect
+}
+
+// with returns a context in which database handles are opened within the
+// limits of the DB.
+func (l *limitedDB) with(ctx context.Context) context.Context {
+	return context.WithValue(ctx, limitsKey{}, l.limits)
+}
+
+func (l *limitedDB) Exec(ctx context.Context, q Query) error {
+	return l.DB.Exec(l.with(ctx), q)
+}
+
+func (l *limitedDB) ExecTx(ctx context.Context, ql []Query) error {
+	return l.DB.ExecTx(l.with(ctx), ql)
+}
+
+func (l *limitedDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	return l.DB.Scan(l.with(ctx), q, dest...)
+}
+
+func (l *limitedDB) Query(ctx context.Context, q Query) (*sql.Rows, error) {
+	return l.DB.Query(l.with(ctx), q)
+}
+
+// Validate validates the supplied statements if the limited DB is a
+// Validator, so that limiting a DB does not disable WithValidation.
+func (l *limitedDB) Validate(ctx context.Context, ql []Query) error {
+	v, ok := l.DB.(Validator)
+	if !ok {
+		return nil
+	}
+	return v.Validate(l.with(ctx), ql)
+}
+
+// limitsFrom returns the connection limits of the supplied context, if any.
+func limitsFrom(ctx context.Context) (ConnectionLimits, bool) {
+	l, ok := ctx.Value(limitsKey{}).(ConnectionLimits)
+	return l, ok
+}
+
+// pools are the connection slots of each pool, which are replaced when its
+// MaxOpen changes.
+var pools = struct {
+	sync.Mutex
+	slots map[string]chan struct{}
+}{slots: map[string]chan struct{}{}}
+
+// slotsOf returns the connection slots of the pool of the supplied limits.
+func slotsOf(l ConnectionLimits) chan struct{} {
+	pools.Lock()
+	defer pools.Unlock()
+	s, ok := pools.slots[l.Pool]
+	if !ok || cap(s) != l.MaxOpen {
+		s = make(chan struct{}, l.MaxOpen)
+		pools.slots[l.Pool] = s
+	}
+	return s
+}
+
+// connectorOf returns a connector of the supplied registered driver.
+func connectorOf(driverName, dsn string) (driver.Connector, error) {
+	// Opening a handle only looks up the driver; it does not connect.
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	_ = db.Close()
+	if dc, ok := d.(driver.DriverContext); ok {
+		return dc.OpenConnector(dsn)
+	}
+	return dsnConnector{dsn: dsn, driver: d}, nil
+}
+
+// A dsnConnector connects using a driver that does not implement
+// driver.DriverContext, like database/sql does.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// A limitedConnector takes a slot of its pool for every connection it opens,
+// and frees it when the connection is closed.
+type limitedConnector struct {
+	driver.Connector
+	slots chan struct{}
+}
+
+func (c *limitedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return &limitedConn{Conn: conn, slots: c.slots}, nil
+}
+
+// A limitedConn frees its slot when it is closed. It implements the optional
+// interfaces of database/sql/driver by deferring to the connection of the
+// driver, or doing what database/sql does if that does not implement them.
+type limitedConn struct {
+	driver.Conn
+	slots chan struct{}
+	once  sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { <-c.slots })
+	return err
+}
+
+func (c *limitedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // The driver does not implement BeginTx.
+}
+
+func (c *limitedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *limitedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *limitedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *limitedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *limitedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *limitedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *limitedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("fake") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("fake") }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+// A rowsConn returns two rows for every query, and executes any statement.
+type rowsConn struct{ fakeConn }
+
+func (rowsConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{n: 2}, nil
+}
+
+func (rowsConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type fakeRows struct{ n int }
+
+func (*fakeRows) Columns() []string { return []string{"id"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	dest[0] = int64(r.n)
+	r.n--
+	return nil
+}
+
+type rowsDriver struct{}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+func init() {
+	sql.Register("xsql-fake", fakeDriver{})
+	sql.Register("xsql-rows", rowsDriver{})
+}
+
+func TestConnectionLimits(t *testing.T) {
+	l := ConnectionLimits{Pool: "test", MaxOpen: 1}
+	ctx := context.WithValue(context.Background(), limitsKey{}, l)
+
+	// Handles of the same pool share its connections.
+	a, err := open(ctx, "xsql-fake", "a")
+	if err != nil {
+		t.Fatalf("open(...): %v", err)
+	}
+	defer a.Close() //nolint:errcheck
+	b, err := open(ctx, "xsql-fake", "b")
+	if err != nil {
+		t.Fatalf("open(...): %v", err)
+	}
+	defer b.Close() //nolint:errcheck
+
+	conn, err := a.Conn(ctx)
+	if err != nil {
+		t.Fatalf("a.Conn(...): %v", err)
+	}
+
+	wait, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := b.Conn(wait); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("b.Conn(...) with no free connection: want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// No idle connections are kept, so closing the connection frees its slot.
+	if err := conn.Close(); err != nil {
+		t.Fatalf("conn.Close(): %v", err)
+	}
+	if diff := cmp.Diff(0, a.Stats().Idle); diff != "" {
+		t.Errorf("a.Stats().Idle: -want, +got:\n%s", diff)
+	}
+	conn, err = b.Conn(ctx)
+	if err != nil {
+		t.Fatalf("b.Conn(...) after the connection was closed: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("conn.Close(): %v", err)
+	}
+}
+
+func TestNestedStatements(t *testing.T) {
+	l := ConnectionLimits{Pool: "nested", MaxOpen: 1}
+	ctx := context.WithValue(context.Background(), limitsKey{}, l)
+
+	db, err := open(ctx, "xsql-rows", "nested")
+	if err != nil {
+		t.Fatalf("open(...): %v", err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	rows, err := db.QueryContext(ctx, "SELECT session_id FROM sys.dm_exec_sessions")
+	if err != nil {
+		t.Fatalf("db.QueryContext(...): %v", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	// The rows hold the only connection of the pool until they are closed,
+	// so a statement executed while they are read never gets one.
+	wait, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := db.ExecContext(wait, "KILL 1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("db.ExecContext(...) while rows are open: want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("rows.Scan(...): %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err(): %v", err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("rows.Close(): %v", err)
+	}
+
+	// Once the rows are read and closed, statements get their connection.
+	for _, id := range ids {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("KILL %d", id)); err != nil {
+			t.Errorf("db.ExecContext(...) after rows are closed: %v", err)
+		}
+	}
+	if diff := cmp.Diff([]int64{2, 1}, ids); diff != "" {
+		t.Errorf("rows: -want, +got:\n%s", diff)
+	}
+}
+
+func TestNewConnectionLimits(t *testing.T) {
+	maxOpen, maxIdle := int32(10), int32(1)
+	idle := 1
+
+	cases := map[string]struct {
+		maxOpen *int32
+		maxIdle *int32
+		want    ConnectionLimits
+	}{
+		"Unset": {
+			want: ConnectionLimits{Pool: "pc"},
+		},
+		"Set": {
+			maxOpen: &maxOpen,
+			maxIdle: &maxIdle,
+			want:    ConnectionLimits{Pool: "pc", MaxOpen: 10, MaxIdle: &idle},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewConnectionLimits("pc", tc.maxOpen, tc.maxIdle)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("NewConnectionLimits(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// LogStatements enables debug logging of every statement executed by a DB
+// returned by WithLogging.
+var LogStatements = false
+
+const redacted = "<redacted>"
+
+// A Dialect describes how a database quotes string literals, so that they
+// can be found and redacted. The zero value describes standard SQL, where
+// literals are single quoted and a quote is escaped by doubling it.
+type Dialect struct {
+	// BackslashEscapes is true if a backslash escapes the next character
+	// of any string literal, as in MySQL.
+	BackslashEscapes bool
+
+	// EscapeStringPrefix is true if a backslash escapes the next character
+	// of string literals prefixed with E, as in PostgreSQL.
+	EscapeStringPrefix bool
+
+	// DoubleQuotedStrings is true if text in double quotes may be a string
+	// literal, as in MySQL, or hold a secret, as do Oracle passwords.
+	DoubleQuotedStrings bool
+
+	// DollarQuotedStrings is true if text between $$ or $tag$ is a string
+	// literal, as in PostgreSQL.
+	DollarQuotedStrings bool
+
+	// BracketIdentifiers is true if identifiers may be quoted in square
+	// brackets, as in MSSQL.
+	BracketIdentifiers bool
+}
+
+// A Dialecter is a DB that knows its Dialect. DBs that don't are assumed to
+// use standard SQL.
+type Dialecter interface {
+	Dialect() Dialect
+}
+
+// DialectOf returns the Dialect of the supplied DB.
+func DialectOf(db DB) Dialect {
+	if d, ok := db.(Dialecter); ok {
+		return d.Dialect()
+	}
+	return Dialect{}
+}
+
+// Redact replaces all string literals in the supplied statement, which may
+// contain passwords or other secrets, with a placeholder. An unterminated
+// literal is redacted up to the end of the statement.
+func Redact(statement string, d Dialect) string {
+	var b strings.Builder
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		switch {
+		case c == '\'' || (c == '"' && d.DoubleQuotedStrings):
+			escapes := d.BackslashEscapes || (d.EscapeStringPrefix && hasEscapePrefix(statement[:i]))
+			b.WriteByte(c)
+			b.WriteString(redacted)
+			b.WriteByte(c)
+			i = quotedEnd(statement, i, c, escapes)
+		case c == '"' || c == '`' || (c == '[' && d.BracketIdentifiers):
+			// A quoted identifier may contain a single quote.
+			end := quotedEnd(statement, i, closingQuote(c), false)
+			b.WriteString(statement[i:end])
+			i = end
+		case c == '$' && d.DollarQuotedStrings:
+			tag, ok := dollarTag(statement[i:])
+			if !ok {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			b.WriteString(tag + redacted + tag)
+			end := strings.Index(statement[i+len(tag):], tag)
+			if end < 0 {
+				return b.String()
+			}
+			i += len(tag) + end + len(tag)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func closingQuote(c byte) byte {
+	if c == '[' {
+		return ']'
+	}
+	return c
+}
+
+// quotedEnd returns the index just past the quote that closes the quoted
+// text starting at start, or the length of the statement if it is not
+// closed. Doubled quotes, and backslash escaped characters if escapes is
+// true, don't close it.
+func quotedEnd(statement string, start int, quote byte, escapes bool) int {
+	for i := start + 1; i < len(statement); i++ {
+		switch statement[i] {
+		case '\\':
+			if escapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(statement) && statement[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(statement)
+}
+
+// hasEscapePrefix returns true if the supplied text, which precedes a
+// quote, ends in the E prefix of an escape string literal.
+func hasEscapePrefix(before string) bool {
+	n := len(before)
+	if n == 0 || (before[n-1] != 'E' && before[n-1] != 'e') {
+		return false
+	}
+	return n == 1 || !isIdentifierByte(before[n-2])
+}
+
+// dollarTag returns the $$ or $tag$ that the supplied text starts with, if
+// any.
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1], true
+		case !isIdentifierByte(s[i]) || (i == 1 && s[i] >= '0' && s[i] <= '9'):
+			// $1 is a parameter placeholder, not a tag.
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// WithLogging returns a DB that logs every executed statement, with string
+// literals redacted and parameters summarized by type, at debug level. The
+// log lines are tagged with the kind, name and ProviderConfig of the supplied
+// managed resource. The DB is returned as is unless LogStatements is set.
+func WithLogging(db DB, log logging.Logger, kind string, mg resource.Managed) DB {
+	if !LogStatements || log == nil {
+		return db
+	}
+	pc := ""
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		pc = ref.Name
+	}
+	return &loggingDB{
+		DB:      db,
+		log:     log.WithValues("kind", kind, "name", mg.GetName(), "providerConfig", pc),
+		dialect: DialectOf(db),
+	}
+}
+
+type loggingDB struct {
+	DB
+	log     logging.Logger
+	dialect Dialect
+}
+
+func (l *loggingDB) Dialect() Dialect {
+	return l.dialect
+}
+
+func (l *loggingDB) logQuery(q Query) {
+	params := make([]string, len(q.Parameters))
+	for i, p := range q.Parameters {
+		params[i] = fmt.Sprintf("%T", p)
+	}
+	l.log.Debug("Executing SQL statement", "statement", Redact(q.String, l.dialect), "parameters", params)
+}
+
+func (l *loggingDB) Exec(ctx context.Context, q Query) error {
+	l.logQuery(q)
+	return l.DB.Exec(ctx, q)
+}
+
+func (l *loggingDB) ExecTx(ctx context.Context, ql []Query) error {
+	for _, q := range ql {
+		l.logQuery(q)
+	}
+	return l.DB.ExecTx(ctx, ql)
+}
+
+func (l *loggingDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	l.logQuery(q)
+	return l.DB.Scan(ctx, q, dest...)
+}
+
+func (l *loggingDB) Query(ctx context.Context, q Query) (*sql.Rows, error) {
+	l.logQuery(q)
+	return l.DB.Query(ctx, q)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import "testing"
+
+var (
+	postgres = Dialect{EscapeStringPrefix: true, DollarQuotedStrings: true}
+	mysql    = Dialect{BackslashEscapes: true, DoubleQuotedStrings: true}
+	mssql    = Dialect{BracketIdentifiers: true}
+)
+
+func TestRedact(t *testing.T) {
+	cases := map[string]struct {
+		statement string
+		dialect   Dialect
+		want      string
+	}{
+		"NoLiterals": {
+			statement: `DROP ROLE "example"`,
+			want:      `DROP ROLE "example"`,
+		},
+		"Password": {
+			statement: `CREATE ROLE "example" PASSWORD 'secret' LOGIN`,
+			want:      `CREATE ROLE "example" PASSWORD '<redacted>' LOGIN`,
+		},
+		"DoubledQuote": {
+			statement: `ALTER LOGIN [example] WITH PASSWORD='it''s secret'`,
+			dialect:   mssql,
+			want:      `ALTER LOGIN [example] WITH PASSWORD='<redacted>'`,
+		},
+		"MSSQLTrailingBackslash": {
+			statement: `ALTER LOGIN [example] WITH PASSWORD='p\', CHECK_POLICY=OFF`,
+			dialect:   mssql,
+			want:      `ALTER LOGIN [example] WITH PASSWORD='<redacted>', CHECK_POLICY=OFF`,
+		},
+		"MSSQLBracketIdentifier": {
+			statement: `CREATE LOGIN [o'brien] WITH PASSWORD='secret'`,
+			dialect:   mssql,
+			want:      `CREATE LOGIN [o'brien] WITH PASSWORD='<redacted>'`,
+		},
+		"PostgreSQLTrailingBackslash": {
+			statement: `CREATE ROLE "example" PASSWORD 'p\' VALID UNTIL 'infinity'`,
+			dialect:   postgres,
+			want:      `CREATE ROLE "example" PASSWORD '<redacted>' VALID UNTIL '<redacted>'`,
+		},
+		"PostgreSQLEscapeString": {
+			statement: `ALTER ROLE "example" PASSWORD  E'back\\slash\'s'`,
+			dialect:   postgres,
+			want:      `ALTER ROLE "example" PASSWORD  E'<redacted>'`,
+		},
+		"PostgreSQLDollarQuoted": {
+			statement: `ALTER ROLE "example" PASSWORD $$it's secret$$ LOGIN`,
+			dialect:   postgres,
+			want:      `ALTER ROLE "example" PASSWORD $$<redacted>$$ LOGIN`,
+		},
+		"PostgreSQLTaggedDollarQuoted": {
+			statement: `ALTER ROLE "example" PASSWORD $pw$a$$b$pw$ LOGIN`,
+			dialect:   postgres,
+			want:      `ALTER ROLE "example" PASSWORD $pw$<redacted>$pw$ LOGIN`,
+		},
+		"PostgreSQLPlaceholder": {
+			statement: `SELECT 1 FROM pg_roles WHERE rolname = $1`,
+			dialect:   postgres,
+			want:      `SELECT 1 FROM pg_roles WHERE rolname = $1`,
+		},
+		"MySQLBackslashEscape": {
+			statement: `ALTER USER 'example'@'%' IDENTIFIED BY 'it\'s secret'`,
+			dialect:   mysql,
+			want:      `ALTER USER '<redacted>'@'<redacted>' IDENTIFIED BY '<redacted>'`,
+		},
+		"MySQLDoubleQuoted": {
+			statement: "CREATE USER `example`@`%` IDENTIFIED BY \"secret\"",
+			dialect:   mysql,
+			want:      "CREATE USER `example`@`%` IDENTIFIED BY \"<redacted>\"",
+		},
+		"Unterminated": {
+			statement: `CREATE ROLE "example" PASSWORD 'secret`,
+			want:      `CREATE ROLE "example" PASSWORD '<redacted>'`,
+		},
+		"UnterminatedDollarQuoted": {
+			statement: `CREATE ROLE "example" PASSWORD $$secret`,
+			dialect:   postgres,
+			want:      `CREATE ROLE "example" PASSWORD $$<redacted>$$`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Redact(tc.statement, tc.dialect); got != tc.want {
+				t.Errorf("Redact(%q): want %q, got %q", tc.statement, tc.want, got)
+			}
+		})
+	}
+}
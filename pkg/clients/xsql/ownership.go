@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ManagedByKey is the key under which the owner of an external object is
+	// recorded in the database catalog.
+	ManagedByKey = "managed-by"
+
+	managedByPrefix = "crossplane/"
+)
+
+// ManagedBy returns the value recorded in the database catalog to identify
+// the managed resource with the supplied name as the owner of an external
+// object. The name, unlike the UID, survives the resource being recreated,
+// e.g. when it is restored from a backup or moved to another cluster.
+func ManagedBy(name string) string {
+	return managedByPrefix + name
+}
+
+// ManagedByComment returns a comment that records the managed resource with
+// the supplied name as the owner of an external object.
+func ManagedByComment(name string) string {
+	return ManagedByKey + ": " + ManagedBy(name)
+}
+
+// CommentWithManagedBy returns the supplied comment followed by a line that
+// records the managed resource with the supplied name as the owner of an
+// external object.
+func CommentWithManagedBy(comment, name string) string {
+	if comment == "" {
+		return ManagedByComment(name)
+	}
+	return comment + "\n" + ManagedByComment(name)
+}
+
+// StripManagedByComment returns the supplied comment without the lines that
+// record the owner of an external object.
+func StripManagedByComment(comment string) string {
+	var lines []string
+	for _, l := range strings.Split(comment, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(l), ManagedByKey+": ") {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseManagedByComment returns the owner recorded by a comment produced by
+// ManagedByComment, or an empty string if the comment records no owner.
+func ParseManagedByComment(comment string) string {
+	for _, l := range strings.Split(comment, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(l), ManagedByKey+": "); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// IsManagedByOther returns true if the supplied owner, as recorded in the
+// database catalog, identifies a managed resource other than the supplied
+// one. Owners recorded by the UID of the supplied resource, as done by
+// earlier versions of the provider, identify it too.
+func IsManagedByOther(owner string, o metav1.Object) bool {
+	if !strings.HasPrefix(owner, managedByPrefix) {
+		return false
+	}
+	return owner != ManagedBy(o.GetName()) && owner != ManagedBy(string(o.GetUID()))
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseManagedByComment(t *testing.T) {
+	cases := map[string]struct {
+		comment string
+		want    string
+	}{
+		"Empty": {
+			comment: "",
+			want:    "",
+		},
+		"NoOwner": {
+			comment: "application role",
+			want:    "",
+		},
+		"RoundTrip": {
+			comment: ManagedByComment("example"),
+			want:    "crossplane/example",
+		},
+		"AmongOtherLines": {
+			comment: "application role\n  managed-by: crossplane/example  \nowned by team a",
+			want:    "crossplane/example",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := ParseManagedByComment(tc.comment); got != tc.want {
+				t.Errorf("ParseManagedByComment(%q): want %q, got %q", tc.comment, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStripManagedByComment(t *testing.T) {
+	cases := map[string]struct {
+		comment string
+		want    string
+	}{
+		"Empty": {
+			comment: "",
+			want:    "",
+		},
+		"OnlyOwner": {
+			comment: ManagedByComment("example"),
+			want:    "",
+		},
+		"RoundTrip": {
+			comment: CommentWithManagedBy("application role", "example"),
+			want:    "application role",
+		},
+		"AmongOtherLines": {
+			comment: "application role\n  managed-by: crossplane/example  \nowned by team a",
+			want:    "application role\nowned by team a",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := StripManagedByComment(tc.comment); got != tc.want {
+				t.Errorf("StripManagedByComment(%q): want %q, got %q", tc.comment, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIsManagedByOther(t *testing.T) {
+	self := &metav1.ObjectMeta{Name: "example", UID: "1234"}
+
+	cases := map[string]struct {
+		owner string
+		want  bool
+	}{
+		"NoOwner": {
+			owner: "",
+			want:  false,
+		},
+		"NotCrossplane": {
+			owner: "terraform",
+			want:  false,
+		},
+		"Self": {
+			owner: ManagedBy("example"),
+			want:  false,
+		},
+		"SelfByUID": {
+			owner: ManagedBy("1234"),
+			want:  false,
+		},
+		"Other": {
+			owner: ManagedBy("other"),
+			want:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsManagedByOther(tc.owner, self); got != tc.want {
+				t.Errorf("IsManagedByOther(%q): want %t, got %t", tc.owner, tc.want, got)
+			}
+		})
+	}
+}
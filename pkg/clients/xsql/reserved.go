@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnnotationKeyManageReserved allows a managed resource to manage an
+// account or role that the provider otherwise refuses to touch, such as the
+// one it connects as or one reserved by the database or cloud vendor.
+const AnnotationKeyManageReserved = "sql.crossplane.io/manage-reserved"
+
+const errReserved = "refusing to manage reserved %s %q; set the " + AnnotationKeyManageReserved + " annotation to override"
+
+// CheckReserved returns an error if the supplied principal is reserved and
+// the object does not set AnnotationKeyManageReserved to true. The kind
+// names the principal, e.g. account or role.
+func CheckReserved(o metav1.Object, kind, principal string, reserved bool) error {
+	if !reserved {
+		return nil
+	}
+	if ok, err := strconv.ParseBool(o.GetAnnotations()[AnnotationKeyManageReserved]); err == nil && ok {
+		return nil
+	}
+	return fmt.Errorf(errReserved, kind, principal)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// TraceStatements enables an OpenTelemetry span for every statement executed
+// by a DB returned by WithTracing.
+var TraceStatements = false
+
+const tracerName = "github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+
+// Attributes set on statement spans to identify the managed resource they
+// were executed for.
+const (
+	AttrKind           = attribute.Key("crossplane.kind")
+	AttrName           = attribute.Key("crossplane.name")
+	AttrProviderConfig = attribute.Key("crossplane.providerconfig")
+)
+
+// Instrument returns a DB that logs and traces every executed statement, and
+// validates it before it is executed, as configured by LogStatements,
+// TraceStatements and ValidateStatements.
+func Instrument(db DB, log logging.Logger, kind string, mg resource.Managed) DB {
+	return WithTracing(WithLogging(WithValidation(db), log, kind, mg), kind, mg)
+}
+
+// WithTracing returns a DB that starts a span for every executed statement.
+// Spans are named after the SQL verb of the statement, carry its redacted
+// text, and are tagged with the kind, name and ProviderConfig of the supplied
+// managed resource. The DB is returned as is unless TraceStatements is set.
+func WithTracing(db DB, kind string, mg resource.Managed) DB {
+	if !TraceStatements {
+		return db
+	}
+	pc := ""
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		pc = ref.Name
+	}
+	return &tracingDB{
+		DB:      db,
+		tracer:  otel.Tracer(tracerName),
+		attrs:   []attribute.KeyValue{AttrKind.String(kind), AttrName.String(mg.GetName()), AttrProviderConfig.String(pc)},
+		dialect: DialectOf(db),
+	}
+}
+
+// Verb returns the upper cased first keyword of the supplied statement.
+func Verb(statement string) string {
+	f := strings.Fields(statement)
+	if len(f) == 0 {
+		return ""
+	}
+	return strings.ToUpper(f[0])
+}
+
+type tracingDB struct {
+	DB
+	tracer  trace.Tracer
+	attrs   []attribute.KeyValue
+	dialect Dialect
+}
+
+func (t *tracingDB) Dialect() Dialect {
+	return t.dialect
+}
+
+func (t *tracingDB) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(attrs...))
+}
+
+func (t *tracingDB) startQuery(ctx context.Context, q Query) (context.Context, trace.Span) {
+	v := Verb(q.String)
+	return t.start(ctx, v, semconv.DBOperation(v), semconv.DBStatement(Redact(q.String, t.dialect)))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && !IsNoRows(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *tracingDB) Exec(ctx context.Context, q Query) error {
+	ctx, span := t.startQuery(ctx, q)
+	err := t.DB.Exec(ctx, q)
+	end(span, err)
+	return err
+}
+
+func (t *tracingDB) ExecTx(ctx context.Context, ql []Query) error {
+	verbs := make([]string, len(ql))
+	for i, q := range ql {
+		verbs[i] = Verb(q.String)
+	}
+	ctx, span := t.start(ctx, "TRANSACTION", attribute.StringSlice("db.operations", verbs))
+	err := t.DB.ExecTx(ctx, ql)
+	end(span, err)
+	return err
+}
+
+func (t *tracingDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	ctx, span := t.startQuery(ctx, q)
+	err := t.DB.Scan(ctx, q, dest...)
+	end(span, err)
+	return err
+}
+
+// Query ends its span once the rows are returned; the time spent iterating
+// over them is not included.
+func (t *tracingDB) Query(ctx context.Context, q Query) (*sql.Rows, error) {
+	ctx, span := t.startQuery(ctx, q)
+	rows, err := t.DB.Query(ctx, q)
+	end(span, err)
+	return rows, err
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestVerb(t *testing.T) {
+	cases := map[string]struct {
+		statement string
+		want      string
+	}{
+		"Empty": {
+			statement: "",
+			want:      "",
+		},
+		"Select": {
+			statement: "select 1",
+			want:      "SELECT",
+		},
+		"LeadingWhitespace": {
+			statement: "\n\tALTER ROLE \"example\" LOGIN",
+			want:      "ALTER",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Verb(tc.statement); got != tc.want {
+				t.Errorf("Verb(%q): want %q, got %q", tc.statement, tc.want, got)
+			}
+		})
+	}
+}
+
+type execOnlyDB struct {
+	DB
+	err error
+}
+
+func (e execOnlyDB) Exec(ctx context.Context, q Query) error {
+	return e.err
+}
+
+func TestWithTracing(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	TraceStatements = true
+	defer func() { TraceStatements = false }()
+
+	type want struct {
+		name   string
+		attrs  []attribute.KeyValue
+		status codes.Code
+	}
+
+	cases := map[string]struct {
+		err  error
+		want want
+	}{
+		"Success": {
+			want: want{
+				name: "CREATE",
+				attrs: []attribute.KeyValue{
+					AttrKind.String("Role"),
+					AttrName.String("example"),
+					AttrProviderConfig.String("default"),
+					attribute.String("db.operation", "CREATE"),
+					attribute.String("db.statement", "CREATE ROLE \"example\" PASSWORD '<redacted>'"),
+				},
+				status: codes.Unset,
+			},
+		},
+		"Error": {
+			err: errBoom,
+			want: want{
+				name: "CREATE",
+				attrs: []attribute.KeyValue{
+					AttrKind.String("Role"),
+					AttrName.String("example"),
+					AttrProviderConfig.String("default"),
+					attribute.String("db.operation", "CREATE"),
+					attribute.String("db.statement", "CREATE ROLE \"example\" PASSWORD '<redacted>'"),
+				},
+				status: codes.Error,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &fake.Managed{
+				ObjectMeta:               metav1.ObjectMeta{Name: "example"},
+				ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}},
+			}
+			db := WithTracing(execOnlyDB{err: tc.err}, "Role", mg)
+
+			err := db.Exec(context.Background(), Query{String: "CREATE ROLE \"example\" PASSWORD 'secret'"})
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Exec(...): -want error, +got error:\n%s", diff)
+			}
+
+			spans := sr.Ended()
+			span := spans[len(spans)-1]
+			got := want{name: span.Name(), attrs: span.Attributes(), status: span.Status().Code}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}, attribute.Value{})); diff != "" {
+				t.Errorf("Exec(...): -want span, +got span:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"fmt"
+)
+
+// ValidateStatements enables validation of every statement executed by a DB
+// returned by WithValidation before it is executed.
+var ValidateStatements = false
+
+const errValidate = "SQL statement failed validation"
+
+// A Validator is a DB that can check the syntax of statements without
+// executing them.
+type Validator interface {
+	// Validate returns an error if any of the supplied statements is not
+	// valid. Statements that cannot be checked are assumed to be valid.
+	Validate(ctx context.Context, ql []Query) error
+}
+
+// WithValidation returns a DB that validates the statements of Exec and
+// ExecTx before executing them, so that a syntax error is returned without
+// executing any of them. The DB is returned as is unless ValidateStatements
+// is set and it is a Validator.
+func WithValidation(db DB) DB {
+	v, ok := db.(Validator)
+	if !ValidateStatements || !ok {
+		return db
+	}
+	return &validatingDB{DB: db, validator: v, dialect: DialectOf(db)}
+}
+
+type validatingDB struct {
+	DB
+	validator Validator
+	dialect   Dialect
+}
+
+func (v *validatingDB) Dialect() Dialect {
+	return v.dialect
+}
+
+func (v *validatingDB) validate(ctx context.Context, ql []Query) error {
+	if err := v.validator.Validate(ctx, ql); err != nil {
+		return fmt.Errorf("%s: %w", errValidate, err)
+	}
+	return nil
+}
+
+func (v *validatingDB) Exec(ctx context.Context, q Query) error {
+	if err := v.validate(ctx, []Query{q}); err != nil {
+		return err
+	}
+	return v.DB.Exec(ctx, q)
+}
+
+func (v *validatingDB) ExecTx(ctx context.Context, ql []Query) error {
+	if err := v.validate(ctx, ql); err != nil {
+		return err
+	}
+	return v.DB.ExecTx(ctx, ql)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xsql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type validatorDB struct {
+	DB
+	invalid  error
+	executed []string
+}
+
+func (v *validatorDB) Validate(ctx context.Context, ql []Query) error {
+	return v.invalid
+}
+
+func (v *validatorDB) ExecTx(ctx context.Context, ql []Query) error {
+	for _, q := range ql {
+		v.executed = append(v.executed, q.String)
+	}
+	return nil
+}
+
+func TestWithValidation(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err      error
+		executed []string
+	}
+
+	cases := map[string]struct {
+		reason   string
+		validate bool
+		invalid  error
+		want     want
+	}{
+		"Disabled": {
+			reason:  "Statements should be executed without validation unless it is enabled",
+			invalid: errBoom,
+			want: want{
+				executed: []string{"CREATE ROLE example", "GRANT example TO other"},
+			},
+		},
+		"Valid": {
+			reason:   "Valid statements should be executed",
+			validate: true,
+			want: want{
+				executed: []string{"CREATE ROLE example", "GRANT example TO other"},
+			},
+		},
+		"Invalid": {
+			reason:   "No statement should be executed if any fails validation",
+			validate: true,
+			invalid:  errBoom,
+			want: want{
+				err: fmt.Errorf("%s: %w", errValidate, errBoom),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ValidateStatements = tc.validate
+			defer func() { ValidateStatements = false }()
+
+			v := &validatorDB{invalid: tc.invalid}
+			err := WithValidation(v).ExecTx(context.Background(), []Query{
+				{String: "CREATE ROLE example"},
+				{String: "GRANT example TO other"},
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nExecTx(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.executed, v.executed); diff != "" {
+				t.Errorf("\n%s\nExecTx(...): -want executed, +got executed:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
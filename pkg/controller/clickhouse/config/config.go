@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/providerconfig"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+)
+
+// Setup adds a controller that reconciles ProviderConfigs by accounting for
+// their current usage. It also indexes ProviderConfigs by their credentials
+// Secret, so that managed resources are reconciled when it changes.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := providerconfig.ControllerName(v1alpha1.ProviderConfigGroupKind)
+
+	if err := secretref.IndexProviderConfigs(mgr, &v1alpha1.ProviderConfig{}, credentials); err != nil {
+		return err
+	}
+
+	of := resource.ProviderConfigKinds{
+		Config:    v1alpha1.ProviderConfigGroupVersionKind,
+		UsageList: v1alpha1.ProviderConfigUsageListGroupVersionKind,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ProviderConfig{}).
+		Watches(&v1alpha1.ProviderConfigUsage{}, &resource.EnqueueRequestForProviderConfig{}).
+		Complete(providerconfig.NewReconciler(mgr, of,
+			providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
+			providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+}
+
+// credentials returns the credentials Secret of the supplied ProviderConfig.
+func credentials(o client.Object) *xpv1.SecretReference {
+	pc, ok := o.(*v1alpha1.ProviderConfig)
+	if !ok {
+		return nil
+	}
+	return pc.Spec.Credentials.ConnectionSecretRef
+}
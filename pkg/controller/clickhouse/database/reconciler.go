@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/clickhouse"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotDatabase = "managed resource is not a Database custom resource"
+	errSelectDB    = "cannot select database"
+	errCreateDB    = "cannot create database"
+	errDropDB      = "cannot drop database"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Database managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Database{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseGroupKind))
+}
+
+// NewConnecter returns a connecter for Database managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: clickhouse.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return nil, errors.New(errNotDatabase)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := clickhouse.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (ClickHouseConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(s.Data, pc.Spec.Secure, pc.Spec.ConnectionOptions), clickhouse.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDatabase)
+	}
+
+	name := meta.GetExternalName(cr)
+	observed := v1alpha1.DatabaseObservation{}
+	query := "SELECT d.engine, " +
+		"toInt64((SELECT sum(bytes_on_disk) FROM system.parts WHERE database = ? AND active)) " +
+		"FROM system.databases d WHERE d.name = ?"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{name, name}},
+		&observed.Engine,
+		&observed.SizeBytes,
+	)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDB)
+	}
+
+	cr.Status.AtProvider = observed
+	cr.SetConditions(xpv1.Available())
+
+	// All parameters are immutable, so an existing database is up to date.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDatabase)
+	}
+
+	p := cr.Spec.ForProvider
+	query := "CREATE DATABASE " + clickhouse.QuoteIdentifier(meta.GetExternalName(cr)) + clickhouse.OnCluster(p.Cluster)
+	if p.Engine != nil {
+		// The engine is an expression, e.g. Replicated('/path', '{shard}',
+		// '{replica}'), so it cannot be quoted.
+		query += " ENGINE = " + *p.Engine
+	}
+	if p.Comment != nil {
+		query += " COMMENT " + clickhouse.QuoteValue(*p.Comment)
+	}
+
+	if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errCreateDB}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	// All parameters are immutable.
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return errors.New(errNotDatabase)
+	}
+
+	query := "DROP DATABASE IF EXISTS " + clickhouse.QuoteIdentifier(meta.GetExternalName(cr)) + clickhouse.OnCluster(cr.Spec.ForProvider.Cluster)
+
+	return clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errDropDB})
+}
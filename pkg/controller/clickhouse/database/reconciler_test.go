@@ -0,0 +1,464 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotDatabase),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetPC),
+		},
+		"ErrMissingConnectionSecret": {
+			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					// We call get to populate the Database struct, then again
+					// to populate the (empty) ProviderConfig struct, resulting
+					// in a ProviderConfig with a nil connection secret.
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.New(errNoSecretRef),
+		},
+		"ErrGetConnectionSecret": {
+			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						case *corev1.Secret:
+							return errBoom
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetSecret),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotDatabase),
+			},
+		},
+		"ErrNoDatabase": {
+			reason: "We should return ResourceExists: false when no database is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectDatabase": {
+			reason: "We should return any errors encountered while trying to select the database",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectDB),
+			},
+		},
+		"Success": {
+			reason: "We should return no error if we can successfully select our database",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: false,
+				},
+				err: nil,
+			},
+		},
+		"SuccessAtProvider": {
+			reason: "The observed state of the database should be reported in its status",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "Atomic"
+						*dest[1].(*int64) = 16384
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{Engine: "Atomic", SizeBytes: 16384},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Database); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotDatabase),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the database should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateDB),
+			},
+		},
+		"SuccessEngine": {
+			reason: "The engine, cluster and comment should be included in the create statement",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE DATABASE `example` ON CLUSTER `default` ENGINE = Replicated('/clickhouse/example', '{shard}', '{replica}') COMMENT 'analytics'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Engine:  ptr.To("Replicated('/clickhouse/example', '{shard}', '{replica}')"),
+							Cluster: ptr.To("default"),
+							Comment: ptr.To("analytics"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a database",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotDatabase),
+		},
+		"ErrDropDB": {
+			reason: "Errors dropping a database should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: errors.Wrap(errBoom, errDropDB),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/clickhouse"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotGrant       = "managed resource is not a Grant custom resource"
+	errCreateGrant    = "cannot create grant"
+	errRevokeGrant    = "cannot revoke grant"
+	errCurrentGrant   = "cannot select current grants"
+	errRevokeGrantOpt = "cannot revoke grant option"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Grant managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.GrantGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: clickhouse.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return nil, errors.New(errNotGrant)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := clickhouse.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (ClickHouseConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// Refuse to change the privileges of the user the provider connects as,
+	// or one reserved by ClickHouse.
+	if u := cr.Spec.ForProvider.User; u != nil {
+		reserved := clickhouse.IsReservedUser(*u, string(s.Data[xpv1.ResourceCredentialsSecretUserKey]))
+		if err := xsql.CheckReserved(cr, "user", *u, reserved); err != nil {
+			return nil, err
+		}
+	}
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newDB(s.Data, pc.Spec.Secure, pc.Spec.ConnectionOptions), clickhouse.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	privileges, grantOption, err := c.getPrivileges(ctx, *p.User, defaultName(p.Database), defaultName(p.Table))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errCurrentGrant)
+	}
+
+	// ClickHouse drops the grants of a user along with the user, so no rows
+	// means the grant does not exist.
+	if len(privileges) == 0 {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider.Privileges = privileges
+	cr.Status.AtProvider.WithGrantOption = grantOption
+
+	toGrant, toRevoke := diffPermissions(p.Privileges.ToStringSlice(), privileges)
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0 && grantOption == ptrBool(p.WithGrantOption),
+	}, nil
+}
+
+// getPrivileges returns the sorted privileges the supplied user was granted
+// on the supplied database and table, and whether they were all granted with
+// grant option.
+func (c *external) getPrivileges(ctx context.Context, user, dbname, table string) ([]string, bool, error) {
+	query := "SELECT toString(access_type), grant_option FROM system.grants " +
+		"WHERE user_name = ? AND ifNull(database, '*') = ? AND ifNull(table, '*') = ? " +
+		"AND column IS NULL AND is_partial_revoke = 0"
+	rows, err := c.db.Query(ctx, xsql.Query{String: query, Parameters: []interface{}{user, dbname, table}})
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var privileges []string
+	grantOption := true
+	for rows.Next() {
+		var p string
+		var g bool
+		if err := rows.Scan(&p, &g); err != nil {
+			return nil, false, err
+		}
+		privileges = append(privileges, p)
+		grantOption = grantOption && g
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	sort.Strings(privileges)
+	return privileges, grantOption && len(privileges) > 0, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	query := createGrantQuery(p.Privileges.ToStringSlice(), on(p), *p.User, ptrBool(p.WithGrantOption))
+	if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	desired := p.Privileges.ToStringSlice()
+	grantOption := ptrBool(p.WithGrantOption)
+	toGrant, toRevoke := diffPermissions(desired, cr.Status.AtProvider.Privileges)
+
+	if len(toRevoke) > 0 {
+		query := createRevokeQuery(toRevoke, on(p), *p.User, false)
+		if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errRevokeGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	switch {
+	case grantOption && !cr.Status.AtProvider.WithGrantOption:
+		// Granting all privileges again adds the grant option to the
+		// ones that were already granted.
+		toGrant = desired
+	case !grantOption && cr.Status.AtProvider.WithGrantOption:
+		query := createRevokeQuery(desired, on(p), *p.User, true)
+		if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errRevokeGrantOpt}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if len(toGrant) > 0 {
+		query := createGrantQuery(toGrant, on(p), *p.User, grantOption)
+		if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	query := createRevokeQuery(p.Privileges.ToStringSlice(), on(p), *p.User, false)
+	return clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errRevokeGrant})
+}
+
+func ptrBool(b *bool) bool {
+	return b != nil && *b
+}
+
+// defaultName returns the supplied database or table name, or * if unset.
+func defaultName(name *string) string {
+	if name == nil {
+		return "*"
+	}
+	return *name
+}
+
+func defaultIdentifier(name *string) string {
+	if name == nil || *name == "*" {
+		return "*"
+	}
+	return clickhouse.QuoteIdentifier(*name)
+}
+
+// on returns the database and table a grant applies to, e.g. `db`.*.
+func on(p v1alpha1.GrantParameters) string {
+	return defaultIdentifier(p.Database) + "." + defaultIdentifier(p.Table)
+}
+
+func createGrantQuery(privileges []string, on, user string, grantOption bool) string {
+	sort.Strings(privileges)
+	result := fmt.Sprintf("GRANT %s ON %s TO %s",
+		strings.Join(privileges, ", "),
+		on,
+		clickhouse.QuoteIdentifier(user),
+	)
+	if grantOption {
+		result += " WITH GRANT OPTION"
+	}
+	return result
+}
+
+func createRevokeQuery(privileges []string, on, user string, grantOption bool) string {
+	sort.Strings(privileges)
+	result := "REVOKE "
+	if grantOption {
+		result += "GRANT OPTION FOR "
+	}
+	return result + fmt.Sprintf("%s ON %s FROM %s",
+		strings.Join(privileges, ", "),
+		on,
+		clickhouse.QuoteIdentifier(user),
+	)
+}
+
+func diffPermissions(desired, observed []string) ([]string, []string) {
+	return subtract(desired, observed), subtract(observed, desired)
+}
+
+// subtract returns the elements of a that are not in b.
+func subtract(a, b []string) []string {
+	bMap := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		bMap[v] = struct{}{}
+	}
+
+	var out []string
+	for _, v := range a {
+		if _, ok := bMap[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
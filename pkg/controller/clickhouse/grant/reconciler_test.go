@@ -0,0 +1,429 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func grant(p v1alpha1.GrantParameters, o v1alpha1.GrantObservation) *v1alpha1.Grant {
+	p.User = ptr.To("example")
+	p.Database = ptr.To("analytics")
+	return &v1alpha1.Grant{
+		Spec:   v1alpha1.GrantSpec{ForProvider: p},
+		Status: v1alpha1.GrantStatus{AtProvider: o},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.GrantObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while trying to select the grants",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{}, v1alpha1.GrantObservation{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCurrentGrant),
+			},
+		},
+		"NoGrant": {
+			reason: "We should return ResourceExists: false when no privileges are granted",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"access_type", "grant_option"})), nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{}, v1alpha1.GrantObservation{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"Success": {
+			reason: "We should report the grant as up to date if the privileges match",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if diff := cmp.Diff([]interface{}{"example", "analytics", "*"}, q.Parameters); diff != "" {
+							return nil, errors.Errorf("unexpected parameters: %s", diff)
+						}
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"access_type", "grant_option"}).
+							AddRow("SELECT", true).
+							AddRow("INSERT", true)), nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"SELECT", "INSERT"},
+					WithGrantOption: ptr.To(true),
+				}, v1alpha1.GrantObservation{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.GrantObservation{
+					Privileges:      []string{"INSERT", "SELECT"},
+					WithGrantOption: true,
+				},
+			},
+		},
+		"GrantOptionChanged": {
+			reason: "We should report the grant as not up to date if the grant option differs",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"access_type", "grant_option"}).
+							AddRow("SELECT", false)), nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+					WithGrantOption: ptr.To(true),
+				}, v1alpha1.GrantObservation{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.GrantObservation{
+					Privileges: []string{"SELECT"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Grant); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"SELECT"}}, v1alpha1.GrantObservation{}),
+			},
+			want: errors.Wrap(errBoom, errCreateGrant),
+		},
+		"Success": {
+			reason: "The privileges should be granted on the database to the user",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "GRANT INSERT, SELECT ON `analytics`.* TO `example` WITH GRANT OPTION" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"SELECT", "INSERT"},
+					WithGrantOption: ptr.To(true),
+				}, v1alpha1.GrantObservation{}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrRevoke": {
+			reason: "Any errors encountered while revoking privileges should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+					v1alpha1.GrantObservation{Privileges: []string{"INSERT", "SELECT"}}),
+			},
+			want: errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"SuccessChangePrivileges": {
+			reason: "Removed privileges should be revoked and added ones granted",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "REVOKE INSERT ON `analytics`.* FROM `example`",
+							"GRANT ALTER ON `analytics`.* TO `example`":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"SELECT", "ALTER"}},
+					v1alpha1.GrantObservation{Privileges: []string{"INSERT", "SELECT"}}),
+			},
+			want: nil,
+		},
+		"SuccessRevokeGrantOption": {
+			reason: "The grant option should be revoked when it is no longer desired",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "REVOKE GRANT OPTION FOR SELECT ON `analytics`.* FROM `example`" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+					v1alpha1.GrantObservation{Privileges: []string{"SELECT"}, WithGrantOption: true}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrRevoke": {
+			reason: "Errors revoking the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"SELECT"}}, v1alpha1.GrantObservation{}),
+			},
+			want: errors.Wrap(errBoom, errRevokeGrant),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/clickhouse"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotUser                 = "managed resource is not a User custom resource"
+	errSelectUser              = "cannot select user"
+	errCreateUser              = "cannot create user"
+	errDropUser                = "cannot drop user"
+	errUpdateUser              = "cannot update user"
+	errSetQuota                = "cannot set quota"
+	errDropQuota               = "cannot drop quota"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	defaultQuotaInterval = "1 hour"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles User managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.UserGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.User{}, &v1alpha1.UserList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.User)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.User{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.UserGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.UserGroupKind))
+}
+
+// NewConnecter returns a connecter for User managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: clickhouse.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return nil, errors.New(errNotUser)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := clickhouse.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (ClickHouseConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// Refuse to manage the user the provider connects as, or one reserved by
+	// ClickHouse, so that a bad composition cannot lock the provider out.
+	reserved := clickhouse.IsReservedUser(meta.GetExternalName(cr), string(s.Data[xpv1.ResourceCredentialsSecretUserKey]))
+	if err := xsql.CheckReserved(cr, "user", meta.GetExternalName(cr), reserved); err != nil {
+		return nil, err
+	}
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newDB(s.Data, pc.Spec.Secure, pc.Spec.ConnectionOptions), clickhouse.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+// quotaToClauses returns the interval and the limits of the supplied quota
+// as clauses, in a stable order so that they can be compared.
+func quotaToClauses(q *v1alpha1.Quota) []string {
+	if q == nil {
+		return nil
+	}
+	interval := q.Interval
+	if interval == "" {
+		interval = defaultQuotaInterval
+	}
+	out := []string{"INTERVAL " + interval}
+	for _, l := range []struct {
+		name  string
+		value *int64
+	}{
+		{"queries", q.MaxQueries},
+		{"errors", q.MaxErrors},
+		{"result_rows", q.MaxResultRows},
+		{"read_rows", q.MaxReadRows},
+		{"execution_time", q.MaxExecutionTime},
+	} {
+		if l.value != nil {
+			out = append(out, fmt.Sprintf("%s = %d", l.name, *l.value))
+		}
+	}
+	return out
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotUser)
+	}
+
+	name := meta.GetExternalName(cr)
+
+	var defaultDatabase, profile string
+	query := "SELECT u.default_database, " +
+		"ifNull((SELECT any(inherit_profile) FROM system.settings_profile_elements WHERE user_name = ? AND isNotNull(inherit_profile)), '') " +
+		"FROM system.users u WHERE u.name = ?"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{name, name}}, &defaultDatabase, &profile)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
+	}
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	p := cr.Spec.ForProvider
+	upToDate := !pwdChanged &&
+		(p.DefaultDatabase == nil || *p.DefaultDatabase == defaultDatabase) &&
+		(p.SettingsProfile == nil || *p.SettingsProfile == profile) &&
+		slices.Equal(quotaToClauses(p.Quota), cr.Status.AtProvider.QuotaAsClauses)
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+// userClauses returns the DEFAULT DATABASE and SETTINGS PROFILE clauses of
+// CREATE USER and ALTER USER for the supplied parameters.
+func userClauses(p v1alpha1.UserParameters) string {
+	out := ""
+	if p.DefaultDatabase != nil {
+		out += " DEFAULT DATABASE " + clickhouse.QuoteIdentifier(*p.DefaultDatabase)
+	}
+	if p.SettingsProfile != nil {
+		out += " SETTINGS PROFILE " + clickhouse.QuoteValue(*p.SettingsProfile)
+	}
+	return out
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	name := meta.GetExternalName(cr)
+
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if pw == "" {
+		pw, err = password.Generate()
+		if err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	query := fmt.Sprintf("CREATE USER %s IDENTIFIED WITH sha256_password BY %s%s",
+		clickhouse.QuoteIdentifier(name),
+		clickhouse.QuoteValue(pw),
+		userClauses(cr.Spec.ForProvider),
+	)
+	if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errCreateUser}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	if err := c.setQuota(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{
+		ConnectionDetails: c.db.GetConnectionDetails(name, pw),
+	}, nil
+}
+
+// setQuota creates, replaces or drops the quota named after the user so
+// that it matches the desired one, and records the applied limits.
+func (c *external) setQuota(ctx context.Context, cr *v1alpha1.User) error {
+	name := clickhouse.QuoteIdentifier(meta.GetExternalName(cr))
+	desired := quotaToClauses(cr.Spec.ForProvider.Quota)
+	if slices.Equal(desired, cr.Status.AtProvider.QuotaAsClauses) {
+		return nil
+	}
+
+	if desired == nil {
+		query := "DROP QUOTA IF EXISTS " + name
+		if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errDropQuota}); err != nil {
+			return err
+		}
+		cr.Status.AtProvider.QuotaAsClauses = nil
+		return nil
+	}
+
+	limits := "NO LIMITS"
+	if len(desired) > 1 {
+		limits = "MAX " + strings.Join(desired[1:], ", ")
+	}
+	query := fmt.Sprintf("CREATE QUOTA OR REPLACE %s FOR %s %s TO %s", name, desired[0], limits, name)
+	if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errSetQuota}); err != nil {
+		return err
+	}
+	cr.Status.AtProvider.QuotaAsClauses = desired
+	return nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotUser)
+	}
+
+	name := meta.GetExternalName(cr)
+
+	pw, pwchanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	clauses := userClauses(cr.Spec.ForProvider)
+	if pwchanged {
+		clauses = " IDENTIFIED WITH sha256_password BY " + clickhouse.QuoteValue(pw) + clauses
+	}
+	if clauses != "" {
+		query := "ALTER USER " + clickhouse.QuoteIdentifier(name) + clauses
+		if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if err := c.setQuota(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if pwchanged {
+		return managed.ExternalUpdate{ConnectionDetails: c.db.GetConnectionDetails(name, pw)}, nil
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	name := clickhouse.QuoteIdentifier(meta.GetExternalName(cr))
+	if err := clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: "DROP QUOTA IF EXISTS " + name, ErrorValue: errDropQuota}); err != nil {
+		return err
+	}
+	return clickhouse.ExecWrapper(ctx, c.db, clickhouse.ExecQuery{Query: "DROP USER IF EXISTS " + name, ErrorValue: errDropUser})
+}
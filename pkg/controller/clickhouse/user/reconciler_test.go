@@ -0,0 +1,653 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/crossplane-contrib/provider-sql/apis/clickhouse/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, secure *bool, opts map[string]string) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	withSecret := func(user string) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				switch o := obj.(type) {
+				case *v1alpha1.ProviderConfig:
+					o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+				case *corev1.Secret:
+					o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretUserKey: []byte(user)}
+				}
+				return nil
+			}),
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotUser),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetPC),
+		},
+		"ErrReservedUser": {
+			reason: "An error should be returned if the user is reserved by ClickHouse",
+			fields: fields{
+				kube:  withSecret("admin"),
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "default"},
+					},
+					Spec: v1alpha1.UserSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "default", true),
+		},
+		"ErrConnectionUser": {
+			reason: "An error should be returned if the user is the one the provider connects as",
+			fields: fields{
+				kube:  withSecret("admin"),
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "admin"},
+					},
+					Spec: v1alpha1.UserSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "admin", true),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotUser),
+			},
+		},
+		"ErrNoUser": {
+			reason: "We should return ResourceExists: false when no user is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectUser": {
+			reason: "We should return any errors encountered while trying to select the user",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectUser),
+			},
+		},
+		"Success": {
+			reason: "We should return no error if we can successfully select our user",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "analytics"
+						*dest[1].(*string) = "readonly"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultDatabase: ptr.To("analytics"),
+							SettingsProfile: ptr.To("readonly"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"SettingsProfileChanged": {
+			reason: "We should report the user as not up to date if its settings profile differs",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							SettingsProfile: ptr.To("readonly"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"QuotaChanged": {
+			reason: "We should report the user as not up to date if its quota differs from the applied one",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							Quota: &v1alpha1.Quota{MaxQueries: ptr.To[int64](100)},
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							QuotaAsClauses: []string{"INTERVAL 1 hour", "queries = 50"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c      managed.ExternalCreation
+		quotas []string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotUser),
+			},
+		},
+		"ErrCreateUser": {
+			reason: "Any errors encountered while creating the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateUser),
+			},
+		},
+		"ErrSetQuota": {
+			reason: "Any errors encountered while setting the quota should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "CREATE QUOTA") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							Quota: &v1alpha1.Quota{},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSetQuota),
+			},
+		},
+		"Success": {
+			reason: "The user should be created with its default database, settings profile and quota",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+						obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("s3cr3t")}
+						return nil
+					},
+				},
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "CREATE USER `example` IDENTIFIED WITH sha256_password BY 's3cr3t' DEFAULT DATABASE `analytics` SETTINGS PROFILE 'readonly'",
+							"CREATE QUOTA OR REPLACE `example` FOR INTERVAL 1 day MAX queries = 100, read_rows = 1000000 TO `example`":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+					MockGetConnectionDetails: func(username, password string) managed.ConnectionDetails {
+						return managed.ConnectionDetails{
+							xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+							xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+						}
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+							DefaultDatabase:   ptr.To("analytics"),
+							SettingsProfile:   ptr.To("readonly"),
+							Quota: &v1alpha1.Quota{
+								Interval:    "1 day",
+								MaxQueries:  ptr.To[int64](100),
+								MaxReadRows: ptr.To[int64](1000000),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				c: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("example"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("s3cr3t"),
+					},
+				},
+				quotas: []string{"INTERVAL 1 day", "queries = 100", "read_rows = 1000000"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.User); ok && tc.want.err == nil {
+				if diff := cmp.Diff(tc.want.quotas, cr.Status.AtProvider.QuotaAsClauses); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want quotaAsClauses, +got quotaAsClauses:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		u   managed.ExternalUpdate
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotUser),
+			},
+		},
+		"ErrUpdateUser": {
+			reason: "Any errors encountered while altering the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultDatabase: ptr.To("analytics"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdateUser),
+			},
+		},
+		"SuccessDropQuota": {
+			reason: "The quota should be dropped when it is removed from the spec",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "DROP QUOTA IF EXISTS `example`" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							QuotaAsClauses: []string{"INTERVAL 1 hour", "queries = 50"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotUser),
+		},
+		"ErrDropQuota": {
+			reason: "Errors dropping the quota should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: errors.Wrap(errBoom, errDropQuota),
+		},
+		"ErrDropUser": {
+			reason: "Errors dropping the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "DROP USER") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: errors.Wrap(errBoom, errDropUser),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deletion backs off from deleting the external resource of managed
+// resources whose deletion keeps failing, and reports them as blocked so
+// that stuck deletions are noticed.
+package deletion
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// TypeDeleteBlocked indicates whether the deletion of the external
+	// resource of a managed resource has failed repeatedly.
+	TypeDeleteBlocked xpv1.ConditionType = "DeleteBlocked"
+
+	// ReasonDeleteFailing is the reason of a DeleteBlocked condition.
+	ReasonDeleteFailing xpv1.ConditionReason = "DeleteFailing"
+
+	reasonDeleteBlocked event.Reason = "DeleteBlocked"
+
+	errDeleteBlocked = "deletion of external resource failed %d times; next attempt in %s"
+	errBlocked       = "deletion of external resource is blocked"
+)
+
+var (
+	// BlockAfter is the number of consecutive failed deletions after which a
+	// managed resource is reported as blocked.
+	BlockAfter = 3
+
+	// BaseDelay is the delay after the first failed deletion. It doubles
+	// with every further failure, up to MaxDelay.
+	BaseDelay = 10 * time.Second
+
+	// MaxDelay is the longest delay between deletion attempts. It is set by
+	// the --max-delete-backoff flag.
+	MaxDelay = 30 * time.Minute
+)
+
+// Blocked returns a condition that indicates the deletion of the external
+// resource has failed the supplied number of times, most recently with the
+// supplied error.
+func Blocked(attempts int, err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeleteBlocked,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDeleteFailing,
+		Message:            errors.Wrapf(err, "%d consecutive deletions failed", attempts).Error(),
+	}
+}
+
+// delay returns the delay after the supplied number of failed deletions.
+func delay(attempts int) time.Duration {
+	d := BaseDelay
+	for i := 1; i < attempts && d < MaxDelay; i++ {
+		d *= 2
+	}
+	if d > MaxDelay {
+		return MaxDelay
+	}
+	return d
+}
+
+type failure struct {
+	attempts int
+	next     time.Time
+}
+
+// A Connecter wraps an ExternalConnecter. It tracks the failed deletions of
+// the external resource of every managed resource, and refuses to connect
+// to delete it again until a delay that doubles with every failure has
+// passed. A managed resource that keeps failing to be deleted is given a
+// DeleteBlocked condition, and a warning event is recorded for it.
+type Connecter struct {
+	managed.ExternalConnecter
+	rec event.Recorder
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures map[types.UID]failure
+}
+
+// NewConnecter returns a Connecter that wraps the supplied ExternalConnecter
+// and records a warning event when a deletion becomes blocked.
+func NewConnecter(c managed.ExternalConnecter, rec event.Recorder) *Connecter {
+	return &Connecter{ExternalConnecter: c, rec: rec, now: time.Now, failures: map[types.UID]failure{}}
+}
+
+// Connect to the external resource, unless the managed resource is being
+// deleted and its last deletion failed too recently.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if !meta.WasDeleted(mg) {
+		return c.ExternalConnecter.Connect(ctx, mg)
+	}
+
+	c.mu.Lock()
+	f, ok := c.failures[mg.GetUID()]
+	c.mu.Unlock()
+	if now := c.now(); ok && now.Before(f.next) {
+		return nil, errors.Errorf(errDeleteBlocked, f.attempts, f.next.Sub(now).Round(time.Second))
+	}
+
+	ext, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{ExternalClient: ext, c: c}, nil
+}
+
+// forget stops tracking the failed deletions of the supplied managed
+// resource.
+func (c *Connecter) forget(mg resource.Managed) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.failures, mg.GetUID())
+}
+
+// fail records a failed deletion of the supplied managed resource.
+func (c *Connecter) fail(mg resource.Managed, err error) {
+	c.mu.Lock()
+	f := c.failures[mg.GetUID()]
+	f.attempts++
+	f.next = c.now().Add(delay(f.attempts))
+	c.failures[mg.GetUID()] = f
+	c.mu.Unlock()
+
+	if f.attempts < BlockAfter {
+		return
+	}
+	mg.SetConditions(Blocked(f.attempts, err))
+	if f.attempts == BlockAfter {
+		c.rec.Event(mg, event.Warning(reasonDeleteBlocked, errors.Wrap(err, errBlocked)))
+	}
+}
+
+// external is an ExternalClient of a managed resource that is being deleted.
+type external struct {
+	managed.ExternalClient
+	c *Connecter
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	if err == nil && !o.ResourceExists {
+		e.c.forget(mg)
+	}
+	return o, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if err := e.ExternalClient.Delete(ctx, mg); err != nil {
+		e.c.fail(mg, err)
+		return err
+	}
+	e.c.forget(mg)
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:   BaseDelay,
+		2:   2 * BaseDelay,
+		4:   8 * BaseDelay,
+		100: MaxDelay,
+	}
+	for attempts, want := range cases {
+		if got := delay(attempts); got != want {
+			t.Errorf("delay(%d): want %s, got %s", attempts, want, got)
+		}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+	deleted := metav1.Now()
+	now := time.Now()
+
+	role := &v1alpha1.Role{ObjectMeta: metav1.ObjectMeta{UID: "example", DeletionTimestamp: &deleted}}
+
+	connects := 0
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		connects++
+		return &managed.ExternalClientFns{
+			ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				return managed.ExternalObservation{ResourceExists: true}, nil
+			},
+			DeleteFn: func(_ context.Context, _ resource.Managed) error { return errBoom },
+		}, nil
+	}), event.NewNopRecorder())
+	c.now = func() time.Time { return now }
+
+	for i := 1; i <= BlockAfter; i++ {
+		ext, err := c.Connect(context.Background(), role)
+		if err != nil {
+			t.Fatalf("attempt %d: c.Connect(...): unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(errBoom, ext.Delete(context.Background(), role), test.EquateErrors()); diff != "" {
+			t.Fatalf("attempt %d: e.Delete(...): -want error, +got error:\n%s", i, diff)
+		}
+
+		blocked := role.GetCondition(TypeDeleteBlocked).Status == corev1.ConditionTrue
+		if want := i >= BlockAfter; blocked != want {
+			t.Errorf("attempt %d: want DeleteBlocked %t, got %t", i, want, blocked)
+		}
+
+		// The next attempt is refused until the delay has passed.
+		if _, err := c.Connect(context.Background(), role); err == nil {
+			t.Errorf("attempt %d: c.Connect(...): want an error before the delay has passed", i)
+		}
+		now = now.Add(delay(i))
+	}
+	if connects != BlockAfter {
+		t.Errorf("want %d connections, got %d", BlockAfter, connects)
+	}
+
+	// Once the external resource is gone its failures are forgotten.
+	ext, err := c.Connect(context.Background(), role)
+	if err != nil {
+		t.Fatalf("c.Connect(...): unexpected error: %v", err)
+	}
+	ext.(*external).ExternalClient = &managed.ExternalClientFns{
+		ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		},
+	}
+	if _, err := ext.Observe(context.Background(), role); err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if len(c.failures) != 0 {
+		t.Errorf("want no tracked failures once the external resource is gone, got %v", c.failures)
+	}
+}
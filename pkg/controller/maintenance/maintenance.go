@@ -0,0 +1,357 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance defers disruptive changes to the external resources of
+// managed resources, i.e. updates and deletions, to maintenance windows.
+// Outside of them, managed resources are observed but not changed, and
+// report a Deferred condition.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// Time zones of maintenance windows are loaded from the embedded
+	// database if the image does not contain one.
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// AnnotationKeyWindow sets the maintenance window of a managed resource,
+	// in the format accepted by Parse. It takes precedence over the
+	// maintenance window of its ProviderConfig. An empty window allows
+	// changes at any time.
+	AnnotationKeyWindow = "sql.crossplane.io/maintenance-window"
+
+	// TypeDeferred indicates whether changes to the external resource of a
+	// managed resource are deferred until its next maintenance window.
+	TypeDeferred xpv1.ConditionType = "Deferred"
+
+	// ReasonOutsideWindow is the reason of a true Deferred condition.
+	ReasonOutsideWindow xpv1.ConditionReason = "OutsideMaintenanceWindow"
+
+	// ReasonApplied is the reason of a Deferred condition that is false
+	// because the deferred changes were applied.
+	ReasonApplied xpv1.ConditionReason = "Applied"
+
+	errGetPC          = "cannot get ProviderConfig"
+	errInvalidWindow  = "invalid maintenance window"
+	errDeferredDelete = "deletion of external resource is deferred until the maintenance window starts at %s"
+	msgDeferred       = "changes to external resource are deferred until the maintenance window starts at %s"
+)
+
+// Deferred returns a condition that indicates changes to the external
+// resource are deferred until the supplied start of the next maintenance
+// window.
+func Deferred(next time.Time) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeferred,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonOutsideWindow,
+		Message:            fmt.Sprintf(msgDeferred, next.UTC().Format(time.RFC3339)),
+	}
+}
+
+// Applied returns a condition that indicates changes to the external
+// resource are no longer deferred.
+func Applied() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeferred,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonApplied,
+	}
+}
+
+// A ProviderConfig may specify the maintenance window of the managed
+// resources that use it.
+type ProviderConfig interface {
+	client.Object
+
+	// GetMaintenanceWindow returns the maintenance window, in the format
+	// accepted by Parse, or an empty string if changes are allowed at any
+	// time.
+	GetMaintenanceWindow() string
+}
+
+// A Connecter wraps an ExternalConnecter. It returns clients that defer the
+// updates and deletions of external resources outside of the maintenance
+// window of their managed resource, or else of its ProviderConfig.
+type Connecter struct {
+	managed.ExternalConnecter
+	kube client.Reader
+	pc   ProviderConfig
+	now  func() time.Time
+}
+
+// NewConnecter returns a Connecter that wraps the supplied ExternalConnecter,
+// and reads the maintenance windows of ProviderConfigs of the same type as
+// the supplied one using the supplied client.
+func NewConnecter(c managed.ExternalConnecter, kube client.Reader, pc ProviderConfig) *Connecter {
+	return &Connecter{ExternalConnecter: c, kube: kube, pc: pc, now: time.Now}
+}
+
+// Connect to the external resource, returning a client that defers changes
+// to it outside of its maintenance window.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	w, err := c.window(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(w)
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidWindow)
+	}
+
+	ext, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{ExternalClient: ext, schedule: s, now: c.now}, nil
+}
+
+// window returns the maintenance window of the supplied managed resource, or
+// else of its ProviderConfig. A ProviderConfig that is not found is left to
+// the wrapped ExternalConnecter to report.
+func (c *Connecter) window(ctx context.Context, mg resource.Managed) (string, error) {
+	if w, ok := mg.GetAnnotations()[AnnotationKeyWindow]; ok {
+		return w, nil
+	}
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return "", nil
+	}
+	pc := c.pc.DeepCopyObject().(ProviderConfig)
+	err := c.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc)
+	if kerrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errGetPC)
+	}
+	return pc.GetMaintenanceWindow(), nil
+}
+
+// external is an ExternalClient that defers updates and deletions outside of
+// a maintenance window.
+type external struct {
+	managed.ExternalClient
+	schedule Schedule
+	now      func() time.Time
+}
+
+// deferred returns the start of the next maintenance window, and true if
+// changes are to be deferred until then.
+func (e *external) deferred() (time.Time, bool) {
+	now := e.now()
+	if len(e.schedule) == 0 || e.schedule.Open(now) {
+		return time.Time{}, false
+	}
+	return e.schedule.Next(now), true
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	if err == nil && (o.ResourceUpToDate || !o.ResourceExists) && mg.GetCondition(TypeDeferred).Status == corev1.ConditionTrue {
+		mg.SetConditions(Applied())
+	}
+	return o, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if next, ok := e.deferred(); ok {
+		mg.SetConditions(Deferred(next))
+		return managed.ExternalUpdate{}, nil
+	}
+	return e.ExternalClient.Update(ctx, mg)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if next, ok := e.deferred(); ok {
+		mg.SetConditions(Deferred(next))
+		return errors.Errorf(errDeferredDelete, next.UTC().Format(time.RFC3339))
+	}
+	return e.ExternalClient.Delete(ctx, mg)
+}
+
+// A Window is a recurring period of up to a day that starts at the same time
+// on some days of the week.
+type Window struct {
+	days     [7]bool
+	hour     int
+	minute   int
+	duration time.Duration
+	location *time.Location
+}
+
+// start returns the start of the window on the day of the supplied time, and
+// whether the window starts on that day at all.
+func (w Window) start(day time.Time) (time.Time, bool) {
+	day = day.In(w.location)
+	s := time.Date(day.Year(), day.Month(), day.Day(), w.hour, w.minute, 0, 0, w.location)
+	return s, w.days[s.Weekday()]
+}
+
+// A Schedule is a set of maintenance windows. Changes are allowed during any
+// of them.
+type Schedule []Window
+
+// Open returns true if the supplied time is within a window of the schedule.
+func (s Schedule) Open(t time.Time) bool {
+	for _, w := range s {
+		// Windows last at most a day, so only those that started on the
+		// same or the previous day can still be open.
+		for d := 0; d >= -1; d-- {
+			start, ok := w.start(t.In(w.location).AddDate(0, 0, d))
+			if ok && !t.Before(start) && t.Before(start.Add(w.duration)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Next returns the start of the first window of the schedule after the
+// supplied time.
+func (s Schedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, w := range s {
+		for d := 0; d <= 7; d++ {
+			start, ok := w.start(t.In(w.location).AddDate(0, 0, d))
+			if ok && start.After(t) {
+				if next.IsZero() || start.Before(next) {
+					next = start
+				}
+				break
+			}
+		}
+	}
+	return next
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Parse parses a schedule of maintenance windows separated by semicolons,
+// each of the form DAYS HH:MM-HH:MM [TIMEZONE], e.g. "Sat,Sun 02:00-06:00
+// Europe/Berlin". DAYS are the days of the week the window starts on, as
+// three letter abbreviations or ranges of them such as Mon-Fri, or * for
+// every day. A window that ends at or before the time it starts ends on the
+// next day. Times are in UTC unless a time zone is given. An empty schedule
+// has no windows, and allows changes at any time.
+func Parse(schedule string) (Schedule, error) {
+	var s Schedule
+	for _, spec := range strings.Split(schedule, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse window %q", strings.TrimSpace(spec))
+		}
+		s = append(s, w)
+	}
+	return s, nil
+}
+
+func parseWindow(spec string) (Window, error) {
+	f := strings.Fields(spec)
+	if len(f) != 2 && len(f) != 3 {
+		return Window{}, errors.New("want DAYS HH:MM-HH:MM [TIMEZONE]")
+	}
+
+	w := Window{location: time.UTC}
+	if len(f) == 3 {
+		l, err := time.LoadLocation(f[2])
+		if err != nil {
+			return Window{}, errors.Wrap(err, "cannot load time zone")
+		}
+		w.location = l
+	}
+
+	var err error
+	if w.days, err = parseDays(f[0]); err != nil {
+		return Window{}, err
+	}
+
+	from, to, ok := strings.Cut(f[1], "-")
+	if !ok {
+		return Window{}, errors.Errorf("want a time range HH:MM-HH:MM, got %q", f[1])
+	}
+	start, err := time.Parse("15:04", from)
+	if err != nil {
+		return Window{}, errors.Wrap(err, "cannot parse start time")
+	}
+	end, err := time.Parse("15:04", to)
+	if err != nil {
+		return Window{}, errors.Wrap(err, "cannot parse end time")
+	}
+	w.hour, w.minute = start.Hour(), start.Minute()
+	w.duration = end.Sub(start)
+	if w.duration <= 0 {
+		w.duration += 24 * time.Hour
+	}
+	return w, nil
+}
+
+func parseDays(spec string) ([7]bool, error) {
+	var days [7]bool
+	if spec == "*" {
+		return [7]bool{true, true, true, true, true, true, true}, nil
+	}
+	for _, r := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(r, "-")
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			return days, errors.Errorf("unknown day of the week %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(to)]; !ok {
+				return days, errors.Errorf("unknown day of the week %q", to)
+			}
+		}
+		// Ranges such as Fri-Mon wrap around the end of the week.
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
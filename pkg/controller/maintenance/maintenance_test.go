@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		schedule string
+		wantErr  bool
+		want     int
+	}{
+		"Empty":         {schedule: "", want: 0},
+		"Single":        {schedule: "Sat 02:00-06:00", want: 1},
+		"Several":       {schedule: "Sat,Sun 02:00-06:00 Europe/Berlin; Mon-Fri 22:00-02:00", want: 2},
+		"EveryDay":      {schedule: "* 03:00-04:00 America/New_York", want: 1},
+		"UnknownDay":    {schedule: "Someday 02:00-06:00", wantErr: true},
+		"NoRange":       {schedule: "Sat 02:00", wantErr: true},
+		"BadTime":       {schedule: "Sat 25:00-06:00", wantErr: true},
+		"BadTimeZone":   {schedule: "Sat 02:00-06:00 Nowhere/Special", wantErr: true},
+		"TooManyFields": {schedule: "Sat 02:00-06:00 UTC extra", wantErr: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(tc.schedule)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Parse(%q): want error %t, got %v", tc.schedule, tc.wantErr, err)
+			}
+			if len(s) != tc.want {
+				t.Errorf("Parse(%q): want %d windows, got %d", tc.schedule, tc.want, len(s))
+			}
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	// 2024-06-07 is a Friday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	cases := map[string]struct {
+		schedule string
+		now      time.Time
+		open     bool
+		next     time.Time
+	}{
+		"Within": {
+			schedule: "Sat,Sun 02:00-06:00",
+			now:      at(8, 3, 0),
+			open:     true,
+			next:     at(9, 2, 0),
+		},
+		"Before": {
+			schedule: "Sat,Sun 02:00-06:00",
+			now:      at(7, 12, 0),
+			next:     at(8, 2, 0),
+		},
+		"AtEnd": {
+			schedule: "Sat 02:00-06:00",
+			now:      at(8, 6, 0),
+			next:     at(15, 2, 0),
+		},
+		"AcrossMidnight": {
+			schedule: "Fri 22:00-02:00",
+			now:      at(8, 1, 30),
+			open:     true,
+			next:     at(14, 22, 0),
+		},
+		"WrappingDays": {
+			schedule: "Sat-Mon 00:00-00:00",
+			now:      at(10, 23, 0),
+			open:     true,
+			next:     at(15, 0, 0),
+		},
+		"TimeZone": {
+			// 02:00 in Berlin is 00:00 UTC in summer.
+			schedule: "Sat 02:00-06:00 Europe/Berlin",
+			now:      at(8, 0, 30),
+			open:     true,
+			next:     at(15, 0, 0),
+		},
+		"Earliest": {
+			schedule: "Sun 02:00-03:00; Sat 23:00-23:30",
+			now:      at(7, 12, 0),
+			next:     at(8, 23, 0),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(tc.schedule)
+			if err != nil {
+				t.Fatalf("Parse(%q): unexpected error: %v", tc.schedule, err)
+			}
+			if got := s.Open(tc.now); got != tc.open {
+				t.Errorf("s.Open(%s): want %t, got %t", tc.now, tc.open, got)
+			}
+			if got := s.Next(tc.now); !got.Equal(tc.next) {
+				t.Errorf("s.Next(%s): want %s, got %s", tc.now, tc.next, got.UTC())
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+	// 2024-06-07 is a Friday.
+	now := time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		updates  int
+		deletes  int
+		deferred corev1.ConditionStatus
+		err      error
+	}
+	cases := map[string]struct {
+		reason      string
+		annotations map[string]string
+		pcWindow    *string
+		pcErr       error
+		want        want
+	}{
+		"NoWindow": {
+			reason: "Changes should be allowed at any time without a maintenance window",
+			want:   want{updates: 1, deletes: 1},
+		},
+		"OutsideProviderConfigWindow": {
+			reason:   "Changes should be deferred outside of the maintenance window of the ProviderConfig",
+			pcWindow: ptr.To("Sat,Sun 02:00-06:00"),
+			want:     want{deferred: corev1.ConditionTrue, err: errors.Errorf(errDeferredDelete, "2024-06-08T02:00:00Z")},
+		},
+		"WithinProviderConfigWindow": {
+			reason:   "Changes should be allowed within the maintenance window of the ProviderConfig",
+			pcWindow: ptr.To("Fri 10:00-14:00"),
+			want:     want{updates: 1, deletes: 1},
+		},
+		"AnnotationTakesPrecedence": {
+			reason:      "The maintenance window of a managed resource should take precedence over that of its ProviderConfig",
+			annotations: map[string]string{AnnotationKeyWindow: "Fri 11:00-13:00"},
+			pcWindow:    ptr.To("Sat,Sun 02:00-06:00"),
+			want:        want{updates: 1, deletes: 1},
+		},
+		"EmptyAnnotation": {
+			reason:      "An empty maintenance window annotation should allow changes at any time",
+			annotations: map[string]string{AnnotationKeyWindow: ""},
+			pcWindow:    ptr.To("Sat,Sun 02:00-06:00"),
+			want:        want{updates: 1, deletes: 1},
+		},
+		"InvalidWindow": {
+			reason:      "An invalid maintenance window should be reported",
+			annotations: map[string]string{AnnotationKeyWindow: "Someday 02:00-06:00"},
+			want:        want{err: errors.Wrap(errors.Wrap(errors.New(`unknown day of the week "Someday"`), `cannot parse window "Someday 02:00-06:00"`), errInvalidWindow)},
+		},
+		"ErrGetProviderConfig": {
+			reason: "Errors getting the ProviderConfig should be returned",
+			pcErr:  errBoom,
+			want:   want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updates, deletes int
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.pcErr != nil {
+						return tc.pcErr
+					}
+					obj.(*v1alpha1.ProviderConfig).Spec.MaintenanceWindow = tc.pcWindow
+					return nil
+				},
+			}
+			c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{
+					UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+						updates++
+						return managed.ExternalUpdate{}, nil
+					},
+					DeleteFn: func(_ context.Context, _ resource.Managed) error {
+						deletes++
+						return nil
+					},
+				}, nil
+			}), kube, &v1alpha1.ProviderConfig{})
+			c.now = func() time.Time { return now }
+
+			role := &v1alpha1.Role{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			role.SetProviderConfigReference(&xpv1.Reference{Name: "example"})
+
+			ext, err := c.Connect(context.Background(), role)
+			if err == nil {
+				if _, err := ext.Update(context.Background(), role); err != nil {
+					t.Fatalf("\n%s\ne.Update(...): unexpected error: %v", tc.reason, err)
+				}
+				err = ext.Delete(context.Background(), role)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n-want error, +got error:\n%s", tc.reason, diff)
+			}
+			if updates != tc.want.updates || deletes != tc.want.deletes {
+				t.Errorf("\n%s\nwant %d updates and %d deletes, got %d and %d", tc.reason, tc.want.updates, tc.want.deletes, updates, deletes)
+			}
+			if got := role.GetCondition(TypeDeferred).Status; got != tc.want.deferred && !(tc.want.deferred == "" && got == corev1.ConditionUnknown) {
+				t.Errorf("\n%s\nwant Deferred condition %q, got %q", tc.reason, tc.want.deferred, got)
+			}
+		})
+	}
+}
+
+func TestApplied(t *testing.T) {
+	role := &v1alpha1.Role{}
+	role.SetConditions(Deferred(time.Now()))
+
+	e := &external{
+		ExternalClient: &managed.ExternalClientFns{
+			ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+			},
+		},
+		now: time.Now,
+	}
+	if _, err := e.Observe(context.Background(), role); err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if got := role.GetCondition(TypeDeferred); got.Status != corev1.ConditionFalse || got.Reason != ReasonApplied {
+		t.Errorf("e.Observe(...): want Deferred condition to be false once changes were applied, got %s (%s)", got.Status, got.Reason)
+	}
+}
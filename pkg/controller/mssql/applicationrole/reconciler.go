@@ -0,0 +1,299 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationrole
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotApplicationRole      = "managed resource is not an ApplicationRole custom resource"
+	errInvalidName             = "invalid application role name"
+	errSelectRole              = "cannot select application role"
+	errCreateRole              = "cannot create application role"
+	errAlterRole               = "cannot alter application role"
+	errDropRole                = "cannot drop application role"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	defaultSchema = "dbo"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles ApplicationRole managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.ApplicationRoleGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ApplicationRoleGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.ApplicationRole{}, &v1alpha1.ApplicationRoleList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.ApplicationRole)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ApplicationRole{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.ApplicationRoleGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.ApplicationRoleGroupKind))
+}
+
+// NewConnecter returns a connecter for ApplicationRole managed resources. It
+// gets ProviderConfigs and credentials using the supplied client, and tracks
+// the usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationRole)
+	if !ok {
+		return nil, errors.New(errNotApplicationRole)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), mssql.OptionsFromProviderConfig(pc)), mssql.ConnectionLimits(pc)), c.log, v1alpha1.ApplicationRoleKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationRole)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotApplicationRole)
+	}
+
+	var schema string
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT COALESCE(default_schema_name, '') FROM sys.database_principals WHERE type = 'A' AND name = @p1",
+		Parameters: []interface{}{meta.GetExternalName(cr)},
+	}, &schema)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectRole)
+	}
+
+	cr.Status.AtProvider.DefaultSchema = schema
+	cr.SetConditions(xpv1.Available())
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: !pwdChanged && schema == ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationRole)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotApplicationRole)
+	}
+
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mssql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if pw == "" {
+		if pw, err = password.Generate(); err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	query := fmt.Sprintf("CREATE APPLICATION ROLE %s WITH PASSWORD=%s, DEFAULT_SCHEMA=%s",
+		mssql.QuoteIdentifier(meta.GetExternalName(cr)),
+		mssql.QuoteValue(pw),
+		mssql.QuoteIdentifier(ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema)))
+	if err := c.db.Exec(ctx, xsql.Query{String: query}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRole)
+	}
+
+	return managed.ExternalCreation{ConnectionDetails: c.connectionDetails(cr, pw)}, nil
+}
+
+// connectionDetails returns the connection details of the supplied
+// application role, including its database.
+func (c *external) connectionDetails(cr *v1alpha1.ApplicationRole, pw string) managed.ConnectionDetails {
+	cd := c.db.GetConnectionDetails(meta.GetExternalName(cr), pw)
+	if db := cr.Spec.ForProvider.Database; db != nil {
+		cd[xsql.DatabaseKey] = []byte(*db)
+	}
+	return cd
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationRole)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotApplicationRole)
+	}
+
+	pw, changed, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	var set []string
+	if changed {
+		set = append(set, "PASSWORD="+mssql.QuoteValue(pw))
+	}
+	if s := ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema); s != cr.Status.AtProvider.DefaultSchema {
+		set = append(set, "DEFAULT_SCHEMA="+mssql.QuoteIdentifier(s))
+	}
+	if len(set) == 0 {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	query := fmt.Sprintf("ALTER APPLICATION ROLE %s WITH %s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), strings.Join(set, ", "))
+	if err := c.db.Exec(ctx, xsql.Query{String: query}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterRole)
+	}
+
+	if !changed {
+		return managed.ExternalUpdate{}, nil
+	}
+	return managed.ExternalUpdate{ConnectionDetails: c.connectionDetails(cr, pw)}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ApplicationRole)
+	if !ok {
+		return errors.New(errNotApplicationRole)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	// DROP APPLICATION ROLE has no IF EXISTS clause. A database deleted
+	// before its application roles takes them along.
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("IF EXISTS (SELECT 1 FROM sys.database_principals WHERE type = 'A' AND name = %s) DROP APPLICATION ROLE %s",
+			mssql.QuoteValue(meta.GetExternalName(cr)), mssql.QuoteIdentifier(meta.GetExternalName(cr))),
+	})
+	if err != nil && !mssql.IsUnknownDatabase(err) {
+		return errors.Wrap(err, errDropRole)
+	}
+	return nil
+}
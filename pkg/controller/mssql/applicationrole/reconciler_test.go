@@ -0,0 +1,296 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationrole
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec   func(ctx context.Context, q xsql.Query) error
+	MockScan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx func(ctx context.Context, ql []xsql.Query) error
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+	}
+}
+
+func role(p v1alpha1.ApplicationRoleParameters) *v1alpha1.ApplicationRole {
+	cr := &v1alpha1.ApplicationRole{Spec: v1alpha1.ApplicationRoleSpec{ForProvider: p}}
+	meta.SetExternalName(cr, "app")
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.ApplicationRoleObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		mg     resource.Managed
+		want   want
+	}{
+		"ErrNotApplicationRole": {
+			reason: "An error should be returned if the managed resource is not an *ApplicationRole",
+			mg:     nil,
+			want: want{
+				err: errors.New(errNotApplicationRole),
+			},
+		},
+		"NoRole": {
+			reason: "We should return ResourceExists: false when no application role is found",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+			},
+			mg: role(v1alpha1.ApplicationRoleParameters{}),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectRole": {
+			reason: "We should return any errors encountered while trying to select the application role",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+			},
+			mg: role(v1alpha1.ApplicationRoleParameters{}),
+			want: want{
+				err: errors.Wrap(errBoom, errSelectRole),
+			},
+		},
+		"UpToDate": {
+			reason: "An application role with the default schema should be up to date",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					*dest[0].(*string) = "dbo"
+					return nil
+				},
+			},
+			mg: role(v1alpha1.ApplicationRoleParameters{}),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.ApplicationRoleObservation{DefaultSchema: "dbo"},
+			},
+		},
+		"SchemaChanged": {
+			reason: "An application role with another default schema should not be up to date",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					*dest[0].(*string) = "dbo"
+					return nil
+				},
+			},
+			mg: role(v1alpha1.ApplicationRoleParameters{DefaultSchema: ptr.To("app")}),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.ApplicationRoleObservation{DefaultSchema: "dbo"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			got, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.mg.(*v1alpha1.ApplicationRole); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var queries []string
+	db := mockDB{
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("s3cr3t")}
+			return nil
+		}),
+	}
+	e := external{db: db, kube: kube}
+
+	cr := role(v1alpha1.ApplicationRoleParameters{
+		Database:          ptr.To("example"),
+		PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+	})
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	wantQueries := []string{
+		"CREATE APPLICATION ROLE [app] WITH PASSWORD='s3cr3t', DEFAULT_SCHEMA=[dbo]",
+	}
+	if diff := cmp.Diff(wantQueries, queries); diff != "" {
+		t.Errorf("e.Create(...): -want queries, +got queries:\n%s\n", diff)
+	}
+
+	want := managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte("app"),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte("s3cr3t"),
+		xsql.DatabaseKey:                          []byte("example"),
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.Create(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		u       managed.ExternalUpdate
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		exec   func(q xsql.Query) error
+		kube   client.Client
+		mg     *v1alpha1.ApplicationRole
+		want   want
+	}{
+		"NoChanges": {
+			reason: "An application role that is up to date should not be altered",
+			mg: func() *v1alpha1.ApplicationRole {
+				cr := role(v1alpha1.ApplicationRoleParameters{})
+				cr.Status.AtProvider = v1alpha1.ApplicationRoleObservation{DefaultSchema: "dbo"}
+				return cr
+			}(),
+			want: want{},
+		},
+		"AlterPasswordAndSchema": {
+			reason: "A changed password and default schema should be altered in one statement",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					s := obj.(*corev1.Secret)
+					if key.Name == "output" {
+						s.Data = map[string][]byte{xpv1.ResourceCredentialsSecretPasswordKey: []byte("old")}
+						return nil
+					}
+					s.Data = map[string][]byte{"password": []byte("new")}
+					return nil
+				},
+			},
+			mg: func() *v1alpha1.ApplicationRole {
+				cr := role(v1alpha1.ApplicationRoleParameters{
+					DefaultSchema:     ptr.To("app"),
+					PasswordSecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "input"}, Key: "password"},
+				})
+				cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Name: "output"})
+				cr.Status.AtProvider = v1alpha1.ApplicationRoleObservation{DefaultSchema: "dbo"}
+				return cr
+			}(),
+			want: want{
+				u: managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{
+					xpv1.ResourceCredentialsSecretUserKey:     []byte("app"),
+					xpv1.ResourceCredentialsSecretPasswordKey: []byte("new"),
+				}},
+				queries: []string{
+					"ALTER APPLICATION ROLE [app] WITH PASSWORD='new', DEFAULT_SCHEMA=[app]",
+				},
+			},
+		},
+		"ErrAlterRole": {
+			reason: "Errors altering the application role should be returned",
+			exec:   func(q xsql.Query) error { return errBoom },
+			mg:     role(v1alpha1.ApplicationRoleParameters{DefaultSchema: ptr.To("app")}),
+			want: want{
+				err: errors.Wrap(errBoom, errAlterRole),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db := mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					if tc.exec != nil {
+						return tc.exec(q)
+					}
+					queries = append(queries, q.String)
+					return nil
+				},
+			}
+			e := external{db: db, kube: tc.kube}
+			got, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
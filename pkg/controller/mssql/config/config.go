@@ -18,20 +18,28 @@ package config
 
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/providerconfig"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
 )
 
 // Setup adds a controller that reconciles ProviderConfigs by accounting for
-// their current usage.
+// their current usage. It also indexes ProviderConfigs by their credentials
+// Secret, so that managed resources are reconciled when it changes.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
 	name := providerconfig.ControllerName(v1alpha1.ProviderConfigGroupKind)
 
+	if err := secretref.IndexProviderConfigs(mgr, &v1alpha1.ProviderConfig{}, credentials); err != nil {
+		return err
+	}
+
 	of := resource.ProviderConfigKinds{
 		Config:    v1alpha1.ProviderConfigGroupVersionKind,
 		UsageList: v1alpha1.ProviderConfigUsageListGroupVersionKind,
@@ -45,3 +53,12 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 			providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
 			providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
 }
+
+// credentials returns the credentials Secret of the supplied ProviderConfig.
+func credentials(o client.Object) *xpv1.SecretReference {
+	pc, ok := o.(*v1alpha1.ProviderConfig)
+	if !ok {
+		return nil
+	}
+	return pc.Spec.Credentials.ConnectionSecretRef
+}
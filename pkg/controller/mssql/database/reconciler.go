@@ -18,10 +18,15 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -29,54 +34,102 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 
 	errNotDatabase = "managed resource is not a Database custom resource"
+	errInvalidName = "invalid database name"
 	errSelectDB    = "cannot select database"
 	errCreateDB    = "cannot create database"
 	errDropDB      = "cannot drop database"
+	errRestoreDB   = "cannot restore database from snapshot"
+	errSelectDSC   = "cannot select database scoped configurations"
+	errAlterDSC    = "cannot alter database scoped configuration"
+	errSelectProps = "cannot select extended properties"
+	errSetProp     = "cannot set extended property %s"
+	errDropProp    = "cannot drop extended property %s"
+	errSchemaProps = "cannot manage extended properties of schema %s"
+	errSelectTDE   = "cannot select database encryption key"
+	errCreateDEK   = "cannot create database encryption key"
+	errAlterDEK    = "cannot change the protector of the database encryption key"
+	errSetTDE      = "cannot set database encryption"
+	errNoProtector = "tdeCertificate or tdeAsymmetricKey is required to create the database encryption key"
 
 	maxConcurrency = 5
 )
 
+// encryptionStates are the names of the encryption_state values of
+// sys.dm_database_encryption_keys. Only SQL Server 2019 and later report
+// them as encryption_state_desc.
+var encryptionStates = map[int]string{
+	0: "NO_DATABASE_ENCRYPTION_KEY",
+	1: "UNENCRYPTED",
+	2: "ENCRYPTION_IN_PROGRESS",
+	3: "ENCRYPTED",
+	4: "KEY_CHANGE_IN_PROGRESS",
+	5: "DECRYPTION_IN_PROGRESS",
+	6: "PROTECTION_CHANGE_IN_PROGRESS",
+}
+
 // Setup adds a controller that reconciles Database managed resources.
 func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newClient: mssql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Database{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseGroupKind))
+}
+
+// NewConnecter returns a connecter for Database managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
 }
 
 type connector struct {
 	kube      client.Client
 	usage     resource.Tracker
-	newClient func(creds map[string][]byte, database string) xsql.DB
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -95,24 +148,43 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
-
-	// We don't need to check the credentials source because we currently only
-	// support one source (MySQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
 	}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
 
-	return &external{db: c.newClient(s.Data, "")}, nil
+	return &external{
+		db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, "", opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr),
+		// Database scoped configurations and extended properties apply to
+		// the database of the connection they are set or selected in.
+		scopedDB: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, meta.GetExternalName(cr), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr),
+	}, nil
 }
 
-type external struct{ db xsql.DB }
+type external struct {
+	db       xsql.DB
+	scopedDB xsql.DB
+}
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
 	cr, ok := mg.(*v1alpha1.Database)
@@ -120,9 +192,24 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotDatabase)
 	}
 
-	var name string
-	query := "SELECT name FROM master.sys.databases WHERE name = @p1"
-	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &name)
+	observed := v1alpha1.DatabaseObservation{
+		// The snapshot a database was restored from cannot be observed, so
+		// we carry it over from the last observation.
+		RestoredFromSnapshot: cr.Status.AtProvider.RestoredFromSnapshot,
+	}
+	query := "SELECT ISNULL(SUSER_SNAME(d.owner_sid), ''), ISNULL(d.collation_name, ''), d.state_desc, " +
+		"d.recovery_model_desc, d.compatibility_level, d.is_read_only, " +
+		"(SELECT ISNULL(SUM(CAST(f.size AS bigint)), 0) * 8192 FROM master.sys.master_files f WHERE f.database_id = d.database_id) " +
+		"FROM master.sys.databases d WHERE d.name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}},
+		&observed.Owner,
+		&observed.Collation,
+		&observed.State,
+		&observed.RecoveryModel,
+		&observed.CompatibilityLevel,
+		&observed.ReadOnly,
+		&observed.SizeBytes,
+	)
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
@@ -130,14 +217,50 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDB)
 	}
 
+	if len(cr.Spec.ForProvider.DatabaseScopedConfigurations) > 0 {
+		dsc, err := c.observeScopedConfigurations(ctx, cr.Spec.ForProvider.DatabaseScopedConfigurations)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectDSC)
+		}
+		observed.DatabaseScopedConfigurations = dsc
+	}
+
+	// Extended properties that were observed before are observed again, so
+	// that those that were removed from the spec are dropped.
+	p, last := cr.Spec.ForProvider, cr.Status.AtProvider
+	if names := unionKeys(p.ExtendedProperties, last.ExtendedProperties); len(names) > 0 {
+		props, err := c.observeExtendedProperties(ctx, "", names)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectProps)
+		}
+		observed.ExtendedProperties = props
+	}
+	for _, schema := range unionKeys(p.SchemaExtendedProperties, last.SchemaExtendedProperties) {
+		props, err := c.observeExtendedProperties(ctx, schema, unionKeys(p.SchemaExtendedProperties[schema], last.SchemaExtendedProperties[schema]))
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrapf(errors.Wrap(err, errSelectProps), errSchemaProps, schema)
+		}
+		if len(props) == 0 {
+			continue
+		}
+		if observed.SchemaExtendedProperties == nil {
+			observed.SchemaExtendedProperties = map[string]map[string]string{}
+		}
+		observed.SchemaExtendedProperties[schema] = props
+	}
+
+	if cr.Spec.ForProvider.TDEEnabled != nil {
+		if err := c.observeEncryption(ctx, meta.GetExternalName(cr), &observed); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectTDE)
+		}
+	}
+
+	cr.Status.AtProvider = observed
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
-		ResourceExists: true,
-
-		// TODO(turkenh): Support these when we have anything to update.
-		ResourceLateInitialized: false,
-		ResourceUpToDate:        true,
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(cr.Spec.ForProvider, observed),
 	}, nil
 }
 
@@ -148,15 +271,188 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotDatabase)
 	}
 
-	err := c.db.Exec(ctx, xsql.Query{String: "CREATE DATABASE " + mssql.QuoteIdentifier(meta.GetExternalName(cr))})
-	return managed.ExternalCreation{}, errors.Wrap(err, errCreateDB)
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mssql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
+	if err := c.db.Exec(ctx, xsql.Query{String: "CREATE DATABASE " + mssql.QuoteIdentifier(meta.GetExternalName(cr))}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateDB)
+	}
+
+	// A new database has nothing to be reverted, so we consider any snapshot
+	// it should be restored from as already applied.
+	cr.Status.AtProvider.RestoredFromSnapshot = ptr.Deref(cr.Spec.ForProvider.RestoreFromSnapshot, "")
+	return managed.ExternalCreation{}, nil
 }
 
-func (c *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
-	// TODO(turkenh): Support updates once we have anything to update.
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDatabase)
+	}
+
+	if err := c.restoreSnapshot(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	names := make([]string, 0, len(cr.Spec.ForProvider.DatabaseScopedConfigurations))
+	for name := range cr.Spec.ForProvider.DatabaseScopedConfigurations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	observed := cr.Status.AtProvider.DatabaseScopedConfigurations
+	for _, name := range names {
+		value := cr.Spec.ForProvider.DatabaseScopedConfigurations[name]
+		if sameConfigValue(value, observed[strings.ToUpper(name)]) {
+			continue
+		}
+		// Names and values are restricted to keywords and numbers by the
+		// CRD, so they are safe to use without quoting.
+		q := "ALTER DATABASE SCOPED CONFIGURATION SET " + strings.ToUpper(name) + " = " + strings.ToUpper(value)
+		if err := c.scopedDB.Exec(ctx, xsql.Query{String: q}); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errAlterDSC)
+		}
+	}
+
+	if err := c.setEncryption(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	p, o := cr.Spec.ForProvider, cr.Status.AtProvider
+	if err := c.setExtendedProperties(ctx, "", p.ExtendedProperties, o.ExtendedProperties); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	for _, schema := range unionKeys(p.SchemaExtendedProperties, o.SchemaExtendedProperties) {
+		if err := c.setExtendedProperties(ctx, schema, p.SchemaExtendedProperties[schema], o.SchemaExtendedProperties[schema]); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrapf(err, errSchemaProps, schema)
+		}
+	}
+
 	return managed.ExternalUpdate{}, nil
 }
 
+// setEncryption turns Transparent Data Encryption of the supplied database
+// on or off. The database encryption key is created if the database has
+// none, and re-encrypted if it should be protected by another certificate
+// or asymmetric key.
+func (c *external) setEncryption(ctx context.Context, cr *v1alpha1.Database) error {
+	p, o := cr.Spec.ForProvider, cr.Status.AtProvider
+	if p.TDEEnabled == nil {
+		return nil
+	}
+
+	// The key is created and altered in the database it encrypts. The
+	// algorithm is restricted to keywords by the CRD.
+	kind, name := tdeProtector(p)
+	switch {
+	case o.EncryptionState == "" && *p.TDEEnabled:
+		if name == "" {
+			return errors.New(errNoProtector)
+		}
+		q := "CREATE DATABASE ENCRYPTION KEY WITH ALGORITHM = " + ptr.Deref(p.TDEAlgorithm, "AES_256") +
+			" ENCRYPTION BY SERVER " + kind + " " + mssql.QuoteIdentifier(name)
+		if err := c.scopedDB.Exec(ctx, xsql.Query{String: q}); err != nil {
+			return errors.Wrap(err, errCreateDEK)
+		}
+	case o.EncryptionState != "" && name != "" && name != o.TDEProtector:
+		q := "ALTER DATABASE ENCRYPTION KEY ENCRYPTION BY SERVER " + kind + " " + mssql.QuoteIdentifier(name)
+		if err := c.scopedDB.Exec(ctx, xsql.Query{String: q}); err != nil {
+			return errors.Wrap(err, errAlterDEK)
+		}
+	}
+
+	if *p.TDEEnabled == encrypted(o.EncryptionState) {
+		return nil
+	}
+	state := "OFF"
+	if *p.TDEEnabled {
+		state = "ON"
+	}
+	err := c.db.Exec(ctx, xsql.Query{String: "ALTER DATABASE " + mssql.QuoteIdentifier(meta.GetExternalName(cr)) + " SET ENCRYPTION " + state})
+	return errors.Wrap(err, errSetTDE)
+}
+
+// tdeProtector returns the kind and name of the certificate or asymmetric
+// key that should protect the database encryption key, if any.
+func tdeProtector(p v1alpha1.DatabaseParameters) (string, string) {
+	switch {
+	case p.TDECertificate != nil:
+		return "CERTIFICATE", *p.TDECertificate
+	case p.TDEAsymmetricKey != nil:
+		return "ASYMMETRIC KEY", *p.TDEAsymmetricKey
+	default:
+		return "", ""
+	}
+}
+
+// encrypted returns true if the supplied encryption state is that of a
+// database that is, or is being, encrypted.
+func encrypted(state string) bool {
+	switch state {
+	case "ENCRYPTION_IN_PROGRESS", "ENCRYPTED", "KEY_CHANGE_IN_PROGRESS", "PROTECTION_CHANGE_IN_PROGRESS":
+		return true
+	default:
+		return false
+	}
+}
+
+// setExtendedProperties adds the desired extended properties of the
+// supplied schema, or of the database if it is empty, that were not
+// observed, updates those whose value differs, and drops those that were
+// observed but are no longer desired.
+func (c *external) setExtendedProperties(ctx context.Context, schema string, desired, observed map[string]string) error {
+	level := ""
+	if schema != "" {
+		level = ", @level0type = N'SCHEMA', @level0name = " + mssql.QuoteValue(schema)
+	}
+
+	for _, name := range unionKeys(desired, observed) {
+		value, want := desired[name]
+		current, ok := observed[name]
+		switch {
+		case !want:
+			q := fmt.Sprintf("EXEC sp_dropextendedproperty @name = %s%s", mssql.QuoteValue(name), level)
+			if err := c.scopedDB.Exec(ctx, xsql.Query{String: q}); err != nil {
+				return errors.Wrapf(err, errDropProp, name)
+			}
+		case !ok || current != value:
+			proc := "sp_addextendedproperty"
+			if ok {
+				proc = "sp_updateextendedproperty"
+			}
+			q := fmt.Sprintf("EXEC %s @name = %s, @value = %s%s", proc, mssql.QuoteValue(name), mssql.QuoteValue(value), level)
+			if err := c.scopedDB.Exec(ctx, xsql.Query{String: q}); err != nil {
+				return errors.Wrapf(err, errSetProp, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *external) restoreSnapshot(ctx context.Context, cr *v1alpha1.Database) error {
+	snapshot := cr.Spec.ForProvider.RestoreFromSnapshot
+	if snapshot == nil || *snapshot == cr.Status.AtProvider.RestoredFromSnapshot {
+		return nil
+	}
+
+	// Reverting requires exclusive access to the database. MSSQL does not
+	// allow RESTORE within a transaction, so these run one after another.
+	db := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	for _, q := range []string{
+		"ALTER DATABASE " + db + " SET SINGLE_USER WITH ROLLBACK IMMEDIATE",
+		"RESTORE DATABASE " + db + " FROM DATABASE_SNAPSHOT = " + mssql.QuoteValue(*snapshot),
+		"ALTER DATABASE " + db + " SET MULTI_USER",
+	} {
+		if err := c.db.Exec(ctx, xsql.Query{String: q}); err != nil {
+			return errors.Wrap(err, errRestoreDB)
+		}
+	}
+
+	cr.Status.AtProvider.RestoredFromSnapshot = *snapshot
+	return nil
+}
+
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	cr, ok := mg.(*v1alpha1.Database)
 	if !ok {
@@ -166,3 +462,141 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	err := c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE IF EXISTS " + mssql.QuoteIdentifier(meta.GetExternalName(cr))})
 	return errors.Wrap(err, errDropDB)
 }
+
+// observeScopedConfigurations returns the values of the supplied database
+// scoped configurations, keyed by their upper case name.
+func (c *external) observeScopedConfigurations(ctx context.Context, want map[string]string) (map[string]string, error) {
+	var agg string
+	query := "SELECT ISNULL(STRING_AGG(CONCAT(name, '=', CAST(value AS nvarchar(4000))), ';'), '') " +
+		"FROM sys.database_scoped_configurations"
+	if err := c.scopedDB.Scan(ctx, xsql.Query{String: query}, &agg); err != nil {
+		return nil, err
+	}
+
+	all := map[string]string{}
+	for _, kv := range strings.Split(agg, ";") {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			all[strings.ToUpper(name)] = value
+		}
+	}
+
+	observed := make(map[string]string, len(want))
+	for name := range want {
+		if value, ok := all[strings.ToUpper(name)]; ok {
+			observed[strings.ToUpper(name)] = value
+		}
+	}
+	return observed, nil
+}
+
+// observeEncryption sets the encryption state of the database of the
+// supplied name, and the name of the certificate or asymmetric key that
+// protects its database encryption key, in the supplied observation. Both
+// are left empty if the database has no database encryption key.
+func (c *external) observeEncryption(ctx context.Context, name string, o *v1alpha1.DatabaseObservation) error {
+	var state int
+	var protector string
+	query := "SELECT k.encryption_state, ISNULL(COALESCE(c.name, a.name), '') " +
+		"FROM sys.dm_database_encryption_keys k " +
+		"LEFT JOIN master.sys.certificates c ON c.thumbprint = k.encryptor_thumbprint " +
+		"LEFT JOIN master.sys.asymmetric_keys a ON a.thumbprint = k.encryptor_thumbprint " +
+		"WHERE k.database_id = DB_ID(@p1)"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{name}}, &state, &protector)
+	if xsql.IsNoRows(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	o.EncryptionState = encryptionStates[state]
+	o.TDEProtector = protector
+	return nil
+}
+
+// observeExtendedProperties returns the values of the supplied extended
+// properties that are set on the supplied schema, or on the database if it
+// is empty, keyed by name.
+func (c *external) observeExtendedProperties(ctx context.Context, schema string, names []string) (map[string]string, error) {
+	q := xsql.Query{String: "SELECT CAST(value AS nvarchar(4000)) FROM sys.extended_properties WHERE class = 0 AND name = @p1"}
+	if schema != "" {
+		q.String = "SELECT CAST(value AS nvarchar(4000)) FROM sys.extended_properties WHERE class = 3 AND major_id = SCHEMA_ID(@p2) AND name = @p1"
+	}
+
+	observed := make(map[string]string, len(names))
+	for _, name := range names {
+		q.Parameters = []interface{}{name}
+		if schema != "" {
+			q.Parameters = append(q.Parameters, schema)
+		}
+		var value string
+		err := c.scopedDB.Scan(ctx, q, &value)
+		if xsql.IsNoRows(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		observed[name] = value
+	}
+	return observed, nil
+}
+
+// sameConfigValue returns true if the supplied database scoped configuration
+// values are equivalent. Switches are reported as 1 or 0 by
+// sys.database_scoped_configurations, but set using ON or OFF.
+func sameConfigValue(a, b string) bool {
+	norm := func(v string) string {
+		switch v = strings.ToUpper(v); v {
+		case "ON":
+			return "1"
+		case "OFF":
+			return "0"
+		default:
+			return v
+		}
+	}
+	return norm(a) == norm(b)
+}
+
+func upToDate(p v1alpha1.DatabaseParameters, o v1alpha1.DatabaseObservation) bool {
+	if p.RestoreFromSnapshot != nil && *p.RestoreFromSnapshot != o.RestoredFromSnapshot {
+		return false
+	}
+	for name, value := range p.DatabaseScopedConfigurations {
+		if !sameConfigValue(value, o.DatabaseScopedConfigurations[strings.ToUpper(name)]) {
+			return false
+		}
+	}
+	// Only extended properties that are desired, or that are to be dropped,
+	// are observed.
+	if !maps.Equal(p.ExtendedProperties, o.ExtendedProperties) {
+		return false
+	}
+	for _, schema := range unionKeys(p.SchemaExtendedProperties, o.SchemaExtendedProperties) {
+		if !maps.Equal(p.SchemaExtendedProperties[schema], o.SchemaExtendedProperties[schema]) {
+			return false
+		}
+	}
+	if p.TDEEnabled != nil && *p.TDEEnabled != encrypted(o.EncryptionState) {
+		return false
+	}
+	if _, name := tdeProtector(p); o.EncryptionState != "" && name != "" && name != o.TDEProtector {
+		return false
+	}
+	return true
+}
+
+// unionKeys returns the sorted keys of both of the supplied maps.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
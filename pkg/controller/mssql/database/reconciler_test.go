@@ -19,15 +19,19 @@ package database
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -179,7 +183,8 @@ func TestObserve(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db       xsql.DB
+		scopedDB xsql.DB
 	}
 
 	type args struct {
@@ -188,8 +193,9 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseObservation
+		err        error
 	}
 
 	cases := map[string]struct {
@@ -254,11 +260,321 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessAtProvider": {
+			reason: "The observed state of the database should be reported in its status",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "sa"
+						*dest[2].(*string) = "ONLINE"
+						*dest[3].(*string) = "FULL"
+						*dest[4].(*int) = 160
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{Owner: "sa", State: "ONLINE", RecoveryModel: "FULL", CompatibilityLevel: 160},
+			},
+		},
+		"NotUpToDateRestoreFromSnapshot": {
+			reason: "The database should be outdated when it has not been restored from the desired snapshot",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{RestoreFromSnapshot: ptr.To("example_snapshot_2")},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{RestoredFromSnapshot: "example_snapshot_1"},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{RestoredFromSnapshot: "example_snapshot_1"},
+			},
+		},
+		"SchemaExtendedProperties": {
+			reason: "Extended properties of schemas that are desired, or were observed before, should be observed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+				scopedDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if !strings.Contains(q.String, "class = 3 AND major_id = SCHEMA_ID(@p2)") {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						switch q.Parameters[1] {
+						case "sales":
+							if q.Parameters[0] == "team" {
+								return sql.ErrNoRows
+							}
+							*dest[0].(*string) = "41"
+						case "hr":
+							*dest[0].(*string) = "people"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{SchemaExtendedProperties: map[string]map[string]string{
+							"sales": {"cost-center": "42", "team": "data"},
+						}},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{SchemaExtendedProperties: map[string]map[string]string{
+							"hr": {"team": "people"},
+						}},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{SchemaExtendedProperties: map[string]map[string]string{
+					"sales": {"cost-center": "41"},
+					"hr":    {"team": "people"},
+				}},
+			},
+		},
+		"ErrSelectSchemaExtendedProperties": {
+			reason: "We should return any errors encountered while trying to select extended properties of a schema",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+				scopedDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{SchemaExtendedProperties: map[string]map[string]string{
+							"sales": {"team": "data"},
+						}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.Wrap(errBoom, errSelectProps), errSchemaProps, "sales"),
+			},
+		},
+		"ErrSelectScopedConfigurations": {
+			reason: "We should return any errors encountered while trying to select database scoped configurations",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+				scopedDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DatabaseScopedConfigurations: map[string]string{"MAXDOP": "4"}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectDSC),
+			},
+		},
+		"UpToDateScopedConfigurations": {
+			reason: "Database scoped configurations should be compared regardless of case, and switches as ON or OFF",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+				scopedDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "MAXDOP=4;LEGACY_CARDINALITY_ESTIMATION=1;ELEVATE_ONLINE=OFF"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DatabaseScopedConfigurations: map[string]string{
+							"maxdop":                        "4",
+							"LEGACY_CARDINALITY_ESTIMATION": "on",
+						}},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.DatabaseObservation{DatabaseScopedConfigurations: map[string]string{
+					"MAXDOP":                        "4",
+					"LEGACY_CARDINALITY_ESTIMATION": "1",
+				}},
+			},
+		},
+		"NotUpToDateScopedConfigurations": {
+			reason: "The database should be outdated when a database scoped configuration differs",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+				scopedDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "MAXDOP=0"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DatabaseScopedConfigurations: map[string]string{"MAXDOP": "4"}},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{DatabaseScopedConfigurations: map[string]string{"MAXDOP": "0"}},
+			},
+		},
+		"ErrSelectEncryption": {
+			reason: "Errors selecting the database encryption key should be returned",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "dm_database_encryption_keys") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true)},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectTDE),
+			},
+		},
+		"NotUpToDateNoEncryptionKey": {
+			reason: "The database should be outdated when it should be encrypted but has no database encryption key",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "dm_database_encryption_keys") {
+							return sql.ErrNoRows
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true), TDECertificate: ptr.To("tde")},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"UpToDateEncryptionInProgress": {
+			reason: "A database that is being encrypted by the desired certificate should be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "dm_database_encryption_keys") {
+							*dest[0].(*int) = 2
+							*dest[1].(*string) = "tde"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true), TDECertificate: ptr.To("tde")},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.DatabaseObservation{EncryptionState: "ENCRYPTION_IN_PROGRESS", TDEProtector: "tde"},
+			},
+		},
+		"NotUpToDateEncryptionProtector": {
+			reason: "The database should be outdated when its database encryption key is protected by another certificate",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "dm_database_encryption_keys") {
+							*dest[0].(*int) = 3
+							*dest[1].(*string) = "tde-2023"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true), TDECertificate: ptr.To("tde-2024")},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{EncryptionState: "ENCRYPTED", TDEProtector: "tde-2023"},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, scopedDB: tc.fields.scopedDB}
 			got, err := e.Observe(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -266,6 +582,11 @@ func TestObserve(t *testing.T) {
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Database); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
 		})
 	}
 }
@@ -346,6 +667,425 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db       xsql.DB
+		scopedDB xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		err                  error
+		restoredFromSnapshot string
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotDatabase),
+			},
+		},
+		"NoRestore": {
+			reason: "Nothing should be executed when no snapshot should be restored",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrRestore": {
+			reason: "Errors restoring the database should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "RESTORE DATABASE [example] FROM DATABASE_SNAPSHOT = 'example_snapshot'" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{RestoreFromSnapshot: ptr.To("example_snapshot")},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errRestoreDB),
+			},
+		},
+		"Success": {
+			reason: "The database should be reverted to the desired snapshot with exclusive access",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func() func(ctx context.Context, q xsql.Query) error {
+						want := []string{
+							"ALTER DATABASE [example] SET SINGLE_USER WITH ROLLBACK IMMEDIATE",
+							"RESTORE DATABASE [example] FROM DATABASE_SNAPSHOT = 'example_snapshot'",
+							"ALTER DATABASE [example] SET MULTI_USER",
+						}
+						i := 0
+						return func(ctx context.Context, q xsql.Query) error {
+							if i >= len(want) || q.String != want[i] {
+								return errors.Errorf("unexpected query: %s", q.String)
+							}
+							i++
+							return nil
+						}
+					}(),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{RestoreFromSnapshot: ptr.To("example_snapshot")},
+					},
+				},
+			},
+			want: want{
+				restoredFromSnapshot: "example_snapshot",
+			},
+		},
+		"ErrAlterScopedConfiguration": {
+			reason: "Errors altering a database scoped configuration should be returned",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DatabaseScopedConfigurations: map[string]string{"MAXDOP": "4"}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAlterDSC),
+			},
+		},
+		"SuccessScopedConfigurations": {
+			reason: "Only database scoped configurations that differ should be altered",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER DATABASE SCOPED CONFIGURATION SET LEGACY_CARDINALITY_ESTIMATION = ON" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DatabaseScopedConfigurations: map[string]string{
+							"MAXDOP":                        "4",
+							"legacy_cardinality_estimation": "on",
+						}},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{DatabaseScopedConfigurations: map[string]string{
+							"MAXDOP":                        "4",
+							"LEGACY_CARDINALITY_ESTIMATION": "0",
+						}},
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessExtendedProperties": {
+			reason: "Extended properties that are missing should be added, and those that differ updated",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "EXEC sp_updateextendedproperty @name = 'cost-center', @value = '42'",
+							"EXEC sp_addextendedproperty @name = 'team', @value = 'data'":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ExtendedProperties: map[string]string{
+							"cost-center": "42",
+							"env":         "prod",
+							"team":        "data",
+						}},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{ExtendedProperties: map[string]string{
+							"cost-center": "41",
+							"env":         "prod",
+						}},
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessDropExtendedProperties": {
+			reason: "Extended properties that were observed but are no longer desired should be dropped",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "EXEC sp_dropextendedproperty @name = 'team'" {
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ExtendedProperties: map[string]string{"env": "prod"}},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{ExtendedProperties: map[string]string{
+							"env":  "prod",
+							"team": "data",
+						}},
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessSchemaExtendedProperties": {
+			reason: "Extended properties of schemas should be added, updated and dropped",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "EXEC sp_updateextendedproperty @name = 'cost-center', @value = '42', @level0type = N'SCHEMA', @level0name = 'sales'",
+							"EXEC sp_addextendedproperty @name = 'team', @value = 'data', @level0type = N'SCHEMA', @level0name = 'sales'",
+							"EXEC sp_dropextendedproperty @name = 'team', @level0type = N'SCHEMA', @level0name = 'hr'":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{SchemaExtendedProperties: map[string]map[string]string{
+							"sales": {"cost-center": "42", "team": "data"},
+						}},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{SchemaExtendedProperties: map[string]map[string]string{
+							"sales": {"cost-center": "41"},
+							"hr":    {"team": "people"},
+						}},
+					},
+				},
+			},
+			want: want{},
+		},
+		"ErrSetSchemaExtendedProperty": {
+			reason: "Errors setting an extended property of a schema should be returned",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{SchemaExtendedProperties: map[string]map[string]string{
+							"sales": {"team": "data"},
+						}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.Wrapf(errBoom, errSetProp, "team"), errSchemaProps, "sales"),
+			},
+		},
+		"ErrSetExtendedProperty": {
+			reason: "Errors setting an extended property should be returned",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ExtendedProperties: map[string]string{"team": "data"}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errSetProp, "team"),
+			},
+		},
+		"ErrNoEncryptionProtector": {
+			reason: "An error should be returned if a database encryption key should be created without a protector",
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true)},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errNoProtector),
+			},
+		},
+		"ErrCreateEncryptionKey": {
+			reason: "Errors creating the database encryption key should be returned",
+			fields: fields{
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true), TDECertificate: ptr.To("tde")},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateDEK),
+			},
+		},
+		"SuccessEnableEncryption": {
+			reason: "A database encryption key should be created before encryption is turned on",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER DATABASE [example] SET ENCRYPTION ON" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE DATABASE ENCRYPTION KEY WITH ALGORITHM = AES_128 ENCRYPTION BY SERVER ASYMMETRIC KEY [akv-tde]" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							TDEEnabled:       ptr.To(true),
+							TDEAsymmetricKey: ptr.To("akv-tde"),
+							TDEAlgorithm:     ptr.To("AES_128"),
+						},
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessChangeEncryptionProtector": {
+			reason: "The database encryption key should be re-encrypted when its protector changes",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+				scopedDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER DATABASE ENCRYPTION KEY ENCRYPTION BY SERVER CERTIFICATE [tde-2024]" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(true), TDECertificate: ptr.To("tde-2024")},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{EncryptionState: "ENCRYPTED", TDEProtector: "tde-2023"},
+					},
+				},
+			},
+			want: want{},
+		},
+		"ErrDisableEncryption": {
+			reason: "Errors turning encryption off should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{TDEEnabled: ptr.To(false)},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{EncryptionState: "ENCRYPTED", TDEProtector: "tde"},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSetTDE),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, scopedDB: tc.fields.scopedDB}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Database); ok {
+				if diff := cmp.Diff(tc.want.restoredFromSnapshot, cr.Status.AtProvider.RestoredFromSnapshot); diff != "" {
+					t.Errorf("\n%s\ne.Update(...): -want restoredFromSnapshot, +got restoredFromSnapshot:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	errBoom := errors.New("boom")
 
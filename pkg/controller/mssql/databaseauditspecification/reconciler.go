@@ -0,0 +1,318 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaseauditspecification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotSpecification    = "managed resource is not a DatabaseAuditSpecification custom resource"
+	errSelectSpecification = "cannot select database audit specification"
+	errCreateSpecification = "cannot create database audit specification"
+	errAlterSpecification  = "cannot alter database audit specification"
+	errSetState            = "cannot set state of database audit specification"
+	errDropSpecification   = "cannot drop database audit specification"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles DatabaseAuditSpecification managed
+// resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseAuditSpecificationGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseAuditSpecificationGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.DatabaseAuditSpecification{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseAuditSpecificationGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseAuditSpecificationGroupKind))
+}
+
+// NewConnecter returns a connecter for DatabaseAuditSpecification managed
+// resources. It gets ProviderConfigs and credentials using the supplied
+// client, and tracks the usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseAuditSpecification)
+	if !ok {
+		return nil, errors.New(errNotSpecification)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseAuditSpecificationKind, cr)}, nil
+}
+
+type external struct {
+	db xsql.DB
+
+	// addGroups and dropGroups are the audit action groups Observe found
+	// missing from and unwanted in the specification.
+	addGroups  []string
+	dropGroups []string
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseAuditSpecification)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSpecification)
+	}
+
+	var audit, groups string
+	var enabled bool
+	query := "SELECT a.name, s.is_state_enabled, " +
+		"ISNULL((SELECT STRING_AGG(d.audit_action_name, ',') FROM sys.database_audit_specification_details d " +
+		"WHERE d.database_specification_id = s.database_specification_id AND d.audit_action_name LIKE '%[_]GROUP'), '') " +
+		"FROM sys.database_audit_specifications s " +
+		"JOIN sys.server_audits a ON a.audit_guid = s.audit_guid " +
+		"WHERE s.name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &audit, &enabled, &groups)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSpecification)
+	}
+
+	var observed []string
+	if groups != "" {
+		observed = strings.Split(groups, ",")
+		sort.Strings(observed)
+	}
+	c.addGroups, c.dropGroups = diffGroups(observed, cr.Spec.ForProvider.AuditActionGroups)
+
+	cr.Status.AtProvider = v1alpha1.DatabaseAuditSpecificationObservation{
+		ServerAudit:       audit,
+		AuditActionGroups: observed,
+		Enabled:           enabled,
+	}
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		ResourceUpToDate: audit == ptr.Deref(cr.Spec.ForProvider.ServerAudit, "") &&
+			enabled == ptr.Deref(cr.Spec.ForProvider.Enabled, true) &&
+			len(c.addGroups) == 0 && len(c.dropGroups) == 0,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseAuditSpecification)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotSpecification)
+	}
+
+	p := cr.Spec.ForProvider
+	adds := make([]string, 0, len(p.AuditActionGroups))
+	for _, g := range p.AuditActionGroups {
+		adds = append(adds, fmt.Sprintf("ADD (%s)", g))
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE DATABASE AUDIT SPECIFICATION %s FOR SERVER AUDIT %s %s WITH (STATE = %s)",
+			mssql.QuoteIdentifier(meta.GetExternalName(cr)),
+			mssql.QuoteIdentifier(ptr.Deref(p.ServerAudit, "")),
+			strings.Join(adds, ", "),
+			state(ptr.Deref(p.Enabled, true))),
+	})
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateSpecification)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseAuditSpecification)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotSpecification)
+	}
+
+	// A specification must be disabled to alter it.
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	if err := c.setState(ctx, name, false); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	clauses := make([]string, 0, len(c.addGroups)+len(c.dropGroups))
+	for _, g := range c.addGroups {
+		clauses = append(clauses, fmt.Sprintf("ADD (%s)", g))
+	}
+	for _, g := range c.dropGroups {
+		clauses = append(clauses, fmt.Sprintf("DROP (%s)", g))
+	}
+	q := fmt.Sprintf("ALTER DATABASE AUDIT SPECIFICATION %s FOR SERVER AUDIT %s", name,
+		mssql.QuoteIdentifier(ptr.Deref(cr.Spec.ForProvider.ServerAudit, "")))
+	if len(clauses) > 0 {
+		q += " " + strings.Join(clauses, ", ")
+	}
+	if err := c.db.Exec(ctx, xsql.Query{String: q}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterSpecification)
+	}
+
+	if ptr.Deref(cr.Spec.ForProvider.Enabled, true) {
+		return managed.ExternalUpdate{}, c.setState(ctx, name, true)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DatabaseAuditSpecification)
+	if !ok {
+		return errors.New(errNotSpecification)
+	}
+
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	err := c.setState(ctx, name, false)
+	if err == nil {
+		err = errors.Wrap(c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE AUDIT SPECIFICATION " + name}), errDropSpecification)
+	}
+	if mssql.IsUnknownDatabase(err) {
+		// The specification was dropped with its database.
+		return nil
+	}
+	return err
+}
+
+func (c *external) setState(ctx context.Context, name string, on bool) error {
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER DATABASE AUDIT SPECIFICATION %s WITH (STATE = %s)", name, state(on)),
+	})
+	return errors.Wrap(err, errSetState)
+}
+
+func state(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// diffGroups returns the desired audit action groups that were not observed,
+// and the observed groups that are not desired.
+func diffGroups(observed []string, desired []v1alpha1.AuditActionGroup) (add, drop []string) {
+	want := make(map[string]bool, len(desired))
+	for _, g := range desired {
+		want[string(g)] = true
+	}
+	have := make(map[string]bool, len(observed))
+	for _, g := range observed {
+		have[g] = true
+		if !want[g] {
+			drop = append(drop, g)
+		}
+	}
+	for _, g := range desired {
+		if !have[string(g)] {
+			add = append(add, string(g))
+			have[string(g)] = true
+		}
+	}
+	return add, drop
+}
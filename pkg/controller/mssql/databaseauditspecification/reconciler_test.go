@@ -0,0 +1,463 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaseauditspecification
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func specification() *v1alpha1.DatabaseAuditSpecification {
+	return &v1alpha1.DatabaseAuditSpecification{
+		Spec: v1alpha1.DatabaseAuditSpecificationSpec{
+			ForProvider: v1alpha1.DatabaseAuditSpecificationParameters{
+				AuditActionGroups: []v1alpha1.AuditActionGroup{"SCHEMA_OBJECT_ACCESS_GROUP", "DATABASE_ROLE_MEMBER_CHANGE_GROUP"},
+				ServerAudit:       ptr.To("audit"),
+				Database:          ptr.To("example"),
+			},
+		},
+	}
+}
+
+// scanSpecification returns a Scan that observes a specification of the
+// supplied server audit with the supplied audit action groups.
+func scanSpecification(audit, groups string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		*dest[0].(*string) = audit
+		*dest[1].(*bool) = true
+		*dest[2].(*string) = groups
+		return nil
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSpecification": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseAuditSpecification",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSpecification),
+			},
+		},
+		"ErrNoSpecification": {
+			reason: "We should return ResourceExists: false when no database audit specification is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"DatabaseGone": {
+			reason: "We should return ResourceExists: false when the database does not exist",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return mssqldb.Error{Number: 911} },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectSpecification": {
+			reason: "We should return any errors encountered while trying to show the database audit specification",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSpecification),
+			},
+		},
+		"Success": {
+			reason: "We should report the database audit specification as up to date if it audits the desired groups",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanSpecification("audit", "SCHEMA_OBJECT_ACCESS_GROUP,DATABASE_ROLE_MEMBER_CHANGE_GROUP"),
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"GroupsChanged": {
+			reason: "We should report the database audit specification as not up to date if its audit action groups changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanSpecification("audit", "SCHEMA_OBJECT_ACCESS_GROUP,FAILED_DATABASE_AUTHENTICATION_GROUP"),
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"ServerAuditChanged": {
+			reason: "We should report the database audit specification as not up to date if it records to another server audit",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanSpecification("other", "SCHEMA_OBJECT_ACCESS_GROUP,DATABASE_ROLE_MEMBER_CHANGE_GROUP"),
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSpecification": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseAuditSpecification",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSpecification),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the database audit specification should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: errors.Wrap(errBoom, errCreateSpecification),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a database audit specification",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE DATABASE AUDIT SPECIFICATION [] FOR SERVER AUDIT [audit] "+
+							"ADD (SCHEMA_OBJECT_ACCESS_GROUP), ADD (DATABASE_ROLE_MEMBER_CHANGE_GROUP) WITH (STATE = ON)" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestUpdateGroups tests that Update adds and drops the audit action groups
+// found to differ by Observe.
+func TestUpdateGroups(t *testing.T) {
+	var queries []string
+	db := &mockDB{
+		MockScan: scanSpecification("audit", "FAILED_DATABASE_AUTHENTICATION_GROUP,SCHEMA_OBJECT_ACCESS_GROUP"),
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	e := external{db: db}
+	cr := specification()
+
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want ResourceUpToDate: false")
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := []string{
+		"ALTER DATABASE AUDIT SPECIFICATION [] WITH (STATE = OFF)",
+		"ALTER DATABASE AUDIT SPECIFICATION [] FOR SERVER AUDIT [audit] " +
+			"ADD (DATABASE_ROLE_MEMBER_CHANGE_GROUP), DROP (FAILED_DATABASE_AUTHENTICATION_GROUP)",
+		"ALTER DATABASE AUDIT SPECIFICATION [] WITH (STATE = ON)",
+	}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s\n", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSpecification": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseAuditSpecification",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSpecification),
+		},
+		"ErrSetState": {
+			reason: "Any errors encountered while disabling the database audit specification should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: errors.Wrap(errBoom, errSetState),
+		},
+		"ErrAlter": {
+			reason: "Any errors encountered while altering the database audit specification should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "ALTER DATABASE AUDIT SPECIFICATION [] WITH (STATE = OFF)" {
+							return nil
+						}
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: errors.Wrap(errBoom, errAlterSpecification),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSpecification": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseAuditSpecification",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSpecification),
+		},
+		"ErrDropSpecification": {
+			reason: "Errors dropping a database audit specification should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "DROP DATABASE AUDIT SPECIFICATION []" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: errors.Wrap(errBoom, errDropSpecification),
+		},
+		"DatabaseGone": {
+			reason: "No error should be returned if the database of the specification has been dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return mssqldb.Error{Number: 911} },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: nil,
+		},
+		"Success": {
+			reason: "No error should be returned if the database audit specification was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: specification(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaselogin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotDatabaseLogin        = "managed resource is not a DatabaseLogin custom resource"
+	errInvalidName             = "invalid login name"
+	errSelectLogin             = "cannot select login"
+	errSelectUser              = "cannot select user"
+	errCreateLogin             = "cannot create login"
+	errCreateUser              = "cannot create user"
+	errAlterLogin              = "cannot alter login"
+	errAlterUser               = "cannot alter user"
+	errAddMember               = "cannot add user to role %s"
+	errDropMember              = "cannot remove user from role %s"
+	errDropUser                = "cannot drop user"
+	errDropLogin               = "cannot drop login"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	defaultSchema = "dbo"
+
+	maxConcurrency = 5
+)
+
+// defaultRoles are the database roles of a user whose DatabaseLogin sets no
+// roles.
+var defaultRoles = []string{"db_datareader", "db_datawriter"}
+
+// Setup adds a controller that reconciles DatabaseLogin managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseLoginGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseLoginGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.DatabaseLogin{}, &v1alpha1.DatabaseLoginList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.DatabaseLogin)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.DatabaseLogin{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseLoginGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseLoginGroupKind))
+}
+
+// NewConnecter returns a connecter for DatabaseLogin managed resources. It
+// gets ProviderConfigs and credentials using the supplied client, and tracks
+// the usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseLogin)
+	if !ok {
+		return nil, errors.New(errNotDatabaseLogin)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{
+		loginDB: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, "", opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseLoginKind, cr),
+		userDB:  xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseLoginKind, cr),
+		kube:    c.kube,
+	}, nil
+}
+
+type external struct {
+	loginDB xsql.DB
+	userDB  xsql.DB
+	kube    client.Client
+
+	// userExists is false if the last observation found the login but not
+	// its user, which the next update creates.
+	userExists bool
+}
+
+// roles returns the desired database roles of the user of the supplied
+// DatabaseLogin.
+func roles(cr *v1alpha1.DatabaseLogin) []string {
+	if cr.Spec.ForProvider.Roles == nil {
+		return defaultRoles
+	}
+	return cr.Spec.ForProvider.Roles
+}
+
+// diffRoles returns the desired roles the user is not yet a member of, and
+// the observed roles it is no longer to be a member of.
+func diffRoles(desired, observed []string) (add, drop []string) {
+	for _, r := range desired {
+		if !slices.Contains(observed, r) {
+			add = append(add, r)
+		}
+	}
+	for _, r := range observed {
+		if !slices.Contains(desired, r) {
+			drop = append(drop, r)
+		}
+	}
+	return add, drop
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseLogin)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDatabaseLogin)
+	}
+
+	var n int
+	err := c.loginDB.Scan(ctx, xsql.Query{
+		String:     "SELECT 1 FROM sys.sql_logins WHERE name = @p1",
+		Parameters: []interface{}{meta.GetExternalName(cr)},
+	}, &n)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectLogin)
+	}
+
+	var schema, members string
+	query := "SELECT COALESCE(p.default_schema_name, ''), " +
+		"COALESCE(STRING_AGG(r.name, ',') WITHIN GROUP (ORDER BY r.name), '') " +
+		"FROM sys.database_principals p " +
+		"LEFT JOIN sys.database_role_members m ON m.member_principal_id = p.principal_id " +
+		"LEFT JOIN sys.database_principals r ON r.principal_id = m.role_principal_id " +
+		"WHERE p.type = 'S' AND p.name = @p1 " +
+		"GROUP BY p.default_schema_name"
+	err = c.userDB.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr)},
+	}, &schema, &members)
+	c.userExists = err == nil
+	if err != nil && !xsql.IsNoRows(err) && !mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
+	}
+
+	cr.Status.AtProvider.DefaultSchema = schema
+	cr.Status.AtProvider.Roles = nil
+	if members != "" {
+		cr.Status.AtProvider.Roles = strings.Split(members, ",")
+	}
+	cr.SetConditions(xpv1.Available())
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	add, drop := diffRoles(roles(cr), cr.Status.AtProvider.Roles)
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		ResourceUpToDate: c.userExists && !pwdChanged &&
+			schema == ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema) &&
+			len(add) == 0 && len(drop) == 0,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseLogin)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDatabaseLogin)
+	}
+
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mssql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if pw == "" {
+		if pw, err = password.Generate(); err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	query := fmt.Sprintf("CREATE LOGIN %s WITH PASSWORD=%s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteValue(pw))
+	if db := cr.Spec.ForProvider.Database; db != nil {
+		query += ", DEFAULT_DATABASE=" + mssql.QuoteIdentifier(*db)
+	}
+	if err := c.loginDB.Exec(ctx, xsql.Query{String: query}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateLogin)
+	}
+
+	if err := c.createUser(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{ConnectionDetails: c.connectionDetails(cr, pw)}, nil
+}
+
+// createUser creates the user of the supplied login in its database, and
+// adds it to its roles.
+func (c *external) createUser(ctx context.Context, cr *v1alpha1.DatabaseLogin) error {
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	query := fmt.Sprintf("CREATE USER %s FOR LOGIN %s WITH DEFAULT_SCHEMA=%s", name, name,
+		mssql.QuoteIdentifier(ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema)))
+	if err := c.userDB.Exec(ctx, xsql.Query{String: query}); err != nil {
+		return errors.Wrap(err, errCreateUser)
+	}
+	return c.alterRoles(ctx, cr, roles(cr), nil)
+}
+
+// alterRoles adds the user of the supplied login to, and removes it from,
+// the supplied database roles.
+func (c *external) alterRoles(ctx context.Context, cr *v1alpha1.DatabaseLogin, add, drop []string) error {
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	for _, r := range add {
+		if err := c.userDB.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("ALTER ROLE %s ADD MEMBER %s", mssql.QuoteIdentifier(r), name),
+		}); err != nil {
+			return errors.Wrapf(err, errAddMember, r)
+		}
+	}
+	for _, r := range drop {
+		if err := c.userDB.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("ALTER ROLE %s DROP MEMBER %s", mssql.QuoteIdentifier(r), name),
+		}); err != nil {
+			return errors.Wrapf(err, errDropMember, r)
+		}
+	}
+	return nil
+}
+
+// connectionDetails returns the connection details of the supplied login,
+// including its database.
+func (c *external) connectionDetails(cr *v1alpha1.DatabaseLogin, pw string) managed.ConnectionDetails {
+	cd := c.userDB.GetConnectionDetails(meta.GetExternalName(cr), pw)
+	if db := cr.Spec.ForProvider.Database; db != nil {
+		cd[xsql.DatabaseKey] = []byte(*db)
+	}
+	return cd
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseLogin)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDatabaseLogin)
+	}
+
+	pw, changed, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	var cd managed.ConnectionDetails
+	if changed {
+		query := fmt.Sprintf("ALTER LOGIN %s WITH PASSWORD=%s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteValue(pw))
+		if err := c.loginDB.Exec(ctx, xsql.Query{String: query}); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errAlterLogin)
+		}
+		cd = c.connectionDetails(cr, pw)
+	}
+
+	// A user dropped outside of the provider, e.g. with its database, is
+	// recreated for the existing login.
+	if !c.userExists {
+		if err := c.createUser(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+	}
+
+	if s := ptr.Deref(cr.Spec.ForProvider.DefaultSchema, defaultSchema); s != cr.Status.AtProvider.DefaultSchema {
+		query := fmt.Sprintf("ALTER USER %s WITH DEFAULT_SCHEMA=%s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteIdentifier(s))
+		if err := c.userDB.Exec(ctx, xsql.Query{String: query}); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errAlterUser)
+		}
+	}
+
+	add, drop := diffRoles(roles(cr), cr.Status.AtProvider.Roles)
+	if err := c.alterRoles(ctx, cr, add, drop); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DatabaseLogin)
+	if !ok {
+		return errors.New(errNotDatabaseLogin)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	// A database deleted before its users takes them along, so only the
+	// login is left to drop.
+	if err := c.userDB.Exec(ctx, xsql.Query{
+		String: "DROP USER IF EXISTS " + mssql.QuoteIdentifier(meta.GetExternalName(cr)),
+	}); err != nil && !mssql.IsUnknownDatabase(err) {
+		return errors.Wrap(err, errDropUser)
+	}
+
+	if err := c.loginDB.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("IF EXISTS (SELECT 1 FROM sys.sql_logins WHERE name = %s) DROP LOGIN %s",
+			mssql.QuoteValue(meta.GetExternalName(cr)), mssql.QuoteIdentifier(meta.GetExternalName(cr))),
+	}); err != nil {
+		return errors.Wrap(err, errDropLogin)
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaselogin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec   func(ctx context.Context, q xsql.Query) error
+	MockScan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx func(ctx context.Context, ql []xsql.Query) error
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+	}
+}
+
+func login(p v1alpha1.DatabaseLoginParameters) *v1alpha1.DatabaseLogin {
+	cr := &v1alpha1.DatabaseLogin{Spec: v1alpha1.DatabaseLoginSpec{ForProvider: p}}
+	meta.SetExternalName(cr, "app")
+	return cr
+}
+
+// found returns a database client that finds a login, or a user of the
+// supplied default schema and roles.
+func found(schema, roles string) mockDB {
+	return mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if len(dest) == 2 {
+				*dest[0].(*string) = schema
+				*dest[1].(*string) = roles
+			}
+			return nil
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		loginDB xsql.DB
+		userDB  xsql.DB
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseLoginObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		mg     resource.Managed
+		want   want
+	}{
+		"ErrNotDatabaseLogin": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseLogin",
+			mg:     nil,
+			want: want{
+				err: errors.New(errNotDatabaseLogin),
+			},
+		},
+		"NoLogin": {
+			reason: "We should return ResourceExists: false when no login is found",
+			fields: fields{
+				loginDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			mg: login(v1alpha1.DatabaseLoginParameters{}),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectUser": {
+			reason: "We should return any errors encountered while trying to select the user",
+			fields: fields{
+				loginDB: found("", ""),
+				userDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			mg: login(v1alpha1.DatabaseLoginParameters{}),
+			want: want{
+				err: errors.Wrap(errBoom, errSelectUser),
+			},
+		},
+		"NoUser": {
+			reason: "A login without its user should exist but not be up to date",
+			fields: fields{
+				loginDB: found("", ""),
+				userDB: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			mg: login(v1alpha1.DatabaseLoginParameters{}),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"UpToDate": {
+			reason: "A user with the default schema and roles should be up to date",
+			fields: fields{
+				loginDB: found("", ""),
+				userDB:  found("dbo", "db_datareader,db_datawriter"),
+			},
+			mg: login(v1alpha1.DatabaseLoginParameters{}),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.DatabaseLoginObservation{DefaultSchema: "dbo", Roles: []string{"db_datareader", "db_datawriter"}},
+			},
+		},
+		"RolesChanged": {
+			reason: "A user that is a member of other roles should not be up to date",
+			fields: fields{
+				loginDB: found("", ""),
+				userDB:  found("app", "db_datareader,db_owner"),
+			},
+			mg: login(v1alpha1.DatabaseLoginParameters{
+				DefaultSchema: ptr.To("app"),
+				Roles:         []string{"db_datareader"},
+			}),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.DatabaseLoginObservation{DefaultSchema: "app", Roles: []string{"db_datareader", "db_owner"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{loginDB: tc.fields.loginDB, userDB: tc.fields.userDB}
+			got, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.mg.(*v1alpha1.DatabaseLogin); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var queries []string
+	record := mockDB{
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("s3cr3t")}
+			return nil
+		}),
+	}
+	e := external{loginDB: record, userDB: record, kube: kube}
+
+	cr := login(v1alpha1.DatabaseLoginParameters{
+		Database:          ptr.To("example"),
+		PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+	})
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	wantQueries := []string{
+		"CREATE LOGIN [app] WITH PASSWORD='s3cr3t', DEFAULT_DATABASE=[example]",
+		"CREATE USER [app] FOR LOGIN [app] WITH DEFAULT_SCHEMA=[dbo]",
+		"ALTER ROLE [db_datareader] ADD MEMBER [app]",
+		"ALTER ROLE [db_datawriter] ADD MEMBER [app]",
+	}
+	if diff := cmp.Diff(wantQueries, queries); diff != "" {
+		t.Errorf("e.Create(...): -want queries, +got queries:\n%s\n", diff)
+	}
+
+	want := managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte("app"),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte("s3cr3t"),
+		xsql.DatabaseKey:                          []byte("example"),
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.Create(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		userExists bool
+		exec       func(q xsql.Query) error
+	}
+
+	type want struct {
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		mg     *v1alpha1.DatabaseLogin
+		want   want
+	}{
+		"RecreateUser": {
+			reason: "A user missing from the database should be recreated",
+			mg:     login(v1alpha1.DatabaseLoginParameters{Roles: []string{"db_datareader"}}),
+			want: want{
+				queries: []string{
+					"CREATE USER [app] FOR LOGIN [app] WITH DEFAULT_SCHEMA=[dbo]",
+					"ALTER ROLE [db_datareader] ADD MEMBER [app]",
+				},
+			},
+		},
+		"AlterSchemaAndRoles": {
+			reason: "The default schema and roles of an existing user should be brought in line",
+			fields: fields{userExists: true},
+			mg: func() *v1alpha1.DatabaseLogin {
+				cr := login(v1alpha1.DatabaseLoginParameters{DefaultSchema: ptr.To("app"), Roles: []string{"db_datareader"}})
+				cr.Status.AtProvider = v1alpha1.DatabaseLoginObservation{DefaultSchema: "dbo", Roles: []string{"db_owner"}}
+				return cr
+			}(),
+			want: want{
+				queries: []string{
+					"ALTER USER [app] WITH DEFAULT_SCHEMA=[app]",
+					"ALTER ROLE [db_datareader] ADD MEMBER [app]",
+					"ALTER ROLE [db_owner] DROP MEMBER [app]",
+				},
+			},
+		},
+		"ErrDropMember": {
+			reason: "Errors removing the user from a role should be returned",
+			fields: fields{
+				userExists: true,
+				exec:       func(q xsql.Query) error { return errBoom },
+			},
+			mg: func() *v1alpha1.DatabaseLogin {
+				cr := login(v1alpha1.DatabaseLoginParameters{Roles: []string{}})
+				cr.Status.AtProvider = v1alpha1.DatabaseLoginObservation{DefaultSchema: "dbo", Roles: []string{"db_owner"}}
+				return cr
+			}(),
+			want: want{
+				err: errors.Wrapf(errBoom, errDropMember, "db_owner"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db := mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					if tc.fields.exec != nil {
+						return tc.fields.exec(q)
+					}
+					queries = append(queries, q.String)
+					return nil
+				},
+			}
+			e := external{loginDB: db, userDB: db, userExists: tc.fields.userExists}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
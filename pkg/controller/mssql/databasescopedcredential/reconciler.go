@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databasescopedcredential
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotCredential         = "managed resource is not a DatabaseScopedCredential custom resource"
+	errSelectCredential      = "cannot select database scoped credential"
+	errCreateCredential      = "cannot create database scoped credential"
+	errAlterCredential       = "cannot alter database scoped credential"
+	errDropCredential        = "cannot drop database scoped credential"
+	errGetCredentialSecret   = "cannot get credential secret"
+	errNoCredentialSecretKey = "credential secret does not contain key %s"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles DatabaseScopedCredential managed
+// resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseScopedCredentialGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseScopedCredentialGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.DatabaseScopedCredential{}, &v1alpha1.DatabaseScopedCredentialList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.DatabaseScopedCredential)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.SecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.DatabaseScopedCredential{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseScopedCredentialGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseScopedCredentialGroupKind))
+}
+
+// NewConnecter returns a connecter for DatabaseScopedCredential managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseScopedCredential)
+	if !ok {
+		return nil, errors.New(errNotCredential)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseScopedCredentialKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+// getSecret returns the secret of the credential and a hash of it, which is
+// recorded in the status because the secret cannot be read back.
+func (c *external) getSecret(ctx context.Context, cr *v1alpha1.DatabaseScopedCredential) (secret, hash string, err error) {
+	ref := cr.Spec.ForProvider.SecretRef
+	if ref == nil {
+		return "", "", nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", "", errors.Wrap(err, errGetCredentialSecret)
+	}
+	v, ok := s.Data[ref.Key]
+	if !ok {
+		return "", "", errors.Errorf(errNoCredentialSecretKey, ref.Key)
+	}
+	sum := sha256.Sum256(v)
+	return string(v), hex.EncodeToString(sum[:]), nil
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseScopedCredential)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCredential)
+	}
+
+	var identity string
+	query := "SELECT credential_identity FROM sys.database_scoped_credentials WHERE name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &identity)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectCredential)
+	}
+
+	_, hash, err := c.getSecret(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: identity == cr.Spec.ForProvider.Identity && hash == cr.Status.AtProvider.SecretHash,
+	}, nil
+}
+
+// withClause returns the WITH clause shared by CREATE and ALTER.
+func withClause(identity, secret string) string {
+	w := "WITH IDENTITY = " + mssql.QuoteValue(identity)
+	if secret != "" {
+		w += ", SECRET = " + mssql.QuoteValue(secret)
+	}
+	return w
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseScopedCredential)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCredential)
+	}
+
+	secret, hash, err := c.getSecret(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE DATABASE SCOPED CREDENTIAL %s %s",
+			mssql.QuoteIdentifier(meta.GetExternalName(cr)), withClause(cr.Spec.ForProvider.Identity, secret)),
+	}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCredential)
+	}
+
+	cr.Status.AtProvider.SecretHash = hash
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseScopedCredential)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCredential)
+	}
+
+	secret, hash, err := c.getSecret(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER DATABASE SCOPED CREDENTIAL %s %s",
+			mssql.QuoteIdentifier(meta.GetExternalName(cr)), withClause(cr.Spec.ForProvider.Identity, secret)),
+	}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterCredential)
+	}
+
+	cr.Status.AtProvider.SecretHash = hash
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DatabaseScopedCredential)
+	if !ok {
+		return errors.New(errNotCredential)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE SCOPED CREDENTIAL " + mssql.QuoteIdentifier(meta.GetExternalName(cr))})
+	if mssql.IsUnknownDatabase(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropCredential)
+}
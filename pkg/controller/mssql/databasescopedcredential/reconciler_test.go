@@ -0,0 +1,433 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databasescopedcredential
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+// sha256 of "s3cr3t"
+const secretHash = "4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"
+
+func secretClient(value string) client.Client {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			s := corev1.Secret{Data: map[string][]byte{"secret": []byte(value)}}
+			s.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+	}
+}
+
+func credential(hash string) *v1alpha1.DatabaseScopedCredential {
+	return &v1alpha1.DatabaseScopedCredential{
+		Spec: v1alpha1.DatabaseScopedCredentialSpec{
+			ForProvider: v1alpha1.DatabaseScopedCredentialParameters{
+				Identity: "remote",
+				SecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Name: "example"},
+					Key:             "secret",
+				},
+			},
+		},
+		Status: v1alpha1.DatabaseScopedCredentialStatus{
+			AtProvider: v1alpha1.DatabaseScopedCredentialObservation{SecretHash: hash},
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotCredential": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseScopedCredential",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotCredential),
+			},
+		},
+		"ErrNoCredential": {
+			reason: "We should return ResourceExists: false when no credential is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: credential(secretHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectCredential": {
+			reason: "We should return any errors encountered while trying to show the credential",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: credential(secretHash),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectCredential),
+			},
+		},
+		"Success": {
+			reason: "We should report the credential as up to date if identity and secret are unchanged",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "remote"
+						return nil
+					},
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: credential(secretHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"SecretChanged": {
+			reason: "We should report the credential as not up to date if the secret changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "remote"
+						return nil
+					},
+				},
+				kube: secretClient("changed"),
+			},
+			args: args{
+				mg: credential(secretHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		hash string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotCredential": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseScopedCredential",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotCredential),
+			},
+		},
+		"ErrGetSecret": {
+			reason: "Any errors encountered while getting the credential secret should be returned",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			args: args{
+				mg: credential(""),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetCredentialSecret),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the credential should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: credential(""),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateCredential),
+			},
+		},
+		"Success": {
+			reason: "No error should be returned and the secret hash recorded when we successfully create a credential",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if !strings.HasSuffix(q.String, "WITH IDENTITY = 'remote', SECRET = 's3cr3t'") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: credential(""),
+			},
+			want: want{
+				hash: secretHash,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.DatabaseScopedCredential); ok && err == nil {
+				if diff := cmp.Diff(tc.want.hash, cr.Status.AtProvider.SecretHash); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want hash, +got hash:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotCredential": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseScopedCredential",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotCredential),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while altering the credential should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: credential(""),
+			},
+			want: errors.Wrap(errBoom, errAlterCredential),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully alter a credential",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if !strings.HasPrefix(q.String, "ALTER DATABASE SCOPED CREDENTIAL") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: credential(""),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotCredential": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseScopedCredential",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotCredential),
+		},
+		"ErrDropCredential": {
+			reason: "Errors dropping a credential should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.DatabaseScopedCredential{},
+			},
+			want: errors.Wrap(errBoom, errDropCredential),
+		},
+		"Success": {
+			reason: "No error should be returned if the credential was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.DatabaseScopedCredential{},
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
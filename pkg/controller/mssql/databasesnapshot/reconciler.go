@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databasesnapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotSnapshot     = "managed resource is not a DatabaseSnapshot custom resource"
+	errNoDatabase      = "the database to snapshot is not specified"
+	errSelectSnapshot  = "cannot select database snapshot"
+	errNotASnapshot    = "database exists but is not a database snapshot"
+	errSelectFiles     = "cannot select data files of database"
+	errNoFiles         = "cannot find data files of database %s"
+	errCreateSnapshot  = "cannot create database snapshot"
+	errDropSnapshot    = "cannot drop database snapshot"
+	errInvalidFileList = "cannot parse data files of database"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles DatabaseSnapshot managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseSnapshotGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseSnapshotGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.DatabaseSnapshot{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseSnapshotGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseSnapshotGroupKind))
+}
+
+// NewConnecter returns a connecter for DatabaseSnapshot managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseSnapshot)
+	if !ok {
+		return nil, errors.New(errNotSnapshot)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, "", opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseSnapshotKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseSnapshot)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSnapshot)
+	}
+
+	var source string
+	var created time.Time
+	query := "SELECT ISNULL(DB_NAME(source_database_id), ''), create_date FROM master.sys.databases WHERE name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &source, &created)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSnapshot)
+	}
+	if source == "" {
+		return managed.ExternalObservation{}, errors.New(errNotASnapshot)
+	}
+
+	cr.Status.AtProvider = v1alpha1.DatabaseSnapshotObservation{
+		SourceDatabase: source,
+		CreationTime:   ptr.To(metav1.NewTime(created)),
+	}
+	cr.SetConditions(xpv1.Available())
+
+	// A snapshot is read-only, so there is nothing to update.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseSnapshot)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotSnapshot)
+	}
+
+	source := ptr.Deref(cr.Spec.ForProvider.Database, "")
+	if source == "" {
+		return managed.ExternalCreation{}, errors.New(errNoDatabase)
+	}
+
+	// A snapshot needs a sparse file for every data file of its source
+	// database, so we look them up to place the sparse files next to them.
+	var list string
+	query := "SELECT ISNULL(STRING_AGG(CONCAT(name, NCHAR(9), physical_name), NCHAR(10)), '') " +
+		"FROM master.sys.master_files WHERE database_id = DB_ID(@p1) AND type = 0"
+	if err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{source}}, &list); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errSelectFiles)
+	}
+
+	stmt, err := createSnapshotQuery(meta.GetExternalName(cr), source, list)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	err = c.db.Exec(ctx, xsql.Query{String: stmt})
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateSnapshot)
+}
+
+func (c *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	// Database snapshots are read-only and all their parameters are
+	// immutable.
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DatabaseSnapshot)
+	if !ok {
+		return errors.New(errNotSnapshot)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE IF EXISTS " + mssql.QuoteIdentifier(meta.GetExternalName(cr))})
+	return errors.Wrap(err, errDropSnapshot)
+}
+
+// createSnapshotQuery returns the statement creating the snapshot of the
+// source database. The list holds a line with the logical name and the
+// physical path, separated by a tab, for each data file of the source.
+func createSnapshotQuery(snapshot, source, list string) (string, error) {
+	if list == "" {
+		return "", errors.Errorf(errNoFiles, source)
+	}
+
+	lines := strings.Split(list, "\n")
+	files := make([]string, 0, len(lines))
+	for _, l := range lines {
+		name, path, ok := strings.Cut(l, "\t")
+		if !ok {
+			return "", errors.New(errInvalidFileList)
+		}
+		dir := path[:strings.LastIndexAny(path, `\/`)+1]
+		file := fmt.Sprintf("%s%s_%s.ss", dir, snapshot, name)
+		files = append(files, fmt.Sprintf("(NAME = %s, FILENAME = %s)", mssql.QuoteIdentifier(name), mssql.QuoteValue(file)))
+	}
+
+	return fmt.Sprintf("CREATE DATABASE %s ON %s AS SNAPSHOT OF %s",
+		mssql.QuoteIdentifier(snapshot), strings.Join(files, ", "), mssql.QuoteIdentifier(source)), nil
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databasesnapshot
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func snapshot() *v1alpha1.DatabaseSnapshot {
+	return &v1alpha1.DatabaseSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "example_snapshot"},
+		},
+		Spec: v1alpha1.DatabaseSnapshotSpec{
+			ForProvider: v1alpha1.DatabaseSnapshotParameters{Database: ptr.To("example")},
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseSnapshotObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSnapshot": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseSnapshot",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSnapshot),
+			},
+		},
+		"ErrNoSnapshot": {
+			reason: "We should return ResourceExists: false when no snapshot is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectSnapshot": {
+			reason: "We should return any errors encountered while trying to select the snapshot",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSnapshot),
+			},
+		},
+		"ErrNotASnapshot": {
+			reason: "An error should be returned if a database that is not a snapshot has the name of the snapshot",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: errors.New(errNotASnapshot),
+			},
+		},
+		"Success": {
+			reason: "The source and creation time of the snapshot should be reported in its status",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "example"
+						*dest[1].(*time.Time) = created
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.DatabaseSnapshotObservation{
+					SourceDatabase: "example",
+					CreationTime:   ptr.To(metav1.NewTime(created)),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.DatabaseSnapshot); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+				if tc.want.err == nil && tc.want.o.ResourceExists {
+					if diff := cmp.Diff(xpv1.Available(), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+						t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSnapshot": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseSnapshot",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSnapshot),
+			},
+		},
+		"ErrNoDatabase": {
+			reason: "An error should be returned if the database to snapshot is not specified",
+			args: args{
+				mg: &v1alpha1.DatabaseSnapshot{},
+			},
+			want: want{
+				err: errors.New(errNoDatabase),
+			},
+		},
+		"ErrSelectFiles": {
+			reason: "Errors selecting the data files of the database should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectFiles),
+			},
+		},
+		"ErrNoFiles": {
+			reason: "An error should be returned if the database has no data files, i.e. does not exist",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: errors.Errorf(errNoFiles, "example"),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the snapshot should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "example\t/var/opt/mssql/data/example.mdf"
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateSnapshot),
+			},
+		},
+		"Success": {
+			reason: "A sparse file should be created next to each data file of the database",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "example\tC:\\data\\example.mdf\nexample_2\tC:\\data2\\example_2.ndf"
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						want := "CREATE DATABASE [example_snapshot] ON " +
+							"(NAME = [example], FILENAME = 'C:\\data\\example_snapshot_example.ss'), " +
+							"(NAME = [example_2], FILENAME = 'C:\\data2\\example_snapshot_example_2.ss') " +
+							"AS SNAPSHOT OF [example]"
+						if q.String != want {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSnapshot": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseSnapshot",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSnapshot),
+		},
+		"ErrDropSnapshot": {
+			reason: "Errors dropping a snapshot should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: errors.Wrap(errBoom, errDropSnapshot),
+		},
+		"Success": {
+			reason: "No error should be returned if the snapshot was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "DROP DATABASE IF EXISTS [example_snapshot]" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: snapshot(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldatasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotDataSource    = "managed resource is not an ExternalDataSource custom resource"
+	errSelectDataSource = "cannot select external data source"
+	errCreateDataSource = "cannot create external data source"
+	errAlterDataSource  = "cannot alter external data source"
+	errDropDataSource   = "cannot drop external data source"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles ExternalDataSource managed
+// resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.ExternalDataSourceGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ExternalDataSourceGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ExternalDataSource{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.ExternalDataSourceGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.ExternalDataSourceGroupKind))
+}
+
+// NewConnecter returns a connecter for ExternalDataSource managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.ExternalDataSource)
+	if !ok {
+		return nil, errors.New(errNotDataSource)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.ExternalDataSourceKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ExternalDataSource)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDataSource)
+	}
+
+	var location, typ, credential string
+	query := "SELECT ds.location, ds.type_desc, COALESCE(c.name, '') " +
+		"FROM sys.external_data_sources ds " +
+		"LEFT JOIN sys.database_scoped_credentials c ON ds.credential_id = c.credential_id " +
+		"WHERE ds.name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &location, &typ, &credential)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDataSource)
+	}
+
+	cr.Status.AtProvider.Type = typ
+	cr.SetConditions(xpv1.Available())
+
+	// Only the location and credential of an external data source can be
+	// altered, so the remaining parameters are not compared.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: location == cr.Spec.ForProvider.Location && credential == ptr.Deref(cr.Spec.ForProvider.Credential, ""),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ExternalDataSource)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDataSource)
+	}
+
+	p := cr.Spec.ForProvider
+	var opts []string
+	if p.Type != nil {
+		opts = append(opts, "TYPE = "+*p.Type)
+	}
+	opts = append(opts, "LOCATION = "+mssql.QuoteValue(p.Location))
+	if p.DatabaseName != nil {
+		opts = append(opts, "DATABASE_NAME = "+mssql.QuoteValue(*p.DatabaseName))
+	}
+	if p.ShardMapName != nil {
+		opts = append(opts, "SHARD_MAP_NAME = "+mssql.QuoteValue(*p.ShardMapName))
+	}
+	if p.Credential != nil {
+		opts = append(opts, "CREDENTIAL = "+mssql.QuoteIdentifier(*p.Credential))
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE EXTERNAL DATA SOURCE %s WITH (%s)",
+			mssql.QuoteIdentifier(meta.GetExternalName(cr)), strings.Join(opts, ", ")),
+	})
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateDataSource)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ExternalDataSource)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDataSource)
+	}
+
+	p := cr.Spec.ForProvider
+	opts := []string{"LOCATION = " + mssql.QuoteValue(p.Location)}
+	if p.Credential != nil {
+		opts = append(opts, "CREDENTIAL = "+mssql.QuoteIdentifier(*p.Credential))
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER EXTERNAL DATA SOURCE %s SET %s",
+			mssql.QuoteIdentifier(meta.GetExternalName(cr)), strings.Join(opts, ", ")),
+	})
+	return managed.ExternalUpdate{}, errors.Wrap(err, errAlterDataSource)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ExternalDataSource)
+	if !ok {
+		return errors.New(errNotDataSource)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP EXTERNAL DATA SOURCE " + mssql.QuoteIdentifier(meta.GetExternalName(cr))})
+	if mssql.IsUnknownDatabase(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropDataSource)
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldatasource
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func dataSource() *v1alpha1.ExternalDataSource {
+	return &v1alpha1.ExternalDataSource{
+		Spec: v1alpha1.ExternalDataSourceSpec{
+			ForProvider: v1alpha1.ExternalDataSourceParameters{
+				Type:         ptr.To("RDBMS"),
+				Location:     "remote.database.windows.net",
+				DatabaseName: ptr.To("remote"),
+				Credential:   ptr.To("remote-credential"),
+			},
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotDataSource": {
+			reason: "An error should be returned if the managed resource is not an *ExternalDataSource",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotDataSource),
+			},
+		},
+		"ErrNoDataSource": {
+			reason: "We should return ResourceExists: false when no external data source is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectDataSource": {
+			reason: "We should return any errors encountered while trying to show the external data source",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectDataSource),
+			},
+		},
+		"Success": {
+			reason: "We should report the external data source as up to date if location and credential are unchanged",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "remote.database.windows.net"
+						*dest[1].(*string) = "RDBMS"
+						*dest[2].(*string) = "remote-credential"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"LocationChanged": {
+			reason: "We should report the external data source as not up to date if the location changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "old.database.windows.net"
+						*dest[1].(*string) = "RDBMS"
+						*dest[2].(*string) = "remote-credential"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotDataSource": {
+			reason: "An error should be returned if the managed resource is not an *ExternalDataSource",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotDataSource),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the external data source should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: errors.Wrap(errBoom, errCreateDataSource),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create an external data source",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE EXTERNAL DATA SOURCE [] WITH (TYPE = RDBMS, LOCATION = 'remote.database.windows.net', "+
+							"DATABASE_NAME = 'remote', CREDENTIAL = [remote-credential])" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotDataSource": {
+			reason: "An error should be returned if the managed resource is not an *ExternalDataSource",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotDataSource),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while altering the external data source should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: errors.Wrap(errBoom, errAlterDataSource),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully alter an external data source",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER EXTERNAL DATA SOURCE [] SET LOCATION = 'remote.database.windows.net', CREDENTIAL = [remote-credential]" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotDataSource": {
+			reason: "An error should be returned if the managed resource is not an *ExternalDataSource",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotDataSource),
+		},
+		"ErrDropDataSource": {
+			reason: "Errors dropping an external data source should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: errors.Wrap(errBoom, errDropDataSource),
+		},
+		"DatabaseGone": {
+			reason: "No error should be returned if the database of the external data source has been dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return mssqldb.Error{Number: 911} },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: nil,
+		},
+		"Success": {
+			reason: "No error should be returned if the external data source was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: dataSource(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,8 +19,6 @@ package grant
 import (
 	"context"
 	"fmt"
-	"sort"
-	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -33,19 +31,31 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 
 	errNotGrant        = "managed resource is not a Grant custom resource"
 	errGrant           = "cannot grant"
@@ -60,27 +70,38 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.GrantGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newClient: mssql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
 		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
 }
 
 type connector struct {
 	kube      client.Client
 	usage     resource.Tracker
-	newClient func(creds map[string][]byte, database string) xsql.DB
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -99,22 +120,33 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (MSSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
+	opts := mssql.OptionsFromProviderConfig(pc)
+
 	return &external{
-		db:   c.newClient(s.Data, ptr.Deref(cr.Spec.ForProvider.Database, "")),
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr),
 		kube: c.kube,
 	}, nil
 }
@@ -131,6 +163,10 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	permissions, err := c.getPermissions(ctx, cr)
+	if mssql.IsUnknownDatabase(err) {
+		// Permissions are dropped along with their database.
+		return managed.ExternalObservation{}, nil
+	}
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
@@ -140,7 +176,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	cr.SetConditions(xpv1.Available())
 
-	g, r := diffPermissions(cr.Spec.ForProvider.Permissions.ToStringSlice(), permissions)
+	g, r := sqlgen.DiffPermissions(cr.Spec.ForProvider.Permissions.ToStringSlice(), permissions)
 	return managed.ExternalObservation{
 		ResourceExists:   true,
 		ResourceUpToDate: len(g) == 0 && len(r) == 0,
@@ -153,10 +189,7 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotGrant)
 	}
 
-	username := *cr.Spec.ForProvider.User
-	permissions := strings.Join(cr.Spec.ForProvider.Permissions.ToStringSlice(), ", ")
-
-	query := fmt.Sprintf("GRANT %s %s TO %s", permissions, onSchemaQuery(cr), mssql.QuoteIdentifier(username))
+	query := sqlgen.GrantOnQuery(cr.Spec.ForProvider.Permissions.ToStringSlice(), on(cr.Spec.ForProvider), *cr.Spec.ForProvider.User)
 	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: query}), errGrant)
 }
 
@@ -171,20 +204,16 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, err
 	}
 	desired := cr.Spec.ForProvider.Permissions.ToStringSlice()
-	toGrant, toRevoke := diffPermissions(desired, observed)
+	toGrant, toRevoke := sqlgen.DiffPermissions(desired, observed)
 
 	if len(toRevoke) > 0 {
-		sort.Strings(toRevoke)
-		query := fmt.Sprintf("REVOKE %s %s FROM %s",
-			strings.Join(toRevoke, ", "), onSchemaQuery(cr), mssql.QuoteIdentifier(*cr.Spec.ForProvider.User))
+		query := sqlgen.RevokeOnQuery(toRevoke, on(cr.Spec.ForProvider), *cr.Spec.ForProvider.User)
 		if err = c.db.Exec(ctx, xsql.Query{String: query}); err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errRevoke)
 		}
 	}
 	if len(toGrant) > 0 {
-		sort.Strings(toGrant)
-		query := fmt.Sprintf("GRANT %s %s TO %s",
-			strings.Join(toGrant, ", "), onSchemaQuery(cr), mssql.QuoteIdentifier(*cr.Spec.ForProvider.User))
+		query := sqlgen.GrantOnQuery(toGrant, on(cr.Spec.ForProvider), *cr.Spec.ForProvider.User)
 		if err = c.db.Exec(ctx, xsql.Query{String: query}); err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errGrant)
 		}
@@ -198,14 +227,12 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotGrant)
 	}
 
-	username := *cr.Spec.ForProvider.User
-
-	query := fmt.Sprintf("REVOKE %s %s FROM %s",
-		strings.Join(cr.Spec.ForProvider.Permissions.ToStringSlice(), ", "),
-		onSchemaQuery(cr),
-		mssql.QuoteIdentifier(username),
-	)
-	return errors.Wrap(c.db.Exec(ctx, xsql.Query{String: query}), errRevoke)
+	query := sqlgen.RevokeOnQuery(cr.Spec.ForProvider.Permissions.ToStringSlice(), on(cr.Spec.ForProvider), *cr.Spec.ForProvider.User)
+	err := c.db.Exec(ctx, xsql.Query{String: query})
+	if mssql.IsUnknownDatabase(err) {
+		return nil
+	}
+	return errors.Wrap(err, errRevoke)
 }
 
 // TODO(turkenh/ulucinar): Possible performance improvement. We first
@@ -231,15 +258,41 @@ const queryPermissionSchema = `SELECT pe.permission_name
 	  AND s.name = %s
 	  AND pr.name = %s`
 
+const queryPermissionUser = `SELECT pe.permission_name
+	FROM sys.database_principals AS pr
+	JOIN sys.database_permissions AS pe
+	    ON pe.grantee_principal_id = pr.principal_id
+	JOIN sys.database_principals AS u
+	    ON u.principal_id = pe.major_id
+	WHERE
+	      pe.class_desc = 'DATABASE_PRINCIPAL'
+	  AND u.name = %s
+	  AND pr.name = %s`
+
+// on returns the ON clause of the securable the permissions are granted on:
+// a database user, a schema, or the database itself.
+func on(gp v1alpha1.GrantParameters) string {
+	if gp.OnUser != nil {
+		return sqlgen.OnUser(*gp.OnUser)
+	}
+	return sqlgen.OnSchema(gp.Schema)
+}
+
 func (c *external) getPermissions(ctx context.Context, cr *v1alpha1.Grant) ([]string, error) {
 	var query string
-	if cr.Spec.ForProvider.Schema == nil {
-		query = fmt.Sprintf(queryPermissionDefault, mssql.QuoteValue(*cr.Spec.ForProvider.User))
-	} else {
+	switch {
+	case cr.Spec.ForProvider.OnUser != nil:
+		query = fmt.Sprintf(queryPermissionUser,
+			mssql.QuoteValue(*cr.Spec.ForProvider.OnUser),
+			mssql.QuoteValue(*cr.Spec.ForProvider.User),
+		)
+	case cr.Spec.ForProvider.Schema != nil:
 		query = fmt.Sprintf(queryPermissionSchema,
 			mssql.QuoteValue(*cr.Spec.ForProvider.Schema),
 			mssql.QuoteValue(*cr.Spec.ForProvider.User),
 		)
+	default:
+		query = fmt.Sprintf(queryPermissionDefault, mssql.QuoteValue(*cr.Spec.ForProvider.User))
 	}
 	rows, err := c.db.Query(ctx, xsql.Query{String: query})
 	if err != nil {
@@ -260,39 +313,3 @@ func (c *external) getPermissions(ctx context.Context, cr *v1alpha1.Grant) ([]st
 	}
 	return permissions, nil
 }
-
-func onSchemaQuery(cr *v1alpha1.Grant) (schema string) {
-	if cr.Spec.ForProvider.Schema != nil {
-		schema = fmt.Sprintf("ON SCHEMA::%s", *cr.Spec.ForProvider.Schema)
-	}
-	return
-}
-
-func diffPermissions(desired, observed []string) ([]string, []string) {
-	md := make(map[string]struct{}, len(desired))
-	mo := make(map[string]struct{}, len(observed))
-
-	for _, v := range desired {
-		md[v] = struct{}{}
-	}
-	for _, v := range observed {
-		mo[v] = struct{}{}
-	}
-
-	var toGrant []string
-	var toRevoke []string
-
-	for p := range md {
-		if _, ok := mo[p]; !ok {
-			toGrant = append(toGrant, p)
-		}
-	}
-
-	for p := range mo {
-		if _, ok := md[p]; !ok {
-			toRevoke = append(toRevoke, p)
-		}
-	}
-
-	return toGrant, toRevoke
-}
@@ -36,6 +36,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -73,7 +74,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string) xsql.DB
+		newDB func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
 	}
 
 	type args struct {
@@ -332,6 +333,42 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessOnUser": {
+			reason: "We should observe permissions granted on a database user",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if !strings.Contains(q.String, "DATABASE_PRINCIPAL") || !strings.Contains(q.String, "'impersonated-user'") {
+							return nil, errBoom
+						}
+						return mockRowsToSQLRows(
+							sqlmock.NewRows(
+								[]string{"Grants"},
+							).AddRow("IMPERSONATE"),
+						), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:    ptr.To("success-db"),
+							User:        ptr.To("success-user"),
+							OnUser:      ptr.To("impersonated-user"),
+							Permissions: v1alpha1.GrantPermissions{"IMPERSONATE"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
 		"SuccessDiffPermissions": {
 			reason: "We should return no error if different permissions exist",
 			fields: fields{
@@ -520,6 +557,34 @@ func TestCreate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessOnUser": {
+			reason: "No error should be returned when we successfully grant a permission on a user",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "GRANT IMPERSONATE ON USER::[impersonated-user] TO [test-example]" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:    ptr.To("test-example"),
+							User:        ptr.To("test-example"),
+							OnUser:      ptr.To("impersonated-user"),
+							Permissions: v1alpha1.GrantPermissions{"IMPERSONATE"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -798,100 +863,3 @@ func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
 	}
 	return rows
 }
-
-func Test_diffPermissions(t *testing.T) {
-	type args struct {
-		desired  []string
-		observed []string
-	}
-	type want struct {
-		toGrant  []string
-		toRevoke []string
-	}
-	cases := map[string]struct {
-		args
-		want
-	}{
-		"AsDesired": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"CREATE TABLE", "DELETE"},
-			},
-			want: want{
-				toGrant:  nil,
-				toRevoke: nil,
-			},
-		},
-		"AsDesiredOrderNotMatter": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"DELETE", "CREATE TABLE"},
-			},
-			want: want{
-				toGrant:  nil,
-				toRevoke: nil,
-			},
-		},
-		"NeedsGrant": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"CREATE TABLE"},
-			},
-			want: want{
-				toGrant: []string{"DELETE"},
-			},
-		},
-		"NeedsRevoke": {
-			args: args{
-				desired:  []string{"CREATE TABLE"},
-				observed: []string{"CREATE TABLE", "DELETE"},
-			},
-			want: want{
-				toRevoke: []string{"DELETE"},
-			},
-		},
-		"NeedsBoth": {
-			args: args{
-				desired:  []string{"CREATE TABLE"},
-				observed: []string{"DELETE"},
-			},
-			want: want{
-				toGrant:  []string{"CREATE TABLE"},
-				toRevoke: []string{"DELETE"},
-			},
-		},
-		"GrantAll": {
-			args: args{
-				desired: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-			want: want{
-				toGrant: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-		},
-		"RevokeAll": {
-			args: args{
-				observed: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-			want: want{
-				toRevoke: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-		},
-	}
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			gotToGrant, gotToRevoke := diffPermissions(tc.args.desired, tc.args.observed)
-			if diff := cmp.Diff(tc.want.toGrant, gotToGrant, equateSlices()); diff != "" {
-				t.Errorf("\ndiffPermissions(...): -want toGrant, +got toGrant:\n%s", diff)
-			}
-			if diff := cmp.Diff(tc.want.toRevoke, gotToRevoke, equateSlices()); diff != "" {
-				t.Errorf("\ndiffPermissions(...): -want toRevoke, +got toRevoke:\n%s", diff)
-			}
-		})
-	}
-}
-
-func equateSlices() cmp.Option {
-	return cmpopts.SortSlices(func(x, y string) bool {
-		return x < y
-	})
-}
@@ -0,0 +1,324 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linkedserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotLinkedServer    = "managed resource is not a LinkedServer custom resource"
+	errSelectLinkedServer = "cannot select linked server"
+	errAddLinkedServer    = "cannot add linked server"
+	errAddLinkedLogin     = "cannot add linked server login"
+	errDropLinkedServer   = "cannot drop linked server"
+	errGetPasswordSecret  = "cannot get remote password secret"
+	errNoPasswordKey      = "remote password secret does not contain key %s"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles LinkedServer managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.LinkedServerGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.LinkedServerGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.LinkedServer{}, &v1alpha1.LinkedServerList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.LinkedServer)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.RemotePasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.LinkedServer{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.LinkedServerGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.LinkedServerGroupKind))
+}
+
+// NewConnecter returns a connecter for LinkedServer managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.LinkedServer)
+	if !ok {
+		return nil, errors.New(errNotLinkedServer)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, "", opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.LinkedServerKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+// getPassword returns the remote password of the linked server and a hash of
+// it, which is recorded in the status because the password cannot be read
+// back.
+func (c *external) getPassword(ctx context.Context, cr *v1alpha1.LinkedServer) (password, hash string, err error) {
+	ref := cr.Spec.ForProvider.RemotePasswordSecretRef
+	if ref == nil {
+		return "", "", nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", "", errors.Wrap(err, errGetPasswordSecret)
+	}
+	v, ok := s.Data[ref.Key]
+	if !ok {
+		return "", "", errors.Errorf(errNoPasswordKey, ref.Key)
+	}
+	sum := sha256.Sum256(v)
+	return string(v), hex.EncodeToString(sum[:]), nil
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.LinkedServer)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotLinkedServer)
+	}
+
+	observed := v1alpha1.LinkedServerParameters{
+		Product:        new(string),
+		Provider:       new(string),
+		DataSource:     new(string),
+		Catalog:        new(string),
+		ProviderString: new(string),
+		RemoteUser:     new(string),
+	}
+
+	// Logins mapped for all local logins have a local_principal_id of 0.
+	query := "SELECT s.product, s.provider, ISNULL(s.data_source, ''), ISNULL(s.catalog, ''), " +
+		"ISNULL(s.provider_string, ''), ISNULL(l.remote_name, '') " +
+		"FROM sys.servers s " +
+		"LEFT JOIN sys.linked_logins l ON l.server_id = s.server_id AND l.local_principal_id = 0 " +
+		"WHERE s.is_linked = 1 AND s.name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}},
+		observed.Product,
+		observed.Provider,
+		observed.DataSource,
+		observed.Catalog,
+		observed.ProviderString,
+		observed.RemoteUser,
+	)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectLinkedServer)
+	}
+
+	_, hash, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(observed, cr.Spec.ForProvider) && hash == cr.Status.AtProvider.RemotePasswordHash,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.LinkedServer)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotLinkedServer)
+	}
+
+	return managed.ExternalCreation{}, c.add(ctx, cr)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.LinkedServer)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotLinkedServer)
+	}
+
+	// The provider and product of a linked server cannot be changed, so we
+	// drop and add it again.
+	if err := c.drop(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	return managed.ExternalUpdate{}, c.add(ctx, cr)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.LinkedServer)
+	if !ok {
+		return errors.New(errNotLinkedServer)
+	}
+
+	return c.drop(ctx, cr)
+}
+
+func (c *external) add(ctx context.Context, cr *v1alpha1.LinkedServer) error {
+	password, hash, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	p := cr.Spec.ForProvider
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: "EXEC sp_addlinkedserver @server = @p1, @srvproduct = @p2, @provider = @p3, " +
+			"@datasrc = @p4, @catalog = @p5, @provstr = @p6",
+		Parameters: []interface{}{meta.GetExternalName(cr), p.Product, p.Provider, p.DataSource, p.Catalog, p.ProviderString},
+	}); err != nil {
+		return errors.Wrap(err, errAddLinkedServer)
+	}
+
+	if p.RemoteUser != nil {
+		if err := c.db.Exec(ctx, xsql.Query{
+			String:     "EXEC sp_addlinkedsrvlogin @rmtsrvname = @p1, @useself = 'FALSE', @locallogin = NULL, @rmtuser = @p2, @rmtpassword = @p3",
+			Parameters: []interface{}{meta.GetExternalName(cr), *p.RemoteUser, password},
+		}); err != nil {
+			return errors.Wrap(err, errAddLinkedLogin)
+		}
+	}
+
+	cr.Status.AtProvider.RemotePasswordHash = hash
+	return nil
+}
+
+func (c *external) drop(ctx context.Context, cr *v1alpha1.LinkedServer) error {
+	err := c.db.Exec(ctx, xsql.Query{
+		String:     "EXEC sp_dropserver @server = @p1, @droplogins = 'droplogins'",
+		Parameters: []interface{}{meta.GetExternalName(cr)},
+	})
+	return errors.Wrap(err, errDropLinkedServer)
+}
+
+func upToDate(observed, desired v1alpha1.LinkedServerParameters) bool {
+	for _, f := range []struct{ observed, desired *string }{
+		{observed.Product, desired.Product},
+		{observed.Provider, desired.Provider},
+		{observed.DataSource, desired.DataSource},
+		{observed.Catalog, desired.Catalog},
+		{observed.ProviderString, desired.ProviderString},
+	} {
+		if f.desired != nil && *f.desired != *f.observed {
+			return false
+		}
+	}
+	return ptr.Deref(desired.RemoteUser, "") == *observed.RemoteUser
+}
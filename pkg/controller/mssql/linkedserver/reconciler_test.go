@@ -0,0 +1,483 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linkedserver
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+// sha256 of "s3cr3t"
+const passwordHash = "4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"
+
+func secretClient(value string) client.Client {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			s := corev1.Secret{Data: map[string][]byte{"password": []byte(value)}}
+			s.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+	}
+}
+
+func linkedServer(hash string) *v1alpha1.LinkedServer {
+	return &v1alpha1.LinkedServer{
+		Spec: v1alpha1.LinkedServerSpec{
+			ForProvider: v1alpha1.LinkedServerParameters{
+				Provider:   ptr.To("MSOLEDBSQL"),
+				DataSource: ptr.To("reporting.example.org"),
+				RemoteUser: ptr.To("reporter"),
+				RemotePasswordSecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Name: "example"},
+					Key:             "password",
+				},
+			},
+		},
+		Status: v1alpha1.LinkedServerStatus{
+			AtProvider: v1alpha1.LinkedServerObservation{RemotePasswordHash: hash},
+		},
+	}
+}
+
+func observe(provider, dataSource, remoteUser string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		*dest[1].(*string) = provider
+		*dest[2].(*string) = dataSource
+		*dest[5].(*string) = remoteUser
+		return nil
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotLinkedServer": {
+			reason: "An error should be returned if the managed resource is not a *LinkedServer",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotLinkedServer),
+			},
+		},
+		"ErrNoLinkedServer": {
+			reason: "We should return ResourceExists: false when no linked server is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectLinkedServer": {
+			reason: "We should return any errors encountered while trying to select the linked server",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectLinkedServer),
+			},
+		},
+		"Success": {
+			reason: "We should report the linked server as up to date if nothing changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe("MSOLEDBSQL", "reporting.example.org", "reporter"),
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"DataSourceChanged": {
+			reason: "We should report the linked server as not up to date if the data source drifted",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe("MSOLEDBSQL", "other.example.org", "reporter"),
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"RemoteUserChanged": {
+			reason: "We should report the linked server as not up to date if the remote user drifted",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe("MSOLEDBSQL", "reporting.example.org", ""),
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"PasswordChanged": {
+			reason: "We should report the linked server as not up to date if the remote password changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe("MSOLEDBSQL", "reporting.example.org", "reporter"),
+				},
+				kube: secretClient("changed"),
+			},
+			args: args{
+				mg: linkedServer(passwordHash),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		hash string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotLinkedServer": {
+			reason: "An error should be returned if the managed resource is not a *LinkedServer",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotLinkedServer),
+			},
+		},
+		"ErrGetSecret": {
+			reason: "An error should be returned if the remote password secret cannot be read",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetPasswordSecret),
+			},
+		},
+		"ErrAddLinkedServer": {
+			reason: "Any errors encountered while adding the linked server should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAddLinkedServer),
+			},
+		},
+		"ErrAddLinkedLogin": {
+			reason: "Any errors encountered while adding the linked server login should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.Contains(q.String, "sp_addlinkedsrvlogin") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAddLinkedLogin),
+			},
+		},
+		"Success": {
+			reason: "The hash of the remote password should be recorded when the linked server is added",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.Contains(q.String, "sp_addlinkedsrvlogin") && q.Parameters[2] != "s3cr3t" {
+							return errors.New("unexpected remote password")
+						}
+						return nil
+					},
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: want{
+				hash: passwordHash,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.LinkedServer); ok && err == nil {
+				if diff := cmp.Diff(tc.want.hash, cr.Status.AtProvider.RemotePasswordHash); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want hash, +got hash:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotLinkedServer": {
+			reason: "An error should be returned if the managed resource is not a *LinkedServer",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotLinkedServer),
+		},
+		"ErrDrop": {
+			reason: "Any errors encountered while dropping the linked server should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: errors.Wrap(errBoom, errDropLinkedServer),
+		},
+		"Success": {
+			reason: "The linked server should be dropped and added again",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+				kube: secretClient("s3cr3t"),
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotLinkedServer": {
+			reason: "An error should be returned if the managed resource is not a *LinkedServer",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotLinkedServer),
+		},
+		"ErrDropLinkedServer": {
+			reason: "Errors dropping a linked server should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: errors.Wrap(errBoom, errDropLinkedServer),
+		},
+		"Success": {
+			reason: "No error should be returned if the linked server was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: linkedServer(""),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
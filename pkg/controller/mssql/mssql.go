@@ -18,12 +18,27 @@ package mssql
 
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/applicationrole"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/config"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/database"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/databaseauditspecification"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/databaselogin"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/databasescopedcredential"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/databasesnapshot"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/externaldatasource"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/grant"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/linkedserver"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/sequence"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/serveraudit"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/synonym"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql/user"
 )
 
@@ -35,6 +50,16 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		database.Setup,
 		user.Setup,
 		grant.Setup,
+		databasescopedcredential.Setup,
+		databasesnapshot.Setup,
+		externaldatasource.Setup,
+		linkedserver.Setup,
+		serveraudit.Setup,
+		databaseauditspecification.Setup,
+		databaselogin.Setup,
+		applicationrole.Setup,
+		sequence.Setup,
+		synonym.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
@@ -42,3 +67,23 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	}
 	return nil
 }
+
+// Connecters returns the constructors of the connecters of all MSSQL
+// managed resources, keyed by their group kind.
+func Connecters() map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter {
+	return map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter{
+		v1alpha1.ApplicationRoleGroupKind:            applicationrole.NewConnecter,
+		v1alpha1.DatabaseGroupKind:                   database.NewConnecter,
+		v1alpha1.DatabaseAuditSpecificationGroupKind: databaseauditspecification.NewConnecter,
+		v1alpha1.DatabaseLoginGroupKind:              databaselogin.NewConnecter,
+		v1alpha1.DatabaseScopedCredentialGroupKind:   databasescopedcredential.NewConnecter,
+		v1alpha1.DatabaseSnapshotGroupKind:           databasesnapshot.NewConnecter,
+		v1alpha1.ExternalDataSourceGroupKind:         externaldatasource.NewConnecter,
+		v1alpha1.GrantGroupKind:                      grant.NewConnecter,
+		v1alpha1.LinkedServerGroupKind:               linkedserver.NewConnecter,
+		v1alpha1.SequenceGroupKind:                   sequence.NewConnecter,
+		v1alpha1.ServerAuditGroupKind:                serveraudit.NewConnecter,
+		v1alpha1.SynonymGroupKind:                    synonym.NewConnecter,
+		v1alpha1.UserGroupKind:                       user.NewConnecter,
+	}
+}
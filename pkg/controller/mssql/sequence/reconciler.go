@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sequence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotSequence    = "managed resource is not a Sequence custom resource"
+	errSelectSequence = "cannot select sequence"
+	errCreateSequence = "cannot create sequence"
+	errAlterSequence  = "cannot alter sequence"
+	errDropSequence   = "cannot drop sequence"
+
+	defaultSchema = "dbo"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Sequence managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.SequenceGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.SequenceGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Sequence{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.SequenceGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.SequenceGroupKind))
+}
+
+// NewConnecter returns a connecter for Sequence managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Sequence)
+	if !ok {
+		return nil, errors.New(errNotSequence)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.SequenceKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// sequenceName returns the quoted, schema qualified name of the supplied
+// Sequence.
+func sequenceName(cr *v1alpha1.Sequence) string {
+	return mssql.QuoteIdentifier(ptr.Deref(cr.Spec.ForProvider.Schema, defaultSchema)) + "." + mssql.QuoteIdentifier(meta.GetExternalName(cr))
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Sequence)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSequence)
+	}
+
+	var observed v1alpha1.SequenceObservation
+	var cached bool
+	var cacheSize sql.NullInt64
+	query := "SELECT TYPE_NAME(s.user_type_id), CAST(s.increment AS bigint), " +
+		"CAST(s.minimum_value AS bigint), CAST(s.maximum_value AS bigint), s.is_cycling, " +
+		"s.is_cached, s.cache_size, CAST(s.current_value AS bigint) " +
+		"FROM sys.sequences s WHERE s.name = @p1 AND SCHEMA_NAME(s.schema_id) = @p2"
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr), ptr.Deref(cr.Spec.ForProvider.Schema, defaultSchema)},
+	}, &observed.DataType, &observed.IncrementBy, &observed.MinValue, &observed.MaxValue, &observed.Cycle, &cached, &cacheSize, &observed.CurrentValue)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSequence)
+	}
+
+	switch {
+	case !cached:
+		observed.Cache = ptr.To[int64](0)
+	case cacheSize.Valid:
+		observed.Cache = ptr.To(cacheSize.Int64)
+	}
+	cr.Status.AtProvider = observed
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(cr.Spec.ForProvider, observed),
+	}, nil
+}
+
+// upToDate returns true if the alterable parameters that are set match the
+// observed sequence. The data type and start value cannot be altered.
+func upToDate(p v1alpha1.SequenceParameters, o v1alpha1.SequenceObservation) bool {
+	switch {
+	case p.IncrementBy != nil && *p.IncrementBy != o.IncrementBy:
+		return false
+	case p.MinValue != nil && *p.MinValue != o.MinValue:
+		return false
+	case p.MaxValue != nil && *p.MaxValue != o.MaxValue:
+		return false
+	case p.Cycle != nil && *p.Cycle != o.Cycle:
+		return false
+	case p.Cache != nil && (o.Cache == nil || *p.Cache != *o.Cache):
+		return false
+	}
+	return true
+}
+
+// alterableOptions returns the options of the supplied parameters that can
+// be set by both CREATE SEQUENCE and ALTER SEQUENCE.
+func alterableOptions(p v1alpha1.SequenceParameters) []string {
+	var opts []string
+	if p.IncrementBy != nil {
+		opts = append(opts, fmt.Sprintf("INCREMENT BY %d", *p.IncrementBy))
+	}
+	if p.MinValue != nil {
+		opts = append(opts, fmt.Sprintf("MINVALUE %d", *p.MinValue))
+	}
+	if p.MaxValue != nil {
+		opts = append(opts, fmt.Sprintf("MAXVALUE %d", *p.MaxValue))
+	}
+	switch {
+	case p.Cycle == nil:
+	case *p.Cycle:
+		opts = append(opts, "CYCLE")
+	default:
+		opts = append(opts, "NO CYCLE")
+	}
+	switch {
+	case p.Cache == nil:
+	case *p.Cache == 0:
+		opts = append(opts, "NO CACHE")
+	default:
+		opts = append(opts, fmt.Sprintf("CACHE %d", *p.Cache))
+	}
+	return opts
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Sequence)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotSequence)
+	}
+
+	p := cr.Spec.ForProvider
+	opts := []string{"AS " + ptr.Deref(p.DataType, "bigint")}
+	if p.StartWith != nil {
+		opts = append(opts, fmt.Sprintf("START WITH %d", *p.StartWith))
+	}
+	opts = append(opts, alterableOptions(p)...)
+
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE SEQUENCE %s %s", sequenceName(cr), strings.Join(opts, " ")),
+	})
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateSequence)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Sequence)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotSequence)
+	}
+
+	opts := alterableOptions(cr.Spec.ForProvider)
+	if len(opts) == 0 {
+		return managed.ExternalUpdate{}, nil
+	}
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER SEQUENCE %s %s", sequenceName(cr), strings.Join(opts, " ")),
+	})
+	return managed.ExternalUpdate{}, errors.Wrap(err, errAlterSequence)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Sequence)
+	if !ok {
+		return errors.New(errNotSequence)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP SEQUENCE IF EXISTS " + sequenceName(cr)})
+	if mssql.IsUnknownDatabase(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropSequence)
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sequence
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func sequence() *v1alpha1.Sequence {
+	cr := &v1alpha1.Sequence{
+		Spec: v1alpha1.SequenceSpec{
+			ForProvider: v1alpha1.SequenceParameters{
+				Schema:      ptr.To("sales"),
+				DataType:    ptr.To("int"),
+				StartWith:   ptr.To[int64](1000),
+				IncrementBy: ptr.To[int64](10),
+				Cycle:       ptr.To(false),
+				Cache:       ptr.To[int64](0),
+			},
+		},
+	}
+	meta.SetExternalName(cr, "order_number")
+	return cr
+}
+
+// observe returns a Scan function reporting a sequence with the supplied
+// increment and cache. A negative cache size reports a sequence whose
+// cache size is chosen by the server.
+func observe(increment int64, cached bool, cacheSize int64) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		*dest[0].(*string) = "int"
+		*dest[1].(*int64) = increment
+		*dest[2].(*int64) = -2147483648
+		*dest[3].(*int64) = 2147483647
+		*dest[4].(*bool) = false
+		*dest[5].(*bool) = cached
+		*dest[6].(*sql.NullInt64) = sql.NullInt64{Int64: cacheSize, Valid: cacheSize >= 0}
+		*dest[7].(*int64) = 1040
+		return nil
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSequence": {
+			reason: "An error should be returned if the managed resource is not a *Sequence",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSequence),
+			},
+		},
+		"ErrNoSequence": {
+			reason: "We should return ResourceExists: false when no sequence is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectSequence": {
+			reason: "We should return any errors encountered while trying to select the sequence",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSequence),
+			},
+		},
+		"Success": {
+			reason: "We should report the sequence as up to date if the parameters that are set are unchanged",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(10, false, -1),
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"IncrementChanged": {
+			reason: "We should report the sequence as not up to date if its increment changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(1, false, -1),
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"CacheChanged": {
+			reason: "We should report the sequence as not up to date if it is cached but should not be",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(10, true, -1),
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSequence": {
+			reason: "An error should be returned if the managed resource is not a *Sequence",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSequence),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the sequence should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: errors.Wrap(errBoom, errCreateSequence),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a sequence",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE SEQUENCE [sales].[order_number] AS int START WITH 1000 INCREMENT BY 10 NO CYCLE NO CACHE" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSequence": {
+			reason: "An error should be returned if the managed resource is not a *Sequence",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSequence),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while altering the sequence should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: errors.Wrap(errBoom, errAlterSequence),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully alter a sequence",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER SEQUENCE [sales].[order_number] INCREMENT BY 10 NO CYCLE NO CACHE" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSequence": {
+			reason: "An error should be returned if the managed resource is not a *Sequence",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSequence),
+		},
+		"ErrDropSequence": {
+			reason: "Errors dropping a sequence should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: errors.Wrap(errBoom, errDropSequence),
+		},
+		"DatabaseGone": {
+			reason: "No error should be returned if the database of the sequence has been dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return mssqldb.Error{Number: 911} },
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: nil,
+		},
+		"Success": {
+			reason: "No error should be returned if the sequence was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "DROP SEQUENCE IF EXISTS [sales].[order_number]" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: sequence(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serveraudit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotServerAudit    = "managed resource is not a ServerAudit custom resource"
+	errSelectServerAudit = "cannot select server audit"
+	errCreateServerAudit = "cannot create server audit"
+	errAlterServerAudit  = "cannot alter server audit"
+	errSetState          = "cannot set state of server audit"
+	errDropServerAudit   = "cannot drop server audit"
+
+	maxConcurrency = 5
+
+	// unlimitedRolloverFiles is the max_rollover_files of a file audit
+	// whose number of files is unlimited.
+	unlimitedRolloverFiles = 2147483647
+)
+
+var (
+	// destinations maps the type_desc of sys.server_audits to destinations.
+	destinations = map[string]string{
+		"FILE":            "FILE",
+		"APPLICATION LOG": "APPLICATION_LOG",
+		"SECURITY LOG":    "SECURITY_LOG",
+	}
+
+	// onFailures maps the on_failure_desc of sys.server_audits to the
+	// ON_FAILURE options.
+	onFailures = map[string]string{
+		"CONTINUE":                 "CONTINUE",
+		"SHUTDOWN SERVER INSTANCE": "SHUTDOWN",
+		"FAIL OPERATION":           "FAIL_OPERATION",
+	}
+)
+
+// Setup adds a controller that reconciles ServerAudit managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.ServerAuditGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ServerAuditGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ServerAudit{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.ServerAuditGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.ServerAuditGroupKind))
+}
+
+// NewConnecter returns a connecter for ServerAudit managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.ServerAudit)
+	if !ok {
+		return nil, errors.New(errNotServerAudit)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{
+		db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, "", opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.ServerAuditKind, cr),
+	}, nil
+}
+
+type external struct {
+	db xsql.DB
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ServerAudit)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotServerAudit)
+	}
+
+	var destination, onFailure, guid, filePath string
+	var queueDelay, maxRolloverFiles int32
+	var maxSizeMB int64
+	var enabled bool
+
+	query := "SELECT a.type_desc, a.queue_delay, a.on_failure_desc, a.is_state_enabled, " +
+		"CONVERT(nvarchar(36), a.audit_guid), ISNULL(f.log_file_path, ''), " +
+		"ISNULL(f.max_file_size, 0), ISNULL(f.max_rollover_files, 0) " +
+		"FROM sys.server_audits a " +
+		"LEFT JOIN sys.server_file_audits f ON f.audit_id = a.audit_id " +
+		"WHERE a.name = @p1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}},
+		&destination, &queueDelay, &onFailure, &enabled, &guid, &filePath, &maxSizeMB, &maxRolloverFiles)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectServerAudit)
+	}
+	if maxRolloverFiles == unlimitedRolloverFiles {
+		maxRolloverFiles = 0
+	}
+
+	cr.Status.AtProvider = v1alpha1.ServerAuditObservation{AuditGUID: guid, Enabled: enabled}
+	cr.SetConditions(xpv1.Available())
+
+	observed := v1alpha1.ServerAuditParameters{
+		Destination:      destinations[destination],
+		FilePath:         &filePath,
+		MaxSizeMB:        &maxSizeMB,
+		MaxRolloverFiles: &maxRolloverFiles,
+		QueueDelay:       &queueDelay,
+		OnFailure:        ptr.To(onFailures[onFailure]),
+		Enabled:          &enabled,
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(observed, cr.Spec.ForProvider),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ServerAudit)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotServerAudit)
+	}
+
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE SERVER AUDIT %s %s %s", name, target(cr.Spec.ForProvider), options(cr.Spec.ForProvider)),
+	}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateServerAudit)
+	}
+
+	// Audits are created disabled.
+	if ptr.Deref(cr.Spec.ForProvider.Enabled, true) {
+		return managed.ExternalCreation{}, c.setState(ctx, name, true)
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ServerAudit)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotServerAudit)
+	}
+
+	// An audit must be disabled to alter it, and the statements that do so
+	// can't be run in a transaction.
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	if err := c.setState(ctx, name, false); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER SERVER AUDIT %s %s %s", name, target(cr.Spec.ForProvider), options(cr.Spec.ForProvider)),
+	}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterServerAudit)
+	}
+	if ptr.Deref(cr.Spec.ForProvider.Enabled, true) {
+		return managed.ExternalUpdate{}, c.setState(ctx, name, true)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ServerAudit)
+	if !ok {
+		return errors.New(errNotServerAudit)
+	}
+
+	name := mssql.QuoteIdentifier(meta.GetExternalName(cr))
+	if err := c.setState(ctx, name, false); err != nil {
+		return err
+	}
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP SERVER AUDIT " + name})
+	return errors.Wrap(err, errDropServerAudit)
+}
+
+func (c *external) setState(ctx context.Context, name string, on bool) error {
+	state := "OFF"
+	if on {
+		state = "ON"
+	}
+	err := c.db.Exec(ctx, xsql.Query{String: fmt.Sprintf("ALTER SERVER AUDIT %s WITH (STATE = %s)", name, state)})
+	return errors.Wrap(err, errSetState)
+}
+
+// target returns the TO clause of the supplied audit.
+func target(p v1alpha1.ServerAuditParameters) string {
+	if p.Destination != "FILE" {
+		return "TO " + p.Destination
+	}
+	maxSize, maxFiles := "UNLIMITED", "UNLIMITED"
+	if s := ptr.Deref(p.MaxSizeMB, 0); s > 0 {
+		maxSize = strconv.FormatInt(s, 10) + " MB"
+	}
+	if n := ptr.Deref(p.MaxRolloverFiles, 0); n > 0 {
+		maxFiles = strconv.FormatInt(int64(n), 10)
+	}
+	return fmt.Sprintf("TO FILE (FILEPATH = %s, MAXSIZE = %s, MAX_ROLLOVER_FILES = %s)",
+		mssql.QuoteValue(ptr.Deref(p.FilePath, "")), maxSize, maxFiles)
+}
+
+// options returns the WITH clause of the supplied audit.
+func options(p v1alpha1.ServerAuditParameters) string {
+	return fmt.Sprintf("WITH (QUEUE_DELAY = %d, ON_FAILURE = %s)", ptr.Deref(p.QueueDelay, 1000), ptr.Deref(p.OnFailure, "CONTINUE"))
+}
+
+func upToDate(observed, desired v1alpha1.ServerAuditParameters) bool {
+	if observed.Destination != desired.Destination ||
+		*observed.QueueDelay != ptr.Deref(desired.QueueDelay, 1000) ||
+		*observed.OnFailure != ptr.Deref(desired.OnFailure, "CONTINUE") ||
+		*observed.Enabled != ptr.Deref(desired.Enabled, true) {
+		return false
+	}
+	if desired.Destination != "FILE" {
+		return true
+	}
+	// The server reports the file path with a trailing separator.
+	return strings.TrimRight(*observed.FilePath, `\/`) == strings.TrimRight(ptr.Deref(desired.FilePath, ""), `\/`) &&
+		*observed.MaxSizeMB == ptr.Deref(desired.MaxSizeMB, 0) &&
+		*observed.MaxRolloverFiles == ptr.Deref(desired.MaxRolloverFiles, 0)
+}
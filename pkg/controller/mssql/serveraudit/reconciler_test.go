@@ -0,0 +1,490 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serveraudit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func serverAudit() *v1alpha1.ServerAudit {
+	return &v1alpha1.ServerAudit{
+		Spec: v1alpha1.ServerAuditSpec{
+			ForProvider: v1alpha1.ServerAuditParameters{
+				Destination:      "FILE",
+				FilePath:         ptr.To(`D:\Audit`),
+				MaxSizeMB:        ptr.To[int64](100),
+				MaxRolloverFiles: ptr.To[int32](10),
+			},
+		},
+	}
+}
+
+// scanAudit returns a Scan that observes a file audit with the supplied path
+// and state.
+func scanAudit(path string, enabled bool) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		*dest[0].(*string) = "FILE"
+		*dest[1].(*int32) = 1000
+		*dest[2].(*string) = "CONTINUE"
+		*dest[3].(*bool) = enabled
+		*dest[4].(*string) = "6a4a2f42-4bd2-4c5b-9b8e-3b9f0b1c2d3e"
+		*dest[5].(*string) = path
+		*dest[6].(*int64) = 100
+		*dest[7].(*int32) = 10
+		return nil
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotServerAudit": {
+			reason: "An error should be returned if the managed resource is not a *ServerAudit",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotServerAudit),
+			},
+		},
+		"ErrNoServerAudit": {
+			reason: "We should return ResourceExists: false when no server audit is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectServerAudit": {
+			reason: "We should return any errors encountered while trying to show the server audit",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectServerAudit),
+			},
+		},
+		"Success": {
+			reason: "We should report the server audit as up to date if the server reports its path with a trailing separator",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanAudit(`D:\Audit\`, true),
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"Disabled": {
+			reason: "We should report the server audit as not up to date if it was disabled out of band",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanAudit(`D:\Audit\`, false),
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"FilePathChanged": {
+			reason: "We should report the server audit as not up to date if its file path changed",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanAudit(`E:\Audit\`, true),
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		err     error
+		queries []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotServerAudit": {
+			reason: "An error should be returned if the managed resource is not a *ServerAudit",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotServerAudit),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the server audit should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				err:     errors.Wrap(errBoom, errCreateServerAudit),
+				queries: []string{"CREATE SERVER AUDIT [] TO FILE (FILEPATH = 'D:\\Audit', MAXSIZE = 100 MB, MAX_ROLLOVER_FILES = 10) WITH (QUEUE_DELAY = 1000, ON_FAILURE = CONTINUE)"},
+			},
+		},
+		"Success": {
+			reason: "A server audit should be created and then enabled",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				queries: []string{
+					"CREATE SERVER AUDIT [] TO FILE (FILEPATH = 'D:\\Audit', MAXSIZE = 100 MB, MAX_ROLLOVER_FILES = 10) WITH (QUEUE_DELAY = 1000, ON_FAILURE = CONTINUE)",
+					"ALTER SERVER AUDIT [] WITH (STATE = ON)",
+				},
+			},
+		},
+		"SuccessDisabledLog": {
+			reason: "A disabled server audit to the application log should be created and left disabled",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.ServerAudit{
+					Spec: v1alpha1.ServerAuditSpec{
+						ForProvider: v1alpha1.ServerAuditParameters{
+							Destination: "APPLICATION_LOG",
+							OnFailure:   ptr.To("FAIL_OPERATION"),
+							Enabled:     ptr.To(false),
+						},
+					},
+				},
+			},
+			want: want{
+				queries: []string{"CREATE SERVER AUDIT [] TO APPLICATION_LOG WITH (QUEUE_DELAY = 1000, ON_FAILURE = FAIL_OPERATION)"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			if db, ok := tc.fields.db.(*mockDB); ok {
+				exec := db.MockExec
+				db.MockExec = func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return exec(ctx, q)
+				}
+			}
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		err     error
+		queries []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotServerAudit": {
+			reason: "An error should be returned if the managed resource is not a *ServerAudit",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotServerAudit),
+			},
+		},
+		"ErrAlter": {
+			reason: "Any errors encountered while altering the server audit should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "ALTER SERVER AUDIT [] WITH (STATE = OFF)" {
+							return nil
+						}
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAlterServerAudit),
+				queries: []string{
+					"ALTER SERVER AUDIT [] WITH (STATE = OFF)",
+					"ALTER SERVER AUDIT [] TO FILE (FILEPATH = 'D:\\Audit', MAXSIZE = 100 MB, MAX_ROLLOVER_FILES = 10) WITH (QUEUE_DELAY = 1000, ON_FAILURE = CONTINUE)",
+				},
+			},
+		},
+		"Success": {
+			reason: "A server audit should be disabled, altered and enabled again",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: want{
+				queries: []string{
+					"ALTER SERVER AUDIT [] WITH (STATE = OFF)",
+					"ALTER SERVER AUDIT [] TO FILE (FILEPATH = 'D:\\Audit', MAXSIZE = 100 MB, MAX_ROLLOVER_FILES = 10) WITH (QUEUE_DELAY = 1000, ON_FAILURE = CONTINUE)",
+					"ALTER SERVER AUDIT [] WITH (STATE = ON)",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			if db, ok := tc.fields.db.(*mockDB); ok {
+				exec := db.MockExec
+				db.MockExec = func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return exec(ctx, q)
+				}
+			}
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotServerAudit": {
+			reason: "An error should be returned if the managed resource is not a *ServerAudit",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotServerAudit),
+		},
+		"ErrSetState": {
+			reason: "Errors disabling a server audit should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: errors.Wrap(errBoom, errSetState),
+		},
+		"ErrDropServerAudit": {
+			reason: "Errors dropping a server audit should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == "DROP SERVER AUDIT []" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: errors.Wrap(errBoom, errDropServerAudit),
+		},
+		"Success": {
+			reason: "No error should be returned if the server audit was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: serverAudit(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
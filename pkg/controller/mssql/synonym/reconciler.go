@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synonym
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotSynonym    = "managed resource is not a Synonym custom resource"
+	errSelectSynonym = "cannot select synonym"
+	errCreateSynonym = "cannot create synonym"
+	errAlterSynonym  = "cannot recreate synonym"
+	errDropSynonym   = "cannot drop synonym"
+
+	defaultSchema = "dbo"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Synonym managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.SynonymGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.SynonymGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Synonym{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.SynonymGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.SynonymGroupKind))
+}
+
+// NewConnecter returns a connecter for Synonym managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
+}
+
+type connector struct {
+	kube      client.Client
+	usage     resource.Tracker
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Synonym)
+	if !ok {
+		return nil, errors.New(errNotSynonym)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.SynonymKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// synonymName returns the quoted, schema qualified name of the supplied
+// Synonym.
+func synonymName(cr *v1alpha1.Synonym) string {
+	return mssql.QuoteIdentifier(ptr.Deref(cr.Spec.ForProvider.Schema, defaultSchema)) + "." + mssql.QuoteIdentifier(meta.GetExternalName(cr))
+}
+
+// baseObjectName returns the quoted name of the object the supplied
+// parameters refer to, as reported by sys.synonyms.
+func baseObjectName(p v1alpha1.SynonymParameters) string {
+	var parts []string
+	if p.TargetServer != nil {
+		parts = append(parts, *p.TargetServer)
+	}
+	if p.TargetDatabase != nil {
+		parts = append(parts, *p.TargetDatabase)
+	}
+	parts = append(parts, ptr.Deref(p.TargetSchema, defaultSchema), p.TargetObject)
+	for i := range parts {
+		parts[i] = mssql.QuoteIdentifier(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Synonym)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSynonym)
+	}
+
+	var base string
+	query := "SELECT s.base_object_name FROM sys.synonyms s WHERE s.name = @p1 AND SCHEMA_NAME(s.schema_id) = @p2"
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr), ptr.Deref(cr.Spec.ForProvider.Schema, defaultSchema)},
+	}, &base)
+	if xsql.IsNoRows(err) || mssql.IsUnknownDatabase(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSynonym)
+	}
+
+	cr.Status.AtProvider.BaseObjectName = base
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: base == baseObjectName(cr.Spec.ForProvider),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Synonym)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotSynonym)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("CREATE SYNONYM %s FOR %s", synonymName(cr), baseObjectName(cr.Spec.ForProvider)),
+	})
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateSynonym)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Synonym)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotSynonym)
+	}
+
+	// A synonym cannot be altered, so it is recreated in a transaction to
+	// retarget it without a window in which it does not exist.
+	err := c.db.ExecTx(ctx, []xsql.Query{
+		{String: "DROP SYNONYM " + synonymName(cr)},
+		{String: fmt.Sprintf("CREATE SYNONYM %s FOR %s", synonymName(cr), baseObjectName(cr.Spec.ForProvider))},
+	})
+	return managed.ExternalUpdate{}, errors.Wrap(err, errAlterSynonym)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Synonym)
+	if !ok {
+		return errors.New(errNotSynonym)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP SYNONYM IF EXISTS " + synonymName(cr)})
+	if mssql.IsUnknownDatabase(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropSynonym)
+}
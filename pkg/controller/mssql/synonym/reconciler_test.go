@@ -0,0 +1,427 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synonym
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func synonym() *v1alpha1.Synonym {
+	cr := &v1alpha1.Synonym{
+		Spec: v1alpha1.SynonymSpec{
+			ForProvider: v1alpha1.SynonymParameters{
+				Schema:         ptr.To("reporting"),
+				TargetDatabase: ptr.To("sales"),
+				TargetObject:   "orders",
+			},
+		},
+	}
+	meta.SetExternalName(cr, "orders")
+	return cr
+}
+
+func TestBaseObjectName(t *testing.T) {
+	cases := map[string]struct {
+		p    v1alpha1.SynonymParameters
+		want string
+	}{
+		"Object": {
+			p:    v1alpha1.SynonymParameters{TargetObject: "orders"},
+			want: "[dbo].[orders]",
+		},
+		"Database": {
+			p:    v1alpha1.SynonymParameters{TargetDatabase: ptr.To("sales"), TargetSchema: ptr.To("archive"), TargetObject: "orders"},
+			want: "[sales].[archive].[orders]",
+		},
+		"LinkedServer": {
+			p:    v1alpha1.SynonymParameters{TargetServer: ptr.To("remote"), TargetDatabase: ptr.To("sales"), TargetObject: "or]ders"},
+			want: "[remote].[sales].[dbo].[or]]ders]",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, baseObjectName(tc.p)); diff != "" {
+				t.Errorf("baseObjectName(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSynonym": {
+			reason: "An error should be returned if the managed resource is not a *Synonym",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSynonym),
+			},
+		},
+		"ErrNoSynonym": {
+			reason: "We should return ResourceExists: false when no synonym is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectSynonym": {
+			reason: "We should return any errors encountered while trying to select the synonym",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSynonym),
+			},
+		},
+		"Success": {
+			reason: "We should report the synonym as up to date if it refers to its target",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "[sales].[dbo].[orders]"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"TargetChanged": {
+			reason: "We should report the synonym as not up to date if it refers to another object",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "[sales_old].[dbo].[orders]"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSynonym": {
+			reason: "An error should be returned if the managed resource is not a *Synonym",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSynonym),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the synonym should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: errors.Wrap(errBoom, errCreateSynonym),
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a synonym",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE SYNONYM [reporting].[orders] FOR [sales].[dbo].[orders]" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSynonym": {
+			reason: "An error should be returned if the managed resource is not a *Synonym",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSynonym),
+		},
+		"ErrExecTx": {
+			reason: "Any errors encountered while recreating the synonym should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: errors.Wrap(errBoom, errAlterSynonym),
+		},
+		"Success": {
+			reason: "The synonym should be dropped and created again in a single transaction",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: "DROP SYNONYM [reporting].[orders]"},
+							{String: "CREATE SYNONYM [reporting].[orders] FOR [sales].[dbo].[orders]"},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("-want, +got:\n%s", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSynonym": {
+			reason: "An error should be returned if the managed resource is not a *Synonym",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSynonym),
+		},
+		"ErrDropSynonym": {
+			reason: "Errors dropping a synonym should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: errors.Wrap(errBoom, errDropSynonym),
+		},
+		"DatabaseGone": {
+			reason: "No error should be returned if the database of the synonym has been dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return mssqldb.Error{Number: 911} },
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: nil,
+		},
+		"Success": {
+			reason: "No error should be returned if the synonym was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "DROP SYNONYM IF EXISTS [reporting].[orders]" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: synonym(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
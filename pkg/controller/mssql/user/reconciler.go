@@ -19,6 +19,7 @@ package user
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -31,33 +32,57 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/password"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 
 	errNotUser                = "managed resource is not a User custom resource"
+	errInvalidName            = "invalid user name"
 	errSelectUser             = "cannot select user"
+	errSelectUserIn           = "cannot select user in database %s"
 	errCreateUser             = "cannot create user %s"
 	errCreateLogin            = "cannot create login %s"
 	errDropUser               = "error dropping user %s"
 	errDropLogin              = "error dropping login %s"
 	errCannotGetLogins        = "cannot get current logins"
 	errCannotKillLoginSession = "error killing session %d for login %s"
+	errSetUserOwner           = "cannot record owner of user %s"
+	errManagedByOther         = "user is managed by another resource: %s"
+	errSelectOwnedSchemas     = "cannot select schemas owned by user"
+	errTransferSchema         = "cannot transfer ownership of schema %s"
+	errSelectRoleMemberships  = "cannot select role memberships of user"
+	errDropRoleMember         = "cannot remove user from role %s"
+	errInDatabase             = "in database %s"
 
 	errUpdateUser              = "cannot update user"
 	errGetPasswordSecretFailed = "cannot get password secret"
+	errSelectLogin             = "cannot select login"
+	errUpdateLoginPolicy       = "cannot update password policy of login"
+
+	loginTypeWindows = "Windows"
 
 	maxConcurrency = 5
 )
@@ -67,26 +92,49 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.UserGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newClient: mssql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.User{}, &v1alpha1.UserList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.User)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.User{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.UserGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.UserGroupKind))
+}
+
+// NewConnecter returns a connecter for User managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newClient: mssql.New, log: log}
 }
 
 type connector struct {
 	kube      client.Client
 	usage     resource.Tracker
-	newClient func(creds map[string][]byte, database string) xsql.DB
+	newClient func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
+	log       logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -105,37 +153,70 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := mssql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (MySQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
-	userDB := c.newClient(s.Data, ptr.Deref(cr.Spec.ForProvider.Database, ""))
+	opts := mssql.OptionsFromProviderConfig(pc)
+
+	userDB := xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.Database, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr)
 	loginDB := userDB
 	if cr.Spec.ForProvider.LoginDatabase != nil {
-		loginDB = c.newClient(s.Data, ptr.Deref(cr.Spec.ForProvider.LoginDatabase, ""))
+		loginDB = xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, ptr.Deref(cr.Spec.ForProvider.LoginDatabase, ""), opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr)
+	}
+
+	databases := make([]userDatabase, len(cr.Spec.ForProvider.Databases))
+	for i, name := range cr.Spec.ForProvider.Databases {
+		databases[i] = userDatabase{name: name, db: xsql.Instrument(xsql.WithConnectionLimits(c.newClient(creds, name, opts), mssql.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr)}
 	}
 
 	return &external{
-		userDB:  userDB,
-		loginDB: loginDB,
-		kube:    c.kube,
+		userDB:    userDB,
+		loginDB:   loginDB,
+		databases: databases,
+		kube:      c.kube,
 	}, nil
 }
 
+// A userDatabase is one of the additional databases the login of a user is
+// mapped to a user in.
+type userDatabase struct {
+	name string
+	db   xsql.DB
+}
+
 type external struct {
-	userDB  xsql.DB
-	loginDB xsql.DB
-	kube    client.Client
+	userDB    xsql.DB
+	loginDB   xsql.DB
+	databases []userDatabase
+	kube      client.Client
+
+	// adopt is true if the last observation found an existing user that is
+	// to be adopted, i.e. marked as owned, by the next update.
+	adopt bool
+
+	// missing are the additional databases the last observation found no
+	// user in, which the next update creates it in.
+	missing []userDatabase
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -144,21 +225,69 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotUser)
 	}
 
-	var name string
-
-	query := "SELECT name FROM sys.database_principals WHERE type = 'S' AND name = @p1"
-	err := c.userDB.Scan(ctx, xsql.Query{
-		String: query, Parameters: []interface{}{
-			meta.GetExternalName(cr),
-		},
-	}, &name)
+	owner, err := selectOwner(ctx, c.userDB, meta.GetExternalName(cr))
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
+	if mssql.IsUnknownDatabase(err) && meta.WasDeleted(cr) {
+		// The user went away with its database, but the login lives on the
+		// server and still needs to be dropped by Delete.
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
 	}
 
+	// Refuse to manage a user that records another resource as its owner, so
+	// that two resources (e.g. in different clusters) don't fight over it.
+	if xsql.IsManagedByOther(owner, cr) {
+		return managed.ExternalObservation{}, errors.Errorf(errManagedByOther, owner)
+	}
+
+	// A user that exists but records no owner was created outside of the
+	// provider. Adopting it only records this resource as its owner; its
+	// password is left as is unless a PasswordSecretRef is given.
+	c.adopt = owner == "" && ptr.Deref(cr.Spec.ForProvider.AdoptExisting, false)
+
+	if err := c.observeDatabases(ctx, cr); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	if isWindows(cr) {
+		// Windows logins have no password to drift, but the login itself
+		// may be missing, e.g. if the user was restored without it.
+		err := c.loginDB.Scan(ctx, xsql.Query{
+			String:     "SELECT type_desc FROM sys.server_principals WHERE name = @p1",
+			Parameters: []interface{}{meta.GetExternalName(cr)},
+		}, &cr.Status.AtProvider.LoginType)
+		if xsql.IsNoRows(err) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectLogin)
+		}
+
+		cr.SetConditions(xpv1.Available())
+		return managed.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: !c.adopt && len(c.missing) == 0,
+		}, nil
+	}
+
+	var checkPolicy, checkExpiration bool
+	err = c.loginDB.Scan(ctx, xsql.Query{
+		String:     "SELECT is_policy_checked, is_expiration_checked FROM sys.sql_logins WHERE name = @p1",
+		Parameters: []interface{}{meta.GetExternalName(cr)},
+	}, &checkPolicy, &checkExpiration)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectLogin)
+	}
+	cr.Status.AtProvider.CheckPolicy = &checkPolicy
+	cr.Status.AtProvider.CheckExpiration = &checkExpiration
+
 	cr.SetConditions(xpv1.Available())
 
 	_, pwdChanged, err := c.getPassword(ctx, cr)
@@ -168,44 +297,155 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	return managed.ExternalObservation{
 		ResourceExists:   true,
-		ResourceUpToDate: !pwdChanged,
+		ResourceUpToDate: !c.adopt && !pwdChanged && len(c.missing) == 0 && len(policyOptions(cr.Spec.ForProvider, cr.Status.AtProvider)) == 0,
 	}, nil
 }
 
+// selectOwner returns the resource recorded as the owner of the supplied user
+// in the supplied database, which is empty if it records none. It returns
+// sql.ErrNoRows if the user does not exist.
+func selectOwner(ctx context.Context, db xsql.DB, user string) (string, error) {
+	var name, owner string
+	query := "SELECT p.name, COALESCE(CAST(ep.value AS nvarchar(256)), '') " +
+		"FROM sys.database_principals p " +
+		"LEFT JOIN sys.extended_properties ep ON ep.class = 4 AND ep.major_id = p.principal_id AND ep.name = @p2 " +
+		"WHERE p.type IN ('S', 'U', 'G') AND p.name = @p1"
+	err := db.Scan(ctx, xsql.Query{
+		String: query, Parameters: []interface{}{
+			user,
+			xsql.ManagedByKey,
+		},
+	}, &name, &owner)
+	return owner, err
+}
+
+// observeDatabases records whether the user exists in each of the additional
+// databases of the supplied User, and which it is missing from. A database
+// that does not exist counts as missing, so that creating the user in it
+// reports why it cannot be.
+func (c *external) observeDatabases(ctx context.Context, cr *v1alpha1.User) error {
+	c.missing = nil
+	if len(c.databases) == 0 {
+		cr.Status.AtProvider.Databases = nil
+		return nil
+	}
+	observed := make([]v1alpha1.UserDatabaseObservation, len(c.databases))
+	for i, d := range c.databases {
+		owner, err := selectOwner(ctx, d.db, meta.GetExternalName(cr))
+		switch {
+		case xsql.IsNoRows(err), mssql.IsUnknownDatabase(err):
+			c.missing = append(c.missing, d)
+		case err != nil:
+			return errors.Wrapf(err, errSelectUserIn, d.name)
+		case xsql.IsManagedByOther(owner, cr):
+			return errors.Errorf(errManagedByOther, owner)
+		}
+		observed[i] = v1alpha1.UserDatabaseObservation{Name: d.name, Exists: err == nil}
+	}
+	cr.Status.AtProvider.Databases = observed
+	return nil
+}
+
+// policyOptions returns the CHECK_POLICY and CHECK_EXPIRATION options of a
+// SQL login that are set by the supplied parameters and differ from the
+// supplied observation.
+func policyOptions(p v1alpha1.UserParameters, o v1alpha1.UserObservation) []string {
+	var opts []string
+	if p.CheckPolicy != nil && (o.CheckPolicy == nil || *p.CheckPolicy != *o.CheckPolicy) {
+		opts = append(opts, "CHECK_POLICY="+onOff(*p.CheckPolicy))
+	}
+	if p.CheckExpiration != nil && (o.CheckExpiration == nil || *p.CheckExpiration != *o.CheckExpiration) {
+		opts = append(opts, "CHECK_EXPIRATION="+onOff(*p.CheckExpiration))
+	}
+	return opts
+}
+
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// isWindows returns true if the user is created for a Windows login.
+func isWindows(cr *v1alpha1.User) bool {
+	return ptr.Deref(cr.Spec.ForProvider.LoginType, "") == loginTypeWindows
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.User)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotUser)
 	}
 
-	pw, _, err := c.getPassword(ctx, cr)
-	if err != nil {
-		return managed.ExternalCreation{}, err
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mssql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
 	}
-	if pw == "" {
-		pw, err = password.Generate()
-		if err != nil {
+
+	var pw, loginQuery string
+	if isWindows(cr) {
+		loginQuery = fmt.Sprintf("CREATE LOGIN %s FROM WINDOWS", mssql.QuoteIdentifier(meta.GetExternalName(cr)))
+	} else {
+		var err error
+		if pw, _, err = c.getPassword(ctx, cr); err != nil {
 			return managed.ExternalCreation{}, err
 		}
+		if pw == "" {
+			pw, err = password.Generate()
+			if err != nil {
+				return managed.ExternalCreation{}, err
+			}
+		}
+		loginQuery = fmt.Sprintf("CREATE LOGIN %s WITH PASSWORD=%s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteValue(pw))
+		for _, o := range policyOptions(cr.Spec.ForProvider, v1alpha1.UserObservation{}) {
+			loginQuery += ", " + o
+		}
 	}
 
-	loginQuery := fmt.Sprintf("CREATE LOGIN %s WITH PASSWORD=%s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteValue(pw))
 	if err := c.loginDB.Exec(ctx, xsql.Query{
 		String: loginQuery,
 	}); err != nil {
 		return managed.ExternalCreation{}, errors.Wrapf(err, errCreateLogin, meta.GetExternalName(cr))
 	}
 
+	if err := c.createUser(ctx, c.userDB, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	for _, d := range c.databases {
+		if err := c.createUser(ctx, d.db, cr); err != nil {
+			return managed.ExternalCreation{}, errors.Wrapf(err, errInDatabase, d.name)
+		}
+	}
+
+	cd := c.userDB.GetConnectionDetails(meta.GetExternalName(cr), pw)
+	if isWindows(cr) {
+		// Windows logins are authenticated by Windows and have no password.
+		delete(cd, xpv1.ResourceCredentialsSecretPasswordKey)
+	}
+	return managed.ExternalCreation{ConnectionDetails: cd}, nil
+}
+
+// createUser creates the user for its login in the supplied database, and
+// records the supplied User as its owner.
+func (c *external) createUser(ctx context.Context, db xsql.DB, cr *v1alpha1.User) error {
 	userQuery := fmt.Sprintf("CREATE USER %s FOR LOGIN %s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), mssql.QuoteIdentifier(meta.GetExternalName(cr)))
-	if err := c.userDB.Exec(ctx, xsql.Query{
+	if err := db.Exec(ctx, xsql.Query{
 		String: userQuery,
 	}); err != nil {
-		return managed.ExternalCreation{}, errors.Wrapf(err, errCreateUser, meta.GetExternalName(cr))
+		return errors.Wrapf(err, errCreateUser, meta.GetExternalName(cr))
 	}
+	return c.setOwner(ctx, db, cr)
+}
 
-	return managed.ExternalCreation{
-		ConnectionDetails: c.userDB.GetConnectionDetails(meta.GetExternalName(cr), pw),
-	}, nil
+func (c *external) setOwner(ctx context.Context, db xsql.DB, cr *v1alpha1.User) error {
+	query := fmt.Sprintf("EXEC sp_addextendedproperty @name = %s, @value = %s, @level0type = N'USER', @level0name = %s",
+		mssql.QuoteValue(xsql.ManagedByKey), mssql.QuoteValue(xsql.ManagedBy(cr.GetName())), mssql.QuoteValue(meta.GetExternalName(cr)))
+	if err := db.Exec(ctx, xsql.Query{
+		String: query,
+	}); err != nil {
+		return errors.Wrapf(err, errSetUserOwner, meta.GetExternalName(cr))
+	}
+	return nil
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -213,6 +453,28 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotUser)
 	}
+	if c.adopt {
+		if err := c.setOwner(ctx, c.userDB, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	for _, d := range c.missing {
+		if err := c.createUser(ctx, d.db, cr); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrapf(err, errInDatabase, d.name)
+		}
+	}
+	if isWindows(cr) {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	if opts := policyOptions(cr.Spec.ForProvider, cr.Status.AtProvider); len(opts) > 0 {
+		query := fmt.Sprintf("ALTER LOGIN %s WITH %s", mssql.QuoteIdentifier(meta.GetExternalName(cr)), strings.Join(opts, ", "))
+		if err := c.loginDB.Exec(ctx, xsql.Query{
+			String: query,
+		}); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateLoginPolicy)
+		}
+	}
 
 	pw, changed, err := c.getPassword(ctx, cr)
 	if err != nil {
@@ -240,37 +502,136 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotUser)
 	}
 
-	query := fmt.Sprintf("SELECT session_id FROM sys.dm_exec_sessions WHERE login_name = %s", mssql.QuoteValue(meta.GetExternalName(cr)))
-	rows, err := c.userDB.Query(ctx, xsql.Query{String: query})
+	// A database deleted before its users takes them along, so only the
+	// login is left to drop.
+	if err := c.dropUser(ctx, cr); err != nil && !mssql.IsUnknownDatabase(err) {
+		return err
+	}
+	for _, d := range c.databases {
+		if err := c.dropUserIn(ctx, d.db, cr); err != nil && !mssql.IsUnknownDatabase(err) {
+			return errors.Wrapf(err, errInDatabase, d.name)
+		}
+	}
+
+	if err := c.loginDB.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("DROP LOGIN %s", mssql.QuoteIdentifier(meta.GetExternalName(cr))),
+	}); err != nil {
+		return errors.Wrapf(err, errDropLogin, meta.GetExternalName(cr))
+	}
+
+	return nil
+}
+
+func (c *external) dropUser(ctx context.Context, cr *v1alpha1.User) error {
+	// The sessions are all read before any is killed, because the rows hold
+	// their connection until they are closed, and a ProviderConfig with a
+	// maxOpenConnections of 1 has no other to kill them with.
+	sessionIDs, err := selectSessions(ctx, c.userDB, meta.GetExternalName(cr))
 	if err != nil {
 		return errors.Wrap(err, errCannotGetLogins)
 	}
+	for _, sessionID := range sessionIDs {
+		if err := c.userDB.Exec(ctx, xsql.Query{String: fmt.Sprintf("KILL %d", sessionID)}); err != nil {
+			return errors.Wrapf(err, errCannotKillLoginSession, sessionID, meta.GetExternalName(cr))
+		}
+	}
+
+	return c.dropUserIn(ctx, c.userDB, cr)
+}
+
+// selectSessions returns the IDs of the sessions of the supplied login.
+func selectSessions(ctx context.Context, db xsql.DB, login string) ([]int, error) {
+	query := fmt.Sprintf("SELECT session_id FROM sys.dm_exec_sessions WHERE login_name = %s", mssql.QuoteValue(login))
+	rows, err := db.Query(ctx, xsql.Query{String: query})
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close() //nolint:errcheck
 
+	var sessionIDs []int
 	for rows.Next() {
 		var sessionID int
 		if err := rows.Scan(&sessionID); err != nil {
-			return errors.Wrap(err, errCannotGetLogins)
-		}
-		if err := c.userDB.Exec(ctx, xsql.Query{String: fmt.Sprintf("KILL %d", sessionID)}); err != nil {
-			return errors.Wrapf(err, errCannotKillLoginSession, sessionID, meta.GetExternalName(cr))
+			return nil, err
 		}
+		sessionIDs = append(sessionIDs, sessionID)
 	}
-	if err := rows.Err(); err != nil {
-		return errors.Wrap(err, errCannotGetLogins)
+	return sessionIDs, rows.Err()
+}
+
+// dropUserIn drops the user from the supplied database, releasing the
+// schemas and roles it holds first if the User asks for it.
+func (c *external) dropUserIn(ctx context.Context, db xsql.DB, cr *v1alpha1.User) error {
+	if ptr.Deref(cr.Spec.ForProvider.ReleaseOwnershipOnDelete, false) {
+		if err := releaseOwnership(ctx, db, meta.GetExternalName(cr), ptr.Deref(cr.Spec.ForProvider.SchemaOwnerOnDelete, "dbo")); err != nil {
+			return err
+		}
 	}
 
-	if err := c.userDB.Exec(ctx, xsql.Query{
+	if err := db.Exec(ctx, xsql.Query{
 		String: fmt.Sprintf("DROP USER IF EXISTS %s", mssql.QuoteIdentifier(meta.GetExternalName(cr))),
 	}); err != nil {
 		return errors.Wrapf(err, errDropUser, meta.GetExternalName(cr))
 	}
 
-	if err := c.loginDB.Exec(ctx, xsql.Query{
-		String: fmt.Sprintf("DROP LOGIN %s", mssql.QuoteIdentifier(meta.GetExternalName(cr))),
-	}); err != nil {
-		return errors.Wrapf(err, errDropLogin, meta.GetExternalName(cr))
+	return nil
+}
+
+// releaseOwnership transfers the schemas owned by the user in the supplied
+// database to the supplied owner and removes the user from every database
+// role it is a member of, so that it can be dropped.
+func releaseOwnership(ctx context.Context, db xsql.DB, user, owner string) error {
+	schemas, err := selectNames(ctx, db, xsql.Query{
+		String: "SELECT s.name FROM sys.schemas s " +
+			"JOIN sys.database_principals p ON s.principal_id = p.principal_id " +
+			"WHERE p.name = @p1",
+		Parameters: []interface{}{user},
+	})
+	if err != nil {
+		return errors.Wrap(err, errSelectOwnedSchemas)
+	}
+	for _, s := range schemas {
+		if err := db.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("ALTER AUTHORIZATION ON SCHEMA::%s TO %s", mssql.QuoteIdentifier(s), mssql.QuoteIdentifier(owner)),
+		}); err != nil {
+			return errors.Wrapf(err, errTransferSchema, s)
+		}
 	}
 
+	roles, err := selectNames(ctx, db, xsql.Query{
+		String: "SELECT r.name FROM sys.database_role_members m " +
+			"JOIN sys.database_principals r ON m.role_principal_id = r.principal_id " +
+			"JOIN sys.database_principals u ON m.member_principal_id = u.principal_id " +
+			"WHERE u.name = @p1",
+		Parameters: []interface{}{user},
+	})
+	if err != nil {
+		return errors.Wrap(err, errSelectRoleMemberships)
+	}
+	for _, r := range roles {
+		if err := db.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("ALTER ROLE %s DROP MEMBER %s", mssql.QuoteIdentifier(r), mssql.QuoteIdentifier(user)),
+		}); err != nil {
+			return errors.Wrapf(err, errDropRoleMember, r)
+		}
+	}
 	return nil
 }
+
+func selectNames(ctx context.Context, db xsql.DB, q xsql.Query) ([]string, error) {
+	rows, err := db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -19,12 +19,14 @@ package user
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/crossplane-contrib/provider-sql/apis/mssql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	mssqldb "github.com/microsoft/go-mssqldb"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +39,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -86,7 +89,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string) xsql.DB
+		newDB func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB
 	}
 
 	type args struct {
@@ -218,7 +221,9 @@ func TestConnect(t *testing.T) {
 					}),
 				},
 				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
-				newDB: func(creds map[string][]byte, database string) xsql.DB { return mockDB{database: database} },
+				newDB: func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB {
+					return mockDB{database: database}
+				},
 			},
 			args: args{
 				mg: &v1alpha1.User{
@@ -255,7 +260,9 @@ func TestConnect(t *testing.T) {
 					}),
 				},
 				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
-				newDB: func(creds map[string][]byte, database string) xsql.DB { return mockDB{database: database} },
+				newDB: func(creds map[string][]byte, database string, opts mssql.Options) xsql.DB {
+					return mockDB{database: database}
+				},
 			},
 			args: args{
 				mg: &v1alpha1.User{
@@ -380,6 +387,27 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrManagedByOther": {
+			reason: "We should return an error if the user is owned by another resource",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[1].(*string) = xsql.ManagedBy("other")
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "mine",
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errManagedByOther, xsql.ManagedBy("other")),
+			},
+		},
 		"PasswordChanged": {
 			reason: "We should return ResourceUpToDate=false if the password changed",
 			fields: fields{
@@ -424,6 +452,110 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessWindows": {
+			reason: "We should observe the login of a Windows user instead of its password",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if q.String == "SELECT type_desc FROM sys.server_principals WHERE name = @p1" {
+							*dest[0].(*string) = "WINDOWS_GROUP"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+							LoginType:         ptr.To("Windows"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"PolicyDrift": {
+			reason: "We should return ResourceUpToDate: false when the password policy of the login differs",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "sys.sql_logins") {
+							*dest[0].(*bool) = false
+							*dest[1].(*bool) = true
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							CheckPolicy:     ptr.To(true),
+							CheckExpiration: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"NoSQLLogin": {
+			reason: "We should return ResourceExists: false when the login of a SQL user is missing",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "sys.sql_logins") {
+							return sql.ErrNoRows
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"NoWindowsLogin": {
+			reason: "We should return ResourceExists: false when the login of a Windows user is missing",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "sys.server_principals") {
+							return sql.ErrNoRows
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							LoginType: ptr.To("Windows"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -492,6 +624,25 @@ func TestCreate(t *testing.T) {
 				err: errors.Wrapf(errBoom, errCreateLogin, ""),
 			},
 		},
+		"ErrSetOwner": {
+			reason: "Any errors encountered while recording the owner of the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "EXEC sp_addextendedproperty") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errSetUserOwner, ""),
+			},
+		},
 		"Success": {
 			reason: "No error should be returned when we successfully create a user",
 			fields: fields{
@@ -574,6 +725,47 @@ func TestCreate(t *testing.T) {
 				},
 			},
 		},
+		"SuccessWindows": {
+			reason:    "A Windows login must be created from Windows without a password",
+			comparePw: true,
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch {
+						case q.String == `CREATE LOGIN [CORP\dba] FROM WINDOWS`,
+							q.String == `CREATE USER [CORP\dba] FOR LOGIN [CORP\dba]`,
+							strings.HasPrefix(q.String, "EXEC sp_addextendedproperty"):
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: `CORP\dba`,
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							LoginType: ptr.To("Windows"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+				c: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte(`CORP\dba`),
+						xpv1.ResourceCredentialsSecretEndpointKey: []byte("localhost"),
+						xpv1.ResourceCredentialsSecretPortKey:     []byte("3306"),
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -793,6 +985,64 @@ func TestUpdate(t *testing.T) {
 				},
 			},
 		},
+		"UpdatePolicy": {
+			reason: "Only the password policy options that differ should be altered",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER LOGIN [example] WITH CHECK_POLICY=ON" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							CheckPolicy:     ptr.To(true),
+							CheckExpiration: ptr.To(false),
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							CheckPolicy:     ptr.To(false),
+							CheckExpiration: ptr.To(false),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"NoOpWindows": {
+			reason: "The password of a Windows user must not be updated",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+							LoginType:         ptr.To("Windows"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -857,6 +1107,108 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.Wrapf(errBoom, errDropUser, ""),
 		},
+		"ErrTransferSchema": {
+			reason: "Errors transferring ownership of an owned schema should be returned",
+			fields: fields{
+				userDB: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if strings.Contains(q.String, "sys.schemas") {
+							return mockRowsToSQLRows(sqlmock.NewRows([]string{"name"}).AddRow("app")), nil
+						}
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{})), nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "ALTER AUTHORIZATION") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				loginDB: &mockDB{},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							ReleaseOwnershipOnDelete: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: errors.Wrapf(errBoom, errTransferSchema, "app"),
+		},
+		"SuccessDatabaseGone": {
+			reason: "The login should still be dropped if the database of the user has been dropped",
+			fields: fields{
+				userDB: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return nil, mssqldb.Error{Number: 4060}
+					},
+				},
+				loginDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "DROP LOGIN [example]" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+				},
+			},
+		},
+		"SuccessReleaseOwnership": {
+			reason: "Owned schemas should be transferred and role memberships removed before the user is dropped",
+			fields: fields{
+				userDB: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						switch {
+						case strings.Contains(q.String, "sys.schemas"):
+							return mockRowsToSQLRows(sqlmock.NewRows([]string{"name"}).AddRow("app")), nil
+						case strings.Contains(q.String, "sys.database_role_members"):
+							return mockRowsToSQLRows(sqlmock.NewRows([]string{"name"}).AddRow("db_datareader")), nil
+						}
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{})), nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "ALTER AUTHORIZATION ON SCHEMA::[app] TO [owner]",
+							"ALTER ROLE [db_datareader] DROP MEMBER [example]",
+							"DROP USER IF EXISTS [example]":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+				loginDB: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							ReleaseOwnershipOnDelete: ptr.To(true),
+							SchemaOwnerOnDelete:      ptr.To("owner"),
+						},
+					},
+				},
+			},
+		},
 		"Success": {
 			reason: "No error should be returned",
 			fields: fields{
@@ -891,3 +1243,112 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAdoptExisting(t *testing.T) {
+	var queries []string
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			// The user exists and records no owner.
+			if name, ok := dest[0].(*string); ok {
+				*name = "example"
+			}
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "example",
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.UserSpec{
+			ForProvider: v1alpha1.UserParameters{
+				AdoptExisting: ptr.To(true),
+			},
+		},
+	}
+
+	e := external{userDB: db, loginDB: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want an existing user that is not up to date, got %+v", o)
+	}
+
+	u, err := e.Update(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{"EXEC sp_addextendedproperty @name = 'managed-by', @value = 'crossplane/example', @level0type = N'USER', @level0name = 'example'"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+	if u.ConnectionDetails != nil {
+		t.Errorf("e.Update(...): want no connection details for an adopted user, got %v", u.ConnectionDetails)
+	}
+}
+
+func TestDatabases(t *testing.T) {
+	var queries []string
+	exists := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+	}
+	missing := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "example",
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.UserSpec{
+			ForProvider: v1alpha1.UserParameters{
+				Databases: []string{"app", "billing"},
+			},
+		},
+	}
+
+	e := external{
+		userDB:  exists,
+		loginDB: exists,
+		databases: []userDatabase{
+			{name: "app", db: exists},
+			{name: "billing", db: missing},
+		},
+	}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want an existing user that is not up to date, got %+v", o)
+	}
+	wantObserved := []v1alpha1.UserDatabaseObservation{{Name: "app", Exists: true}, {Name: "billing", Exists: false}}
+	if diff := cmp.Diff(wantObserved, cr.Status.AtProvider.Databases); diff != "" {
+		t.Errorf("e.Observe(...): -want status.atProvider.databases, +got:\n%s", diff)
+	}
+
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{
+		"CREATE USER [example] FOR LOGIN [example]",
+		"EXEC sp_addextendedproperty @name = 'managed-by', @value = 'crossplane/example', @level0type = N'USER', @level0name = 'example'",
+	}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+}
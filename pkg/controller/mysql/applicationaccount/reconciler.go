@@ -0,0 +1,514 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationaccount
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/tls"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+	errTLSConfig    = "cannot load TLS config"
+
+	errNotAccount              = "managed resource is not an ApplicationAccount custom resource"
+	errInvalidDBName           = "invalid database name"
+	errInvalidUserName         = "invalid user name"
+	errSelectAccount           = "cannot select application account"
+	errCreateDB                = "cannot create database"
+	errAlterDB                 = "cannot alter database"
+	errDropDB                  = "cannot drop database"
+	errCreateUser              = "cannot create user"
+	errUpdateUser              = "cannot update user"
+	errDropUser                = "cannot drop user"
+	errGrant                   = "cannot grant privileges"
+	errRevoke                  = "cannot revoke privileges"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	maxConcurrency = 5
+)
+
+// privileges are the database level privileges of MySQL, in the order of the
+// columns of mysql.db that record them.
+var privileges = []struct{ name, column string }{
+	{"SELECT", "Select_priv"},
+	{"INSERT", "Insert_priv"},
+	{"UPDATE", "Update_priv"},
+	{"DELETE", "Delete_priv"},
+	{"CREATE", "Create_priv"},
+	{"DROP", "Drop_priv"},
+	{"REFERENCES", "References_priv"},
+	{"INDEX", "Index_priv"},
+	{"ALTER", "Alter_priv"},
+	{"CREATE TEMPORARY TABLES", "Create_tmp_table_priv"},
+	{"LOCK TABLES", "Lock_tables_priv"},
+	{"CREATE VIEW", "Create_view_priv"},
+	{"SHOW VIEW", "Show_view_priv"},
+	{"CREATE ROUTINE", "Create_routine_priv"},
+	{"ALTER ROUTINE", "Alter_routine_priv"},
+	{"EXECUTE", "Execute_priv"},
+	{"EVENT", "Event_priv"},
+	{"TRIGGER", "Trigger_priv"},
+}
+
+// profiles are the privileges granted by each profile but admin, which grants
+// all privileges, in the order of privileges.
+var profiles = map[string][]string{
+	v1alpha1.ProfileReadOnly:  {"SELECT", "SHOW VIEW"},
+	v1alpha1.ProfileReadWrite: {"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE TEMPORARY TABLES", "LOCK TABLES", "SHOW VIEW", "EXECUTE"},
+}
+
+// profilePrivileges returns the privileges granted by the profile of the
+// supplied account.
+func profilePrivileges(cr *v1alpha1.ApplicationAccount) []string {
+	profile := ptr.Deref(cr.Spec.ForProvider.Profile, v1alpha1.ProfileReadWrite)
+	if profile != v1alpha1.ProfileAdmin {
+		return profiles[profile]
+	}
+	out := make([]string, len(privileges))
+	for i, p := range privileges {
+		out[i] = p.name
+	}
+	return out
+}
+
+// Setup adds a controller that reconciles ApplicationAccount managed
+// resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.ApplicationAccountGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ApplicationAccountGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.ApplicationAccount{}, &v1alpha1.ApplicationAccountList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.ApplicationAccount)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ApplicationAccount{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.ApplicationAccountGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.ApplicationAccountGroupKind))
+}
+
+// NewConnecter returns a connecter for ApplicationAccount managed resources.
+// It gets ProviderConfigs and credentials using the supplied client, and
+// tracks the usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: mysql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationAccount)
+	if !ok {
+		return nil, errors.New(errNotAccount)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	providerConfigName := cr.GetProviderConfigReference().Name
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: providerConfigName}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mysql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	tlsName, err := tls.LoadConfig(ctx, c.kube, providerConfigName, pc.Spec.TLS, pc.Spec.TLSConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errTLSConfig)
+	}
+
+	cd := managed.ConnectionDetails{}
+	caCert, err := tls.PublishedCACert(ctx, c.kube, pc.Spec.TLS, pc.Spec.TLSConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errTLSConfig)
+	}
+	if caCert != nil {
+		cd[xsql.CACertKey] = caCert
+	}
+
+	db := xsql.WithConnectionDetails(c.newDB(creds, tlsName, cr.Spec.ForProvider.BinLog, mysql.OptionsFromProviderConfig(pc)), cd)
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(db, mysql.ConnectionLimits(pc)), c.log, v1alpha1.ApplicationAccountKind, cr),
+		kube: c.kube,
+		self: string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+
+	// self is the user the provider connects as.
+	self string
+
+	// userExists is false if the last observation found the database but
+	// not its user, which the next update creates.
+	userExists bool
+}
+
+// account returns the user of the supplied account, in user@host form.
+func account(cr *v1alpha1.ApplicationAccount) string {
+	return ptr.Deref(cr.Spec.ForProvider.User, meta.GetExternalName(cr))
+}
+
+// checkReserved refuses to manage the account the provider connects as, or
+// one reserved by MySQL or the database service.
+func (c *external) checkReserved(cr *v1alpha1.ApplicationAccount) error {
+	username, _ := mysql.SplitUserHost(account(cr))
+	return xsql.CheckReserved(cr, "account", account(cr), mysql.IsReservedUser(username, c.self))
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationAccount)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAccount)
+	}
+
+	username, host := mysql.SplitUserHost(account(cr))
+	db := meta.GetExternalName(cr)
+
+	held := make([]string, len(privileges))
+	for i, p := range privileges {
+		held[i] = fmt.Sprintf("IF(d.%s = 'Y', '%s', NULL)", p.column, p.name)
+	}
+
+	var privs string
+	observed := v1alpha1.ApplicationAccountObservation{}
+	query := "SELECT s.default_character_set_name, s.default_collation_name, " +
+		"EXISTS (SELECT 1 FROM mysql.user WHERE User = ? AND Host = ?), " +
+		"COALESCE((SELECT CONCAT_WS(',', " + strings.Join(held, ", ") + ") FROM mysql.db d WHERE d.User = ? AND d.Host = ? AND d.Db = ?), '') " +
+		"FROM information_schema.schemata s WHERE s.schema_name = ?"
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{username, host, username, host, db, db},
+	}, &observed.CharacterSet, &observed.Collation, &c.userExists, &privs)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectAccount)
+	}
+	if privs != "" {
+		observed.Privileges = strings.Split(privs, ",")
+	}
+
+	cr.Status.AtProvider = observed
+	cr.SetConditions(xpv1.Available())
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	toGrant, toRevoke := diffPrivileges(cr, observed.Privileges)
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		ResourceUpToDate: c.userExists && !pwdChanged &&
+			len(alterClauses(cr.Spec.ForProvider, observed)) == 0 &&
+			len(toGrant) == 0 && len(toRevoke) == 0,
+	}, nil
+}
+
+// diffPrivileges returns the privileges of the profile of the supplied
+// account that the user has yet to be granted, and those it holds beyond
+// them.
+func diffPrivileges(cr *v1alpha1.ApplicationAccount, observed []string) (toGrant, toRevoke []string) {
+	desired := profilePrivileges(cr)
+	for _, p := range desired {
+		if !slices.Contains(observed, p) {
+			toGrant = append(toGrant, p)
+		}
+	}
+	for _, p := range observed {
+		if !slices.Contains(desired, p) {
+			toRevoke = append(toRevoke, p)
+		}
+	}
+	return toGrant, toRevoke
+}
+
+// alterClauses returns the ALTER DATABASE clauses needed to bring the
+// character set and collation of the observed database in line with the
+// desired parameters.
+func alterClauses(p v1alpha1.ApplicationAccountParameters, o v1alpha1.ApplicationAccountObservation) []string {
+	var clauses []string
+	if p.CharacterSet != nil && !strings.EqualFold(*p.CharacterSet, o.CharacterSet) {
+		clauses = append(clauses, "CHARACTER SET "+*p.CharacterSet)
+	}
+	if p.Collation != nil && !strings.EqualFold(*p.Collation, o.Collation) {
+		clauses = append(clauses, "COLLATE "+*p.Collation)
+	}
+	return clauses
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationAccount)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotAccount)
+	}
+
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	username, _ := mysql.SplitUserHost(account(cr))
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mysql.DatabaseNameLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidDBName)
+	}
+	if err := xsql.ValidateIdentifier(username, mysql.UserNameLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidUserName)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	query := "CREATE DATABASE " + mysql.QuoteIdentifier(meta.GetExternalName(cr))
+	if p := cr.Spec.ForProvider; p.CharacterSet != nil {
+		query += " CHARACTER SET " + *p.CharacterSet
+	}
+	if p := cr.Spec.ForProvider; p.Collation != nil {
+		query += " COLLATE " + *p.Collation
+	}
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errCreateDB}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	cd, err := c.createUser(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	if err := c.grant(ctx, cr, profilePrivileges(cr)); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{ConnectionDetails: cd}, nil
+}
+
+// createUser creates the user of the supplied account, and returns its
+// connection details.
+func (c *external) createUser(ctx context.Context, cr *v1alpha1.ApplicationAccount) (managed.ConnectionDetails, error) {
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	if pw == "" {
+		if pw, err = password.Generate(); err != nil {
+			return nil, err
+		}
+	}
+
+	username, host := mysql.SplitUserHost(account(cr))
+	query := fmt.Sprintf("CREATE USER %s@%s IDENTIFIED BY %s", mysql.QuoteValue(username), mysql.QuoteValue(host), mysql.QuoteValue(pw))
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errCreateUser}); err != nil {
+		return nil, err
+	}
+	return c.connectionDetails(cr, pw), nil
+}
+
+// connectionDetails returns the connection details of the user of the
+// supplied account, including its database.
+func (c *external) connectionDetails(cr *v1alpha1.ApplicationAccount, pw string) managed.ConnectionDetails {
+	username, _ := mysql.SplitUserHost(account(cr))
+	cd := c.db.GetConnectionDetails(username, pw)
+	cd[xsql.DatabaseKey] = []byte(meta.GetExternalName(cr))
+	return cd
+}
+
+func (c *external) grant(ctx context.Context, cr *v1alpha1.ApplicationAccount, privs []string) error {
+	username, host := mysql.SplitUserHost(account(cr))
+	query := fmt.Sprintf("GRANT %s ON %s.* TO %s@%s",
+		strings.Join(privs, ", "),
+		mysql.QuoteIdentifier(meta.GetExternalName(cr)),
+		mysql.QuoteValue(username),
+		mysql.QuoteValue(host),
+	)
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errGrant})
+}
+
+func (c *external) revoke(ctx context.Context, cr *v1alpha1.ApplicationAccount, privs []string) error {
+	username, host := mysql.SplitUserHost(account(cr))
+	query := fmt.Sprintf("REVOKE %s ON %s.* FROM %s@%s",
+		strings.Join(privs, ", "),
+		mysql.QuoteIdentifier(meta.GetExternalName(cr)),
+		mysql.QuoteValue(username),
+		mysql.QuoteValue(host),
+	)
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errRevoke})
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ApplicationAccount)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAccount)
+	}
+
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if clauses := alterClauses(cr.Spec.ForProvider, cr.Status.AtProvider); len(clauses) > 0 {
+		query := "ALTER DATABASE " + mysql.QuoteIdentifier(meta.GetExternalName(cr)) + " " + strings.Join(clauses, " ")
+		if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errAlterDB}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	var cd managed.ConnectionDetails
+	if !c.userExists {
+		var err error
+		if cd, err = c.createUser(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	} else {
+		pw, pwdChanged, err := c.getPassword(ctx, cr)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		if pwdChanged {
+			username, host := mysql.SplitUserHost(account(cr))
+			query := fmt.Sprintf("ALTER USER %s@%s IDENTIFIED BY %s", mysql.QuoteValue(username), mysql.QuoteValue(host), mysql.QuoteValue(pw))
+			if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+			cd = c.connectionDetails(cr, pw)
+		}
+	}
+
+	toGrant, toRevoke := diffPrivileges(cr, cr.Status.AtProvider.Privileges)
+	if len(toRevoke) > 0 {
+		if err := c.revoke(ctx, cr, toRevoke); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	if len(toGrant) > 0 {
+		if err := c.grant(ctx, cr, toGrant); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ApplicationAccount)
+	if !ok {
+		return errors.New(errNotAccount)
+	}
+
+	if err := c.checkReserved(cr); err != nil {
+		return err
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	username, host := mysql.SplitUserHost(account(cr))
+	query := fmt.Sprintf("DROP USER IF EXISTS %s@%s", mysql.QuoteValue(username), mysql.QuoteValue(host))
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errDropUser}); err != nil {
+		return err
+	}
+
+	query = "DROP DATABASE IF EXISTS " + mysql.QuoteIdentifier(meta.GetExternalName(cr))
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errDropDB})
+}
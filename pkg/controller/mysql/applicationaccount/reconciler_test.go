@@ -0,0 +1,403 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationaccount
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec   func(ctx context.Context, q xsql.Query) error
+	MockExecTx func(ctx context.Context, ql []xsql.Query) error
+	MockScan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+	}
+}
+
+func applicationAccount(p v1alpha1.ApplicationAccountParameters) *v1alpha1.ApplicationAccount {
+	cr := &v1alpha1.ApplicationAccount{Spec: v1alpha1.ApplicationAccountSpec{ForProvider: p}}
+	meta.SetExternalName(cr, "app")
+	return cr
+}
+
+// observed returns a database client that observes a database of the
+// supplied character set, and a user holding the supplied privileges on it.
+func observed(charset string, userExists bool, privs string) mockDB {
+	return mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = charset
+			*dest[2].(*bool) = userExists
+			*dest[3].(*string) = privs
+			return nil
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.ApplicationAccountObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		args   args
+		want   want
+	}{
+		"ErrNotAccount": {
+			reason: "An error should be returned if the managed resource is not an *ApplicationAccount",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotAccount),
+			},
+		},
+		"NoDatabase": {
+			reason: "We should return ResourceExists: false when no database is found",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+			},
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectAccount": {
+			reason: "We should return any errors encountered while trying to select the account",
+			db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+			},
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectAccount),
+			},
+		},
+		"UpToDate": {
+			reason: "An account whose user holds exactly the privileges of its profile should be up to date",
+			db:     observed("utf8mb4", true, "SELECT,SHOW VIEW"),
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{
+					Profile:      ptr.To(v1alpha1.ProfileReadOnly),
+					CharacterSet: ptr.To("utf8mb4"),
+				}),
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.ApplicationAccountObservation{CharacterSet: "utf8mb4", Privileges: []string{"SELECT", "SHOW VIEW"}},
+			},
+		},
+		"UserMissing": {
+			reason: "An account whose user does not exist should not be up to date",
+			db:     observed("utf8mb4", false, ""),
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{}),
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.ApplicationAccountObservation{CharacterSet: "utf8mb4"},
+			},
+		},
+		"ProfileChanged": {
+			reason: "An account whose user holds privileges beyond its profile should not be up to date",
+			db:     observed("utf8mb4", true, "SELECT,INSERT,UPDATE,DELETE,CREATE TEMPORARY TABLES,LOCK TABLES,SHOW VIEW,EXECUTE"),
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{Profile: ptr.To(v1alpha1.ProfileReadOnly)}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.ApplicationAccountObservation{
+					CharacterSet: "utf8mb4",
+					Privileges:   []string{"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE TEMPORARY TABLES", "LOCK TABLES", "SHOW VIEW", "EXECUTE"},
+				},
+			},
+		},
+		"CharacterSetChanged": {
+			reason: "An account whose database has another character set should not be up to date",
+			db:     observed("latin1", true, "SELECT,INSERT,UPDATE,DELETE,CREATE TEMPORARY TABLES,LOCK TABLES,SHOW VIEW,EXECUTE"),
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{CharacterSet: ptr.To("utf8mb4")}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.ApplicationAccountObservation{
+					CharacterSet: "latin1",
+					Privileges:   []string{"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE TEMPORARY TABLES", "LOCK TABLES", "SHOW VIEW", "EXECUTE"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.ApplicationAccount); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		exec   func(q xsql.Query) error
+		args   args
+		want   want
+	}{
+		"ErrNotAccount": {
+			reason: "An error should be returned if the managed resource is not an *ApplicationAccount",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotAccount),
+			},
+		},
+		"ErrReserved": {
+			reason: "Accounts reserved by MySQL should not be created",
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{User: ptr.To("root@%")}),
+			},
+			want: want{
+				err: xsql.CheckReserved(applicationAccount(v1alpha1.ApplicationAccountParameters{}), "account", "root@%", true),
+			},
+		},
+		"ErrCreateDB": {
+			reason: "Any errors encountered while creating the database should be returned",
+			exec:   func(q xsql.Query) error { return errBoom },
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateDB),
+			},
+		},
+		"ReadOnly": {
+			reason: "The database, the user and a grant of the privileges of the profile should be created",
+			args: args{
+				mg: applicationAccount(v1alpha1.ApplicationAccountParameters{
+					User:              ptr.To("reader@10.0.0.%"),
+					PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+					Profile:           ptr.To(v1alpha1.ProfileReadOnly),
+					CharacterSet:      ptr.To("utf8mb4"),
+				}),
+			},
+			want: want{
+				queries: []string{
+					"CREATE DATABASE `app` CHARACTER SET utf8mb4",
+					"CREATE USER 'reader'@'10.0.0.%' IDENTIFIED BY 's3cr3t'",
+					"GRANT SELECT, SHOW VIEW ON `app`.* TO 'reader'@'10.0.0.%'",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("s3cr3t")}
+					return nil
+				}),
+			}
+			e := external{kube: kube, db: mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					if tc.exec != nil {
+						return tc.exec(q)
+					}
+					queries = append(queries, q.String)
+					return nil
+				},
+			}}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff("app", string(got.ConnectionDetails[xsql.DatabaseKey])); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want database, +got database:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type fields struct {
+		userExists bool
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   []string
+	}{
+		"ChangeProfile": {
+			reason: "Privileges beyond the new profile should be revoked and missing ones granted",
+			fields: fields{
+				userExists: true,
+			},
+			args: args{
+				mg: func() resource.Managed {
+					cr := applicationAccount(v1alpha1.ApplicationAccountParameters{Profile: ptr.To(v1alpha1.ProfileReadOnly)})
+					cr.Status.AtProvider.Privileges = []string{"SELECT", "INSERT"}
+					return cr
+				}(),
+			},
+			want: []string{
+				"REVOKE INSERT ON `app`.* FROM 'app'@'%'",
+				"GRANT SHOW VIEW ON `app`.* TO 'app'@'%'",
+			},
+		},
+		"AlterCharacterSet": {
+			reason: "The character set of the database should be altered",
+			fields: fields{
+				userExists: true,
+			},
+			args: args{
+				mg: func() resource.Managed {
+					cr := applicationAccount(v1alpha1.ApplicationAccountParameters{
+						Profile:      ptr.To(v1alpha1.ProfileReadOnly),
+						CharacterSet: ptr.To("utf8mb4"),
+					})
+					cr.Status.AtProvider = v1alpha1.ApplicationAccountObservation{CharacterSet: "latin1", Privileges: []string{"SELECT", "SHOW VIEW"}}
+					return cr
+				}(),
+			},
+			want: []string{
+				"ALTER DATABASE `app` CHARACTER SET utf8mb4",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			e := external{userExists: tc.fields.userExists, db: mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return nil
+				},
+			}}
+			if _, err := e.Update(tc.args.ctx, tc.args.mg); err != nil {
+				t.Errorf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	var queries []string
+	e := external{db: mockDB{
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}}
+	if err := e.Delete(context.Background(), applicationAccount(v1alpha1.ApplicationAccountParameters{})); err != nil {
+		t.Errorf("e.Delete(...): %v", err)
+	}
+	want := []string{
+		"DROP USER IF EXISTS 'app'@'%'",
+		"DROP DATABASE IF EXISTS `app`",
+	}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Delete(...): -want queries, +got queries:\n%s\n", diff)
+	}
+}
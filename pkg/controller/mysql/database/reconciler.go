@@ -18,6 +18,7 @@ package database
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -29,28 +30,45 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/tls"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 	errTLSConfig    = "cannot load TLS config"
 
 	errNotDatabase = "managed resource is not a Database custom resource"
+	errInvalidName = "invalid database name"
 	errSelectDB    = "cannot select database"
 	errCreateDB    = "cannot create database"
+	errShowDB      = "cannot show create database"
+	errAlterDB     = "cannot alter database"
 	errDropDB      = "cannot drop database"
 
+	errCheckEncryption       = "cannot check whether the server supports default encryption"
+	errEncryptionUnsupported = "the server does not support defaultEncryption, which requires MySQL 8.0.16 or later"
+
 	maxConcurrency = 5
 )
 
@@ -59,26 +77,37 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: mysql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Database{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseGroupKind))
+}
+
+// NewConnecter returns a connecter for Database managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: mysql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+	newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -98,18 +127,27 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: providerConfigName}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
-
-	// We don't need to check the credentials source because we currently only
-	// support one source (MySQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	if err := mysql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
 	}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
 	tlsName, err := tls.LoadConfig(ctx, c.kube, providerConfigName, pc.Spec.TLS, pc.Spec.TLSConfig)
@@ -117,7 +155,7 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errTLSConfig)
 	}
 
-	return &external{db: c.newDB(s.Data, tlsName, cr.Spec.ForProvider.BinLog)}, nil
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, tlsName, cr.Spec.ForProvider.BinLog, mysql.OptionsFromProviderConfig(pc)), mysql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr)}, nil
 }
 
 type external struct{ db xsql.DB }
@@ -128,9 +166,23 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotDatabase)
 	}
 
-	var name string
-	query := "SELECT schema_name FROM information_schema.schemata WHERE schema_name = ?"
-	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &name)
+	// Refuse to manage default encryption on servers that would fail every
+	// attempt to create or alter the database with it.
+	if cr.Spec.ForProvider.DefaultEncryption != nil {
+		if err := c.checkDefaultEncryption(ctx); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	observed := v1alpha1.DatabaseObservation{}
+	query := "SELECT s.default_character_set_name, s.default_collation_name, " +
+		"(SELECT CAST(COALESCE(SUM(t.data_length + t.index_length), 0) AS SIGNED) FROM information_schema.tables t WHERE t.table_schema = s.schema_name) " +
+		"FROM information_schema.schemata s WHERE s.schema_name = ?"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}},
+		&observed.CharacterSet,
+		&observed.Collation,
+		&observed.SizeBytes,
+	)
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
@@ -138,25 +190,110 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDB)
 	}
 
+	// Encryption and read only are not exposed by information_schema before
+	// MySQL 8.0.16 and 8.0.22 respectively, so we only read them from the
+	// database definition when they are managed.
+	p := cr.Spec.ForProvider
+	if p.DefaultEncryption != nil || p.ReadOnly != nil {
+		var name, stmt string
+		if err := c.db.Scan(ctx, xsql.Query{String: "SHOW CREATE DATABASE " + mysql.QuoteIdentifier(meta.GetExternalName(cr))}, &name, &stmt); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errShowDB)
+		}
+		encryption, readOnly := parseCreateDatabase(stmt)
+		observed.DefaultEncryption = &encryption
+		observed.ReadOnly = &readOnly
+	}
+
+	cr.Status.AtProvider = observed
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
-		ResourceExists: true,
-
-		// TODO(negz): Support these when we have anything to update.
+		ResourceExists:          true,
 		ResourceLateInitialized: false,
-		ResourceUpToDate:        true,
+		ResourceUpToDate:        len(alterClauses(p, observed)) == 0,
 	}, nil
 }
 
+// checkDefaultEncryption returns an error unless the server supports the
+// default encryption of databases, which information_schema exposes from
+// MySQL 8.0.16. MariaDB does not support it.
+func (c *external) checkDefaultEncryption(ctx context.Context) error {
+	var supported bool
+	query := "SELECT COUNT(*) > 0 FROM information_schema.columns " +
+		"WHERE table_schema = 'information_schema' AND table_name = 'SCHEMATA' AND column_name = 'DEFAULT_ENCRYPTION'"
+	if err := c.db.Scan(ctx, xsql.Query{String: query}, &supported); err != nil {
+		return errors.Wrap(err, errCheckEncryption)
+	}
+	if !supported {
+		return errors.New(errEncryptionUnsupported)
+	}
+	return nil
+}
+
+// parseCreateDatabase returns whether the supplied SHOW CREATE DATABASE
+// statement enables default encryption and read only, e.g.
+// CREATE DATABASE `db` /*!80016 DEFAULT ENCRYPTION='Y' */ /* READ ONLY = 1 */
+func parseCreateDatabase(stmt string) (encryption, readOnly bool) {
+	return strings.Contains(stmt, "DEFAULT ENCRYPTION='Y'"), strings.Contains(stmt, "READ ONLY = 1")
+}
+
+// yesNo renders b as the 'Y' or 'N' value DEFAULT ENCRYPTION expects.
+func yesNo(b bool) string {
+	if b {
+		return "'Y'"
+	}
+	return "'N'"
+}
+
+// alterClauses returns the ALTER DATABASE clauses needed to bring the
+// observed database in line with the desired parameters. Only parameters
+// that are set are considered.
+func alterClauses(p v1alpha1.DatabaseParameters, o v1alpha1.DatabaseObservation) []string {
+	var clauses []string
+	if p.CharacterSet != nil && !strings.EqualFold(*p.CharacterSet, o.CharacterSet) {
+		clauses = append(clauses, "CHARACTER SET "+*p.CharacterSet)
+	}
+	if p.Collation != nil && !strings.EqualFold(*p.Collation, o.Collation) {
+		clauses = append(clauses, "COLLATE "+*p.Collation)
+	}
+	if p.DefaultEncryption != nil && (o.DefaultEncryption == nil || *p.DefaultEncryption != *o.DefaultEncryption) {
+		clauses = append(clauses, "DEFAULT ENCRYPTION "+yesNo(*p.DefaultEncryption))
+	}
+	if p.ReadOnly != nil && (o.ReadOnly == nil || *p.ReadOnly != *o.ReadOnly) {
+		ro := "0"
+		if *p.ReadOnly {
+			ro = "1"
+		}
+		clauses = append(clauses, "READ ONLY = "+ro)
+	}
+	return clauses
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Database)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotDatabase)
 	}
 
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mysql.DatabaseNameLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
 	query := "CREATE DATABASE " + mysql.QuoteIdentifier(meta.GetExternalName(cr))
 
+	// READ ONLY cannot be set when creating a database; Update sets it once
+	// the database exists.
+	p := cr.Spec.ForProvider
+	if p.CharacterSet != nil {
+		query += " CHARACTER SET " + *p.CharacterSet
+	}
+	if p.Collation != nil {
+		query += " COLLATE " + *p.Collation
+	}
+	if p.DefaultEncryption != nil {
+		query += " DEFAULT ENCRYPTION " + yesNo(*p.DefaultEncryption)
+	}
+
 	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errCreateDB}); err != nil {
 		return managed.ExternalCreation{}, err
 	}
@@ -165,7 +302,21 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	// TODO(negz): Support updates once we have anything to update.
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDatabase)
+	}
+
+	clauses := alterClauses(cr.Spec.ForProvider, cr.Status.AtProvider)
+	if len(clauses) == 0 {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	query := "ALTER DATABASE " + mysql.QuoteIdentifier(meta.GetExternalName(cr)) + " " + strings.Join(clauses, " ")
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errAlterDB}); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	return managed.ExternalUpdate{}, nil
 }
 
@@ -19,15 +19,19 @@ package database
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -64,7 +68,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+		newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -189,8 +193,9 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseObservation
+		err        error
 	}
 
 	cases := map[string]struct {
@@ -255,6 +260,160 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessAtProvider": {
+			reason: "The observed state of the database should be reported in its status",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "utf8mb4"
+						*dest[1].(*string) = "utf8mb4_0900_ai_ci"
+						*dest[2].(*int64) = 16384
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{CharacterSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", SizeBytes: 16384},
+			},
+		},
+		"CollationDrift": {
+			reason: "A database whose collation differs from the desired one should not be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "utf8mb4"
+						*dest[1].(*string) = "utf8mb4_general_ci"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							CharacterSet: ptr.To("UTF8MB4"),
+							Collation:    ptr.To("utf8mb4_0900_ai_ci"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{CharacterSet: "utf8mb4", Collation: "utf8mb4_general_ci"},
+			},
+		},
+		"ErrShowCreateDatabase": {
+			reason: "We should return any errors encountered while trying to show the database definition",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.HasPrefix(q.String, "SHOW CREATE DATABASE") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ReadOnly: ptr.To(true)},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errShowDB),
+			},
+		},
+		"ErrCheckEncryption": {
+			reason: "We should return any errors encountered while checking whether the server supports default encryption",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DefaultEncryption: ptr.To(true)},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCheckEncryption),
+			},
+		},
+		"ErrEncryptionUnsupported": {
+			reason: "We should return an error if default encryption is managed on a server that does not support it",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "DEFAULT_ENCRYPTION") {
+							*dest[0].(*bool) = false
+							return nil
+						}
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{DefaultEncryption: ptr.To(false)},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errEncryptionUnsupported),
+			},
+		},
+		"EncryptionAndReadOnly": {
+			reason: "Default encryption and read only should be read from the database definition when managed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "DEFAULT_ENCRYPTION") {
+							*dest[0].(*bool) = true
+						}
+						if q.String == "SHOW CREATE DATABASE `example`" {
+							*dest[1].(*string) = "CREATE DATABASE `example` /*!40100 DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci */ /*!80016 DEFAULT ENCRYPTION='Y' */"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							DefaultEncryption: ptr.To(true),
+							ReadOnly:          ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.DatabaseObservation{DefaultEncryption: ptr.To(true), ReadOnly: ptr.To(false)},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -267,6 +426,11 @@ func TestObserve(t *testing.T) {
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Database); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
 		})
 	}
 }
@@ -317,6 +481,37 @@ func TestCreate(t *testing.T) {
 				err: errors.Wrap(errBoom, errCreateDB),
 			},
 		},
+		"SuccessOptions": {
+			reason: "The character set, collation and default encryption should be set when creating a database",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE DATABASE `example` CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT ENCRYPTION 'Y'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							CharacterSet:      ptr.To("utf8mb4"),
+							Collation:         ptr.To("utf8mb4_bin"),
+							DefaultEncryption: ptr.To(true),
+							ReadOnly:          ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 		"Success": {
 			reason: "No error should be returned when we successfully create a database",
 			fields: fields{
@@ -347,6 +542,127 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		u   managed.ExternalUpdate
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotDatabase": {
+			reason: "An error should be returned if the managed resource is not a *Database",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotDatabase),
+			},
+		},
+		"NoOp": {
+			reason: "Nothing should be altered when the database matches its parameters",
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{CharacterSet: ptr.To("utf8mb4")},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{CharacterSet: "utf8mb4"},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while altering the database should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{Collation: ptr.To("utf8mb4_bin")},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAlterDB),
+			},
+		},
+		"Success": {
+			reason: "Only the drifted options should be altered",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER DATABASE `example` COLLATE utf8mb4_bin DEFAULT ENCRYPTION 'N' READ ONLY = 1" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							CharacterSet:      ptr.To("utf8mb4"),
+							Collation:         ptr.To("utf8mb4_bin"),
+							DefaultEncryption: ptr.To(false),
+							ReadOnly:          ptr.To(true),
+						},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{
+							CharacterSet:      "utf8mb4",
+							Collation:         "utf8mb4_0900_ai_ci",
+							DefaultEncryption: ptr.To(true),
+							ReadOnly:          ptr.To(false),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	errBoom := errors.New("boom")
 
@@ -18,15 +18,15 @@ package grant
 
 import (
 	"context"
-	"fmt"
-	"regexp"
+	"database/sql"
+	"encoding/json"
 	"sort"
-	"strings"
 
 	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,34 +34,58 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/tls"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 	errTLSConfig    = "cannot load TLS config"
 
-	errNotGrant     = "managed resource is not a Grant custom resource"
-	errCreateGrant  = "cannot create grant"
-	errRevokeGrant  = "cannot revoke grant"
-	errCurrentGrant = "cannot show current grants"
-
-	allPrivileges      = "ALL PRIVILEGES"
-	errCodeNoSuchGrant = 1141
-	maxConcurrency     = 5
-)
-
-var (
-	grantRegex = regexp.MustCompile(`^GRANT (.+) ON (\S+)\.(\S+) TO \S+@\S+?(\sWITH GRANT OPTION)?$`)
+	errNotGrant          = "managed resource is not a Grant custom resource"
+	errCreateGrant       = "cannot create grant"
+	errRevokeGrant       = "cannot revoke grant"
+	errCurrentGrant      = "cannot show current grants"
+	errRestrictGrant     = "cannot partially revoke grant"
+	errLiftRestriction   = "cannot lift partial revoke of grant"
+	errRestrictionsScope = "restrictions can only be set on grants for all databases and tables"
+	errPatternAndDB      = "databasePattern cannot be set together with database"
+	errPatternScope      = "databasePattern can only be set on grants for all tables"
+
+	errServerVersion        = "cannot detect server version"
+	errUpdatePCStatus       = "cannot record server version in ProviderConfig status"
+	errUnsupportedPrivilege = "server does not support the requested privileges"
+	errNoGrantable          = "none of the requested privileges can be granted on a server of flavor %s"
+
+	errPrivilegeSetAndPrivileges = "cannot set both privileges and privilegeSet"
+	errUnknownPrivilegeSet       = "privilege set %q is not defined by ProviderConfig %q"
+
+	errCodeUnknownDatabase  = 1049
+	errCodeBadField         = 1054
+	errCodeNoSuchGrant      = 1141
+	errCodeNoSuchTable      = 1146
+	errCodeNoSuchTableGrant = 1147
+	maxConcurrency          = 5
 )
 
 // Setup adds a controller that reconciles Grant managed resources.
@@ -69,27 +93,38 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.GrantGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: mysql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
 		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: mysql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+	newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -109,18 +144,31 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: providerConfigName}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := mysql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+	privileges, err := privilegeSet(cr.Spec.ForProvider, pc)
+	if err != nil {
+		return nil, err
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (MySQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
 	tlsName, err := tls.LoadConfig(ctx, c.kube, providerConfigName, pc.Spec.TLS, pc.Spec.TLSConfig)
@@ -129,14 +177,117 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	}
 
 	return &external{
-		db:   c.newDB(s.Data, tlsName, cr.Spec.ForProvider.BinLog),
-		kube: c.kube,
+		db:           xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, tlsName, cr.Spec.ForProvider.BinLog, mysql.OptionsFromProviderConfig(pc)), mysql.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr),
+		kube:         c.kube,
+		self:         string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+		privilegeSet: privileges,
+		pc:           pc,
+		flavor:       ptr.Deref(pc.Spec.Flavor, sqlgen.FlavorVanilla),
 	}, nil
 }
 
+// privilegeSet returns the privileges of the privilege set the supplied
+// Grant refers to, or nil if it does not refer to one.
+func privilegeSet(gp v1alpha1.GrantParameters, pc *v1alpha1.ProviderConfig) (v1alpha1.GrantPrivileges, error) {
+	if gp.PrivilegeSet == nil {
+		return nil, nil
+	}
+	if len(gp.Privileges) > 0 {
+		return nil, errors.New(errPrivilegeSetAndPrivileges)
+	}
+	p, ok := pc.Spec.PrivilegeSets[*gp.PrivilegeSet]
+	if !ok {
+		return nil, errors.Errorf(errUnknownPrivilegeSet, *gp.PrivilegeSet, pc.GetName())
+	}
+	return p, nil
+}
+
 type external struct {
 	db   xsql.DB
 	kube client.Client
+
+	// self is the user the provider connects as.
+	self string
+
+	// privilegeSet holds the privileges of the privilege set of the Grant,
+	// if any.
+	privilegeSet v1alpha1.GrantPrivileges
+
+	// pc is the ProviderConfig of the Grant, in whose status the detected
+	// server version is recorded.
+	pc *v1alpha1.ProviderConfig
+
+	// flavor is the flavor of the server, which determines the privileges
+	// that can be granted on it.
+	flavor string
+}
+
+// privileges returns the privileges of the supplied Grant, or those of its
+// privilege set if it refers to one.
+func (c *external) privileges(cr *v1alpha1.Grant) []string {
+	if c.privilegeSet != nil {
+		return c.privilegeSet.ToStringSlice()
+	}
+	return cr.Spec.ForProvider.Privileges.ToStringSlice()
+}
+
+// diffPrivileges returns the desired privileges that are not observed, and
+// the observed privileges that are not desired. Privileges that cannot be
+// granted on the flavor of the server are ignored, as neither granting nor
+// revoking them would succeed.
+func (c *external) diffPrivileges(desired, observed []string) ([]string, []string) {
+	toGrant, toRevoke := sqlgen.DiffPrivileges(desired, observed)
+	return sqlgen.GrantablePrivileges(toGrant, c.flavor), sqlgen.GrantablePrivileges(toRevoke, c.flavor)
+}
+
+// checkPrivileges returns an error if the server does not support one of the
+// supplied privileges, rather than let it reject the grant with a syntax
+// error. The server version is only detected if one of the privileges is not
+// supported by every version, and is recorded in the ProviderConfig status.
+func (c *external) checkPrivileges(ctx context.Context, privileges []string) error {
+	if len(sqlgen.VersionedPrivileges(privileges)) == 0 {
+		return nil
+	}
+
+	pcName := ""
+	if c.pc != nil {
+		pcName = c.pc.GetName()
+	}
+	version, err := mysql.CachedServerVersion(ctx, pcName, c.db)
+	if err != nil {
+		return errors.Wrap(err, errServerVersion)
+	}
+	if err := c.recordVersion(ctx, version); err != nil {
+		return err
+	}
+
+	v, err := sqlgen.ParseServerVersion(version)
+	if err != nil {
+		return errors.Wrap(err, errServerVersion)
+	}
+	return errors.Wrap(sqlgen.ValidatePrivileges(privileges, v), errUnsupportedPrivilege)
+}
+
+// recordVersion records the supplied server version in the status of the
+// ProviderConfig, unless it is already recorded.
+func (c *external) recordVersion(ctx context.Context, version string) error {
+	if c.pc == nil || c.pc.Status.ServerVersion == version {
+		return nil
+	}
+	orig := c.pc.DeepCopy()
+	c.pc.Status.ServerVersion = version
+	return errors.Wrap(c.kube.Status().Patch(ctx, c.pc, client.MergeFrom(orig)), errUpdatePCStatus)
+}
+
+// checkReserved refuses to change the privileges of the account the
+// provider connects as, or one reserved by MySQL or the database service.
+func (c *external) checkReserved(cr *v1alpha1.Grant) error {
+	u := cr.Spec.ForProvider.User
+	if u == nil {
+		return nil
+	}
+	username, _ := mysql.SplitUserHost(*u)
+	return xsql.CheckReserved(cr, "account", *u, mysql.IsReservedUser(username, c.self))
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -146,10 +297,23 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	username, host := mysql.SplitUserHost(*cr.Spec.ForProvider.User)
-	dbname := defaultIdentifier(cr.Spec.ForProvider.Database)
+	dbname := grantDatabase(cr.Spec.ForProvider)
 	table := defaultIdentifier(cr.Spec.ForProvider.Table)
 
-	observedPrivileges, result, err := c.getPrivileges(ctx, username, host, dbname, table)
+	if cr.Spec.ForProvider.DatabasePattern != nil {
+		if cr.Spec.ForProvider.Database != nil {
+			return managed.ExternalObservation{}, errors.New(errPatternAndDB)
+		}
+		if table != "*" {
+			return managed.ExternalObservation{}, errors.New(errPatternScope)
+		}
+	}
+
+	if len(cr.Spec.ForProvider.Restrictions) > 0 && (dbname != "*" || table != "*") {
+		return managed.ExternalObservation{}, errors.New(errRestrictionsScope)
+	}
+
+	observedPrivileges, observedRestrictions, result, err := c.getPrivileges(ctx, username, host, cr.Spec.ForProvider)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
@@ -157,19 +321,34 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return *result, nil
 	}
 
+	desiredPrivileges := c.privileges(cr)
+	if grantsAll(desiredPrivileges) {
+		observedPrivileges = sqlgen.CollapsePrivileges(observedPrivileges, grantScope(dbname, table), c.flavor)
+	}
+
 	cr.Status.AtProvider.Privileges = observedPrivileges
+	cr.Status.AtProvider.Restrictions = observedRestrictions
 
-	desiredPrivileges := cr.Spec.ForProvider.Privileges.ToStringSlice()
-	toGrant, toRevoke := diffPermissions(desiredPrivileges, observedPrivileges)
+	toGrant, toRevoke := c.diffPrivileges(desiredPrivileges, observedPrivileges)
+	toRestrict, toLift := sqlgen.DiffRestrictions(cr.Spec.ForProvider.Restrictions, observedRestrictions)
 
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
 		ResourceExists:   true,
-		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0,
+		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0 && len(toRestrict) == 0 && len(toLift) == 0,
 	}, nil
 }
 
+// grantDatabase returns the quoted database, or database pattern, of the
+// supplied grant. The wildcards and escapes of a pattern are kept as is.
+func grantDatabase(p v1alpha1.GrantParameters) string {
+	if p.DatabasePattern != nil {
+		return mysql.QuoteIdentifier(*p.DatabasePattern)
+	}
+	return defaultIdentifier(p.Database)
+}
+
 func defaultIdentifier(identifier *string) string {
 	if identifier != nil && *identifier != "*" {
 		return mysql.QuoteIdentifier(*identifier)
@@ -178,36 +357,81 @@ func defaultIdentifier(identifier *string) string {
 	return "*"
 }
 
-func parseGrant(grant, dbname string, table string) (privileges []string) {
-	matches := grantRegex.FindStringSubmatch(grant)
-	if len(matches) == 5 && matches[2] == dbname && matches[3] == table {
-		privileges := strings.Split(matches[1], ", ")
-
-		if matches[4] != "" {
-			privileges = append(privileges, "GRANT OPTION")
+// grantsAll returns true if the supplied privileges include ALL.
+func grantsAll(privileges []string) bool {
+	for _, p := range privileges {
+		if p == "ALL" || p == sqlgen.AllPrivileges {
+			return true
 		}
+	}
+	return false
+}
 
-		return privileges
+// grantScope returns the scope of a grant on the supplied quoted database and
+// table.
+func grantScope(dbname, table string) sqlgen.Scope {
+	switch {
+	case dbname == "*":
+		return sqlgen.ScopeGlobal
+	case table == "*":
+		return sqlgen.ScopeDatabase
+	default:
+		return sqlgen.ScopeTable
 	}
+}
 
-	return nil
+// grantees returns the account of the supplied user as information_schema
+// reports it in the GRANTEE column. Servers that escape the names quote them
+// like string literals, doubling single quotes and escaping backslashes,
+// while others report them as they are, so both renderings are returned.
+// They are the same unless a name contains a quote or backslash.
+func grantees(username, host string) []interface{} {
+	return []interface{}{
+		"'" + username + "'@'" + host + "'",
+		sqlgen.QuoteValue(username) + "@" + sqlgen.QuoteValue(host),
+	}
 }
 
-func (c *external) getPrivileges(ctx context.Context, username, host, dbname, table string) ([]string, *managed.ExternalObservation, error) {
-	privileges, err := c.parseGrantRows(ctx, username, host, dbname, table)
-	if err != nil {
-		var myErr *mysqldriver.MySQLError
-		if errors.As(err, &myErr) && myErr.Number == errCodeNoSuchGrant {
-			// The user doesn't (yet) exist and therefore no grants either
-			return nil, &managed.ExternalObservation{ResourceExists: false}, nil
+// privilegesQuery returns the query of the privileges the supplied user holds
+// on the database, or database pattern, and table of a grant. Unlike SHOW
+// GRANTS, the information_schema tables it reads have one row per privilege,
+// and compare the unquoted names of the database and table.
+func privilegesQuery(username, host string, p v1alpha1.GrantParameters) xsql.Query {
+	dbname := ptr.Deref(p.Database, "*")
+	if p.DatabasePattern != nil {
+		dbname = *p.DatabasePattern
+	}
+	table := ptr.Deref(p.Table, "*")
+
+	switch {
+	case dbname == "*":
+		return xsql.Query{
+			String:     "SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM information_schema.USER_PRIVILEGES WHERE GRANTEE IN (?, ?)",
+			Parameters: grantees(username, host),
+		}
+	case table == "*":
+		return xsql.Query{
+			String:     "SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM information_schema.SCHEMA_PRIVILEGES WHERE GRANTEE IN (?, ?) AND TABLE_SCHEMA = ?",
+			Parameters: append(grantees(username, host), dbname),
 		}
+	default:
+		return xsql.Query{
+			String:     "SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM information_schema.TABLE_PRIVILEGES WHERE GRANTEE IN (?, ?) AND TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			Parameters: append(grantees(username, host), dbname, table),
+		}
+	}
+}
 
-		return nil, nil, errors.Wrap(err, errCurrentGrant)
+func (c *external) getPrivileges(ctx context.Context, username, host string, p v1alpha1.GrantParameters) ([]string, []string, *managed.ExternalObservation, error) {
+	privileges, err := c.selectPrivileges(ctx, privilegesQuery(username, host, p))
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errCurrentGrant)
 	}
 
 	// In mysql when all grants are revoked from user, it still grants usage (meaning no
 	// privileges) on *.* So the grant can be considered as non existent, just like when
-	// privileges slice is nil/empty
+	// privileges slice is nil/empty. A user that doesn't (yet) exist has no
+	// privileges at all.
 	// https://dev.mysql.com/doc/refman/8.0/en/privileges-provided.html#priv_usage
 	var ret []string
 	for _, p := range privileges {
@@ -217,59 +441,107 @@ func (c *external) getPrivileges(ctx context.Context, username, host, dbname, ta
 	}
 
 	if ret == nil {
-		return nil, &managed.ExternalObservation{ResourceExists: false}, nil
+		return nil, nil, &managed.ExternalObservation{ResourceExists: false}, nil
 	}
 
-	return ret, nil, nil
-}
+	// Partial revokes only apply to global grants.
+	if grantDatabase(p) != "*" {
+		return ret, nil, nil, nil
+	}
+	restrictions, err := c.selectRestrictions(ctx, username, host)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errCurrentGrant)
+	}
 
-func (c *external) parseGrantRows(ctx context.Context, username, host, dbname, table string) ([]string, error) {
-	query := fmt.Sprintf("SHOW GRANTS FOR %s@%s", mysql.QuoteValue(username), mysql.QuoteValue(host))
-	rows, err := c.db.Query(ctx, xsql.Query{String: query})
+	return ret, restrictions, nil, nil
+}
 
+// selectPrivileges returns the privileges selected by the supplied query.
+// information_schema reports WITH GRANT OPTION as IS_GRANTABLE on every
+// privilege of a grant, rather than as a privilege, so it is returned as
+// the GrantOption privilege.
+func (c *external) selectPrivileges(ctx context.Context, q xsql.Query) ([]string, error) {
+	rows, err := c.db.Query(ctx, q)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck
 
 	var privileges []string
+	grantOption := false
 	for rows.Next() {
-		var grant string
-		if err := rows.Scan(&grant); err != nil {
+		var privilege, grantable string
+		if err := rows.Scan(&privilege, &grantable); err != nil {
 			return nil, err
 		}
-		p := parseGrant(grant, dbname, table)
-
-		if p != nil {
-			// found the grant we were looking for
-			privileges = p
-			break
-		}
+		privileges = append(privileges, privilege)
+		grantOption = grantOption || grantable == "YES"
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	if grantOption {
+		privileges = append(privileges, sqlgen.GrantOption)
+	}
 	return privileges, nil
 }
 
+// selectRestrictions returns the databases on which the privileges of the
+// supplied user are partially revoked. MySQL stores them in the
+// User_attributes of the user, which versions without partial revokes lack.
+// See https://dev.mysql.com/doc/refman/8.0/en/partial-revokes.html
+func (c *external) selectRestrictions(ctx context.Context, username, host string) ([]string, error) {
+	var attr sql.NullString
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT JSON_EXTRACT(User_attributes, '$.Restrictions[*].Database') FROM mysql.user WHERE User = ? AND Host = ?",
+		Parameters: []interface{}{username, host},
+	}, &attr)
+	var myErr *mysqldriver.MySQLError
+	if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &myErr) && myErr.Number == errCodeBadField) {
+		return nil, nil
+	}
+	if err != nil || !attr.Valid {
+		return nil, err
+	}
+
+	var restrictions []string
+	err = json.Unmarshal([]byte(attr.String), &restrictions)
+	return restrictions, err
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Grant)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotGrant)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	desired := c.privileges(cr)
+	grantable := sqlgen.GrantablePrivileges(desired, c.flavor)
+	if err := c.checkPrivileges(ctx, grantable); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	username, host := mysql.SplitUserHost(*cr.Spec.ForProvider.User)
-	dbname := defaultIdentifier(cr.Spec.ForProvider.Database)
+	dbname := grantDatabase(cr.Spec.ForProvider)
 	table := defaultIdentifier(cr.Spec.ForProvider.Table)
 
-	privileges, grantOption := getPrivilegesString(cr.Spec.ForProvider.Privileges.ToStringSlice())
-	query := createGrantQuery(privileges, dbname, username, host, table, grantOption)
+	privileges, grantOption := sqlgen.PrivilegesString(grantable)
+	if privileges == "" && len(grantable) < len(desired) {
+		return managed.ExternalCreation{}, errors.Errorf(errNoGrantable, c.flavor)
+	}
+	query := sqlgen.GrantQuery(privileges, dbname, username, host, table, grantOption)
 
 	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
 		return managed.ExternalCreation{}, err
 	}
+
+	if err := c.restrict(ctx, privileges, username, host, cr.Spec.ForProvider.Restrictions); err != nil {
+		return managed.ExternalCreation{}, err
+	}
 	return managed.ExternalCreation{}, nil
 }
 
@@ -279,18 +551,25 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotGrant)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	username, host := mysql.SplitUserHost(*cr.Spec.ForProvider.User)
-	dbname := defaultIdentifier(cr.Spec.ForProvider.Database)
+	dbname := grantDatabase(cr.Spec.ForProvider)
 	table := defaultIdentifier(cr.Spec.ForProvider.Table)
 
 	observed := cr.Status.AtProvider.Privileges
-	desired := cr.Spec.ForProvider.Privileges.ToStringSlice()
-	toGrant, toRevoke := diffPermissions(desired, observed)
+	desired := c.privileges(cr)
+	toGrant, toRevoke := c.diffPrivileges(desired, observed)
+	if err := c.checkPrivileges(ctx, toGrant); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
 
 	if len(toRevoke) > 0 {
 		sort.Strings(toRevoke)
-		privileges, grantOption := getPrivilegesString(toRevoke)
-		query := createRevokeQuery(privileges, dbname, username, host, table, grantOption)
+		privileges, grantOption := sqlgen.PrivilegesString(toRevoke)
+		query := sqlgen.RevokeQuery(privileges, dbname, username, host, table, grantOption)
 		if err := mysql.ExecWrapper(ctx, c.db,
 			mysql.ExecQuery{
 				Query: query, ErrorValue: errRevokeGrant,
@@ -299,65 +578,62 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
+	desiredRestrictions := cr.Spec.ForProvider.Restrictions
+	toRestrict, toLift := sqlgen.DiffRestrictions(desiredRestrictions, cr.Status.AtProvider.Restrictions)
+
 	if len(toGrant) > 0 {
 		sort.Strings(toGrant)
-		privileges, grantOption := getPrivilegesString(toGrant)
-		query := createGrantQuery(privileges, dbname, username, host, table, grantOption)
+		privileges, grantOption := sqlgen.PrivilegesString(toGrant)
+		query := sqlgen.GrantQuery(privileges, dbname, username, host, table, grantOption)
 		if err := mysql.ExecWrapper(ctx, c.db,
 			mysql.ExecQuery{
 				Query: query, ErrorValue: errCreateGrant,
 			}); err != nil {
 			return managed.ExternalUpdate{}, err
 		}
-	}
-	return managed.ExternalUpdate{}, nil
-}
 
-// getPrivilegesString returns a privileges string without grant option item and a grantOption boolean
-func getPrivilegesString(privileges []string) (string, bool) {
-	privilegesWithoutGrantOption := []string{}
-	grantOption := false
-	for _, p := range privileges {
-		if p == "GRANT OPTION" {
-			grantOption = true
-			continue
+		// Newly granted global privileges are not covered by the existing
+		// partial revokes, so they have to be restricted as well.
+		if err := c.restrict(ctx, privileges, username, host, sqlgen.Subtract(desiredRestrictions, toRestrict)); err != nil {
+			return managed.ExternalUpdate{}, err
 		}
-		privilegesWithoutGrantOption = append(privilegesWithoutGrantOption, p)
 	}
-	out := strings.Join(privilegesWithoutGrantOption, ", ")
-	return out, grantOption
-}
-
-func createRevokeQuery(privileges, dbname, username, host, table string, grantOption bool) string {
-	result := fmt.Sprintf("REVOKE %s ON %s.%s FROM %s@%s",
-		privileges,
-		dbname,
-		table,
-		mysql.QuoteValue(username),
-		mysql.QuoteValue(host),
-	)
 
-	if grantOption {
-		result = fmt.Sprintf("%s WITH GRANT OPTION", result)
+	privileges, _ := sqlgen.PrivilegesString(desired)
+	if err := c.restrict(ctx, privileges, username, host, toRestrict); err != nil {
+		return managed.ExternalUpdate{}, err
 	}
 
-	return result
+	for _, r := range toLift {
+		// Granting the privileges on a database that is partially revoked
+		// removes the partial revoke.
+		query := sqlgen.GrantQuery(privileges, mysql.QuoteIdentifier(r), username, host, "*", false)
+		if err := mysql.ExecWrapper(ctx, c.db,
+			mysql.ExecQuery{
+				Query: query, ErrorValue: errLiftRestriction,
+			}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	return managed.ExternalUpdate{}, nil
 }
 
-func createGrantQuery(privileges, dbname, username, host, table string, grantOption bool) string {
-	result := fmt.Sprintf("GRANT %s ON %s.%s TO %s@%s",
-		privileges,
-		dbname,
-		table,
-		mysql.QuoteValue(username),
-		mysql.QuoteValue(host),
-	)
-
-	if grantOption {
-		result = fmt.Sprintf("%s WITH GRANT OPTION", result)
+// restrict partially revokes the supplied global privileges on each of the
+// supplied databases.
+func (c *external) restrict(ctx context.Context, privileges, username, host string, databases []string) error {
+	if privileges == "" {
+		return nil
 	}
-
-	return result
+	for _, d := range databases {
+		query := sqlgen.RevokeQuery(privileges, mysql.QuoteIdentifier(d), username, host, "*", false)
+		if err := mysql.ExecWrapper(ctx, c.db,
+			mysql.ExecQuery{
+				Query: query, ErrorValue: errRestrictGrant,
+			}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -366,17 +642,22 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotGrant)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return err
+	}
+
 	username, host := mysql.SplitUserHost(*cr.Spec.ForProvider.User)
-	dbname := defaultIdentifier(cr.Spec.ForProvider.Database)
+	dbname := grantDatabase(cr.Spec.ForProvider)
 	table := defaultIdentifier(cr.Spec.ForProvider.Table)
 
-	privileges, grantOption := getPrivilegesString(cr.Spec.ForProvider.Privileges.ToStringSlice())
-	query := createRevokeQuery(privileges, dbname, username, host, table, grantOption)
+	privileges, grantOption := sqlgen.PrivilegesString(c.privileges(cr))
+	query := sqlgen.RevokeQuery(privileges, dbname, username, host, table, grantOption)
 
 	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errRevokeGrant}); err != nil {
 		var myErr *mysqldriver.MySQLError
-		if errors.As(err, &myErr) && myErr.Number == errCodeNoSuchGrant {
-			// MySQL automatically deletes related grants if the user has been deleted
+		if errors.As(err, &myErr) && isGone(myErr.Number) {
+			// MySQL automatically deletes related grants if the user has been
+			// deleted, and a table grant cannot outlive its table or database.
 			return nil
 		}
 
@@ -386,35 +667,13 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	return nil
 }
 
-func diffPermissions(desired, observed []string) ([]string, []string) {
-	desiredMap := make(map[string]struct{}, len(desired))
-	observedMap := make(map[string]struct{}, len(observed))
-
-	for _, desiredPrivilege := range desired {
-		// Special case because ALL is an alias for "ALL PRIVILEGES"
-		desiredPrivilegeMapped := strings.ReplaceAll(desiredPrivilege, allPrivileges, "ALL")
-		desiredMap[desiredPrivilegeMapped] = struct{}{}
-	}
-	for _, observedPrivilege := range observed {
-		// Special case because ALL is an alias for "ALL PRIVILEGES"
-		observedPrivilegeMapped := strings.ReplaceAll(observedPrivilege, allPrivileges, "ALL")
-		observedMap[observedPrivilegeMapped] = struct{}{}
+// isGone returns true if a revoke failed because the grant, or the object it
+// was made on, no longer exists.
+func isGone(code uint16) bool {
+	switch code {
+	case errCodeNoSuchGrant, errCodeUnknownDatabase, errCodeNoSuchTable, errCodeNoSuchTableGrant:
+		return true
+	default:
+		return false
 	}
-
-	var toGrant []string
-	var toRevoke []string
-
-	for desiredPrivilege := range desiredMap {
-		if _, ok := observedMap[desiredPrivilege]; !ok {
-			toGrant = append(toGrant, desiredPrivilege)
-		}
-	}
-
-	for observedPrivilege := range observedMap {
-		if _, ok := desiredMap[observedPrivilege]; !ok {
-			toRevoke = append(toRevoke, observedPrivilege)
-		}
-	}
-
-	return toGrant, toRevoke
 }
@@ -38,6 +38,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mysql"
 )
 
 type mockDB struct {
@@ -74,7 +75,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+		newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -173,6 +174,33 @@ func TestConnect(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errGetSecret),
 		},
+		"ErrUnknownPrivilegeSet": {
+			reason: "An error should be returned if the privilege set is not defined by our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						o := obj.(*v1alpha1.ProviderConfig)
+						o.SetName("default")
+						o.Spec.PrivilegeSets = map[string]v1alpha1.GrantPrivileges{"readonly": {"SELECT"}}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{Name: "default"},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							PrivilegeSet: ptr.To("readwrite"),
+						},
+					},
+				},
+			},
+			want: errors.Errorf(errUnknownPrivilegeSet, "readwrite", "default"),
+		},
 	}
 
 	for name, tc := range cases {
@@ -190,7 +218,8 @@ func TestObserve(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db     xsql.DB
+		flavor string
 	}
 
 	type args struct {
@@ -199,9 +228,10 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o                  managed.ExternalObservation
-		err                error
-		observedPrivileges []string
+		o                    managed.ExternalObservation
+		err                  error
+		observedPrivileges   []string
+		observedRestrictions []string
 	}
 
 	cases := map[string]struct {
@@ -268,11 +298,7 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT CREATE, DROP ON `success-db`.* TO 'no-user'@%").
-								RowError(0, &mysql.MySQLError{Number: errCodeNoSuchGrant}),
-						), nil
+						return privilegeRows(false), nil
 					},
 				},
 			},
@@ -296,11 +322,7 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows(
-								[]string{"Grants"},
-							).AddRow("GRANT " + allPrivileges + " ON `success-db`.* TO 'success-user'@%"),
-						), nil
+						return privilegeRows(false, databaseAll...), nil
 					},
 				},
 			},
@@ -321,7 +343,7 @@ func TestObserve(t *testing.T) {
 					ResourceUpToDate: true,
 				},
 				err:                nil,
-				observedPrivileges: []string{allPrivileges},
+				observedPrivileges: []string{sqlgen.AllPrivileges},
 			},
 		},
 		"SuccessGrantOptionNoDatabase": {
@@ -329,12 +351,9 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows(
-								[]string{"Grants"},
-							).AddRow("GRANT INSERT, SELECT ON *.* TO 'success-user'@% WITH GRANT OPTION"),
-						), nil
+						return privilegeRows(true, "INSERT", "SELECT"), nil
 					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
 				},
 			},
 			args: args{
@@ -365,11 +384,7 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows(
-								[]string{"Grants"},
-							).AddRow("GRANT INSERT, SELECT ON `success-db`.* TO 'success-user'@% WITH GRANT OPTION"),
-						), nil
+						return privilegeRows(true, "INSERT", "SELECT"), nil
 					},
 				},
 			},
@@ -402,11 +417,7 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows(
-								[]string{"Grants"},
-							).AddRow("GRANT CREATE ON `success-db`.* TO 'diff-user'@%"),
-						), nil
+						return privilegeRows(false, "CREATE"), nil
 					},
 				},
 			},
@@ -434,11 +445,9 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT INSERT ON *.* TO 'success-user'@%"),
-						), nil
+						return privilegeRows(false, "INSERT"), nil
 					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
 				},
 			},
 			args: args{
@@ -465,10 +474,7 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT USAGE ON *.* TO 'success-user'@%"),
-						), nil
+						return privilegeRows(false, "USAGE"), nil
 					},
 				},
 			},
@@ -490,15 +496,11 @@ func TestObserve(t *testing.T) {
 			},
 		},
 		"SuccessManyGrants": {
-			reason: "We should return no error if there are more than one grant for a user",
+			reason: "We should see the grant out of sync when the user holds more privileges than desired",
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT CREATE, DROP ON `success-db`.* TO 'success-user'@%").
-								AddRow("GRANT EVENT ON `success-db`.* TO 'success-user'@%"),
-						), nil
+						return privilegeRows(false, "CREATE", "DROP", "EVENT"), nil
 					},
 				},
 			},
@@ -516,10 +518,10 @@ func TestObserve(t *testing.T) {
 			want: want{
 				o: managed.ExternalObservation{
 					ResourceExists:   true,
-					ResourceUpToDate: true,
+					ResourceUpToDate: false,
 				},
 				err:                nil,
-				observedPrivileges: []string{"CREATE", "DROP"},
+				observedPrivileges: []string{"CREATE", "DROP", "EVENT"},
 			},
 		},
 		"SuccessGrantNoDatabaseNoTable": {
@@ -527,11 +529,9 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT CREATE, DROP ON *.* TO 'success-user'@%"),
-						), nil
+						return privilegeRows(false, "CREATE", "DROP"), nil
 					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
 				},
 			},
 			args: args{
@@ -558,10 +558,10 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT CREATE, DROP ON `success-db`.`success-table` TO 'success-user'@%"),
-						), nil
+						if !strings.Contains(q.String, "TABLE_PRIVILEGES") {
+							return nil, errBoom
+						}
+						return privilegeRows(false, "CREATE", "DROP"), nil
 					},
 				},
 			},
@@ -591,10 +591,10 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-						return mockRowsToSQLRows(
-							sqlmock.NewRows([]string{"Grants"}).
-								AddRow("GRANT CREATE, DROP ON `success-db`.`success-table` TO 'success-user'@%"),
-						), nil
+						if !strings.Contains(q.String, "TABLE_PRIVILEGES") {
+							return nil, errBoom
+						}
+						return privilegeRows(false, "CREATE", "DROP"), nil
 					},
 				},
 			},
@@ -619,11 +619,289 @@ func TestObserve(t *testing.T) {
 				observedPrivileges: []string{"CREATE", "DROP"},
 			},
 		},
+		"ErrRestrictionsScope": {
+			reason: "An error should be returned if restrictions are set on a grant that is not global",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:     ptr.To("success-db"),
+							User:         ptr.To("success-user"),
+							Privileges:   v1alpha1.GrantPrivileges{"SELECT"},
+							Restrictions: []string{"mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errRestrictionsScope),
+			},
+		},
+		"ErrPatternAndDatabase": {
+			reason: "An error should be returned if both a database and a database pattern are set",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:        ptr.To("success-db"),
+							DatabasePattern: ptr.To(`app\_%`),
+							User:            ptr.To("success-user"),
+							Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errPatternAndDB),
+			},
+		},
+		"ErrPatternScope": {
+			reason: "An error should be returned if a database pattern is set on a grant for a single table",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							DatabasePattern: ptr.To(`app\_%`),
+							Table:           ptr.To("users"),
+							User:            ptr.To("success-user"),
+							Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errPatternScope),
+			},
+		},
+		"SuccessDatabasePattern": {
+			reason: "We should find the grant on a database pattern as stored, with its escapes",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if diff := cmp.Diff([]interface{}{"'success-user'@'%'", "'success-user'@'%'", `app\_%`}, q.Parameters); diff != "" {
+							return nil, errors.New(diff)
+						}
+						return privilegeRows(false, "INSERT", "SELECT"), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							DatabasePattern: ptr.To(`app\_%`),
+							User:            ptr.To("success-user"),
+							Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				observedPrivileges: []string{"INSERT", "SELECT"},
+			},
+		},
+		"SuccessQuotedUser": {
+			reason: "We should find the grant of a user whose name has a quote however the server renders it",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if diff := cmp.Diff([]interface{}{"'o'brien'@'%'", "'o''brien'@'%'", "success-db"}, q.Parameters); diff != "" {
+							return nil, errors.New(diff)
+						}
+						return privilegeRows(false, "SELECT"), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("success-db"),
+							User:       ptr.To("o'brien"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				observedPrivileges: []string{"SELECT"},
+			},
+		},
+		"SuccessPartialRevokes": {
+			reason: "We should read partial revokes of a global grant as restrictions",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return privilegeRows(false, "INSERT", "SELECT"), nil
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*sql.NullString) = sql.NullString{String: `["mysql", "sys"]`, Valid: true}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:         ptr.To("success-user"),
+							Privileges:   v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+							Restrictions: []string{"sys", "mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				observedPrivileges:   []string{"INSERT", "SELECT"},
+				observedRestrictions: []string{"mysql", "sys"},
+			},
+		},
+		"SuccessDiffPartialRevokes": {
+			reason: "We should see the grant out of sync when the partial revokes differ from the restrictions",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return privilegeRows(false, "SELECT"), nil
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*sql.NullString) = sql.NullString{String: `["mysql"]`, Valid: true}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("success-user"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				observedPrivileges:   []string{"SELECT"},
+				observedRestrictions: []string{"mysql"},
+			},
+		},
+		"SuccessAllOnRDS": {
+			reason: "A global grant of ALL should be up to date on RDS without the privileges its master user cannot grant",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return privilegeRows(false,
+							"ALTER", "CREATE", "CREATE VIEW", "DELETE", "DROP", "INDEX", "INSERT",
+							"REFERENCES", "SELECT", "SHOW VIEW", "TRIGGER", "UPDATE",
+							"ALTER ROUTINE", "CREATE ROUTINE", "CREATE TEMPORARY TABLES", "EVENT",
+							"EXECUTE", "LOCK TABLES",
+							"CREATE USER", "PROCESS", "RELOAD", "REPLICATION SLAVE", "SHOW DATABASES"), nil
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return sql.ErrNoRows
+					},
+				},
+				flavor: sqlgen.FlavorRDS,
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("success-user"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				observedPrivileges: []string{sqlgen.AllPrivileges},
+			},
+		},
+		"SuccessUngrantableOnAurora": {
+			reason: "Privileges that cannot be granted on Aurora should not keep the grant out of date",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return privilegeRows(false, "SELECT"), nil
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return sql.ErrNoRows
+					},
+				},
+				flavor: sqlgen.FlavorAurora,
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("success-user"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT", "SUPER"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				observedPrivileges: []string{"SELECT"},
+			},
+		},
+		"SuccessNoPartialRevokes": {
+			reason: "We should observe no restrictions on servers that do not support partial revokes",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return privilegeRows(false, "SELECT"), nil
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return &mysql.MySQLError{Number: errCodeBadField}
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("success-user"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				observedPrivileges: []string{"SELECT"},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, flavor: tc.fields.flavor}
 			got, err := e.Observe(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -637,6 +915,9 @@ func TestObserve(t *testing.T) {
 				if diff := cmp.Diff(tc.want.observedPrivileges, cr.Status.AtProvider.Privileges, equateSlices()...); diff != "" {
 					t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 				}
+				if diff := cmp.Diff(tc.want.observedRestrictions, cr.Status.AtProvider.Restrictions, equateSlices()...); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+				}
 			}
 		})
 	}
@@ -646,7 +927,11 @@ func TestCreate(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db           xsql.DB
+		kube         client.Client
+		privilegeSet v1alpha1.GrantPrivileges
+		pc           *v1alpha1.ProviderConfig
+		flavor       string
 	}
 
 	type args struct {
@@ -701,12 +986,18 @@ func TestCreate(t *testing.T) {
 				err: errors.Wrap(errBoom, errCreateGrant),
 			},
 		},
-		"Success": {
-			reason: "No error should be returned when we successfully create a grant",
+		"SuccessSkipsUngrantable": {
+			reason: "Privileges that cannot be granted on RDS should be left out of the grant",
 			fields: fields{
 				db: &mockDB{
-					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "GRANT SELECT ON `test-example`.* TO 'test-example'@'%'" {
+							return errors.Errorf("unexpected query %q", q.String)
+						}
+						return nil
+					},
 				},
+				flavor: sqlgen.FlavorRDS,
 			},
 			args: args{
 				mg: &v1alpha1.Grant{
@@ -714,17 +1005,33 @@ func TestCreate(t *testing.T) {
 						ForProvider: v1alpha1.GrantParameters{
 							Database:   ptr.To("test-example"),
 							User:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+							Privileges: v1alpha1.GrantPrivileges{"SELECT", "FILE"},
+						},
+					},
+				},
+			},
+		},
+		"ErrNoGrantable": {
+			reason: "An error should be returned if none of the privileges can be granted on RDS",
+			fields: fields{
+				flavor: sqlgen.FlavorRDS,
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"SUPER"},
 						},
 					},
 				},
 			},
 			want: want{
-				err: nil,
+				err: errors.Errorf(errNoGrantable, sqlgen.FlavorRDS),
 			},
 		},
-		"SuccessNoDatabase": {
-			reason: "No error should be returned when we successfully create a grant with no database",
+		"Success": {
+			reason: "No error should be returned when we successfully create a grant",
 			fields: fields{
 				db: &mockDB{
 					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
@@ -734,6 +1041,7 @@ func TestCreate(t *testing.T) {
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
 							User:       ptr.To("test-example"),
 							Privileges: v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
 						},
@@ -744,16 +1052,64 @@ func TestCreate(t *testing.T) {
 				err: nil,
 			},
 		},
-		"SuccessGrantOption": {
-			reason: "No error should be returned when we successfully create a grant with grant option",
+		"SuccessDatabasePattern": {
+			reason: "The database pattern should be granted on with its wildcards and escapes as is",
 			fields: fields{
 				db: &mockDB{
 					MockExec: func(ctx context.Context, q xsql.Query) error {
-						if strings.HasPrefix(q.String, "GRANT") &&
-							!strings.HasSuffix(q.String, "WITH GRANT OPTION") {
-							return errBoom
+						if q.String != "GRANT INSERT, SELECT ON `app\\_%`.* TO 'test-example'@'%'" {
+							return errors.Errorf("unexpected query: %s", q.String)
 						}
-
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							DatabasePattern: ptr.To(`app\_%`),
+							User:            ptr.To("test-example"),
+							Privileges:      v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessNoDatabase": {
+			reason: "No error should be returned when we successfully create a grant with no database",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessGrantOption": {
+			reason: "No error should be returned when we successfully create a grant with grant option",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "GRANT") &&
+							!strings.HasSuffix(q.String, "WITH GRANT OPTION") {
+							return errBoom
+						}
+
 						return nil
 					},
 				},
@@ -773,11 +1129,208 @@ func TestCreate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrExecRestrict": {
+			reason: "Any errors encountered while partially revoking the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "REVOKE") {
+							return errBoom
+						}
+
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:         ptr.To("test-example"),
+							Privileges:   v1alpha1.GrantPrivileges{"SELECT"},
+							Restrictions: []string{"mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errRestrictGrant),
+			},
+		},
+		"SuccessRestrictions": {
+			reason: "No error should be returned when we successfully create a partially revoked grant",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "REVOKE") &&
+							q.String != "REVOKE SELECT ON `mysql`.* FROM 'test-example'@'%'" {
+							return errBoom
+						}
+
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:         ptr.To("test-example"),
+							Privileges:   v1alpha1.GrantPrivileges{"SELECT", "GRANT OPTION"},
+							Restrictions: []string{"mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessPrivilegeSet": {
+			reason: "The privileges of the privilege set of the grant should be granted",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "GRANT INSERT, SELECT ON `test-example`.* TO 'test-example'@'%'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+				privilegeSet: v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:     ptr.To("test-example"),
+							User:         ptr.To("test-example"),
+							PrivilegeSet: ptr.To("readwrite"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrServerVersion": {
+			reason: "Any errors encountered while detecting the server version should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"BACKUP_ADMIN"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errServerVersion),
+			},
+		},
+		"ErrUnsupportedPrivilege": {
+			reason: "Privileges the server does not support should be rejected before granting them",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "5.7.44-log"
+						return nil
+					},
+				},
+				kube: &test.MockClient{
+					MockStatusPatch: test.NewMockSubResourcePatchFn(nil),
+				},
+				pc: &v1alpha1.ProviderConfig{},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT", "BACKUP_ADMIN"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.New("privilege BACKUP_ADMIN requires MySQL 8.0 or later, the server is MySQL 5.7.44"), errUnsupportedPrivilege),
+			},
+		},
+		"ErrRecordServerVersion": {
+			reason: "Any errors encountered while recording the server version should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "8.0.36"
+						return nil
+					},
+				},
+				kube: &test.MockClient{
+					MockStatusPatch: test.NewMockSubResourcePatchFn(errBoom),
+				},
+				pc: &v1alpha1.ProviderConfig{},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"BACKUP_ADMIN"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdatePCStatus),
+			},
+		},
+		"SuccessSupportedPrivilege": {
+			reason: "Privileges the server supports should be granted once its version is recorded",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "8.0.36"
+						return nil
+					},
+				},
+				kube: &test.MockClient{
+					MockStatusPatch: test.NewMockSubResourcePatchFn(nil, func(obj client.Object) error {
+						if v := obj.(*v1alpha1.ProviderConfig).Status.ServerVersion; v != "8.0.36" {
+							return errors.Errorf("unexpected server version %q", v)
+						}
+						return nil
+					}),
+				},
+				pc: &v1alpha1.ProviderConfig{},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT", "BACKUP_ADMIN"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, kube: tc.fields.kube, privilegeSet: tc.fields.privilegeSet, pc: tc.fields.pc, flavor: tc.fields.flavor}
 			got, err := e.Create(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -793,7 +1346,8 @@ func TestUpdate(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db   xsql.DB
+		self string
 	}
 
 	type args struct {
@@ -821,6 +1375,24 @@ func TestUpdate(t *testing.T) {
 				err: errors.New(errNotGrant),
 			},
 		},
+		"ErrConnectionAccount": {
+			reason: "An error should be returned if the grantee is the user the provider connects as",
+			fields: fields{
+				self: "admin",
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User: ptr.To("admin@%"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: xsql.CheckReserved(&v1alpha1.Grant{}, "account", "admin@%", true),
+			},
+		},
 		"ErrExecRevokeNotRequired": {
 			reason: "Any errors encountered while revoking a not required privilege from the desired ones should be returned",
 			fields: fields{
@@ -854,6 +1426,36 @@ func TestUpdate(t *testing.T) {
 				err: errors.Wrap(errBoom, errRevokeGrant),
 			},
 		},
+		"ErrUnsupportedPrivilege": {
+			reason: "Missing privileges the server does not support should be rejected before changing the grant",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "10.4.32-MariaDB"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT", "BINLOG ADMIN"},
+						},
+					},
+					Status: v1alpha1.GrantStatus{
+						AtProvider: v1alpha1.GrantObservation{
+							Privileges: []string{"INSERT", "SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.New("privilege BINLOG ADMIN requires MariaDB 10.5 or later, the server is MariaDB 10.4.32"), errUnsupportedPrivilege),
+			},
+		},
 		"ErrExecGrantMissing": {
 			reason: "Any errors encountered while granting a missing privilege from the desired ones should be returned",
 			fields: fields{
@@ -1014,12 +1616,82 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrExecLiftRestriction": {
+			reason: "Any errors encountered while lifting a partial revoke should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "GRANT") {
+							return errBoom
+						}
+
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+					Status: v1alpha1.GrantStatus{
+						AtProvider: v1alpha1.GrantObservation{
+							Privileges:   []string{"SELECT"},
+							Restrictions: []string{"mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errLiftRestriction),
+			},
+		},
+		"SuccessRestrictNewPrivileges": {
+			reason: "Newly granted global privileges should be partially revoked on existing restrictions",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "REVOKE") &&
+							q.String != "REVOKE INSERT ON `mysql`.* FROM 'test-example'@'%'" &&
+							q.String != "REVOKE INSERT, SELECT ON `sys`.* FROM 'test-example'@'%'" {
+							return errBoom
+						}
+
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User:         ptr.To("test-example"),
+							Privileges:   v1alpha1.GrantPrivileges{"INSERT", "SELECT"},
+							Restrictions: []string{"mysql", "sys"},
+						},
+					},
+					Status: v1alpha1.GrantStatus{
+						AtProvider: v1alpha1.GrantObservation{
+							Privileges:   []string{"SELECT"},
+							Restrictions: []string{"mysql"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			e := external{
-				db: tc.fields.db,
+				db:   tc.fields.db,
+				self: tc.fields.self,
 			}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1057,6 +1729,19 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.New(errNotGrant),
 		},
+		"ErrReservedAccount": {
+			reason: "An error should be returned if the grantee is reserved by MySQL",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							User: ptr.To("root@localhost"),
+						},
+					},
+				},
+			},
+			want: xsql.CheckReserved(&v1alpha1.Grant{}, "account", "root@localhost", true),
+		},
 		"ErrDropGrant": {
 			reason: "Errors dropping a grant should be returned",
 			fields: fields{
@@ -1126,6 +1811,57 @@ func TestDelete(t *testing.T) {
 			},
 			want: nil,
 		},
+		"TableAlreadyDropped": {
+			reason: "No error should be returned if the table of the grant has been dropped",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database: ptr.To("test-example"),
+							Table:    ptr.To("test-table"),
+							User:     ptr.To("test-example"),
+						},
+					},
+				},
+			},
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "REVOKE") {
+							return &mysql.MySQLError{Number: errCodeNoSuchTable}
+						}
+
+						return nil
+					},
+				},
+			},
+			want: nil,
+		},
+		"DatabaseAlreadyDropped": {
+			reason: "No error should be returned if the database of the grant has been dropped",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database: ptr.To("test-example"),
+							User:     ptr.To("test-example"),
+						},
+					},
+				},
+			},
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "REVOKE") {
+							return &mysql.MySQLError{Number: errCodeUnknownDatabase}
+						}
+
+						return nil
+					},
+				},
+			},
+			want: nil,
+		},
 	}
 
 	for name, tc := range cases {
@@ -1139,6 +1875,28 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+// databaseAll are the privileges information_schema lists for a grant of ALL
+// on a database.
+var databaseAll = []string{
+	"ALTER", "ALTER ROUTINE", "CREATE", "CREATE ROUTINE", "CREATE TEMPORARY TABLES",
+	"CREATE VIEW", "DELETE", "DROP", "EVENT", "EXECUTE", "INDEX", "INSERT",
+	"LOCK TABLES", "REFERENCES", "SELECT", "SHOW VIEW", "TRIGGER", "UPDATE",
+}
+
+// privilegeRows returns the rows information_schema reports for the supplied
+// privileges.
+func privilegeRows(grantable bool, privileges ...string) *sql.Rows {
+	isGrantable := "NO"
+	if grantable {
+		isGrantable = "YES"
+	}
+	rows := sqlmock.NewRows([]string{"PRIVILEGE_TYPE", "IS_GRANTABLE"})
+	for _, p := range privileges {
+		rows.AddRow(p, isGrantable)
+	}
+	return mockRowsToSQLRows(rows)
+}
+
 func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
 	db, mock, _ := sqlmock.New()
 	mock.ExpectQuery("select").WillReturnRows(mockRows)
@@ -1150,97 +1908,6 @@ func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
 	return rows
 }
 
-func Test_diffPermissions(t *testing.T) {
-	type args struct {
-		desired  []string
-		observed []string
-	}
-	type want struct {
-		toGrant  []string
-		toRevoke []string
-	}
-	cases := map[string]struct {
-		args
-		want
-	}{
-		"AsDesired": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"CREATE TABLE", "DELETE"},
-			},
-			want: want{
-				toGrant:  nil,
-				toRevoke: nil,
-			},
-		},
-		"AsDesiredOrderNotMatter": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"DELETE", "CREATE TABLE"},
-			},
-			want: want{
-				toGrant:  nil,
-				toRevoke: nil,
-			},
-		},
-		"NeedsGrant": {
-			args: args{
-				desired:  []string{"CREATE TABLE", "DELETE"},
-				observed: []string{"CREATE TABLE"},
-			},
-			want: want{
-				toGrant: []string{"DELETE"},
-			},
-		},
-		"NeedsRevoke": {
-			args: args{
-				desired:  []string{"CREATE TABLE"},
-				observed: []string{"CREATE TABLE", "DELETE"},
-			},
-			want: want{
-				toRevoke: []string{"DELETE"},
-			},
-		},
-		"NeedsBoth": {
-			args: args{
-				desired:  []string{"CREATE TABLE"},
-				observed: []string{"DELETE"},
-			},
-			want: want{
-				toGrant:  []string{"CREATE TABLE"},
-				toRevoke: []string{"DELETE"},
-			},
-		},
-		"GrantAll": {
-			args: args{
-				desired: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-			want: want{
-				toGrant: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-		},
-		"RevokeAll": {
-			args: args{
-				observed: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-			want: want{
-				toRevoke: []string{"CREATE TABLE", "DELETE", "INSERT"},
-			},
-		},
-	}
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			gotToGrant, gotToRevoke := diffPermissions(tc.args.desired, tc.args.observed)
-			if diff := cmp.Diff(tc.want.toGrant, gotToGrant, equateSlices()...); diff != "" {
-				t.Errorf("\ndiffPermissions(...): -want toGrant, +got toGrant:\n%s", diff)
-			}
-			if diff := cmp.Diff(tc.want.toRevoke, gotToRevoke, equateSlices()...); diff != "" {
-				t.Errorf("\ndiffPermissions(...): -want toRevoke, +got toRevoke:\n%s", diff)
-			}
-		})
-	}
-}
-
 func equateSlices() []cmp.Option {
 	return []cmp.Option{
 		cmp.Transformer("mapAllPrivileges", func(s string) string {
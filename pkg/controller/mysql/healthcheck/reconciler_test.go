@@ -0,0 +1,340 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotHealthCheck": {
+			reason: "An error should be returned if the managed resource is not a HealthCheck",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotHealthCheck),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{},
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetPC),
+		},
+		"ErrMissingConnectionSecret": {
+			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.New(errNoSecretRef),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o        managed.ExternalObservation
+		err      error
+		ready    xpv1.Condition
+		rowCount *int64
+	}
+
+	healthy := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotHealthCheck": {
+			reason: "An error should be returned if the managed resource is not a HealthCheck",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotHealthCheck),
+			},
+		},
+		"QueryFailed": {
+			reason: "A failing query should mark the HealthCheck unavailable without returning an error",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ForProvider: v1alpha1.HealthCheckParameters{Query: "SELECT 1"},
+					},
+				},
+			},
+			want: want{
+				o:     healthy,
+				ready: xpv1.Unavailable().WithMessage(errors.Wrap(errBoom, errRunQuery).Error()),
+			},
+		},
+		"TooFewRows": {
+			reason: "A query returning fewer than minRows rows should mark the HealthCheck unavailable",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int64) = 1
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ForProvider: v1alpha1.HealthCheckParameters{
+							Query:   "SELECT 1",
+							MinRows: func() *int64 { n := int64(2); return &n }(),
+						},
+					},
+				},
+			},
+			want: want{
+				o:        healthy,
+				ready:    xpv1.Unavailable().WithMessage(fmt.Sprintf(errTooFewRows, 1, 2)),
+				rowCount: func() *int64 { n := int64(1); return &n }(),
+			},
+		},
+		"NoRows": {
+			reason: "A query returning no rows should mark the HealthCheck unavailable by default",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ForProvider: v1alpha1.HealthCheckParameters{Query: "SELECT 1 FROM DUAL WHERE false"},
+					},
+				},
+			},
+			want: want{
+				o:        healthy,
+				ready:    xpv1.Unavailable().WithMessage(fmt.Sprintf(errTooFewRows, 0, 1)),
+				rowCount: new(int64),
+			},
+		},
+		"Success": {
+			reason: "A query returning rows should mark the HealthCheck available",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if q.String != "SELECT COUNT(*) FROM (SELECT 1) AS healthcheck" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						*dest[0].(*int64) = 1
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.HealthCheck{
+					Spec: v1alpha1.HealthCheckSpec{
+						ForProvider: v1alpha1.HealthCheckParameters{Query: "SELECT 1"},
+					},
+				},
+			},
+			want: want{
+				o:        healthy,
+				ready:    xpv1.Available(),
+				rowCount: func() *int64 { n := int64(1); return &n }(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			cr, ok := tc.args.mg.(*v1alpha1.HealthCheck)
+			if !ok {
+				return
+			}
+			if diff := cmp.Diff(tc.want.ready, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want ready, +got ready:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rowCount, cr.Status.AtProvider.RowCount); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want rowCount, +got rowCount:\n%s\n", tc.reason, diff)
+			}
+			if cr.Status.AtProvider.LastCheckTime == nil {
+				t.Errorf("\n%s\ne.Observe(...): lastCheckTime was not set", tc.reason)
+			}
+		})
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   time.Duration
+	}{
+		"Default": {
+			reason: "The provider poll interval should be used when no interval is set",
+			mg:     &v1alpha1.HealthCheck{},
+			want:   time.Minute,
+		},
+		"Interval": {
+			reason: "The HealthCheck interval should be used when set",
+			mg: &v1alpha1.HealthCheck{
+				Spec: v1alpha1.HealthCheckSpec{
+					ForProvider: v1alpha1.HealthCheckParameters{
+						Interval: &metav1.Duration{Duration: 10 * time.Second},
+					},
+				},
+			},
+			want: 10 * time.Second,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, pollInterval(tc.mg, time.Minute)); diff != "" {
+				t.Errorf("\n%s\npollInterval(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,12 +18,20 @@ package mysql
 
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/applicationaccount"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/config"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/database"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/grant"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/healthcheck"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/plugin"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/user"
 )
 
@@ -35,6 +43,9 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		database.Setup,
 		user.Setup,
 		grant.Setup,
+		healthcheck.Setup,
+		applicationaccount.Setup,
+		plugin.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
@@ -42,3 +53,16 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	}
 	return nil
 }
+
+// Connecters returns the constructors of the connecters of all MySQL
+// managed resources, keyed by their group kind.
+func Connecters() map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter {
+	return map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter{
+		v1alpha1.ApplicationAccountGroupKind: applicationaccount.NewConnecter,
+		v1alpha1.DatabaseGroupKind:           database.NewConnecter,
+		v1alpha1.GrantGroupKind:              grant.NewConnecter,
+		v1alpha1.HealthCheckGroupKind:        healthcheck.NewConnecter,
+		v1alpha1.PluginGroupKind:             plugin.NewConnecter,
+		v1alpha1.UserGroupKind:               user.NewConnecter,
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/tls"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+	errTLSConfig    = "cannot load TLS config"
+
+	errNotPlugin       = "managed resource is not a Plugin custom resource"
+	errInvalidName     = "invalid plugin name"
+	errSelectPlugin    = "cannot select plugin"
+	errInstallPlugin   = "cannot install plugin"
+	errUninstallPlugin = "cannot uninstall plugin"
+
+	// statusActive is the status of a plugin that is loaded and working.
+	statusActive = "ACTIVE"
+
+	// errCodeNoSuchPlugin is returned when uninstalling a plugin that is
+	// not installed.
+	errCodeNoSuchPlugin = 1305
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Plugin managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.PluginGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.PluginGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Plugin{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.PluginGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.PluginGroupKind))
+}
+
+// NewConnecter returns a connecter for Plugin managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: mysql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Plugin)
+	if !ok {
+		return nil, errors.New(errNotPlugin)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	providerConfigName := cr.GetProviderConfigReference().Name
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: providerConfigName}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := mysql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	tlsName, err := tls.LoadConfig(ctx, c.kube, providerConfigName, pc.Spec.TLS, pc.Spec.TLSConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errTLSConfig)
+	}
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, tlsName, nil, mysql.OptionsFromProviderConfig(pc)), mysql.ConnectionLimits(pc)), c.log, v1alpha1.PluginKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Plugin)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotPlugin)
+	}
+
+	observed := v1alpha1.PluginObservation{}
+	query := "SELECT plugin_status, plugin_version, COALESCE(plugin_library, '') " +
+		"FROM information_schema.plugins WHERE plugin_name = ?"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}},
+		&observed.Status,
+		&observed.Version,
+		&observed.Library,
+	)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectPlugin)
+	}
+
+	cr.Status.AtProvider = observed
+	if observed.Status == statusActive {
+		cr.SetConditions(xpv1.Available())
+	} else {
+		cr.SetConditions(xpv1.Unavailable().WithMessage("plugin status is " + observed.Status))
+	}
+
+	// Plugins built into the server have no library, and cannot be
+	// reinstalled from one.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: observed.Library == "" || observed.Library == cr.Spec.ForProvider.Soname,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Plugin)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotPlugin)
+	}
+
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), mysql.PluginNameLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
+	return managed.ExternalCreation{}, c.install(ctx, cr)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Plugin)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotPlugin)
+	}
+
+	// A plugin is loaded from the library it was installed from, so it is
+	// reinstalled to load it from another one.
+	if err := c.uninstall(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	return managed.ExternalUpdate{}, c.install(ctx, cr)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Plugin)
+	if !ok {
+		return errors.New(errNotPlugin)
+	}
+
+	return c.uninstall(ctx, cr)
+}
+
+func (c *external) install(ctx context.Context, cr *v1alpha1.Plugin) error {
+	query := "INSTALL PLUGIN " + mysql.QuoteIdentifier(meta.GetExternalName(cr)) +
+		" SONAME " + mysql.QuoteValue(cr.Spec.ForProvider.Soname)
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errInstallPlugin})
+}
+
+// uninstall uninstalls the plugin, if it is installed.
+func (c *external) uninstall(ctx context.Context, cr *v1alpha1.Plugin) error {
+	err := c.db.Exec(ctx, xsql.Query{String: "UNINSTALL PLUGIN " + mysql.QuoteIdentifier(meta.GetExternalName(cr))})
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errCodeNoSuchPlugin {
+		return nil
+	}
+	return errors.Wrap(err, errUninstallPlugin)
+}
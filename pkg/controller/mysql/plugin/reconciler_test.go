@@ -0,0 +1,316 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec func(ctx context.Context, q xsql.Query) error
+	MockScan func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error { return nil }
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return nil
+}
+
+func plugin(soname string) *v1alpha1.Plugin {
+	cr := &v1alpha1.Plugin{Spec: v1alpha1.PluginSpec{ForProvider: v1alpha1.PluginParameters{Soname: soname}}}
+	meta.SetExternalName(cr, "audit_log")
+	return cr
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		usage  resource.Tracker
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotPlugin": {
+			reason: "An error should be returned if the managed resource is not a *Plugin",
+			mg:     nil,
+			want:   errors.New(errNotPlugin),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			usage:  resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			mg:     &v1alpha1.Plugin{},
+			want:   errors.Wrap(errBoom, errTrackPCUsage),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{usage: tc.usage}
+			_, err := e.Connect(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	scan := func(status, library string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = status
+			*dest[1].(*string) = "1.0"
+			*dest[2].(*string) = library
+			return nil
+		}
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.PluginObservation
+		condition  xpv1.Condition
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		mg     resource.Managed
+		want   want
+	}{
+		"ErrNotPlugin": {
+			reason: "An error should be returned if the managed resource is not a *Plugin",
+			want:   want{err: errors.New(errNotPlugin)},
+		},
+		"NotInstalled": {
+			reason: "A plugin that is not listed should not exist",
+			db: mockDB{MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				return sql.ErrNoRows
+			}},
+			mg:   plugin("audit_log.so"),
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"ErrSelect": {
+			reason: "Errors selecting the plugin should be returned",
+			db: mockDB{MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				return errBoom
+			}},
+			mg:   plugin("audit_log.so"),
+			want: want{err: errors.Wrap(errBoom, errSelectPlugin)},
+		},
+		"Active": {
+			reason: "An active plugin loaded from the desired library should be available and up to date",
+			db:     mockDB{MockScan: scan("ACTIVE", "audit_log.so")},
+			mg:     plugin("audit_log.so"),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.PluginObservation{Status: "ACTIVE", Version: "1.0", Library: "audit_log.so"},
+				condition:  xpv1.Available(),
+			},
+		},
+		"Disabled": {
+			reason: "A disabled plugin should be unavailable",
+			db:     mockDB{MockScan: scan("DISABLED", "audit_log.so")},
+			mg:     plugin("audit_log.so"),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.PluginObservation{Status: "DISABLED", Version: "1.0", Library: "audit_log.so"},
+				condition:  xpv1.Unavailable().WithMessage("plugin status is DISABLED"),
+			},
+		},
+		"OtherLibrary": {
+			reason: "A plugin loaded from another library should not be up to date",
+			db:     mockDB{MockScan: scan("ACTIVE", "audit_log_old.so")},
+			mg:     plugin("audit_log.so"),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.PluginObservation{Status: "ACTIVE", Version: "1.0", Library: "audit_log_old.so"},
+				condition:  xpv1.Available(),
+			},
+		},
+		"BuiltIn": {
+			reason: "A plugin built into the server should be up to date",
+			db:     mockDB{MockScan: scan("ACTIVE", "")},
+			mg:     plugin("audit_log.so"),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider: v1alpha1.PluginObservation{Status: "ACTIVE", Version: "1.0"},
+				condition:  xpv1.Available(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			got, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			cr, ok := tc.mg.(*v1alpha1.Plugin)
+			if !ok || tc.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.condition.Type != "" {
+				if diff := cmp.Diff(tc.want.condition, cr.GetCondition(tc.want.condition.Type), test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotPlugin": {
+			reason: "An error should be returned if the managed resource is not a *Plugin",
+			want:   errors.New(errNotPlugin),
+		},
+		"ErrInstall": {
+			reason: "Errors installing the plugin should be returned",
+			db:     mockDB{MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom }},
+			mg:     plugin("audit_log.so"),
+			want:   errors.Wrap(errBoom, errInstallPlugin),
+		},
+		"Success": {
+			reason: "The plugin should be installed from its library",
+			db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+				if q.String != "INSTALL PLUGIN `audit_log` SONAME 'audit_log.so'" {
+					return errors.Errorf("unexpected query: %s", q.String)
+				}
+				return nil
+			}},
+			mg: plugin("audit_log.so"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var got []string
+	e := external{db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+		got = append(got, q.String)
+		return nil
+	}}}
+	if _, err := e.Update(context.Background(), plugin("audit_log.so")); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+	want := []string{
+		"UNINSTALL PLUGIN `audit_log`",
+		"INSTALL PLUGIN `audit_log` SONAME 'audit_log.so'",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s\n", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotPlugin": {
+			reason: "An error should be returned if the managed resource is not a *Plugin",
+			want:   errors.New(errNotPlugin),
+		},
+		"ErrUninstall": {
+			reason: "Errors uninstalling the plugin should be returned",
+			db:     mockDB{MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom }},
+			mg:     plugin("audit_log.so"),
+			want:   errors.Wrap(errBoom, errUninstallPlugin),
+		},
+		"NotInstalled": {
+			reason: "A plugin that is not installed should be considered deleted",
+			db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+				return &mysqldriver.MySQLError{Number: errCodeNoSuchPlugin}
+			}},
+			mg: plugin("audit_log.so"),
+		},
+		"Success": {
+			reason: "The plugin should be uninstalled",
+			db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+				if q.String != "UNINSTALL PLUGIN `audit_log`" {
+					return errors.Errorf("unexpected query: %s", q.String)
+				}
+				return nil
+			}},
+			mg: plugin("audit_log.so"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -37,44 +37,91 @@ func LoadConfig(ctx context.Context, kube client.Client, providerConfigName stri
 	return &tlsName, nil
 }
 
+// PublishedCACert returns the CA certificate of the supplied TLS
+// configuration if it should be published in connection secrets, or nil.
+func PublishedCACert(ctx context.Context, kube client.Client, mode *string, cfg *v1alpha1.TLSConfig) ([]byte, error) {
+	if mode == nil || *mode != "custom" || cfg == nil || !cfg.PublishCACert || cfg.CACert.SecretRef.Name == "" {
+		return nil, nil
+	}
+	caCert, err := getSecret(ctx, kube, cfg.CACert.SecretRef)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get CA certificate: %w", err)
+	}
+	return caCert, nil
+}
+
 func validateTLSConfig(cfg *v1alpha1.TLSConfig) error {
-	if cfg == nil ||
-		cfg.CACert.SecretRef.Name == "" ||
-		cfg.CACert.SecretRef.Key == "" ||
-		cfg.ClientCert.SecretRef.Name == "" ||
-		cfg.ClientCert.SecretRef.Key == "" ||
-		cfg.ClientKey.SecretRef.Name == "" ||
-		cfg.ClientKey.SecretRef.Key == "" {
+	if cfg == nil {
 		return fmt.Errorf("tlsConfig is required when tls=custom")
 	}
+	if (cfg.ClientCert.SecretRef.Name == "") != (cfg.ClientKey.SecretRef.Name == "") {
+		return fmt.Errorf("tlsConfig.clientCert and tlsConfig.clientKey must be set together")
+	}
+	if _, err := minVersion(cfg.MinVersion); err != nil {
+		return err
+	}
 	return nil
 }
 
+// minVersion returns the crypto/tls version for the supplied minimum TLS
+// version, defaulting to TLS 1.2.
+func minVersion(v *string) (uint16, error) {
+	if v == nil {
+		return tls.VersionTLS12, nil
+	}
+	switch *v {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported tlsConfig.minVersion %q", *v)
+}
+
 func registerTLS(ctx context.Context, kube client.Client, tlsName string, cfg *v1alpha1.TLSConfig) error {
-	if cfg == nil {
-		return nil
+	c, err := newConfig(ctx, kube, cfg)
+	if err != nil {
+		return err
 	}
+	return mysql.RegisterTLSConfig(tlsName, c)
+}
 
-	caCert, err := getSecret(ctx, kube, cfg.CACert.SecretRef)
+func newConfig(ctx context.Context, kube client.Client, cfg *v1alpha1.TLSConfig) (*tls.Config, error) {
+	mv, err := minVersion(cfg.MinVersion)
 	if err != nil {
-		return fmt.Errorf("cannot get CA certificate: %w", err)
+		return nil, err
+	}
+	c := &tls.Config{
+		ServerName:         cfg.ServerName,
+		MinVersion:         mv,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // Explicitly requested by the ProviderConfig, e.g. for integration tests.
 	}
 
-	pool := x509.NewCertPool()
-	if ok := pool.AppendCertsFromPEM(caCert); !ok {
-		return fmt.Errorf("cannot append CA certificate to pool")
+	if cfg.CACert.SecretRef.Name != "" {
+		caCert, err := getSecret(ctx, kube, cfg.CACert.SecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM(caCert); !ok {
+			return nil, fmt.Errorf("cannot append CA certificate to pool")
+		}
+		c.RootCAs = pool
 	}
 
-	keyPair, err := getClientKeyPair(ctx, kube, cfg)
-	if err != nil {
-		return err
+	if cfg.ClientCert.SecretRef.Name != "" {
+		keyPair, err := getClientKeyPair(ctx, kube, cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{keyPair}
 	}
 
-	return mysql.RegisterTLSConfig(tlsName, &tls.Config{
-		RootCAs:            pool,
-		Certificates:       []tls.Certificate{keyPair},
-		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // This is only required by integration tests and should never be used in production
-	})
+	return c, nil
 }
 
 func getClientKeyPair(ctx context.Context, kube client.Client, cfg *v1alpha1.TLSConfig) (tls.Certificate, error) {
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+)
+
+func TestValidateTLSConfig(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cfg    *v1alpha1.TLSConfig
+		want   error
+	}{
+		"Nil": {
+			reason: "A TLS configuration is required when tls=custom",
+			want:   fmt.Errorf("tlsConfig is required when tls=custom"),
+		},
+		"ServerNameOnly": {
+			reason: "A TLS configuration without certificates should be valid",
+			cfg:    &v1alpha1.TLSConfig{ServerName: "db.example.org"},
+		},
+		"ClientCertWithoutKey": {
+			reason: "A client certificate requires a client key",
+			cfg: &v1alpha1.TLSConfig{
+				ClientCert: v1alpha1.TLSSecret{SecretRef: xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "cert"}, Key: "tls.crt"}},
+			},
+			want: fmt.Errorf("tlsConfig.clientCert and tlsConfig.clientKey must be set together"),
+		},
+		"UnsupportedMinVersion": {
+			reason: "Unknown minimum TLS versions should be rejected",
+			cfg:    &v1alpha1.TLSConfig{MinVersion: ptr.To("2.0")},
+			want:   fmt.Errorf(`unsupported tlsConfig.minVersion "2.0"`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateTLSConfig(tc.cfg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateTLSConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		serverName string
+		minVersion uint16
+		rootCAs    bool
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		cfg    *v1alpha1.TLSConfig
+		want   want
+	}{
+		"Defaults": {
+			reason: "The system roots and TLS 1.2 should be used by default",
+			cfg:    &v1alpha1.TLSConfig{},
+			want: want{
+				minVersion: tls.VersionTLS12,
+			},
+		},
+		"ServerNameAndMinVersion": {
+			reason: "The server name and minimum version should be applied",
+			cfg:    &v1alpha1.TLSConfig{ServerName: "db.example.org", MinVersion: ptr.To("1.3")},
+			want: want{
+				serverName: "db.example.org",
+				minVersion: tls.VersionTLS13,
+			},
+		},
+		"ErrGetCACert": {
+			reason: "Errors getting the CA certificate should be returned",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			cfg: &v1alpha1.TLSConfig{
+				CACert: v1alpha1.TLSSecret{SecretRef: xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "ca", Namespace: "ns"}, Key: "ca.crt"}},
+			},
+			want: want{
+				err: fmt.Errorf("cannot get CA certificate: %w", fmt.Errorf(`cannot get Secret "ca" in namespace "ns": %w`, errBoom)),
+			},
+		},
+		"ErrInvalidCACert": {
+			reason: "A CA certificate that is not PEM encoded should be rejected",
+			kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				obj.(*corev1.Secret).Data = map[string][]byte{"ca.crt": []byte("not a certificate")}
+				return nil
+			})},
+			cfg: &v1alpha1.TLSConfig{
+				CACert: v1alpha1.TLSSecret{SecretRef: xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "ca"}, Key: "ca.crt"}},
+			},
+			want: want{
+				err: fmt.Errorf("cannot append CA certificate to pool"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := newConfig(context.Background(), tc.kube, tc.cfg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nnewConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			got := want{serverName: c.ServerName, minVersion: c.MinVersion, rootCAs: c.RootCAs != nil}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nnewConfig(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,11 +19,14 @@ package user
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -31,31 +34,53 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/password"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/tls"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 	errTLSConfig    = "cannot load TLS config"
 
 	errNotUser                 = "managed resource is not a User custom resource"
+	errInvalidName             = "invalid user name"
 	errSelectUser              = "cannot select user"
 	errCreateUser              = "cannot create user"
 	errDropUser                = "cannot drop user"
 	errUpdateUser              = "cannot update user"
 	errGetPasswordSecretFailed = "cannot get password secret"
 	errCompareResourceOptions  = "cannot compare desired and observed resource options"
+	errSelectProxy             = "cannot select proxy grant"
+	errGrantProxy              = "cannot grant proxy"
+	errRevokeProxy             = "cannot revoke proxy"
+	errRenameUser              = "cannot rename user"
+	errSelectDefaultRoles      = "cannot select default roles"
+	errGrantRoles              = "cannot grant roles"
+	errSetDefaultRoles         = "cannot set default roles"
+	errNoPasswordHash          = "hashedPassword requires a passwordSecretRef containing the password hash"
+	errUnknownPasswordHash     = "the password hash is not one of caching_sha2_password, sha256_password or mysql_native_password"
 
 	maxConcurrency = 5
 )
@@ -65,26 +90,49 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.UserGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: mysql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.User{}, &v1alpha1.UserList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.User)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.User{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.UserGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.UserGroupKind))
+}
+
+// NewConnecter returns a connecter for User managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: mysql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+	newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -104,18 +152,27 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: providerConfigName}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := mysql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (MySQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
 	tlsName, err := tls.LoadConfig(ctx, c.kube, providerConfigName, pc.Spec.TLS, pc.Spec.TLSConfig)
@@ -123,28 +180,69 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errTLSConfig)
 	}
 
+	cd := managed.ConnectionDetails{}
+	caCert, err := tls.PublishedCACert(ctx, c.kube, pc.Spec.TLS, pc.Spec.TLSConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errTLSConfig)
+	}
+	if caCert != nil {
+		cd[xsql.CACertKey] = caCert
+	}
+
+	db := xsql.WithConnectionDetails(c.newDB(creds, tlsName, cr.Spec.ForProvider.BinLog, mysql.OptionsFromProviderConfig(pc)), cd)
 	return &external{
-		db:   c.newDB(s.Data, tlsName, cr.Spec.ForProvider.BinLog),
-		kube: c.kube,
+		db:     xsql.Instrument(xsql.WithConnectionLimits(db, mysql.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr),
+		kube:   c.kube,
+		self:   string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+		flavor: ptr.Deref(pc.Spec.Flavor, sqlgen.FlavorVanilla),
 	}, nil
 }
 
 type external struct {
 	db   xsql.DB
 	kube client.Client
+
+	// self is the user the provider connects as.
+	self string
+
+	// flavor is the flavor of the server, which determines the resource
+	// options it supports.
+	flavor string
+
+	// hashChanged is true if the last observation found the password hash
+	// of a user with a hashed password to differ from the desired one.
+	hashChanged bool
+
+	// renameFrom is the account, in user@host form, the last observation
+	// found the user as, if its external name changed and the next update
+	// is to rename it.
+	renameFrom string
 }
 
-func handleClause(clause string, value *int, out *[]string) {
+// checkReserved refuses to manage the account the provider connects as, or
+// one reserved by MySQL or the database service, so that a bad composition
+// cannot lock the provider out.
+func (c *external) checkReserved(cr *v1alpha1.User) error {
+	username, _ := mysql.SplitUserHost(meta.GetExternalName(cr))
+	return xsql.CheckReserved(cr, "account", meta.GetExternalName(cr), mysql.IsReservedUser(username, c.self))
+}
+
+func handleClause(clause string, value *int, flavor string, out *[]string) {
 	// If clause is not set (nil pointer), do not push a setting.
 	// This means the default is applied.
 	if value == nil {
 		return
 	}
+	// Resource options the server does not support are skipped, rather
+	// than failing every statement that sets them.
+	if !sqlgen.SupportsResourceOption(clause, flavor) {
+		return
+	}
 
 	*out = append(*out, fmt.Sprintf("%s %d", clause, *value))
 }
 
-func resourceOptionsToClauses(r *v1alpha1.ResourceOptions) []string {
+func resourceOptionsToClauses(r *v1alpha1.ResourceOptions, flavor string) []string {
 	// Never copy user inputted data to this string. These values are
 	// passed directly into the query.
 	ro := []string{}
@@ -153,14 +251,24 @@ func resourceOptionsToClauses(r *v1alpha1.ResourceOptions) []string {
 		return ro
 	}
 
-	handleClause("MAX_QUERIES_PER_HOUR", r.MaxQueriesPerHour, &ro)
-	handleClause("MAX_UPDATES_PER_HOUR", r.MaxUpdatesPerHour, &ro)
-	handleClause("MAX_CONNECTIONS_PER_HOUR", r.MaxConnectionsPerHour, &ro)
-	handleClause("MAX_USER_CONNECTIONS", r.MaxUserConnections, &ro)
+	handleClause("MAX_QUERIES_PER_HOUR", r.MaxQueriesPerHour, flavor, &ro)
+	handleClause("MAX_UPDATES_PER_HOUR", r.MaxUpdatesPerHour, flavor, &ro)
+	handleClause("MAX_CONNECTIONS_PER_HOUR", r.MaxConnectionsPerHour, flavor, &ro)
+	handleClause("MAX_USER_CONNECTIONS", r.MaxUserConnections, flavor, &ro)
 
 	return ro
 }
 
+// resourceOptionColumn returns the column of mysql.user that holds the
+// resource option set by the supplied clause, or 0 if the server does not
+// support it, and thus has no such column.
+func resourceOptionColumn(clause, column, flavor string) string {
+	if !sqlgen.SupportsResourceOption(clause, flavor) {
+		return "0"
+	}
+	return column
+}
+
 func changedResourceOptions(existing []string, desired []string) ([]string, error) {
 	out := []string{}
 
@@ -196,25 +304,46 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		ResourceOptions: &v1alpha1.ResourceOptions{},
 	}
 
+	var plugin, authString string
 	query := "SELECT " +
-		"max_questions, " +
-		"max_updates, " +
-		"max_connections, " +
-		"max_user_connections " +
+		resourceOptionColumn("MAX_QUERIES_PER_HOUR", "max_questions", c.flavor) + ", " +
+		resourceOptionColumn("MAX_UPDATES_PER_HOUR", "max_updates", c.flavor) + ", " +
+		resourceOptionColumn("MAX_CONNECTIONS_PER_HOUR", "max_connections", c.flavor) + ", " +
+		resourceOptionColumn("MAX_USER_CONNECTIONS", "max_user_connections", c.flavor) + ", " +
+		"plugin, " +
+		"authentication_string " +
 		"FROM mysql.user WHERE User = ? AND Host = ?"
-	err := c.db.Scan(ctx,
-		xsql.Query{
-			String: query,
-			Parameters: []interface{}{
-				username,
-				host,
+	scan := func(username, host string) error {
+		return c.db.Scan(ctx,
+			xsql.Query{
+				String: query,
+				Parameters: []interface{}{
+					username,
+					host,
+				},
 			},
-		},
-		&observed.ResourceOptions.MaxQueriesPerHour,
-		&observed.ResourceOptions.MaxUpdatesPerHour,
-		&observed.ResourceOptions.MaxConnectionsPerHour,
-		&observed.ResourceOptions.MaxUserConnections,
-	)
+			&observed.ResourceOptions.MaxQueriesPerHour,
+			&observed.ResourceOptions.MaxUpdatesPerHour,
+			&observed.ResourceOptions.MaxConnectionsPerHour,
+			&observed.ResourceOptions.MaxUserConnections,
+			&plugin,
+			&authString,
+		)
+	}
+
+	// A user that is not found under its external name, but as the account
+	// it was last observed as, is renamed by the next update rather than
+	// created anew, which keeps its password and grants.
+	c.renameFrom = ""
+	err := scan(username, host)
+	if last := cr.Status.AtProvider.Name; xsql.IsNoRows(err) && last != "" {
+		if lu, lh := mysql.SplitUserHost(last); lu != username || lh != host {
+			if err = scan(lu, lh); err == nil {
+				c.renameFrom = last
+				username, host = lu, lh
+			}
+		}
+	}
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
@@ -222,44 +351,197 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
 	}
 
-	_, pwdChanged, err := c.getPassword(ctx, cr)
-	if err != nil {
-		return managed.ExternalObservation{}, err
+	observed.AuthPlugin = &plugin
+
+	// Users of an authentication plugin have no password to drift. The hash
+	// of a hashed password is compared to the one the server stores, as the
+	// connection secret cannot contain the password it is the hash of.
+	pwdChanged := false
+	c.hashChanged = false
+	switch {
+	case isHashed(cr.Spec.ForProvider):
+		hash, _, err := c.getPassword(ctx, cr)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		c.hashChanged = hash != "" && (hash != authString || !strings.EqualFold(plugin, hashPlugin(hash)))
+		pwdChanged = c.hashChanged
+	case cr.Spec.ForProvider.AuthPlugin == nil:
+		if _, pwdChanged, err = c.getPassword(ctx, cr); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	// TiDB does not support PROXY, so there is no proxy grant to observe
+	// unless one is desired.
+	if c.flavor != sqlgen.FlavorTiDB || cr.Spec.ForProvider.ProxyOf != nil {
+		proxyOf, err := c.observeProxy(ctx, username, host, ptr.Deref(cr.Spec.ForProvider.ProxyOf, ""))
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		observed.ProxyOf = proxyOf
+	}
+
+	if len(cr.Spec.ForProvider.DefaultRoles) > 0 {
+		if observed.DefaultRoles, err = c.observeDefaultRoles(ctx, username, host); err != nil {
+			return managed.ExternalObservation{}, err
+		}
 	}
 
-	cr.Status.AtProvider.ResourceOptionsAsClauses = resourceOptionsToClauses(observed.ResourceOptions)
+	cr.Status.AtProvider.ResourceOptionsAsClauses = resourceOptionsToClauses(observed.ResourceOptions, c.flavor)
+	cr.Status.AtProvider.ResourceOptions = observed.ResourceOptions
+	cr.Status.AtProvider.ProxyOf = observed.ProxyOf
+	cr.Status.AtProvider.AuthPlugin = observed.AuthPlugin
+	cr.Status.AtProvider.DefaultRoles = observed.DefaultRoles
+	if c.renameFrom == "" {
+		cr.Status.AtProvider.Name = meta.GetExternalName(cr)
+	}
 
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
 		ResourceExists:   true,
-		ResourceUpToDate: !pwdChanged && upToDate(observed, &cr.Spec.ForProvider),
+		ResourceUpToDate: c.renameFrom == "" && !pwdChanged && upToDate(observed, &cr.Spec.ForProvider, c.flavor),
 	}, nil
 }
 
+// observeProxy returns the account the user has been granted PROXY on,
+// preferring the desired one when there are several, or nil if there is none.
+func (c *external) observeProxy(ctx context.Context, username, host, desired string) (*string, error) {
+	proxiedUser, proxiedHost := mysql.SplitUserHost(desired)
+
+	var proxyOf string
+	query := "SELECT CONCAT(Proxied_user, '@', Proxied_host) FROM mysql.proxies_priv " +
+		"WHERE User = ? AND Host = ? " +
+		"ORDER BY (Proxied_user = ? AND Proxied_host = ?) DESC LIMIT 1"
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{username, host, proxiedUser, proxiedHost},
+	}, &proxyOf)
+	if xsql.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectProxy)
+	}
+	if proxyOf == "" {
+		return nil, nil
+	}
+	return &proxyOf, nil
+}
+
+// observeDefaultRoles returns the default roles of the user, in role@host
+// form and sorted, that the user has been granted. A default role that is no
+// longer granted is not activated when the user connects.
+func (c *external) observeDefaultRoles(ctx context.Context, username, host string) ([]string, error) {
+	var roles string
+	query := "SELECT COALESCE(GROUP_CONCAT(CONCAT(d.DEFAULT_ROLE_USER, '@', d.DEFAULT_ROLE_HOST) SEPARATOR ','), '') " +
+		"FROM mysql.default_roles d JOIN mysql.role_edges e " +
+		"ON e.FROM_USER = d.DEFAULT_ROLE_USER AND e.FROM_HOST = d.DEFAULT_ROLE_HOST " +
+		"AND e.TO_USER = d.USER AND e.TO_HOST = d.HOST " +
+		"WHERE d.USER = ? AND d.HOST = ?"
+	if err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{username, host}}, &roles); err != nil {
+		return nil, errors.Wrap(err, errSelectDefaultRoles)
+	}
+	if roles == "" {
+		return nil, nil
+	}
+	out := strings.Split(roles, ",")
+	sort.Strings(out)
+	return out, nil
+}
+
+// roleAccounts returns the supplied roles in role@host form, sorted and
+// without duplicates, as they are observed.
+func roleAccounts(roles []string) []string {
+	out := make([]string, len(roles))
+	for i, r := range roles {
+		u, h := mysql.SplitUserHost(r)
+		out[i] = u + "@" + h
+	}
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// setDefaultRoles grants the supplied roles to the user, which is a no-op for
+// the roles it already holds, and makes them its only default roles.
+func (c *external) setDefaultRoles(ctx context.Context, username, host string, roles []string) error {
+	quoted := make([]string, len(roles))
+	for i, r := range roleAccounts(roles) {
+		u, h := mysql.SplitUserHost(r)
+		quoted[i] = mysql.QuoteValue(u) + "@" + mysql.QuoteValue(h)
+	}
+	user := mysql.QuoteValue(username) + "@" + mysql.QuoteValue(host)
+
+	query := fmt.Sprintf("GRANT %s TO %s", strings.Join(quoted, ", "), user)
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errGrantRoles}); err != nil {
+		return err
+	}
+	query = fmt.Sprintf("ALTER USER %s DEFAULT ROLE %s", user, strings.Join(quoted, ", "))
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errSetDefaultRoles})
+}
+
+func (c *external) grantProxy(ctx context.Context, username, host, proxyOf string) error {
+	proxiedUser, proxiedHost := mysql.SplitUserHost(proxyOf)
+	query := fmt.Sprintf("GRANT PROXY ON %s@%s TO %s@%s",
+		mysql.QuoteValue(proxiedUser),
+		mysql.QuoteValue(proxiedHost),
+		mysql.QuoteValue(username),
+		mysql.QuoteValue(host),
+	)
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errGrantProxy})
+}
+
+func (c *external) revokeProxy(ctx context.Context, username, host, proxyOf string) error {
+	proxiedUser, proxiedHost := mysql.SplitUserHost(proxyOf)
+	query := fmt.Sprintf("REVOKE PROXY ON %s@%s FROM %s@%s",
+		mysql.QuoteValue(proxiedUser),
+		mysql.QuoteValue(proxiedHost),
+		mysql.QuoteValue(username),
+		mysql.QuoteValue(host),
+	)
+	return mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errRevokeProxy})
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.User)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotUser)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	cr.SetConditions(xpv1.Creating())
 
 	username, host := mysql.SplitUserHost(meta.GetExternalName(cr))
-	pw, _, err := c.getPassword(ctx, cr)
-	if err != nil {
-		return managed.ExternalCreation{}, err
+
+	if err := xsql.ValidateIdentifier(username, mysql.UserNameLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
 	}
 
-	if pw == "" {
-		pw, err = password.Generate()
-		if err != nil {
+	var pw string
+	if cr.Spec.ForProvider.AuthPlugin == nil {
+		var err error
+		if pw, _, err = c.getPassword(ctx, cr); err != nil {
 			return managed.ExternalCreation{}, err
 		}
+		if isHashed(cr.Spec.ForProvider) {
+			if err := validateHash(pw); err != nil {
+				return managed.ExternalCreation{}, err
+			}
+		}
+		if pw == "" {
+			pw, err = password.Generate()
+			if err != nil {
+				return managed.ExternalCreation{}, err
+			}
+		}
 	}
 
-	ro := resourceOptionsToClauses(cr.Spec.ForProvider.ResourceOptions)
-	if err := c.executeCreateUserQuery(ctx, username, host, ro, pw); err != nil {
+	ro := resourceOptionsToClauses(cr.Spec.ForProvider.ResourceOptions, c.flavor)
+	if err := c.executeCreateUserQuery(ctx, username, host, ro, identifiedBy(cr.Spec.ForProvider, pw)); err != nil {
 		return managed.ExternalCreation{}, err
 	}
 
@@ -267,22 +549,95 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		cr.Status.AtProvider.ResourceOptionsAsClauses = ro
 	}
 
+	if p := cr.Spec.ForProvider.ProxyOf; p != nil {
+		if err := c.grantProxy(ctx, username, host, *p); err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	if r := cr.Spec.ForProvider.DefaultRoles; len(r) > 0 {
+		if err := c.setDefaultRoles(ctx, username, host, r); err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
 	return managed.ExternalCreation{
-		ConnectionDetails: c.db.GetConnectionDetails(username, pw),
+		ConnectionDetails: connectionDetails(c.db.GetConnectionDetails(username, pw), cr.Spec.ForProvider),
 	}, nil
 }
 
-func (c *external) executeCreateUserQuery(ctx context.Context, username string, host string, resourceOptionsClauses []string, pw string) error {
+// identifiedBy returns the IDENTIFIED clause of CREATE USER and ALTER USER,
+// which names the authentication plugin if one is set, the plugin the
+// password was hashed by if it is hashed, and the password otherwise.
+func identifiedBy(p v1alpha1.UserParameters, pw string) string {
+	if isHashed(p) {
+		return "IDENTIFIED WITH " + hashPlugin(pw) + " AS " + mysql.QuoteValue(pw)
+	}
+	if p.AuthPlugin == nil {
+		return "IDENTIFIED BY " + mysql.QuoteValue(pw)
+	}
+	// AuthPlugin is validated to be a plain identifier by the CRD schema.
+	clause := "IDENTIFIED WITH " + *p.AuthPlugin
+	if p.AuthString != nil {
+		clause += " AS " + mysql.QuoteValue(*p.AuthString)
+	}
+	return clause
+}
+
+// connectionDetails omits the password from the supplied connection details
+// of users of an authentication plugin, which have none, and of users with
+// a hashed password, whose password is not known.
+func connectionDetails(cd managed.ConnectionDetails, p v1alpha1.UserParameters) managed.ConnectionDetails {
+	if p.AuthPlugin != nil || isHashed(p) {
+		delete(cd, xpv1.ResourceCredentialsSecretPasswordKey)
+	}
+	return cd
+}
+
+// isHashed returns true if the password of a user is an already hashed
+// authentication string. It has no effect for users of an AuthPlugin.
+func isHashed(p v1alpha1.UserParameters) bool {
+	return p.AuthPlugin == nil && ptr.Deref(p.HashedPassword, false)
+}
+
+// hashPlugin returns the authentication plugin the supplied password hash
+// was created by, as told by its format, or an empty string if it is not
+// known.
+func hashPlugin(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$A$"):
+		return "caching_sha2_password"
+	case strings.HasPrefix(hash, "$5$"):
+		return "sha256_password"
+	case len(hash) == 41 && strings.HasPrefix(hash, "*"):
+		return "mysql_native_password"
+	}
+	return ""
+}
+
+// validateHash returns an error unless the supplied password hash was
+// created by a known authentication plugin.
+func validateHash(hash string) error {
+	if hash == "" {
+		return errors.New(errNoPasswordHash)
+	}
+	if hashPlugin(hash) == "" {
+		return errors.New(errUnknownPasswordHash)
+	}
+	return nil
+}
+
+func (c *external) executeCreateUserQuery(ctx context.Context, username string, host string, resourceOptionsClauses []string, identified string) error {
 	resourceOptions := ""
 	if len(resourceOptionsClauses) != 0 {
 		resourceOptions = fmt.Sprintf(" WITH %s", strings.Join(resourceOptionsClauses, " "))
 	}
 
 	query := fmt.Sprintf(
-		"CREATE USER %s@%s IDENTIFIED BY %s%s",
+		"CREATE USER %s@%s %s%s",
 		mysql.QuoteValue(username),
 		mysql.QuoteValue(host),
-		mysql.QuoteValue(pw),
+		identified,
 		resourceOptions,
 	)
 
@@ -299,9 +654,19 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotUser)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	username, host := mysql.SplitUserHost(meta.GetExternalName(cr))
 
-	ro := resourceOptionsToClauses(cr.Spec.ForProvider.ResourceOptions)
+	if c.renameFrom != "" {
+		if err := c.rename(ctx, cr, username, host); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	ro := resourceOptionsToClauses(cr.Spec.ForProvider.ResourceOptions, c.flavor)
 	rochanged, err := changedResourceOptions(cr.Status.AtProvider.ResourceOptionsAsClauses, ro)
 	if err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateUser)
@@ -323,18 +688,93 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		cr.Status.AtProvider.ResourceOptionsAsClauses = ro
 	}
 
-	connectionDetails, err := c.UpdatePassword(ctx, cr, username, host)
+	if p, o := cr.Spec.ForProvider.ProxyOf, cr.Status.AtProvider.ProxyOf; ptr.Deref(p, "") != ptr.Deref(o, "") {
+		if o != nil {
+			if err := c.revokeProxy(ctx, username, host, *o); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+		}
+		if p != nil {
+			if err := c.grantProxy(ctx, username, host, *p); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+		}
+		cr.Status.AtProvider.ProxyOf = p
+	}
+
+	if r := cr.Spec.ForProvider.DefaultRoles; len(r) > 0 && !slices.Equal(roleAccounts(r), cr.Status.AtProvider.DefaultRoles) {
+		if err := c.setDefaultRoles(ctx, username, host, r); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		cr.Status.AtProvider.DefaultRoles = roleAccounts(r)
+	}
+
+	if p := cr.Spec.ForProvider.AuthPlugin; p != nil {
+		if ptr.Deref(cr.Status.AtProvider.AuthPlugin, "") != *p {
+			query := fmt.Sprintf("ALTER USER %s@%s %s", mysql.QuoteValue(username), mysql.QuoteValue(host), identifiedBy(cr.Spec.ForProvider, ""))
+			if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+			cr.Status.AtProvider.AuthPlugin = p
+		}
+		return managed.ExternalUpdate{}, nil
+	}
+
+	if isHashed(cr.Spec.ForProvider) {
+		if c.hashChanged {
+			hash, _, err := c.getPassword(ctx, cr)
+			if err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+			if err := validateHash(hash); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+			query := fmt.Sprintf("ALTER USER %s@%s %s", mysql.QuoteValue(username), mysql.QuoteValue(host), identifiedBy(cr.Spec.ForProvider, hash))
+			if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+		}
+		return managed.ExternalUpdate{}, nil
+	}
+
+	cd, err := c.UpdatePassword(ctx, cr, username, host)
 	if err != nil {
 		return managed.ExternalUpdate{}, err
 	}
 
-	if len(connectionDetails) > 0 {
-		return managed.ExternalUpdate{ConnectionDetails: connectionDetails}, nil
+	if len(cd) > 0 {
+		return managed.ExternalUpdate{ConnectionDetails: cd}, nil
 	}
 
 	return managed.ExternalUpdate{}, nil
 }
 
+// rename renames the account the last observation found the user as to its
+// external name. MySQL moves the password and grants of the account along
+// with it.
+func (c *external) rename(ctx context.Context, cr *v1alpha1.User, username, host string) error {
+	if err := xsql.ValidateIdentifier(username, mysql.UserNameLimit); err != nil {
+		return errors.Wrap(err, errInvalidName)
+	}
+	lu, lh := mysql.SplitUserHost(c.renameFrom)
+	if err := xsql.CheckReserved(cr, "account", c.renameFrom, mysql.IsReservedUser(lu, c.self)); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("RENAME USER %s@%s TO %s@%s",
+		mysql.QuoteValue(lu),
+		mysql.QuoteValue(lh),
+		mysql.QuoteValue(username),
+		mysql.QuoteValue(host),
+	)
+	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errRenameUser}); err != nil {
+		return err
+	}
+	cr.Status.AtProvider.Name = meta.GetExternalName(cr)
+	c.renameFrom = ""
+	return nil
+}
+
 func (c *external) UpdatePassword(ctx context.Context, cr *v1alpha1.User, username, host string) (managed.ConnectionDetails, error) {
 	pw, pwchanged, err := c.getPassword(ctx, cr)
 	if err != nil {
@@ -359,9 +799,18 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotUser)
 	}
 
+	if err := c.checkReserved(cr); err != nil {
+		return err
+	}
+
 	cr.SetConditions(xpv1.Deleting())
 
-	username, host := mysql.SplitUserHost(meta.GetExternalName(cr))
+	// A user that was not renamed yet is dropped as its old account.
+	name := meta.GetExternalName(cr)
+	if c.renameFrom != "" {
+		name = c.renameFrom
+	}
+	username, host := mysql.SplitUserHost(name)
 
 	query := fmt.Sprintf("DROP USER IF EXISTS %s@%s", mysql.QuoteValue(username), mysql.QuoteValue(host))
 	if err := mysql.ExecWrapper(ctx, c.db, mysql.ExecQuery{Query: query, ErrorValue: errDropUser}); err != nil {
@@ -371,22 +820,41 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	return nil
 }
 
-func upToDate(observed *v1alpha1.UserParameters, desired *v1alpha1.UserParameters) bool {
+func upToDate(observed *v1alpha1.UserParameters, desired *v1alpha1.UserParameters, flavor string) bool {
+	if ptr.Deref(observed.ProxyOf, "") != ptr.Deref(desired.ProxyOf, "") {
+		return false
+	}
+	if desired.AuthPlugin != nil && ptr.Deref(observed.AuthPlugin, "") != *desired.AuthPlugin {
+		return false
+	}
+	if len(desired.DefaultRoles) > 0 && !slices.Equal(roleAccounts(desired.DefaultRoles), observed.DefaultRoles) {
+		return false
+	}
 	if desired.ResourceOptions == nil {
 		// Return true if there are no desired ResourceOptions
 		return true
 	}
-	if observed.ResourceOptions.MaxQueriesPerHour != desired.ResourceOptions.MaxQueriesPerHour {
+	if !resourceOptionUpToDate("MAX_QUERIES_PER_HOUR", observed.ResourceOptions.MaxQueriesPerHour, desired.ResourceOptions.MaxQueriesPerHour, flavor) {
 		return false
 	}
-	if observed.ResourceOptions.MaxUpdatesPerHour != desired.ResourceOptions.MaxUpdatesPerHour {
+	if !resourceOptionUpToDate("MAX_UPDATES_PER_HOUR", observed.ResourceOptions.MaxUpdatesPerHour, desired.ResourceOptions.MaxUpdatesPerHour, flavor) {
 		return false
 	}
-	if observed.ResourceOptions.MaxConnectionsPerHour != desired.ResourceOptions.MaxConnectionsPerHour {
+	if !resourceOptionUpToDate("MAX_CONNECTIONS_PER_HOUR", observed.ResourceOptions.MaxConnectionsPerHour, desired.ResourceOptions.MaxConnectionsPerHour, flavor) {
 		return false
 	}
-	if observed.ResourceOptions.MaxUserConnections != desired.ResourceOptions.MaxUserConnections {
+	if !resourceOptionUpToDate("MAX_USER_CONNECTIONS", observed.ResourceOptions.MaxUserConnections, desired.ResourceOptions.MaxUserConnections, flavor) {
 		return false
 	}
 	return true
 }
+
+// resourceOptionUpToDate returns true if the desired resource option set by
+// the supplied clause is unset, and thus left as it is, is not supported by
+// the server, or has the observed value.
+func resourceOptionUpToDate(clause string, observed, desired *int, flavor string) bool {
+	if desired == nil || !sqlgen.SupportsResourceOption(clause, flavor) {
+		return true
+	}
+	return observed != nil && *observed == *desired
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -37,6 +38,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/mysql"
 )
 
 type mockDB struct {
@@ -72,7 +74,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, tls *string, binlog *bool) xsql.DB
+		newDB func(creds map[string][]byte, tls *string, binlog *bool, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -271,6 +273,160 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ProxyNotGranted": {
+			reason: "We should return ResourceUpToDate=false if the user has not been granted the desired proxy",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "proxies_priv") {
+							*dest[0].(*string) = "other@%"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							ProxyOf: ptr.To("proxied@%"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				err: nil,
+			},
+		},
+		"ErrSelectProxy": {
+			reason: "We should return any errors encountered while selecting the proxy grant",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "proxies_priv") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectProxy),
+			},
+		},
+		"ProxyRemoved": {
+			reason: "We should return ResourceUpToDate=false if the user holds a proxy grant that is no longer desired",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "proxies_priv") {
+							*dest[0].(*string) = "proxied@%"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				err: nil,
+			},
+		},
+		"DefaultRolesNotActive": {
+			reason: "We should return ResourceUpToDate=false if a desired default role is not granted or not activated",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "default_roles") {
+							*dest[0].(*string) = "reader@%"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultRoles: []string{"reader", "writer@%"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				err: nil,
+			},
+		},
+		"DefaultRolesActive": {
+			reason: "We should return ResourceUpToDate=true if the desired default roles are granted and activated",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "default_roles") {
+							*dest[0].(*string) = "writer@%,reader@%"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultRoles: []string{"reader", "writer@%"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"ErrSelectDefaultRoles": {
+			reason: "We should return any errors encountered while selecting the default roles",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "default_roles") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultRoles: []string{"reader"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectDefaultRoles),
+			},
+		},
 		"PasswordChanged": {
 			reason: "We should return ResourceUpToDate=false if the password changed",
 			fields: fields{
@@ -315,6 +471,65 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"AuthPluginIgnoresPassword": {
+			reason: "We should not compare the password of a user identified with an authentication plugin",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "mysql.user") {
+							*dest[4].(*string) = "authentication_ldap_simple"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+							AuthPlugin:        ptr.To("authentication_ldap_simple"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"AuthPluginChanged": {
+			reason: "We should return ResourceUpToDate=false if the user is identified with another plugin",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "mysql.user") {
+							*dest[4].(*string) = "caching_sha2_password"
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							AuthPlugin: ptr.To("authentication_ldap_simple"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -368,6 +583,21 @@ func TestCreate(t *testing.T) {
 				err: errors.New(errNotUser),
 			},
 		},
+		"ErrReservedAccount": {
+			reason: "An error should be returned if the user is reserved by MySQL",
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "root@localhost",
+						},
+					},
+				},
+			},
+			want: want{
+				err: xsql.CheckReserved(&v1.ObjectMeta{}, "account", "root@localhost", true),
+			},
+		},
 		"ErrExec": {
 			reason: "Any errors encountered while creating the user should be returned",
 			fields: fields{
@@ -501,6 +731,45 @@ func TestCreate(t *testing.T) {
 				},
 			},
 		},
+		"UserWithAuthPlugin": {
+			reason:    "A user of an authentication plugin must be created without a password",
+			comparePw: true,
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "CREATE USER 'example'@'%' IDENTIFIED WITH authentication_ldap_simple AS 'uid=example,ou=people,dc=example,dc=com'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							AuthPlugin: ptr.To("authentication_ldap_simple"),
+							AuthString: ptr.To("uid=example,ou=people,dc=example,dc=com"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+				c: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("example"),
+						xpv1.ResourceCredentialsSecretEndpointKey: []byte("localhost"),
+						xpv1.ResourceCredentialsSecretPortKey:     []byte("3306"),
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -528,7 +797,8 @@ func TestUpdate(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db   xsql.DB
+		self string
 	}
 
 	type args struct {
@@ -557,6 +827,24 @@ func TestUpdate(t *testing.T) {
 				err: errors.New(errNotUser),
 			},
 		},
+		"ErrConnectionAccount": {
+			reason: "An error should be returned if the user is the one the provider connects as",
+			fields: fields{
+				self: "admin",
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "admin@%",
+						},
+					},
+				},
+			},
+			want: want{
+				err: xsql.CheckReserved(&v1.ObjectMeta{}, "account", "admin@%", true),
+			},
+		},
 		"ErrExec": {
 			reason: "Any errors encountered while updating the user should be returned",
 			fields: fields{
@@ -660,6 +948,164 @@ func TestUpdate(t *testing.T) {
 			},
 			want: want{},
 		},
+		"ErrGrantProxy": {
+			reason: "Any errors encountered while granting proxy should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "GRANT PROXY") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							ProxyOf: ptr.To("proxied@%"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGrantProxy),
+			},
+		},
+		"ReplaceProxy": {
+			reason: "The previously granted proxy should be revoked before the desired one is granted",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "REVOKE PROXY ON 'other'@'%' FROM 'example'@'%'",
+							"GRANT PROXY ON 'proxied'@'%' TO 'example'@'%'":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							ProxyOf: ptr.To("proxied@%"),
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							ProxyOf: ptr.To("other@%"),
+						},
+					},
+				},
+			},
+			want: want{},
+		},
+		"RevokeProxy": {
+			reason: "The previously granted proxy should be revoked once it is removed from the spec",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "REVOKE PROXY ON 'proxied'@'%' FROM 'example'@'%'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							ProxyOf: ptr.To("proxied@%"),
+						},
+					},
+				},
+			},
+			want: want{},
+		},
+		"SetDefaultRoles": {
+			reason: "The desired roles should be granted and made the only default roles of the user",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case "GRANT 'reader'@'%', 'writer'@'10.0.0.1' TO 'example'@'%'",
+							"ALTER USER 'example'@'%' DEFAULT ROLE 'reader'@'%', 'writer'@'10.0.0.1'":
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultRoles: []string{"writer@10.0.0.1", "reader"},
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							DefaultRoles: []string{"reader@%"},
+						},
+					},
+				},
+			},
+			want: want{},
+		},
+		"ErrSetDefaultRoles": {
+			reason: "Any errors encountered while setting the default roles should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.Contains(q.String, "DEFAULT ROLE") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							DefaultRoles: []string{"reader"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSetDefaultRoles),
+			},
+		},
 		"UpdatePassword": {
 			reason: "The password must be updated",
 			fields: fields{
@@ -787,6 +1233,43 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"AuthPluginChanged": {
+			reason: "A user must be identified with the desired plugin without touching its password",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != "ALTER USER 'example'@'%' IDENTIFIED WITH authentication_pam AS 'mysql'" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.UserSpec{
+						ForProvider: v1alpha1.UserParameters{
+							PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+							AuthPlugin:        ptr.To("authentication_pam"),
+							AuthString:        ptr.To("mysql"),
+						},
+					},
+					Status: v1alpha1.UserStatus{
+						AtProvider: v1alpha1.UserObservation{
+							AuthPlugin: ptr.To("caching_sha2_password"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -794,6 +1277,7 @@ func TestUpdate(t *testing.T) {
 			e := external{
 				db:   tc.fields.db,
 				kube: tc.args.kube,
+				self: tc.fields.self,
 			}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -806,6 +1290,56 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpToDate(t *testing.T) {
+	observed := &v1alpha1.UserParameters{
+		ResourceOptions: &v1alpha1.ResourceOptions{
+			MaxQueriesPerHour:     ptr.To(10),
+			MaxUpdatesPerHour:     ptr.To(0),
+			MaxConnectionsPerHour: ptr.To(0),
+			MaxUserConnections:    ptr.To(5),
+		},
+	}
+	cases := map[string]struct {
+		reason  string
+		desired *v1alpha1.UserParameters
+		want    bool
+	}{
+		"NoResourceOptions": {
+			reason:  "A user without desired resource options should be up to date",
+			desired: &v1alpha1.UserParameters{},
+			want:    true,
+		},
+		"SameResourceOptions": {
+			reason: "Resource options should be compared by value, and only if they are set",
+			desired: &v1alpha1.UserParameters{
+				ResourceOptions: &v1alpha1.ResourceOptions{
+					MaxQueriesPerHour:  ptr.To(10),
+					MaxUserConnections: ptr.To(5),
+				},
+			},
+			want: true,
+		},
+		"ChangedResourceOptions": {
+			reason: "A user whose resource options differ should not be up to date",
+			desired: &v1alpha1.UserParameters{
+				ResourceOptions: &v1alpha1.ResourceOptions{
+					MaxQueriesPerHour:  ptr.To(10),
+					MaxUserConnections: ptr.To(6),
+				},
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := upToDate(observed, tc.desired, sqlgen.FlavorVanilla); got != tc.want {
+				t.Errorf("\n%s\nupToDate(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	errBoom := errors.New("boom")
 
@@ -831,6 +1365,38 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.New(errNotUser),
 		},
+		"ErrReservedAccount": {
+			reason: "An error should be returned if the user is reserved by MySQL",
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "root@localhost",
+						},
+					},
+				},
+			},
+			want: xsql.CheckReserved(&v1.ObjectMeta{}, "account", "root@localhost", true),
+		},
+		"ReservedAccountOverride": {
+			reason: "A reserved user should be dropped if the resource sets the manage-reserved annotation",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.User{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName:   "root@localhost",
+							xsql.AnnotationKeyManageReserved: "true",
+						},
+					},
+				},
+			},
+			want: nil,
+		},
 		"ErrDropUser": {
 			reason: "Errors dropping a user should be returned",
 			fields: fields{
@@ -878,3 +1444,226 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAdoptExisting(t *testing.T) {
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			// The user exists, with no resource limits and no proxy grant.
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			return errors.Errorf("unexpected query: %s", q.String)
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.UserSpec{
+			ForProvider: v1alpha1.UserParameters{
+				AdoptExisting: ptr.To(true),
+			},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || !o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want an existing user that is up to date, got %+v", o)
+	}
+
+	// The password of the adopted user must be left as is.
+	u, err := e.Update(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	if len(u.ConnectionDetails) != 0 {
+		t.Errorf("e.Update(...): want no connection details for an adopted user, got %v", u.ConnectionDetails)
+	}
+}
+
+func TestTiDB(t *testing.T) {
+	var queries []string
+	db := mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if strings.Contains(q.String, "proxies_priv") {
+				return errors.New("TiDB has no mysql.proxies_priv")
+			}
+			if strings.Contains(q.String, "max_questions") {
+				return errors.New("TiDB has no max_questions column")
+			}
+			*dest[3].(**int) = ptr.To(10)
+			*dest[4].(*string) = "mysql_native_password"
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.UserSpec{
+			ForProvider: v1alpha1.UserParameters{
+				AuthPlugin: ptr.To("mysql_native_password"),
+				ResourceOptions: &v1alpha1.ResourceOptions{
+					MaxQueriesPerHour:  ptr.To(100),
+					MaxUserConnections: ptr.To(10),
+				},
+			},
+		},
+	}
+
+	// Resource options TiDB does not support are neither observed nor
+	// compared.
+	e := external{db: db, flavor: sqlgen.FlavorTiDB}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || !o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want an existing user that is up to date, got %+v", o)
+	}
+
+	// Nor are they set when the user is created.
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	want := []string{"CREATE USER 'example'@'%' IDENTIFIED WITH mysql_native_password WITH MAX_USER_CONNECTIONS 10"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Create(...): -want queries, +got queries:\n%s", diff)
+	}
+}
+
+func TestRename(t *testing.T) {
+	var queries []string
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if len(q.Parameters) == 2 && q.Parameters[0] == "old" {
+				return nil
+			}
+			return sql.ErrNoRows
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "new@10.0.0.%",
+			},
+		},
+		Status: v1alpha1.UserStatus{
+			AtProvider: v1alpha1.UserObservation{Name: "old"},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a renamed user that exists and is not up to date, got %+v", o)
+	}
+
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{"RENAME USER 'old'@'%' TO 'new'@'10.0.0.%'"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+	if diff := cmp.Diff("new@10.0.0.%", cr.Status.AtProvider.Name); diff != "" {
+		t.Errorf("e.Update(...): -want status.atProvider.name, +got:\n%s", diff)
+	}
+}
+
+func TestHashedPassword(t *testing.T) {
+	hash := "*" + strings.Repeat("A", 40)
+	var queries []string
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			secret := corev1.Secret{Data: map[string][]byte{"hash": []byte(hash)}}
+			secret.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+	}
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if len(dest) < 6 {
+				return sql.ErrNoRows
+			}
+			// The user is identified by a different hash.
+			*dest[4].(*string) = "mysql_native_password"
+			*dest[5].(*string) = "*" + strings.Repeat("B", 40)
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.User{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.UserSpec{
+			ForProvider: v1alpha1.UserParameters{
+				PasswordSecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Name: "example"},
+					Key:             "hash",
+				},
+				HashedPassword: ptr.To(true),
+			},
+		},
+	}
+
+	e := external{db: db, kube: kube}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a user with a drifted hash not to be up to date, got %+v", o)
+	}
+
+	u, err := e.Update(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{"ALTER USER 'example'@'%' IDENTIFIED WITH mysql_native_password AS '" + hash + "'"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+	if len(u.ConnectionDetails) != 0 {
+		t.Errorf("e.Update(...): want no connection details for a hashed password, got %v", u.ConnectionDetails)
+	}
+}
+
+func TestHashPlugin(t *testing.T) {
+	cases := map[string]string{
+		"$A$005$salt":                 "caching_sha2_password",
+		"$5$salt$abc":                 "sha256_password",
+		"*" + strings.Repeat("0", 40): "mysql_native_password",
+		"plaintext":                   "",
+	}
+	for hash, want := range cases {
+		if got := hashPlugin(hash); got != want {
+			t.Errorf("hashPlugin(%q): want %q, got %q", hash, want, got)
+		}
+	}
+}
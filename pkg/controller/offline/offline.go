@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline allows managed resources to be deleted without removing
+// their external resource when the ProviderConfig or credentials Secret
+// needed to connect to the database no longer exist, or when connecting to
+// the database keeps failing.
+package offline
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// AnnotationKeyAllowDeletion allows a managed resource to be deleted
+	// without removing its external resource when its ProviderConfig or
+	// credentials Secret is gone. It takes precedence over AllowDeletion.
+	AnnotationKeyAllowDeletion = "sql.crossplane.io/allow-offline-deletion"
+
+	reasonOfflineDeletion event.Reason = "OfflineDeletion"
+
+	errOfflineDeletion = "cannot connect to delete external resource; removing finalizer without deleting it"
+)
+
+// AllowDeletion allows offline deletion of managed resources that do not set
+// AnnotationKeyAllowDeletion. It is set by the --allow-offline-deletion flag.
+var AllowDeletion bool
+
+// DeleteAfter is the number of consecutive failed attempts to connect to
+// delete the external resource of a managed resource after which it is
+// deleted offline, whatever the error. Zero only deletes offline when the
+// ProviderConfig or credentials Secret is gone. It is set by the
+// --offline-delete-after flag.
+var DeleteAfter int
+
+// Allowed returns true if the supplied managed resource may be deleted
+// offline.
+func Allowed(mg resource.Managed) bool {
+	v, ok := mg.GetAnnotations()[AnnotationKeyAllowDeletion]
+	if !ok {
+		return AllowDeletion
+	}
+	allowed, err := strconv.ParseBool(v)
+	return err == nil && allowed
+}
+
+// A Connecter wraps an ExternalConnecter. When a managed resource that is
+// allowed to be deleted offline is being deleted and cannot be connected to
+// because a Kubernetes object it depends on is not found, or has failed to
+// be connected to DeleteAfter times in a row, the Connecter returns a client
+// that reports the external resource as gone, so that the managed
+// reconciler removes its finalizer.
+type Connecter struct {
+	managed.ExternalConnecter
+	rec         event.Recorder
+	deleteAfter int
+
+	mu       sync.Mutex
+	failures map[types.UID]int
+}
+
+// NewConnecter returns a Connecter that wraps the supplied ExternalConnecter
+// and records a warning event for every offline deletion.
+func NewConnecter(c managed.ExternalConnecter, rec event.Recorder) *Connecter {
+	return &Connecter{ExternalConnecter: c, rec: rec, deleteAfter: DeleteAfter, failures: map[types.UID]int{}}
+}
+
+// Connect to the external resource, falling back to a client that reports it
+// as gone if the managed resource may be deleted offline.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ext, err := c.ExternalConnecter.Connect(ctx, mg)
+	if !meta.WasDeleted(mg) {
+		return ext, err
+	}
+	if err == nil {
+		c.forget(mg)
+		return ext, nil
+	}
+
+	attempts := c.fail(mg)
+	if !Allowed(mg) {
+		return ext, err
+	}
+	if !kerrors.IsNotFound(errors.Cause(err)) && (c.deleteAfter == 0 || attempts < c.deleteAfter) {
+		return ext, err
+	}
+	c.forget(mg)
+	c.rec.Event(mg, event.Warning(reasonOfflineDeletion, errors.Wrap(err, errOfflineDeletion)))
+	return gone{}, nil
+}
+
+// fail records a failed attempt to connect to delete the external resource
+// of the supplied managed resource, and returns the number of consecutive
+// failed attempts.
+func (c *Connecter) fail(mg resource.Managed) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[mg.GetUID()]++
+	return c.failures[mg.GetUID()]
+}
+
+// forget stops tracking the failed attempts to connect to delete the
+// external resource of the supplied managed resource.
+func (c *Connecter) forget(mg resource.Managed) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.failures, mg.GetUID())
+}
+
+// gone is an ExternalClient for an external resource that does not exist.
+type gone struct{}
+
+func (gone) Observe(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+	return managed.ExternalObservation{ResourceExists: false}, nil
+}
+
+func (gone) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (gone) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (gone) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNotFound := errors.Wrap(kerrors.NewNotFound(schema.GroupResource{}, "default"), "cannot get ProviderConfig")
+	now := metav1.Now()
+
+	role := func(deleted bool, annotations map[string]string) *v1alpha1.Role {
+		r := &v1alpha1.Role{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+		if deleted {
+			r.SetDeletionTimestamp(&now)
+		}
+		return r
+	}
+	allow := map[string]string{AnnotationKeyAllowDeletion: "true"}
+
+	cases := map[string]struct {
+		reason      string
+		err         error
+		mg          resource.Managed
+		deleteAfter int
+		attempts    int
+		wantGone    bool
+		wantErr     error
+	}{
+		"NotDeleted": {
+			reason:  "Resources that are not being deleted should return the connect error",
+			err:     errNotFound,
+			mg:      role(false, allow),
+			wantErr: errNotFound,
+		},
+		"NotAllowed": {
+			reason:  "Resources that do not allow offline deletion should return the connect error",
+			err:     errNotFound,
+			mg:      role(true, nil),
+			wantErr: errNotFound,
+		},
+		"OtherError": {
+			reason:  "Errors other than not found should be returned",
+			err:     errBoom,
+			mg:      role(true, allow),
+			wantErr: errBoom,
+		},
+		"Offline": {
+			reason:   "Deleted resources that allow offline deletion should be reported as gone",
+			err:      errNotFound,
+			mg:       role(true, allow),
+			wantGone: true,
+		},
+		"FailingAttemptsDisabled": {
+			reason:   "Failed attempts should not allow offline deletion unless a threshold is set",
+			err:      errBoom,
+			mg:       role(true, allow),
+			attempts: 10,
+			wantErr:  errBoom,
+		},
+		"FailingBelowThreshold": {
+			reason:      "Failed attempts below the threshold should return the connect error",
+			err:         errBoom,
+			mg:          role(true, allow),
+			deleteAfter: 3,
+			attempts:    2,
+			wantErr:     errBoom,
+		},
+		"FailingAtThreshold": {
+			reason:      "Deleted resources that failed to connect as many times as the threshold should be reported as gone",
+			err:         errBoom,
+			mg:          role(true, allow),
+			deleteAfter: 3,
+			attempts:    3,
+			wantGone:    true,
+		},
+		"FailingNotAllowed": {
+			reason:      "Resources that do not allow offline deletion should return the connect error whatever the failed attempts",
+			err:         errBoom,
+			mg:          role(true, nil),
+			deleteAfter: 3,
+			attempts:    3,
+			wantErr:     errBoom,
+		},
+		"FailingNotDeleted": {
+			reason:      "Failed attempts to connect to resources that are not being deleted should return the connect error",
+			err:         errBoom,
+			mg:          role(false, allow),
+			deleteAfter: 1,
+			attempts:    1,
+			wantErr:     errBoom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return nil, tc.err
+			}), event.NewNopRecorder())
+			c.deleteAfter = tc.deleteAfter
+
+			var got managed.ExternalClient
+			var err error
+			for i := 0; i < max(tc.attempts, 1); i++ {
+				got, err = c.Connect(context.Background(), tc.mg)
+			}
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if _, isGone := got.(gone); isGone != tc.wantGone {
+				t.Errorf("\n%s\nc.Connect(...): want gone client %t, got %t", tc.reason, tc.wantGone, isGone)
+			}
+		})
+	}
+}
+
+func TestConnectForgetsAttempts(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+	mg := &v1alpha1.Role{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyAllowDeletion: "true"}}}
+	mg.SetDeletionTimestamp(&now)
+
+	errs := []error{errBoom, nil, errBoom}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		err := errs[0]
+		errs = errs[1:]
+		return nil, err
+	}), event.NewNopRecorder())
+	c.deleteAfter = 2
+
+	for range errs {
+		got, _ := c.Connect(context.Background(), mg)
+		if _, isGone := got.(gone); isGone {
+			t.Fatalf("c.Connect(...): want failed attempts to be counted from the last successful connect, got gone client")
+		}
+	}
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/oracle"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/oracle/wallet"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotGrant     = "managed resource is not a Grant custom resource"
+	errGrantee      = "exactly one of user or role must be set"
+	errNoPrivileges = "privileges must be set unless memberOf is set"
+	errSelectGrant  = "cannot select grant"
+	errCreateGrant  = "cannot create grant"
+	errRevokeGrant  = "cannot revoke grant"
+
+	optionGranted = "YES"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Grant managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.GrantGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: oracle.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, service string, opts oracle.Options) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return nil, errors.New(errNotGrant)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := oracle.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (OracleConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// Refuse to grant to the user the provider connects as, or one created by
+	// Oracle, so that a bad composition cannot lock the provider out.
+	if u := cr.Spec.ForProvider.User; u != nil {
+		reserved := oracle.IsReservedUser(*u, string(s.Data[xpv1.ResourceCredentialsSecretUserKey]))
+		if err := xsql.CheckReserved(cr, "user", *u, reserved); err != nil {
+			return nil, err
+		}
+	}
+
+	opts, err := wallet.Options(ctx, c.kube, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	db := c.newDB(s.Data, pc.Spec.ServiceName, opts)
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(db, oracle.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// grantee returns the user or role the privileges are granted to.
+func grantee(p v1alpha1.GrantParameters) (string, error) {
+	switch {
+	case p.User != nil && p.Role == nil:
+		return *p.User, nil
+	case p.Role != nil && p.User == nil:
+		return *p.Role, nil
+	default:
+		return "", errors.New(errGrantee)
+	}
+}
+
+// target returns the clause of GRANT and REVOKE statements that names the
+// object the privileges are granted on, and the query that selects each
+// privilege of the grantee on it along with whether it may be granted on.
+// System privileges have no object, so their clause is empty.
+func target(p v1alpha1.GrantParameters, to string) (string, xsql.Query) {
+	if p.On == nil {
+		return "", xsql.Query{
+			String:     "SELECT privilege, admin_option FROM dba_sys_privs WHERE grantee = :1",
+			Parameters: []interface{}{to},
+		}
+	}
+	owner, object, _ := strings.Cut(*p.On, ".")
+	return " ON " + oracle.QuoteIdentifier(owner) + "." + oracle.QuoteIdentifier(object), xsql.Query{
+		String:     "SELECT privilege, grantable FROM dba_tab_privs WHERE grantee = :1 AND owner = :2 AND table_name = :3",
+		Parameters: []interface{}{to, owner, object},
+	}
+}
+
+// withOption returns true if the privileges are to be granted with the
+// option to grant them on: the grant option for object privileges and the
+// admin option for system privileges and roles.
+func withOption(p v1alpha1.GrantParameters) bool {
+	if p.On != nil && p.MemberOf == nil {
+		return p.WithGrantOption != nil && *p.WithGrantOption
+	}
+	return p.WithAdminOption != nil && *p.WithAdminOption
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	to, err := grantee(p)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	if p.MemberOf != nil {
+		return c.observeMembership(ctx, cr, to)
+	}
+	if len(p.Privileges) == 0 {
+		return managed.ExternalObservation{}, errors.New(errNoPrivileges)
+	}
+
+	_, query := target(p, to)
+	rows, err := c.db.Query(ctx, query)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	// Other grants may give the grantee further privileges, so only those
+	// that are desired or were granted by this one are considered. The
+	// options are immutable, so a privilege that lacks a desired option is
+	// treated as missing and granted again with it.
+	desired := p.Privileges.ToStringSlice()
+	var observed []string
+	for rows.Next() {
+		var privilege, option string
+		if err := rows.Scan(&privilege, &option); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+		}
+		if withOption(p) && option != optionGranted {
+			continue
+		}
+		if slices.Contains(desired, privilege) || slices.Contains(cr.Status.AtProvider.Privileges, privilege) {
+			observed = append(observed, privilege)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+	if len(observed) == 0 {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	sort.Strings(observed)
+
+	cr.Status.AtProvider.Privileges = observed
+	cr.SetConditions(xpv1.Available())
+
+	toGrant, toRevoke := diffPermissions(desired, observed)
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0,
+	}, nil
+}
+
+func (c *external) observeMembership(ctx context.Context, cr *v1alpha1.Grant, to string) (managed.ExternalObservation, error) {
+	p := cr.Spec.ForProvider
+
+	var option string
+	query := "SELECT admin_option FROM dba_role_privs WHERE grantee = :1 AND granted_role = :2"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{to, *p.MemberOf}}, &option)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+
+	// The admin option is immutable, so a membership that lacks it is
+	// treated as missing and granted again with it.
+	if withOption(p) && option != optionGranted {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	// Role memberships have no parameters that can be updated.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	to, err := grantee(p)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	query := createGrantQuery(p, to, p.Privileges.ToStringSlice())
+	if err := oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	to, err := grantee(p)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if p.MemberOf != nil {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	toGrant, toRevoke := diffPermissions(p.Privileges.ToStringSlice(), cr.Status.AtProvider.Privileges)
+
+	if len(toRevoke) > 0 {
+		query := createRevokeQuery(p, to, toRevoke)
+		if err := oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errRevokeGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if len(toGrant) > 0 {
+		query := createGrantQuery(p, to, toGrant)
+		if err := oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	to, err := grantee(p)
+	if err != nil {
+		return err
+	}
+
+	query := createRevokeQuery(p, to, p.Privileges.ToStringSlice())
+	return oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errRevokeGrant})
+}
+
+// granted returns the privileges, or the role when memberOf is set, as
+// they appear in GRANT and REVOKE statements.
+func granted(p v1alpha1.GrantParameters, privileges []string) string {
+	if p.MemberOf != nil {
+		return oracle.QuoteIdentifier(*p.MemberOf)
+	}
+	sort.Strings(privileges)
+	return strings.Join(privileges, ", ")
+}
+
+func createGrantQuery(p v1alpha1.GrantParameters, to string, privileges []string) string {
+	on := ""
+	if p.MemberOf == nil {
+		on, _ = target(p, to)
+	}
+	result := fmt.Sprintf("GRANT %s%s TO %s", granted(p, privileges), on, oracle.QuoteIdentifier(to))
+	switch {
+	case withOption(p) && p.On != nil && p.MemberOf == nil:
+		result += " WITH GRANT OPTION"
+	case withOption(p):
+		result += " WITH ADMIN OPTION"
+	}
+	return result
+}
+
+func createRevokeQuery(p v1alpha1.GrantParameters, to string, privileges []string) string {
+	on := ""
+	if p.MemberOf == nil {
+		on, _ = target(p, to)
+	}
+	return fmt.Sprintf("REVOKE %s%s FROM %s", granted(p, privileges), on, oracle.QuoteIdentifier(to))
+}
+func diffPermissions(desired, observed []string) ([]string, []string) {
+	return subtract(desired, observed), subtract(observed, desired)
+}
+
+// subtract returns the elements of a that are not in b.
+func subtract(a, b []string) []string {
+	bMap := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		bMap[v] = struct{}{}
+	}
+
+	var out []string
+	for _, v := range a {
+		if _, ok := bMap[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
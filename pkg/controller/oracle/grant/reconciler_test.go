@@ -0,0 +1,620 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/oracle"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func grant(p v1alpha1.GrantParameters) *v1alpha1.Grant {
+	if p.Role == nil && p.User == nil {
+		p.User = ptr.To("APP")
+	}
+	return &v1alpha1.Grant{
+		Spec: v1alpha1.GrantSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{},
+			},
+			ForProvider: p,
+		},
+	}
+}
+
+func selectQuery(want string, rows *sqlmock.Rows) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		if q.String != want {
+			return nil, errors.Errorf("unexpected query: %s", q.String)
+		}
+		return mockRowsToSQLRows(rows), nil
+	}
+}
+
+func execQueries(want ...string) func(ctx context.Context, q xsql.Query) error {
+	return func(ctx context.Context, q xsql.Query) error {
+		for _, w := range want {
+			if q.String == w {
+				return nil
+			}
+		}
+		return errors.Errorf("unexpected query: %s", q.String)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, service string, opts oracle.Options) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.ProviderConfig:
+				o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+			case *corev1.Secret:
+				o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretUserKey: []byte("ADMIN")}
+			}
+			return nil
+		}),
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{}),
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrReservedUser": {
+			reason: "An error should be returned if the grantee is created by Oracle",
+			fields: fields{
+				kube:  kube,
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("SYSTEM")}),
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "SYSTEM", true),
+		},
+		"ErrConnectionUser": {
+			reason: "An error should be returned if the grantee is the user the provider connects as",
+			fields: fields{
+				kube:  kube,
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("ADMIN")}),
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "ADMIN", true),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.GrantObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrGrantee": {
+			reason: "An error should be returned if both a user and a role are set",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("APP"), Role: ptr.To("ANALYST")}),
+			},
+			want: want{
+				err: errors.New(errGrantee),
+			},
+		},
+		"ErrNoPrivileges": {
+			reason: "An error should be returned if neither privileges nor memberOf are set",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{}),
+			},
+			want: want{
+				err: errors.New(errNoPrivileges),
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while trying to select the grants",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE SESSION"}}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGrant),
+			},
+		},
+		"SuccessSystem": {
+			reason: "Desired system privileges of the grantee should be observed, ignoring those granted otherwise",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery("SELECT privilege, admin_option FROM dba_sys_privs WHERE grantee = :1",
+						sqlmock.NewRows([]string{"privilege", "admin_option"}).
+							AddRow("CREATE SESSION", "NO").
+							AddRow("CREATE TABLE", "NO").
+							AddRow("UNLIMITED TABLESPACE", "NO")),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE TABLE", "CREATE SESSION"}}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.GrantObservation{Privileges: []string{"CREATE SESSION", "CREATE TABLE"}},
+			},
+		},
+		"ObjectPrivilegeRemoved": {
+			reason: "Object privileges granted by this grant but no longer desired should be observed",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery("SELECT privilege, grantable FROM dba_tab_privs WHERE grantee = :1 AND owner = :2 AND table_name = :3",
+						sqlmock.NewRows([]string{"privilege", "grantable"}).
+							AddRow("SELECT", "NO").
+							AddRow("DELETE", "NO").
+							AddRow("INSERT", "NO")),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+							On:         ptr.To("HR.EMPLOYEES"),
+							User:       ptr.To("APP"),
+						},
+					},
+					Status: v1alpha1.GrantStatus{
+						AtProvider: v1alpha1.GrantObservation{Privileges: []string{"DELETE", "SELECT"}},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.GrantObservation{Privileges: []string{"DELETE", "SELECT"}},
+			},
+		},
+		"MissingGrantOption": {
+			reason: "We should return ResourceExists: false when the object privileges lack the desired grant option",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery("SELECT privilege, grantable FROM dba_tab_privs WHERE grantee = :1 AND owner = :2 AND table_name = :3",
+						sqlmock.NewRows([]string{"privilege", "grantable"}).
+							AddRow("SELECT", "NO")),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+					On:              ptr.To("HR.EMPLOYEES"),
+					WithGrantOption: ptr.To(true),
+				}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"NoMembership": {
+			reason: "We should return ResourceExists: false when the grantee has not been granted the role",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("READER")}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"SuccessMembership": {
+			reason: "A role granted to the grantee with the desired admin option should be observed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if q.String != "SELECT admin_option FROM dba_role_privs WHERE grantee = :1 AND granted_role = :2" {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						*dest[0].(*string) = "YES"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("READER"), WithAdminOption: ptr.To(true)}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Grant); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrCreateGrant": {
+			reason: "Any errors encountered while creating the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE SESSION"}}),
+			},
+			want: errors.Wrap(errBoom, errCreateGrant),
+		},
+		"SuccessSystem": {
+			reason: "System privileges should be granted with the admin option",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT CREATE SESSION, CREATE TABLE TO "APP" WITH ADMIN OPTION`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"CREATE TABLE", "CREATE SESSION"},
+					WithAdminOption: ptr.To(true),
+				}),
+			},
+			want: nil,
+		},
+		"SuccessObject": {
+			reason: "Object privileges should be granted on the object with the grant option",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT INSERT, SELECT ON "HR"."EMPLOYEES" TO "APP" WITH GRANT OPTION`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"SELECT", "INSERT"},
+					On:              ptr.To("HR.EMPLOYEES"),
+					WithGrantOption: ptr.To(true),
+				}),
+			},
+			want: nil,
+		},
+		"SuccessMembership": {
+			reason: "The role should be granted to the grantee role if memberOf is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT "READER" TO "ANALYST"`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("READER"), Role: ptr.To("ANALYST")}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	stale := func() *v1alpha1.Grant {
+		cr := grant(v1alpha1.GrantParameters{
+			Privileges: v1alpha1.GrantPrivileges{"SELECT", "INSERT"},
+			On:         ptr.To("HR.EMPLOYEES"),
+		})
+		cr.Status.AtProvider.Privileges = []string{"DELETE", "SELECT"}
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrRevokeGrant": {
+			reason: "Any errors encountered while revoking privileges should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: stale(),
+			},
+			want: errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"Success": {
+			reason: "Privileges no longer desired should be revoked and new ones granted",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(
+						`REVOKE DELETE ON "HR"."EMPLOYEES" FROM "APP"`,
+						`GRANT INSERT ON "HR"."EMPLOYEES" TO "APP"`,
+					),
+				},
+			},
+			args: args{
+				mg: stale(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrRevokeGrant": {
+			reason: "Errors revoking the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE SESSION"}}),
+			},
+			want: errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"SuccessMembership": {
+			reason: "The role should be revoked from the grantee if memberOf is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`REVOKE "READER" FROM "APP"`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("READER")}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/oracle"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/oracle/wallet"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotUser                 = "managed resource is not a User custom resource"
+	errSelectUser              = "cannot select user"
+	errCreateUser              = "cannot create user"
+	errDropUser                = "cannot drop user"
+	errUpdateUser              = "cannot update user"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles User managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.UserGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.User{}, &v1alpha1.UserList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.User)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.User{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.UserGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.UserGroupKind))
+}
+
+// NewConnecter returns a connecter for User managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: oracle.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, service string, opts oracle.Options) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return nil, errors.New(errNotUser)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := oracle.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (OracleConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// Refuse to manage the user the provider connects as, or one created by
+	// Oracle, so that a bad composition cannot lock the provider out.
+	reserved := oracle.IsReservedUser(meta.GetExternalName(cr), string(s.Data[xpv1.ResourceCredentialsSecretUserKey]))
+	if err := xsql.CheckReserved(cr, "user", meta.GetExternalName(cr), reserved); err != nil {
+		return nil, err
+	}
+
+	opts, err := wallet.Options(ctx, c.kube, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(c.newDB(s.Data, pc.Spec.ServiceName, opts), oracle.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+// isLocked returns true if the supplied account status, e.g. LOCKED(TIMED)
+// or EXPIRED & LOCKED, reports a locked account.
+func isLocked(status string) bool {
+	return strings.Contains(status, "LOCKED")
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotUser)
+	}
+
+	var status, defaultTablespace, temporaryTablespace, profile string
+	query := "SELECT account_status, default_tablespace, temporary_tablespace, profile FROM dba_users WHERE username = :1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &status, &defaultTablespace, &temporaryTablespace, &profile)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
+	}
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.Status.AtProvider.AccountStatus = status
+	cr.SetConditions(xpv1.Available())
+
+	p := cr.Spec.ForProvider
+	upToDate := !pwdChanged &&
+		(p.DefaultTablespace == nil || *p.DefaultTablespace == defaultTablespace) &&
+		(p.TemporaryTablespace == nil || *p.TemporaryTablespace == temporaryTablespace) &&
+		(p.Profile == nil || *p.Profile == profile) &&
+		(p.AccountLocked == nil || *p.AccountLocked == isLocked(status))
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+// userClauses returns the tablespace, profile and account clauses of
+// CREATE USER and ALTER USER for the supplied parameters.
+func userClauses(p v1alpha1.UserParameters) string {
+	out := ""
+	if p.DefaultTablespace != nil {
+		out += " DEFAULT TABLESPACE " + oracle.QuoteIdentifier(*p.DefaultTablespace)
+	}
+	if p.TemporaryTablespace != nil {
+		out += " TEMPORARY TABLESPACE " + oracle.QuoteIdentifier(*p.TemporaryTablespace)
+	}
+	if p.Profile != nil {
+		out += " PROFILE " + oracle.QuoteIdentifier(*p.Profile)
+	}
+	if p.AccountLocked != nil {
+		if *p.AccountLocked {
+			out += " ACCOUNT LOCK"
+		} else {
+			out += " ACCOUNT UNLOCK"
+		}
+	}
+	return out
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	name := meta.GetExternalName(cr)
+
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if pw == "" {
+		pw, err = password.Generate()
+		if err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	query := "CREATE USER " + oracle.QuoteIdentifier(name) + " IDENTIFIED BY " + oracle.QuoteIdentifier(pw) + userClauses(cr.Spec.ForProvider)
+	if err := oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errCreateUser}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{
+		ConnectionDetails: c.db.GetConnectionDetails(name, pw),
+	}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotUser)
+	}
+
+	name := meta.GetExternalName(cr)
+
+	pw, pwchanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	clauses := userClauses(cr.Spec.ForProvider)
+	if pwchanged {
+		clauses = " IDENTIFIED BY " + oracle.QuoteIdentifier(pw) + clauses
+	}
+	if clauses != "" {
+		query := "ALTER USER " + oracle.QuoteIdentifier(name) + clauses
+		if err := oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if pwchanged {
+		return managed.ExternalUpdate{ConnectionDetails: c.db.GetConnectionDetails(name, pw)}, nil
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	query := "DROP USER " + oracle.QuoteIdentifier(meta.GetExternalName(cr))
+	if cr.Spec.ForProvider.CascadeOnDelete != nil && *cr.Spec.ForProvider.CascadeOnDelete {
+		query += " CASCADE"
+	}
+	return oracle.ExecWrapper(ctx, c.db, oracle.ExecQuery{Query: query, ErrorValue: errDropUser})
+}
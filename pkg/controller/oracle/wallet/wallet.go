@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wallet
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-sql/apis/oracle/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/oracle"
+)
+
+const (
+	errGetWallet   = "cannot get wallet Secret"
+	errWriteWallet = "cannot write wallet"
+)
+
+// Options returns the options used to connect to the database of the
+// supplied ProviderConfig. The files of its wallet Secret, if any, are
+// written to a directory of their own so the driver can read them.
+func Options(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) (oracle.Options, error) {
+	o := oracle.Options{
+		TLS:               pc.Spec.TLS != nil && *pc.Spec.TLS,
+		ConnectionOptions: pc.Spec.ConnectionOptions,
+	}
+
+	ref := pc.Spec.WalletSecretRef
+	if ref == nil {
+		return o, nil
+	}
+
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return oracle.Options{}, errors.Wrap(err, errGetWallet)
+	}
+
+	dir := oracle.WalletDir(pc.GetName())
+	if err := oracle.WriteWallet(dir, s.Data); err != nil {
+		return oracle.Options{}, errors.Wrap(err, errWriteWallet)
+	}
+
+	o.TLS = true
+	o.Wallet = dir
+	return o, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis"
+)
+
+// setupManager is a manager that keeps the controllers it is given, rather
+// than starting them.
+type setupManager struct {
+	fake.Manager
+	controllers []reconcile.Reconciler
+}
+
+func (m *setupManager) Add(r manager.Runnable) error {
+	if c, ok := r.(reconcile.Reconciler); ok {
+		m.controllers = append(m.controllers, c)
+	}
+	return nil
+}
+
+func (m *setupManager) GetControllerOptions() config.Controller {
+	return config.Controller{}
+}
+
+func (m *setupManager) GetEventRecorderFor(_ string) record.EventRecorder {
+	return record.NewFakeRecorder(100)
+}
+
+func (m *setupManager) GetFieldIndexer() client.FieldIndexer {
+	return m
+}
+
+func (m *setupManager) IndexField(_ context.Context, _ client.Object, _ string, _ client.IndexerFunc) error {
+	return nil
+}
+
+// TestPaused ensures that the controller Setup creates for every managed
+// resource kind this provider serves honors the crossplane.io/paused
+// annotation: a paused resource must be marked as such without the provider
+// connecting to its database to observe, create, update or delete it, even
+// while it is being deleted.
+func TestPaused(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("apis.AddToScheme(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		deleted bool
+	}{
+		"Paused":             {deleted: false},
+		"PausedWhileDeleted": {deleted: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var (
+				kind      string
+				got       resource.Managed
+				connected []string
+			)
+			kube := test.NewMockClient()
+			kube.MockGet = func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				gvks, _, _ := s.ObjectKinds(obj)
+				mg, ok := obj.(resource.Managed)
+				if !ok {
+					// Reconcilers of managed resources get nothing else
+					// before they connect to the database.
+					if kind != "" && len(gvks) > 0 {
+						connected = append(connected, gvks[0].Kind)
+					}
+					return nil
+				}
+				if len(gvks) > 0 {
+					kind = gvks[0].GroupKind().String()
+				}
+				mg.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+				meta.AddAnnotations(mg, map[string]string{meta.AnnotationKeyReconciliationPaused: "true"})
+				if tc.deleted {
+					now := metav1.Now()
+					mg.SetDeletionTimestamp(&now)
+					meta.AddFinalizer(mg, "finalizer.managedresource.crossplane.io")
+				}
+				return nil
+			}
+			kube.MockStatusUpdate = func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+				got, _ = obj.(resource.Managed)
+				return nil
+			}
+
+			mgr := &setupManager{Manager: fake.Manager{Client: kube, Scheme: s}}
+			o := controller.Options{Logger: logging.NewNopLogger(), Features: &feature.Flags{}}
+			if err := Setup(mgr, o); err != nil {
+				t.Fatalf("Setup(...): %v", err)
+			}
+
+			tested := map[string]bool{}
+			for _, c := range mgr.controllers {
+				kind, got, connected = "", nil, nil
+				if _, err := c.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "paused"}}); err != nil {
+					t.Errorf("%s: c.Reconcile(...): %v", kind, err)
+				}
+				if kind == "" {
+					// Not the controller of a managed resource kind.
+					continue
+				}
+				tested[kind] = true
+				if len(connected) > 0 {
+					t.Errorf("%s: got %v to connect to the database of a paused resource", kind, connected)
+				}
+				if got == nil {
+					t.Errorf("%s: status of a paused resource was not updated", kind)
+					continue
+				}
+				if diff := cmp.Diff(xpv1.ReconcilePaused(), got.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+					t.Errorf("%s: -want synced condition, +got synced condition:\n%s", kind, diff)
+				}
+			}
+
+			for gvk := range s.AllKnownTypes() {
+				obj, err := s.New(gvk)
+				if err != nil {
+					t.Fatalf("s.New(%s): %v", gvk, err)
+				}
+				if _, ok := obj.(resource.Managed); ok && !tested[gvk.GroupKind().String()] {
+					t.Errorf("Setup(...): no controller reconciles %s", gvk.GroupKind())
+				}
+			}
+		})
+	}
+}
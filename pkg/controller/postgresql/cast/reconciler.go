@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cast
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotCast     = "managed resource is not a Cast custom resource"
+	errSelectCast  = "cannot select cast"
+	errResolveCast = "cannot resolve cast types"
+	errCreateCast  = "cannot create cast"
+	errUpdateCast  = "cannot recreate cast"
+	errDropCast    = "cannot drop cast"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Cast managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.CastGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CastGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Cast{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.CastGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.CastGroupKind))
+}
+
+// NewConnecter returns a connecter for Cast managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Cast)
+	if !ok {
+		return nil, errors.New(errNotCast)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	// We do not want to create a cast on the default DB
+	// if the user was expecting a database name to be resolved.
+	if cr.Spec.ForProvider.Database != nil {
+		return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, *cr.Spec.ForProvider.Database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.CastKind, cr)}, nil
+	}
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.CastKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Cast)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCast)
+	}
+
+	var castContext, method string
+	var function bool
+
+	query := "SELECT " +
+		"CASE c.castcontext WHEN 'a' THEN 'Assignment' WHEN 'i' THEN 'Implicit' ELSE 'Explicit' END, " +
+		"c.castmethod, " +
+		"c.castfunc = COALESCE(to_regprocedure($3)::oid, 0) " +
+		"FROM pg_catalog.pg_cast c " +
+		"WHERE c.castsource = to_regtype($1) AND c.casttarget = to_regtype($2)"
+
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{cr.Spec.ForProvider.SourceType, cr.Spec.ForProvider.TargetType, cr.Spec.ForProvider.Function},
+	},
+		&castContext,
+		&method,
+		&function,
+	)
+
+	// If the database we try to connect on does not exist then
+	// there cannot be a cast in that database either.
+	if xsql.IsNoRows(err) || postgresql.IsInvalidCatalog(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectCast)
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: castContext == desiredContext(cr.Spec.ForProvider) && method == desiredMethod(cr.Spec.ForProvider) && function,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Cast)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCast)
+	}
+
+	sig, err := c.resolve(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errResolveCast)
+	}
+
+	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, sig.create(cr.Spec.ForProvider)), errCreateCast)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Cast)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCast)
+	}
+
+	sig, err := c.resolve(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errResolveCast)
+	}
+
+	// Casts cannot be altered, so we replace the cast in a transaction.
+	err = c.db.ExecTx(ctx, []xsql.Query{sig.drop(), sig.create(cr.Spec.ForProvider)})
+	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCast)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Cast)
+	if !ok {
+		return errors.New(errNotCast)
+	}
+
+	sig, err := c.resolve(ctx, cr.Spec.ForProvider)
+	// A cast cannot exist if either of its types, or its database, does not.
+	if postgresql.IsUndefinedObject(err) || postgresql.IsInvalidCatalog(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errResolveCast)
+	}
+
+	err = c.db.Exec(ctx, sig.drop())
+	if postgresql.IsInvalidCatalog(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropCast)
+}
+
+// signature holds the types and function of a cast as formatted by
+// PostgreSQL, which makes them safe to embed in a statement.
+type signature struct {
+	source   string
+	target   string
+	function string
+}
+
+func (c *external) resolve(ctx context.Context, p v1alpha1.CastParameters) (signature, error) {
+	sig := signature{}
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT $1::regtype::text, $2::regtype::text, COALESCE($3::regprocedure::text, '')",
+		Parameters: []interface{}{p.SourceType, p.TargetType, p.Function},
+	},
+		&sig.source,
+		&sig.target,
+		&sig.function,
+	)
+	return sig, err
+}
+
+func (s signature) create(p v1alpha1.CastParameters) xsql.Query {
+	var b strings.Builder
+	b.WriteString("CREATE CAST (" + s.source + " AS " + s.target + ")")
+
+	switch desiredMethod(p) {
+	case methodFunction:
+		b.WriteString(" WITH FUNCTION " + s.function)
+	case methodInOut:
+		b.WriteString(" WITH INOUT")
+	default:
+		b.WriteString(" WITHOUT FUNCTION")
+	}
+
+	switch desiredContext(p) {
+	case contextAssignment:
+		b.WriteString(" AS ASSIGNMENT")
+	case contextImplicit:
+		b.WriteString(" AS IMPLICIT")
+	}
+
+	return xsql.Query{String: b.String()}
+}
+
+func (s signature) drop() xsql.Query {
+	return xsql.Query{String: "DROP CAST IF EXISTS (" + s.source + " AS " + s.target + ")"}
+}
+
+const (
+	contextExplicit   = "Explicit"
+	contextAssignment = "Assignment"
+	contextImplicit   = "Implicit"
+
+	// Values of pg_cast.castmethod.
+	methodFunction = "f"
+	methodInOut    = "i"
+	methodBinary   = "b"
+)
+
+func desiredContext(p v1alpha1.CastParameters) string {
+	if p.Context == nil {
+		return contextExplicit
+	}
+	return *p.Context
+}
+
+func desiredMethod(p v1alpha1.CastParameters) string {
+	switch {
+	case p.Function != nil:
+		return methodFunction
+	case p.InOut != nil && *p.InOut:
+		return methodInOut
+	default:
+		return methodBinary
+	}
+}
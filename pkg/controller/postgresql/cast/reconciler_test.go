@@ -0,0 +1,590 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cast
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotCast": {
+			reason: "An error should be returned if the managed resource is not a Cast",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotCast),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetPC),
+		},
+		"ErrMissingConnectionSecret": {
+			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					// We call get to populate the Database struct, then again
+					// to populate the (empty) ProviderConfig struct, resulting
+					// in a ProviderConfig with a nil connection secret.
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.New(errNoSecretRef),
+		},
+		"ErrGetConnectionSecret": {
+			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						case *corev1.Secret:
+							return errBoom
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetSecret),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	observe := func(castContext, method string, function bool) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = castContext
+			*dest[1].(*string) = method
+			*dest[2].(*bool) = function
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotCast": {
+			reason: "An error should be returned if the managed resource is not a Cast",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotCast),
+			},
+		},
+		"ErrNoCast": {
+			reason: "We should return ResourceExists: false when no cast is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectCast": {
+			reason: "We should return any errors encountered while trying to select the cast",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectCast),
+			},
+		},
+		"Success": {
+			reason: "We should return no error if we can successfully select our cast",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(contextExplicit, methodBinary, true),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"SuccessContextChanged": {
+			reason: "A cast invoked in a different context should not be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(contextExplicit, methodBinary, true),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ForProvider: v1alpha1.CastParameters{
+							Context: ptr.To(contextImplicit),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"SuccessFunctionChanged": {
+			reason: "A cast performed by a different function should not be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: observe(contextExplicit, methodFunction, false),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ForProvider: v1alpha1.CastParameters{
+							Function: ptr.To("public.to_my_type(text)"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func resolved(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	*dest[0].(*string) = "text"
+	*dest[1].(*string) = "my_type"
+	*dest[2].(*string) = "to_my_type(text)"
+	return nil
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotCast": {
+			reason: "An error should be returned if the managed resource is not a Cast",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotCast),
+			},
+		},
+		"ErrResolve": {
+			reason: "Any errors encountered while resolving the cast types should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errResolveCast),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the cast should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: resolved,
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateCast),
+			},
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a cast",
+			fields: fields{
+				db: &mockDB{
+					MockScan: resolved,
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := "CREATE CAST (text AS my_type) WITH FUNCTION to_my_type(text) AS IMPLICIT"; q.String != want {
+							return errors.Errorf("unexpected query %q", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{
+					Spec: v1alpha1.CastSpec{
+						ForProvider: v1alpha1.CastParameters{
+							SourceType: "text",
+							TargetType: "my_type",
+							Function:   ptr.To("to_my_type(text)"),
+							Context:    ptr.To(contextImplicit),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		u   managed.ExternalUpdate
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotCast": {
+			reason: "An error should be returned if the managed resource is not a Cast",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotCast),
+			},
+		},
+		"ErrExecTx": {
+			reason: "Any errors encountered while recreating the cast should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan:   resolved,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdateCast),
+			},
+		},
+		"Success": {
+			reason: "The cast should be dropped and created again in one transaction",
+			fields: fields{
+				db: &mockDB{
+					MockScan: resolved,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if len(ql) != 2 || ql[0].String != "DROP CAST IF EXISTS (text AS my_type)" {
+							return errors.Errorf("unexpected queries %v", ql)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotCast": {
+			reason: "An error should be returned if the managed resource is not a Cast",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotCast),
+		},
+		"SuccessUndefinedType": {
+			reason: "No error should be returned if a type of the cast no longer exists",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return &pq.Error{Code: "42704"}
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: nil,
+		},
+		"ErrDropCast": {
+			reason: "Errors dropping a cast should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockScan: resolved,
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Cast{},
+			},
+			want: errors.Wrap(errBoom, errDropCast),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collation
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotCollation    = "managed resource is not a Collation custom resource"
+	errSelectCollation = "cannot select collation"
+	errCreateCollation = "cannot create collation"
+	errDropCollation   = "cannot drop collation"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Collation managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.CollationGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CollationGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Collation{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.CollationGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.CollationGroupKind))
+}
+
+// NewConnecter returns a connecter for Collation managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Collation)
+	if !ok {
+		return nil, errors.New(errNotCollation)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	// We do not want to create a collation on the default DB
+	// if the user was expecting a database name to be resolved.
+	if cr.Spec.ForProvider.Database != nil {
+		return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, *cr.Spec.ForProvider.Database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.CollationKind, cr)}, nil
+	}
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.CollationKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Collation)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCollation)
+	}
+
+	// If the Collation exists, it will have all of these properties.
+	observed := v1alpha1.CollationParameters{
+		Provider:      new(string),
+		Deterministic: new(bool),
+	}
+
+	query := "SELECT " +
+		"CASE c.collprovider WHEN 'i' THEN 'icu' WHEN 'b' THEN 'builtin' ELSE 'libc' END, " +
+		"c.collisdeterministic " +
+		"FROM pg_catalog.pg_collation c " +
+		"JOIN pg_catalog.pg_namespace n ON n.oid = c.collnamespace " +
+		"WHERE c.collname = $1 AND n.nspname = COALESCE($2, current_schema())"
+
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr), cr.Spec.ForProvider.Schema},
+	},
+		observed.Provider,
+		observed.Deterministic,
+	)
+
+	// If the database we try to connect on does not exist then
+	// there cannot be a collation in that database either.
+	if xsql.IsNoRows(err) || postgresql.IsInvalidCatalog(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectCollation)
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	// Collations cannot be altered, so an existing collation is always
+	// considered up to date.
+	return managed.ExternalObservation{
+		ResourceExists:          true,
+		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
+		ResourceUpToDate:        true,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Collation)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCollation)
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE COLLATION IF NOT EXISTS ")
+	b.WriteString(qualifiedName(cr))
+
+	if cr.Spec.ForProvider.From != nil {
+		b.WriteString(" FROM ")
+		b.WriteString(pq.QuoteIdentifier(*cr.Spec.ForProvider.From))
+	} else {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(collationOptions(cr.Spec.ForProvider), ", "))
+		b.WriteString(")")
+	}
+
+	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: b.String()}), errCreateCollation)
+}
+
+func (c *external) Update(_ context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	_, ok := mg.(*v1alpha1.Collation)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCollation)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Collation)
+	if !ok {
+		return errors.New(errNotCollation)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP COLLATION IF EXISTS " + qualifiedName(cr)})
+	if postgresql.IsInvalidCatalog(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropCollation)
+}
+
+func qualifiedName(cr *v1alpha1.Collation) string {
+	name := pq.QuoteIdentifier(meta.GetExternalName(cr))
+	if cr.Spec.ForProvider.Schema != nil {
+		return pq.QuoteIdentifier(*cr.Spec.ForProvider.Schema) + "." + name
+	}
+	return name
+}
+
+func collationOptions(p v1alpha1.CollationParameters) []string {
+	o := []string{}
+	if p.Provider != nil {
+		o = append(o, "PROVIDER = "+*p.Provider)
+	}
+	if p.Locale != nil {
+		o = append(o, "LOCALE = "+pq.QuoteLiteral(*p.Locale))
+	} else {
+		if p.LCCollate != nil {
+			o = append(o, "LC_COLLATE = "+pq.QuoteLiteral(*p.LCCollate))
+		}
+		if p.LCCtype != nil {
+			o = append(o, "LC_CTYPE = "+pq.QuoteLiteral(*p.LCCtype))
+		}
+	}
+	if p.Deterministic != nil {
+		o = append(o, "DETERMINISTIC = "+strconv.FormatBool(*p.Deterministic))
+	}
+	return o
+}
+
+func lateInit(observed v1alpha1.CollationParameters, desired *v1alpha1.CollationParameters) bool {
+	if desired.From != nil {
+		return false
+	}
+
+	li := false
+
+	if desired.Provider == nil && observed.Provider != nil {
+		desired.Provider = observed.Provider
+		li = true
+	}
+	if desired.Deterministic == nil && observed.Deterministic != nil {
+		desired.Deterministic = observed.Deterministic
+		li = true
+	}
+
+	return li
+}
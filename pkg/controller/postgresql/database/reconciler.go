@@ -27,6 +27,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,6 +35,7 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -42,22 +44,50 @@ import (
 	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 
 	errNotDatabase       = "managed resource is not a Database custom resource"
+	errInvalidName       = "invalid database name"
 	errSelectDB          = "cannot select database"
 	errCreateDB          = "cannot create database"
 	errAlterDBOwner      = "cannot alter database owner"
+	errReassignOwned     = "cannot reassign objects owned by the previous database owner"
 	errAlterDBConnLimit  = "cannot alter database connection limit"
 	errAlterDBAllowConns = "cannot alter database allow connections"
 	errAlterDBIsTmpl     = "cannot alter database is template"
+	errTerminateConns    = "cannot terminate connections to the database"
 	errDropDB            = "cannot drop database"
+	errServerVersion     = "cannot select server version"
+	errSelectCollVersion = "cannot select database collation version"
+	errRefreshCollVer    = "cannot refresh database collation version"
+	errCommentDB         = "cannot set database comment"
+	errLocaleVersion     = "localeProvider, icuLocale and collationVersionRefresh require PostgreSQL 15 or later"
+	errOIDVersion        = "oid requires PostgreSQL 15 or later"
+	errSetDBAudit        = "cannot set database audit settings"
+
+	maxTerminateAttempts = 3
+
+	// Versions of PostgreSQL that introduced the clauses of CREATE and DROP
+	// DATABASE the provider uses.
+	versionDropForce = 130000
+	versionLocales   = 150000
+	versionOID       = 150000
+	versionStrategy  = 150000
 
 	maxConcurrency = 5
 )
@@ -67,26 +97,37 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Database{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseGroupKind))
+}
+
+// NewConnecter returns a connecter for Database managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -105,24 +146,65 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
-	return &external{db: c.newDB(s.Data, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode))}, nil
+	return &external{
+		db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr),
+		dbIn: func(database string) xsql.DB {
+			return xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr)
+		},
+		providerConfig: pc.GetName(),
+	}, nil
+}
+
+type external struct {
+	db xsql.DB
+
+	// providerConfig is the name of the ProviderConfig of the database,
+	// whose server version is cached.
+	providerConfig string
+
+	// dbIn returns a client connected to the supplied database. Some
+	// statements, such as REASSIGN OWNED, only affect the database they
+	// are run in.
+	dbIn func(database string) xsql.DB
+
+	// refreshCollation is true if the last observation found that the
+	// collation version of the database is outdated.
+	refreshCollation bool
+
+	// audit is the configuration of the parameters managed by the audit
+	// settings of the database, as found by the last observation.
+	audit map[string]string
 }
 
-type external struct{ db xsql.DB }
+// localeProviders maps the locale providers of pg_database to their names.
+var localeProviders = map[string]string{
+	"b": "builtin",
+	"c": "libc",
+	"i": "icu",
+}
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
 	cr, ok := mg.(*v1alpha1.Database)
@@ -141,6 +223,9 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		IsTemplate:       new(bool),
 		Tablespace:       new(string),
 	}
+	var size, oid int64
+	var provider, iculocale, comment string
+	var setconfig []string
 
 	query := "SELECT " +
 		"pg_catalog.pg_get_userbyid(db.datdba), " +
@@ -150,7 +235,15 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		"db.datallowconn, " +
 		"db.datconnlimit, " +
 		"db.datistemplate, " +
-		"ts.spcname " +
+		"ts.spcname, " +
+		"CASE WHEN has_database_privilege(db.oid, 'CONNECT') THEN pg_catalog.pg_database_size(db.oid) ELSE 0 END, " +
+		// The locale columns only exist as of PostgreSQL 15, and
+		// daticulocale was renamed datlocale in PostgreSQL 17.
+		"COALESCE(to_jsonb(db)->>'datlocprovider', ''), " +
+		"COALESCE(to_jsonb(db)->>'daticulocale', to_jsonb(db)->>'datlocale', ''), " +
+		"COALESCE(pg_catalog.shobj_description(db.oid, 'pg_database'), ''), " +
+		"db.oid::bigint, " +
+		"ARRAY(SELECT unnest(s.setconfig) FROM pg_db_role_setting AS s WHERE s.setdatabase = db.oid AND s.setrole = 0) " +
 		"FROM pg_database AS db, pg_tablespace AS ts " +
 		"WHERE db.datname=$1 AND db.dattablespace = ts.oid"
 
@@ -163,6 +256,12 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		observed.ConnectionLimit,
 		observed.IsTemplate,
 		observed.Tablespace,
+		&size,
+		&provider,
+		&iculocale,
+		&comment,
+		&oid,
+		pq.Array(&setconfig),
 	)
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
@@ -171,6 +270,59 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDB)
 	}
 
+	// The builtin provider of PostgreSQL 17 is reported, but can't be
+	// requested and so isn't late initialized.
+	provider = localeProviders[provider]
+	if provider == "libc" || provider == "icu" {
+		observed.LocaleProvider = &provider
+	}
+	if iculocale != "" {
+		observed.ICULocale = &iculocale
+	}
+
+	// The comment is not late initialized, so that it is only managed if
+	// it is specified.
+	if cr.Spec.ForProvider.Comment != nil {
+		observed.Comment = &comment
+	}
+
+	// The parameters that audit settings manage are only observed if the
+	// database specifies audit settings, so that they are otherwise left
+	// untouched.
+	c.audit = nil
+	if cr.Spec.ForProvider.Audit != nil {
+		c.audit = postgresql.ObservedAuditConfiguration(setconfig)
+	}
+
+	c.refreshCollation = false
+	if ptr.Deref(cr.Spec.ForProvider.CollationVersionRefresh, false) {
+		if err := c.requireLocales(ctx); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		query := "SELECT COALESCE(datcollversion <> pg_database_collation_actual_version(oid), false) FROM pg_database WHERE datname = $1"
+		if err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{meta.GetExternalName(cr)}}, &c.refreshCollation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectCollVersion)
+		}
+	}
+
+	cr.Status.AtProvider = v1alpha1.DatabaseObservation{
+		Owner:            *observed.Owner,
+		Encoding:         *observed.Encoding,
+		LCCollate:        *observed.LCCollate,
+		LCCType:          *observed.LCCType,
+		LocaleProvider:   provider,
+		ICULocale:        iculocale,
+		Tablespace:       *observed.Tablespace,
+		AllowConnections: *observed.AllowConnections,
+		ConnectionLimit:  *observed.ConnectionLimit,
+		IsTemplate:       *observed.IsTemplate,
+		Comment:          comment,
+		OID:              oid,
+		SizeBytes:        size,
+	}
+	if c.audit != nil {
+		cr.Status.AtProvider.Audit = postgresql.AuditSettingsFromConfiguration(c.audit)
+	}
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
@@ -180,10 +332,71 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// values that weren't supplied before we determine if an update is
 		// required.
 		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
-		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider),
+		ResourceUpToDate:        !c.refreshCollation && upToDate(observed, cr.Spec.ForProvider) && len(auditQueries(cr, c.audit)) == 0,
 	}, nil
 }
 
+// serverVersion returns the version of the server, e.g. 150004 for
+// PostgreSQL 15.4, which is cached per ProviderConfig.
+func (c *external) serverVersion(ctx context.Context) (int, error) {
+	v, err := postgresql.CachedServerVersion(ctx, c.providerConfig, c.db)
+	return v, errors.Wrap(err, errServerVersion)
+}
+
+// requireLocales returns an error unless the server supports locale
+// providers, i.e. is PostgreSQL 15 or later.
+func (c *external) requireLocales(ctx context.Context) error {
+	v, err := c.serverVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if v < versionLocales {
+		return errors.New(errLocaleVersion)
+	}
+	return nil
+}
+
+// versionedOptions returns the options of CREATE DATABASE that depend on the
+// version of the server. Options older servers don't support are omitted if
+// they don't change the database that is created, and return an error if
+// they do.
+func (c *external) versionedOptions(ctx context.Context, p v1alpha1.DatabaseParameters) (string, error) {
+	if p.LocaleProvider == nil && p.ICULocale == nil && p.OID == nil && p.Strategy == nil {
+		return "", nil
+	}
+	v, err := c.serverVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if p.LocaleProvider != nil || p.ICULocale != nil {
+		if v < versionLocales {
+			return "", errors.New(errLocaleVersion)
+		}
+	}
+	if p.LocaleProvider != nil {
+		b.WriteString(" LOCALE_PROVIDER ")
+		b.WriteString(pq.QuoteLiteral(*p.LocaleProvider))
+	}
+	if p.ICULocale != nil {
+		b.WriteString(" ICU_LOCALE ")
+		b.WriteString(pq.QuoteLiteral(*p.ICULocale))
+	}
+	if p.OID != nil {
+		if v < versionOID {
+			return "", errors.New(errOIDVersion)
+		}
+		b.WriteString(fmt.Sprintf(" OID %d", *p.OID))
+	}
+	// Older servers always copy the files of the template.
+	if p.Strategy != nil && v >= versionStrategy {
+		b.WriteString(" STRATEGY ")
+		b.WriteString(*p.Strategy)
+	}
+	return b.String(), nil
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) { //nolint:gocyclo
 	// NOTE(negz): This is only a tiny bit over our cyclomatic complexity limit,
 	// and more readable than if we refactored it to avoid the linter error.
@@ -193,6 +406,10 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotDatabase)
 	}
 
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), postgresql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
 	var b strings.Builder
 	b.WriteString("CREATE DATABASE ")
 	b.WriteString(pq.QuoteIdentifier(meta.GetExternalName(cr)))
@@ -217,6 +434,11 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		b.WriteString(" LC_CTYPE ")
 		b.WriteString(quoteIfLiteral(*cr.Spec.ForProvider.LCCType))
 	}
+	opts, err := c.versionedOptions(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	b.WriteString(opts)
 	if cr.Spec.ForProvider.Tablespace != nil {
 		b.WriteString(" TABLESPACE ")
 		b.WriteString(quoteIfIdentifier(*cr.Spec.ForProvider.Tablespace))
@@ -231,7 +453,77 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		b.WriteString(fmt.Sprintf(" IS_TEMPLATE %t", *cr.Spec.ForProvider.IsTemplate))
 	}
 
-	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: b.String()}), errCreateDB)
+	create := xsql.Query{String: b.String()}
+	if t := cr.Spec.ForProvider.Template; t != nil && *t != "DEFAULT" && ptr.Deref(cr.Spec.ForProvider.TerminateTemplateConnections, false) {
+		err = errors.Wrap(c.execTerminating(ctx, create, *t), errCreateDB)
+	} else {
+		err = errors.Wrap(c.db.Exec(ctx, create), errCreateDB)
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if err := c.setComment(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, c.setAudit(ctx, auditQueries(cr, nil))
+}
+
+// setComment sets the comment of the supplied database, if it specifies
+// one.
+func (c *external) setComment(ctx context.Context, cr *v1alpha1.Database) error {
+	if cr.Spec.ForProvider.Comment == nil {
+		return nil
+	}
+	literal := "NULL"
+	if *cr.Spec.ForProvider.Comment != "" {
+		literal = pq.QuoteLiteral(*cr.Spec.ForProvider.Comment)
+	}
+	query := xsql.Query{String: fmt.Sprintf("COMMENT ON DATABASE %s IS %s",
+		pq.QuoteIdentifier(meta.GetExternalName(cr)), literal)}
+	return errors.Wrap(c.db.Exec(ctx, query), errCommentDB)
+}
+
+// setAudit executes the supplied statements that change the audit settings
+// of a database, if any.
+func (c *external) setAudit(ctx context.Context, ql []xsql.Query) error {
+	if len(ql) == 0 {
+		return nil
+	}
+	return errors.Wrap(c.db.ExecTx(ctx, ql), errSetDBAudit)
+}
+
+// auditQueries returns the statements that change the supplied observed
+// configuration of the parameters that the audit settings of the database
+// manage to the desired one. They are left untouched if it specifies no
+// audit settings.
+func auditQueries(cr *v1alpha1.Database, observed map[string]string) []xsql.Query {
+	if cr.Spec.ForProvider.Audit == nil {
+		return nil
+	}
+	object := "DATABASE " + pq.QuoteIdentifier(meta.GetExternalName(cr))
+	return postgresql.AuditQueries(object, postgresql.AuditConfiguration(cr.Spec.ForProvider.Audit), observed)
+}
+
+// execTerminating terminates the connections to the supplied database before
+// each attempt to execute the supplied statement, e.g. to create a database
+// from it or drop it, retrying while the database is still in use because
+// clients reconnected in the meantime.
+func (c *external) execTerminating(ctx context.Context, q xsql.Query, database string) error {
+	terminate := xsql.Query{
+		String:     "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+		Parameters: []interface{}{database},
+	}
+
+	var err error
+	for i := 0; i < maxTerminateAttempts; i++ {
+		if err := c.db.Exec(ctx, terminate); err != nil {
+			return errors.Wrap(err, errTerminateConns)
+		}
+		if err = c.db.Exec(ctx, q); !postgresql.IsObjectInUse(err) {
+			break
+		}
+	}
+	return err
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) { //nolint:gocyclo
@@ -243,6 +535,10 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotDatabase)
 	}
 
+	if err := c.reassignOwned(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	if cr.Spec.ForProvider.Owner != nil {
 		query := xsql.Query{String: fmt.Sprintf("ALTER DATABASE %s OWNER TO %s",
 			pq.QuoteIdentifier(meta.GetExternalName(cr)),
@@ -279,7 +575,36 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
-	return managed.ExternalUpdate{}, nil
+	if c.refreshCollation {
+		query := xsql.Query{String: fmt.Sprintf("ALTER DATABASE %s REFRESH COLLATION VERSION",
+			pq.QuoteIdentifier(meta.GetExternalName(cr)))}
+		if err := c.db.Exec(ctx, query); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errRefreshCollVer)
+		}
+	}
+
+	if comment := cr.Spec.ForProvider.Comment; comment != nil && *comment != cr.Status.AtProvider.Comment {
+		if err := c.setComment(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	return managed.ExternalUpdate{}, c.setAudit(ctx, auditQueries(cr, c.audit))
+}
+
+// reassignOwned transfers the objects inside the database from the
+// previously observed owner to the desired owner, if requested.
+func (c *external) reassignOwned(ctx context.Context, cr *v1alpha1.Database) error {
+	previous := cr.Status.AtProvider.Owner
+	if !ptr.Deref(cr.Spec.ForProvider.ReassignOwnedObjects, false) || cr.Spec.ForProvider.Owner == nil ||
+		previous == "" || previous == *cr.Spec.ForProvider.Owner {
+		return nil
+	}
+
+	query := xsql.Query{String: fmt.Sprintf("REASSIGN OWNED BY %s TO %s",
+		pq.QuoteIdentifier(previous),
+		pq.QuoteIdentifier(*cr.Spec.ForProvider.Owner))}
+	return errors.Wrap(c.dbIn(meta.GetExternalName(cr)).Exec(ctx, query), errReassignOwned)
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -288,13 +613,30 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotDatabase)
 	}
 
-	err := c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr))})
-	return errors.Wrap(err, errDropDB)
+	drop := xsql.Query{String: "DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr))}
+	if !ptr.Deref(cr.Spec.ForProvider.ForceDrop, false) {
+		return errors.Wrap(c.db.Exec(ctx, drop), errDropDB)
+	}
+
+	v, err := c.serverVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if v < versionDropForce {
+		return errors.Wrap(c.execTerminating(ctx, drop, meta.GetExternalName(cr)), errDropDB)
+	}
+	drop.String += " WITH (FORCE)"
+	return errors.Wrap(c.db.Exec(ctx, drop), errDropDB)
 }
 
 func upToDate(observed, desired v1alpha1.DatabaseParameters) bool {
-	// Template is only used at create time.
-	return cmp.Equal(desired, observed, cmpopts.IgnoreFields(v1alpha1.DatabaseParameters{}, "Template"))
+	// Template, TerminateTemplateConnections, Strategy and OID are only used
+	// at create time, ReassignOwnedObjects only when the owner changes, and
+	// ForceDrop at delete time. CollationVersionRefresh is compared to the
+	// collation version that the server reports, and Audit to the audit
+	// configuration of the database, by Observe.
+	return cmp.Equal(desired, observed, cmpopts.IgnoreFields(v1alpha1.DatabaseParameters{},
+		"Template", "TerminateTemplateConnections", "Strategy", "OID", "ReassignOwnedObjects", "ForceDrop", "CollationVersionRefresh", "Audit"))
 }
 
 func lateInit(observed v1alpha1.DatabaseParameters, desired *v1alpha1.DatabaseParameters) bool {
@@ -332,6 +674,14 @@ func lateInit(observed v1alpha1.DatabaseParameters, desired *v1alpha1.DatabasePa
 		desired.Tablespace = observed.Tablespace
 		li = true
 	}
+	if desired.LocaleProvider == nil && observed.LocaleProvider != nil {
+		desired.LocaleProvider = observed.LocaleProvider
+		li = true
+	}
+	if desired.ICULocale == nil && observed.ICULocale != nil {
+		desired.ICULocale = observed.ICULocale
+		li = true
+	}
 
 	return li
 }
@@ -19,19 +19,25 @@ package database
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -64,7 +70,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+		newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -189,8 +195,9 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o          managed.ExternalObservation
+		atProvider v1alpha1.DatabaseObservation
+		err        error
 	}
 
 	cases := map[string]struct {
@@ -255,6 +262,85 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessAtProvider": {
+			reason: "The observed state of the database should be reported in its status",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "owner"
+						*dest[1].(*string) = "UTF8"
+						*dest[8].(*int64) = 8192
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+				atProvider: v1alpha1.DatabaseObservation{Owner: "owner", Encoding: "UTF8", SizeBytes: 8192},
+			},
+		},
+		"SuccessICULocale": {
+			reason: "The locale provider and ICU locale of the database should be observed",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[9].(*string) = "i"
+						*dest[10].(*string) = "en-US"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							LocaleProvider: ptr.To("icu"),
+							ICULocale:      ptr.To("en-US"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+				atProvider: v1alpha1.DatabaseObservation{LocaleProvider: "icu", ICULocale: "en-US"},
+			},
+		},
+		"ErrCollationVersionRefreshUnsupported": {
+			reason: "An error should be returned if collation versions are refreshed on PostgreSQL 14 or earlier",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "server_version_num") {
+							*dest[0].(*int) = 140009
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							CollationVersionRefresh: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errLocaleVersion),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -267,6 +353,11 @@ func TestObserve(t *testing.T) {
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Database); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
 		})
 	}
 }
@@ -345,6 +436,248 @@ func TestCreate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrTerminateConnections": {
+			reason: "Any errors encountered while terminating connections to the template should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Template:                     ptr.To("golden"),
+							TerminateTemplateConnections: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrap(errBoom, errTerminateConns), errCreateDB),
+			},
+		},
+		"SuccessAfterTemplateInUse": {
+			reason: "Creation should be retried after terminating connections while the template is in use",
+			fields: fields{
+				db: func() xsql.DB {
+					creates := 0
+					return &mockDB{
+						MockExec: func(ctx context.Context, q xsql.Query) error {
+							switch q.String {
+							case "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()":
+								return nil
+							case `CREATE DATABASE "example" TEMPLATE "golden"`:
+								if creates++; creates < 2 {
+									return &pq.Error{Code: "55006"}
+								}
+								return nil
+							}
+							return errors.Errorf("unexpected query: %s", q.String)
+						},
+					}
+				}(),
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Template:                     ptr.To("golden"),
+							TerminateTemplateConnections: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrInvalidName": {
+			reason: "An error should be returned if the name of the database is longer than PostgreSQL allows",
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: strings.Repeat("a", 64)},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(xsql.ValidateIdentifier(strings.Repeat("a", 64), postgresql.IdentifierLimit), errInvalidName),
+			},
+		},
+		"SuccessICULocale": {
+			reason: "The locale provider and ICU locale should be passed to CREATE DATABASE on PostgreSQL 15 or later",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 150004
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `CREATE DATABASE "example" LOCALE_PROVIDER 'icu' ICU_LOCALE 'en-US'`; q.String != want {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							LocaleProvider: ptr.To("icu"),
+							ICULocale:      ptr.To("en-US"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrLocaleProviderUnsupported": {
+			reason: "An error should be returned if a locale provider is requested on PostgreSQL 14 or earlier",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 140009
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							LocaleProvider: ptr.To("icu"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errLocaleVersion),
+			},
+		},
+		"SuccessOIDAndStrategy": {
+			reason: "The OID and strategy should be passed to CREATE DATABASE on PostgreSQL 15 or later",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 150004
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `CREATE DATABASE "example" OID 20000 STRATEGY FILE_COPY`; q.String != want {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							OID:      ptr.To[int64](20000),
+							Strategy: ptr.To("FILE_COPY"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"StrategyIgnored": {
+			reason: "The strategy should be omitted on PostgreSQL 14 or earlier, which always copies files",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 120017
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `CREATE DATABASE "example"`; q.String != want {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Strategy: ptr.To("WAL_LOG"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrOIDUnsupported": {
+			reason: "An error should be returned if an OID is requested on PostgreSQL 14 or earlier",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 140009
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							OID: ptr.To[int64](20000),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errOIDVersion),
+			},
+		},
+		"ErrTemplateStillInUse": {
+			reason: "An error should be returned if the template is still in use after all attempts",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "CREATE DATABASE") {
+							return &pq.Error{Code: "55006"}
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Template:                     ptr.To("golden"),
+							TerminateTemplateConnections: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(&pq.Error{Code: "55006"}, errCreateDB),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -365,7 +698,8 @@ func TestUpdate(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db   xsql.DB
+		dbIn func(database string) xsql.DB
 	}
 
 	type args struct {
@@ -504,11 +838,105 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessComment": {
+			reason: "A comment that differs from the observed one should be set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `COMMENT ON DATABASE "example" IS 'billing'`; q.String != want {
+							return errors.Errorf("unexpected query %q", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Comment: ptr.To("billing"),
+						},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{Comment: "old"},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrReassignOwned": {
+			reason: "Errors reassigning objects owned by the previous owner should be returned",
+			fields: fields{
+				dbIn: func(database string) xsql.DB {
+					return &mockDB{
+						MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+					}
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Owner:                ptr.To("new"),
+							ReassignOwnedObjects: ptr.To(true),
+						},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{Owner: "old"},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errReassignOwned),
+			},
+		},
+		"SuccessReassignOwned": {
+			reason: "Objects owned by the previous owner should be reassigned in the database before the owner is altered",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+				dbIn: func(database string) xsql.DB {
+					return &mockDB{
+						MockExec: func(ctx context.Context, q xsql.Query) error {
+							if want := `REASSIGN OWNED BY "old" TO "new"`; database != "example" || q.String != want {
+								return errors.Errorf("unexpected query %q in database %q", q.String, database)
+							}
+							return nil
+						},
+					}
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{
+							Owner:                ptr.To("new"),
+							ReassignOwnedObjects: ptr.To(true),
+						},
+					},
+					Status: v1alpha1.DatabaseStatus{
+						AtProvider: v1alpha1.DatabaseObservation{Owner: "old"},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, dbIn: tc.fields.dbIn}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -559,6 +987,59 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errDropDB),
 		},
+		"SuccessForceDrop": {
+			reason: "Connections should be terminated by DROP DATABASE WITH (FORCE) on PostgreSQL 13 or later",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 130014
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `DROP DATABASE IF EXISTS "example" WITH (FORCE)`; q.String != want {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+					},
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ForceDrop: ptr.To(true)},
+					},
+				},
+			},
+			want: nil,
+		},
+		"ErrForceDropTerminate": {
+			reason: "Connections should be terminated before the database is dropped on PostgreSQL 12 or earlier",
+			fields: fields{
+				db: &mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*int) = 120017
+						return nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.Contains(q.String, "pg_terminate_backend") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						ForProvider: v1alpha1.DatabaseParameters{ForceDrop: ptr.To(true)},
+					},
+				},
+			},
+			want: errors.Wrap(errors.Wrap(errBoom, errTerminateConns), errDropDB),
+		},
 	}
 
 	for name, tc := range cases {
@@ -571,3 +1052,117 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshCollationVersion(t *testing.T) {
+	var queries []string
+	db := mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			switch {
+			case strings.Contains(q.String, "server_version_num"):
+				*dest[0].(*int) = 150004
+			case strings.Contains(q.String, "pg_database_collation_actual_version"):
+				*dest[0].(*bool) = true
+			}
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+		},
+		Spec: v1alpha1.DatabaseSpec{
+			ForProvider: v1alpha1.DatabaseParameters{
+				CollationVersionRefresh: ptr.To(true),
+			},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a database with an outdated collation version not to be up to date")
+	}
+
+	// Late initialization filled in every other parameter, so only the
+	// collation version is refreshed.
+	cr.Spec.ForProvider.Owner = nil
+	cr.Spec.ForProvider.ConnectionLimit = nil
+	cr.Spec.ForProvider.AllowConnections = nil
+	cr.Spec.ForProvider.IsTemplate = nil
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{`ALTER DATABASE "example" REFRESH COLLATION VERSION`}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	var queries []string
+	db := mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[13].(*pq.StringArray) = pq.StringArray{"work_mem=64MB", "pgaudit.log=all", "pgaudit.log_relation=off"}
+			return nil
+		},
+		MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+			for _, q := range ql {
+				queries = append(queries, q.String)
+			}
+			return nil
+		},
+	}
+	cr := &v1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+		},
+		Spec: v1alpha1.DatabaseSpec{
+			ForProvider: v1alpha1.DatabaseParameters{
+				Audit: &v1alpha1.AuditSettings{
+					Log:          []v1alpha1.AuditLogClass{"ALL"},
+					LogParameter: ptr.To(true),
+				},
+			},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a database with different audit settings not to be up to date")
+	}
+	observed := &v1alpha1.AuditSettings{
+		Log:         []v1alpha1.AuditLogClass{"ALL"},
+		LogRelation: ptr.To(false),
+	}
+	if diff := cmp.Diff(observed, cr.Status.AtProvider.Audit); diff != "" {
+		t.Errorf("e.Observe(...): -want status.atProvider.audit, +got:\n%s", diff)
+	}
+
+	// Late initialization filled in every other parameter, so only the
+	// audit settings are changed.
+	cr.Spec.ForProvider.Owner = nil
+	cr.Spec.ForProvider.ConnectionLimit = nil
+	cr.Spec.ForProvider.AllowConnections = nil
+	cr.Spec.ForProvider.IsTemplate = nil
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{
+		`ALTER DATABASE "example" SET pgaudit.log_parameter = 'on'`,
+		`ALTER DATABASE "example" RESET pgaudit.log_relation`,
+	}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+}
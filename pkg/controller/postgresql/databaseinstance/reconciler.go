@@ -0,0 +1,440 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaseinstance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotInstance       = "managed resource is not a DatabaseInstance custom resource"
+	errInvalidName       = "invalid database name"
+	errInvalidOwner      = "invalid owner name"
+	errSelectInstance    = "cannot select database instance"
+	errSelectSchemas     = "cannot select database instance schemas"
+	errGetPasswordSecret = "cannot get password secret"
+	errCreateOwner       = "cannot create owner role"
+	errAlterOwner        = "cannot alter owner role"
+	errCreateDB          = "cannot create database"
+	errAlterDBOwner      = "cannot alter database owner"
+	errBootstrap         = "cannot bootstrap database"
+	errDropDB            = "cannot drop database"
+	errDropOwner         = "cannot drop owner role"
+
+	// noRole is the connection limit observed for an owner that does not
+	// exist.
+	noRole = -2
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles DatabaseInstance managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseInstanceGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseInstanceGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.DatabaseInstance{}, &v1alpha1.DatabaseInstanceList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.DatabaseInstance)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.DatabaseInstance{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseInstanceGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseInstanceGroupKind))
+}
+
+// NewConnecter returns a connecter for DatabaseInstance managed resources. It
+// gets ProviderConfigs and credentials using the supplied client, and tracks
+// the usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseInstance)
+	if !ok {
+		return nil, errors.New(errNotInstance)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	return &external{
+		db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseInstanceKind, cr),
+		dbIn: func(database string) xsql.DB {
+			return xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseInstanceKind, cr)
+		},
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+
+	// dbIn returns a client connected to the supplied database, in which
+	// its schemas are bootstrapped.
+	dbIn func(database string) xsql.DB
+
+	// ownerExists is false if the last observation found the database but
+	// not its owner, which the next update creates.
+	ownerExists bool
+}
+
+// owner returns the name of the role that owns the supplied instance.
+func owner(cr *v1alpha1.DatabaseInstance) string {
+	return ptr.Deref(cr.Spec.ForProvider.Owner, meta.GetExternalName(cr))
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseInstance)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotInstance)
+	}
+
+	var dbOwner string
+	var connLimit int32
+	var publicDB bool
+	query := "SELECT pg_get_userbyid(d.datdba), " +
+		"COALESCE((SELECT rolconnlimit FROM pg_roles WHERE rolname = $2), $3), " +
+		"has_database_privilege('public', d.oid, 'CONNECT,TEMPORARY') " +
+		"FROM pg_database AS d WHERE d.datname = $1"
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr), owner(cr), noRole},
+	}, &dbOwner, &connLimit, &publicDB)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectInstance)
+	}
+	c.ownerExists = connLimit != noRole
+
+	var schemas []string
+	var publicSchema bool
+	err = c.dbIn(meta.GetExternalName(cr)).Scan(ctx, xsql.Query{
+		String: "SELECT ARRAY(SELECT nspname FROM pg_namespace WHERE nspname = ANY($1) AND pg_get_userbyid(nspowner) = $2 ORDER BY nspname), " +
+			"EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'public' AND has_schema_privilege('public', oid, 'CREATE'))",
+		Parameters: []interface{}{pq.Array(cr.Spec.ForProvider.Schemas), owner(cr)},
+	}, pq.Array(&schemas), &publicSchema)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSchemas)
+	}
+
+	cr.Status.AtProvider = v1alpha1.DatabaseInstanceObservation{
+		Owner:         dbOwner,
+		Schemas:       schemas,
+		PublicRevoked: !publicDB && !publicSchema,
+	}
+	cr.SetConditions(xpv1.Available())
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	p := cr.Spec.ForProvider
+	wantSchemas := slices.Clone(p.Schemas)
+	slices.Sort(wantSchemas)
+	upToDate := c.ownerExists && !pwdChanged &&
+		dbOwner == owner(cr) &&
+		(p.ConnectionLimit == nil || *p.ConnectionLimit == connLimit) &&
+		len(schemas) == len(slices.Compact(wantSchemas)) &&
+		(!ptr.Deref(p.RevokePublic, true) || cr.Status.AtProvider.PublicRevoked)
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseInstance)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotInstance)
+	}
+
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), postgresql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+	if err := xsql.ValidateIdentifier(owner(cr), postgresql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidOwner)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	pw, err := c.createOwner(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE DATABASE %s OWNER %s", pq.QuoteIdentifier(meta.GetExternalName(cr)), pq.QuoteIdentifier(owner(cr)))
+	if cr.Spec.ForProvider.Encoding != nil {
+		fmt.Fprintf(&b, " ENCODING %s", pq.QuoteLiteral(*cr.Spec.ForProvider.Encoding))
+	}
+	if err := c.db.Exec(ctx, xsql.Query{String: b.String()}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateDB)
+	}
+
+	if err := c.bootstrap(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{ConnectionDetails: c.connectionDetails(cr, pw)}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DatabaseInstance)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotInstance)
+	}
+
+	ro := pq.QuoteIdentifier(owner(cr))
+	var details managed.ConnectionDetails
+	if !c.ownerExists {
+		pw, err := c.createOwner(ctx, cr)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		details = c.connectionDetails(cr, pw)
+	} else {
+		pw, pwdChanged, err := c.getPassword(ctx, cr)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		if pwdChanged {
+			if err := c.db.Exec(ctx, xsql.Query{String: fmt.Sprintf("ALTER ROLE %s PASSWORD %s", ro, pq.QuoteLiteral(pw))}); err != nil {
+				return managed.ExternalUpdate{}, errors.Wrap(err, errAlterOwner)
+			}
+			details = c.connectionDetails(cr, pw)
+		}
+		if cl := cr.Spec.ForProvider.ConnectionLimit; cl != nil {
+			if err := c.db.Exec(ctx, xsql.Query{String: fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d", ro, *cl)}); err != nil {
+				return managed.ExternalUpdate{}, errors.Wrap(err, errAlterOwner)
+			}
+		}
+	}
+
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(meta.GetExternalName(cr)), ro),
+	}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterDBOwner)
+	}
+
+	if err := c.bootstrap(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	return managed.ExternalUpdate{ConnectionDetails: details}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DatabaseInstance)
+	if !ok {
+		return errors.New(errNotInstance)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+	if err := c.db.Exec(ctx, xsql.Query{String: "DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr))}); err != nil {
+		return errors.Wrap(err, errDropDB)
+	}
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP ROLE IF EXISTS " + pq.QuoteIdentifier(owner(cr))})
+	return errors.Wrap(err, errDropOwner)
+}
+
+// createOwner creates the login role that owns the supplied instance, and
+// returns its password.
+func (c *external) createOwner(ctx context.Context, cr *v1alpha1.DatabaseInstance) (string, error) {
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return "", err
+	}
+	if pw == "" {
+		if pw, err = password.Generate(); err != nil {
+			return "", err
+		}
+	}
+
+	q := fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD %s", pq.QuoteIdentifier(owner(cr)), pq.QuoteLiteral(pw))
+	if cl := cr.Spec.ForProvider.ConnectionLimit; cl != nil {
+		q += fmt.Sprintf(" CONNECTION LIMIT %d", *cl)
+	}
+	if err := c.db.Exec(ctx, xsql.Query{String: q}); err != nil {
+		return "", errors.Wrap(err, errCreateOwner)
+	}
+	return pw, nil
+}
+
+// bootstrap revokes the privileges of PUBLIC on the supplied instance and
+// creates its schemas, in the database of the instance. It is safe to run
+// repeatedly.
+func (c *external) bootstrap(ctx context.Context, cr *v1alpha1.DatabaseInstance) error {
+	db := meta.GetExternalName(cr)
+	ro := pq.QuoteIdentifier(owner(cr))
+
+	var ql []xsql.Query
+	if ptr.Deref(cr.Spec.ForProvider.RevokePublic, true) {
+		ql = append(ql,
+			xsql.Query{String: fmt.Sprintf("REVOKE ALL ON DATABASE %s FROM PUBLIC", pq.QuoteIdentifier(db))},
+			xsql.Query{String: "REVOKE CREATE ON SCHEMA public FROM PUBLIC"},
+		)
+	}
+	for _, s := range cr.Spec.ForProvider.Schemas {
+		sn := pq.QuoteIdentifier(s)
+		ql = append(ql,
+			xsql.Query{String: fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s AUTHORIZATION %s", sn, ro)},
+			xsql.Query{String: fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", sn, ro)},
+		)
+	}
+	if len(ql) == 0 {
+		return nil
+	}
+	return errors.Wrap(c.dbIn(db).ExecTx(ctx, ql), errBootstrap)
+}
+
+// connectionDetails returns the connection details of the owner of the
+// supplied instance, including its database.
+func (c *external) connectionDetails(cr *v1alpha1.DatabaseInstance, pw string) managed.ConnectionDetails {
+	cd := c.db.GetConnectionDetails(owner(cr), pw)
+	cd[xsql.DatabaseKey] = []byte(meta.GetExternalName(cr))
+	return cd
+}
+
+// getPassword returns the password in the PasswordSecretRef of the supplied
+// instance, and whether it differs from the one in its connection secret.
+func (c *external) getPassword(ctx context.Context, cr *v1alpha1.DatabaseInstance) (string, bool, error) {
+	ref := cr.Spec.ForProvider.PasswordSecretRef
+	if ref == nil {
+		return "", false, nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", false, errors.Wrap(err, errGetPasswordSecret)
+	}
+	pw := string(s.Data[ref.Key])
+
+	out := cr.Spec.WriteConnectionSecretToReference
+	if out == nil {
+		return pw, false, nil
+	}
+	cs := &corev1.Secret{}
+	// The connection secret does not exist until the instance is created.
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: out.Namespace, Name: out.Name}, cs); resource.IgnoreNotFound(err) != nil {
+		return "", false, err
+	}
+	return pw, pw != "" && pw != string(cs.Data[xpv1.ResourceCredentialsSecretPasswordKey]), nil
+}
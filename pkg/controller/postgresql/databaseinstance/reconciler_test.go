@@ -0,0 +1,700 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package databaseinstance
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func instance(fp v1alpha1.DatabaseInstanceParameters) *v1alpha1.DatabaseInstance {
+	cr := &v1alpha1.DatabaseInstance{Spec: v1alpha1.DatabaseInstanceSpec{ForProvider: fp}}
+	meta.SetExternalName(cr, "app")
+	return cr
+}
+
+func details(username, password string) managed.ConnectionDetails {
+	return managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotInstance": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseInstance",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotInstance),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.DatabaseInstance{},
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrMissingConnectionSecret": {
+			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.DatabaseInstance{
+					Spec: v1alpha1.DatabaseInstanceSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.New(errNoSecretRef),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// observed returns a database client that reports the supplied owner,
+	// connection limit of the owner and whether PUBLIC may connect.
+	observed := func(owner string, connLimit int32, public bool) xsql.DB {
+		return mockDB{
+			MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				*dest[0].(*string) = owner
+				*dest[1].(*int32) = connLimit
+				*dest[2].(*bool) = public
+				return nil
+			},
+		}
+	}
+	// schemas returns a database client that reports the supplied schemas
+	// as owned by the owner.
+	schemas := func(s ...string) xsql.DB {
+		return mockDB{
+			MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				*dest[0].(*pq.StringArray) = s
+				return nil
+			},
+		}
+	}
+
+	type fields struct {
+		db   xsql.DB
+		dbIn xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o           managed.ExternalObservation
+		atProvider  v1alpha1.DatabaseInstanceObservation
+		ownerExists bool
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotInstance": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseInstance",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotInstance),
+			},
+		},
+		"NoDatabase": {
+			reason: "We should return ResourceExists: false when no database is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectInstance": {
+			reason: "We should return any errors encountered while trying to select the database",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectInstance),
+			},
+		},
+		"ErrSelectSchemas": {
+			reason: "We should return any errors encountered while trying to select the schemas of the database",
+			fields: fields{
+				db: observed("app", -1, false),
+				dbIn: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: want{
+				err:         errors.Wrap(errBoom, errSelectSchemas),
+				ownerExists: true,
+			},
+		},
+		"UpToDate": {
+			reason: "An instance whose owner exists and owns the database and its schemas should be up to date",
+			fields: fields{
+				db:   observed("app", 10, false),
+				dbIn: schemas("app"),
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{
+					ConnectionLimit: ptr.To[int32](10),
+					Schemas:         []string{"app"},
+				}),
+			},
+			want: want{
+				o:           managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider:  v1alpha1.DatabaseInstanceObservation{Owner: "app", Schemas: []string{"app"}, PublicRevoked: true},
+				ownerExists: true,
+			},
+		},
+		"OwnerMissing": {
+			reason: "An instance whose owner does not exist should not be up to date",
+			fields: fields{
+				db:   observed("postgres", noRole, false),
+				dbIn: schemas(),
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider: v1alpha1.DatabaseInstanceObservation{Owner: "postgres", PublicRevoked: true},
+			},
+		},
+		"SchemaMissing": {
+			reason: "An instance missing one of its schemas should not be up to date",
+			fields: fields{
+				db:   observed("app", -1, false),
+				dbIn: schemas("app"),
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{Schemas: []string{"app", "reporting"}}),
+			},
+			want: want{
+				o:           managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider:  v1alpha1.DatabaseInstanceObservation{Owner: "app", Schemas: []string{"app"}, PublicRevoked: true},
+				ownerExists: true,
+			},
+		},
+		"PublicNotRevoked": {
+			reason: "An instance whose database PUBLIC may connect to should not be up to date",
+			fields: fields{
+				db:   observed("app", -1, true),
+				dbIn: schemas(),
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: want{
+				o:           managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				atProvider:  v1alpha1.DatabaseInstanceObservation{Owner: "app"},
+				ownerExists: true,
+			},
+		},
+		"PublicKept": {
+			reason: "An instance that keeps the privileges of PUBLIC should be up to date regardless of them",
+			fields: fields{
+				db:   observed("app", -1, true),
+				dbIn: schemas(),
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{RevokePublic: ptr.To(false)}),
+			},
+			want: want{
+				o:           managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				atProvider:  v1alpha1.DatabaseInstanceObservation{Owner: "app"},
+				ownerExists: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, dbIn: func(string) xsql.DB { return tc.fields.dbIn }}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ownerExists, e.ownerExists); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want ownerExists, +got ownerExists:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.DatabaseInstance); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want atProvider, +got atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube client.Client
+		db   mockDB
+		dbIn mockDB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c       managed.ExternalCreation
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotInstance": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseInstance",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotInstance),
+			},
+		},
+		"ErrInvalidOwner": {
+			reason: "An error should be returned if the owner name is not a valid identifier",
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{Owner: ptr.To(strings.Repeat("a", 64))}),
+			},
+			want: want{
+				err: errors.Wrap(xsql.ValidateIdentifier(strings.Repeat("a", 64), postgresql.IdentifierLimit), errInvalidOwner),
+			},
+		},
+		"ErrCreateOwner": {
+			reason: "Any errors encountered while creating the owner should be returned",
+			fields: fields{
+				db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom }},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{
+					PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+				}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateOwner),
+			},
+		},
+		"ErrBootstrap": {
+			reason: "Any errors encountered while bootstrapping the database should be returned",
+			fields: fields{
+				db:   mockDB{MockExec: func(ctx context.Context, q xsql.Query) error { return nil }},
+				dbIn: mockDB{MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom }},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{
+					PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+				}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errBootstrap),
+			},
+		},
+		"Success": {
+			reason: "The owner and database should be created, PUBLIC revoked and schemas created in one transaction",
+			fields: fields{
+				db: mockDB{
+					MockGetConnectionDetails: details,
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{
+					Owner:             ptr.To("app_owner"),
+					PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+					ConnectionLimit:   ptr.To[int32](5),
+					Encoding:          ptr.To("UTF8"),
+					Schemas:           []string{"app"},
+				}),
+			},
+			want: want{
+				c: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("app_owner"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("s3cr3t"),
+						xsql.DatabaseKey:                          []byte("app"),
+					},
+				},
+				queries: []string{
+					`CREATE ROLE "app_owner" LOGIN PASSWORD 's3cr3t' CONNECTION LIMIT 5`,
+					`CREATE DATABASE "app" OWNER "app_owner" ENCODING 'UTF8'`,
+					`REVOKE ALL ON DATABASE "app" FROM PUBLIC`,
+					`REVOKE CREATE ON SCHEMA public FROM PUBLIC`,
+					`CREATE SCHEMA IF NOT EXISTS "app" AUTHORIZATION "app_owner"`,
+					`ALTER SCHEMA "app" OWNER TO "app_owner"`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db, dbIn := tc.fields.db, tc.fields.dbIn
+			if db.MockExec == nil {
+				db.MockExec = func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return nil
+				}
+			}
+			if dbIn.MockExecTx == nil {
+				dbIn.MockExecTx = func(ctx context.Context, ql []xsql.Query) error {
+					for _, q := range ql {
+						queries = append(queries, q.String)
+					}
+					return nil
+				}
+			}
+			kube := tc.fields.kube
+			if kube == nil {
+				kube = &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+						if key.Name == "" {
+							obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("s3cr3t")}
+						}
+						return nil
+					},
+				}
+			}
+			e := external{db: db, dbIn: func(string) xsql.DB { return dbIn }, kube: kube}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.queries != nil {
+				if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+					t.Errorf("\n%s\ne.Create(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db          mockDB
+		ownerExists bool
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotInstance": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseInstance",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotInstance),
+			},
+		},
+		"ErrAlterOwner": {
+			reason: "Any errors encountered while altering the owner should be returned",
+			fields: fields{
+				db:          mockDB{MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom }},
+				ownerExists: true,
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{ConnectionLimit: ptr.To[int32](5)}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAlterOwner),
+			},
+		},
+		"ReassignDatabase": {
+			reason: "The database and its schemas should be reassigned to an existing owner",
+			fields: fields{
+				ownerExists: true,
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{
+					ConnectionLimit: ptr.To[int32](5),
+					RevokePublic:    ptr.To(false),
+					Schemas:         []string{"app"},
+				}),
+			},
+			want: want{
+				queries: []string{
+					`ALTER ROLE "app" CONNECTION LIMIT 5`,
+					`ALTER DATABASE "app" OWNER TO "app"`,
+					`CREATE SCHEMA IF NOT EXISTS "app" AUTHORIZATION "app"`,
+					`ALTER SCHEMA "app" OWNER TO "app"`,
+				},
+			},
+		},
+		"RecreateOwner": {
+			reason: "An owner that was dropped outside of Crossplane should be recreated",
+			fields: fields{
+				db: mockDB{MockGetConnectionDetails: details},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{RevokePublic: ptr.To(false)}),
+			},
+			want: want{
+				queries: []string{
+					`ALTER DATABASE "app" OWNER TO "app"`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db := tc.fields.db
+			if db.MockExec == nil {
+				db.MockExec = func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return nil
+				}
+			}
+			dbIn := mockDB{MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+				for _, q := range ql {
+					queries = append(queries, q.String)
+				}
+				return nil
+			}}
+			e := external{db: db, dbIn: func(string) xsql.DB { return dbIn }, ownerExists: tc.fields.ownerExists}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.queries == nil {
+				return
+			}
+			// A recreated owner has a generated password.
+			if !tc.fields.ownerExists && len(queries) > 0 {
+				queries = queries[1:]
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotInstance": {
+			reason: "An error should be returned if the managed resource is not a *DatabaseInstance",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotInstance),
+		},
+		"ErrDropDB": {
+			reason: "Errors dropping the database should be returned",
+			fields: fields{
+				db: mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: errors.Wrap(errBoom, errDropDB),
+		},
+		"ErrDropOwner": {
+			reason: "Errors dropping the owner should be returned",
+			fields: fields{
+				db: mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == `DROP ROLE IF EXISTS "app"` {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: errors.Wrap(errBoom, errDropOwner),
+		},
+		"Success": {
+			reason: "No error should be returned if the database and its owner were dropped",
+			fields: fields{
+				db: mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: instance(v1alpha1.DatabaseInstanceParameters{}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,12 +19,15 @@ package grant
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -32,6 +35,8 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
@@ -39,18 +44,29 @@ import (
 	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	sqlgen "github.com/crossplane-contrib/provider-sql/pkg/sqlgen/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
 
 	errNotGrant     = "managed resource is not a Grant custom resource"
 	errSelectGrant  = "cannot select grant"
 	errCreateGrant  = "cannot create grant"
 	errRevokeGrant  = "cannot revoke grant"
+	errUpdateGrant  = "cannot update grant"
 	errNoRole       = "role not passed or could not be resolved"
 	errNoDatabase   = "database not passed or could not be resolved"
 	errNoPrivileges = "privileges not passed"
@@ -59,6 +75,28 @@ const (
 	errInvalidParams = "invalid parameters for grant type %s"
 
 	errMemberOfWithDatabaseOrPrivileges = "cannot set privileges or database in the same grant as memberOf"
+	errParametersWithDatabase           = "cannot set database in the same grant as parameters"
+	errNoSchema                         = "schema must be set with tables, sequences or routines"
+	errTablesOrSequences                = "cannot set more than one of tables, sequences and routines in the same grant"
+	errSchemaAndSchemas                 = "cannot set both schema and schemas"
+	errSchemasWithObjects               = "schemas cannot be set with tables, sequences or routines; use schema"
+	errSchemaPrivilege                  = "privilege %s cannot be granted on a schema; only USAGE, CREATE or ALL can"
+	errSchemaCreateWithoutUsage         = "CREATE on a schema requires USAGE, without which the role cannot use the objects it creates; grant both, or use the owner privilegeProfile"
+	errSelectDefaultPrivs               = "cannot select default privileges"
+	errPrivilegeSetAndPrivileges        = "cannot set both privileges and privilegeSet"
+	errUnknownPrivilegeSet              = "privilege set %q is not defined by ProviderConfig %q"
+	errPrivilegeProfileAndPrivileges    = "cannot set privilegeProfile in the same grant as privileges or privilegeSet"
+	errPrivilegeProfileWithoutSchema    = "privilegeProfile can only be set on grants that set schema or schemas"
+	errUnknownPrivilegeProfile          = "unknown privilege profile %q"
+	errDatabasesSelectorWithDatabase    = "cannot set database, databaseRef or databaseSelector in the same grant as databasesSelector"
+	errDatabasesSelectorGrantType       = "databasesSelector can only be set on database grants"
+	errListDatabases                    = "cannot list databases selected by databasesSelector"
+
+	fmtFutureObjectsNotCovered = "%s created later in schema %s will not be granted to %s, because no default privileges grant them; set defaultPrivilegesFor to the role that creates them"
+
+	// maxTxAttempts is how often a grant transaction is attempted when it
+	// is aborted to resolve a deadlock with a concurrent reconcile.
+	maxTxAttempts = 3
 
 	maxConcurrency = 5
 )
@@ -68,26 +106,37 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.GrantGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -106,38 +155,242 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+	privileges, err := privilegeProfile(cr.Spec.ForProvider)
+	if err != nil {
+		return nil, err
+	}
+	if privileges == nil {
+		if privileges, err = privilegeSet(cr.Spec.ForProvider, pc); err != nil {
+			return nil, err
+		}
+	}
+	databases, err := c.selectDatabases(ctx, cr, privileges)
+	if err != nil {
+		return nil, err
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	// Tables and sequences live in a particular database, so connect to it
+	// rather than to the default database.
+	database := pc.Spec.DefaultDatabase
+	if (cr.Spec.ForProvider.Schema != nil || len(cr.Spec.ForProvider.Schemas) > 0) && cr.Spec.ForProvider.Database != nil {
+		database = *cr.Spec.ForProvider.Database
 	}
+
 	return &external{
-		db:   c.newDB(s.Data, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode)),
-		kube: c.kube,
+		db:          xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr),
+		kube:        c.kube,
+		self:        string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+		lockTimeout: pc.Spec.LockTimeout,
+		privileges:  privileges,
+		databases:   databases,
 	}, nil
 }
 
+// selectDatabases returns the sorted names of the Databases selected by the
+// databasesSelector of the supplied Grant, if any, that use the same
+// ProviderConfig and are not being deleted.
+func (c *connector) selectDatabases(ctx context.Context, cr *v1alpha1.Grant, privileges v1alpha1.GrantPrivileges) ([]string, error) {
+	gp := cr.Spec.ForProvider
+	sel := gp.DatabasesSelector
+	if sel == nil {
+		return nil, nil
+	}
+	if gp.Database != nil || gp.DatabaseRef != nil || gp.DatabaseSelector != nil {
+		return nil, errors.New(errDatabasesSelectorWithDatabase)
+	}
+	probe := gp
+	if privileges != nil {
+		probe.Privileges = privileges
+	}
+	if gt, err := identifyGrantType(probe); err == nil && gt != roleDatabase {
+		return nil, errors.New(errDatabasesSelectorGrantType)
+	}
+
+	l := &v1alpha1.DatabaseList{}
+	if err := c.kube.List(ctx, l, client.MatchingLabels(sel.MatchLabels)); err != nil {
+		return nil, errors.Wrap(err, errListDatabases)
+	}
+	names := []string{}
+	for i := range l.Items {
+		db := &l.Items[i]
+		ref := db.GetProviderConfigReference()
+		if meta.WasDeleted(db) || ref == nil || ref.Name != cr.GetProviderConfigReference().Name {
+			continue
+		}
+		if ptr.Deref(sel.MatchControllerRef, false) && !meta.HaveSameController(db, cr) {
+			continue
+		}
+		names = append(names, meta.GetExternalName(db))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// privilegeSet returns the privileges of the privilege set the supplied
+// Grant refers to, or nil if it does not refer to one.
+func privilegeSet(gp v1alpha1.GrantParameters, pc *v1alpha1.ProviderConfig) (v1alpha1.GrantPrivileges, error) {
+	if gp.PrivilegeSet == nil {
+		return nil, nil
+	}
+	if len(gp.Privileges) > 0 {
+		return nil, errors.New(errPrivilegeSetAndPrivileges)
+	}
+	p, ok := pc.Spec.PrivilegeSets[*gp.PrivilegeSet]
+	if !ok {
+		return nil, errors.Errorf(errUnknownPrivilegeSet, *gp.PrivilegeSet, pc.GetName())
+	}
+	return p, nil
+}
+
+// profilePrivileges are the privileges each privilege profile grants on a
+// schema, and on the tables, sequences and routines in it.
+var profilePrivileges = map[v1alpha1.PrivilegeProfile]struct {
+	schema, tables, sequences, routines v1alpha1.GrantPrivileges
+}{
+	v1alpha1.PrivilegeProfileOwner: {
+		schema:    v1alpha1.GrantPrivileges{"CREATE", "USAGE"},
+		tables:    v1alpha1.GrantPrivileges{"ALL"},
+		sequences: v1alpha1.GrantPrivileges{"ALL"},
+		routines:  v1alpha1.GrantPrivileges{"ALL"},
+	},
+	v1alpha1.PrivilegeProfileWriter: {
+		schema:    v1alpha1.GrantPrivileges{"USAGE"},
+		tables:    v1alpha1.GrantPrivileges{"DELETE", "INSERT", "SELECT", "UPDATE"},
+		sequences: v1alpha1.GrantPrivileges{"SELECT", "UPDATE", "USAGE"},
+		routines:  v1alpha1.GrantPrivileges{"EXECUTE"},
+	},
+	v1alpha1.PrivilegeProfileReader: {
+		schema:    v1alpha1.GrantPrivileges{"USAGE"},
+		tables:    v1alpha1.GrantPrivileges{"SELECT"},
+		sequences: v1alpha1.GrantPrivileges{"SELECT"},
+		routines:  v1alpha1.GrantPrivileges{"EXECUTE"},
+	},
+}
+
+// privilegeProfile returns the privileges of the privilege profile of the
+// supplied Grant on its schema, tables, sequences or routines, or nil if it does not
+// use one.
+func privilegeProfile(gp v1alpha1.GrantParameters) (v1alpha1.GrantPrivileges, error) {
+	if gp.PrivilegeProfile == nil {
+		return nil, nil
+	}
+	if len(gp.Privileges) > 0 || gp.PrivilegeSet != nil {
+		return nil, errors.New(errPrivilegeProfileAndPrivileges)
+	}
+	if gp.Schema == nil && len(gp.Schemas) == 0 {
+		return nil, errors.New(errPrivilegeProfileWithoutSchema)
+	}
+	p, ok := profilePrivileges[*gp.PrivilegeProfile]
+	if !ok {
+		return nil, errors.Errorf(errUnknownPrivilegeProfile, *gp.PrivilegeProfile)
+	}
+	switch {
+	case len(gp.Tables) > 0:
+		return p.tables, nil
+	case len(gp.Sequences) > 0:
+		return p.sequences, nil
+	case len(gp.Routines) > 0:
+		return p.routines, nil
+	}
+	return p.schema, nil
+}
+
 type external struct {
 	db   xsql.DB
 	kube client.Client
+
+	// self is the role the provider connects as.
+	self string
+
+	// lockTimeout bounds how long grant transactions wait for locks.
+	lockTimeout *metav1.Duration
+
+	// privileges are those of the privilege set or profile of the Grant,
+	// if any.
+	privileges v1alpha1.GrantPrivileges
+
+	// objectsDrifted is set by Observe if the role holds the privileges of
+	// a table, sequence or routine grant with the wrong grant option, or
+	// along with unmanaged ones that should be revoked.
+	objectsDrifted bool
+
+	// objectsMissing is set by Observe to the tables, sequences or routines
+	// of a grant on which the role lacks its privileges while it holds them
+	// on others, e.g. because a migration recreated them.
+	objectsMissing []string
+
+	// databases are those selected by the databasesSelector of the Grant,
+	// if it has one.
+	databases []string
+}
+
+// parameters returns the parameters of the supplied Grant, with the
+// privileges of its privilege set or profile if it uses one.
+func (c *external) parameters(cr *v1alpha1.Grant) v1alpha1.GrantParameters {
+	gp := cr.Spec.ForProvider
+	if c.privileges != nil {
+		gp.Privileges = c.privileges
+	}
+	return gp
+}
+
+// targets returns the parameters of the grant on each of its schemas, or on
+// each database selected by its databasesSelector, or the supplied parameters
+// if it has neither.
+func (c *external) targets(gp v1alpha1.GrantParameters) []v1alpha1.GrantParameters {
+	if len(gp.Schemas) > 0 {
+		out := make([]v1alpha1.GrantParameters, len(gp.Schemas))
+		for i, name := range gp.Schemas {
+			out[i] = gp
+			out[i].Schema = ptr.To(name)
+			out[i].Schemas = nil
+		}
+		return out
+	}
+	if gp.DatabasesSelector == nil {
+		return []v1alpha1.GrantParameters{gp}
+	}
+	out := make([]v1alpha1.GrantParameters, len(c.databases))
+	for i, name := range c.databases {
+		out[i] = gp
+		out[i].Database = ptr.To(name)
+	}
+	return out
 }
 
 type grantType string
 
 const (
-	roleMember   grantType = "ROLE_MEMBER"
-	roleDatabase grantType = "ROLE_DATABASE"
+	roleMember    grantType = "ROLE_MEMBER"
+	roleDatabase  grantType = "ROLE_DATABASE"
+	roleParameter grantType = "ROLE_PARAMETER"
+	roleSchema    grantType = "ROLE_SCHEMA"
+	roleSchemaObj grantType = "ROLE_SCHEMA_OBJECT"
 )
 
-func identifyGrantType(gp v1alpha1.GrantParameters) (grantType, error) {
+func identifyGrantType(gp v1alpha1.GrantParameters) (grantType, error) { // nolint: gocyclo
 	pc := len(gp.Privileges)
 
 	// If memberOf is specified, this is ROLE_MEMBER
@@ -150,7 +403,52 @@ func identifyGrantType(gp v1alpha1.GrantParameters) (grantType, error) {
 		return roleMember, nil
 	}
 
-	if gp.Database == nil {
+	if len(gp.Parameters) > 0 {
+		if gp.Database != nil {
+			return "", errors.New(errParametersWithDatabase)
+		}
+		if pc < 1 {
+			return "", errors.New(errNoPrivileges)
+		}
+		return roleParameter, nil
+	}
+
+	if len(gp.Schemas) > 0 {
+		if gp.Schema != nil {
+			return "", errors.New(errSchemaAndSchemas)
+		}
+		if objectLists(gp) > 0 {
+			return "", errors.New(errSchemasWithObjects)
+		}
+		if pc < 1 {
+			return "", errors.New(errNoPrivileges)
+		}
+		if err := validateSchemaPrivileges(gp.Privileges); err != nil {
+			return "", err
+		}
+		return roleSchema, nil
+	}
+
+	if gp.Schema != nil || objectLists(gp) > 0 {
+		if gp.Schema == nil {
+			return "", errors.New(errNoSchema)
+		}
+		if objectLists(gp) > 1 {
+			return "", errors.New(errTablesOrSequences)
+		}
+		if pc < 1 {
+			return "", errors.New(errNoPrivileges)
+		}
+		if objectLists(gp) > 0 {
+			return roleSchemaObj, nil
+		}
+		if err := validateSchemaPrivileges(gp.Privileges); err != nil {
+			return "", err
+		}
+		return roleSchema, nil
+	}
+
+	if gp.Database == nil && gp.DatabasesSelector == nil {
 		return "", errors.New(errNoDatabase)
 	}
 
@@ -162,6 +460,38 @@ func identifyGrantType(gp v1alpha1.GrantParameters) (grantType, error) {
 	return roleDatabase, nil
 }
 
+// objectLists returns how many of the tables, sequences and routines of a
+// grant are set.
+func objectLists(gp v1alpha1.GrantParameters) int {
+	n := 0
+	for _, l := range [][]string{gp.Tables, gp.Sequences, gp.Routines} {
+		if len(l) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// validateSchemaPrivileges returns an error if the supplied privileges cannot
+// be granted on a schema, or grant CREATE without USAGE.
+func validateSchemaPrivileges(gp v1alpha1.GrantPrivileges) error {
+	var usage, create bool
+	for _, p := range gp.ExpandSchemaPrivileges() {
+		switch p {
+		case "USAGE":
+			usage = true
+		case "CREATE":
+			create = true
+		default:
+			return errors.Errorf(errSchemaPrivilege, p)
+		}
+	}
+	if create && !usage {
+		return errors.New(errSchemaCreateWithoutUsage)
+	}
+	return nil
+}
+
 func selectGrantQuery(gp v1alpha1.GrantParameters, q *xsql.Query) error {
 	gt, err := identifyGrantType(gp)
 	if err != nil {
@@ -190,43 +520,291 @@ func selectGrantQuery(gp v1alpha1.GrantParameters, q *xsql.Query) error {
 		}
 		return nil
 	case roleDatabase:
-		gro := gp.WithOption != nil && *gp.WithOption == v1alpha1.GrantOptionGrant
-
-		ep := gp.Privileges.ExpandPrivileges()
-		sp := ep.ToStringSlice()
-		// Join grantee. Filter by database name and grantee name.
-		// Finally, perform a permission comparison against expected
-		// permissions.
-		q.String = "SELECT EXISTS(SELECT 1 " +
+		// Select every privilege the role holds on the database, split by
+		// whether it carries the grant option, so that only the difference
+		// to the desired privileges has to be applied. Only privileges
+		// granted by the grantor, if any, count, as it cannot revoke those
+		// granted by others.
+		q.String = "SELECT " +
+			"array_agg(acl.privilege_type) FILTER (WHERE acl.is_grantable), " +
+			"array_agg(acl.privilege_type) FILTER (WHERE NOT acl.is_grantable) " +
 			"FROM pg_database db, " +
 			"aclexplode(datacl) as acl " +
 			"INNER JOIN pg_roles s ON acl.grantee = s.oid " +
-			// Filter by database, role and grantable setting
 			"WHERE db.datname=$1 " +
 			"AND s.rolname=$2 " +
+			"AND ($3::text IS NULL OR pg_get_userbyid(acl.grantor) = $3)"
+
+		q.Parameters = []interface{}{
+			gp.Database,
+			gp.Role,
+			gp.Grantor,
+		}
+		return nil
+	case roleParameter:
+		gro := gp.WithOption != nil && *gp.WithOption == v1alpha1.GrantOptionGrant
+
+		ep := gp.Privileges.ExpandParameterPrivileges()
+		sp := ep.ToStringSlice()
+		// Parameter privileges are recorded in pg_parameter_acl, one row per
+		// parameter. Count the parameters on which the role holds exactly
+		// the expected privileges.
+		q.String = "SELECT COUNT(*) = $4 FROM (SELECT 1 " +
+			"FROM pg_parameter_acl p, " +
+			"aclexplode(p.paracl) as acl " +
+			"INNER JOIN pg_roles s ON acl.grantee = s.oid " +
+			"WHERE p.parname = ANY($1) " +
+			"AND s.rolname=$2 " +
 			"AND acl.is_grantable=$3 " +
-			"GROUP BY db.datname, s.rolname, acl.is_grantable " +
-			// Check privileges match. Convoluted right-hand-side is necessary to
-			// ensure identical sort order of the input permissions.
+			"GROUP BY p.parname " +
 			"HAVING array_agg(acl.privilege_type ORDER BY privilege_type ASC) " +
-			"= (SELECT array(SELECT unnest($4::text[]) as perms ORDER BY perms ASC)))"
+			"= (SELECT array(SELECT unnest($5::text[]) as perms ORDER BY perms ASC))) g"
 
 		q.Parameters = []interface{}{
-			gp.Database,
+			pq.Array(sqlgen.LowerParameters(gp.Parameters)),
 			gp.Role,
 			gro,
+			len(gp.Parameters),
 			pq.Array(sp),
 		}
 		return nil
+	case roleSchema:
+		// Select every privilege the role holds on the schema, split by
+		// whether it carries the grant option, as for database grants.
+		q.String = "SELECT " +
+			"array_agg(acl.privilege_type) FILTER (WHERE acl.is_grantable), " +
+			"array_agg(acl.privilege_type) FILTER (WHERE NOT acl.is_grantable) " +
+			"FROM pg_namespace n, " +
+			"aclexplode(n.nspacl) as acl " +
+			"INNER JOIN pg_roles s ON acl.grantee = s.oid " +
+			"WHERE n.nspname=$1 " +
+			"AND s.rolname=$2 " +
+			"AND ($3::text IS NULL OR pg_get_userbyid(acl.grantor) = $3)"
+
+		q.Parameters = []interface{}{
+			gp.Schema,
+			gp.Role,
+			gp.Grantor,
+		}
+		return nil
+	case roleSchemaObj:
+		o := objectsOf(gp)
+		gro := gp.WithOption != nil && *gp.WithOption == v1alpha1.GrantOptionGrant
+
+		// Count the objects the grant is for, those of them on which the
+		// role lacks any of the expected privileges, and those on which it
+		// holds them but with the wrong grant option or, if unmanaged
+		// privileges are revoked, along with others, and name those that
+		// lack privileges so that they alone can be repaired. Only
+		// privileges granted by the grantor, if any, count.
+		q.String = "SELECT COUNT(*), " +
+			"COUNT(*) FILTER (WHERE NOT COALESCE(a.held, '{}') @> $6::text[]), " +
+			"COUNT(*) FILTER (WHERE COALESCE(a.held, '{}') @> $6::text[] AND (" +
+			"CASE WHEN $5 THEN NOT COALESCE(a.grantable, '{}') @> $6::text[] " +
+			"ELSE COALESCE(a.grantable, '{}') && $6::text[] END " +
+			"OR ($8 AND NOT $6::text[] @> a.held))), " +
+			"array_agg(c." + o.column("name") + ") FILTER (WHERE NOT COALESCE(a.held, '{}') @> $6::text[]) " +
+			"FROM " + o.catalog + " c " +
+			"INNER JOIN pg_namespace n ON c." + o.column("namespace") + " = n.oid " +
+			"LEFT JOIN LATERAL (SELECT array_agg(acl.privilege_type) AS held, " +
+			"array_agg(acl.privilege_type) FILTER (WHERE acl.is_grantable) AS grantable " +
+			"FROM aclexplode(c." + o.column("acl") + ") as acl " +
+			"INNER JOIN pg_roles s ON acl.grantee = s.oid " +
+			"WHERE s.rolname=$4 " +
+			"AND ($7::text IS NULL OR pg_get_userbyid(acl.grantor) = $7)) a ON true " +
+			"WHERE n.nspname=$1 " +
+			"AND c." + o.column("kind") + " = ANY($2) " +
+			"AND ($3::text[] = '{*}' OR c." + o.column("name") + " = ANY($3))"
+
+		q.Parameters = []interface{}{
+			gp.Schema,
+			pq.Array(o.relkinds),
+			pq.Array(o.names),
+			gp.Role,
+			gro,
+			pq.Array(o.privileges),
+			gp.Grantor,
+			ptr.Deref(gp.RevokeUnmanaged, false),
+		}
+		return nil
 	}
 	return errors.New(errUnknownGrant)
 }
 
+// schemaObjects are the tables, sequences or routines of a schema object
+// grant.
+type schemaObjects struct {
+	// kind is TABLE, SEQUENCE, FUNCTION or PROCEDURE.
+	kind string
+
+	// catalog is the system catalog of the objects, pg_class or pg_proc.
+	catalog string
+
+	// relkinds are the relkinds or prokinds of the objects in catalog.
+	relkinds []string
+
+	// defaults are the objects as named by ALTER DEFAULT PRIVILEGES.
+	defaults string
+
+	// defaclobjtype is the pg_default_acl object type of the objects.
+	defaclobjtype string
+
+	// names of the objects, or ["*"] for all objects in the schema.
+	names []string
+
+	// privileges are the expanded privileges of the grant.
+	privileges []string
+}
+
+func objectsOf(gp v1alpha1.GrantParameters) schemaObjects {
+	if len(gp.Sequences) > 0 {
+		ep := gp.Privileges.ExpandSequencePrivileges()
+		return schemaObjects{kind: "SEQUENCE", catalog: "pg_class", relkinds: []string{"S"}, defaults: "SEQUENCES", defaclobjtype: "S", names: gp.Sequences, privileges: sqlgen.Sorted(ep.ToStringSlice())}
+	}
+	if len(gp.Routines) > 0 {
+		// Default privileges on functions also apply to procedures, which
+		// ALTER DEFAULT PRIVILEGES has no name of their own for.
+		ep := gp.Privileges.ExpandRoutinePrivileges()
+		o := schemaObjects{kind: string(v1alpha1.RoutineTypeFunction), catalog: "pg_proc", relkinds: []string{"f", "a", "w"}, defaults: "FUNCTIONS", defaclobjtype: "f", names: gp.Routines, privileges: sqlgen.Sorted(ep.ToStringSlice())}
+		if ptr.Deref(gp.RoutineType, v1alpha1.RoutineTypeFunction) == v1alpha1.RoutineTypeProcedure {
+			o.kind = string(v1alpha1.RoutineTypeProcedure)
+			o.relkinds = []string{"p"}
+		}
+		return o
+	}
+	ep := gp.Privileges.ExpandTablePrivileges()
+	return schemaObjects{kind: "TABLE", catalog: "pg_class", relkinds: []string{"r", "p", "v", "m", "f"}, defaults: "TABLES", defaclobjtype: "r", names: gp.Tables, privileges: sqlgen.Sorted(ep.ToStringSlice())}
+}
+
+// column returns the supplied column of the catalog of the objects, e.g.
+// relname or proname for name. pg_class and pg_proc prefix their columns
+// with rel and pro.
+func (o schemaObjects) column(name string) string {
+	if o.catalog == "pg_proc" {
+		return "pro" + name
+	}
+	return "rel" + name
+}
+
+func (o schemaObjects) all() bool {
+	return len(o.names) == 1 && o.names[0] == "*"
+}
+
+// target returns the objects of a grant as named by GRANT and REVOKE, e.g.
+// ALL TABLES IN SCHEMA "s" or TABLE "s"."a","s"."b".
+func (o schemaObjects) target(schema string) string {
+	sc := pq.QuoteIdentifier(schema)
+	if o.all() {
+		return fmt.Sprintf("ALL %sS IN SCHEMA %s", o.kind, sc)
+	}
+	names := make([]string, len(o.names))
+	for i, n := range sqlgen.Sorted(o.names) {
+		names[i] = sc + "." + pq.QuoteIdentifier(n)
+	}
+	return o.kind + " " + strings.Join(names, ",")
+}
+
+// defaultPrivilegesQuery returns the ALTER DEFAULT PRIVILEGES statement that
+// grants or revokes the privileges of a schema object grant on the objects
+// DefaultPrivilegesFor creates in the schema later.
+func defaultPrivilegesQuery(gp v1alpha1.GrantParameters, grant bool) xsql.Query {
+	o := objectsOf(gp)
+	action := fmt.Sprintf("REVOKE %s ON %s FROM %s", privileges(gp), o.defaults, pq.QuoteIdentifier(*gp.Role))
+	if grant {
+		action = strings.TrimSpace(fmt.Sprintf("GRANT %s ON %s TO %s %s", privileges(gp), o.defaults, pq.QuoteIdentifier(*gp.Role), withOption(gp.WithOption)))
+	}
+	return xsql.Query{String: fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s %s",
+		pq.QuoteIdentifier(*gp.DefaultPrivilegesFor),
+		pq.QuoteIdentifier(*gp.Schema),
+		action,
+	)}
+}
+
+// privileges returns the sorted, comma separated privileges of a grant.
+func privileges(gp v1alpha1.GrantParameters) string {
+	return strings.Join(sqlgen.Sorted(gp.Privileges.ToStringSlice()), ",")
+}
+
 func withOption(option *v1alpha1.GrantOption) string {
-	if option != nil {
-		return fmt.Sprintf("WITH %s OPTION", string(*option))
+	return sqlgen.WithOption(string(ptr.Deref(option, "")))
+}
+
+// databasePrivileges returns the privileges a role holds on a database or
+// schema, as selected by selectGrantQuery, mapped to whether they carry the
+// grant option.
+func (c *external) databasePrivileges(ctx context.Context, q xsql.Query) (map[string]bool, error) {
+	var grantable, plain pq.StringArray
+	if err := c.db.Scan(ctx, q, &grantable, &plain); err != nil {
+		return nil, err
+	}
+	held := make(map[string]bool, len(grantable)+len(plain))
+	for _, p := range plain {
+		held[p] = false
+	}
+	for _, p := range grantable {
+		held[p] = true
+	}
+	return held, nil
+}
+
+// diffDatabasePrivileges returns the changes needed to bring the privileges
+// a role holds on a database or schema in line with a Grant.
+func diffDatabasePrivileges(gp v1alpha1.GrantParameters, held map[string]bool) sqlgen.PrivilegeDiff {
+	gro := gp.WithOption != nil && *gp.WithOption == v1alpha1.GrantOptionGrant
+	return sqlgen.DiffDatabasePrivileges(expandedPrivileges(gp), gro, ptr.Deref(gp.RevokeUnmanaged, false), held)
+}
+
+// expandedPrivileges returns the privileges of a database or schema grant,
+// with any shorthands expanded.
+func expandedPrivileges(gp v1alpha1.GrantParameters) []string {
+	if gp.Schema != nil || len(gp.Schemas) > 0 {
+		ep := gp.Privileges.ExpandSchemaPrivileges()
+		return ep.ToStringSlice()
+	}
+	ep := gp.Privileges.ExpandPrivileges()
+	return ep.ToStringSlice()
+}
+
+// updateSchemaObjectQueries returns the queries that bring a table,
+// sequence or routine grant up to date. Drifted privileges are revoked and granted again
+// with the desired grant option, after revoking all privileges of the role on
+// the objects if unmanaged ones should be revoked. Otherwise the privileges
+// are only granted on the supplied objects that lack them.
+func updateSchemaObjectQueries(gp v1alpha1.GrantParameters, drifted bool, missing []string) []xsql.Query {
+	var ql []xsql.Query
+	switch {
+	case drifted:
+		o := objectsOf(gp)
+		ro := pq.QuoteIdentifier(*gp.Role)
+		ta := o.target(*gp.Schema)
+		if ptr.Deref(gp.RevokeUnmanaged, false) {
+			ql = append(ql, xsql.Query{String: fmt.Sprintf("REVOKE ALL ON %s FROM %s", ta, ro)})
+		}
+		ql = append(ql,
+			xsql.Query{String: fmt.Sprintf("REVOKE %s ON %s FROM %s", privileges(gp), ta, ro)},
+			xsql.Query{String: strings.TrimSpace(fmt.Sprintf("GRANT %s ON %s TO %s %s", privileges(gp), ta, ro, withOption(gp.WithOption)))},
+		)
+	case len(missing) > 0:
+		ql = append(ql, repairQuery(gp, missing))
+	}
+	if gp.DefaultPrivilegesFor != nil {
+		ql = append(ql, defaultPrivilegesQuery(gp, true))
+	}
+	return ql
+}
+
+// repairQuery returns the GRANT that gives the role of a table, sequence or
+// routine grant its privileges on the supplied objects, which lack them.
+// Routines may be overloaded, so those of a grant on all routines of a
+// schema are granted on the whole schema, which leaves the privileges the
+// role already holds untouched.
+func repairQuery(gp v1alpha1.GrantParameters, missing []string) xsql.Query {
+	o := objectsOf(gp)
+	if !o.all() || o.catalog != "pg_proc" {
+		o.names = missing
 	}
-	return ""
+	return xsql.Query{String: strings.TrimSpace(fmt.Sprintf("GRANT %s ON %s TO %s %s",
+		privileges(gp), o.target(*gp.Schema), pq.QuoteIdentifier(*gp.Role), withOption(gp.WithOption)))}
 }
 
 func createGrantQueries(gp v1alpha1.GrantParameters, ql *[]xsql.Query) error { // nolint: gocyclo
@@ -258,7 +836,7 @@ func createGrantQueries(gp v1alpha1.GrantParameters, ql *[]xsql.Query) error { /
 		}
 
 		db := pq.QuoteIdentifier(*gp.Database)
-		sp := strings.Join(gp.Privileges.ToStringSlice(), ",")
+		sp := privileges(gp)
 
 		*ql = append(*ql,
 			// REVOKE ANY MATCHING EXISTING PERMISSIONS
@@ -277,11 +855,89 @@ func createGrantQueries(gp v1alpha1.GrantParameters, ql *[]xsql.Query) error { /
 			)},
 		)
 		return nil
+	case roleParameter:
+		if gp.Role == nil || len(gp.Privileges) < 1 {
+			return errors.Errorf(errInvalidParams, roleParameter)
+		}
+
+		pa := sqlgen.QuoteParameters(gp.Parameters)
+		sp := privileges(gp)
+
+		*ql = append(*ql,
+			// REVOKE ANY MATCHING EXISTING PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("REVOKE %s ON PARAMETER %s FROM %s",
+				sp,
+				pa,
+				ro,
+			)},
+
+			// GRANT REQUESTED PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("GRANT %s ON PARAMETER %s TO %s %s",
+				sp,
+				pa,
+				ro,
+				withOption(gp.WithOption),
+			)},
+		)
+		return nil
+	case roleSchema:
+		if gp.Role == nil || len(gp.Privileges) < 1 {
+			return errors.Errorf(errInvalidParams, roleSchema)
+		}
+
+		sc := pq.QuoteIdentifier(*gp.Schema)
+		sp := privileges(gp)
+
+		*ql = append(*ql,
+			// REVOKE ANY MATCHING EXISTING PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("REVOKE %s ON SCHEMA %s FROM %s",
+				sp,
+				sc,
+				ro,
+			)},
+
+			// GRANT REQUESTED PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("GRANT %s ON SCHEMA %s TO %s %s",
+				sp,
+				sc,
+				ro,
+				withOption(gp.WithOption),
+			)},
+		)
+		return nil
+	case roleSchemaObj:
+		if gp.Role == nil || len(gp.Privileges) < 1 {
+			return errors.Errorf(errInvalidParams, roleSchemaObj)
+		}
+
+		ta := objectsOf(gp).target(*gp.Schema)
+		sp := privileges(gp)
+
+		*ql = append(*ql,
+			// REVOKE ANY MATCHING EXISTING PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("REVOKE %s ON %s FROM %s",
+				sp,
+				ta,
+				ro,
+			)},
+
+			// GRANT REQUESTED PERMISSIONS
+			xsql.Query{String: fmt.Sprintf("GRANT %s ON %s TO %s %s",
+				sp,
+				ta,
+				ro,
+				withOption(gp.WithOption),
+			)},
+		)
+		if gp.DefaultPrivilegesFor != nil {
+			*ql = append(*ql, defaultPrivilegesQuery(gp, true))
+		}
+		return nil
 	}
 	return errors.New(errUnknownGrant)
 }
 
-func deleteGrantQuery(gp v1alpha1.GrantParameters, q *xsql.Query) error {
+func deleteGrantQueries(gp v1alpha1.GrantParameters, ql *[]xsql.Query) error {
 	gt, err := identifyGrantType(gp)
 	if err != nil {
 		return err
@@ -291,17 +947,41 @@ func deleteGrantQuery(gp v1alpha1.GrantParameters, q *xsql.Query) error {
 
 	switch gt {
 	case roleMember:
-		q.String = fmt.Sprintf("REVOKE %s FROM %s",
+		*ql = append(*ql, xsql.Query{String: fmt.Sprintf("REVOKE %s FROM %s",
 			pq.QuoteIdentifier(*gp.MemberOf),
 			ro,
-		)
+		)})
 		return nil
 	case roleDatabase:
-		q.String = fmt.Sprintf("REVOKE %s ON DATABASE %s FROM %s",
-			strings.Join(gp.Privileges.ToStringSlice(), ","),
+		*ql = append(*ql, xsql.Query{String: fmt.Sprintf("REVOKE %s ON DATABASE %s FROM %s",
+			privileges(gp),
 			pq.QuoteIdentifier(*gp.Database),
 			ro,
-		)
+		)})
+		return nil
+	case roleParameter:
+		*ql = append(*ql, xsql.Query{String: fmt.Sprintf("REVOKE %s ON PARAMETER %s FROM %s",
+			privileges(gp),
+			sqlgen.QuoteParameters(gp.Parameters),
+			ro,
+		)})
+		return nil
+	case roleSchema:
+		*ql = append(*ql, xsql.Query{String: fmt.Sprintf("REVOKE %s ON SCHEMA %s FROM %s",
+			privileges(gp),
+			pq.QuoteIdentifier(*gp.Schema),
+			ro,
+		)})
+		return nil
+	case roleSchemaObj:
+		*ql = append(*ql, xsql.Query{String: fmt.Sprintf("REVOKE %s ON %s FROM %s",
+			privileges(gp),
+			objectsOf(gp).target(*gp.Schema),
+			ro,
+		)})
+		if gp.DefaultPrivilegesFor != nil {
+			*ql = append(*ql, defaultPrivilegesQuery(gp, false))
+		}
 		return nil
 	}
 	return errors.New(errUnknownGrant)
@@ -317,12 +997,26 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNoRole)
 	}
 
-	gp := cr.Spec.ForProvider
+	gp := c.parameters(cr)
+	if gp.DatabasesSelector != nil || len(gp.Schemas) > 0 {
+		if _, err := identifyGrantType(gp); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		return c.observeSelected(ctx, cr, gp)
+	}
+
 	var query xsql.Query
 	if err := selectGrantQuery(gp, &query); err != nil {
 		return managed.ExternalObservation{}, err
 	}
 
+	switch gt, _ := identifyGrantType(gp); gt {
+	case roleDatabase, roleSchema:
+		return c.observeDatabase(ctx, cr, gp, query)
+	case roleSchemaObj:
+		return c.observeSchemaObjects(ctx, cr, gp, query)
+	}
+
 	exists := false
 
 	if err := c.db.Scan(ctx, query, &exists); err != nil {
@@ -343,28 +1037,229 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}, nil
 }
 
+// observeDatabase observes a database or schema grant. The grant exists if
+// the role holds any of the desired privileges, and is up to date once it holds
+// exactly those the Grant asks for.
+func (c *external) observeDatabase(ctx context.Context, cr *v1alpha1.Grant, gp v1alpha1.GrantParameters, query xsql.Query) (managed.ExternalObservation, error) {
+	held, err := c.databasePrivileges(ctx, query)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+
+	if !holdsAny(gp, held) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: diffDatabasePrivileges(gp, held).Empty(),
+	}, nil
+}
+
+// observeSelected observes a database grant on every database selected by
+// its databasesSelector, and records whether it is up to date on each, or a
+// schema grant on each of its schemas. The grant exists if the role holds any
+// of the desired privileges on any of them, or no databases are selected, and
+// is up to date once it holds exactly those the Grant asks for on all of them.
+func (c *external) observeSelected(ctx context.Context, cr *v1alpha1.Grant, gp v1alpha1.GrantParameters) (managed.ExternalObservation, error) {
+	selected := gp.DatabasesSelector != nil
+	exists := selected && len(c.databases) == 0
+	upToDate := true
+	observed := make([]v1alpha1.GrantDatabaseObservation, 0, len(c.databases))
+	for _, t := range c.targets(gp) {
+		var query xsql.Query
+		if err := selectGrantQuery(t, &query); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		held, err := c.databasePrivileges(ctx, query)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+		}
+		ok := diffDatabasePrivileges(t, held).Empty()
+		exists = exists || holdsAny(t, held)
+		upToDate = upToDate && ok
+		if selected {
+			observed = append(observed, v1alpha1.GrantDatabaseObservation{Name: *t.Database, UpToDate: ok})
+		}
+	}
+	if selected {
+		cr.Status.AtProvider.Databases = observed
+	}
+
+	if !exists {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+// holdsAny returns true if the supplied privileges a role holds on a
+// database or schema include any of those of the grant.
+func holdsAny(gp v1alpha1.GrantParameters, held map[string]bool) bool {
+	for _, p := range expandedPrivileges(gp) {
+		if _, ok := held[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// observeSchemaObjects observes a table, sequence or routine grant. The grant
+// exists once all of its objects exist and the role holds the desired
+// privileges on any of them, and is only up to date once it holds them on
+// all of them with the desired grant option. It also reports whether objects
+// created later in the schema are covered by default privileges, and is only
+// up to date once those it manages exist.
+func (c *external) observeSchemaObjects(ctx context.Context, cr *v1alpha1.Grant, gp v1alpha1.GrantParameters, query xsql.Query) (managed.ExternalObservation, error) {
+	var total, missing, drifted int
+	var missingObjects pq.StringArray
+	err := c.db.Scan(ctx, query, &total, &missing, &drifted, &missingObjects)
+	if postgresql.IsInvalidCatalog(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+
+	o := objectsOf(gp)
+	if (missing > 0 && missing == total) || (!o.all() && total < len(o.names)) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	c.objectsDrifted = drifted > 0
+	c.objectsMissing = missingObjects
+
+	covered, managedCovered, err := c.defaultPrivileges(ctx, gp)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDefaultPrivs)
+	}
+
+	cr.SetConditions(xpv1.Available())
+	if covered {
+		cr.SetConditions(v1alpha1.FutureObjectsCovered())
+	} else {
+		cr.SetConditions(v1alpha1.FutureObjectsNotCovered().WithMessage(
+			fmt.Sprintf(fmtFutureObjectsNotCovered, strings.ToLower(o.kind)+"s", *gp.Schema, *gp.Role)))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: !c.objectsDrifted && len(c.objectsMissing) == 0 && (gp.DefaultPrivilegesFor == nil || managedCovered),
+	}, nil
+}
+
+// defaultPrivileges returns whether default privileges of any role grant the
+// role of a schema object grant its privileges on objects created later in
+// its schema, and whether those of DefaultPrivilegesFor do.
+func (c *external) defaultPrivileges(ctx context.Context, gp v1alpha1.GrantParameters) (bool, bool, error) {
+	var anyRole, forRole bool
+	err := c.db.Scan(ctx, xsql.Query{
+		String: "SELECT COALESCE(bool_or(d.privileges @> $4::text[]), false), " +
+			"COALESCE(bool_or(d.privileges @> $4::text[] AND d.owner = $5), false) " +
+			"FROM (SELECT pg_get_userbyid(da.defaclrole) AS owner, array_agg(acl.privilege_type) AS privileges " +
+			"FROM pg_default_acl da " +
+			"INNER JOIN pg_namespace n ON da.defaclnamespace = n.oid, " +
+			"aclexplode(da.defaclacl) as acl " +
+			"INNER JOIN pg_roles s ON acl.grantee = s.oid " +
+			"WHERE n.nspname=$1 " +
+			"AND da.defaclobjtype=$2 " +
+			"AND s.rolname=$3 " +
+			"GROUP BY da.defaclrole) d",
+		Parameters: []interface{}{
+			gp.Schema,
+			objectsOf(gp).defaclobjtype,
+			gp.Role,
+			pq.Array(objectsOf(gp).privileges),
+			ptr.Deref(gp.DefaultPrivilegesFor, ""),
+		},
+	}, &anyRole, &forRole)
+	return anyRole, forRole, err
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Grant)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotGrant)
 	}
 
+	if err := c.checkSelf(cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	var queries []xsql.Query
 
 	cr.SetConditions(xpv1.Creating())
 
-	if err := createGrantQueries(cr.Spec.ForProvider, &queries); err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, errCreateGrant)
+	for _, gp := range c.targets(c.parameters(cr)) {
+		if err := createGrantQueries(gp, &queries); err != nil {
+			return managed.ExternalCreation{}, errors.Wrap(err, errCreateGrant)
+		}
+	}
+	if len(queries) == 0 {
+		return managed.ExternalCreation{}, nil
 	}
 
-	err := c.db.ExecTx(ctx, queries)
+	err := c.execTx(ctx, cr.Spec.ForProvider.Grantor, queries...)
 	return managed.ExternalCreation{}, errors.Wrap(err, errCreateGrant)
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	// Update is a no-op, as permissions are fully revoked and then granted in the Create function,
-	// inside a transaction.
-	return managed.ExternalUpdate{}, nil
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGrant)
+	}
+
+	if err := c.checkSelf(cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	// Membership and parameter grants are only ever observed as missing or
+	// up to date; Create fully revokes and then grants them inside a
+	// transaction. Database and schema grants are brought up to date by
+	// applying only the privileges that differ. Table and sequence grants are out of date
+	// when their privileges have drifted, some of their objects lack them, or
+	// their default privileges are missing.
+	gp := c.parameters(cr)
+	gt, err := identifyGrantType(gp)
+	if gt == roleSchemaObj {
+		ql := updateSchemaObjectQueries(gp, c.objectsDrifted, c.objectsMissing)
+		if len(ql) == 0 {
+			return managed.ExternalUpdate{}, nil
+		}
+		return managed.ExternalUpdate{}, errors.Wrap(c.execTx(ctx, gp.Grantor, ql...), errUpdateGrant)
+	}
+	if err != nil || (gt != roleDatabase && gt != roleSchema) {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateGrant)
+	}
+
+	var ql []xsql.Query
+	for _, t := range c.targets(gp) {
+		var query xsql.Query
+		if err := selectGrantQuery(t, &query); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateGrant)
+		}
+		held, err := c.databasePrivileges(ctx, query)
+		if err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errSelectGrant)
+		}
+		d, option := diffDatabasePrivileges(t, held), string(ptr.Deref(t.WithOption, ""))
+		if gt == roleSchema {
+			ql = append(ql, sqlgen.UpdateSchemaQueries(*t.Schema, *t.Role, d, option)...)
+			continue
+		}
+		ql = append(ql, sqlgen.UpdateDatabaseQueries(*t.Database, *t.Role, d, option)...)
+	}
+	if len(ql) == 0 {
+		return managed.ExternalUpdate{}, nil
+	}
+	return managed.ExternalUpdate{}, errors.Wrap(c.execTx(ctx, gp.Grantor, ql...), errUpdateGrant)
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -372,14 +1267,74 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	if !ok {
 		return errors.New(errNotGrant)
 	}
-	var query xsql.Query
+
+	if err := c.checkSelf(cr); err != nil {
+		return err
+	}
 
 	cr.SetConditions(xpv1.Deleting())
 
-	err := deleteGrantQuery(cr.Spec.ForProvider, &query)
-	if err != nil {
-		return errors.Wrap(err, errRevokeGrant)
+	var queries []xsql.Query
+	for _, gp := range c.targets(c.parameters(cr)) {
+		if err := deleteGrantQueries(gp, &queries); err != nil {
+			return errors.Wrap(err, errRevokeGrant)
+		}
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	// A role can only revoke the grants it made, so revoke as the grantor.
+	var err error
+	if len(queries) > 1 || cr.Spec.ForProvider.Grantor != nil || c.lockTimeout != nil {
+		err = c.execTx(ctx, cr.Spec.ForProvider.Grantor, queries...)
+	} else {
+		err = c.db.Exec(ctx, queries[0])
+	}
+
+	// Privileges on a database, or on objects in it, are dropped along with
+	// the database.
+	if postgresql.IsInvalidCatalog(err) {
+		return nil
+	}
+	return errors.Wrap(err, errRevokeGrant)
+}
+
+// checkSelf refuses to change the grants of the role the provider connects
+// as, which could remove the privileges the provider relies on.
+func (c *external) checkSelf(cr *v1alpha1.Grant) error {
+	r := cr.Spec.ForProvider.Role
+	if r == nil {
+		return nil
+	}
+	return xsql.CheckReserved(cr, "role", *r, c.self != "" && *r == c.self)
+}
+
+// execTx runs the supplied queries in a transaction as the grantor, if any,
+// with the configured lock timeout. Transactions aborted to resolve a
+// deadlock with a concurrent reconcile are retried.
+func (c *external) execTx(ctx context.Context, grantor *string, ql ...xsql.Query) error {
+	ql = asGrantor(grantor, ql...)
+	if c.lockTimeout != nil {
+		lt := xsql.Query{String: fmt.Sprintf("SET LOCAL lock_timeout = %d", c.lockTimeout.Milliseconds())}
+		ql = append([]xsql.Query{lt}, ql...)
 	}
 
-	return errors.Wrap(c.db.Exec(ctx, query), errRevokeGrant)
+	var err error
+	for i := 0; i < maxTxAttempts; i++ {
+		if err = c.db.ExecTx(ctx, ql); !postgresql.IsDeadlock(err) {
+			break
+		}
+	}
+	return err
+}
+
+// asGrantor prefixes the supplied queries with a SET LOCAL ROLE to the
+// grantor, if any, so that they run as the grantor for the rest of the
+// transaction.
+func asGrantor(grantor *string, ql ...xsql.Query) []xsql.Query {
+	if grantor == nil {
+		return ql
+	}
+	return append([]xsql.Query{{String: "SET LOCAL ROLE " + pq.QuoteIdentifier(*grantor)}}, ql...)
 }
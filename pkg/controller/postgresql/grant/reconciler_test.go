@@ -19,9 +19,9 @@ package grant
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
@@ -29,10 +29,12 @@ import (
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -74,7 +76,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+		newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -173,6 +175,131 @@ func TestConnect(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errGetSecret),
 		},
+		"ErrUnknownPrivilegeSet": {
+			reason: "An error should be returned if the privilege set is not defined by our ProviderConfig",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						o := obj.(*v1alpha1.ProviderConfig)
+						o.SetName("default")
+						o.Spec.PrivilegeSets = map[string]v1alpha1.GrantPrivileges{"readonly": {"SELECT"}}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{Name: "default"},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							PrivilegeSet: ptr.To("readwrite"),
+						},
+					},
+				},
+			},
+			want: errors.Errorf(errUnknownPrivilegeSet, "readwrite", "default"),
+		},
+		"ErrPrivilegeSetAndPrivileges": {
+			reason: "An error should be returned if both a privilege set and privileges are set",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							Privileges:   v1alpha1.GrantPrivileges{"SELECT"},
+							PrivilegeSet: ptr.To("readonly"),
+						},
+					},
+				},
+			},
+			want: errors.New(errPrivilegeSetAndPrivileges),
+		},
+		"ErrPrivilegeProfileAndPrivileges": {
+			reason: "An error should be returned if both a privilege profile and privileges are set",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							Schema:           ptr.To("app"),
+							Privileges:       v1alpha1.GrantPrivileges{"USAGE"},
+							PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileReader),
+						},
+					},
+				},
+			},
+			want: errors.New(errPrivilegeProfileAndPrivileges),
+		},
+		"ErrDatabasesSelectorGrantType": {
+			reason: "An error should be returned if a grant that is not a database grant selects databases",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							Role:              ptr.To("monitoring"),
+							Schema:            ptr.To("app"),
+							Tables:            []string{"*"},
+							Privileges:        v1alpha1.GrantPrivileges{"SELECT"},
+							DatabasesSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "platform"}},
+						},
+					},
+				},
+			},
+			want: errors.New(errDatabasesSelectorGrantType),
+		},
+		"ErrListDatabases": {
+			reason: "An error should be returned if we can't list the databases selected by databasesSelector",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet:  test.NewMockGetFn(nil),
+					MockList: test.NewMockListFn(errBoom),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.GrantParameters{
+							Role:              ptr.To("monitoring"),
+							Privileges:        v1alpha1.GrantPrivileges{"CONNECT"},
+							DatabasesSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "platform"}},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errListDatabases),
+		},
 	}
 
 	for name, tc := range cases {
@@ -186,13 +313,116 @@ func TestConnect(t *testing.T) {
 	}
 }
 
+func TestSelectDatabases(t *testing.T) {
+	database := func(name, pc string, deleted bool) v1alpha1.Database {
+		db := v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		meta.SetExternalName(&db, name)
+		db.SetProviderConfigReference(&xpv1.Reference{Name: pc})
+		if deleted {
+			db.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		}
+		return db
+	}
+
+	kube := &test.MockClient{
+		MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+			l := obj.(*v1alpha1.DatabaseList)
+			l.Items = []v1alpha1.Database{
+				database("orders", "default", false),
+				database("billing", "default", false),
+				database("other", "other", false),
+				database("old", "default", true),
+			}
+			return nil
+		}),
+	}
+	cr := &v1alpha1.Grant{
+		Spec: v1alpha1.GrantSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{Name: "default"},
+			},
+			ForProvider: v1alpha1.GrantParameters{
+				Role:              ptr.To("monitoring"),
+				Privileges:        v1alpha1.GrantPrivileges{"CONNECT"},
+				DatabasesSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "platform"}},
+			},
+		},
+	}
+
+	c := &connector{kube: kube}
+	got, err := c.selectDatabases(context.Background(), cr, nil)
+	if err != nil {
+		t.Fatalf("selectDatabases(...): %v", err)
+	}
+	want := []string{"billing", "orders"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("selectDatabases(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPrivilegeProfile(t *testing.T) {
+	type want struct {
+		privileges v1alpha1.GrantPrivileges
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		gp     v1alpha1.GrantParameters
+		want   want
+	}{
+		"NoProfile": {
+			reason: "No privileges should be returned if the grant does not use a profile",
+			gp:     v1alpha1.GrantParameters{Schema: ptr.To("app")},
+		},
+		"OwnerSchema": {
+			reason: "An owner should be granted both USAGE and CREATE on a schema",
+			gp:     v1alpha1.GrantParameters{Schema: ptr.To("app"), PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileOwner)},
+			want:   want{privileges: v1alpha1.GrantPrivileges{"CREATE", "USAGE"}},
+		},
+		"WriterTables": {
+			reason: "A writer should be able to read and modify the data of tables",
+			gp:     v1alpha1.GrantParameters{Schema: ptr.To("app"), Tables: []string{"*"}, PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileWriter)},
+			want:   want{privileges: v1alpha1.GrantPrivileges{"DELETE", "INSERT", "SELECT", "UPDATE"}},
+		},
+		"ReaderSequences": {
+			reason: "A reader should only be able to read sequences",
+			gp:     v1alpha1.GrantParameters{Schema: ptr.To("app"), Sequences: []string{"*"}, PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileReader)},
+			want:   want{privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+		},
+		"ErrWithoutSchema": {
+			reason: "An error should be returned if a grant without a schema uses a profile",
+			gp:     v1alpha1.GrantParameters{Database: ptr.To("app"), PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileReader)},
+			want:   want{err: errors.New(errPrivilegeProfileWithoutSchema)},
+		},
+		"ErrPrivilegeSet": {
+			reason: "An error should be returned if a grant uses both a profile and a privilege set",
+			gp:     v1alpha1.GrantParameters{Schema: ptr.To("app"), PrivilegeSet: ptr.To("readonly"), PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileReader)},
+			want:   want{err: errors.New(errPrivilegeProfileAndPrivileges)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := privilegeProfile(tc.gp)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nprivilegeProfile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.privileges, got); diff != "" {
+				t.Errorf("\n%s\nprivilegeProfile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestObserve(t *testing.T) {
 	errBoom := errors.New("boom")
 	goa := v1alpha1.GrantOptionAdmin
 	gog := v1alpha1.GrantOptionGrant
 
 	type fields struct {
-		db xsql.DB
+		db        xsql.DB
+		databases []string
 	}
 
 	type args struct {
@@ -201,8 +431,51 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o          managed.ExternalObservation
+		err        error
+		atProvider v1alpha1.GrantObservation
+
+		// futureObjects is the reason of the FutureObjectsCovered condition.
+		futureObjects xpv1.ConditionReason
+	}
+
+	// schemaObjectsDB reports the supplied number of tables, of which missing
+	// lack privileges and drifted hold them with the wrong grant option, and
+	// whether default privileges of any role and of the defaultPrivilegesFor
+	// role cover future tables. The tables that lack privileges are named by
+	// missingObjects.
+	schemaObjectsDB := func(total, missing, drifted int, anyRole, forRole bool, missingObjects ...string) xsql.DB {
+		return mockDB{
+			MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				if strings.Contains(q.String, "pg_default_acl") {
+					*dest[0].(*bool) = anyRole
+					*dest[1].(*bool) = forRole
+					return nil
+				}
+				if !strings.Contains(q.String, "pg_class") {
+					return errBoom
+				}
+				*dest[0].(*int) = total
+				*dest[1].(*int) = missing
+				*dest[2].(*int) = drifted
+				*dest[3].(*pq.StringArray) = missingObjects
+				return nil
+			},
+		}
+	}
+	tableGrant := func(tables []string, defaultPrivilegesFor *string) *v1alpha1.Grant {
+		return &v1alpha1.Grant{
+			Spec: v1alpha1.GrantSpec{
+				ForProvider: v1alpha1.GrantParameters{
+					Database:             ptr.To("testdb"),
+					Role:                 ptr.To("testrole"),
+					Schema:               ptr.To("app"),
+					Tables:               tables,
+					Privileges:           v1alpha1.GrantPrivileges{"SELECT"},
+					DefaultPrivilegesFor: defaultPrivilegesFor,
+				},
+			},
+		}
 	}
 
 	cases := map[string]struct {
@@ -225,9 +498,1193 @@ func TestObserve(t *testing.T) {
 			fields: fields{
 				db: mockDB{
 					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						// Default value is false, so just return
-						bv := dest[0].(*bool)
-						*bv = false
+						// The role holds no privileges on the database.
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"AllMapsToExpandedPrivileges": {
+			reason: "We expand ALL to CREATE, TEMPORARY, CONNECT when checking for existing grants",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[1].(*pq.StringArray) = pq.StringArray{"TEMPORARY", "CONNECT", "CREATE"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"UnmanagedPrivilege": {
+			reason: "A privilege that is not part of the grant should make it not up to date when revokeUnmanaged is true",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[1].(*pq.StringArray) = pq.StringArray{"CONNECT", "CREATE"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:        ptr.To("test-example"),
+							Role:            ptr.To("test-example"),
+							Privileges:      v1alpha1.GrantPrivileges{"CONNECT"},
+							RevokeUnmanaged: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"TwoGrantsOneRoleAndDatabase": {
+			reason: "A privilege managed by another Grant of the same role and database should be ignored by default",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[1].(*pq.StringArray) = pq.StringArray{"CONNECT", "CREATE"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"MissingGrantOption": {
+			reason: "A privilege held without the requested grant option should make the grant not up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[1].(*pq.StringArray) = pq.StringArray{"CONNECT"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+							WithOption: &gog,
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while trying to show the grant",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT", "TEMPORARY"},
+							WithOption: &gog,
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGrant),
+			},
+		},
+		"SuccessRoleDb": {
+			reason: "We should return no error if we can find our role-db grant",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*pq.StringArray) = pq.StringArray{"CONNECT", "CREATE", "TEMPORARY"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("testdb"),
+							Role:       ptr.To("testrole"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							WithOption: &gog,
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"SuccessRoleMembership": {
+			reason: "We should return no error if we can find our role-membership grant",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*bool)
+						*bv = true
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							MemberOf:   ptr.To("parentrole"),
+							WithOption: &goa,
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"ErrParametersWithDatabase": {
+			reason: "We should return an error if both parameters and database are set",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("testdb"),
+							Role:       ptr.To("testrole"),
+							Parameters: []string{"work_mem"},
+							Privileges: v1alpha1.GrantPrivileges{"SET"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errParametersWithDatabase),
+			},
+		},
+		"SuccessRoleParameter": {
+			reason: "We should return no error if we can find our role-parameter grant",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if !strings.Contains(q.String, "pg_parameter_acl") {
+							return errBoom
+						}
+						bv := dest[0].(*bool)
+						*bv = true
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Parameters: []string{"work_mem", "pgaudit.log"},
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				err: nil,
+			},
+		},
+		"ErrTablesAndSequences": {
+			reason: "We should return an error if both tables and sequences are set",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schema:     ptr.To("app"),
+							Tables:     []string{"*"},
+							Sequences:  []string{"*"},
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errTablesOrSequences),
+			},
+		},
+		"ErrSchemaPrivilege": {
+			reason: "We should return an error if a privilege that does not apply to schemas is granted on one",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schema:     ptr.To("app"),
+							Privileges: v1alpha1.GrantPrivileges{"USAGE", "SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errSchemaPrivilege, "SELECT"),
+			},
+		},
+		"ErrSchemaCreateWithoutUsage": {
+			reason: "We should return an error if CREATE is granted on a schema without USAGE",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schema:     ptr.To("app"),
+							Privileges: v1alpha1.GrantPrivileges{"CREATE"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errSchemaCreateWithoutUsage),
+			},
+		},
+		"SuccessSchema": {
+			reason: "We expand ALL to USAGE and CREATE when checking for existing schema grants",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if !strings.Contains(q.String, "pg_namespace") {
+							return errBoom
+						}
+						*dest[1].(*pq.StringArray) = pq.StringArray{"USAGE", "CREATE"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schema:     ptr.To("app"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"SuccessSchemasNotUpToDate": {
+			reason: "A grant on several schemas should not be up to date while any schema lacks the privileges",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if *q.Parameters[0].(*string) == "billing" {
+							*dest[1].(*pq.StringArray) = pq.StringArray{"USAGE"}
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schemas:    []string{"app", "billing"},
+							Privileges: v1alpha1.GrantPrivileges{"USAGE"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ErrSchemaAndSchemas": {
+			reason: "We should return an error if both schema and schemas are set",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Schema:     ptr.To("app"),
+							Schemas:    []string{"billing"},
+							Privileges: v1alpha1.GrantPrivileges{"USAGE"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errSchemaAndSchemas),
+			},
+		},
+		"ErrNoSchema": {
+			reason: "We should return an error if tables are set without a schema",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("testrole"),
+							Tables:     []string{"users"},
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.New(errNoSchema),
+			},
+		},
+		"SuccessSchemaObjectsMissing": {
+			reason: "A table grant should not exist while every listed table lacks the privileges",
+			fields: fields{
+				db: schemaObjectsDB(2, 2, 0, false, false, "orders", "users"),
+			},
+			args: args{
+				mg: tableGrant([]string{"orders", "users"}, nil),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"SuccessSchemaObjectsMissingTable": {
+			reason: "A table grant should exist but not be up to date while some of its tables lack the privileges, so that they alone are repaired",
+			fields: fields{
+				db: schemaObjectsDB(2, 1, 0, false, false, "orders"),
+			},
+			args: args{
+				mg: tableGrant([]string{"orders", "users"}, nil),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesMissing,
+			},
+		},
+		"SuccessSchemaObjectsNotCovered": {
+			reason: "A table grant should exist but warn when no default privileges cover future tables",
+			fields: fields{
+				db: schemaObjectsDB(2, 0, 0, false, false),
+			},
+			args: args{
+				mg: tableGrant([]string{"orders", "users"}, nil),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesMissing,
+			},
+		},
+		"SuccessSchemaObjectsCovered": {
+			reason: "A grant on all tables should report when default privileges cover future tables",
+			fields: fields{
+				db: schemaObjectsDB(0, 0, 0, true, false),
+			},
+			args: args{
+				mg: tableGrant([]string{"*"}, nil),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesPresent,
+			},
+		},
+		"SuccessSchemaObjectsDefaultPrivilegesMissing": {
+			reason: "A table grant should not be up to date while the default privileges it manages are missing",
+			fields: fields{
+				db: schemaObjectsDB(3, 0, 0, true, false),
+			},
+			args: args{
+				mg: tableGrant([]string{"*"}, ptr.To("migrator")),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesPresent,
+			},
+		},
+		"SuccessSelectedDatabases": {
+			reason: "A grant on selected databases should record whether it is up to date on each of them",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if *q.Parameters[0].(*string) == "orders" {
+							*dest[1].(*pq.StringArray) = pq.StringArray{"CONNECT"}
+						}
+						return nil
+					},
+				},
+				databases: []string{"billing", "orders"},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:              ptr.To("monitoring"),
+							Privileges:        v1alpha1.GrantPrivileges{"CONNECT"},
+							DatabasesSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "platform"}},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.GrantObservation{Databases: []v1alpha1.GrantDatabaseObservation{
+					{Name: "billing", UpToDate: false},
+					{Name: "orders", UpToDate: true},
+				}},
+			},
+		},
+		"SuccessNoSelectedDatabases": {
+			reason: "A grant that selects no databases should exist and be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:              ptr.To("monitoring"),
+							Privileges:        v1alpha1.GrantPrivileges{"CONNECT"},
+							DatabasesSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "platform"}},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.GrantObservation{Databases: []v1alpha1.GrantDatabaseObservation{}},
+			},
+		},
+		"SuccessSchemaObjectsDrifted": {
+			reason: "A table grant should not be up to date while the role holds its privileges with the wrong grant option",
+			fields: fields{
+				db: schemaObjectsDB(2, 0, 1, false, false),
+			},
+			args: args{
+				mg: tableGrant([]string{"orders", "users"}, nil),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesMissing,
+			},
+		},
+		"SuccessProcedures": {
+			reason: "A procedure grant should be observed on the procedures of its schema in pg_proc",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if strings.Contains(q.String, "pg_default_acl") {
+							return nil
+						}
+						if !strings.Contains(q.String, "FROM pg_proc c") || !strings.Contains(q.String, "c.prokind = ANY($2)") {
+							return errBoom
+						}
+						if diff := cmp.Diff(pq.Array([]string{"p"}), q.Parameters[1]); diff != "" {
+							return errors.New(diff)
+						}
+						*dest[0].(*int) = 1
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:        ptr.To("testrole"),
+							Schema:      ptr.To("app"),
+							Routines:    []string{"refresh"},
+							RoutineType: ptr.To(v1alpha1.RoutineTypeProcedure),
+							Privileges:  v1alpha1.GrantPrivileges{"EXECUTE"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				futureObjects: v1alpha1.ReasonDefaultPrivilegesMissing,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, databases: tc.fields.databases}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Grant); ok {
+				if diff := cmp.Diff(tc.want.futureObjects, cr.GetCondition(v1alpha1.TypeFutureObjectsCovered).Reason); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want FutureObjectsCovered reason, +got:\n%s\n", tc.reason, diff)
+				}
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db          xsql.DB
+		lockTimeout *metav1.Duration
+		privileges  v1alpha1.GrantPrivileges
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrSelf": {
+			reason: "Grants to the role the provider connects as should not be changed",
+			fields: fields{
+				db: &mockDB{},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("provider"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: xsql.CheckReserved(&v1alpha1.Grant{}, "role", "provider", true),
+			},
+		},
+		"SuccessLockTimeout": {
+			reason: "The lock timeout should be set for the grant transaction",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if ql[0].String != "SET LOCAL lock_timeout = 5000" {
+							return errors.Errorf("unexpected query: %s", ql[0].String)
+						}
+						return nil
+					},
+				},
+				lockTimeout: &metav1.Duration{Duration: 5 * time.Second},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessPrivilegeSet": {
+			reason: "The privileges of the privilege set of the grant should be granted",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if ql[0].String != `REVOKE CONNECT,TEMP ON DATABASE "test-example" FROM "test-example"` {
+							return errors.Errorf("unexpected query: %s", ql[0].String)
+						}
+						return nil
+					},
+				},
+				privileges: v1alpha1.GrantPrivileges{"TEMP", "CONNECT"},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:     ptr.To("test-example"),
+							Role:         ptr.To("test-example"),
+							PrivilegeSet: ptr.To("readonly"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessDeadlockRetry": {
+			reason: "A grant transaction aborted to resolve a deadlock should be retried",
+			fields: fields{
+				db: func() *mockDB {
+					attempts := 0
+					return &mockDB{
+						MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+							attempts++
+							if attempts == 1 {
+								return &pq.Error{Code: "40P01"}
+							}
+							return nil
+						},
+					}
+				}(),
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrDeadlock": {
+			reason: "A grant transaction that keeps deadlocking should fail after a bounded number of attempts",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return &pq.Error{Code: "40P01"} },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(&pq.Error{Code: "40P01"}, errCreateGrant),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateGrant),
+			},
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a grant",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessRoleParameter": {
+			reason: "Privileges on configuration parameters should be granted",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if ql[1].String != `GRANT ALTER SYSTEM,SET ON PARAMETER "pgaudit"."log","work_mem" TO "test-example" ` {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("test-example"),
+							Parameters: []string{"work_mem", "pgaudit.log"},
+							Privileges: v1alpha1.GrantPrivileges{"SET", "ALTER SYSTEM"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessGrantor": {
+			reason: "Grants with a grantor should be issued after switching to the grantor role",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if len(ql) != 3 || ql[0].String != `SET LOCAL ROLE "schema-owner"` {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+							Grantor:    ptr.To("schema-owner"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessSchema": {
+			reason: "The privileges of a privilege profile should be granted on a schema",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `REVOKE CREATE,USAGE ON SCHEMA "app" FROM "test-example"`},
+							{String: `GRANT CREATE,USAGE ON SCHEMA "app" TO "test-example" `},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+				privileges: v1alpha1.GrantPrivileges{"USAGE", "CREATE"},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:             ptr.To("test-example"),
+							Schema:           ptr.To("app"),
+							PrivilegeProfile: ptr.To(v1alpha1.PrivilegeProfileOwner),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessSchemas": {
+			reason: "Privileges should be granted on every schema in a single transaction",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `REVOKE USAGE ON SCHEMA "app" FROM "test-example"`},
+							{String: `GRANT USAGE ON SCHEMA "app" TO "test-example" `},
+							{String: `REVOKE USAGE ON SCHEMA "billing" FROM "test-example"`},
+							{String: `GRANT USAGE ON SCHEMA "billing" TO "test-example" `},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:       ptr.To("test-example"),
+							Schemas:    []string{"app", "billing"},
+							Privileges: v1alpha1.GrantPrivileges{"USAGE"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessSchemaObjects": {
+			reason: "Privileges on listed sequences and their default privileges should be granted",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `REVOKE SELECT,USAGE ON SEQUENCE "app"."orders_id_seq","app"."users_id_seq" FROM "test-example"`},
+							{String: `GRANT SELECT,USAGE ON SEQUENCE "app"."orders_id_seq","app"."users_id_seq" TO "test-example" `},
+							{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" GRANT SELECT,USAGE ON SEQUENCES TO "test-example"`},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:                 ptr.To("test-example"),
+							Schema:               ptr.To("app"),
+							Sequences:            []string{"users_id_seq", "orders_id_seq"},
+							Privileges:           v1alpha1.GrantPrivileges{"USAGE", "SELECT"},
+							DefaultPrivilegesFor: ptr.To("migrator"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessProcedures": {
+			reason: "EXECUTE should be granted on procedures, and on the functions created later by defaultPrivilegesFor",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `REVOKE EXECUTE ON ALL PROCEDURES IN SCHEMA "app" FROM "test-example"`},
+							{String: `GRANT EXECUTE ON ALL PROCEDURES IN SCHEMA "app" TO "test-example" `},
+							{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" GRANT EXECUTE ON FUNCTIONS TO "test-example"`},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:                 ptr.To("test-example"),
+							Schema:               ptr.To("app"),
+							Routines:             []string{"*"},
+							RoutineType:          ptr.To(v1alpha1.RoutineTypeProcedure),
+							Privileges:           v1alpha1.GrantPrivileges{"EXECUTE"},
+							DefaultPrivilegesFor: ptr.To("migrator"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, lockTimeout: tc.fields.lockTimeout, privileges: tc.fields.privileges, self: "provider"}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+	gog := v1alpha1.GrantOptionGrant
+
+	type fields struct {
+		db             xsql.DB
+		objectsDrifted bool
+		objectsMissing []string
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalUpdate
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrSelf": {
+			reason: "Grants to the role the provider connects as should not be changed, which could revoke the privileges the provider relies on",
+			fields: fields{
+				db: &mockDB{},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:        ptr.To("test-example"),
+							Role:            ptr.To("provider"),
+							Privileges:      v1alpha1.GrantPrivileges{"CONNECT"},
+							RevokeUnmanaged: ptr.To(true),
+						},
+					},
+				},
+			},
+			want: want{
+				err: xsql.CheckReserved(&v1alpha1.Grant{}, "role", "provider", true),
+			},
+		},
+		"ErrNoOp": {
+			reason: "Update is a no-op for role membership grants, make sure we dont throw an error *Grant",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:     ptr.To("test-example"),
+							MemberOf: ptr.To("test-example-parent"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while selecting the held privileges",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGrant),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while applying the difference should be returned",
+			fields: fields{
+				db: mockDB{
+					MockScan:   func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdateGrant),
+			},
+		},
+		"SuccessDiff": {
+			reason: "Only the privileges that differ should be granted and revoked when revokeUnmanaged is true",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*pq.StringArray) = pq.StringArray{"CONNECT"}
+						*dest[1].(*pq.StringArray) = pq.StringArray{"TEMPORARY", "CREATE"}
+						return nil
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []string{
+							`REVOKE CREATE,TEMPORARY ON DATABASE "test-example" FROM "test-example"`,
+							`REVOKE GRANT OPTION FOR CONNECT ON DATABASE "test-example" FROM "test-example"`,
+						}
+						got := make([]string, len(ql))
+						for i, q := range ql {
+							got[i] = q.String
+						}
+						if diff := cmp.Diff(want, got); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
 						return nil
 					},
 				},
@@ -236,43 +1693,37 @@ func TestObserve(t *testing.T) {
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("test-example"),
-							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							Database:        ptr.To("test-example"),
+							Role:            ptr.To("test-example"),
+							Privileges:      v1alpha1.GrantPrivileges{"CONNECT"},
+							RevokeUnmanaged: ptr.To(true),
 						},
 					},
 				},
 			},
 			want: want{
-				o: managed.ExternalObservation{ResourceExists: false},
+				err: nil,
 			},
 		},
-		"AllMapsToExpandedPrivileges": {
-			reason: "We expand ALL to CREATE, TEMPORARY, CONNECT when checking for existing grants",
+		"SuccessSchemaDiff": {
+			reason: "Only the privileges that differ should be granted on a schema",
 			fields: fields{
 				db: mockDB{
 					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						privileges := q.Parameters[3]
-
-						privs, ok := privileges.(*pq.StringArray)
-						if !ok {
-							return fmt.Errorf("expected Scan parameter to be pq.StringArray, got %T", privileges)
+						*dest[1].(*pq.StringArray) = pq.StringArray{"USAGE"}
+						return nil
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []string{
+							`GRANT CREATE ON SCHEMA "app" TO "test-example" `,
 						}
-
-						// The order is not guaranteed, so sort the slices before comparing
-						sort.Strings(*privs)
-
-						// Return if there's a diff between the expected and actual privileges
-						diff := cmp.Diff(&pq.StringArray{"CONNECT", "CREATE", "TEMPORARY"}, privileges)
-
-						bv := dest[0].(*bool)
-						*bv = diff == ""
-
-						// Extra logging in case this test is going to fail
-						if diff != "" {
-							t.Logf("expected empty diff, got: %s", diff)
+						got := make([]string, len(ql))
+						for i, q := range ql {
+							got[i] = q.String
+						}
+						if diff := cmp.Diff(want, got); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
 						}
-
 						return nil
 					},
 				},
@@ -281,27 +1732,30 @@ func TestObserve(t *testing.T) {
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("test-example"),
 							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							Schema:     ptr.To("app"),
+							Privileges: v1alpha1.GrantPrivileges{"USAGE", "CREATE"},
 						},
 					},
 				},
 			},
 			want: want{
-				o: managed.ExternalObservation{
-					ResourceExists:   true,
-					ResourceUpToDate: true,
-				},
 				err: nil,
 			},
 		},
-		"ErrSelectGrant": {
-			reason: "We should return any errors encountered while trying to show the grant",
+		"SuccessTwoGrantsOneRoleAndDatabase": {
+			reason: "Privileges managed by another Grant of the same role and database should be kept by default",
 			fields: fields{
 				db: mockDB{
 					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						return errBoom
+						*dest[1].(*pq.StringArray) = pq.StringArray{"CREATE"}
+						return nil
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if len(ql) != 1 || ql[0].String != `GRANT CONNECT ON DATABASE "test-example" TO "test-example" ` {
+							return errors.Errorf("unexpected queries: %v", ql)
+						}
+						return nil
 					},
 				},
 			},
@@ -311,23 +1765,27 @@ func TestObserve(t *testing.T) {
 						ForProvider: v1alpha1.GrantParameters{
 							Database:   ptr.To("test-example"),
 							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"CONNECT", "TEMPORARY"},
-							WithOption: &gog,
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
 						},
 					},
 				},
 			},
 			want: want{
-				err: errors.Wrap(errBoom, errSelectGrant),
+				err: nil,
 			},
 		},
-		"SuccessRoleDb": {
-			reason: "We should return no error if we can find our role-db grant",
+		"SuccessPreserveUnmanaged": {
+			reason: "Privileges granted outside the Grant should be kept when revokeUnmanaged is false",
 			fields: fields{
 				db: mockDB{
 					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						bv := dest[0].(*bool)
-						*bv = true
+						*dest[1].(*pq.StringArray) = pq.StringArray{"CREATE"}
+						return nil
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if len(ql) != 1 || ql[0].String != `GRANT CONNECT ON DATABASE "test-example" TO "test-example" WITH GRANT OPTION` {
+							return errors.Errorf("unexpected queries: %v", ql)
+						}
 						return nil
 					},
 				},
@@ -336,29 +1794,28 @@ func TestObserve(t *testing.T) {
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("testdb"),
-							Role:       ptr.To("testrole"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
-							WithOption: &gog,
+							Database:        ptr.To("test-example"),
+							Role:            ptr.To("test-example"),
+							Privileges:      v1alpha1.GrantPrivileges{"CONNECT"},
+							WithOption:      &gog,
+							RevokeUnmanaged: ptr.To(false),
 						},
 					},
 				},
 			},
 			want: want{
-				o: managed.ExternalObservation{
-					ResourceExists:   true,
-					ResourceUpToDate: true,
-				},
 				err: nil,
 			},
 		},
-		"SuccessRoleMembership": {
-			reason: "We should return no error if we can find our role-membership grant",
+		"SuccessSchemaObjectsDefaultPrivileges": {
+			reason: "Missing default privileges of a table grant should be granted",
 			fields: fields{
-				db: mockDB{
-					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						bv := dest[0].(*bool)
-						*bv = true
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := `ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" GRANT SELECT ON TABLES TO "test-example"`
+						if len(ql) != 1 || ql[0].String != want {
+							return errors.Errorf("unexpected queries: %v", ql)
+						}
 						return nil
 					},
 				},
@@ -367,105 +1824,112 @@ func TestObserve(t *testing.T) {
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Role:       ptr.To("testrole"),
-							MemberOf:   ptr.To("parentrole"),
-							WithOption: &goa,
+							Role:                 ptr.To("test-example"),
+							Schema:               ptr.To("app"),
+							Tables:               []string{"*"},
+							Privileges:           v1alpha1.GrantPrivileges{"SELECT"},
+							DefaultPrivilegesFor: ptr.To("migrator"),
 						},
 					},
 				},
 			},
 			want: want{
-				o: managed.ExternalObservation{
-					ResourceExists:   true,
-					ResourceUpToDate: true,
-				},
 				err: nil,
 			},
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
-			got, err := e.Observe(tc.args.ctx, tc.args.mg)
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.o, got); diff != "" {
-				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
-			}
-		})
-	}
-}
-
-func TestCreate(t *testing.T) {
-	errBoom := errors.New("boom")
-
-	type fields struct {
-		db xsql.DB
-	}
-
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
-
-	type want struct {
-		c   managed.ExternalCreation
-		err error
-	}
-
-	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
-	}{
-		"ErrNotGrant": {
-			reason: "An error should be returned if the managed resource is not a *Grant",
+		"SuccessSchemaObjectsDrifted": {
+			reason: "Drifted table privileges should be revoked, including unmanaged ones, and granted again",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []string{
+							`REVOKE ALL ON TABLE "app"."orders","app"."users" FROM "test-example"`,
+							`REVOKE SELECT ON TABLE "app"."orders","app"."users" FROM "test-example"`,
+							`GRANT SELECT ON TABLE "app"."orders","app"."users" TO "test-example" WITH GRANT OPTION`,
+						}
+						if len(ql) != len(want) {
+							return errors.Errorf("unexpected queries: %v", ql)
+						}
+						for i := range want {
+							if ql[i].String != want[i] {
+								return errors.Errorf("unexpected query: %s", ql[i].String)
+							}
+						}
+						return nil
+					},
+				},
+				objectsDrifted: true,
+			},
 			args: args{
-				mg: nil,
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:            ptr.To("test-example"),
+							Schema:          ptr.To("app"),
+							Tables:          []string{"users", "orders"},
+							Privileges:      v1alpha1.GrantPrivileges{"SELECT"},
+							WithOption:      &gog,
+							RevokeUnmanaged: ptr.To(true),
+						},
+					},
+				},
 			},
 			want: want{
-				err: errors.New(errNotGrant),
+				err: nil,
 			},
 		},
-		"ErrExec": {
-			reason: "Any errors encountered while creating the grant should be returned",
+		"SuccessSchemaObjectsRepaired": {
+			reason: "Privileges should only be granted on the tables that lack them",
 			fields: fields{
 				db: &mockDB{
-					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{{String: `GRANT SELECT ON TABLE "app"."orders" TO "test-example"`}}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
 				},
+				objectsMissing: []string{"orders"},
 			},
 			args: args{
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("test-example"),
 							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							Schema:     ptr.To("app"),
+							Tables:     []string{"*"},
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
 						},
 					},
 				},
 			},
 			want: want{
-				err: errors.Wrap(errBoom, errCreateGrant),
+				err: nil,
 			},
 		},
-		"Success": {
-			reason: "No error should be returned when we successfully create a grant",
+		"SuccessRoutinesRepaired": {
+			reason: "Privileges on all routines should be granted on the whole schema, as routines may be overloaded",
 			fields: fields{
 				db: &mockDB{
-					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{{String: `GRANT EXECUTE ON ALL FUNCTIONS IN SCHEMA "app" TO "test-example"`}}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
 				},
+				objectsMissing: []string{"refresh"},
 			},
 			args: args{
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("test-example"),
 							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							Schema:     ptr.To("app"),
+							Routines:   []string{"*"},
+							Privileges: v1alpha1.GrantPrivileges{"EXECUTE"},
 						},
 					},
 				},
@@ -474,52 +1938,21 @@ func TestCreate(t *testing.T) {
 				err: nil,
 			},
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
-			got, err := e.Create(tc.args.ctx, tc.args.mg)
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.c, got); diff != "" {
-				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
-			}
-		})
-	}
-}
-
-func TestUpdate(t *testing.T) {
-	type fields struct {
-		db xsql.DB
-	}
-
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
-
-	type want struct {
-		c   managed.ExternalUpdate
-		err error
-	}
-
-	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
-	}{
-		"ErrNoOp": {
-			reason: "Update is a no-op, make sure we dont throw an error *Grant",
+		"SuccessSchemaObjectsUpToDate": {
+			reason: "Nothing should be executed for a table grant whose privileges have not drifted",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
 			args: args{
 				mg: &v1alpha1.Grant{
 					Spec: v1alpha1.GrantSpec{
 						ForProvider: v1alpha1.GrantParameters{
-							Database:   ptr.To("test-example"),
 							Role:       ptr.To("test-example"),
-							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+							Schema:     ptr.To("app"),
+							Tables:     []string{"*"},
+							Privileges: v1alpha1.GrantPrivileges{"SELECT"},
 						},
 					},
 				},
@@ -533,7 +1966,10 @@ func TestUpdate(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			e := external{
-				db: tc.fields.db,
+				db:             tc.fields.db,
+				self:           "provider",
+				objectsDrifted: tc.fields.objectsDrifted,
+				objectsMissing: tc.fields.objectsMissing,
 			}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -593,6 +2029,28 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errRevokeGrant),
 		},
+		"DatabaseGone": {
+			reason: "No error should be returned if the database of the grant has been dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return &pq.Error{Code: "3D000"}
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
 		"Success": {
 			reason: "No error should be returned if the grant was revoked",
 			args: args{
@@ -613,11 +2071,86 @@ func TestDelete(t *testing.T) {
 			},
 			want: nil,
 		},
+		"SuccessGrantor": {
+			reason: "Grants with a grantor should be revoked as the grantor role",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("test-example"),
+							Privileges: v1alpha1.GrantPrivileges{"CONNECT"},
+							Grantor:    ptr.To("schema-owner"),
+						},
+					},
+				},
+			},
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if len(ql) != 2 || ql[0].String != `SET LOCAL ROLE "schema-owner"` {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			want: nil,
+		},
+		"ErrSelf": {
+			reason: "Grants to the role the provider connects as should not be revoked",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Database:   ptr.To("test-example"),
+							Role:       ptr.To("provider"),
+							Privileges: v1alpha1.GrantPrivileges{"ALL"},
+						},
+					},
+				},
+			},
+			fields: fields{
+				db: &mockDB{},
+			},
+			want: xsql.CheckReserved(&v1alpha1.Grant{}, "role", "provider", true),
+		},
+		"SuccessSchemaObjects": {
+			reason: "Privileges on all tables and their default privileges should be revoked together",
+			args: args{
+				mg: &v1alpha1.Grant{
+					Spec: v1alpha1.GrantSpec{
+						ForProvider: v1alpha1.GrantParameters{
+							Role:                 ptr.To("test-example"),
+							Schema:               ptr.To("app"),
+							Tables:               []string{"*"},
+							Privileges:           v1alpha1.GrantPrivileges{"SELECT"},
+							DefaultPrivilegesFor: ptr.To("migrator"),
+						},
+					},
+				},
+			},
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `REVOKE SELECT ON ALL TABLES IN SCHEMA "app" FROM "test-example"`},
+							{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" REVOKE SELECT ON TABLES FROM "test-example"`},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+			},
+			want: nil,
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, self: "provider"}
 			err := e.Delete(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
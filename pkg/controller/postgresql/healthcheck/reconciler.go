@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotHealthCheck = "managed resource is not a HealthCheck custom resource"
+	errRunQuery       = "cannot run health check query"
+	errTooFewRows     = "health check query returned %d rows, expected at least %d"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles HealthCheck managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.HealthCheckGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.HealthCheckGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(pollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.HealthCheck{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.HealthCheckGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.HealthCheckGroupKind))
+}
+
+// pollInterval returns the interval requested by a HealthCheck, falling back
+// to the provider poll interval.
+func pollInterval(mg resource.Managed, d time.Duration) time.Duration {
+	cr, ok := mg.(*v1alpha1.HealthCheck)
+	if !ok || cr.Spec.ForProvider.Interval == nil || cr.Spec.ForProvider.Interval.Duration <= 0 {
+		return d
+	}
+	return cr.Spec.ForProvider.Interval.Duration
+}
+
+// NewConnecter returns a connecter for HealthCheck managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.HealthCheck)
+	if !ok {
+		return nil, errors.New(errNotHealthCheck)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	database := pc.Spec.DefaultDatabase
+	if cr.Spec.ForProvider.Database != nil {
+		database = *cr.Spec.ForProvider.Database
+	}
+
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.HealthCheckKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// Observe runs the health check. A failing check is reported through the
+// Ready condition rather than as an error, so the resource is always treated
+// as existing and up to date.
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.HealthCheck)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotHealthCheck)
+	}
+
+	start := time.Now()
+	n, err := xsql.CountRows(ctx, c.db, xsql.Query{String: cr.Spec.ForProvider.Query})
+	latency := time.Since(start).Milliseconds()
+
+	checked := metav1.NewTime(start)
+	cr.Status.AtProvider.LastCheckTime = &checked
+	cr.Status.AtProvider.LatencyMilliseconds = &latency
+	cr.Status.AtProvider.RowCount = nil
+
+	want := int64(1)
+	if cr.Spec.ForProvider.MinRows != nil {
+		want = *cr.Spec.ForProvider.MinRows
+	}
+
+	switch {
+	case err != nil:
+		cr.SetConditions(xpv1.Unavailable().WithMessage(errors.Wrap(err, errRunQuery).Error()))
+	case n < want:
+		cr.Status.AtProvider.RowCount = &n
+		cr.SetConditions(xpv1.Unavailable().WithMessage(fmt.Sprintf(errTooFewRows, n, want)))
+	default:
+		cr.Status.AtProvider.RowCount = &n
+		cr.SetConditions(xpv1.Available())
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *external) Create(_ context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if _, ok := mg.(*v1alpha1.HealthCheck); !ok {
+		return managed.ExternalCreation{}, errors.New(errNotHealthCheck)
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(_ context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if _, ok := mg.(*v1alpha1.HealthCheck); !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotHealthCheck)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete is a no-op; a HealthCheck has no external state.
+func (c *external) Delete(_ context.Context, mg resource.Managed) error {
+	if _, ok := mg.(*v1alpha1.HealthCheck); !ok {
+		return errors.New(errNotHealthCheck)
+	}
+	return nil
+}
@@ -18,13 +18,22 @@ package postgresql
 
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/cast"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/collation"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/config"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/database"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/databaseinstance"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/extension"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/grant"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/healthcheck"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/role"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql/schema"
 )
@@ -35,10 +44,14 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
 		database.Setup,
+		databaseinstance.Setup,
 		role.Setup,
 		grant.Setup,
 		extension.Setup,
 		schema.Setup,
+		collation.Setup,
+		cast.Setup,
+		healthcheck.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
@@ -46,3 +59,19 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	}
 	return nil
 }
+
+// Connecters returns the constructors of the connecters of all PostgreSQL
+// managed resources, keyed by their group kind.
+func Connecters() map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter {
+	return map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter{
+		v1alpha1.CastGroupKind:             cast.NewConnecter,
+		v1alpha1.CollationGroupKind:        collation.NewConnecter,
+		v1alpha1.DatabaseGroupKind:         database.NewConnecter,
+		v1alpha1.DatabaseInstanceGroupKind: databaseinstance.NewConnecter,
+		v1alpha1.ExtensionGroupKind:        extension.NewConnecter,
+		v1alpha1.GrantGroupKind:            grant.NewConnecter,
+		v1alpha1.HealthCheckGroupKind:      healthcheck.NewConnecter,
+		v1alpha1.RoleGroupKind:             role.NewConnecter,
+		v1alpha1.SchemaGroupKind:           schema.NewConnecter,
+	}
+}
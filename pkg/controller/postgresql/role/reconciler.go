@@ -19,6 +19,7 @@ package role
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
@@ -28,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -35,6 +37,8 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/password"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -44,15 +48,27 @@ import (
 	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+	errGetCACert    = "cannot get CA certificate Secret"
+	errNoCACertKey  = "CA certificate Secret does not contain key %s"
 
 	errNotRole                 = "managed resource is not a Role custom resource"
+	errInvalidName             = "invalid role name"
 	errSelectRole              = "cannot select role"
 	errCreateRole              = "cannot create role"
 	errDropRole                = "cannot drop role"
@@ -60,35 +76,100 @@ const (
 	errGetPasswordSecretFailed = "cannot get password secret"
 	errComparePrivileges       = "cannot compare desired and observed privileges"
 	errSetRoleConfigs          = "cannot set role configuration parameters"
+	errUnknownParameters       = "unknown configuration parameters: %s"
+	errSetRoleOwner            = "cannot record owner of role"
+	errSetRoleComment          = "cannot set role comment"
+	errManagedByOther          = "role is managed by another resource: %s"
+	errGrantMembers            = "cannot grant role to members"
+	errRevokeMembers           = "cannot revoke role from members"
+	errRenameRole              = "cannot rename role"
+	errNotVerifier             = "hashedPassword requires a passwordSecretRef containing a SCRAM-SHA-256 verifier"
+	errGetConnectionSecret     = "cannot get connection secret"
+	errSessionParameters       = "configurationParameters only take effect when a session starts, and a pooler in transaction mode shares sessions between clients"
+	errAuditParameter          = "configurationParameters cannot set %s, which audit manages"
+	errSetRoleAudit            = "cannot set role audit settings"
+
+	// reasonSessionState is the reason of the warning events of Roles that
+	// require session state their ProviderConfig's pooler does not keep.
+	reasonSessionState event.Reason = "SessionState"
+
+	// scramPrefix starts every SCRAM-SHA-256 verifier.
+	scramPrefix = "SCRAM-SHA-256$"
 
 	maxConcurrency = 5
 )
 
+// DisableLateInitialization keeps the privileges and connection limit of
+// existing roles out of the spec of Roles that omit them, so that the spec
+// stays as written. It is enabled by the --disable-late-initialization flag.
+const DisableLateInitialization feature.Flag = "DisableLateInitialization"
+
 // Setup adds a controller that reconciles Role managed resources.
 func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.RoleGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.RoleGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(newConnecter(mgr.GetClient(), t, o.Logger, rec, o.Features.Enabled(DisableLateInitialization)), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec),
+	}
+
+	// Management policies that omit LateInitialize keep the observed
+	// privileges of a role out of its spec.
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.RoleGroupVersionKind), opts...)
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.Role{}, &v1alpha1.RoleList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.Role)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Role{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.RoleGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.RoleGroupKind))
+}
+
+// NewConnecter returns a connecter for Role managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return newConnecter(kube, usage, log, event.NewNopRecorder(), false)
+}
+
+// newConnecter returns a connecter that records warning events of Roles
+// that require session state using the supplied recorder, and that does not
+// late-initialize Roles if skipLateInit is true.
+func newConnecter(kube client.Client, usage resource.Tracker, log logging.Logger, rec event.Recorder, skipLateInit bool) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log, rec: rec, skipLateInit: skipLateInit}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+	rec   event.Recorder
+
+	skipLateInit bool
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -107,29 +188,107 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Configuration parameters are applied by the server when a session
+	// starts, so clients of a pooler in transaction mode may not see them.
+	if postgresql.TransactionPooling(pc) && (cr.Spec.ForProvider.ConfigurationParameters != nil && len(*cr.Spec.ForProvider.ConfigurationParameters) > 0 || cr.Spec.ForProvider.Audit != nil) {
+		c.rec.Event(cr, event.Warning(reasonSessionState, errors.New(errSessionParameters)))
 	}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
 	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
 
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
+	}
+
+	cd, err := c.caCertDetails(ctx, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	db := xsql.WithConnectionDetails(c.newDB(creds, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), cd)
 	return &external{
-		db:   c.newDB(s.Data, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode)),
+		db:   xsql.Instrument(xsql.WithConnectionLimits(db, postgresql.ConnectionLimits(pc)), c.log, v1alpha1.RoleKind, cr),
 		kube: c.kube,
+		self: string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+
+		skipLateInit: c.skipLateInit,
 	}, nil
 }
 
+// caCertDetails returns the CA bundle to publish in connection secrets, if
+// the supplied ProviderConfig references one and requires TLS.
+func (c *connector) caCertDetails(ctx context.Context, pc *v1alpha1.ProviderConfig) (managed.ConnectionDetails, error) {
+	ref := pc.Spec.CACertSecretRef
+	if ref == nil || !postgresql.RequiresTLS(clients.ToString(pc.Spec.SSLMode)) {
+		return nil, nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetCACert)
+	}
+	ca, ok := s.Data[ref.Key]
+	if !ok {
+		return nil, errors.Errorf(errNoCACertKey, ref.Key)
+	}
+	return managed.ConnectionDetails{xsql.CACertKey: ca}, nil
+}
+
 type external struct {
 	db   xsql.DB
 	kube client.Client
+
+	// self is the role the provider connects as.
+	self string
+
+	// skipLateInit leaves the privileges and connection limit that are
+	// omitted from the spec out of it.
+	skipLateInit bool
+
+	// secrets caches the Secrets read during a reconcile, so that Observe
+	// and Update read the password and connection Secrets at most once.
+	secrets map[types.NamespacedName]*corev1.Secret
+
+	// unknownParameters are the desired configuration parameters that the
+	// server does not know, as found by the last observation.
+	unknownParameters []string
+
+	// adopt is true if the last observation found an existing role that is
+	// to be adopted, i.e. marked as owned, by the next update.
+	adopt bool
+
+	// owned is true if the last observation found this resource recorded
+	// as the owner of the role.
+	owned bool
+
+	// grantMembers and revokeMembers are the members that the last
+	// observation found to be missing from, or not wanted in, the role.
+	grantMembers  []string
+	revokeMembers []string
+
+	// renameFrom is the name the last observation found the role under, if
+	// its external name changed and the next update is to rename it.
+	renameFrom string
+
+	// audit is the configuration of the parameters managed by the audit
+	// settings of the role, as found by the last observation.
+	audit map[string]string
 }
 
 func negateClause(clause string, negate *bool, out *[]string) {
@@ -189,6 +348,9 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotRole)
 	}
+	if err := validateAudit(cr.Spec.ForProvider); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
 	observed := &v1alpha1.RoleParameters{
 		Privileges: v1alpha1.RolePrivilege{
@@ -202,36 +364,63 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		},
 	}
 
+	// The role, its members and the desired configuration parameters the
+	// server does not know are read in one round trip, so that observing
+	// many roles costs a single query each.
 	query := "SELECT " +
-		"rolsuper, " +
-		"rolinherit, " +
-		"rolcreatedb, " +
-		"rolcreaterole, " +
-		"rolcanlogin, " +
-		"rolreplication, " +
-		"rolbypassrls, " +
-		"rolconnlimit, " +
-		"rolconfig " +
-		"FROM pg_roles WHERE rolname = $1"
-
-	var rolconfigs []string
-	err := c.db.Scan(ctx,
-		xsql.Query{
-			String: query,
-			Parameters: []interface{}{
-				meta.GetExternalName(cr),
+		"r.rolsuper, " +
+		"r.rolinherit, " +
+		"r.rolcreatedb, " +
+		"r.rolcreaterole, " +
+		"r.rolcanlogin, " +
+		"r.rolreplication, " +
+		"r.rolbypassrls, " +
+		"r.rolconnlimit, " +
+		"r.rolconfig, " +
+		"COALESCE(shobj_description(r.oid, 'pg_authid'), ''), " +
+		"ARRAY(SELECT DISTINCT m.rolname FROM pg_auth_members AS am JOIN pg_roles AS m ON m.oid = am.member " +
+		"WHERE am.roleid = r.oid ORDER BY m.rolname), " +
+		"ARRAY(SELECT p.name FROM unnest($2::text[]) AS p(name) " +
+		"WHERE NOT EXISTS (SELECT 1 FROM pg_settings s WHERE s.name = lower(p.name)) ORDER BY p.name) " +
+		"FROM pg_roles AS r WHERE r.rolname = $1"
+
+	var rolconfigs, members []string
+	var comment string
+	scan := func(name string) error {
+		return c.db.Scan(ctx,
+			xsql.Query{
+				String: query,
+				Parameters: []interface{}{
+					name,
+					pq.Array(validatedParameters(cr.Spec.ForProvider.ConfigurationParameters)),
+				},
 			},
-		},
-		&observed.Privileges.SuperUser,
-		&observed.Privileges.Inherit,
-		&observed.Privileges.CreateDb,
-		&observed.Privileges.CreateRole,
-		&observed.Privileges.Login,
-		&observed.Privileges.Replication,
-		&observed.Privileges.BypassRls,
-		&observed.ConnectionLimit,
-		pq.Array(&rolconfigs),
-	)
+			&observed.Privileges.SuperUser,
+			&observed.Privileges.Inherit,
+			&observed.Privileges.CreateDb,
+			&observed.Privileges.CreateRole,
+			&observed.Privileges.Login,
+			&observed.Privileges.Replication,
+			&observed.Privileges.BypassRls,
+			&observed.ConnectionLimit,
+			pq.Array(&rolconfigs),
+			&comment,
+			pq.Array(&members),
+			pq.Array(&c.unknownParameters),
+		)
+	}
+
+	// A role that is not found under its external name, but under the name
+	// it was last observed with, is renamed by the next update rather than
+	// created anew, which keeps its password, memberships and privileges.
+	name := meta.GetExternalName(cr)
+	c.renameFrom = ""
+	err := scan(name)
+	if last := cr.Status.AtProvider.Name; xsql.IsNoRows(err) && last != "" && last != name {
+		if err = scan(last); err == nil {
+			c.renameFrom = last
+		}
+	}
 
 	if xsql.IsNoRows(err) {
 		return managed.ExternalObservation{ResourceExists: false}, nil
@@ -239,6 +428,19 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectRole)
 	}
+
+	// The parameters that audit settings manage are observed as such, and
+	// left out of the configuration parameters they are compared to.
+	c.audit = nil
+	cr.Status.AtProvider.Audit = nil
+	if cr.Spec.ForProvider.Audit != nil {
+		c.audit = postgresql.ObservedAuditConfiguration(rolconfigs)
+		cr.Status.AtProvider.Audit = postgresql.AuditSettingsFromConfiguration(c.audit)
+		rolconfigs = slices.DeleteFunc(rolconfigs, func(kv string) bool {
+			name, _, _ := strings.Cut(kv, "=")
+			return postgresql.IsAuditParameter(name)
+		})
+	}
 	if len(rolconfigs) > 0 {
 		var rc []v1alpha1.RoleConfigurationParameter
 		for _, c := range rolconfigs {
@@ -252,29 +454,167 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 	cr.Status.AtProvider.ConfigurationParameters = observed.ConfigurationParameters
 
+	// Refuse to manage a role that records another resource as its owner, so
+	// that two resources (e.g. in different clusters) don't fight over it.
+	owner := xsql.ParseManagedByComment(comment)
+	if xsql.IsManagedByOther(owner, cr) {
+		return managed.ExternalObservation{}, errors.Errorf(errManagedByOther, owner)
+	}
+
+	// A role that exists but records no owner was created outside of the
+	// provider. Adopting it only records this resource as its owner; its
+	// password is left as is unless a PasswordSecretRef is given.
+	c.adopt = owner == "" && ptr.Deref(cr.Spec.ForProvider.AdoptExisting, false)
+	c.owned = owner != ""
+
+	// The line that records the owner is not part of the comment.
+	cr.Status.AtProvider.Comment = xsql.StripManagedByComment(comment)
+	observed.Comment = &cr.Status.AtProvider.Comment
+
 	_, pwdChanged, err := c.getPassword(ctx, cr)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
 
-	cr.SetConditions(xpv1.Available())
+	c.grantMembers, c.revokeMembers = c.diffMembers(cr, members)
 
 	// PrivilegesAsClauses is used as role status output
 	cr.Status.AtProvider.PrivilegesAsClauses = privilegesToClauses(observed.Privileges)
 
+	if c.renameFrom == "" {
+		cr.Status.AtProvider.Name = name
+	}
+
+	// Privileges that are omitted from the spec are compared as observed
+	// even when they are not late-initialized.
+	li := false
+	desired := cr.Spec.ForProvider
+	if !c.skipLateInit {
+		li = lateInit(observed, &cr.Spec.ForProvider)
+		desired = cr.Spec.ForProvider
+	} else {
+		lateInit(observed, &desired)
+	}
+	cr.SetConditions(xpv1.Available())
+	if len(c.unknownParameters) > 0 {
+		// Applying the configuration parameters would fail on every
+		// reconcile, so we report them instead and leave them as they are.
+		cr.SetConditions(xpv1.Unavailable().WithMessage(fmt.Sprintf(errUnknownParameters, strings.Join(c.unknownParameters, ", "))))
+		desired.ConfigurationParameters = observed.ConfigurationParameters
+	}
+
 	return managed.ExternalObservation{
 		ResourceExists:          true,
-		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
-		ResourceUpToDate:        !pwdChanged && upToDate(observed, &cr.Spec.ForProvider),
+		ResourceLateInitialized: li,
+		ResourceUpToDate:        c.renameFrom == "" && !c.adopt && !pwdChanged && len(c.grantMembers) == 0 && len(c.revokeMembers) == 0 && upToDate(observed, &desired) && len(c.auditQueries(cr, c.audit)) == 0,
 	}, nil
 }
 
+// diffMembers records the supplied observed members of a role in its status
+// if members are listed in its spec, and returns the listed members that are
+// missing and the unlisted members that are to be revoked. The role the
+// provider connects as is never revoked, since PostgreSQL 16 makes the
+// creator of a role a member of it so that it can administer the role.
+func (c *external) diffMembers(cr *v1alpha1.Role, observed []string) (grant, revoke []string) {
+	desired := cr.Spec.ForProvider.Members
+	if len(desired) == 0 {
+		cr.Status.AtProvider.Members = nil
+		return nil, nil
+	}
+	cr.Status.AtProvider.Members = observed
+
+	for _, m := range desired {
+		if !slices.Contains(observed, m) {
+			grant = append(grant, m)
+		}
+	}
+	if ptr.Deref(cr.Spec.ForProvider.KeepUnmanagedMembers, false) {
+		return grant, nil
+	}
+	for _, m := range observed {
+		if m != c.self && !slices.Contains(desired, m) {
+			revoke = append(revoke, m)
+		}
+	}
+	return grant, revoke
+}
+
+// updateMembers grants the supplied role to, and revokes it from, the
+// supplied members.
+func (c *external) updateMembers(ctx context.Context, role string, grant, revoke []string) error {
+	if len(grant) > 0 {
+		if err := c.db.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("GRANT %s TO %s", role, quoteIdentifiers(grant)),
+		}); err != nil {
+			return errors.Wrap(err, errGrantMembers)
+		}
+	}
+	if len(revoke) > 0 {
+		if err := c.db.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("REVOKE %s FROM %s", role, quoteIdentifiers(revoke)),
+		}); err != nil {
+			return errors.Wrap(err, errRevokeMembers)
+		}
+	}
+	return nil
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = pq.QuoteIdentifier(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// validatedParameters returns the names of the supplied configuration
+// parameters that are validated against pg_settings. Customized options, i.e.
+// those with a dot in their name, are not validated because their
+// placeholders only exist once a session or extension defines them.
+func validatedParameters(params *[]v1alpha1.RoleConfigurationParameter) []string {
+	if params == nil {
+		return nil
+	}
+	names := make([]string, 0, len(*params))
+	for _, p := range *params {
+		if !strings.Contains(p.Name, ".") {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func (c *external) setOwner(ctx context.Context, cr *v1alpha1.Role) error {
+	return errors.Wrap(c.setComment(ctx, cr, true), errSetRoleOwner)
+}
+
+// setComment sets the comment of the supplied role, followed by the line
+// that records this resource as its owner if owned is true. The observed
+// comment is kept if the role specifies none.
+func (c *external) setComment(ctx context.Context, cr *v1alpha1.Role, owned bool) error {
+	comment := ptr.Deref(cr.Spec.ForProvider.Comment, cr.Status.AtProvider.Comment)
+	if owned {
+		comment = xsql.CommentWithManagedBy(comment, cr.GetName())
+	}
+	literal := "NULL"
+	if comment != "" {
+		literal = pq.QuoteLiteral(comment)
+	}
+	return c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("COMMENT ON ROLE %s IS %s", pq.QuoteIdentifier(meta.GetExternalName(cr)), literal),
+	})
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Role)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotRole)
 	}
 
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), postgresql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
 	cr.SetConditions(xpv1.Creating())
 
 	crn := pq.QuoteIdentifier(meta.GetExternalName(cr))
@@ -285,6 +625,12 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, err
 	}
 
+	if isHashed(cr) {
+		if err := validateVerifier(pw); err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
 	if pw == "" {
 		pw, err = password.Generate()
 		if err != nil {
@@ -305,6 +651,17 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRole)
 	}
 
+	if err := c.setOwner(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if isHashed(cr) {
+		cr.Status.AtProvider.PasswordVerifierDigest = verifierDigest(pw)
+	}
+
+	if err := c.updateMembers(ctx, crn, cr.Spec.ForProvider.Members, nil); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	// PrivilegesAsClauses is used as role status output
 	// Update here so that state is reflected to the user prior to the next
 	// reconciler loop.
@@ -319,12 +676,26 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 		cr.Status.AtProvider.ConfigurationParameters = cr.Spec.ForProvider.ConfigurationParameters
 	}
+	if ql := c.auditQueries(cr, nil); len(ql) > 0 {
+		if err := c.db.ExecTx(ctx, ql); err != nil {
+			return managed.ExternalCreation{}, errors.Wrap(err, errSetRoleAudit)
+		}
+	}
 
 	return managed.ExternalCreation{
-		ConnectionDetails: c.db.GetConnectionDetails(meta.GetExternalName(cr), pw),
+		ConnectionDetails: connectionDetails(c.db.GetConnectionDetails(meta.GetExternalName(cr), pw), cr),
 	}, nil
 }
 
+// connectionDetails omits the password from the supplied connection details
+// of a role with a hashed password, whose password is not known.
+func connectionDetails(cd managed.ConnectionDetails, cr *v1alpha1.Role) managed.ConnectionDetails {
+	if isHashed(cr) {
+		delete(cd, xpv1.ResourceCredentialsSecretPasswordKey)
+	}
+	return cd
+}
+
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) { //nolint:gocyclo
 	// NOTE(benagricola): This is just a touch over the cyclomatic complexity
 	// limit, but is unlikely to become more complex unless new role features
@@ -335,6 +706,12 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotRole)
 	}
 
+	// Altering the role the provider connects as could revoke the
+	// privileges, or change the password, the provider relies on.
+	if err := xsql.CheckReserved(cr, "role", meta.GetExternalName(cr), c.isSelf(cr)); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	pw, pwchanged, err := c.getPassword(ctx, cr)
 	if err != nil {
 		return managed.ExternalUpdate{}, err
@@ -342,12 +719,41 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	crn := pq.QuoteIdentifier(meta.GetExternalName(cr))
 
+	if c.renameFrom != "" {
+		// Renaming clears an MD5 password, which is salted with the name
+		// of the role, so it is set again under the new name.
+		if pw, err = c.rename(ctx, cr, pw); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		pwchanged = pw != ""
+	}
+
+	switch comment := cr.Spec.ForProvider.Comment; {
+	case c.adopt:
+		if err := c.setOwner(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	case comment != nil && *comment != cr.Status.AtProvider.Comment:
+		if err := c.setComment(ctx, cr, c.owned); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errSetRoleComment)
+		}
+		cr.Status.AtProvider.Comment = *comment
+	}
+
 	if pwchanged {
+		if isHashed(cr) {
+			if err := validateVerifier(pw); err != nil {
+				return managed.ExternalUpdate{}, err
+			}
+		}
 		if err := c.db.Exec(ctx, xsql.Query{
 			String: fmt.Sprintf("ALTER ROLE %s PASSWORD %s", crn, pq.QuoteLiteral(pw)),
 		}); err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRole)
 		}
+		if isHashed(cr) {
+			cr.Status.AtProvider.PasswordVerifierDigest = verifierDigest(pw)
+		}
 	}
 
 	privs := privilegesToClauses(cr.Spec.ForProvider.Privileges)
@@ -372,7 +778,7 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	// Checks if current role configuration parameters differs from desired state.
 	// If difference, reset all parameters and apply desired parameters in a transaction
-	if cr.Spec.ForProvider.ConfigurationParameters != nil && !cmp.Equal(cr.Status.AtProvider.ConfigurationParameters, cr.Spec.ForProvider.ConfigurationParameters,
+	if cr.Spec.ForProvider.ConfigurationParameters != nil && len(c.unknownParameters) == 0 && !cmp.Equal(cr.Status.AtProvider.ConfigurationParameters, cr.Spec.ForProvider.ConfigurationParameters,
 		cmpopts.SortSlices(func(o, d v1alpha1.RoleConfigurationParameter) bool { return o.Name < d.Name })) {
 		q := make([]xsql.Query, 0)
 		q = append(q, xsql.Query{
@@ -392,11 +798,17 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 				String: fmt.Sprintf("ALTER ROLE %s set %s=%s", crn, pq.QuoteIdentifier(v.Name), sb.String()),
 			})
 		}
+		// Resetting all parameters resets those of the audit settings too.
+		q = append(q, c.auditQueries(cr, nil)...)
 		if err := c.db.ExecTx(ctx, q); err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRole)
 		}
 		// Update state to reflect the current configuration parameters
 		cr.Status.AtProvider.ConfigurationParameters = cr.Spec.ForProvider.ConfigurationParameters
+	} else if ql := c.auditQueries(cr, c.audit); len(ql) > 0 {
+		if err := c.db.ExecTx(ctx, ql); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errSetRoleAudit)
+		}
 	}
 	cl := cr.Spec.ForProvider.ConnectionLimit
 	if cl != nil {
@@ -407,27 +819,102 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
+	if err := c.updateMembers(ctx, crn, c.grantMembers, c.revokeMembers); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	// Only update connection details if password is changed
 	if pwchanged {
 		return managed.ExternalUpdate{
-			ConnectionDetails: c.db.GetConnectionDetails(meta.GetExternalName(cr), pw),
+			ConnectionDetails: connectionDetails(c.db.GetConnectionDetails(meta.GetExternalName(cr), pw), cr),
 		}, nil
 	}
 	return managed.ExternalUpdate{}, nil
 }
 
+// rename renames the role from the name it was last observed with to its
+// external name. It returns the supplied password, or else the one in the
+// connection secret of the role, which is to be set again.
+func (c *external) rename(ctx context.Context, cr *v1alpha1.Role, pw string) (string, error) {
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), postgresql.IdentifierLimit); err != nil {
+		return "", errors.Wrap(err, errInvalidName)
+	}
+	if err := xsql.CheckReserved(cr, "role", c.renameFrom, c.self != "" && c.renameFrom == c.self); err != nil {
+		return "", err
+	}
+
+	if err := c.db.Exec(ctx, xsql.Query{
+		String: fmt.Sprintf("ALTER ROLE %s RENAME TO %s", pq.QuoteIdentifier(c.renameFrom), pq.QuoteIdentifier(meta.GetExternalName(cr))),
+	}); err != nil {
+		return "", errors.Wrap(err, errRenameRole)
+	}
+	cr.Status.AtProvider.Name = meta.GetExternalName(cr)
+	c.renameFrom = ""
+
+	if pw == "" && cr.Spec.WriteConnectionSecretToReference != nil {
+		ref := cr.Spec.WriteConnectionSecretToReference
+		s, err := c.getSecret(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+		if resource.IgnoreNotFound(err) != nil {
+			return "", errors.Wrap(err, errGetConnectionSecret)
+		}
+		pw = string(s.Data[xpv1.ResourceCredentialsSecretPasswordKey])
+	}
+	return pw, nil
+}
+
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	cr, ok := mg.(*v1alpha1.Role)
 	if !ok {
 		return errors.New(errNotRole)
 	}
+	if err := xsql.CheckReserved(cr, "role", meta.GetExternalName(cr), c.isSelf(cr)); err != nil {
+		return err
+	}
 	cr.SetConditions(xpv1.Deleting())
+
+	// A role that was not renamed yet is dropped under its old name.
+	name := meta.GetExternalName(cr)
+	if c.renameFrom != "" {
+		name = c.renameFrom
+	}
 	err := c.db.Exec(ctx, xsql.Query{
-		String: "DROP ROLE IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr)),
+		String: "DROP ROLE IF EXISTS " + pq.QuoteIdentifier(name),
 	})
 	return errors.Wrap(err, errDropRole)
 }
 
+// auditQueries returns the statements that change the supplied observed
+// configuration of the parameters that the audit settings of the role
+// manage to the desired one. They are left untouched if it specifies no
+// audit settings.
+func (c *external) auditQueries(cr *v1alpha1.Role, observed map[string]string) []xsql.Query {
+	if cr.Spec.ForProvider.Audit == nil {
+		return nil
+	}
+	object := "ROLE " + pq.QuoteIdentifier(meta.GetExternalName(cr))
+	return postgresql.AuditQueries(object, postgresql.AuditConfiguration(cr.Spec.ForProvider.Audit), observed)
+}
+
+// validateAudit returns an error if the supplied configuration parameters
+// set a parameter that the supplied audit settings manage.
+func validateAudit(p v1alpha1.RoleParameters) error {
+	if p.Audit == nil || p.ConfigurationParameters == nil {
+		return nil
+	}
+	for _, v := range *p.ConfigurationParameters {
+		if postgresql.IsAuditParameter(v.Name) {
+			return errors.Errorf(errAuditParameter, v.Name)
+		}
+	}
+	return nil
+}
+
+// isSelf returns true if the supplied role is the one the provider connects
+// as.
+func (c *external) isSelf(cr *v1alpha1.Role) bool {
+	return c.self != "" && meta.GetExternalName(cr) == c.self
+}
+
 func upToDate(observed *v1alpha1.RoleParameters, desired *v1alpha1.RoleParameters) bool {
 	if observed.ConnectionLimit != desired.ConnectionLimit {
 		return false
@@ -457,6 +944,9 @@ func upToDate(observed *v1alpha1.RoleParameters, desired *v1alpha1.RoleParameter
 		cmpopts.SortSlices(func(o, d v1alpha1.RoleConfigurationParameter) bool { return o.Name < d.Name })) {
 		return false
 	}
+	if desired.Comment != nil && *desired.Comment != ptr.Deref(observed.Comment, "") {
+		return false
+	}
 	return true
 }
 
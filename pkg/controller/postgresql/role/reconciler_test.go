@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
@@ -29,15 +30,18 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -78,7 +82,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+		newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -128,6 +132,30 @@ func TestConnect(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errGetPC),
 		},
+		"ErrInvalidConnectionOptions": {
+			reason: "An error should be returned if our ProviderConfig specifies unsupported connection options",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.ConnectionOptions = map[string]string{"sslmode": "disable"}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(postgresql.ValidateConnectionOptions(map[string]string{"sslmode": "disable"}), errConnOpts),
+		},
 		"ErrMissingConnectionSecret": {
 			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
 			fields: fields{
@@ -177,6 +205,37 @@ func TestConnect(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errGetSecret),
 		},
+		"ErrGetCACertSecret": {
+			reason: "An error should be returned if we can't get the CA certificate Secret to publish",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "creds"}
+							o.Spec.SSLMode = ptr.To("verify-full")
+							o.Spec.CACertSecretRef = &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "ca"}, Key: "ca.crt"}
+						case *corev1.Secret:
+							if key.Name == "ca" {
+								return errBoom
+							}
+						}
+						return nil
+					},
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errGetCACert),
+		},
 	}
 
 	for name, tc := range cases {
@@ -190,6 +249,75 @@ func TestConnect(t *testing.T) {
 	}
 }
 
+// recorder records the events of managed resources.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestConnectTransactionPooling(t *testing.T) {
+	params := &[]v1alpha1.RoleConfigurationParameter{{Name: "statement_timeout", Value: "30s"}}
+
+	cases := map[string]struct {
+		reason string
+		pooler *string
+		params *[]v1alpha1.RoleConfigurationParameter
+		want   []event.Event
+	}{
+		"ConfigurationParameters": {
+			reason: "A warning should be recorded for configuration parameters of Roles behind a pooler in transaction mode",
+			pooler: ptr.To(v1alpha1.PoolerPgBouncerTransaction),
+			params: params,
+			want:   []event.Event{event.Warning(reasonSessionState, errors.New(errSessionParameters))},
+		},
+		"NoConfigurationParameters": {
+			reason: "No warning should be recorded for Roles without configuration parameters",
+			pooler: ptr.To(v1alpha1.PoolerPgBouncerTransaction),
+		},
+		"NoPooler": {
+			reason: "No warning should be recorded for Roles that connect directly to the server",
+			params: params,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{}
+			c := &connector{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Pooler = tc.pooler
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				rec:   rec,
+			}
+			cr := &v1alpha1.Role{Spec: v1alpha1.RoleSpec{
+				ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{}},
+				ForProvider:  v1alpha1.RoleParameters{ConfigurationParameters: tc.params},
+			}}
+			// The ProviderConfig references no credentials Secret, so Connect
+			// returns once the warning has been recorded.
+			if _, err := c.Connect(context.Background(), cr); err == nil {
+				t.Fatalf("\n%s\nc.Connect(...): want an error", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want, rec.events); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestObserve(t *testing.T) {
 	errBoom := errors.New("boom")
 
@@ -204,8 +332,9 @@ func TestObserve(t *testing.T) {
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o         managed.ExternalObservation
+		condition *xpv1.Condition
+		err       error
 	}
 
 	cases := map[string]struct {
@@ -277,6 +406,52 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrManagedByOther": {
+			reason: "We should return an error if the role is owned by another resource",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[9].(*string) = xsql.ManagedByComment("other")
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "mine",
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errManagedByOther, xsql.ManagedBy("other")),
+			},
+		},
+		"SuccessManagedBySelf": {
+			reason: "We should return no error if the role is owned by this resource",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[9].(*string) = xsql.ManagedByComment("mine")
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "mine",
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+			},
+		},
 		"PasswordChanged": {
 			reason: "We should return ResourceUpToDate=false if the password changed",
 			fields: fields{
@@ -354,6 +529,46 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"UnknownConfigurationParameters": {
+			reason: "Unknown configuration parameters should be reported in a condition rather than applied",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if diff := cmp.Diff(pq.Array([]string{"idle_in_transaction_session_timout"}), q.Parameters[1]); diff != "" {
+							return errors.Errorf("unexpected parameters: %s", diff)
+						}
+						*dest[11].(*pq.StringArray) = pq.StringArray{"idle_in_transaction_session_timout"}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							ConfigurationParameters: &[]v1alpha1.RoleConfigurationParameter{
+								{
+									Name:  "idle_in_transaction_session_timout",
+									Value: "60s",
+								},
+								{
+									Name:  "pgaudit.log",
+									Value: "All",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+				condition: ptr.To(xpv1.Unavailable().WithMessage("unknown configuration parameters: idle_in_transaction_session_timout")),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -369,6 +584,12 @@ func TestObserve(t *testing.T) {
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if tc.want.condition != nil {
+				cr := tc.args.mg.(*v1alpha1.Role)
+				if diff := cmp.Diff(*tc.want.condition, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+				}
+			}
 		})
 	}
 }
@@ -421,6 +642,25 @@ func TestCreate(t *testing.T) {
 				err: errors.Wrap(errBoom, errCreateRole),
 			},
 		},
+		"ErrSetOwner": {
+			reason: "Any errors encountered while recording the owner of the role should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "COMMENT ON ROLE") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSetRoleOwner),
+			},
+		},
 		"Success": {
 			reason: "No error should be returned when we successfully create a role",
 			fields: fields{
@@ -549,7 +789,8 @@ func TestUpdate(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
-		db xsql.DB
+		db                xsql.DB
+		unknownParameters []string
 	}
 
 	type args struct {
@@ -1057,13 +1298,47 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"NoUpdateQueryUnknownConfigurationParameters": {
+			reason: "We should not try to set configuration parameters the server does not know.",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, q []xsql.Query) error {
+						return errBoom
+					},
+				},
+				unknownParameters: []string{"statment_timeout"},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							ConfigurationParameters: &[]v1alpha1.RoleConfigurationParameter{
+								{
+									Name:  "statment_timeout",
+									Value: "123",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			e := external{
-				db:   tc.fields.db,
-				kube: tc.args.kube,
+				db:                tc.fields.db,
+				kube:              tc.args.kube,
+				unknownParameters: tc.fields.unknownParameters,
 			}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1128,11 +1403,47 @@ func TestDelete(t *testing.T) {
 				mg: &v1alpha1.Role{},
 			},
 		},
+		"ErrSelf": {
+			reason: "The role the provider connects as should not be dropped",
+			fields: fields{
+				db: &mockDB{},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "provider",
+						},
+					},
+				},
+			},
+			want: xsql.CheckReserved(&v1.ObjectMeta{}, "role", "provider", true),
+		},
+		"SuccessSelfOverride": {
+			reason: "The role the provider connects as should be dropped if the override annotation is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName:   "provider",
+							xsql.AnnotationKeyManageReserved: "true",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, self: "provider"}
 			err := e.Delete(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -1140,3 +1451,499 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAdoptExisting(t *testing.T) {
+	var queries []string
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			// The role exists and records no owner.
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "example",
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ForProvider: v1alpha1.RoleParameters{
+				AdoptExisting: ptr.To(true),
+			},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want an existing role that is not up to date, got %+v", o)
+	}
+
+	u, err := e.Update(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{`COMMENT ON ROLE "example" IS 'managed-by: crossplane/example'`}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+	if u.ConnectionDetails != nil {
+		t.Errorf("e.Update(...): want no connection details for an adopted role, got %v", u.ConnectionDetails)
+	}
+}
+
+func TestComment(t *testing.T) {
+	var queries []string
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[9].(*string) = "old\n" + xsql.ManagedByComment("example")
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "example",
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ForProvider: v1alpha1.RoleParameters{
+				Comment: ptr.To("new"),
+			},
+		},
+	}
+
+	e := external{db: db}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a role whose comment changed not to be up to date")
+	}
+	if cr.Status.AtProvider.Comment != "old" {
+		t.Errorf("e.Observe(...): want comment %q without the owner, got %q", "old", cr.Status.AtProvider.Comment)
+	}
+
+	// The owner is still recorded after the comment.
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{"COMMENT ON ROLE \"example\" IS 'new\nmanaged-by: crossplane/example'"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+}
+
+func TestManageMembers(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		keep   *bool
+		want   []string
+	}{
+		"Converge": {
+			reason: "Missing members should be granted, and unlisted ones other than the provider's own role revoked",
+			want: []string{
+				`GRANT "group" TO "bob"`,
+				`REVOKE "group" FROM "mallory"`,
+			},
+		},
+		"KeepUnmanaged": {
+			reason: "Only missing members should be granted if unmanaged members are kept",
+			keep:   ptr.To(true),
+			want: []string{
+				`GRANT "group" TO "bob"`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db := &mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					*dest[10].(*pq.StringArray) = pq.StringArray{"alice", "crossplane", "mallory"}
+					return nil
+				},
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return nil
+				},
+			}
+			cr := &v1alpha1.Role{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						meta.AnnotationKeyExternalName: "group",
+					},
+				},
+				Spec: v1alpha1.RoleSpec{
+					ForProvider: v1alpha1.RoleParameters{
+						Members:              []string{"alice", "bob"},
+						KeepUnmanagedMembers: tc.keep,
+					},
+				},
+			}
+
+			e := external{db: db, self: "crossplane"}
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %v", tc.reason, err)
+			}
+			if o.ResourceUpToDate {
+				t.Fatalf("\n%s\ne.Observe(...): want a role with missing members not to be up to date", tc.reason)
+			}
+			if diff := cmp.Diff([]string{"alice", "crossplane", "mallory"}, cr.Status.AtProvider.Members); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status.atProvider.members, +got:\n%s", tc.reason, diff)
+			}
+
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		rolconfig []string
+		audit     *v1alpha1.AuditSettings
+		observed  *v1alpha1.AuditSettings
+		want      []string
+	}{
+		"Converge": {
+			reason:    "Audit settings that differ should be set, and omitted ones reset, leaving other parameters untouched",
+			rolconfig: []string{"search_path=app", "pgaudit.log=read", "pgaudit.log_parameter=on"},
+			audit: &v1alpha1.AuditSettings{
+				Log:         []v1alpha1.AuditLogClass{"WRITE", "DDL"},
+				LogRelation: ptr.To(true),
+			},
+			observed: &v1alpha1.AuditSettings{
+				Log:          []v1alpha1.AuditLogClass{"READ"},
+				LogParameter: ptr.To(true),
+			},
+			want: []string{
+				`ALTER ROLE "auditor" SET pgaudit.log = 'ddl,write'`,
+				`ALTER ROLE "auditor" RESET pgaudit.log_parameter`,
+				`ALTER ROLE "auditor" SET pgaudit.log_relation = 'on'`,
+			},
+		},
+		"UpToDate": {
+			reason:    "Audit settings that were set with different spelling or order should be up to date",
+			rolconfig: []string{"PGAUDIT.LOG=Write, DDL", "pgaudit.log_relation=true"},
+			audit: &v1alpha1.AuditSettings{
+				Log:         []v1alpha1.AuditLogClass{"DDL", "WRITE"},
+				LogRelation: ptr.To(true),
+			},
+			observed: &v1alpha1.AuditSettings{
+				Log:         []v1alpha1.AuditLogClass{"DDL", "WRITE"},
+				LogRelation: ptr.To(true),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			db := &mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					*dest[8].(*pq.StringArray) = tc.rolconfig
+					return nil
+				},
+				MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+					for _, q := range ql {
+						queries = append(queries, q.String)
+					}
+					return nil
+				},
+			}
+			cr := &v1alpha1.Role{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						meta.AnnotationKeyExternalName: "auditor",
+					},
+				},
+				Spec: v1alpha1.RoleSpec{
+					ForProvider: v1alpha1.RoleParameters{
+						Audit: tc.audit,
+					},
+				},
+			}
+
+			e := external{db: db}
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %v", tc.reason, err)
+			}
+			if want := len(tc.want) == 0; o.ResourceUpToDate != want {
+				t.Errorf("\n%s\ne.Observe(...): want ResourceUpToDate %t, got %t", tc.reason, want, o.ResourceUpToDate)
+			}
+			if diff := cmp.Diff(tc.observed, cr.Status.AtProvider.Audit); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status.atProvider.audit, +got:\n%s", tc.reason, diff)
+			}
+			want := &[]v1alpha1.RoleConfigurationParameter{{Name: "search_path", Value: "app"}}
+			if len(tc.want) == 0 {
+				want = nil
+			}
+			if diff := cmp.Diff(want, cr.Status.AtProvider.ConfigurationParameters); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status.atProvider.configurationParameters, +got:\n%s", tc.reason, diff)
+			}
+
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, queries); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want queries, +got queries:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAuditParameterConflict(t *testing.T) {
+	cr := &v1alpha1.Role{
+		Spec: v1alpha1.RoleSpec{
+			ForProvider: v1alpha1.RoleParameters{
+				ConfigurationParameters: &[]v1alpha1.RoleConfigurationParameter{{Name: "pgaudit.log", Value: "all"}},
+				Audit:                   &v1alpha1.AuditSettings{LogRelation: ptr.To(true)},
+			},
+		},
+	}
+	e := external{db: &mockDB{}}
+	_, err := e.Observe(context.Background(), cr)
+	if diff := cmp.Diff(errors.Errorf(errAuditParameter, "pgaudit.log"), err, test.EquateErrors()); diff != "" {
+		t.Errorf("e.Observe(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestSecretsReadOnce(t *testing.T) {
+	gets := map[string]int{}
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			gets[key.Name]++
+			secret := corev1.Secret{
+				Data: map[string][]byte{
+					"new-password": []byte("new"),
+					xpv1.ResourceCredentialsSecretPasswordKey: []byte("old"),
+				},
+			}
+			secret.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+	}
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+		MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+	}
+	cr := &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "example",
+			},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ForProvider: v1alpha1.RoleParameters{
+				PasswordSecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Name: "password"},
+					Key:             "new-password",
+				},
+			},
+			ResourceSpec: xpv1.ResourceSpec{
+				WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "connection"},
+			},
+		},
+	}
+
+	e := external{db: db, kube: kube}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := map[string]int{"password": 1, "connection": 1}
+	if diff := cmp.Diff(want, gets); diff != "" {
+		t.Errorf("Observe and Update: -want Secret reads, +got:\n%s", diff)
+	}
+}
+
+func TestRename(t *testing.T) {
+	var queries []string
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			secret := corev1.Secret{
+				Data: map[string][]byte{
+					xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+				},
+			}
+			secret.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+	}
+	db := &mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if q.Parameters[0] != "old" {
+				return sql.ErrNoRows
+			}
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			queries = append(queries, q.String)
+			return nil
+		},
+	}
+	cr := &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.AnnotationKeyExternalName: "new",
+			},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "connection"},
+			},
+		},
+		Status: v1alpha1.RoleStatus{
+			AtProvider: v1alpha1.RoleObservation{Name: "old"},
+		},
+	}
+
+	e := external{db: db, kube: kube}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want a renamed role that exists and is not up to date, got %+v", o)
+	}
+
+	u, err := e.Update(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	want := []string{
+		`ALTER ROLE "old" RENAME TO "new"`,
+		`ALTER ROLE "new" PASSWORD 'secret'`,
+	}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Errorf("e.Update(...): -want queries, +got queries:\n%s", diff)
+	}
+	if diff := cmp.Diff("new", cr.Status.AtProvider.Name); diff != "" {
+		t.Errorf("e.Update(...): -want status.atProvider.name, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]byte("new"), u.ConnectionDetails[xpv1.ResourceCredentialsSecretUserKey]); diff != "" {
+		t.Errorf("e.Update(...): -want username, +got:\n%s", diff)
+	}
+}
+
+func TestHashedPassword(t *testing.T) {
+	verifier := "SCRAM-SHA-256$4096:c2FsdA==$c3RvcmVk:c2VydmVy"
+
+	cases := map[string]struct {
+		reason   string
+		password string
+		want     error
+	}{
+		"Verifier": {
+			reason:   "A SCRAM verifier should be set as the password as is",
+			password: verifier,
+		},
+		"NotVerifier": {
+			reason:   "A password that is not a SCRAM verifier should be refused",
+			password: "plaintext",
+			want:     errors.New(errNotVerifier),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					secret := corev1.Secret{Data: map[string][]byte{"verifier": []byte(tc.password)}}
+					secret.DeepCopyInto(obj.(*corev1.Secret))
+					return nil
+				},
+			}
+			db := &mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					queries = append(queries, q.String)
+					return nil
+				},
+			}
+			cr := &v1alpha1.Role{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						meta.AnnotationKeyExternalName: "example",
+					},
+				},
+				Spec: v1alpha1.RoleSpec{
+					ForProvider: v1alpha1.RoleParameters{
+						PasswordSecretRef: &xpv1.SecretKeySelector{
+							SecretReference: xpv1.SecretReference{Name: "example"},
+							Key:             "verifier",
+						},
+						HashedPassword: ptr.To(true),
+					},
+				},
+			}
+
+			e := external{db: db, kube: kube}
+			c, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\ne.Create(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want != nil {
+				return
+			}
+			if !strings.Contains(queries[0], "PASSWORD '"+verifier+"'") {
+				t.Errorf("\n%s\ne.Create(...): want the verifier set as the password, got %q", tc.reason, queries[0])
+			}
+			if _, ok := c.ConnectionDetails[xpv1.ResourceCredentialsSecretPasswordKey]; ok {
+				t.Errorf("\n%s\ne.Create(...): want no password in the connection details", tc.reason)
+			}
+
+			// The verifier is not changed until the secret is.
+			if _, changed, _ := e.getPassword(context.Background(), cr); changed {
+				t.Errorf("\n%s\ne.getPassword(...): want an unchanged verifier after it was set", tc.reason)
+			}
+		})
+	}
+}
+
+func TestLateInitializeDisabled(t *testing.T) {
+	e := external{db: mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+	}, skipLateInit: true}
+	cr := &v1alpha1.Role{}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+
+	// Omitted privileges are left out of the spec, but do not make the role
+	// out of date.
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.Observe(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.RoleParameters{}, cr.Spec.ForProvider); diff != "" {
+		t.Errorf("e.Observe(...): -want spec, +got spec:\n%s", diff)
+	}
+}
@@ -18,9 +18,13 @@ package role
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 
 	"github.com/pkg/errors"
 
@@ -38,12 +42,19 @@ func (c *external) getPassword(ctx context.Context, role *v1alpha1.Role) (newPwd
 		Name:      role.Spec.ForProvider.PasswordSecretRef.Name,
 		Namespace: role.Spec.ForProvider.PasswordSecretRef.Namespace,
 	}
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, nn, s); err != nil {
+	s, err := c.getSecret(ctx, nn)
+	if err != nil {
 		return "", false, errors.Wrap(err, errGetPasswordSecretFailed)
 	}
 	newPwd = string(s.Data[role.Spec.ForProvider.PasswordSecretRef.Key])
 
+	// The connection secret of a role with a hashed password contains no
+	// password to compare the verifier to, so the digest of the verifier
+	// last set is compared instead.
+	if isHashed(role) {
+		return newPwd, newPwd != "" && verifierDigest(newPwd) != role.Status.AtProvider.PasswordVerifierDigest, nil
+	}
+
 	if role.Spec.WriteConnectionSecretToReference == nil {
 		return newPwd, false, nil
 	}
@@ -52,10 +63,10 @@ func (c *external) getPassword(ctx context.Context, role *v1alpha1.Role) (newPwd
 		Name:      role.Spec.WriteConnectionSecretToReference.Name,
 		Namespace: role.Spec.WriteConnectionSecretToReference.Namespace,
 	}
-	s = &corev1.Secret{}
 	// the output secret may not exist yet, so we can skip returning an
 	// error if the error is NotFound
-	if err := c.kube.Get(ctx, nn, s); resource.IgnoreNotFound(err) != nil {
+	s, err = c.getSecret(ctx, nn)
+	if resource.IgnoreNotFound(err) != nil {
 		return "", false, err
 	}
 	// if newPwd was set to some value, compare value in output secret with
@@ -64,3 +75,45 @@ func (c *external) getPassword(ctx context.Context, role *v1alpha1.Role) (newPwd
 
 	return newPwd, changed, nil
 }
+
+// getSecret gets the supplied Secret, reading it from the API server only the
+// first time it is requested by this client. An empty Secret is returned
+// with any error; Secrets that could not be read are not cached.
+func (c *external) getSecret(ctx context.Context, nn types.NamespacedName) (*corev1.Secret, error) {
+	if s, ok := c.secrets[nn]; ok {
+		return s, nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, nn, s); err != nil {
+		return &corev1.Secret{}, err
+	}
+	if c.secrets == nil {
+		c.secrets = map[types.NamespacedName]*corev1.Secret{}
+	}
+	c.secrets[nn] = s
+	return s, nil
+}
+
+// isHashed returns true if the password of the supplied role is a SCRAM
+// verifier rather than a password.
+func isHashed(role *v1alpha1.Role) bool {
+	return ptr.Deref(role.Spec.ForProvider.HashedPassword, false)
+}
+
+// validateVerifier returns an error unless the supplied password is a
+// SCRAM-SHA-256 verifier, which PostgreSQL stores as is. Anything else would
+// be taken to be a password and hashed.
+func validateVerifier(v string) error {
+	if !strings.HasPrefix(v, scramPrefix) {
+		return errors.New(errNotVerifier)
+	}
+	return nil
+}
+
+// verifierDigest returns the hex encoded SHA-256 digest of the supplied
+// verifier, which is recorded in the status of a role instead of the
+// verifier itself.
+func verifierDigest(v string) string {
+	d := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(d[:])
+}
@@ -18,6 +18,8 @@ package schema
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/lib/pq"
@@ -31,6 +33,8 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -39,20 +43,36 @@ import (
 	"github.com/crossplane-contrib/provider-sql/pkg/clients"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
 )
 
 const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
 	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
 	errGetSecret    = "cannot get credentials Secret"
-
-	errNotSchema    = "managed resource is not a Schema custom resource"
-	errSelectSchema = "cannot select schema"
-	errCreateSchema = "cannot create schema"
-	errDropSchema   = "cannot drop schema"
-	errNoDatabase   = "database must be specified"
-	errAlterSchema  = "cannot alter schema"
+	errGetCreds     = "cannot get ProviderConfig credentials"
+
+	errNotSchema     = "managed resource is not a Schema custom resource"
+	errInvalidName   = "invalid schema name"
+	errSelectSchema  = "cannot select schema"
+	errCreateSchema  = "cannot create schema"
+	errDropSchema    = "cannot drop schema"
+	errNoDatabase    = "database must be specified"
+	errAlterSchema   = "cannot alter schema"
+	errCommentSchema = "cannot set schema comment"
+
+	errSelectDefaultPrivs = "cannot select default privileges"
+	errAlterDefaultPrivs  = "cannot alter default privileges"
+	errSelectOwners       = "cannot select owners of schema objects"
+	errReassignOwners     = "cannot reassign schema objects to the schema owner"
 
 	maxConcurrency = 5
 )
@@ -62,26 +82,45 @@ func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
 	name := managed.ControllerName(v1alpha1.SchemaGroupKind)
 
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.SchemaGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec),
+	}
+
+	// Management policies let an existing schema be adopted with only the
+	// Observe and Update actions, so that it is never created or dropped.
+	if o.Features.Enabled(feature.EnableBetaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.SchemaGroupVersionKind), opts...)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.Schema{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.SchemaGroupVersionKind)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(tracing.NewReconciler(r, v1alpha1.SchemaGroupKind))
+}
+
+// NewConnecter returns a connecter for Schema managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: postgresql.New, log: log}
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+	newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -100,28 +139,90 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
-
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	if err := postgresql.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
 	}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+	// Credentials are read from the connection secret unless the
+	// ProviderConfig sources them from the environment or filesystem.
+	creds, err := clients.ExtractCredentials(pc.Spec.Credentials.Source, pc.Spec.Credentials.Env, pc.Spec.Credentials.Fs)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds == nil {
+		ref := pc.Spec.Credentials.ConnectionSecretRef
+		if ref == nil {
+			return nil, errors.New(errNoSecretRef)
+		}
+
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+		creds = s.Data
 	}
 
 	if cr.Spec.ForProvider.Database == nil {
 		return nil, errors.New(errNoDatabase)
 	}
 
-	return &external{db: c.newDB(s.Data, *cr.Spec.ForProvider.Database, clients.ToString(pc.Spec.SSLMode))}, nil
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(c.newDB(creds, *cr.Spec.ForProvider.Database, clients.ToString(pc.Spec.SSLMode), postgresql.OptionsFromProviderConfig(pc)), postgresql.ConnectionLimits(pc)), c.log, v1alpha1.SchemaKind, cr)}, nil
 }
 
-type external struct{ db xsql.DB }
+type external struct {
+	db xsql.DB
+
+	// defaultPrivs are the default privileges observed for the schema owner,
+	// as returned by defaultPrivilegeKey.
+	defaultPrivs []string
+
+	// reassign are the statements that reassign the objects of the schema
+	// that are not owned by the schema owner, if DefaultOwner is enforced.
+	reassign []string
+}
+
+// reassignQuery returns an ALTER ... OWNER TO statement for every table,
+// view, materialized view, sequence, foreign table, routine and type in
+// schema $1 that is not owned by role $2. Objects that belong to an
+// extension, and sequences that belong to a table column and so follow its
+// owner, are left alone.
+const reassignQuery = "SELECT COALESCE(array_agg(o.s ORDER BY o.s), '{}') FROM (" +
+	"SELECT format('ALTER %s %I.%I OWNER TO %I', " +
+	"CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'S' THEN 'SEQUENCE' " +
+	"WHEN 'f' THEN 'FOREIGN TABLE' WHEN 'c' THEN 'TYPE' ELSE 'TABLE' END, n.nspname, c.relname, $2::text) AS s " +
+	"FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace) " +
+	"WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f', 'c') " +
+	"AND pg_catalog.pg_get_userbyid(c.relowner) <> $2::text " +
+	"AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid " +
+	"AND (d.deptype = 'e' OR (c.relkind = 'S' AND d.deptype IN ('a', 'i')))) " +
+	"UNION ALL " +
+	"SELECT format('ALTER ROUTINE %I.%I(%s) OWNER TO %I', n.nspname, p.proname, " +
+	"pg_catalog.pg_get_function_identity_arguments(p.oid), $2::text) " +
+	"FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON (n.oid = p.pronamespace) " +
+	"WHERE n.nspname = $1 AND pg_catalog.pg_get_userbyid(p.proowner) <> $2::text " +
+	"AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e') " +
+	"UNION ALL " +
+	"SELECT format('ALTER %s %I.%I OWNER TO %I', CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END, " +
+	"n.nspname, t.typname, $2::text) " +
+	"FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace) " +
+	"WHERE n.nspname = $1 AND t.typtype IN ('d', 'e', 'r') AND pg_catalog.pg_get_userbyid(t.typowner) <> $2::text " +
+	"AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e')" +
+	") o"
+
+// objectTypes maps the object types of a SchemaDefaultPrivilege to the
+// defaclobjtype values of pg_default_acl.
+var objectTypes = map[string]string{
+	"TABLES":    "r",
+	"SEQUENCES": "S",
+	"FUNCTIONS": "f",
+	"TYPES":     "T",
+}
+
+// defaultPrivilegeKey identifies a single default privilege granted to a role
+// on a type of object.
+func defaultPrivilegeKey(role, objType, privilege string) string {
+	return role + "/" + objType + "/" + privilege
+}
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
 	cr, ok := mg.(*v1alpha1.Schema)
@@ -134,13 +235,16 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		Role: new(string),
 	}
 
-	query := "SELECT rolname FROM pg_catalog.pg_namespace JOIN pg_catalog.pg_roles ON (nspowner=pg_roles.oid) where nspname = $1"
+	query := "SELECT rolname, COALESCE(pg_catalog.obj_description(pg_namespace.oid, 'pg_namespace'), '') " +
+		"FROM pg_catalog.pg_namespace JOIN pg_catalog.pg_roles ON (nspowner=pg_roles.oid) where nspname = $1"
 
+	var comment string
 	err := c.db.Scan(ctx, xsql.Query{
 		String:     query,
 		Parameters: []interface{}{meta.GetExternalName(cr)},
 	},
 		observed.Role,
+		&comment,
 	)
 
 	// If the database we try to connect on does not exist then
@@ -152,21 +256,100 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectSchema)
 	}
 
+	if cr.Spec.ForProvider.DefaultPrivileges != nil {
+		if c.defaultPrivs, err = c.selectDefaultPrivileges(ctx, meta.GetExternalName(cr)); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectDefaultPrivs)
+		}
+	}
+
+	if enforceOwner(cr.Spec.ForProvider) {
+		owner := *observed.Role
+		if cr.Spec.ForProvider.Role != nil {
+			owner = *cr.Spec.ForProvider.Role
+		}
+		c.reassign = []string{}
+		if err := c.db.Scan(ctx, xsql.Query{
+			String:     reassignQuery,
+			Parameters: []interface{}{meta.GetExternalName(cr), owner},
+		}, pq.Array(&c.reassign)); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errSelectOwners)
+		}
+	}
+
+	cr.Status.AtProvider.Comment = comment
 	cr.SetConditions(xpv1.Available())
 
 	return managed.ExternalObservation{
 		ResourceExists:          true,
 		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
-		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider),
+		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider) && commentUpToDate(cr) && c.defaultPrivilegesUpToDate(cr.Spec.ForProvider) && len(c.reassign) == 0,
 	}, nil
 }
 
+// enforceOwner returns true if objects in the schema that are not owned by
+// its owner should be reassigned to it.
+func enforceOwner(p v1alpha1.SchemaParameters) bool {
+	return p.DefaultOwner != nil && *p.DefaultOwner == v1alpha1.DefaultOwnerEnforce
+}
+
+// selectDefaultPrivileges returns the default privileges the owner of the
+// supplied schema has set in it, sorted.
+func (c *external) selectDefaultPrivileges(ctx context.Context, schema string) ([]string, error) {
+	privs := []string{}
+	err := c.db.Scan(ctx, xsql.Query{
+		String: "SELECT COALESCE(array_agg((CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END) || '/' || d.defaclobjtype || '/' || a.privilege_type), '{}') " +
+			"FROM pg_catalog.pg_default_acl d JOIN pg_catalog.pg_namespace n ON (n.oid = d.defaclnamespace) " +
+			"CROSS JOIN LATERAL aclexplode(d.defaclacl) a " +
+			"WHERE n.nspname = $1 AND d.defaclrole = n.nspowner",
+		Parameters: []interface{}{schema},
+	}, pq.Array(&privs))
+	sort.Strings(privs)
+	return privs, err
+}
+
+// desiredDefaultPrivileges returns the default privileges requested by the
+// supplied parameters, sorted and in the format of defaultPrivilegeKey.
+func desiredDefaultPrivileges(p v1alpha1.SchemaParameters) []string {
+	set := map[string]struct{}{}
+	for _, dp := range p.DefaultPrivileges {
+		for _, priv := range dp.Privileges {
+			set[defaultPrivilegeKey(dp.Role, objectTypes[dp.ObjectType], string(priv))] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (c *external) defaultPrivilegesUpToDate(p v1alpha1.SchemaParameters) bool {
+	if p.DefaultPrivileges == nil {
+		return true
+	}
+	desired := desiredDefaultPrivileges(p)
+	if len(desired) != len(c.defaultPrivs) {
+		return false
+	}
+	for i := range desired {
+		if desired[i] != c.defaultPrivs[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) { //nolint:gocyclo
 	cr, ok := mg.(*v1alpha1.Schema)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotSchema)
 	}
 
+	if err := xsql.ValidateIdentifier(meta.GetExternalName(cr), postgresql.IdentifierLimit); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidName)
+	}
+
 	var b strings.Builder
 	b.WriteString("CREATE SCHEMA IF NOT EXISTS ")
 	b.WriteString(pq.QuoteIdentifier(meta.GetExternalName(cr)))
@@ -176,7 +359,30 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		b.WriteString(pq.QuoteIdentifier(*cr.Spec.ForProvider.Role))
 	}
 
-	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: b.String()}), errCreateSchema)
+	if err := c.db.Exec(ctx, xsql.Query{String: b.String()}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateSchema)
+	}
+	return managed.ExternalCreation{}, c.setComment(ctx, cr)
+}
+
+// commentUpToDate returns true if the supplied schema specifies no comment,
+// or the one it was observed with.
+func commentUpToDate(cr *v1alpha1.Schema) bool {
+	return cr.Spec.ForProvider.Comment == nil || *cr.Spec.ForProvider.Comment == cr.Status.AtProvider.Comment
+}
+
+// setComment sets the comment of the supplied schema, if it specifies one.
+func (c *external) setComment(ctx context.Context, cr *v1alpha1.Schema) error {
+	if cr.Spec.ForProvider.Comment == nil {
+		return nil
+	}
+	literal := "NULL"
+	if *cr.Spec.ForProvider.Comment != "" {
+		literal = pq.QuoteLiteral(*cr.Spec.ForProvider.Comment)
+	}
+	query := xsql.Query{String: fmt.Sprintf("COMMENT ON SCHEMA %s IS %s",
+		pq.QuoteIdentifier(meta.GetExternalName(cr)), literal)}
+	return errors.Wrap(c.db.Exec(ctx, query), errCommentSchema)
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) { //nolint:gocyclo
@@ -185,6 +391,12 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotSchema)
 	}
 
+	if !commentUpToDate(cr) {
+		if err := c.setComment(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
 	if cr.Spec.ForProvider.Role == nil {
 		return managed.ExternalUpdate{}, nil
 	}
@@ -195,8 +407,76 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	b.WriteString(" OWNER TO ")
 	b.WriteString(pq.QuoteIdentifier(*cr.Spec.ForProvider.Role))
 
-	err := c.db.Exec(ctx, xsql.Query{String: b.String()})
-	return managed.ExternalUpdate{}, errors.Wrap(err, errAlterSchema)
+	if err := c.db.Exec(ctx, xsql.Query{String: b.String()}); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errAlterSchema)
+	}
+
+	// Objects are reassigned in a single transaction, so that a failure
+	// leaves none of them half repaired.
+	if len(c.reassign) > 0 {
+		ql := make([]xsql.Query, len(c.reassign))
+		for i, stmt := range c.reassign {
+			ql[i] = xsql.Query{String: stmt}
+		}
+		if err := c.db.ExecTx(ctx, ql); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errReassignOwners)
+		}
+	}
+
+	if cr.Spec.ForProvider.DefaultPrivileges == nil || c.defaultPrivilegesUpToDate(cr.Spec.ForProvider) {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	ql := defaultPrivilegeQueries(meta.GetExternalName(cr), *cr.Spec.ForProvider.Role, c.defaultPrivs, desiredDefaultPrivileges(cr.Spec.ForProvider))
+	return managed.ExternalUpdate{}, errors.Wrap(c.db.ExecTx(ctx, ql), errAlterDefaultPrivs)
+}
+
+// defaultPrivilegeQueries returns the statements that turn the observed
+// default privileges of the supplied owner into the desired ones.
+func defaultPrivilegeQueries(schema, owner string, observed, desired []string) []xsql.Query {
+	want := map[string]bool{}
+	for _, k := range desired {
+		want[k] = true
+	}
+	have := map[string]bool{}
+	for _, k := range observed {
+		have[k] = true
+	}
+
+	ql := []xsql.Query{}
+	for _, k := range observed {
+		if !want[k] {
+			ql = append(ql, defaultPrivilegeQuery(schema, owner, k, false))
+		}
+	}
+	for _, k := range desired {
+		if !have[k] {
+			ql = append(ql, defaultPrivilegeQuery(schema, owner, k, true))
+		}
+	}
+	return ql
+}
+
+func defaultPrivilegeQuery(schema, owner, key string, grant bool) xsql.Query {
+	parts := strings.SplitN(key, "/", 3)
+	role, objType, priv := parts[0], parts[1], parts[2]
+
+	grantee := pq.QuoteIdentifier(role)
+	if role == "PUBLIC" {
+		grantee = role
+	}
+	for t, v := range objectTypes {
+		if v == objType {
+			objType = t
+		}
+	}
+
+	action := fmt.Sprintf("REVOKE %s ON %s FROM %s", priv, objType, grantee)
+	if grant {
+		action = fmt.Sprintf("GRANT %s ON %s TO %s", priv, objType, grantee)
+	}
+	return xsql.Query{String: fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s %s",
+		pq.QuoteIdentifier(owner), pq.QuoteIdentifier(schema), action)}
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -206,6 +486,9 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	}
 
 	err := c.db.Exec(ctx, xsql.Query{String: "DROP SCHEMA IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr))})
+	if postgresql.IsInvalidCatalog(err) {
+		return nil
+	}
 	return errors.Wrap(err, errDropSchema)
 }
 
@@ -19,6 +19,7 @@ package schema
 import (
 	"context"
 	"database/sql"
+	"net"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
@@ -29,11 +30,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -70,7 +73,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string, sslmode string) xsql.DB
+		newDB func(creds map[string][]byte, database string, sslmode string, opts map[string]string) xsql.DB
 	}
 
 	type args struct {
@@ -316,6 +319,199 @@ func TestObserve(t *testing.T) {
 				},
 			},
 		},
+		"CommentChanged": {
+			reason: "A schema whose comment differs from the observed one should not be up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "blah"
+						*dest[1].(*string) = "old"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Role:    ptr.To("blah"),
+							Comment: ptr.To("new"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ErrSelectDefaultPrivileges": {
+			reason: "We should return any errors encountered while trying to select default privileges",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if bv, ok := dest[0].(*string); ok {
+							*bv = "role"
+							return nil
+						}
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database:          ptr.To("db"),
+							Role:              ptr.To("role"),
+							DefaultPrivileges: []v1alpha1.SchemaDefaultPrivilege{},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectDefaultPrivs),
+			},
+		},
+		"DefaultPrivilegesNotUpToDate": {
+			reason: "We should return ResourceUpToDate: false when the default privileges differ",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if bv, ok := dest[0].(*string); ok {
+							*bv = "role"
+							return nil
+						}
+						return dest[0].(sql.Scanner).Scan("{reader/r/SELECT,PUBLIC/f/EXECUTE}")
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database: ptr.To("db"),
+							Role:     ptr.To("role"),
+							DefaultPrivileges: []v1alpha1.SchemaDefaultPrivilege{
+								{Role: "reader", ObjectType: "TABLES", Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"DefaultPrivilegesUpToDate": {
+			reason: "We should return ResourceUpToDate: true when the default privileges match",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if bv, ok := dest[0].(*string); ok {
+							*bv = "role"
+							return nil
+						}
+						return dest[0].(sql.Scanner).Scan("{reader/r/SELECT,reader/S/USAGE}")
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database: ptr.To("db"),
+							Role:     ptr.To("role"),
+							DefaultPrivileges: []v1alpha1.SchemaDefaultPrivilege{
+								{Role: "reader", ObjectType: "SEQUENCES", Privileges: v1alpha1.GrantPrivileges{"USAGE"}},
+								{Role: "reader", ObjectType: "TABLES", Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"DefaultOwnerNotEnforced": {
+			reason: "We should return ResourceUpToDate: false when objects in the schema are not owned by its owner",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if bv, ok := dest[0].(*string); ok {
+							*bv = "role"
+							return nil
+						}
+						if q.String != reassignQuery {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						if diff := cmp.Diff([]interface{}{"cool", "role"}, q.Parameters); diff != "" {
+							return errors.Errorf("unexpected parameters: %s", diff)
+						}
+						return dest[0].(sql.Scanner).Scan(`{"ALTER TABLE cool.orders OWNER TO role"}`)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database:     ptr.To("db"),
+							Role:         ptr.To("role"),
+							DefaultOwner: ptr.To(v1alpha1.DefaultOwnerEnforce),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ErrSelectOwners": {
+			reason: "We should return any errors encountered while selecting the owners of schema objects",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if bv, ok := dest[0].(*string); ok {
+							*bv = "role"
+							return nil
+						}
+						return errBoom
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database:     ptr.To("db"),
+							DefaultOwner: ptr.To(v1alpha1.DefaultOwnerEnforce),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectOwners),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -421,11 +617,15 @@ func TestCreate(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
 	cr := v1alpha1.Schema{}
 	meta.SetExternalName(&cr, "cool")
 
 	type fields struct {
-		db xsql.DB
+		db           xsql.DB
+		defaultPrivs []string
+		reassign     []string
 	}
 
 	type args struct {
@@ -474,11 +674,138 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"ErrAlterDefaultPrivileges": {
+			reason: "We should return any errors encountered while altering default privileges",
+			fields: fields{
+				db: &mockDB{
+					MockExec:   func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database: ptr.To("db"),
+							Role:     ptr.To("owner"),
+							DefaultPrivileges: []v1alpha1.SchemaDefaultPrivilege{
+								{Role: "reader", ObjectType: "TABLES", Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAlterDefaultPrivs),
+			},
+		},
+		"SuccessDefaultPrivileges": {
+			reason: "Missing default privileges should be granted and unwanted ones revoked",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != `ALTER SCHEMA "cool" OWNER TO "owner"` {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "owner" IN SCHEMA "cool" REVOKE EXECUTE ON FUNCTIONS FROM PUBLIC`},
+							{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "owner" IN SCHEMA "cool" GRANT SELECT ON TABLES TO "reader"`},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+				defaultPrivs: []string{"PUBLIC/f/EXECUTE", "reader/S/USAGE"},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database: ptr.To("db"),
+							Role:     ptr.To("owner"),
+							DefaultPrivileges: []v1alpha1.SchemaDefaultPrivilege{
+								{Role: "reader", ObjectType: "SEQUENCES", Privileges: v1alpha1.GrantPrivileges{"USAGE"}},
+								{Role: "reader", ObjectType: "TABLES", Privileges: v1alpha1.GrantPrivileges{"SELECT"}},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SuccessReassign": {
+			reason: "Objects in the schema that are not owned by its owner should be reassigned in a transaction",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `ALTER ROUTINE cool.refresh() OWNER TO owner`},
+							{String: `ALTER TABLE cool.orders OWNER TO owner`},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							return errors.Errorf("unexpected queries: %s", diff)
+						}
+						return nil
+					},
+				},
+				reassign: []string{"ALTER ROUTINE cool.refresh() OWNER TO owner", "ALTER TABLE cool.orders OWNER TO owner"},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database:     ptr.To("db"),
+							Role:         ptr.To("owner"),
+							DefaultOwner: ptr.To(v1alpha1.DefaultOwnerEnforce),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrReassign": {
+			reason: "We should return any errors encountered while reassigning schema objects",
+			fields: fields{
+				db: &mockDB{
+					MockExec:   func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+				reassign: []string{"ALTER TABLE cool.orders OWNER TO owner"},
+			},
+			args: args{
+				mg: &v1alpha1.Schema{
+					ObjectMeta: cr.ObjectMeta,
+					Spec: v1alpha1.SchemaSpec{
+						ForProvider: v1alpha1.SchemaParameters{
+							Database:     ptr.To("db"),
+							Role:         ptr.To("owner"),
+							DefaultOwner: ptr.To(v1alpha1.DefaultOwnerEnforce),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errReassignOwners),
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, defaultPrivs: tc.fields.defaultPrivs, reassign: tc.fields.reassign}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -551,3 +878,61 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectDisconnect(t *testing.T) {
+	// Nothing listens on the port once the listener is closed, so the
+	// statements of the external client fail to connect.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen(...): %v", err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close() //nolint:errcheck
+
+	c := managed.NewNopDisconnecter(&connector{
+		kube: &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				switch o := obj.(type) {
+				case *v1alpha1.ProviderConfig:
+					o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "creds"}
+					o.Spec.MaxOpenConnections = ptr.To[int32](2)
+				case *corev1.Secret:
+					o.Data = map[string][]byte{
+						xpv1.ResourceCredentialsSecretEndpointKey: []byte("127.0.0.1"),
+						xpv1.ResourceCredentialsSecretPortKey:     []byte(port),
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("provider"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("password"),
+					}
+				}
+				return nil
+			}),
+		},
+		usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+		newDB: postgresql.New,
+		log:   logging.NewNopLogger(),
+	})
+
+	cr := &v1alpha1.Schema{Spec: v1alpha1.SchemaSpec{
+		ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "default"}},
+		ForProvider:  v1alpha1.SchemaParameters{Database: ptr.To("db")},
+	}}
+	meta.SetExternalName(cr, "cool")
+
+	ctx := context.Background()
+	e, err := c.Connect(ctx, cr)
+	if err != nil {
+		t.Fatalf("c.Connect(...): %v", err)
+	}
+	if _, err := e.Observe(ctx, cr); err == nil {
+		t.Fatalf("e.Observe(...): want an error connecting to the server")
+	}
+	if err := c.Disconnect(ctx); err != nil {
+		t.Fatalf("c.Disconnect(...): %v", err)
+	}
+
+	// The handles opened by the statements of the external client are closed
+	// once it is done with them, rather than left to the end of a reconcile.
+	if diff := cmp.Diff(0, xsql.OpenHandles()); diff != "" {
+		t.Errorf("xsql.OpenHandles(): -want, +got:\n%s", diff)
+	}
+}
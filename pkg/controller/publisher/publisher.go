@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package publisher configures where managed resource controllers publish
+// the connection details of the resources they reconcile.
+package publisher
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+)
+
+// EnableAlphaExternalSecretStores enables alpha support for publishing
+// connection details to the external secret stores configured by
+// StoreConfigs, such as Vault through an ESS plugin.
+const EnableAlphaExternalSecretStores feature.Flag = "EnableAlphaExternalSecretStores"
+
+// ConnectionPublishers returns the publishers of the connection details of a
+// controller's managed resources. Connection details are always written to
+// the Secret a resource references in writeConnectionSecretToRef. When
+// EnableAlphaExternalSecretStores is enabled they are also published to the
+// StoreConfig, of the supplied kind, it references in
+// publishConnectionDetailsTo.
+func ConnectionPublishers(mgr ctrl.Manager, o xpcontroller.Options, storeConfig schema.GroupVersionKind) []managed.ConnectionPublisher {
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if !o.Features.Enabled(EnableAlphaExternalSecretStores) {
+		return cps
+	}
+
+	var opts []connection.DetailsManagerOption
+	if o.ESSOptions != nil && o.ESSOptions.TLSConfig != nil {
+		opts = append(opts, connection.WithTLSConfig(o.ESSOptions.TLSConfig))
+	}
+	return append(cps, connection.NewDetailsManager(mgr.GetClient(), storeConfig, opts...))
+}
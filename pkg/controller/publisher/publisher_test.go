@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publisher
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// manager is a ctrl.Manager that only has a client and scheme.
+type manager struct {
+	ctrl.Manager
+	scheme *runtime.Scheme
+}
+
+func (m manager) GetClient() client.Client {
+	return &test.MockClient{MockScheme: test.NewMockSchemeFn(m.scheme)}
+}
+
+func (m manager) GetScheme() *runtime.Scheme {
+	return m.scheme
+}
+
+func TestConnectionPublishers(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+	ess := &feature.Flags{}
+	ess.Enable(EnableAlphaExternalSecretStores)
+
+	cases := map[string]struct {
+		reason   string
+		features *feature.Flags
+		wantESS  bool
+	}{
+		"SecretsOnly": {
+			reason:   "Connection details should only be written to Secrets unless external secret stores are enabled",
+			features: &feature.Flags{},
+		},
+		"ExternalSecretStores": {
+			reason:   "Connection details should also be published to the StoreConfig when external secret stores are enabled",
+			features: ess,
+			wantESS:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cps := ConnectionPublishers(manager{scheme: s}, xpcontroller.Options{Features: tc.features}, v1alpha1.StoreConfigGroupVersionKind)
+			if _, ok := cps[0].(*managed.APISecretPublisher); !ok {
+				t.Errorf("\n%s\nConnectionPublishers(...): want an APISecretPublisher first, got %T", tc.reason, cps[0])
+			}
+			gotESS := len(cps) == 2
+			if gotESS {
+				_, gotESS = cps[1].(*connection.DetailsManager)
+			}
+			if gotESS != tc.wantESS {
+				t.Errorf("\n%s\nConnectionPublishers(...): want a DetailsManager %t, got %d publishers", tc.reason, tc.wantESS, len(cps))
+			}
+		})
+	}
+}
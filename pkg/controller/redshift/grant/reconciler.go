@@ -0,0 +1,355 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/redshift"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotGrant    = "managed resource is not a Grant custom resource"
+	errNoGrantee   = "a grant must be for either a user or a group"
+	errNoSchema    = "grants on a table must specify its schema"
+	errNoDatabase  = "grants without a schema must specify the database"
+	errSelectGrant = "cannot select grant"
+	errCreateGrant = "cannot create grant"
+	errRevokeGrant = "cannot revoke grant"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Grant managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.GrantGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: redshift.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return nil, errors.New(errNotGrant)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := redshift.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (RedshiftConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// The privileges of a database's objects are observed through the
+	// system views of that database.
+	db := c.newDB(s.Data, ptr.Deref(cr.Spec.ForProvider.Database, pc.Spec.DefaultDatabase), clients.ToString(pc.Spec.SSLMode), pc.Spec.ConnectionOptions)
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(db, redshift.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// target is the object that privileges are granted on, and how to find the
+// privileges the grantee holds on it.
+type target struct {
+	// on is the object as it appears in GRANT and REVOKE statements, e.g.
+	// SCHEMA "marts".
+	on string
+	// to is the grantee as it appears in GRANT and REVOKE statements, e.g.
+	// GROUP "analysts".
+	to string
+	// query selects the privileges the grantee holds on the object.
+	query xsql.Query
+}
+
+func newTarget(p v1alpha1.GrantParameters) (target, error) {
+	var identityType, identityName, to string
+	switch {
+	case p.User != nil && p.Group == nil:
+		identityType, identityName, to = "user", *p.User, redshift.QuoteIdentifier(*p.User)
+	case p.Group != nil && p.User == nil:
+		identityType, identityName, to = "group", *p.Group, "GROUP "+redshift.QuoteIdentifier(*p.Group)
+	default:
+		return target{}, errors.New(errNoGrantee)
+	}
+
+	switch {
+	case p.Table != nil && p.Schema == nil:
+		return target{}, errors.New(errNoSchema)
+	case p.Table != nil:
+		return target{
+			on: "TABLE " + redshift.QualifiedName(*p.Schema, *p.Table),
+			to: to,
+			query: xsql.Query{
+				String:     "SELECT privilege_type FROM svv_relation_privileges WHERE namespace_name = $1 AND relation_name = $2 AND identity_type = $3 AND identity_name = $4",
+				Parameters: []interface{}{*p.Schema, *p.Table, identityType, identityName},
+			},
+		}, nil
+	case p.Schema != nil:
+		return target{
+			on: "SCHEMA " + redshift.QuoteIdentifier(*p.Schema),
+			to: to,
+			query: xsql.Query{
+				String:     "SELECT privilege_type FROM svv_schema_privileges WHERE namespace_name = $1 AND identity_type = $2 AND identity_name = $3",
+				Parameters: []interface{}{*p.Schema, identityType, identityName},
+			},
+		}, nil
+	case p.Database != nil:
+		return target{
+			on: "DATABASE " + redshift.QuoteIdentifier(*p.Database),
+			to: to,
+			query: xsql.Query{
+				String:     "SELECT privilege_type FROM svv_database_privileges WHERE database_name = $1 AND identity_type = $2 AND identity_name = $3",
+				Parameters: []interface{}{*p.Database, identityType, identityName},
+			},
+		}, nil
+	default:
+		return target{}, errors.New(errNoDatabase)
+	}
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	t, err := newTarget(p)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	observed, err := c.observePrivileges(ctx, t)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+	if len(observed) == 0 {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider.Privileges = observed
+	cr.SetConditions(xpv1.Available())
+
+	toGrant, toRevoke := diffPermissions(p.Privileges.ToStringSlice(), observed)
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0,
+	}, nil
+}
+
+// observePrivileges returns the sorted privileges the grantee of the supplied
+// target holds on it, each listed once.
+func (c *external) observePrivileges(ctx context.Context, t target) ([]string, error) {
+	rows, err := c.db.Query(ctx, t.query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []string
+	for rows.Next() {
+		var privilege string
+		if err := rows.Scan(&privilege); err != nil {
+			return nil, err
+		}
+		out = append(out, privilege)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(out)
+	return slices.Compact(out), nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGrant)
+	}
+
+	t, err := newTarget(cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	query := createGrantQuery(t, cr.Spec.ForProvider.Privileges.ToStringSlice())
+	if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGrant)
+	}
+
+	t, err := newTarget(cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	toGrant, toRevoke := diffPermissions(cr.Spec.ForProvider.Privileges.ToStringSlice(), cr.Status.AtProvider.Privileges)
+
+	if len(toRevoke) > 0 {
+		query := createRevokeQuery(t, toRevoke)
+		if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errRevokeGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if len(toGrant) > 0 {
+		query := createGrantQuery(t, toGrant)
+		if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return errors.New(errNotGrant)
+	}
+
+	t, err := newTarget(cr.Spec.ForProvider)
+	if err != nil {
+		return err
+	}
+
+	query := createRevokeQuery(t, cr.Spec.ForProvider.Privileges.ToStringSlice())
+	return redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errRevokeGrant})
+}
+
+func createGrantQuery(t target, privileges []string) string {
+	sort.Strings(privileges)
+	return fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(privileges, ", "), t.on, t.to)
+}
+
+func createRevokeQuery(t target, privileges []string) string {
+	sort.Strings(privileges)
+	return fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(privileges, ", "), t.on, t.to)
+}
+
+func diffPermissions(desired, observed []string) ([]string, []string) {
+	return subtract(desired, observed), subtract(observed, desired)
+}
+
+// subtract returns the elements of a that are not in b.
+func subtract(a, b []string) []string {
+	bMap := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		bMap[v] = struct{}{}
+	}
+
+	var out []string
+	for _, v := range a {
+		if _, ok := bMap[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func grant(p v1alpha1.GrantParameters) *v1alpha1.Grant {
+	return &v1alpha1.Grant{
+		Spec: v1alpha1.GrantSpec{ForProvider: p},
+	}
+}
+
+func privileges(p ...string) v1alpha1.GrantPrivileges {
+	out := make(v1alpha1.GrantPrivileges, len(p))
+	for i := range p {
+		out[i] = v1alpha1.GrantPrivilege(p[i])
+	}
+	return out
+}
+
+func selectQuery(want string, params []interface{}, rows *sqlmock.Rows) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		if q.String != want {
+			return nil, errors.Errorf("unexpected query: %s", q.String)
+		}
+		if diff := cmp.Diff(params, q.Parameters); diff != "" {
+			return nil, errors.Errorf("unexpected parameters: %s", diff)
+		}
+		return mockRowsToSQLRows(rows), nil
+	}
+}
+
+func execQueries(want ...string) func(ctx context.Context, q xsql.Query) error {
+	return func(ctx context.Context, q xsql.Query) error {
+		for _, w := range want {
+			if q.String == w {
+				return nil
+			}
+		}
+		return errors.Errorf("unexpected query: %s", q.String)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		privileges []string
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrNoGrantee": {
+			reason: "An error should be returned if the grant is for both a user and a group",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Group: ptr.To("analysts"), Schema: ptr.To("marts")}),
+			},
+			want: want{
+				err: errors.New(errNoGrantee),
+			},
+		},
+		"ErrNoSchema": {
+			reason: "An error should be returned if a grant on a table does not specify its schema",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Table: ptr.To("orders")}),
+			},
+			want: want{
+				err: errors.New(errNoSchema),
+			},
+		},
+		"ErrNoDatabase": {
+			reason: "An error should be returned if a grant without a schema does not specify the database",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst")}),
+			},
+			want: want{
+				err: errors.New(errNoDatabase),
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while trying to select the privileges",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts")}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGrant),
+			},
+		},
+		"NoGrant": {
+			reason: "We should return ResourceExists: false when the grantee holds no privileges",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery(
+						"SELECT privilege_type FROM svv_database_privileges WHERE database_name = $1 AND identity_type = $2 AND identity_name = $3",
+						[]interface{}{"dev", "user", "analyst"},
+						sqlmock.NewRows([]string{"privilege_type"}),
+					),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Database: ptr.To("dev"), Privileges: privileges("TEMP")}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"SchemaGrantToGroup": {
+			reason: "We should report a grant on a schema to a group as up to date if the privileges match",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery(
+						"SELECT privilege_type FROM svv_schema_privileges WHERE namespace_name = $1 AND identity_type = $2 AND identity_name = $3",
+						[]interface{}{"marts", "group", "analysts"},
+						sqlmock.NewRows([]string{"privilege_type"}).AddRow("USAGE").AddRow("CREATE"),
+					),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Group: ptr.To("analysts"), Schema: ptr.To("marts"), Privileges: privileges("USAGE", "CREATE")}),
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				privileges: []string{"CREATE", "USAGE"},
+			},
+		},
+		"TableGrantChanged": {
+			reason: "We should report a grant on a table as not up to date if the privileges differ",
+			fields: fields{
+				db: mockDB{
+					MockQuery: selectQuery(
+						"SELECT privilege_type FROM svv_relation_privileges WHERE namespace_name = $1 AND relation_name = $2 AND identity_type = $3 AND identity_name = $4",
+						[]interface{}{"marts", "orders", "user", "analyst"},
+						sqlmock.NewRows([]string{"privilege_type"}).AddRow("SELECT").AddRow("SELECT"),
+					),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts"), Table: ptr.To("orders"), Privileges: privileges("SELECT", "INSERT")}),
+			},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				privileges: []string{"SELECT"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Grant); ok {
+				if diff := cmp.Diff(tc.want.privileges, cr.Status.AtProvider.Privileges); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want privileges, +got privileges:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			want:   errors.New(errNotGrant),
+		},
+		"ErrCreateGrant": {
+			reason: "Errors granting the privileges should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			mg:     grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts"), Privileges: privileges("USAGE")}),
+			want:   errors.Wrap(errBoom, errCreateGrant),
+		},
+		"DatabaseGrant": {
+			reason: "Privileges should be granted on the database to the user",
+			exec:   execQueries(`GRANT CREATE, TEMP ON DATABASE "dev" TO "analyst"`),
+			mg:     grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Database: ptr.To("dev"), Privileges: privileges("TEMP", "CREATE")}),
+		},
+		"SchemaGrant": {
+			reason: "Privileges should be granted on the schema to the group",
+			exec:   execQueries(`GRANT USAGE ON SCHEMA "marts" TO GROUP "analysts"`),
+			mg:     grant(v1alpha1.GrantParameters{Group: ptr.To("analysts"), Database: ptr.To("dev"), Schema: ptr.To("marts"), Privileges: privileges("USAGE")}),
+		},
+		"TableGrant": {
+			reason: "Privileges should be granted on the table in the schema",
+			exec:   execQueries(`GRANT SELECT ON TABLE "marts"."orders" TO GROUP "analysts"`),
+			mg:     grant(v1alpha1.GrantParameters{Group: ptr.To("analysts"), Schema: ptr.To("marts"), Table: ptr.To("orders"), Privileges: privileges("SELECT")}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	withObserved := func(p v1alpha1.GrantParameters, observed ...string) *v1alpha1.Grant {
+		cr := grant(p)
+		cr.Status.AtProvider.Privileges = observed
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrRevokeGrant": {
+			reason: "Errors revoking privileges should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			mg:     withObserved(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts"), Privileges: privileges("USAGE")}, "CREATE", "USAGE"),
+			want:   errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"Success": {
+			reason: "Privileges that are no longer desired should be revoked, and new ones granted",
+			exec: execQueries(
+				`REVOKE CREATE ON SCHEMA "marts" FROM "analyst"`,
+				`GRANT USAGE ON SCHEMA "marts" TO "analyst"`,
+			),
+			mg: withObserved(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts"), Privileges: privileges("USAGE")}, "CREATE"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		want   error
+	}{
+		"ErrRevokeGrant": {
+			reason: "Errors revoking the privileges should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			want:   errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"Success": {
+			reason: "The privileges should be revoked",
+			exec:   execQueries(`REVOKE INSERT, SELECT ON TABLE "marts"."orders" FROM "analyst"`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			err := e.Delete(context.Background(), grant(v1alpha1.GrantParameters{User: ptr.To("analyst"), Schema: ptr.To("marts"), Table: ptr.To("orders"), Privileges: privileges("SELECT", "INSERT")}))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
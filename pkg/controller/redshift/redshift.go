@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redshift
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift/config"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift/grant"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift/group"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift/schema"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift/user"
+)
+
+// Setup creates all Redshift controllers with the supplied logger and adds
+// them to the supplied manager.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+		config.Setup,
+		user.Setup,
+		group.Setup,
+		schema.Setup,
+		grant.Setup,
+	} {
+		if err := setup(mgr, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Connecters returns the constructors of the connecters of all Redshift
+// managed resources, keyed by their group kind.
+func Connecters() map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter {
+	return map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter{
+		v1alpha1.GrantGroupKind:  grant.NewConnecter,
+		v1alpha1.GroupGroupKind:  group.NewConnecter,
+		v1alpha1.SchemaGroupKind: schema.NewConnecter,
+		v1alpha1.UserGroupKind:   user.NewConnecter,
+	}
+}
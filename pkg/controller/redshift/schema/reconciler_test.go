@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func schema(p v1alpha1.SchemaParameters) *v1alpha1.Schema {
+	return &v1alpha1.Schema{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "marts"},
+		},
+		Spec: v1alpha1.SchemaSpec{ForProvider: p},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o     managed.ExternalObservation
+		owner string
+		err   error
+	}
+
+	withOwner := func(owner string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = owner
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSchema),
+			},
+		},
+		"ErrSelectSchema": {
+			reason: "We should return any errors encountered while trying to select the schema",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSchema),
+			},
+		},
+		"NoSchema": {
+			reason: "We should return ResourceExists: false when no schema is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"OwnerUnmanaged": {
+			reason: "We should report the schema as up to date if its owner is not set",
+			fields: fields{
+				db: mockDB{MockScan: withOwner("admin")},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: want{
+				o:     managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				owner: "admin",
+			},
+		},
+		"OwnerChanged": {
+			reason: "We should report the schema as not up to date if its owner differs",
+			fields: fields{
+				db: mockDB{MockScan: withOwner("admin")},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{Owner: ptr.To("analyst")}),
+			},
+			want: want{
+				o:     managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				owner: "admin",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Schema); ok {
+				if diff := cmp.Diff(tc.want.owner, cr.Status.AtProvider.Owner); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want owner, +got owner:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			want:   errors.New(errNotSchema),
+		},
+		"ErrCreateSchema": {
+			reason: "Errors creating the schema should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			mg:     schema(v1alpha1.SchemaParameters{}),
+			want:   errors.Wrap(errBoom, errCreateSchema),
+		},
+		"Success": {
+			reason: "The schema should be created with its owner",
+			exec: func(ctx context.Context, q xsql.Query) error {
+				if q.String != `CREATE SCHEMA "marts" AUTHORIZATION "analyst"` {
+					return errors.Errorf("unexpected query: %s", q.String)
+				}
+				return nil
+			},
+			mg: schema(v1alpha1.SchemaParameters{Owner: ptr.To("analyst")}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrUpdateSchema": {
+			reason: "Errors altering the owner of the schema should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			mg:     schema(v1alpha1.SchemaParameters{Owner: ptr.To("analyst")}),
+			want:   errors.Wrap(errBoom, errUpdateSchema),
+		},
+		"NoOwner": {
+			reason: "Nothing should be altered if the owner of the schema is not set",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			mg:     schema(v1alpha1.SchemaParameters{}),
+		},
+		"Success": {
+			reason: "The owner of the schema should be altered",
+			exec: func(ctx context.Context, q xsql.Query) error {
+				if q.String != `ALTER SCHEMA "marts" OWNER TO "analyst"` {
+					return errors.Errorf("unexpected query: %s", q.String)
+				}
+				return nil
+			},
+			mg: schema(v1alpha1.SchemaParameters{Owner: ptr.To("analyst")}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		exec   func(ctx context.Context, q xsql.Query) error
+		want   error
+	}{
+		"ErrDropSchema": {
+			reason: "Errors dropping the schema should be returned",
+			exec:   func(ctx context.Context, q xsql.Query) error { return errBoom },
+			want:   errors.Wrap(errBoom, errDropSchema),
+		},
+		"Success": {
+			reason: "The schema should be dropped",
+			exec: func(ctx context.Context, q xsql.Query) error {
+				if q.String != `DROP SCHEMA IF EXISTS "marts"` {
+					return errors.Errorf("unexpected query: %s", q.String)
+				}
+				return nil
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: tc.exec}}
+			err := e.Delete(context.Background(), schema(v1alpha1.SchemaParameters{}))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/redshift"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotUser                 = "managed resource is not a User custom resource"
+	errSelectUser              = "cannot select user"
+	errSelectGroups            = "cannot select groups of user"
+	errCreateUser              = "cannot create user"
+	errUpdateUser              = "cannot update user"
+	errAddToGroup              = "cannot add user to group"
+	errRemoveFromGroup         = "cannot remove user from group"
+	errDropUser                = "cannot drop user"
+	errGetPasswordSecretFailed = "cannot get password secret"
+
+	// unlimited is the useconnlimit of users whose connections are not
+	// limited.
+	unlimited = "UNLIMITED"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles User managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.UserGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	secrets, err := secretref.EnqueueReferencing(mgr, &v1alpha1.User{}, &v1alpha1.UserList{}, func(o client.Object) []*xpv1.SecretKeySelector {
+		cr, ok := o.(*v1alpha1.User)
+		if !ok {
+			return nil
+		}
+		return []*xpv1.SecretKeySelector{cr.Spec.ForProvider.PasswordSecretRef}
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.User{}).
+		Watches(&corev1.Secret{}, secrets).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.UserGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.UserGroupKind))
+}
+
+// NewConnecter returns a connecter for User managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: redshift.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database, sslmode string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return nil, errors.New(errNotUser)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := redshift.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (RedshiftConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	// Refuse to manage the user the provider connects as, or one created by
+	// Amazon Redshift, so that a bad composition cannot lock the provider out.
+	reserved := redshift.IsReservedUser(meta.GetExternalName(cr), string(s.Data[xpv1.ResourceCredentialsSecretUserKey]))
+	if err := xsql.CheckReserved(cr, "user", meta.GetExternalName(cr), reserved); err != nil {
+		return nil, err
+	}
+
+	db := c.newDB(s.Data, pc.Spec.DefaultDatabase, clients.ToString(pc.Spec.SSLMode), pc.Spec.ConnectionOptions)
+	return &external{
+		db:   xsql.Instrument(xsql.WithConnectionLimits(db, redshift.ConnectionLimits(pc)), c.log, v1alpha1.UserKind, cr),
+		kube: c.kube,
+	}, nil
+}
+
+type external struct {
+	db   xsql.DB
+	kube client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotUser)
+	}
+
+	name := meta.GetExternalName(cr)
+
+	var createDB, createUser bool
+	var connLimit string
+	query := "SELECT usecreatedb, usesuper, COALESCE(useconnlimit, '" + unlimited + "') FROM pg_user_info WHERE usename = $1"
+	err := c.db.Scan(ctx, xsql.Query{String: query, Parameters: []interface{}{name}}, &createDB, &createUser, &connLimit)
+	if xsql.IsNoRows(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectUser)
+	}
+
+	groups, err := c.observeGroups(ctx, name)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	_, pwdChanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.Status.AtProvider = v1alpha1.UserObservation{
+		CreateDB:        ptr.To(createDB),
+		CreateUser:      ptr.To(createUser),
+		ConnectionLimit: connectionLimit(connLimit),
+		Groups:          groups,
+	}
+	cr.SetConditions(xpv1.Available())
+
+	p := cr.Spec.ForProvider
+	upToDate := !pwdChanged &&
+		(p.CreateDB == nil || *p.CreateDB == createDB) &&
+		(p.CreateUser == nil || *p.CreateUser == createUser) &&
+		ptr.Equal(p.ConnectionLimit, cr.Status.AtProvider.ConnectionLimit) &&
+		(p.Groups == nil || slices.Equal(sorted(p.Groups), groups))
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+// observeGroups returns the sorted names of the groups the supplied user is
+// a member of. Redshift records the members of a group in its grolist rather
+// than in a table of memberships, as pg_auth_members does.
+func (c *external) observeGroups(ctx context.Context, name string) ([]string, error) {
+	query := "SELECT g.groname FROM pg_group g, pg_user u WHERE u.usename = $1 AND u.usesysid = ANY(g.grolist)"
+	rows, err := c.db.Query(ctx, xsql.Query{String: query, Parameters: []interface{}{name}})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectGroups)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var groups []string
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, errors.Wrap(err, errSelectGroups)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errSelectGroups)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// connectionLimit returns the supplied useconnlimit as a number, or nil if
+// the connections of the user are not limited.
+func connectionLimit(useconnlimit string) *int32 {
+	n, err := strconv.ParseInt(useconnlimit, 10, 32)
+	if err != nil {
+		return nil
+	}
+	return ptr.To(int32(n))
+}
+
+func sorted(s []string) []string {
+	out := slices.Clone(s)
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// userOptions returns the options of CREATE USER and ALTER USER for the
+// supplied parameters. An unset connection limit is UNLIMITED.
+func userOptions(p v1alpha1.UserParameters) string {
+	out := ""
+	switch {
+	case p.CreateDB == nil:
+	case *p.CreateDB:
+		out += " CREATEDB"
+	default:
+		out += " NOCREATEDB"
+	}
+	switch {
+	case p.CreateUser == nil:
+	case *p.CreateUser:
+		out += " CREATEUSER"
+	default:
+		out += " NOCREATEUSER"
+	}
+	if p.ConnectionLimit != nil {
+		return out + " CONNECTION LIMIT " + strconv.Itoa(int(*p.ConnectionLimit))
+	}
+	return out + " CONNECTION LIMIT " + unlimited
+}
+
+func quoteIdentifiers(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = redshift.QuoteIdentifier(id)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	name := meta.GetExternalName(cr)
+
+	pw, _, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if pw == "" {
+		pw, err = redshift.GeneratePassword()
+		if err != nil {
+			return managed.ExternalCreation{}, err
+		}
+	}
+
+	query := "CREATE USER " + redshift.QuoteIdentifier(name) + " PASSWORD " + redshift.QuoteValue(pw) + userOptions(cr.Spec.ForProvider)
+	if len(cr.Spec.ForProvider.Groups) > 0 {
+		query += " IN GROUP " + quoteIdentifiers(sorted(cr.Spec.ForProvider.Groups))
+	}
+	if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errCreateUser}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{
+		ConnectionDetails: c.db.GetConnectionDetails(name, pw),
+	}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotUser)
+	}
+
+	name := meta.GetExternalName(cr)
+
+	pw, pwchanged, err := c.getPassword(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	options := userOptions(cr.Spec.ForProvider)
+	if pwchanged {
+		options = " PASSWORD " + redshift.QuoteValue(pw) + options
+	}
+	query := "ALTER USER " + redshift.QuoteIdentifier(name) + options
+	if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errUpdateUser}); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if err := c.updateGroups(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if pwchanged {
+		return managed.ExternalUpdate{ConnectionDetails: c.db.GetConnectionDetails(name, pw)}, nil
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+// updateGroups adds the user to and removes it from groups, so that it is a
+// member of exactly the desired groups, if they are set.
+func (c *external) updateGroups(ctx context.Context, cr *v1alpha1.User) error {
+	if cr.Spec.ForProvider.Groups == nil {
+		return nil
+	}
+	desired := sorted(cr.Spec.ForProvider.Groups)
+	observed := cr.Status.AtProvider.Groups
+	user := redshift.QuoteIdentifier(meta.GetExternalName(cr))
+
+	for _, g := range desired {
+		if slices.Contains(observed, g) {
+			continue
+		}
+		query := "ALTER GROUP " + redshift.QuoteIdentifier(g) + " ADD USER " + user
+		if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errAddToGroup}); err != nil {
+			return err
+		}
+	}
+	for _, g := range observed {
+		if slices.Contains(desired, g) {
+			continue
+		}
+		query := "ALTER GROUP " + redshift.QuoteIdentifier(g) + " DROP USER " + user
+		if err := redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errRemoveFromGroup}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return errors.New(errNotUser)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	query := "DROP USER IF EXISTS " + redshift.QuoteIdentifier(meta.GetExternalName(cr))
+	return redshift.ExecWrapper(ctx, c.db, redshift.ExecQuery{Query: query, ErrorValue: errDropUser})
+}
+
+// getPassword returns the password of the PasswordSecretRef, if any, and
+// whether it differs from the password in the connection secret.
+func (c *external) getPassword(ctx context.Context, user *v1alpha1.User) (newPwd string, changed bool, err error) {
+	if user.Spec.ForProvider.PasswordSecretRef == nil {
+		return "", false, nil
+	}
+	nn := types.NamespacedName{
+		Name:      user.Spec.ForProvider.PasswordSecretRef.Name,
+		Namespace: user.Spec.ForProvider.PasswordSecretRef.Namespace,
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, nn, s); err != nil {
+		return "", false, errors.Wrap(err, errGetPasswordSecretFailed)
+	}
+	newPwd = string(s.Data[user.Spec.ForProvider.PasswordSecretRef.Key])
+
+	if user.Spec.WriteConnectionSecretToReference == nil {
+		return newPwd, false, nil
+	}
+
+	nn = types.NamespacedName{
+		Name:      user.Spec.WriteConnectionSecretToReference.Name,
+		Namespace: user.Spec.WriteConnectionSecretToReference.Namespace,
+	}
+	s = &corev1.Secret{}
+	// the output secret may not exist yet, so we can skip returning an
+	// error if the error is NotFound
+	if err := c.kube.Get(ctx, nn, s); resource.IgnoreNotFound(err) != nil {
+		return "", false, err
+	}
+	changed = newPwd != "" && newPwd != string(s.Data[xpv1.ResourceCredentialsSecretPasswordKey])
+
+	return newPwd, changed, nil
+}
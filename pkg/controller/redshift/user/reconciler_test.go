@@ -0,0 +1,629 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/redshift/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func user(p v1alpha1.UserParameters) *v1alpha1.User {
+	return &v1alpha1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "analyst"},
+		},
+		Spec: v1alpha1.UserSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{},
+			},
+			ForProvider: p,
+		},
+	}
+}
+
+// scanUser returns a Scan that reports a user with the supplied attributes.
+func scanUser(createDB, createUser bool, connLimit string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		*dest[0].(*bool) = createDB
+		*dest[1].(*bool) = createUser
+		*dest[2].(*string) = connLimit
+		return nil
+	}
+}
+
+// queryGroups returns a Query that reports membership of the supplied groups.
+func queryGroups(groups ...string) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		rows := sqlmock.NewRows([]string{"groname"})
+		for _, g := range groups {
+			rows.AddRow(g)
+		}
+		return mockRowsToSQLRows(rows), nil
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, database, sslmode string, opts map[string]string) xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	withCredentials := func(username string) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				switch o := obj.(type) {
+				case *v1alpha1.ProviderConfig:
+					o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+				case *corev1.Secret:
+					o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretUserKey: []byte(username)}
+				}
+				return nil
+			}),
+		}
+	}
+
+	reserved := func(name string) *v1alpha1.User {
+		cr := user(v1alpha1.UserParameters{})
+		meta.SetExternalName(cr, name)
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotUser),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: errors.Wrap(errBoom, errTrackPCUsage),
+		},
+		"ErrSystemUser": {
+			reason: "An error should be returned if the user is created by Amazon Redshift",
+			fields: fields{
+				kube:  withCredentials("admin"),
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: reserved("rdsdb"),
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "rdsdb", true),
+		},
+		"ErrConnectionUser": {
+			reason: "An error should be returned if the user is the one the provider connects as",
+			fields: fields{
+				kube:  withCredentials("admin"),
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			args: args{
+				mg: reserved("admin"),
+			},
+			want: xsql.CheckReserved(&metav1.ObjectMeta{}, "user", "admin", true),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		obs v1alpha1.UserObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotUser),
+			},
+		},
+		"ErrSelectUser": {
+			reason: "We should return any errors encountered while trying to select the user",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectUser),
+			},
+		},
+		"NoUser": {
+			reason: "We should return ResourceExists: false when no user is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectGroups": {
+			reason: "We should return any errors encountered while trying to select the groups of the user",
+			fields: fields{
+				db: mockDB{
+					MockScan:  scanUser(false, false, "UNLIMITED"),
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGroups),
+			},
+		},
+		"UpToDate": {
+			reason: "We should report the user as up to date if its attributes and groups match",
+			fields: fields{
+				db: mockDB{
+					MockScan:  scanUser(true, false, "10"),
+					MockQuery: queryGroups("analysts", "readers"),
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{
+					CreateDB:        ptr.To(true),
+					ConnectionLimit: ptr.To[int32](10),
+					Groups:          []string{"readers", "analysts"},
+				}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.UserObservation{
+					CreateDB:        ptr.To(true),
+					CreateUser:      ptr.To(false),
+					ConnectionLimit: ptr.To[int32](10),
+					Groups:          []string{"analysts", "readers"},
+				},
+			},
+		},
+		"ConnectionLimitRemoved": {
+			reason: "We should report the user as not up to date if its connections are limited but should not be",
+			fields: fields{
+				db: mockDB{
+					MockScan:  scanUser(false, false, "10"),
+					MockQuery: queryGroups(),
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.UserObservation{
+					CreateDB:        ptr.To(false),
+					CreateUser:      ptr.To(false),
+					ConnectionLimit: ptr.To[int32](10),
+				},
+			},
+		},
+		"GroupsChanged": {
+			reason: "We should report the user as not up to date if it is not a member of exactly its groups",
+			fields: fields{
+				db: mockDB{
+					MockScan:  scanUser(false, false, "UNLIMITED"),
+					MockQuery: queryGroups("admins"),
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{Groups: []string{"readers"}}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.UserObservation{
+					CreateDB:   ptr.To(false),
+					CreateUser: ptr.To(false),
+					Groups:     []string{"admins"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.User); ok && err == nil {
+				if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db   xsql.DB
+		kube client.Client
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotUser),
+			},
+		},
+		"ErrCreateUser": {
+			reason: "Any errors encountered while creating the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateUser),
+			},
+		},
+		"Success": {
+			reason: "The user should be created with the password of its secret, its options and its groups",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != `CREATE USER "analyst" PASSWORD 'Secret1' CREATEDB NOCREATEUSER CONNECTION LIMIT 10 IN GROUP "analysts", "readers"` {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+					MockGetConnectionDetails: func(username, password string) managed.ConnectionDetails {
+						return managed.ConnectionDetails{
+							xpv1.ResourceCredentialsSecretUserKey:     []byte(username),
+							xpv1.ResourceCredentialsSecretPasswordKey: []byte(password),
+						}
+					},
+				},
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("Secret1")}
+						return nil
+					}),
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{
+					PasswordSecretRef: &xpv1.SecretKeySelector{Key: "password"},
+					CreateDB:          ptr.To(true),
+					CreateUser:        ptr.To(false),
+					ConnectionLimit:   ptr.To[int32](10),
+					Groups:            []string{"readers", "analysts"},
+				}),
+			},
+			want: want{
+				c: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("analyst"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("Secret1"),
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, kube: tc.fields.kube}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	withGroups := func(p v1alpha1.UserParameters, observed ...string) *v1alpha1.User {
+		cr := user(p)
+		cr.Status.AtProvider.Groups = observed
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotUser),
+		},
+		"ErrUpdateUser": {
+			reason: "Any errors encountered while altering the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: errors.Wrap(errBoom, errUpdateUser),
+		},
+		"ErrAddToGroup": {
+			reason: "Any errors encountered while adding the user to a group should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String == `ALTER GROUP "readers" ADD USER "analyst"` {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: withGroups(v1alpha1.UserParameters{Groups: []string{"readers"}}),
+			},
+			want: errors.Wrap(errBoom, errAddToGroup),
+		},
+		"Success": {
+			reason: "The user should be altered and added to and removed from groups",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case `ALTER USER "analyst" NOCREATEDB CONNECTION LIMIT UNLIMITED`,
+							`ALTER GROUP "readers" ADD USER "analyst"`,
+							`ALTER GROUP "admins" DROP USER "analyst"`:
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: withGroups(v1alpha1.UserParameters{CreateDB: ptr.To(false), Groups: []string{"analysts", "readers"}}, "admins", "analysts"),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotUser": {
+			reason: "An error should be returned if the managed resource is not a *User",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotUser),
+		},
+		"ErrDropUser": {
+			reason: "Errors dropping the user should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: errors.Wrap(errBoom, errDropUser),
+		},
+		"Success": {
+			reason: "No error should be returned if the user was dropped",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != `DROP USER IF EXISTS "analyst"` {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: user(v1alpha1.UserParameters{}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
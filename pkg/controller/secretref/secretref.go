@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretref triggers reconciles of managed resources when a Secret
+// they reference changes, either directly or as the credentials of their
+// ProviderConfig.
+package secretref
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// indexKey is the field index of the Secrets a managed resource
+	// references, formatted as namespace/name.
+	indexKey = "secretRefs"
+
+	// credentialsIndexKey is the field index of the credentials Secret a
+	// ProviderConfig references, formatted as namespace/name.
+	credentialsIndexKey = "credentialsSecretRef"
+
+	errIndex            = "cannot index managed resources by referenced secrets"
+	errIndexCredentials = "cannot index ProviderConfigs by credentials secret"
+)
+
+// An Extractor returns the Secrets referenced by the supplied object.
+type Extractor func(obj client.Object) []*xpv1.SecretKeySelector
+
+// EnqueueReferencing indexes objects of the supplied type by the Secrets they
+// reference and returns a handler that enqueues every object referencing a
+// Secret when that Secret changes. Passing the handler to Watches along with
+// a &corev1.Secret{} propagates changes within seconds instead of waiting for
+// the next poll.
+func EnqueueReferencing(mgr ctrl.Manager, obj client.Object, list client.ObjectList, extract Extractor) (handler.EventHandler, error) {
+	index := func(o client.Object) []string {
+		keys := []string{}
+		for _, ref := range extract(o) {
+			if ref != nil {
+				keys = append(keys, key(ref.Namespace, ref.Name))
+			}
+		}
+		return keys
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, indexKey, index); err != nil {
+		return nil, errors.Wrap(err, errIndex)
+	}
+
+	kube := mgr.GetClient()
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, s client.Object) []reconcile.Request {
+		return Requests(ctx, kube, list, s)
+	}), nil
+}
+
+// Requests returns a reconcile request for every object in the supplied list
+// type that references the supplied Secret. Objects are listed through the
+// index created by EnqueueReferencing.
+func Requests(ctx context.Context, kube client.Reader, list client.ObjectList, s client.Object) []reconcile.Request {
+	if _, ok := s.(*corev1.Secret); !ok {
+		return nil
+	}
+	l := list.DeepCopyObject().(client.ObjectList)
+	if err := kube.List(ctx, l, client.MatchingFields{indexKey: key(s.GetNamespace(), s.GetName())}); err != nil {
+		return nil
+	}
+	items, err := meta.ExtractList(l)
+	if err != nil {
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(items))
+	for _, i := range items {
+		o, ok := i.(client.Object)
+		if !ok {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}})
+	}
+	return reqs
+}
+
+// A CredentialsExtractor returns the credentials Secret referenced by the
+// supplied ProviderConfig, if any.
+type CredentialsExtractor func(pc client.Object) *xpv1.SecretReference
+
+// IndexProviderConfigs indexes ProviderConfigs of the supplied type by the
+// credentials Secret they reference, so that EnqueueUsingCredentials can find
+// those using a Secret that changed in the manager's cache.
+func IndexProviderConfigs(mgr ctrl.Manager, pc client.Object, extract CredentialsExtractor) error {
+	index := func(o client.Object) []string {
+		ref := extract(o)
+		if ref == nil {
+			return nil
+		}
+		return []string{key(ref.Namespace, ref.Name)}
+	}
+	return errors.Wrap(mgr.GetFieldIndexer().IndexField(context.Background(), pc, credentialsIndexKey, index), errIndexCredentials)
+}
+
+// EnqueueUsingCredentials returns a handler that enqueues every managed
+// resource of the supplied kind that uses a ProviderConfig whose credentials
+// Secret changed, so that it connects using the new credentials within
+// seconds instead of waiting for the next poll. ProviderConfigs are listed
+// through the index created by IndexProviderConfigs, and the managed
+// resources using them through their ProviderConfigUsages. Passing the
+// handler to Watches along with a &corev1.Secret{} lets Connect read the
+// ProviderConfig and its Secret from the manager's cache.
+func EnqueueUsingCredentials(kube client.Reader, pcs client.ObjectList, usages resource.ProviderConfigUsageList, gvk schema.GroupVersionKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, s client.Object) []reconcile.Request {
+		return CredentialsRequests(ctx, kube, pcs, usages, gvk, s)
+	})
+}
+
+// CredentialsRequests returns a reconcile request for every managed resource
+// of the supplied kind that uses a ProviderConfig in the supplied list type
+// whose credentials are the supplied Secret.
+func CredentialsRequests(ctx context.Context, kube client.Reader, pcs client.ObjectList, usages resource.ProviderConfigUsageList, gvk schema.GroupVersionKind, s client.Object) []reconcile.Request {
+	if _, ok := s.(*corev1.Secret); !ok {
+		return nil
+	}
+	pl := pcs.DeepCopyObject().(client.ObjectList)
+	if err := kube.List(ctx, pl, client.MatchingFields{credentialsIndexKey: key(s.GetNamespace(), s.GetName())}); err != nil {
+		return nil
+	}
+	items, err := meta.ExtractList(pl)
+	if err != nil {
+		return nil
+	}
+	reqs := []reconcile.Request{}
+	for _, i := range items {
+		pc, ok := i.(client.Object)
+		if !ok {
+			continue
+		}
+		ul := usages.DeepCopyObject().(resource.ProviderConfigUsageList)
+		if err := kube.List(ctx, ul, client.MatchingLabels{xpv1.LabelKeyProviderName: pc.GetName()}); err != nil {
+			continue
+		}
+		for _, u := range ul.GetItems() {
+			ref := u.GetResourceReference()
+			if ref.APIVersion != gvk.GroupVersion().String() || ref.Kind != gvk.Kind {
+				continue
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: ref.Name}})
+		}
+	}
+	return reqs
+}
+
+func key(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretref
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestRequests(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "password"}}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		obj    client.Object
+		want   []reconcile.Request
+	}{
+		"NotASecret": {
+			reason: "Objects other than Secrets should not enqueue anything",
+			kube:   &test.MockClient{},
+			obj:    &corev1.ConfigMap{},
+			want:   nil,
+		},
+		"ErrList": {
+			reason: "Nothing should be enqueued if referencing objects cannot be listed",
+			kube: &test.MockClient{
+				MockList: test.NewMockListFn(errors.New("boom")),
+			},
+			obj:  secret,
+			want: nil,
+		},
+		"Success": {
+			reason: "Every object referencing the Secret should be enqueued",
+			kube: &test.MockClient{
+				MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					if got := lo.FieldSelector.String(); got != indexKey+"=default/password" {
+						return errors.Errorf("unexpected field selector %q", got)
+					}
+					list.(*v1alpha1.RoleList).Items = []v1alpha1.Role{
+						{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+					}
+					return nil
+				},
+			},
+			obj: secret,
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "a"}},
+				{NamespacedName: types.NamespacedName{Name: "b"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Requests(context.Background(), tc.kube, &v1alpha1.RoleList{}, tc.obj)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRequests(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCredentialsRequests(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "crossplane-system", Name: "creds"}}
+	usage := func(name, kind string) v1alpha1.ProviderConfigUsage {
+		return v1alpha1.ProviderConfigUsage{ProviderConfigUsage: xpv1.ProviderConfigUsage{
+			ResourceReference: xpv1.TypedReference{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       kind,
+				Name:       name,
+			},
+		}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		obj    client.Object
+		want   []reconcile.Request
+	}{
+		"NotASecret": {
+			reason: "Objects other than Secrets should not enqueue anything",
+			kube:   &test.MockClient{},
+			obj:    &corev1.ConfigMap{},
+			want:   nil,
+		},
+		"ErrList": {
+			reason: "Nothing should be enqueued if ProviderConfigs cannot be listed",
+			kube: &test.MockClient{
+				MockList: test.NewMockListFn(errors.New("boom")),
+			},
+			obj:  secret,
+			want: nil,
+		},
+		"Success": {
+			reason: "Every managed resource of the kind using a ProviderConfig with the Secret as credentials should be enqueued",
+			kube: &test.MockClient{
+				MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					switch l := list.(type) {
+					case *v1alpha1.ProviderConfigList:
+						if got := lo.FieldSelector.String(); got != credentialsIndexKey+"=crossplane-system/creds" {
+							return errors.Errorf("unexpected field selector %q", got)
+						}
+						l.Items = []v1alpha1.ProviderConfig{{ObjectMeta: metav1.ObjectMeta{Name: "default"}}}
+					case *v1alpha1.ProviderConfigUsageList:
+						if got := lo.LabelSelector.String(); got != xpv1.LabelKeyProviderName+"=default" {
+							return errors.Errorf("unexpected label selector %q", got)
+						}
+						l.Items = []v1alpha1.ProviderConfigUsage{usage("a", v1alpha1.RoleKind), usage("b", v1alpha1.GrantKind)}
+					}
+					return nil
+				},
+			},
+			obj: secret,
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "a"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := CredentialsRequests(context.Background(), tc.kube, &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.RoleGroupVersionKind, tc.obj)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nCredentialsRequests(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
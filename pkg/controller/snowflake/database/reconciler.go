@@ -0,0 +1,235 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/snowflake"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotDatabase = "managed resource is not a Database custom resource"
+	errSelectDB    = "cannot select database"
+	errCreateDB    = "cannot create database"
+	errUpdateDB    = "cannot update database"
+	errDropDB      = "cannot drop database"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Database managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Database{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.DatabaseGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.DatabaseGroupKind))
+}
+
+// NewConnecter returns a connecter for Database managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: snowflake.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, account string, role, warehouse *string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return nil, errors.New(errNotDatabase)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := snowflake.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (SnowflakeConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	db := c.newDB(s.Data, pc.Spec.Account, pc.Spec.Role, pc.Spec.Warehouse, pc.Spec.ConnectionOptions)
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(db, snowflake.ConnectionLimits(pc)), c.log, v1alpha1.DatabaseKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDatabase)
+	}
+
+	name := meta.GetExternalName(cr)
+	rows, err := snowflake.Show(ctx, c.db, "SHOW DATABASES LIKE "+snowflake.QuoteValue(name))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectDB)
+	}
+
+	// LIKE is case-insensitive and treats _ as a wildcard, so pick the
+	// database that matches exactly.
+	i := slices.IndexFunc(rows, func(r map[string]string) bool { return r["name"] == name })
+	if i < 0 {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	observed := rows[i]
+
+	cr.Status.AtProvider.Owner = observed["owner"]
+	cr.SetConditions(xpv1.Available())
+
+	p := cr.Spec.ForProvider
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		ResourceUpToDate: (p.Comment == nil || *p.Comment == observed["comment"]) &&
+			(p.DataRetentionTimeInDays == nil || strconv.FormatInt(*p.DataRetentionTimeInDays, 10) == observed["retention_time"]),
+	}, nil
+}
+
+// properties returns the properties of the supplied database that can be
+// set when it is created or altered.
+func properties(p v1alpha1.DatabaseParameters) string {
+	out := ""
+	if p.DataRetentionTimeInDays != nil {
+		out += " DATA_RETENTION_TIME_IN_DAYS = " + strconv.FormatInt(*p.DataRetentionTimeInDays, 10)
+	}
+	if p.Comment != nil {
+		out += " COMMENT = " + snowflake.QuoteValue(*p.Comment)
+	}
+	return out
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDatabase)
+	}
+
+	p := cr.Spec.ForProvider
+	query := "CREATE DATABASE "
+	if p.Transient != nil && *p.Transient {
+		query = "CREATE TRANSIENT DATABASE "
+	}
+	query += snowflake.QuoteIdentifier(meta.GetExternalName(cr)) + properties(p)
+
+	if err := snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errCreateDB}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDatabase)
+	}
+
+	props := properties(cr.Spec.ForProvider)
+	if props == "" {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	query := "ALTER DATABASE " + snowflake.QuoteIdentifier(meta.GetExternalName(cr)) + " SET" + props
+	if err := snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errUpdateDB}); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Database)
+	if !ok {
+		return errors.New(errNotDatabase)
+	}
+
+	query := "DROP DATABASE IF EXISTS " + snowflake.QuoteIdentifier(meta.GetExternalName(cr))
+	return snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errDropDB})
+}
@@ -0,0 +1,402 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/snowflake"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/deletion"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/maintenance"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/offline"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/publisher"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/secretref"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/syncstatus"
+	"github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errConnOpts     = "ProviderConfig has invalid connectionOptions"
+	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret    = "cannot get credentials Secret"
+
+	errNotGrant     = "managed resource is not a Grant custom resource"
+	errNoRole       = "role is not set"
+	errNoDatabase   = "database must be set when schema or onFuture is set"
+	errNoPrivileges = "privileges must be set unless memberOf is set"
+	errSelectGrant  = "cannot show grants"
+	errCreateGrant  = "cannot create grant"
+	errRevokeGrant  = "cannot revoke grant"
+
+	privilegeOwnership = "OWNERSHIP"
+
+	maxConcurrency = 5
+)
+
+// Setup adds a controller that reconciles Grant managed resources.
+func Setup(mgr ctrl.Manager, o xpcontroller.Options) error {
+	name := managed.ControllerName(v1alpha1.GrantGroupKind)
+
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	rec := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
+		managed.WithExternalConnecter(maintenance.NewConnecter(deletion.NewConnecter(offline.NewConnecter(syncstatus.NewConnecter(NewConnecter(mgr.GetClient(), t, o.Logger), mgr.GetClient()), rec), rec), mgr.GetClient(), &v1alpha1.ProviderConfig{})),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithConnectionPublishers(publisher.ConnectionPublishers(mgr, o, v1alpha1.StoreConfigGroupVersionKind)...),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Grant{}).
+		Watches(&corev1.Secret{}, secretref.EnqueueUsingCredentials(mgr.GetClient(), &v1alpha1.ProviderConfigList{}, &v1alpha1.ProviderConfigUsageList{}, v1alpha1.GrantGroupVersionKind)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(tracing.NewReconciler(r, v1alpha1.GrantGroupKind))
+}
+
+// NewConnecter returns a connecter for Grant managed resources. It gets
+// ProviderConfigs and credentials using the supplied client, and tracks the
+// usage of ProviderConfigs using the supplied tracker.
+func NewConnecter(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter {
+	return &connector{kube: kube, usage: usage, newDB: snowflake.New, log: log}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, account string, role, warehouse *string, opts map[string]string) xsql.DB
+	log   logging.Logger
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return nil, errors.New(errNotGrant)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	if err := snowflake.ValidateConnectionOptions(pc.Spec.ConnectionOptions); err != nil {
+		return nil, errors.Wrap(err, errConnOpts)
+	}
+
+	// We don't need to check the credentials source because we currently only
+	// support one source (SnowflakeConnectionSecret), which is required and
+	// enforced by the ProviderConfig schema.
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+
+	db := c.newDB(s.Data, pc.Spec.Account, pc.Spec.Role, pc.Spec.Warehouse, pc.Spec.ConnectionOptions)
+	return &external{db: xsql.Instrument(xsql.WithConnectionLimits(db, snowflake.ConnectionLimits(pc)), c.log, v1alpha1.GrantKind, cr)}, nil
+}
+
+type external struct{ db xsql.DB }
+
+// target is the object that privileges are granted on, and how to find the
+// existing grants on it.
+type target struct {
+	// on is the object as it appears in GRANT and REVOKE statements, e.g.
+	// SCHEMA "db"."schema".
+	on string
+	// show lists the grants on the object.
+	show string
+	// match returns true if the supplied row of show is a grant to the
+	// grantee role.
+	match func(row map[string]string) bool
+}
+
+func newTarget(p v1alpha1.GrantParameters) (target, error) {
+	if p.Role == nil {
+		return target{}, errors.New(errNoRole)
+	}
+	if p.MemberOf != nil {
+		return target{}, nil
+	}
+	if len(p.Privileges) == 0 {
+		return target{}, errors.New(errNoPrivileges)
+	}
+	if p.Database == nil && (p.Schema != nil || p.OnFuture != nil) {
+		return target{}, errors.New(errNoDatabase)
+	}
+
+	role := *p.Role
+	// Future grants name their columns grant_on and grant_to rather than
+	// granted_on and granted_to.
+	toRole := func(to string) func(map[string]string) bool {
+		return func(r map[string]string) bool {
+			return r[to] == "ROLE" && r["grantee_name"] == role && r["privilege"] != privilegeOwnership
+		}
+	}
+
+	in := ""
+	switch {
+	case p.Schema != nil:
+		in = "SCHEMA " + snowflake.QualifiedName(*p.Database, *p.Schema)
+	case p.Database != nil:
+		in = "DATABASE " + snowflake.QualifiedName(*p.Database)
+	}
+
+	switch {
+	case p.OnFuture != nil:
+		// Future grants report the singular object type, e.g. TABLE.
+		kind := strings.TrimSuffix(*p.OnFuture, "S")
+		match := toRole("grant_to")
+		return target{
+			on:    "FUTURE " + *p.OnFuture + " IN " + in,
+			show:  "SHOW FUTURE GRANTS IN " + in,
+			match: func(r map[string]string) bool { return r["grant_on"] == kind && match(r) },
+		}, nil
+	case in != "":
+		return target{
+			on:    in,
+			show:  "SHOW GRANTS ON " + in,
+			match: toRole("granted_to"),
+		}, nil
+	default:
+		match := toRole("granted_to")
+		return target{
+			on:    "ACCOUNT",
+			show:  "SHOW GRANTS TO ROLE " + snowflake.QuoteIdentifier(role),
+			match: func(r map[string]string) bool { return r["granted_on"] == "ACCOUNT" && match(r) },
+		}, nil
+	}
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	t, err := newTarget(p)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	if p.MemberOf != nil {
+		return c.observeMembership(ctx, cr)
+	}
+
+	rows, err := snowflake.Show(ctx, c.db, t.show)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+
+	var observed []string
+	for _, r := range rows {
+		if t.match(r) {
+			observed = append(observed, r["privilege"])
+		}
+	}
+	if len(observed) == 0 {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	sort.Strings(observed)
+
+	cr.Status.AtProvider.Privileges = observed
+	cr.SetConditions(xpv1.Available())
+
+	toGrant, toRevoke := diffPermissions(p.Privileges.ToStringSlice(), observed)
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(toGrant) == 0 && len(toRevoke) == 0,
+	}, nil
+}
+
+func (c *external) observeMembership(ctx context.Context, cr *v1alpha1.Grant) (managed.ExternalObservation, error) {
+	p := cr.Spec.ForProvider
+	rows, err := snowflake.Show(ctx, c.db, "SHOW GRANTS OF ROLE "+snowflake.QuoteIdentifier(*p.MemberOf))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectGrant)
+	}
+
+	if !slices.ContainsFunc(rows, func(r map[string]string) bool { return r["granted_to"] == "ROLE" && r["grantee_name"] == *p.Role }) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	// Role memberships have no parameters that can be updated.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	t, err := newTarget(p)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	var query string
+	if p.MemberOf != nil {
+		query = fmt.Sprintf("GRANT ROLE %s TO ROLE %s", snowflake.QuoteIdentifier(*p.MemberOf), snowflake.QuoteIdentifier(*p.Role))
+	} else {
+		query = createGrantQuery(p, t, p.Privileges.ToStringSlice())
+	}
+
+	if err := snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	t, err := newTarget(p)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if p.MemberOf != nil {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	toGrant, toRevoke := diffPermissions(p.Privileges.ToStringSlice(), cr.Status.AtProvider.Privileges)
+
+	if len(toRevoke) > 0 {
+		query := createRevokeQuery(p, t, toRevoke)
+		if err := snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errRevokeGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if len(toGrant) > 0 {
+		query := createGrantQuery(p, t, toGrant)
+		if err := snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errCreateGrant}); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Grant)
+	if !ok {
+		return errors.New(errNotGrant)
+	}
+
+	p := cr.Spec.ForProvider
+	t, err := newTarget(p)
+	if err != nil {
+		return err
+	}
+
+	var query string
+	if p.MemberOf != nil {
+		query = fmt.Sprintf("REVOKE ROLE %s FROM ROLE %s", snowflake.QuoteIdentifier(*p.MemberOf), snowflake.QuoteIdentifier(*p.Role))
+	} else {
+		query = createRevokeQuery(p, t, p.Privileges.ToStringSlice())
+	}
+
+	return snowflake.ExecWrapper(ctx, c.db, snowflake.ExecQuery{Query: query, ErrorValue: errRevokeGrant})
+}
+
+func createGrantQuery(p v1alpha1.GrantParameters, t target, privileges []string) string {
+	sort.Strings(privileges)
+	result := fmt.Sprintf("GRANT %s ON %s TO ROLE %s",
+		strings.Join(privileges, ", "),
+		t.on,
+		snowflake.QuoteIdentifier(*p.Role),
+	)
+	if p.WithGrantOption != nil && *p.WithGrantOption {
+		result += " WITH GRANT OPTION"
+	}
+	return result
+}
+
+func createRevokeQuery(p v1alpha1.GrantParameters, t target, privileges []string) string {
+	sort.Strings(privileges)
+	return fmt.Sprintf("REVOKE %s ON %s FROM ROLE %s",
+		strings.Join(privileges, ", "),
+		t.on,
+		snowflake.QuoteIdentifier(*p.Role),
+	)
+}
+
+func diffPermissions(desired, observed []string) ([]string, []string) {
+	return subtract(desired, observed), subtract(observed, desired)
+}
+
+// subtract returns the elements of a that are not in b.
+func subtract(a, b []string) []string {
+	bMap := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		bMap[v] = struct{}{}
+	}
+
+	var out []string
+	for _, v := range a {
+		if _, ok := bMap[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
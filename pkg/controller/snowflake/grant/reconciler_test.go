@@ -0,0 +1,463 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grant
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func grant(p v1alpha1.GrantParameters) *v1alpha1.Grant {
+	p.Role = ptr.To("analyst")
+	return &v1alpha1.Grant{
+		Spec: v1alpha1.GrantSpec{ForProvider: p},
+	}
+}
+
+func showQuery(want string, rows *sqlmock.Rows) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		if q.String != want {
+			return nil, errors.Errorf("unexpected query: %s", q.String)
+		}
+		return mockRowsToSQLRows(rows), nil
+	}
+}
+
+func execQueries(want ...string) func(ctx context.Context, q xsql.Query) error {
+	return func(ctx context.Context, q xsql.Query) error {
+		for _, w := range want {
+			if q.String == w {
+				return nil
+			}
+		}
+		return errors.Errorf("unexpected query: %s", q.String)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		atProvider v1alpha1.GrantObservation
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotGrant),
+			},
+		},
+		"ErrNoDatabase": {
+			reason: "An error should be returned if a schema is set without a database",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"USAGE"}, Schema: ptr.To("raw")}),
+			},
+			want: want{
+				err: errors.New(errNoDatabase),
+			},
+		},
+		"ErrSelectGrant": {
+			reason: "We should return any errors encountered while trying to show the grants",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE DATABASE"}}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectGrant),
+			},
+		},
+		"SuccessSchema": {
+			reason: "Privileges granted to the role on the schema should be observed",
+			fields: fields{
+				db: mockDB{
+					MockQuery: showQuery(`SHOW GRANTS ON SCHEMA "analytics"."raw"`,
+						sqlmock.NewRows([]string{"privilege", "granted_on", "granted_to", "grantee_name"}).
+							AddRow("OWNERSHIP", "SCHEMA", "ROLE", "analyst").
+							AddRow("USAGE", "SCHEMA", "ROLE", "analyst").
+							AddRow("MONITOR", "SCHEMA", "ROLE", "analyst").
+							AddRow("USAGE", "SCHEMA", "ROLE", "loader")),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges: v1alpha1.GrantPrivileges{"USAGE"},
+					Database:   ptr.To("analytics"),
+					Schema:     ptr.To("raw"),
+				}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				atProvider: v1alpha1.GrantObservation{Privileges: []string{"MONITOR", "USAGE"}},
+			},
+		},
+		"SuccessFuture": {
+			reason: "Future privileges granted to the role in the schema should be observed",
+			fields: fields{
+				db: mockDB{
+					MockQuery: showQuery(`SHOW FUTURE GRANTS IN SCHEMA "analytics"."raw"`,
+						sqlmock.NewRows([]string{"privilege", "grant_on", "grant_to", "grantee_name"}).
+							AddRow("SELECT", "TABLE", "ROLE", "analyst").
+							AddRow("SELECT", "VIEW", "ROLE", "analyst")),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+					Database:   ptr.To("analytics"),
+					Schema:     ptr.To("raw"),
+					OnFuture:   ptr.To("TABLES"),
+				}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				atProvider: v1alpha1.GrantObservation{Privileges: []string{"SELECT"}},
+			},
+		},
+		"NoMembership": {
+			reason: "We should return ResourceExists: false when the role is not a member of the role",
+			fields: fields{
+				db: mockDB{
+					MockQuery: showQuery(`SHOW GRANTS OF ROLE "reader"`,
+						sqlmock.NewRows([]string{"role", "granted_to", "grantee_name"}).
+							AddRow("reader", "USER", "analyst")),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("reader")}),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.args.mg.(*v1alpha1.Grant); ok {
+				if diff := cmp.Diff(tc.want.atProvider, cr.Status.AtProvider); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want status.atProvider, +got status.atProvider:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrNoPrivileges": {
+			reason: "An error should be returned if neither privileges nor memberOf are set",
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{}),
+			},
+			want: errors.New(errNoPrivileges),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE DATABASE"}}),
+			},
+			want: errors.Wrap(errBoom, errCreateGrant),
+		},
+		"SuccessAccount": {
+			reason: "Privileges should be granted on the account if no database is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT CREATE DATABASE ON ACCOUNT TO ROLE "analyst" WITH GRANT OPTION`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges:      v1alpha1.GrantPrivileges{"CREATE DATABASE"},
+					WithGrantOption: ptr.To(true),
+				}),
+			},
+			want: nil,
+		},
+		"SuccessFuture": {
+			reason: "Privileges should be granted on future objects in the database",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT INSERT, SELECT ON FUTURE TABLES IN DATABASE "analytics" TO ROLE "analyst"`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{
+					Privileges: v1alpha1.GrantPrivileges{"SELECT", "INSERT"},
+					Database:   ptr.To("analytics"),
+					OnFuture:   ptr.To("TABLES"),
+				}),
+			},
+			want: nil,
+		},
+		"SuccessMembership": {
+			reason: "The role should be granted to the grantee role if memberOf is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`GRANT ROLE "reader" TO ROLE "analyst"`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("reader")}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"Success": {
+			reason: "Removed privileges should be revoked and added ones granted",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(
+						`REVOKE MONITOR ON DATABASE "analytics" FROM ROLE "analyst"`,
+						`GRANT CREATE SCHEMA ON DATABASE "analytics" TO ROLE "analyst"`,
+					),
+				},
+			},
+			args: args{
+				mg: func() *v1alpha1.Grant {
+					cr := grant(v1alpha1.GrantParameters{
+						Privileges: v1alpha1.GrantPrivileges{"USAGE", "CREATE SCHEMA"},
+						Database:   ptr.To("analytics"),
+					})
+					cr.Status.AtProvider.Privileges = []string{"MONITOR", "USAGE"}
+					return cr
+				}(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotGrant": {
+			reason: "An error should be returned if the managed resource is not a *Grant",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotGrant),
+		},
+		"ErrRevoke": {
+			reason: "Errors revoking the grant should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{Privileges: v1alpha1.GrantPrivileges{"CREATE DATABASE"}}),
+			},
+			want: errors.Wrap(errBoom, errRevokeGrant),
+		},
+		"SuccessMembership": {
+			reason: "The role should be revoked from the grantee role if memberOf is set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: execQueries(`REVOKE ROLE "reader" FROM ROLE "analyst"`),
+				},
+			},
+			args: args{
+				mg: grant(v1alpha1.GrantParameters{MemberOf: ptr.To("reader")}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
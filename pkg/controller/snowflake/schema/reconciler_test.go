@@ -0,0 +1,390 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/crossplane-contrib/provider-sql/apis/snowflake/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return m.MockQuery(ctx, q)
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		println("%v", err)
+		return nil
+	}
+	return rows
+}
+
+func schema(p v1alpha1.SchemaParameters) *v1alpha1.Schema {
+	if p.Database == nil {
+		p.Database = ptr.To("example_db")
+	}
+	return &v1alpha1.Schema{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "example_schema"},
+		},
+		Spec: v1alpha1.SchemaSpec{ForProvider: p},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotSchema),
+			},
+		},
+		"ErrNoDatabase": {
+			reason: "An error should be returned if the database of the schema is not set",
+			args: args{
+				mg: &v1alpha1.Schema{},
+			},
+			want: want{
+				err: errors.New(errNoDatabase),
+			},
+		},
+		"ErrSelectSchema": {
+			reason: "We should return any errors encountered while trying to show the schema",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectSchema),
+			},
+		},
+		"Success": {
+			reason: "We should report the schema as up to date if its properties match",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						if q.String != `SHOW SCHEMAS LIKE 'example_schema' IN DATABASE "example_db"` {
+							return nil, errors.Errorf("unexpected query: %s", q.String)
+						}
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"name", "owner", "comment", "options", "retention_time"}).
+							AddRow("example_schema", "SYSADMIN", "", "MANAGED ACCESS", "1")), nil
+					},
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{ManagedAccess: ptr.To(true)}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"ManagedAccessChanged": {
+			reason: "We should report the schema as not up to date if managed access differs",
+			fields: fields{
+				db: mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"name", "owner", "comment", "options", "retention_time"}).
+							AddRow("example_schema", "SYSADMIN", "", "", "1")), nil
+					},
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{ManagedAccess: ptr.To(true)}),
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSchema),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the schema should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: errors.Wrap(errBoom, errCreateSchema),
+		},
+		"Success": {
+			reason: "The schema should be created in its database with its properties",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if q.String != `CREATE SCHEMA "example_db"."example_schema" WITH MANAGED ACCESS COMMENT = 'raw data'` {
+							return errors.Errorf("unexpected query: %s", q.String)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{
+					ManagedAccess: ptr.To(true),
+					Comment:       ptr.To("raw data"),
+				}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSchema),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while altering the schema should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{Comment: ptr.To("raw data")}),
+			},
+			want: errors.Wrap(errBoom, errUpdateSchema),
+		},
+		"Success": {
+			reason: "The properties and managed access of the schema should be set",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case `ALTER SCHEMA "example_db"."example_schema" SET DATA_RETENTION_TIME_IN_DAYS = 3`,
+							`ALTER SCHEMA "example_db"."example_schema" DISABLE MANAGED ACCESS`:
+							return nil
+						}
+						return errors.Errorf("unexpected query: %s", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{
+					DataRetentionTimeInDays: ptr.To[int64](3),
+					ManagedAccess:           ptr.To(false),
+				}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   error
+	}{
+		"ErrNotSchema": {
+			reason: "An error should be returned if the managed resource is not a *Schema",
+			args: args{
+				mg: nil,
+			},
+			want: errors.New(errNotSchema),
+		},
+		"ErrDropSchema": {
+			reason: "Errors dropping a schema should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: schema(v1alpha1.SchemaParameters{}),
+			},
+			want: errors.Wrap(errBoom, errDropSchema),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			err := e.Delete(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
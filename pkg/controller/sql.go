@@ -18,21 +18,33 @@ package controller
 
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/clickhouse"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mssql"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/oracle"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/redshift"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/snowflake"
 )
 
 // Setup creates all PostgreSQL controllers with the supplied logger and adds
 // them to the supplied manager.
 func Setup(mgr ctrl.Manager, l controller.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+		clickhouse.Setup,
 		mssql.Setup,
 		mysql.Setup,
+		oracle.Setup,
 		postgresql.Setup,
+		redshift.Setup,
+		snowflake.Setup,
 	} {
 		if err := setup(mgr, l); err != nil {
 			return err
@@ -40,3 +52,27 @@ func Setup(mgr ctrl.Manager, l controller.Options) error {
 	}
 	return nil
 }
+
+// A NewConnecterFn returns the connecter of a managed resource kind, which
+// gets ProviderConfigs and credentials using the supplied client.
+type NewConnecterFn func(kube client.Client, usage resource.Tracker, log logging.Logger) managed.ExternalConnecter
+
+// Connecters returns the constructors of the connecters of all managed
+// resources, keyed by their group kind, e.g. Grant.postgresql.sql.crossplane.io.
+func Connecters() map[string]NewConnecterFn {
+	out := map[string]NewConnecterFn{}
+	for _, cs := range []map[string]func(client.Client, resource.Tracker, logging.Logger) managed.ExternalConnecter{
+		clickhouse.Connecters(),
+		mssql.Connecters(),
+		mysql.Connecters(),
+		oracle.Connecters(),
+		postgresql.Connecters(),
+		redshift.Connecters(),
+		snowflake.Connecters(),
+	} {
+		for gk, fn := range cs {
+			out[gk] = fn
+		}
+	}
+	return out
+}
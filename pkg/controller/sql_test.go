@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis"
+)
+
+// TestConnecters ensures that the connecter of every managed resource kind
+// this provider serves can be looked up, e.g. by sqlctl.
+func TestConnecters(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("apis.AddToScheme(...): %v", err)
+	}
+
+	c := Connecters()
+	for gvk := range s.AllKnownTypes() {
+		obj, err := s.New(gvk)
+		if err != nil {
+			t.Fatalf("s.New(%s): %v", gvk, err)
+		}
+		if _, ok := obj.(resource.Managed); !ok {
+			continue
+		}
+		if _, ok := c[gvk.GroupKind().String()]; !ok {
+			t.Errorf("Connecters(): no connecter for %s", gvk.GroupKind())
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package syncstatus records the outcome of reconciling managed resources in
+// their status, i.e. the last error and the last successful sync, so that
+// they can be monitored without scraping logs.
+package syncstatus
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// The operations an error may occur during.
+const (
+	OperationConnect = "Connect"
+	OperationObserve = "Observe"
+	OperationCreate  = "Create"
+	OperationUpdate  = "Update"
+	OperationDelete  = "Delete"
+)
+
+// maxMessageLength is the length after which error messages are truncated.
+const maxMessageLength = 1024
+
+// RefreshInterval is how often LastSuccessfulSync is refreshed while a
+// managed resource stays in sync. Refreshing it on every reconcile would
+// change the status, and so trigger another reconcile, every time.
+var RefreshInterval = time.Hour
+
+// An Object is a managed resource that records its SyncStatus.
+type Object interface {
+	resource.Managed
+	GetSyncStatus() *common.SyncStatus
+}
+
+// Message returns the supplied error's message with string literals, which
+// may hold secrets or data, redacted and truncated to a length that fits in
+// the status of a managed resource.
+func Message(err error) string {
+	m := xsql.Redact(err.Error(), xsql.Dialect{})
+	if len(m) > maxMessageLength {
+		m = strings.ToValidUTF8(m[:maxMessageLength], "") + "..."
+	}
+	return m
+}
+
+// Failed records that the supplied operation failed with the supplied
+// error. It returns false if the status is unchanged because the error
+// recurs, i.e. the same operation failed with the same message, and the
+// resource has not been in sync since.
+func Failed(s *common.SyncStatus, operation string, err error, now time.Time) bool {
+	msg := Message(err)
+	if e := s.LastSQLError; e != nil && e.Operation == operation && e.Message == msg && !e.Time.Before(s.LastSuccessfulSync) {
+		return false
+	}
+	s.LastSQLError = &common.SQLError{Operation: operation, Message: msg, Time: metav1.NewTime(now)}
+	return true
+}
+
+// Synced records that the external resource was observed to match the
+// managed resource. It returns false if the status is unchanged because the
+// resource was already in sync less than RefreshInterval ago, and has not
+// failed since.
+func Synced(s *common.SyncStatus, now time.Time) bool {
+	last := s.LastSuccessfulSync
+	if last != nil && now.Sub(last.Time) < RefreshInterval && (s.LastSQLError == nil || s.LastSQLError.Time.Before(last)) {
+		return false
+	}
+	Made(s, now)
+	return true
+}
+
+// Made records that the external resource was made to match the managed
+// resource, e.g. that it was created.
+func Made(s *common.SyncStatus, now time.Time) {
+	t := metav1.NewTime(now)
+	s.LastSuccessfulSync = &t
+}
+
+// A Connecter wraps an ExternalConnecter. It records the errors returned by,
+// and the successful syncs of, the managed resources it connects to that are
+// Objects. The managed reconciler persists them when it updates the status
+// of the resource.
+type Connecter struct {
+	managed.ExternalConnecter
+	kube client.Client
+	now  func() time.Time
+}
+
+// NewConnecter returns a Connecter that wraps the supplied ExternalConnecter.
+// The supplied client persists the status of resources that are created, as
+// the managed reconciler replaces it before it updates their status.
+func NewConnecter(c managed.ExternalConnecter, kube client.Client) *Connecter {
+	return &Connecter{ExternalConnecter: c, kube: kube, now: time.Now}
+}
+
+// Connect to the external resource, recording the error if that fails.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	o, ok := mg.(Object)
+	if !ok {
+		return c.ExternalConnecter.Connect(ctx, mg)
+	}
+	ext, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		Failed(o.GetSyncStatus(), OperationConnect, err, c.now())
+		return nil, err
+	}
+	return &external{ExternalClient: ext, c: c}, nil
+}
+
+// external is an ExternalClient of a managed resource that is an Object.
+type external struct {
+	managed.ExternalClient
+	c *Connecter
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	switch {
+	case err != nil:
+		Failed(mg.(Object).GetSyncStatus(), OperationObserve, err, e.c.now())
+	case o.ResourceExists && o.ResourceUpToDate && !meta.WasDeleted(mg):
+		Synced(mg.(Object).GetSyncStatus(), e.c.now())
+	}
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, err := e.ExternalClient.Create(ctx, mg)
+	s := mg.(Object).GetSyncStatus()
+	if err != nil {
+		if Failed(s, OperationCreate, err, e.c.now()) {
+			e.persist(ctx, mg)
+		}
+		return cr, err
+	}
+	Made(s, e.c.now())
+	e.persist(ctx, mg)
+	return cr, nil
+}
+
+// persist the status of the supplied managed resource. The managed
+// reconciler updates a resource it created, or failed to create, to record
+// that it did, which replaces its status with the stored one. The status is
+// updated first, using a copy so that changes to the resource made by Create
+// are kept, and the resource takes on the new resource version so that the
+// reconciler's update does not conflict. Recording the sync status is best
+// effort, so a status that cannot be updated is dropped.
+func (e *external) persist(ctx context.Context, mg resource.Managed) {
+	cp, ok := mg.DeepCopyObject().(client.Object)
+	if !ok || e.c.kube.Status().Update(ctx, cp) != nil {
+		return
+	}
+	mg.SetResourceVersion(cp.GetResourceVersion())
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.ExternalClient.Update(ctx, mg)
+	if err != nil {
+		Failed(mg.(Object).GetSyncStatus(), OperationUpdate, err, e.c.now())
+		return u, err
+	}
+	// An update is recorded like an up to date observation, so that a
+	// resource that is never observed to be up to date does not change its
+	// status, and thus reconcile again, on every update.
+	Synced(mg.(Object).GetSyncStatus(), e.c.now())
+	return u, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.ExternalClient.Delete(ctx, mg)
+	if err != nil {
+		Failed(mg.(Object).GetSyncStatus(), OperationDelete, err, e.c.now())
+	}
+	return err
+}
@@ -0,0 +1,353 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncstatus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/common"
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func at(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+func TestMessage(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"Redacted": {
+			err:  errors.New("cannot create role: invalid value 's3cr3t' for password"),
+			want: "cannot create role: invalid value '<redacted>' for password",
+		},
+		"Truncated": {
+			err:  errors.New(strings.Repeat("a", maxMessageLength+10)),
+			want: strings.Repeat("a", maxMessageLength) + "...",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, Message(tc.err)); diff != "" {
+				t.Errorf("Message(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailed(t *testing.T) {
+	errBoom := errors.New("boom")
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := then.Add(time.Hour)
+	boom := func(t time.Time) *common.SQLError {
+		return &common.SQLError{Operation: OperationObserve, Message: "boom", Time: *at(t)}
+	}
+
+	cases := map[string]struct {
+		reason      string
+		s           common.SyncStatus
+		operation   string
+		want        common.SyncStatus
+		wantChanged bool
+	}{
+		"First": {
+			reason:      "The first error should be recorded",
+			operation:   OperationObserve,
+			want:        common.SyncStatus{LastSQLError: boom(now)},
+			wantChanged: true,
+		},
+		"Recurs": {
+			reason:    "An error that recurs should keep the time it first occurred",
+			s:         common.SyncStatus{LastSQLError: boom(then)},
+			operation: OperationObserve,
+			want:      common.SyncStatus{LastSQLError: boom(then)},
+		},
+		"RecursAfterSync": {
+			reason:      "An error that occurs again after the resource was in sync should be recorded anew",
+			s:           common.SyncStatus{LastSQLError: boom(then), LastSuccessfulSync: at(then.Add(time.Minute))},
+			operation:   OperationObserve,
+			want:        common.SyncStatus{LastSQLError: boom(now), LastSuccessfulSync: at(then.Add(time.Minute))},
+			wantChanged: true,
+		},
+		"OtherOperation": {
+			reason:      "The same error returned by another operation should be recorded",
+			s:           common.SyncStatus{LastSQLError: boom(then)},
+			operation:   OperationUpdate,
+			want:        common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationUpdate, Message: "boom", Time: *at(now)}},
+			wantChanged: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			changed := Failed(&tc.s, tc.operation, errBoom, now)
+			if diff := cmp.Diff(tc.want, tc.s); diff != "" {
+				t.Errorf("\n%s\nFailed(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("\n%s\nFailed(...): want changed %t, got %t", tc.reason, tc.wantChanged, changed)
+			}
+		})
+	}
+}
+
+func TestSynced(t *testing.T) {
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := then.Add(time.Minute)
+	boom := &common.SQLError{Operation: OperationObserve, Message: "boom", Time: *at(then.Add(time.Second))}
+
+	cases := map[string]struct {
+		reason      string
+		s           common.SyncStatus
+		now         time.Time
+		want        common.SyncStatus
+		wantChanged bool
+	}{
+		"First": {
+			reason:      "The first successful sync should be recorded",
+			now:         now,
+			want:        common.SyncStatus{LastSuccessfulSync: at(now)},
+			wantChanged: true,
+		},
+		"Recent": {
+			reason: "A sync should not be recorded while the last one is recent",
+			s:      common.SyncStatus{LastSuccessfulSync: at(then)},
+			now:    now,
+			want:   common.SyncStatus{LastSuccessfulSync: at(then)},
+		},
+		"Stale": {
+			reason:      "A sync should be recorded once the last one is older than the refresh interval",
+			s:           common.SyncStatus{LastSuccessfulSync: at(then)},
+			now:         then.Add(RefreshInterval),
+			want:        common.SyncStatus{LastSuccessfulSync: at(then.Add(RefreshInterval))},
+			wantChanged: true,
+		},
+		"FailedSince": {
+			reason:      "A sync should be recorded if the resource failed since the last one",
+			s:           common.SyncStatus{LastSQLError: boom, LastSuccessfulSync: at(then)},
+			now:         now,
+			want:        common.SyncStatus{LastSQLError: boom, LastSuccessfulSync: at(now)},
+			wantChanged: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			changed := Synced(&tc.s, tc.now)
+			if diff := cmp.Diff(tc.want, tc.s); diff != "" {
+				t.Errorf("\n%s\nSynced(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("\n%s\nSynced(...): want changed %t, got %t", tc.reason, tc.wantChanged, changed)
+			}
+		})
+	}
+}
+
+func TestExternal(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		status          common.SyncStatus
+		resourceVersion string
+	}
+
+	cases := map[string]struct {
+		reason string
+		ext    managed.ExternalClient
+		call   func(ctx context.Context, e managed.ExternalClient, mg resource.Managed)
+		want   want
+	}{
+		"ObserveError": {
+			reason: "Errors returned by Observe should be recorded",
+			ext: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errBoom
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Observe(ctx, mg)
+			},
+			want: want{
+				status: common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationObserve, Message: "boom", Time: *at(now)}},
+			},
+		},
+		"ObserveUpToDate": {
+			reason: "Observing an up to date resource should record a successful sync",
+			ext: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Observe(ctx, mg)
+			},
+			want: want{
+				status: common.SyncStatus{LastSuccessfulSync: at(now)},
+			},
+		},
+		"ObserveNotUpToDate": {
+			reason: "Observing a resource that is not up to date should not record a sync",
+			ext: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true}, nil
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Observe(ctx, mg)
+			},
+		},
+		"CreateError": {
+			reason: "Errors returned by Create should be recorded and persisted",
+			ext: &managed.ExternalClientFns{
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					return managed.ExternalCreation{}, errBoom
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Create(ctx, mg)
+			},
+			want: want{
+				status:          common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationCreate, Message: "boom", Time: *at(now)}},
+				resourceVersion: "2",
+			},
+		},
+		"Create": {
+			reason: "A successful Create should be recorded as a sync and persisted",
+			ext: &managed.ExternalClientFns{
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					return managed.ExternalCreation{}, nil
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Create(ctx, mg)
+			},
+			want: want{
+				status:          common.SyncStatus{LastSuccessfulSync: at(now)},
+				resourceVersion: "2",
+			},
+		},
+		"UpdateError": {
+			reason: "Errors returned by Update should be recorded",
+			ext: &managed.ExternalClientFns{
+				UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+					return managed.ExternalUpdate{}, errBoom
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Update(ctx, mg)
+			},
+			want: want{
+				status: common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationUpdate, Message: "boom", Time: *at(now)}},
+			},
+		},
+		"Update": {
+			reason: "A successful Update should be recorded as a sync",
+			ext: &managed.ExternalClientFns{
+				UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+					return managed.ExternalUpdate{}, nil
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_, _ = e.Update(ctx, mg)
+			},
+			want: want{
+				status: common.SyncStatus{LastSuccessfulSync: at(now)},
+			},
+		},
+		"DeleteError": {
+			reason: "Errors returned by Delete should be recorded",
+			ext: &managed.ExternalClientFns{
+				DeleteFn: func(_ context.Context, _ resource.Managed) error {
+					return errBoom
+				},
+			},
+			call: func(ctx context.Context, e managed.ExternalClient, mg resource.Managed) {
+				_ = e.Delete(ctx, mg)
+			},
+			want: want{
+				status: common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationDelete, Message: "boom", Time: *at(now)}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+					obj.SetResourceVersion("2")
+					return nil
+				},
+			}
+			c := &Connecter{
+				ExternalConnecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+					return tc.ext, nil
+				}),
+				kube: kube,
+				now:  func() time.Time { return now },
+			}
+			role := &v1alpha1.Role{}
+			e, err := c.Connect(context.Background(), role)
+			if err != nil {
+				t.Fatalf("c.Connect(...): %v", err)
+			}
+			tc.call(context.Background(), e, role)
+			if diff := cmp.Diff(tc.want.status, role.Status.SyncStatus); diff != "" {
+				t.Errorf("\n%s\n-want status, +got status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resourceVersion, role.GetResourceVersion()); diff != "" {
+				t.Errorf("\n%s\n-want resource version, +got resource version:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &Connecter{
+		ExternalConnecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+			return nil, errBoom
+		}),
+		now: func() time.Time { return now },
+	}
+	role := &v1alpha1.Role{}
+	if _, err := c.Connect(context.Background(), role); !errors.Is(err, errBoom) {
+		t.Fatalf("c.Connect(...): want %v, got %v", errBoom, err)
+	}
+	want := common.SyncStatus{LastSQLError: &common.SQLError{Operation: OperationConnect, Message: "boom", Time: *at(now)}}
+	if diff := cmp.Diff(want, role.Status.SyncStatus); diff != "" {
+		t.Errorf("c.Connect(...): -want status, +got status:\n%s", diff)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// permissionPattern is the validation of GrantPermission in the API.
+// Permissions are keywords, so they cannot be quoted and must be validated
+// instead.
+var permissionPattern = regexp.MustCompile(`^[A-Z_ ]+$`)
+
+// skeleton returns the supplied statement with each quoted identifier and
+// string literal replaced by a question mark, or false if one of them is not
+// terminated. Any user input that escaped its quotes shows up in the
+// skeleton.
+func skeleton(stmt string) (string, bool) {
+	var out strings.Builder
+	for i := 0; i < len(stmt); i++ {
+		var q byte
+		switch stmt[i] {
+		case '[':
+			q = ']'
+		case '\'':
+			q = '\''
+		default:
+			out.WriteByte(stmt[i])
+			continue
+		}
+		end := closing(stmt, i+1, q)
+		if end < 0 {
+			return "", false
+		}
+		out.WriteByte('?')
+		i = end
+	}
+	return out.String(), true
+}
+
+// closing returns the index of the quote that terminates the quoted
+// identifier or string literal starting at i, or -1 if there is none.
+func closing(s string, i int, q byte) int {
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == q && i+1 < len(s) && s[i+1] == q:
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, s := range []string{"", "example", "a]b", "a'b", "]; DROP USER x; --", "]]", "'", "["} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, q := range []string{QuoteIdentifier(s), QuoteValue(s)} {
+			if got, ok := skeleton(q); !ok || got != "?" {
+				t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", q, "?", got, ok)
+			}
+		}
+	})
+}
+
+func FuzzGrantQuery(f *testing.F) {
+	f.Add("dbo", "user", "SELECT", true)
+	f.Add("a]b", "] TO [public]; --", "CREATE TABLE", true)
+	f.Add("", "u'", "ALTER", false)
+	f.Add("]; --", "user", "IMPERSONATE", true)
+	f.Fuzz(func(t *testing.T, securable, user, permission string, onSchema bool) {
+		if !permissionPattern.MatchString(permission) {
+			t.Skip()
+		}
+		// An empty ON clause leaves two spaces between the permission
+		// and TO.
+		var sc *string
+		on := "  "
+		if onSchema {
+			sc = &securable
+			on = " ON SCHEMA::? "
+		}
+
+		cases := map[string]string{
+			GrantQuery([]string{permission}, sc, user):                   "GRANT " + permission + on + "TO ?",
+			RevokeQuery([]string{permission}, sc, user):                  "REVOKE " + permission + on + "FROM ?",
+			GrantOnQuery([]string{permission}, OnUser(securable), user):  "GRANT " + permission + " ON USER::? TO ?",
+			RevokeOnQuery([]string{permission}, OnUser(securable), user): "REVOKE " + permission + " ON USER::? FROM ?",
+		}
+		for stmt, want := range cases {
+			if got, ok := skeleton(stmt); !ok || got != want {
+				t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", stmt, want, got, ok)
+			}
+		}
+	})
+}
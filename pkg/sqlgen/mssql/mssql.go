@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mssql generates the SQL statements the provider uses to manage
+// MSSQL users and grants, and diffs the permissions they describe. It has no
+// dependency on the provider's API types, so that other tools can generate
+// the exact statements the provider executes.
+package mssql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// QuoteIdentifier quotes the supplied identifier, e.g. a user or schema
+// name, in square brackets, doubling any closing brackets it contains.
+func QuoteIdentifier(id string) string {
+	return "[" + strings.ReplaceAll(id, "]", "]]") + "]"
+}
+
+// QuoteValue quotes the supplied string literal, doubling any single quotes
+// it contains.
+func QuoteValue(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// OnSchema returns the ON clause of a grant on the supplied schema, or an
+// empty string if the grant is on the database.
+func OnSchema(schema *string) string {
+	if schema == nil {
+		return ""
+	}
+	return fmt.Sprintf("ON SCHEMA::%s", QuoteIdentifier(*schema))
+}
+
+// OnUser returns the ON clause of a grant on the supplied database user, for
+// example to grant IMPERSONATE on it.
+func OnUser(user string) string {
+	return fmt.Sprintf("ON USER::%s", QuoteIdentifier(user))
+}
+
+// GrantQuery returns a statement granting the supplied permissions on the
+// schema, or on the database if schema is nil, to a user.
+func GrantQuery(permissions []string, schema *string, user string) string {
+	return GrantOnQuery(permissions, OnSchema(schema), user)
+}
+
+// RevokeQuery returns a statement revoking the supplied permissions on the
+// schema, or on the database if schema is nil, from a user.
+func RevokeQuery(permissions []string, schema *string, user string) string {
+	return RevokeOnQuery(permissions, OnSchema(schema), user)
+}
+
+// GrantOnQuery returns a statement granting the supplied permissions to a
+// user on the securable named by the supplied ON clause, as returned by
+// OnSchema or OnUser.
+func GrantOnQuery(permissions []string, on, user string) string {
+	return fmt.Sprintf("GRANT %s %s TO %s", strings.Join(permissions, ", "), on, QuoteIdentifier(user))
+}
+
+// RevokeOnQuery returns a statement revoking the supplied permissions from a
+// user on the securable named by the supplied ON clause, as returned by
+// OnSchema or OnUser.
+func RevokeOnQuery(permissions []string, on, user string) string {
+	return fmt.Sprintf("REVOKE %s %s FROM %s", strings.Join(permissions, ", "), on, QuoteIdentifier(user))
+}
+
+// DiffPermissions returns the desired permissions that are not observed, and
+// the observed permissions that are not desired, both sorted.
+func DiffPermissions(desired, observed []string) ([]string, []string) {
+	md := make(map[string]struct{}, len(desired))
+	mo := make(map[string]struct{}, len(observed))
+
+	for _, v := range desired {
+		md[v] = struct{}{}
+	}
+	for _, v := range observed {
+		mo[v] = struct{}{}
+	}
+
+	var toGrant []string
+	var toRevoke []string
+
+	for p := range md {
+		if _, ok := mo[p]; !ok {
+			toGrant = append(toGrant, p)
+		}
+	}
+
+	for p := range mo {
+		if _, ok := md[p]; !ok {
+			toRevoke = append(toRevoke, p)
+		}
+	}
+
+	sort.Strings(toGrant)
+	sort.Strings(toRevoke)
+	return toGrant, toRevoke
+}
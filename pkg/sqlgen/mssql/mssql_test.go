@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffPermissions(t *testing.T) {
+	type args struct {
+		desired  []string
+		observed []string
+	}
+	type want struct {
+		toGrant  []string
+		toRevoke []string
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"AsDesired": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"CREATE TABLE", "DELETE"},
+			},
+			want: want{
+				toGrant:  nil,
+				toRevoke: nil,
+			},
+		},
+		"AsDesiredOrderNotMatter": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"DELETE", "CREATE TABLE"},
+			},
+			want: want{
+				toGrant:  nil,
+				toRevoke: nil,
+			},
+		},
+		"NeedsGrant": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"CREATE TABLE"},
+			},
+			want: want{
+				toGrant: []string{"DELETE"},
+			},
+		},
+		"NeedsRevoke": {
+			args: args{
+				desired:  []string{"CREATE TABLE"},
+				observed: []string{"CREATE TABLE", "DELETE"},
+			},
+			want: want{
+				toRevoke: []string{"DELETE"},
+			},
+		},
+		"NeedsBoth": {
+			args: args{
+				desired:  []string{"CREATE TABLE"},
+				observed: []string{"DELETE"},
+			},
+			want: want{
+				toGrant:  []string{"CREATE TABLE"},
+				toRevoke: []string{"DELETE"},
+			},
+		},
+		"GrantAll": {
+			args: args{
+				desired: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+			want: want{
+				toGrant: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+		},
+		"RevokeAll": {
+			args: args{
+				observed: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+			want: want{
+				toRevoke: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotToGrant, gotToRevoke := DiffPermissions(tc.args.desired, tc.args.observed)
+			if diff := cmp.Diff(tc.want.toGrant, gotToGrant); diff != "" {
+				t.Errorf("\nDiffPermissions(...): -want toGrant, +got toGrant:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.toRevoke, gotToRevoke); diff != "" {
+				t.Errorf("\nDiffPermissions(...): -want toRevoke, +got toRevoke:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"slices"
+	"strings"
+)
+
+// The flavors of MySQL servers, which differ in the privileges they let
+// accounts grant and the account resource limits they support.
+const (
+	// FlavorVanilla is a MySQL or MariaDB server that lets an account with
+	// the GRANT OPTION grant every privilege.
+	FlavorVanilla = "vanilla"
+
+	// FlavorRDS is an Amazon RDS for MySQL or MariaDB instance.
+	FlavorRDS = "rds"
+
+	// FlavorAurora is an Amazon Aurora MySQL cluster.
+	FlavorAurora = "aurora"
+
+	// FlavorTiDB is a self-hosted TiDB cluster or a TiDB Cloud cluster,
+	// which speak the MySQL protocol.
+	FlavorTiDB = "tidb"
+)
+
+// ungrantablePrivileges are the privileges that no account of a flavor can
+// grant, as its master user does not have them.
+var ungrantablePrivileges = map[string][]string{
+	FlavorRDS:    {"CREATE TABLESPACE", "FILE", "SHUTDOWN", "SUPER"},
+	FlavorAurora: {"CREATE TABLESPACE", "FILE", "SHUTDOWN", "SUPER"},
+	FlavorTiDB:   {"CONFIG", "FILE", "SHUTDOWN", "SUPER"},
+}
+
+// unsupportedResourceOptions are the account resource limits a flavor does
+// not support, as the clauses of CREATE USER and ALTER USER that set them.
+// TiDB only limits the number of simultaneous connections of an account.
+var unsupportedResourceOptions = map[string][]string{
+	FlavorTiDB: {"MAX_QUERIES_PER_HOUR", "MAX_UPDATES_PER_HOUR", "MAX_CONNECTIONS_PER_HOUR"},
+}
+
+// Grantable returns true if the supplied privilege can be granted on a
+// server of the supplied flavor. Every privilege is grantable on an unknown
+// flavor.
+func Grantable(privilege, flavor string) bool {
+	return !slices.Contains(ungrantablePrivileges[flavor], strings.ToUpper(privilege))
+}
+
+// GrantablePrivileges returns those of the supplied privileges that can be
+// granted on a server of the supplied flavor.
+func GrantablePrivileges(privileges []string, flavor string) []string {
+	if len(ungrantablePrivileges[flavor]) == 0 {
+		return privileges
+	}
+	var out []string
+	for _, p := range privileges {
+		if Grantable(p, flavor) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// SupportsResourceOption returns true if a server of the supplied flavor
+// supports the account resource limit set by the supplied clause, e.g.
+// MAX_QUERIES_PER_HOUR. Every limit is supported on an unknown flavor.
+func SupportsResourceOption(clause, flavor string) bool {
+	return !slices.Contains(unsupportedResourceOptions[flavor], clause)
+}
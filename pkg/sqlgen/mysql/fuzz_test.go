@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// privilegePattern is the validation of GrantPrivilege in the API. Privileges
+// are keywords, so they cannot be quoted and must be validated instead.
+var privilegePattern = regexp.MustCompile(`^[A-Z_ ]+$`)
+
+// skeleton returns the supplied statement with each quoted identifier and
+// string literal replaced by a question mark, or false if one of them is not
+// terminated. Any user input that escaped its quotes shows up in the
+// skeleton.
+func skeleton(stmt string) (string, bool) {
+	var out strings.Builder
+	for i := 0; i < len(stmt); i++ {
+		q := stmt[i]
+		if q != '`' && q != '\'' {
+			out.WriteByte(q)
+			continue
+		}
+		end := closing(stmt, i+1, q)
+		if end < 0 {
+			return "", false
+		}
+		out.WriteByte('?')
+		i = end
+	}
+	return out.String(), true
+}
+
+// closing returns the index of the quote that terminates the quoted
+// identifier or string literal starting at i, or -1 if there is none.
+func closing(s string, i int, q byte) int {
+	for ; i < len(s); i++ {
+		switch {
+		case q == '\'' && s[i] == '\\':
+			i++
+		case s[i] == q && i+1 < len(s) && s[i+1] == q:
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, s := range []string{"", "example", "a`b", "a'b", `a\`, `\'; DROP USER x; --`, "``", "'"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, q := range []string{QuoteIdentifier(s), QuoteValue(s)} {
+			if got, ok := skeleton(q); !ok || got != "?" {
+				t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", q, "?", got, ok)
+			}
+		}
+	})
+}
+
+func FuzzGrantQuery(f *testing.F) {
+	f.Add("db", "table", "user", "%", "SELECT", false)
+	f.Add("a`.`b", "*", `u\`, "' OR 1=1 --", "ALL PRIVILEGES", true)
+	f.Add("", "t`", "u'", `h\'`, "INSERT", false)
+	f.Fuzz(func(t *testing.T, database, table, username, host, privilege string, grantOption bool) {
+		if !privilegePattern.MatchString(privilege) {
+			t.Skip()
+		}
+		db, tb := QuoteIdentifier(database), QuoteIdentifier(table)
+		suffix := ""
+		if grantOption {
+			suffix = " WITH GRANT OPTION"
+		}
+
+		cases := map[string]string{
+			GrantQuery(privilege, db, username, host, tb, grantOption):  "GRANT " + privilege + " ON ?.? TO ?@?" + suffix,
+			RevokeQuery(privilege, db, username, host, tb, grantOption): "REVOKE " + privilege + " ON ?.? FROM ?@?" + suffix,
+		}
+		for stmt, want := range cases {
+			if got, ok := skeleton(stmt); !ok || got != want {
+				t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", stmt, want, got, ok)
+			}
+		}
+	})
+}
+
+func FuzzParseRevoke(f *testing.F) {
+	for _, s := range []string{"db", "a`b", "app\\_%", "`", "*", "a.*"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, database string) {
+		// SHOW GRANTS output is split on whitespace.
+		if database == "" || strings.IndexFunc(database, unicode.IsSpace) >= 0 {
+			t.Skip()
+		}
+		revoke := RevokeQuery("SELECT", QuoteIdentifier(database), "user", "%", "*", false)
+		if got := ParseRevoke(revoke); got != database {
+			t.Errorf("ParseRevoke(%q): want %q, got %q", revoke, database, got)
+		}
+	})
+}
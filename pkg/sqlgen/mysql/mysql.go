@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mysql generates the SQL statements the provider uses to manage
+// MySQL users and grants, and diffs the privileges they describe. It has no
+// dependency on the provider's API types, so that other tools can generate
+// the exact statements the provider executes.
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AllPrivileges is the privilege that SHOW GRANTS reports for a grant of
+// ALL, which it is an alias for.
+const AllPrivileges = "ALL PRIVILEGES"
+
+// A Scope is the level of the objects a grant applies to.
+type Scope int
+
+// The scopes of a grant.
+const (
+	// ScopeGlobal is the scope of a grant on *.*.
+	ScopeGlobal Scope = iota
+
+	// ScopeDatabase is the scope of a grant on all tables of a database,
+	// or of the databases matching a pattern.
+	ScopeDatabase
+
+	// ScopeTable is the scope of a grant on a single table.
+	ScopeTable
+)
+
+// tablePrivileges are the privileges a grant of ALL includes on a table.
+var tablePrivileges = []string{
+	"ALTER", "CREATE", "CREATE VIEW", "DELETE", "DROP", "INDEX", "INSERT",
+	"REFERENCES", "SELECT", "SHOW VIEW", "TRIGGER", "UPDATE",
+}
+
+// databasePrivileges are the privileges a grant of ALL includes on a
+// database, in addition to its tablePrivileges.
+var databasePrivileges = []string{
+	"ALTER ROUTINE", "CREATE ROUTINE", "CREATE TEMPORARY TABLES", "EVENT",
+	"EXECUTE", "LOCK TABLES",
+}
+
+// globalPrivileges are the privileges a grant of ALL includes on *.* in
+// every supported MySQL and MariaDB version, in addition to its
+// databasePrivileges. Newer versions include more, such as the dynamic
+// privileges of MySQL 8.0.
+var globalPrivileges = []string{
+	"CREATE USER", "FILE", "PROCESS", "RELOAD", "REPLICATION SLAVE",
+	"SHOW DATABASES", "SHUTDOWN", "SUPER",
+}
+
+// GrantOption is the privilege that stands for WITH GRANT OPTION in a list
+// of privileges.
+const GrantOption = "GRANT OPTION"
+
+var (
+	grantRegex  = regexp.MustCompile(`^GRANT (.+) ON (\S+)\.(\S+) TO \S+@\S+?(\sWITH GRANT OPTION)?$`)
+	revokeRegex = regexp.MustCompile(`^REVOKE (.+) ON (\S+)\.\* FROM \S+@\S+$`)
+
+	valueEscaper = strings.NewReplacer(`\`, `\\`, "'", "''")
+)
+
+// QuoteIdentifier quotes the supplied identifier, e.g. a database or table
+// name, doubling any backticks it contains.
+func QuoteIdentifier(id string) string {
+	return "`" + strings.ReplaceAll(id, "`", "``") + "`"
+}
+
+// QuoteValue quotes the supplied string literal, e.g. a user or host name,
+// doubling any single quotes it contains. Backslashes are escaped too, as
+// MySQL treats them as escape characters in string literals unless the
+// sql_mode includes NO_BACKSLASH_ESCAPES, which the sessions of the MySQL
+// client never do.
+func QuoteValue(v string) string {
+	return "'" + valueEscaper.Replace(v) + "'"
+}
+
+// ParseGrant returns the privileges of a line of SHOW GRANTS output, or nil
+// if it is not a grant on the supplied quoted database and table. A grant
+// made WITH GRANT OPTION includes the GrantOption privilege.
+func ParseGrant(grant, database, table string) []string {
+	matches := grantRegex.FindStringSubmatch(grant)
+	if len(matches) == 5 && matches[2] == database && matches[3] == table {
+		privileges := strings.Split(matches[1], ", ")
+
+		if matches[4] != "" {
+			privileges = append(privileges, GrantOption)
+		}
+
+		return privileges
+	}
+
+	return nil
+}
+
+// ParseRevoke returns the unquoted database of a partial revoke, as reported
+// by SHOW GRANTS when partial_revokes is enabled, or an empty string if the
+// supplied line is not a partial revoke.
+func ParseRevoke(revoke string) string {
+	matches := revokeRegex.FindStringSubmatch(revoke)
+	if len(matches) != 3 || matches[2] == "*" {
+		return ""
+	}
+
+	return strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(matches[2], "`"), "`"), "``", "`")
+}
+
+// CollapsePrivileges returns the supplied privileges observed at the supplied
+// scope, replaced by AllPrivileges if they include every privilege a grant
+// of ALL includes at that scope. information_schema lists a grant of ALL as
+// the privileges it includes, rather than as AllPrivileges. ALL implies every
+// other privilege at the same scope, so only GrantOption is kept alongside it.
+// Privileges that cannot be granted on the supplied flavor are not expected,
+// as a grant of ALL on such a server does not include them.
+func CollapsePrivileges(privileges []string, scope Scope, flavor string) []string {
+	all := append([]string{}, tablePrivileges...)
+	if scope != ScopeTable {
+		all = append(all, databasePrivileges...)
+	}
+	if scope == ScopeGlobal {
+		all = append(all, globalPrivileges...)
+	}
+	if len(Subtract(GrantablePrivileges(all, flavor), privileges)) > 0 {
+		return privileges
+	}
+
+	collapsed := []string{AllPrivileges}
+	for _, p := range privileges {
+		if p == GrantOption {
+			collapsed = append(collapsed, GrantOption)
+		}
+	}
+	return collapsed
+}
+
+// PrivilegesString returns the comma separated privileges, without the
+// GrantOption privilege, and whether it was among them.
+func PrivilegesString(privileges []string) (string, bool) {
+	privilegesWithoutGrantOption := []string{}
+	grantOption := false
+	for _, p := range privileges {
+		if p == GrantOption {
+			grantOption = true
+			continue
+		}
+		privilegesWithoutGrantOption = append(privilegesWithoutGrantOption, p)
+	}
+	out := strings.Join(privilegesWithoutGrantOption, ", ")
+	return out, grantOption
+}
+
+// GrantQuery returns a statement granting the supplied comma separated
+// privileges on the quoted database and table to a user.
+func GrantQuery(privileges, database, username, host, table string, grantOption bool) string {
+	result := fmt.Sprintf("GRANT %s ON %s.%s TO %s@%s",
+		privileges,
+		database,
+		table,
+		QuoteValue(username),
+		QuoteValue(host),
+	)
+
+	if grantOption {
+		result = fmt.Sprintf("%s WITH GRANT OPTION", result)
+	}
+
+	return result
+}
+
+// RevokeQuery returns a statement revoking the supplied comma separated
+// privileges on the quoted database and table from a user.
+func RevokeQuery(privileges, database, username, host, table string, grantOption bool) string {
+	result := fmt.Sprintf("REVOKE %s ON %s.%s FROM %s@%s",
+		privileges,
+		database,
+		table,
+		QuoteValue(username),
+		QuoteValue(host),
+	)
+
+	if grantOption {
+		result = fmt.Sprintf("%s WITH GRANT OPTION", result)
+	}
+
+	return result
+}
+
+// DiffPrivileges returns the desired privileges that are not observed, and
+// the observed privileges that are not desired, in no particular order.
+// AllPrivileges and ALL are considered the same privilege.
+func DiffPrivileges(desired, observed []string) ([]string, []string) {
+	desiredMap := make(map[string]struct{}, len(desired))
+	observedMap := make(map[string]struct{}, len(observed))
+
+	for _, desiredPrivilege := range desired {
+		// Special case because ALL is an alias for "ALL PRIVILEGES"
+		desiredPrivilegeMapped := strings.ReplaceAll(desiredPrivilege, AllPrivileges, "ALL")
+		desiredMap[desiredPrivilegeMapped] = struct{}{}
+	}
+	for _, observedPrivilege := range observed {
+		// Special case because ALL is an alias for "ALL PRIVILEGES"
+		observedPrivilegeMapped := strings.ReplaceAll(observedPrivilege, AllPrivileges, "ALL")
+		observedMap[observedPrivilegeMapped] = struct{}{}
+	}
+
+	var toGrant []string
+	var toRevoke []string
+
+	for desiredPrivilege := range desiredMap {
+		if _, ok := observedMap[desiredPrivilege]; !ok {
+			toGrant = append(toGrant, desiredPrivilege)
+		}
+	}
+
+	for observedPrivilege := range observedMap {
+		if _, ok := desiredMap[observedPrivilege]; !ok {
+			toRevoke = append(toRevoke, observedPrivilege)
+		}
+	}
+
+	return toGrant, toRevoke
+}
+
+// DiffRestrictions returns the desired partial revokes that are not
+// observed, and the observed partial revokes that are not desired.
+func DiffRestrictions(desired, observed []string) ([]string, []string) {
+	return Subtract(desired, observed), Subtract(observed, desired)
+}
+
+// Subtract returns the elements of a that are not in b.
+func Subtract(a, b []string) []string {
+	bMap := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		bMap[v] = struct{}{}
+	}
+
+	var out []string
+	for _, v := range a {
+		if _, ok := bMap[v]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiffPrivileges(t *testing.T) {
+	type args struct {
+		desired  []string
+		observed []string
+	}
+	type want struct {
+		toGrant  []string
+		toRevoke []string
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"AsDesired": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"CREATE TABLE", "DELETE"},
+			},
+			want: want{
+				toGrant:  nil,
+				toRevoke: nil,
+			},
+		},
+		"AsDesiredOrderNotMatter": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"DELETE", "CREATE TABLE"},
+			},
+			want: want{
+				toGrant:  nil,
+				toRevoke: nil,
+			},
+		},
+		"NeedsGrant": {
+			args: args{
+				desired:  []string{"CREATE TABLE", "DELETE"},
+				observed: []string{"CREATE TABLE"},
+			},
+			want: want{
+				toGrant: []string{"DELETE"},
+			},
+		},
+		"NeedsRevoke": {
+			args: args{
+				desired:  []string{"CREATE TABLE"},
+				observed: []string{"CREATE TABLE", "DELETE"},
+			},
+			want: want{
+				toRevoke: []string{"DELETE"},
+			},
+		},
+		"NeedsBoth": {
+			args: args{
+				desired:  []string{"CREATE TABLE"},
+				observed: []string{"DELETE"},
+			},
+			want: want{
+				toGrant:  []string{"CREATE TABLE"},
+				toRevoke: []string{"DELETE"},
+			},
+		},
+		"GrantAll": {
+			args: args{
+				desired: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+			want: want{
+				toGrant: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+		},
+		"RevokeAll": {
+			args: args{
+				observed: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+			want: want{
+				toRevoke: []string{"CREATE TABLE", "DELETE", "INSERT"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotToGrant, gotToRevoke := DiffPrivileges(tc.args.desired, tc.args.observed)
+			if diff := cmp.Diff(tc.want.toGrant, gotToGrant, equateSlices()...); diff != "" {
+				t.Errorf("\nDiffPrivileges(...): -want toGrant, +got toGrant:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.toRevoke, gotToRevoke, equateSlices()...); diff != "" {
+				t.Errorf("\nDiffPrivileges(...): -want toRevoke, +got toRevoke:\n%s", diff)
+			}
+		})
+	}
+}
+
+func equateSlices() []cmp.Option {
+	return []cmp.Option{
+		cmp.Transformer("mapAllPrivileges", func(s string) string {
+			if s == "ALL PRIVILEGES" {
+				return "ALL"
+			}
+			return s
+		}),
+		cmpopts.SortSlices(func(x, y string) bool {
+			return x < y
+		}),
+	}
+}
+
+func TestGrantablePrivileges(t *testing.T) {
+	cases := map[string]struct {
+		privileges []string
+		flavor     string
+		want       []string
+	}{
+		"Vanilla": {
+			privileges: []string{"SELECT", "SUPER", "FILE"},
+			flavor:     FlavorVanilla,
+			want:       []string{"SELECT", "SUPER", "FILE"},
+		},
+		"Unset": {
+			privileges: []string{"SELECT", "SUPER"},
+			want:       []string{"SELECT", "SUPER"},
+		},
+		"RDS": {
+			privileges: []string{"SELECT", "super", "FILE", GrantOption},
+			flavor:     FlavorRDS,
+			want:       []string{"SELECT", GrantOption},
+		},
+		"Aurora": {
+			privileges: []string{"SHUTDOWN", "CREATE TABLESPACE"},
+			flavor:     FlavorAurora,
+		},
+		"TiDB": {
+			privileges: []string{"SELECT", "SUPER", "CONFIG"},
+			flavor:     FlavorTiDB,
+			want:       []string{"SELECT"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, GrantablePrivileges(tc.privileges, tc.flavor)); diff != "" {
+				t.Errorf("GrantablePrivileges(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSupportsResourceOption(t *testing.T) {
+	cases := map[string]struct {
+		clause string
+		flavor string
+		want   bool
+	}{
+		"Vanilla": {
+			clause: "MAX_QUERIES_PER_HOUR",
+			flavor: FlavorVanilla,
+			want:   true,
+		},
+		"TiDBUnsupported": {
+			clause: "MAX_QUERIES_PER_HOUR",
+			flavor: FlavorTiDB,
+			want:   false,
+		},
+		"TiDBSupported": {
+			clause: "MAX_USER_CONNECTIONS",
+			flavor: FlavorTiDB,
+			want:   true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := SupportsResourceOption(tc.clause, tc.flavor); got != tc.want {
+				t.Errorf("SupportsResourceOption(%q, %q): want %t, got %t", tc.clause, tc.flavor, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCollapsePrivileges(t *testing.T) {
+	all := append(append([]string{}, tablePrivileges...), databasePrivileges...)
+
+	cases := map[string]struct {
+		privileges []string
+		scope      Scope
+		flavor     string
+		want       []string
+	}{
+		"Partial": {
+			privileges: []string{"SELECT", "INSERT"},
+			scope:      ScopeTable,
+			want:       []string{"SELECT", "INSERT"},
+		},
+		"AllOnTable": {
+			privileges: append(append([]string{}, tablePrivileges...), GrantOption),
+			scope:      ScopeTable,
+			want:       []string{AllPrivileges, GrantOption},
+		},
+		"AllOnDatabase": {
+			privileges: all,
+			scope:      ScopeDatabase,
+			want:       []string{AllPrivileges},
+		},
+		"NotAllOnGlobal": {
+			privileges: all,
+			scope:      ScopeGlobal,
+			want:       all,
+		},
+		"AllOnGlobal": {
+			privileges: append(append(append([]string{}, all...), globalPrivileges...), "BACKUP_ADMIN"),
+			scope:      ScopeGlobal,
+			want:       []string{AllPrivileges},
+		},
+		"AllOnGlobalRDS": {
+			privileges: append(append(append([]string{}, all...), "CREATE USER", "PROCESS", "RELOAD", "REPLICATION SLAVE", "SHOW DATABASES"), GrantOption),
+			scope:      ScopeGlobal,
+			flavor:     FlavorRDS,
+			want:       []string{AllPrivileges, GrantOption},
+		},
+		"NotAllOnGlobalVanilla": {
+			privileges: append(append([]string{}, all...), "CREATE USER", "PROCESS", "RELOAD", "REPLICATION SLAVE", "SHOW DATABASES"),
+			scope:      ScopeGlobal,
+			flavor:     FlavorVanilla,
+			want:       append(append([]string{}, all...), "CREATE USER", "PROCESS", "RELOAD", "REPLICATION SLAVE", "SHOW DATABASES"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, CollapsePrivileges(tc.privileges, tc.scope, tc.flavor)); diff != "" {
+				t.Errorf("CollapsePrivileges(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A ServerVersion is the version of a MySQL or MariaDB server.
+type ServerVersion struct {
+	Major   int
+	Minor   int
+	Patch   int
+	MariaDB bool
+}
+
+// versionRegex matches the version reported by VERSION(), e.g. 8.0.36,
+// 10.11.6-MariaDB-1:10.11.6+maria~ubu2204, or 8.0.mysql_aurora.3.05.2.
+var versionRegex = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseServerVersion parses the supplied version, as reported by VERSION().
+func ParseServerVersion(version string) (ServerVersion, error) {
+	// MariaDB versions reported through the replication protocol are
+	// prefixed with the version MySQL clients expect.
+	v := strings.TrimPrefix(version, "5.5.5-")
+	m := versionRegex.FindStringSubmatch(v)
+	if m == nil {
+		return ServerVersion{}, fmt.Errorf("cannot parse server version %q", version)
+	}
+	sv := ServerVersion{MariaDB: strings.Contains(strings.ToLower(version), "mariadb")}
+	sv.Major, _ = strconv.Atoi(m[1])
+	sv.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		sv.Patch, _ = strconv.Atoi(m[3])
+	}
+	return sv, nil
+}
+
+// AtLeast returns true if the version is the supplied major and minor
+// version, or later.
+func (v ServerVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v ServerVersion) String() string {
+	return fmt.Sprintf("%s %d.%d.%d", v.product(), v.Major, v.Minor, v.Patch)
+}
+
+func (v ServerVersion) product() string {
+	if v.MariaDB {
+		return "MariaDB"
+	}
+	return "MySQL"
+}
+
+// A minVersion is the version that introduced a privilege. A zero minVersion
+// means no version supports it.
+type minVersion struct {
+	major int
+	minor int
+}
+
+func (m minVersion) supported() bool {
+	return m != minVersion{}
+}
+
+// A requirement is the MySQL and MariaDB versions that introduced a
+// privilege.
+type requirement struct {
+	mysql   minVersion
+	mariadb minVersion
+}
+
+// dynamicPrivileges is the requirement of the dynamic privileges of MySQL,
+// e.g. BACKUP_ADMIN, which MariaDB does not have.
+var dynamicPrivileges = requirement{mysql: minVersion{8, 0}}
+
+// privilegeRequirements are the requirements of the static privileges that
+// not every supported MySQL and MariaDB version has.
+var privilegeRequirements = map[string]requirement{
+	"CREATE ROLE":              {mysql: minVersion{8, 0}},
+	"DROP ROLE":                {mysql: minVersion{8, 0}},
+	"DELETE HISTORY":           {mariadb: minVersion{10, 3}},
+	"BINLOG ADMIN":             {mariadb: minVersion{10, 5}},
+	"BINLOG MONITOR":           {mariadb: minVersion{10, 5}},
+	"BINLOG REPLAY":            {mariadb: minVersion{10, 5}},
+	"CONNECTION ADMIN":         {mariadb: minVersion{10, 5}},
+	"FEDERATED ADMIN":          {mariadb: minVersion{10, 5}},
+	"READ_ONLY ADMIN":          {mariadb: minVersion{10, 5}},
+	"REPLICATION MASTER ADMIN": {mariadb: minVersion{10, 5}},
+	"REPLICATION SLAVE ADMIN":  {mariadb: minVersion{10, 5}},
+	"SET USER":                 {mariadb: minVersion{10, 5}},
+	"SLAVE MONITOR":            {mariadb: minVersion{10, 5}},
+	"SHOW CREATE ROUTINE":      {mariadb: minVersion{11, 3}},
+}
+
+// requirementOf returns the requirement of the supplied privilege, or false
+// if every supported version has it.
+func requirementOf(privilege string) (requirement, bool) {
+	p := strings.ToUpper(privilege)
+	if r, ok := privilegeRequirements[p]; ok {
+		return r, true
+	}
+	// Dynamic privileges are single words joined by underscores, while the
+	// words of static privileges are separated by spaces.
+	if !strings.Contains(p, " ") && strings.Contains(p, "_") {
+		return dynamicPrivileges, true
+	}
+	return requirement{}, false
+}
+
+// VersionedPrivileges returns those of the supplied privileges that not every
+// supported MySQL and MariaDB version has.
+func VersionedPrivileges(privileges []string) []string {
+	var versioned []string
+	for _, p := range privileges {
+		if _, ok := requirementOf(p); ok {
+			versioned = append(versioned, p)
+		}
+	}
+	return versioned
+}
+
+// ValidatePrivileges returns an error naming the first of the supplied
+// privileges that the server of the supplied version does not support, so
+// that it can be reported instead of the syntax error the server returns
+// for an unknown privilege.
+func ValidatePrivileges(privileges []string, v ServerVersion) error {
+	for _, p := range privileges {
+		r, ok := requirementOf(p)
+		if !ok {
+			continue
+		}
+		m := r.mysql
+		if v.MariaDB {
+			m = r.mariadb
+		}
+		switch {
+		case !m.supported():
+			return fmt.Errorf("privilege %s is not supported by %s", p, v.product())
+		case !v.AtLeast(m.major, m.minor):
+			return fmt.Errorf("privilege %s requires %s %d.%d or later, the server is %s", p, v.product(), m.major, m.minor, v)
+		}
+	}
+	return nil
+}
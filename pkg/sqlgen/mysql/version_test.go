@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	cases := map[string]struct {
+		version string
+		want    ServerVersion
+		wantErr bool
+	}{
+		"MySQL": {
+			version: "8.0.36",
+			want:    ServerVersion{Major: 8, Minor: 0, Patch: 36},
+		},
+		"MySQLLog": {
+			version: "5.7.44-log",
+			want:    ServerVersion{Major: 5, Minor: 7, Patch: 44},
+		},
+		"Aurora": {
+			version: "8.0.mysql_aurora.3.05.2",
+			want:    ServerVersion{Major: 8, Minor: 0},
+		},
+		"MariaDB": {
+			version: "10.11.6-MariaDB-1:10.11.6+maria~ubu2204",
+			want:    ServerVersion{Major: 10, Minor: 11, Patch: 6, MariaDB: true},
+		},
+		"MariaDBReplicationPrefix": {
+			version: "5.5.5-10.4.32-MariaDB",
+			want:    ServerVersion{Major: 10, Minor: 4, Patch: 32, MariaDB: true},
+		},
+		"Invalid": {
+			version: "unknown",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseServerVersion(tc.version)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseServerVersion(%q): want error %t, got %v", tc.version, tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseServerVersion(%q): -want, +got:\n%s", tc.version, diff)
+			}
+		})
+	}
+}
+
+func TestValidatePrivileges(t *testing.T) {
+	mysql57 := ServerVersion{Major: 5, Minor: 7, Patch: 44}
+	mysql80 := ServerVersion{Major: 8, Minor: 0, Patch: 36}
+	mariadb104 := ServerVersion{Major: 10, Minor: 4, Patch: 32, MariaDB: true}
+	mariadb1011 := ServerVersion{Major: 10, Minor: 11, Patch: 6, MariaDB: true}
+
+	cases := map[string]struct {
+		privileges []string
+		version    ServerVersion
+		want       string
+	}{
+		"StaticPrivileges": {
+			privileges: []string{"SELECT", "CREATE TEMPORARY TABLES", GrantOption},
+			version:    mysql57,
+		},
+		"DynamicPrivilegeOnMySQL80": {
+			privileges: []string{"SELECT", "BACKUP_ADMIN"},
+			version:    mysql80,
+		},
+		"DynamicPrivilegeOnMySQL57": {
+			privileges: []string{"SELECT", "BACKUP_ADMIN"},
+			version:    mysql57,
+			want:       "privilege BACKUP_ADMIN requires MySQL 8.0 or later, the server is MySQL 5.7.44",
+		},
+		"DynamicPrivilegeOnMariaDB": {
+			privileges: []string{"SYSTEM_VARIABLES_ADMIN"},
+			version:    mariadb1011,
+			want:       "privilege SYSTEM_VARIABLES_ADMIN is not supported by MariaDB",
+		},
+		"RolePrivilegeOnMySQL57": {
+			privileges: []string{"CREATE ROLE"},
+			version:    mysql57,
+			want:       "privilege CREATE ROLE requires MySQL 8.0 or later, the server is MySQL 5.7.44",
+		},
+		"MariaDBPrivilegeOnMariaDB1011": {
+			privileges: []string{"BINLOG ADMIN", "READ_ONLY ADMIN"},
+			version:    mariadb1011,
+		},
+		"MariaDBPrivilegeOnMariaDB104": {
+			privileges: []string{"DELETE HISTORY", "BINLOG ADMIN"},
+			version:    mariadb104,
+			want:       "privilege BINLOG ADMIN requires MariaDB 10.5 or later, the server is MariaDB 10.4.32",
+		},
+		"MariaDBPrivilegeOnMySQL": {
+			privileges: []string{"DELETE HISTORY"},
+			version:    mysql80,
+			want:       "privilege DELETE HISTORY is not supported by MySQL",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ""
+			if err := ValidatePrivileges(tc.privileges, tc.version); err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ValidatePrivileges(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// privilegePattern is the validation of GrantPrivilege in the API. Privileges
+// are keywords, so they cannot be quoted and must be validated instead.
+var privilegePattern = regexp.MustCompile(`^[A-Z]+( [A-Z]+)?$`)
+
+// skeleton returns the supplied statement with each quoted identifier and
+// string literal replaced by a question mark, or false if one of them is not
+// terminated. Any user input that escaped its quotes shows up in the
+// skeleton.
+func skeleton(stmt string) (string, bool) {
+	var out strings.Builder
+	for i := 0; i < len(stmt); i++ {
+		q := stmt[i]
+		if q != '"' && q != '\'' {
+			out.WriteByte(q)
+			continue
+		}
+		end := closing(stmt, i+1, q)
+		if end < 0 {
+			return "", false
+		}
+		out.WriteByte('?')
+		i = end
+	}
+	return out.String(), true
+}
+
+// closing returns the index of the quote that terminates the quoted
+// identifier or string literal starting at i, or -1 if there is none.
+func closing(s string, i int, q byte) int {
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == q && i+1 < len(s) && s[i+1] == q:
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func FuzzUpdateDatabaseQueries(f *testing.F) {
+	f.Add("db", "role", "CONNECT", false)
+	f.Add(`a"b`, `" TO PUBLIC; --`, "TEMPORARY", true)
+	f.Add("", "r'", "ALL PRIVILEGES", false)
+	f.Fuzz(func(t *testing.T, database, role, privilege string, grantOption bool) {
+		if !privilegePattern.MatchString(privilege) {
+			t.Skip()
+		}
+		option, with := "", " "
+		if grantOption {
+			option, with = "GRANT", " WITH GRANT OPTION"
+		}
+		d := PrivilegeDiff{Grant: []string{privilege}, RevokeOption: []string{privilege}, Revoke: []string{privilege}}
+
+		want := []string{
+			"REVOKE " + privilege + " ON DATABASE ? FROM ?",
+			"REVOKE GRANT OPTION FOR " + privilege + " ON DATABASE ? FROM ?",
+			"GRANT " + privilege + " ON DATABASE ? TO ?" + with,
+		}
+		ql := UpdateDatabaseQueries(database, role, d, option)
+		if len(ql) != len(want) {
+			t.Fatalf("UpdateDatabaseQueries(...): want %d queries, got %d", len(want), len(ql))
+		}
+		for i, q := range ql {
+			if got, ok := skeleton(q.String); !ok || got != want[i] {
+				t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", q.String, want[i], got, ok)
+			}
+		}
+	})
+}
+
+func FuzzQuoteParameters(f *testing.F) {
+	f.Add("pgaudit.log", "work_mem")
+	f.Add(`a"b`, `"."; DROP ROLE x; --`)
+	f.Add("", "..")
+	f.Fuzz(func(t *testing.T, a, b string) {
+		want := make([]string, 0, 2)
+		for _, p := range Sorted([]string{a, b}) {
+			want = append(want, strings.Repeat("?.", strings.Count(p, "."))+"?")
+		}
+
+		stmt := QuoteParameters([]string{a, b})
+		if got, ok := skeleton(stmt); !ok || got != strings.Join(want, ",") {
+			t.Errorf("skeleton(%q): want %q, got %q (terminated %t)", stmt, strings.Join(want, ","), got, ok)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package postgresql generates the SQL statements the provider uses to
+// manage PostgreSQL grants, and diffs the privileges they describe. It has no
+// dependency on the provider's API types, so that other tools can generate
+// the exact statements the provider executes. Identifiers are quoted using
+// pq.QuoteIdentifier.
+package postgresql
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Sorted returns a sorted copy of the supplied names. Grants and revokes
+// name objects and privileges in sorted order so that concurrent
+// transactions acquire their locks in the same order.
+func Sorted(names []string) []string {
+	out := slices.Clone(names)
+	sort.Strings(out)
+	return out
+}
+
+// LowerParameters returns the supplied configuration parameter names as
+// recorded in pg_parameter_acl, which stores them in lower case.
+func LowerParameters(params []string) []string {
+	out := make([]string, len(params))
+	for i, p := range params {
+		out[i] = strings.ToLower(p)
+	}
+	return out
+}
+
+// QuoteParameters quotes each part of the supplied, possibly qualified,
+// configuration parameter names (e.g. pgaudit.log), and returns them sorted
+// and comma separated.
+func QuoteParameters(params []string) string {
+	out := make([]string, len(params))
+	for i, p := range Sorted(LowerParameters(params)) {
+		parts := strings.Split(p, ".")
+		for j, pt := range parts {
+			parts[j] = pq.QuoteIdentifier(pt)
+		}
+		out[i] = strings.Join(parts, ".")
+	}
+	return strings.Join(out, ",")
+}
+
+// WithOption returns the WITH clause of a grant made with the supplied
+// option, i.e. GRANT or ADMIN, or an empty string if option is empty.
+func WithOption(option string) string {
+	if option != "" {
+		return fmt.Sprintf("WITH %s OPTION", option)
+	}
+	return ""
+}
+
+// A PrivilegeDiff is the set of changes needed to bring the privileges a
+// role holds on a database or schema in line with the desired ones.
+type PrivilegeDiff struct {
+	// Grant are the privileges to grant.
+	Grant []string
+
+	// RevokeOption are the privileges to revoke the grant option of.
+	RevokeOption []string
+
+	// Revoke are the privileges to revoke.
+	Revoke []string
+}
+
+// Empty returns true if no changes are needed.
+func (d PrivilegeDiff) Empty() bool {
+	return len(d.Grant) == 0 && len(d.RevokeOption) == 0 && len(d.Revoke) == 0
+}
+
+// DiffDatabasePrivileges returns the changes needed to bring the held
+// privileges, mapped to whether they carry the grant option, in line with
+// the desired ones. Held privileges that are not desired are only revoked if
+// revokeUnmanaged is true.
+func DiffDatabasePrivileges(desired []string, grantOption, revokeUnmanaged bool, held map[string]bool) PrivilegeDiff {
+	desired = Sorted(desired)
+
+	d := PrivilegeDiff{}
+	for _, p := range desired {
+		g, ok := held[p]
+		switch {
+		case !ok || (grantOption && !g):
+			d.Grant = append(d.Grant, p)
+		case !grantOption && g:
+			d.RevokeOption = append(d.RevokeOption, p)
+		}
+	}
+
+	// Other grants may manage other privileges of the same role on the same
+	// database, so only revoke those not listed here when asked to.
+	if !revokeUnmanaged {
+		return d
+	}
+	for p := range held {
+		if !slices.Contains(desired, p) {
+			d.Revoke = append(d.Revoke, p)
+		}
+	}
+	sort.Strings(d.Revoke)
+	return d
+}
+
+// UpdateDatabaseQueries returns the statements that apply the supplied diff
+// to the privileges of a role on a database. Privileges are granted with the
+// supplied option, as accepted by WithOption.
+func UpdateDatabaseQueries(database, role string, d PrivilegeDiff, option string) []xsql.Query {
+	return updateQueries("DATABASE "+pq.QuoteIdentifier(database), role, d, option)
+}
+
+// UpdateSchemaQueries returns the statements that apply the supplied diff to
+// the privileges of a role on a schema. Privileges are granted with the
+// supplied option, as accepted by WithOption.
+func UpdateSchemaQueries(schema, role string, d PrivilegeDiff, option string) []xsql.Query {
+	return updateQueries("SCHEMA "+pq.QuoteIdentifier(schema), role, d, option)
+}
+
+// updateQueries returns the statements that apply the supplied diff to the
+// privileges of a role on the supplied object, e.g. DATABASE "db".
+func updateQueries(object, role string, d PrivilegeDiff, option string) []xsql.Query {
+	ro := pq.QuoteIdentifier(role)
+
+	var ql []xsql.Query
+	if len(d.Revoke) > 0 {
+		ql = append(ql, xsql.Query{String: fmt.Sprintf("REVOKE %s ON %s FROM %s",
+			strings.Join(d.Revoke, ","),
+			object,
+			ro,
+		)})
+	}
+	if len(d.RevokeOption) > 0 {
+		ql = append(ql, xsql.Query{String: fmt.Sprintf("REVOKE GRANT OPTION FOR %s ON %s FROM %s",
+			strings.Join(d.RevokeOption, ","),
+			object,
+			ro,
+		)})
+	}
+	if len(d.Grant) > 0 {
+		ql = append(ql, xsql.Query{String: fmt.Sprintf("GRANT %s ON %s TO %s %s",
+			strings.Join(d.Grant, ","),
+			object,
+			ro,
+			WithOption(option),
+		)})
+	}
+	return ql
+}
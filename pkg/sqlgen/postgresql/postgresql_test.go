@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiffDatabasePrivileges(t *testing.T) {
+	type args struct {
+		desired         []string
+		grantOption     bool
+		revokeUnmanaged bool
+		held            map[string]bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   PrivilegeDiff
+	}{
+		"UpToDate": {
+			reason: "No changes are needed if the desired privileges are held",
+			args: args{
+				desired: []string{"CREATE", "CONNECT"},
+				held:    map[string]bool{"CONNECT": false, "CREATE": false},
+			},
+			want: PrivilegeDiff{},
+		},
+		"Missing": {
+			reason: "Desired privileges that are not held should be granted in sorted order",
+			args: args{
+				desired: []string{"TEMPORARY", "CREATE", "CONNECT"},
+				held:    map[string]bool{"CONNECT": false},
+			},
+			want: PrivilegeDiff{Grant: []string{"CREATE", "TEMPORARY"}},
+		},
+		"MissingGrantOption": {
+			reason: "Privileges held without the desired grant option should be granted again",
+			args: args{
+				desired:     []string{"CONNECT"},
+				grantOption: true,
+				held:        map[string]bool{"CONNECT": false},
+			},
+			want: PrivilegeDiff{Grant: []string{"CONNECT"}},
+		},
+		"ExtraGrantOption": {
+			reason: "The grant option of privileges should be revoked if it is not desired",
+			args: args{
+				desired: []string{"CONNECT"},
+				held:    map[string]bool{"CONNECT": true},
+			},
+			want: PrivilegeDiff{RevokeOption: []string{"CONNECT"}},
+		},
+		"Unmanaged": {
+			reason: "Privileges that are not desired should be left alone unless unmanaged ones are revoked",
+			args: args{
+				desired: []string{"CONNECT"},
+				held:    map[string]bool{"CONNECT": false, "CREATE": false},
+			},
+			want: PrivilegeDiff{},
+		},
+		"RevokeUnmanaged": {
+			reason: "Privileges that are not desired should be revoked in sorted order if unmanaged ones are revoked",
+			args: args{
+				desired:         []string{"CONNECT"},
+				revokeUnmanaged: true,
+				held:            map[string]bool{"CONNECT": false, "TEMPORARY": true, "CREATE": false},
+			},
+			want: PrivilegeDiff{Revoke: []string{"CREATE", "TEMPORARY"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DiffDatabasePrivileges(tc.args.desired, tc.args.grantOption, tc.args.revokeUnmanaged, tc.args.held)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nDiffDatabasePrivileges(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestQuoteParameters(t *testing.T) {
+	got := QuoteParameters([]string{"pgaudit.log", "Work_Mem"})
+	want := `"pgaudit"."log","work_mem"`
+	if got != want {
+		t.Errorf("QuoteParameters(...): want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing exports OpenTelemetry spans for reconciles.
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errNewExporter = "cannot create OTLP trace exporter"
+
+	serviceName = "provider-sql"
+	tracerName  = "github.com/crossplane-contrib/provider-sql/pkg/tracing"
+)
+
+// Options configure how spans are exported.
+type Options struct {
+	// Endpoint is the host and port of the OTLP/HTTP collector.
+	Endpoint string
+
+	// Insecure disables TLS when connecting to the collector.
+	Insecure bool
+
+	// SampleRatio is the fraction of reconciles that are traced.
+	SampleRatio float64
+}
+
+// Setup installs a global TracerProvider that exports spans to the OTLP
+// collector described by the supplied options, and enables statement spans.
+// The returned function flushes pending spans and must be called on exit.
+func Setup(ctx context.Context, o Options) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(o.Endpoint)}
+	if o.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exp, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewExporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	xsql.TraceStatements = true
+
+	return tp.Shutdown, nil
+}
+
+// NewReconciler returns a reconciler that starts a span for every reconcile of
+// the supplied kind of managed resource. Spans of the statements executed
+// during the reconcile are its children.
+func NewReconciler(r reconcile.Reconciler, kind string) reconcile.Reconciler {
+	return &reconciler{wrapped: r, kind: kind}
+}
+
+type reconciler struct {
+	wrapped reconcile.Reconciler
+	kind    string
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Reconcile "+r.kind,
+		trace.WithAttributes(xsql.AttrKind.String(r.kind), xsql.AttrName.String(req.Name)))
+	defer span.End()
+
+	res, err := r.wrapped.Reconcile(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attribute.Bool("crossplane.requeue", res.Requeue || res.RequeueAfter > 0))
+	return res, err
+}